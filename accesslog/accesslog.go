@@ -0,0 +1,42 @@
+package accesslog
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Open returns the writer of an access log destination: stdout, stderr,
+// file:/path for a file rotated when it reaches maxSize bytes with up to
+// backups older files kept, syslog for the local syslog daemon, or
+// syslog://host:port and syslog+tcp://host:port for a remote one. It
+// returns nil for an empty destination, which leaves lines to the standard
+// logger.
+func Open(dest string, maxSize int64, backups int) (io.Writer, error) {
+	switch {
+	case dest == "":
+		return nil, nil
+	case dest == "stdout":
+		return os.Stdout, nil
+	case dest == "stderr":
+		return os.Stderr, nil
+	case strings.HasPrefix(dest, "file:"):
+		return OpenFile(strings.TrimPrefix(dest, "file:"), maxSize, backups)
+	case dest == "syslog":
+		return dialSyslog("", "")
+	case strings.HasPrefix(dest, "syslog://"), strings.HasPrefix(dest, "syslog+tcp://"):
+		u, err := url.Parse(dest)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog address %q", dest)
+		}
+		network := "udp"
+		if u.Scheme == "syslog+tcp" {
+			network = "tcp"
+		}
+		return dialSyslog(network, u.Host)
+	default:
+		return nil, fmt.Errorf("unknown access log destination %q", dest)
+	}
+}
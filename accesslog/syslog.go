@@ -0,0 +1,19 @@
+//go:build !windows && !plan9
+
+package accesslog
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+)
+
+// dialSyslog connects to the syslog daemon at address over network, or to
+// the local one when both are empty.
+func dialSyslog(network, address string) (io.Writer, error) {
+	w, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_LOCAL0, "jplaw2epub-api")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %v", err)
+	}
+	return w, nil
+}
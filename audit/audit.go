@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Entry records one document generation request.
+type Entry struct {
+	Time        time.Time     `json:"time"`
+	Operation   string        `json:"operation"`
+	Requester   string        `json:"requester,omitempty"`
+	Tenant      string        `json:"tenant,omitempty"`
+	RevisionID  string        `json:"revisionId,omitempty"`
+	Articles    []string      `json:"articles,omitempty"`
+	DiffAgainst string        `json:"diffAgainst,omitempty"`
+	Preset      string        `json:"preset,omitempty"`
+	Filename    string        `json:"filename,omitempty"`
+	Output      string        `json:"output,omitempty"`
+	Result      string        `json:"result"`
+	Error       string        `json:"error,omitempty"`
+	Duration    time.Duration `json:"-"`
+}
+
+// Logger is the audit sink for document generation.
+type Logger interface {
+	Log(ctx context.Context, entry Entry)
+}
+
+// NewLogger returns the audit logger for a backend: "stdout" for Cloud
+// Logging structured entries or "none" to disable auditing.
+func NewLogger(backend string) (Logger, error) {
+	switch backend {
+	case "stdout":
+		return NewJSONLogger(os.Stdout), nil
+	case "none":
+		return nopLogger{}, nil
+	default:
+		return nil, fmt.Errorf("unknown audit log backend %q", backend)
+	}
+}
+
+// JSONLogger writes one JSON object per line in the structured logging
+// format that Cloud Run forwards to Cloud Logging.
+type JSONLogger struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONLogger(w io.Writer) *JSONLogger {
+	return &JSONLogger{w: w}
+}
+
+// structuredEntry follows the special fields recognized by Cloud Logging.
+type structuredEntry struct {
+	Severity string            `json:"severity"`
+	Message  string            `json:"message"`
+	Time     string            `json:"time"`
+	Labels   map[string]string `json:"logging.googleapis.com/labels"`
+	Audit    Entry             `json:"audit"`
+	Duration float64           `json:"durationSeconds"`
+}
+
+func (l *JSONLogger) Log(_ context.Context, entry Entry) {
+	severity := "NOTICE"
+	if entry.Error != "" {
+		severity = "WARNING"
+	}
+
+	subject := entry.RevisionID
+	if subject == "" {
+		subject = entry.Filename
+	}
+
+	data, err := json.Marshal(structuredEntry{
+		Severity: severity,
+		Message:  fmt.Sprintf("%s %s by %s: %s", entry.Operation, subject, entry.Requester, entry.Result),
+		Time:     entry.Time.UTC().Format(time.RFC3339Nano),
+		Labels:   map[string]string{"type": "audit", "operation": entry.Operation},
+		Audit:    entry,
+		Duration: entry.Duration.Seconds(),
+	})
+	if err != nil {
+		log.Printf("Failed to encode audit entry: %v", err)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.w.Write(append(data, '\n')); err != nil {
+		log.Printf("Failed to write audit entry: %v", err)
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(context.Context, Entry) {}
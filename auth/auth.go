@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+type contextKey struct{}
+
+// User is a person signed in with an OpenID Connect provider.
+type User struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// ID returns a stable identifier of the user for storage paths and quota
+// counters. Subjects are only unique per issuer and may contain characters
+// such as "|", so the pair is hashed.
+func (u *User) ID() string {
+	sum := sha256.Sum256([]byte(u.Issuer + "\n" + u.Subject))
+	return "oidc-" + hex.EncodeToString(sum[:16])
+}
+
+// WithUser returns a copy of ctx carrying the signed-in user.
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// UserFromContext returns the user recorded by WithUser, or nil for
+// anonymous requests.
+func UserFromContext(ctx context.Context) *User {
+	u, _ := ctx.Value(contextKey{}).(*User)
+	return u
+}
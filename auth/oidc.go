@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// ErrInvalidToken is wrapped by the errors of Verifier.Verify for tokens
+// that are malformed, expired, or not signed by a configured provider.
+var ErrInvalidToken = errors.New("invalid ID token")
+
+const (
+	// keySetTTL is how long a provider's signing keys are used before they
+	// are fetched again. Tokens signed with an unknown key refresh them
+	// earlier, at most once per keyRefreshInterval.
+	keySetTTL          = time.Hour
+	keyRefreshInterval = time.Minute
+	// clockSkew is the leeway for the expiry and issue times of tokens.
+	clockSkew = time.Minute
+)
+
+// Provider is an OpenID Connect provider whose ID tokens are accepted, such
+// as https://accounts.google.com or an Auth0 tenant.
+type Provider struct {
+	// Issuer is the iss claim of the provider's tokens; its discovery
+	// document is read from {Issuer}/.well-known/openid-configuration.
+	Issuer string
+	// Audiences are the accepted aud claims, such as OAuth client IDs.
+	Audiences []string
+}
+
+// Verifier verifies ID tokens of a set of providers.
+type Verifier struct {
+	client    *http.Client
+	providers map[string]*providerKeys
+}
+
+// providerKeys caches the signing keys of a provider.
+type providerKeys struct {
+	Provider
+	mu        sync.Mutex
+	keys      *jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+// NewVerifier returns a verifier for the providers. Keys are fetched when
+// the first token of a provider is verified.
+func NewVerifier(providers []Provider) *Verifier {
+	v := &Verifier{
+		client:    &http.Client{Timeout: 10 * time.Second},
+		providers: make(map[string]*providerKeys, len(providers)),
+	}
+	for _, p := range providers {
+		v.providers[strings.TrimSuffix(p.Issuer, "/")] = &providerKeys{Provider: p}
+	}
+	return v
+}
+
+// Verify checks the signature, issuer, audience, and validity period of an
+// ID token and returns its user.
+func (v *Verifier) Verify(ctx context.Context, raw string) (*User, error) {
+	token, err := jwt.ParseSigned(raw, []jose.SignatureAlgorithm{jose.RS256, jose.ES256})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	var unverified jwt.Claims
+	if err := token.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	provider, ok := v.providers[strings.TrimSuffix(unverified.Issuer, "/")]
+	if !ok {
+		return nil, fmt.Errorf("%w: untrusted issuer %q", ErrInvalidToken, unverified.Issuer)
+	}
+	if len(token.Headers) != 1 {
+		return nil, fmt.Errorf("%w: expected one signature", ErrInvalidToken)
+	}
+
+	key, err := v.key(ctx, provider, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+	var claims jwt.Claims
+	var profile struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := token.Claims(key, &claims, &profile); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	expected := jwt.Expected{Issuer: unverified.Issuer, AnyAudience: jwt.Audience(provider.Audiences), Time: time.Now()}
+	if err := claims.ValidateWithLeeway(expected, clockSkew); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: token does not expire", ErrInvalidToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidToken)
+	}
+
+	return &User{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         profile.Email,
+		EmailVerified: profile.EmailVerified,
+		Name:          profile.Name,
+		Picture:       profile.Picture,
+	}, nil
+}
+
+// key returns the signing key of a provider with the key ID, fetching the
+// provider's keys when they are stale or do not include it.
+func (v *Verifier) key(ctx context.Context, p *providerKeys, keyID string) (*jose.JSONWebKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	age := time.Since(p.fetchedAt)
+	if p.keys == nil || age > keySetTTL || (len(p.keys.Key(keyID)) == 0 && age > keyRefreshInterval) {
+		keys, err := v.fetchKeys(ctx, p.Issuer)
+		if err != nil {
+			if p.keys == nil {
+				return nil, err
+			}
+			// Keep verifying with the known keys while the provider is
+			// unreachable.
+		} else {
+			p.keys, p.fetchedAt = keys, time.Now()
+		}
+	}
+
+	for _, key := range p.keys.Key(keyID) {
+		if key.Use == "" || key.Use == "sig" {
+			return &key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidToken, keyID)
+}
+
+// fetchKeys reads the JSON Web Key Set named by the discovery document of
+// an issuer.
+func (v *Verifier) fetchKeys(ctx context.Context, issuer string) (*jose.JSONWebKeySet, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("failed to read OpenID configuration of %s: %v", issuer, err)
+	}
+	if discovery.JWKSURI == "" || strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("invalid OpenID configuration of %s", issuer)
+	}
+
+	var keys jose.JSONWebKeySet
+	if err := v.getJSON(ctx, discovery.JWKSURI, &keys); err != nil {
+		return nil, fmt.Errorf("failed to read signing keys of %s: %v", issuer, err)
+	}
+	return &keys, nil
+}
+
+func (v *Verifier) getJSON(ctx context.Context, url string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(dest)
+}
@@ -0,0 +1,308 @@
+// Command smoketest checks a running deployment end to end: health, a law
+// search, an EPUB request polled to completion, and the download of the
+// EPUB with structural checks. It exits with status 1 when a step fails,
+// for use after a deployment.
+//
+//	go run ./cmd/smoketest -url https://api.example.com
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+type options struct {
+	baseURL string
+	lawID   string
+	apiKey  string
+	timeout time.Duration
+	poll    time.Duration
+	output  string
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.baseURL, "url", os.Getenv("SMOKETEST_URL"), "Base URL of the deployment (default: SMOKETEST_URL)")
+	flag.StringVar(&opts.lawID, "law-id", "321CONSTITUTION", "Law ID to search for and request the EPUB of")
+	flag.StringVar(&opts.apiKey, "api-key", os.Getenv("SMOKETEST_API_KEY"), "X-API-Key sent with every request (default: SMOKETEST_API_KEY)")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Minute, "Time allowed for the whole test, including EPUB generation")
+	flag.DurationVar(&opts.poll, "poll", 5*time.Second, "Interval between EPUB status checks")
+	flag.StringVar(&opts.output, "output", "", "Path to save the downloaded EPUB to")
+	flag.Parse()
+	if opts.baseURL == "" {
+		fmt.Fprintln(os.Stderr, "smoketest: -url or SMOKETEST_URL is required")
+		os.Exit(2)
+	}
+	opts.baseURL = strings.TrimSuffix(opts.baseURL, "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	if err := run(ctx, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "FAIL %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("PASS")
+}
+
+// tester runs the steps against one deployment.
+type tester struct {
+	opts   options
+	client *http.Client
+}
+
+func run(ctx context.Context, opts options) error {
+	t := &tester{opts: opts, client: &http.Client{Timeout: time.Minute}}
+
+	if err := step("health", func() error { return t.checkHealth(ctx) }); err != nil {
+		return err
+	}
+	var revisionID string
+	if err := step("search", func() (err error) {
+		revisionID, err = t.search(ctx)
+		return err
+	}); err != nil {
+		return err
+	}
+	var epub *epubStatus
+	if err := step("epub", func() (err error) {
+		epub, err = t.waitForEpub(ctx, revisionID)
+		return err
+	}); err != nil {
+		return err
+	}
+	var data []byte
+	if err := step("download", func() (err error) {
+		data, err = t.download(ctx, epub)
+		return err
+	}); err != nil {
+		return err
+	}
+	return step("validate", func() error {
+		if opts.output != "" {
+			if err := os.WriteFile(opts.output, data, 0o644); err != nil {
+				return fmt.Errorf("failed to save EPUB: %v", err)
+			}
+		}
+		summary, err := lawdata.CheckEPUB(data)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("     EPUB %s, %d manifest items, %d spine items\n", summary.Version, summary.ManifestItems, summary.SpineItems)
+		return nil
+	})
+}
+
+// step runs a step and reports its outcome and duration.
+func step(name string, fn func() error) error {
+	start := time.Now()
+	if err := fn(); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	fmt.Printf("ok   %-9s %v\n", name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+func (t *tester) checkHealth(ctx context.Context) error {
+	body, err := t.get(ctx, t.opts.baseURL+"/health")
+	if err != nil {
+		return err
+	}
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &health); err != nil {
+		return fmt.Errorf("failed to parse health response: %v", err)
+	}
+	if health.Status != "ok" {
+		return fmt.Errorf("status is %q", health.Status)
+	}
+	return nil
+}
+
+// search looks the law up and returns its current revision ID.
+func (t *tester) search(ctx context.Context) (string, error) {
+	var data struct {
+		Laws struct {
+			TotalCount int `json:"totalCount"`
+			Laws       []struct {
+				RevisionInfo *struct {
+					LawRevisionID string `json:"lawRevisionId"`
+					LawTitle      string `json:"lawTitle"`
+				} `json:"revisionInfo"`
+			} `json:"laws"`
+		} `json:"laws"`
+	}
+	query := `query Smoke($lawId: String) { laws(lawId: $lawId, limit: 1) { totalCount laws { revisionInfo { lawRevisionId lawTitle } } } }`
+	if err := t.graphQL(ctx, query, map[string]interface{}{"lawId": t.opts.lawID}, &data); err != nil {
+		return "", err
+	}
+	if data.Laws.TotalCount == 0 || len(data.Laws.Laws) == 0 || data.Laws.Laws[0].RevisionInfo == nil {
+		return "", fmt.Errorf("law %s was not found", t.opts.lawID)
+	}
+	revision := data.Laws.Laws[0].RevisionInfo
+	fmt.Printf("     found %s (%s)\n", revision.LawTitle, revision.LawRevisionID)
+	return revision.LawRevisionID, nil
+}
+
+type epubStatus struct {
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	SignedURL   *string `json:"signedUrl"`
+	DownloadURL *string `json:"downloadUrl"`
+	Size        *int    `json:"size"`
+	SHA256      *string `json:"sha256"`
+	Error       *string `json:"error"`
+}
+
+// waitForEpub requests the EPUB of a revision and polls it until it is
+// generated.
+func (t *tester) waitForEpub(ctx context.Context, revisionID string) (*epubStatus, error) {
+	query := `query Smoke($id: String!) { epub(id: $id) { id status signedUrl downloadUrl size sha256 error } }`
+	last := ""
+	for {
+		var data struct {
+			Epub epubStatus `json:"epub"`
+		}
+		if err := t.graphQL(ctx, query, map[string]interface{}{"id": revisionID}, &data); err != nil {
+			return nil, err
+		}
+		epub := data.Epub
+		if epub.Status != last {
+			fmt.Printf("     %s\n", epub.Status)
+			last = epub.Status
+		}
+		switch epub.Status {
+		case "COMPLETED":
+			return &epub, nil
+		case "FAILED":
+			message := "no error reported"
+			if epub.Error != nil {
+				message = *epub.Error
+			}
+			return nil, fmt.Errorf("generation failed: %s", message)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("still %s when the timeout expired", epub.Status)
+		case <-time.After(t.opts.poll):
+		}
+	}
+}
+
+// download fetches the EPUB through the download proxy, or the signed URL
+// without it, and checks its size and digest.
+func (t *tester) download(ctx context.Context, epub *epubStatus) ([]byte, error) {
+	var target string
+	switch {
+	case epub.DownloadURL != nil:
+		ref, err := url.Parse(*epub.DownloadURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid download URL: %v", err)
+		}
+		base, err := url.Parse(t.opts.baseURL + "/")
+		if err != nil {
+			return nil, fmt.Errorf("invalid base URL: %v", err)
+		}
+		target = base.ResolveReference(ref).String()
+	case epub.SignedURL != nil:
+		target = *epub.SignedURL
+	default:
+		return nil, fmt.Errorf("the completed EPUB has no download URL")
+	}
+	data, err := t.get(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if epub.Size != nil && *epub.Size != len(data) {
+		return nil, fmt.Errorf("downloaded %d bytes, but the EPUB is %d bytes", len(data), *epub.Size)
+	}
+	if epub.SHA256 != nil {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != *epub.SHA256 {
+			return nil, fmt.Errorf("SHA-256 is %s, but %s was recorded", got, *epub.SHA256)
+		}
+	}
+	fmt.Printf("     %d bytes\n", len(data))
+	return data, nil
+}
+
+func (t *tester) graphQL(ctx context.Context, query string, variables map[string]interface{}, data interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.opts.baseURL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	respBody, err := t.do(req)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("failed to parse GraphQL response: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("GraphQL error: %s", resp.Errors[0].Message)
+	}
+	if err := json.Unmarshal(resp.Data, data); err != nil {
+		return fmt.Errorf("failed to parse GraphQL data: %v", err)
+	}
+	return nil
+}
+
+func (t *tester) get(ctx context.Context, target string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	return t.do(req)
+}
+
+// do sends a request, with the API key to the deployment, and returns the
+// body of a successful response.
+func (t *tester) do(req *http.Request) ([]byte, error) {
+	if t.opts.apiKey != "" && strings.HasPrefix(req.URL.String(), t.opts.baseURL+"/") {
+		req.Header.Set("X-API-Key", t.opts.apiKey)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s failed: %v", req.Method, req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response of %s: %v", req.URL.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL.Path, resp.Status, truncate(string(body), 200))
+	}
+	return body, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
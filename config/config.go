@@ -0,0 +1,1125 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"go.ngs.io/jplaw2epub-web-api/naming"
+)
+
+// Config holds all server settings. Values are resolved in increasing order
+// of precedence from defaults, an optional YAML file, environment
+// variables, and command-line flags.
+type Config struct {
+	// Port is 0 when an available port should be chosen and logged.
+	Port string `yaml:"port"`
+	// Listen replaces Port with unix:/path.sock for a Unix domain socket,
+	// systemd for socket activation, or host:port. Sockets passed by
+	// systemd are used without it.
+	Listen string `yaml:"listen"`
+	// GRPCPort enables the gRPC API on a second port when set.
+	GRPCPort         string   `yaml:"grpcPort"`
+	CORSOrigins      []string `yaml:"corsOrigins"`
+	DisableAccessLog bool     `yaml:"disableAccessLog"`
+	// CORSRoutes override the preflight options of routes by their
+	// pattern, such as "/graphql" or "/epubs/{id}".
+	CORSRoutes map[string]CORSRoute `yaml:"corsRoutes"`
+
+	AccessLog AccessLog `yaml:"accessLog"`
+
+	TLS TLS `yaml:"tls"`
+
+	// TrustedProxies are the CIDRs or addresses of proxies whose
+	// X-Forwarded-For and X-Real-IP headers identify the client.
+	TrustedProxies []string `yaml:"trustedProxies"`
+
+	ProjectID  string `yaml:"projectId"`
+	Region     string `yaml:"region"`
+	BucketName string `yaml:"bucketName"`
+	JobName    string `yaml:"jobName"`
+	// JobVersions maps older converter versions, such as v0.9.0, to the
+	// Cloud Run Jobs running their generator images, so that clients can
+	// pin them. EPUBs of other older versions are only served while they
+	// remain in the bucket.
+	JobVersions map[string]string `yaml:"jobVersions"`
+	// FilenameTemplate names downloaded EPUBs, such as
+	// {lawTitle}_{era}{year}_{revisionId}.epub; see naming.Parse.
+	FilenameTemplate string `yaml:"filenameTemplate"`
+
+	JobStore           string `yaml:"jobStore"`
+	JobStoreCollection string `yaml:"jobStoreCollection"`
+	// PresetCollection is the Firestore collection of converter presets,
+	// which use the JobStore backend.
+	PresetCollection string `yaml:"presetCollection"`
+	// LibraryCollection is the Firestore collection of users' bookmarks and
+	// saved searches, which use the JobStore backend.
+	LibraryCollection string `yaml:"libraryCollection"`
+
+	Retry Retry `yaml:"retry"`
+
+	Dispatch Dispatch `yaml:"dispatch"`
+
+	Canary Canary `yaml:"canary"`
+
+	LawCache LawCache `yaml:"lawCache"`
+
+	LawIndex LawIndex `yaml:"lawIndex"`
+
+	Upstream Upstream `yaml:"upstream"`
+
+	WarmUp WarmUp `yaml:"warmUp"`
+
+	Revalidate Revalidate `yaml:"revalidate"`
+
+	Cleanup Cleanup `yaml:"cleanup"`
+
+	// AdminToken enables admin-only GraphQL queries for requests sending it
+	// as a bearer token.
+	AdminToken string `yaml:"adminToken"`
+
+	// AuditLog selects the audit sink: stdout or none.
+	AuditLog string `yaml:"auditLog"`
+
+	GraphQL GraphQL `yaml:"graphql"`
+
+	Converter Converter `yaml:"converter"`
+
+	Quota Quota `yaml:"quota"`
+
+	Tenants Tenants `yaml:"tenants"`
+
+	// TranslationsFile is a CSV table of English law titles with the
+	// columns lawId, lawNum, and titleEn.
+	TranslationsFile string `yaml:"translationsFile"`
+
+	Furigana Furigana `yaml:"furigana"`
+
+	OIDC OIDC `yaml:"oidc"`
+
+	Mail Mail `yaml:"mail"`
+
+	Webhook Webhook `yaml:"webhook"`
+
+	// NotifyInterval is how often generations awaited by an email or a
+	// callback are checked for completion.
+	NotifyInterval time.Duration `yaml:"notifyInterval"`
+}
+
+// TLS configures HTTPS for deployments without a proxy that terminates TLS.
+// Without a certificate the server speaks plain HTTP.
+type TLS struct {
+	// CertFile and KeyFile are a PEM certificate chain and its private key.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+	// AutocertDomains obtains certificates for the host names from Let's
+	// Encrypt instead, which must reach the server on port 443.
+	AutocertDomains []string `yaml:"autocertDomains"`
+	// AutocertCacheDir keeps obtained certificates across restarts.
+	AutocertCacheDir string `yaml:"autocertCacheDir"`
+	// AutocertEmail is the contact address of the ACME account.
+	AutocertEmail string `yaml:"autocertEmail"`
+	// H2C accepts HTTP/2 without TLS, for proxies that forward HTTP/2 in
+	// cleartext. HTTPS always offers HTTP/2.
+	H2C bool `yaml:"h2c"`
+}
+
+// Retry configures automatic re-triggering of failed generations.
+type Retry struct {
+	MaxAttempts int           `yaml:"maxAttempts"`
+	Backoff     time.Duration `yaml:"backoff"`
+	MaxBackoff  time.Duration `yaml:"maxBackoff"`
+}
+
+// Dispatch caps the generator executions of all instances. Requests beyond
+// the cap wait in a queue and are started by priority.
+type Dispatch struct {
+	// Concurrency is the number of generations run at once; 0 starts every
+	// generation right away.
+	Concurrency int `yaml:"concurrency"`
+	// Interval is how often executing generations are counted and checked
+	// for completion to free their slots.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Canary routes a share of new generations to the generator of an upcoming
+// converter version, so that its failure rate, EPUB sizes, and durations
+// can be compared with the current generator before it is rolled out.
+type Canary struct {
+	// Version is the converter version of the canary generator, such as
+	// v1.1.0.
+	Version string `yaml:"version"`
+	// JobName is the Cloud Run Job running the canary generator image.
+	JobName string `yaml:"jobName"`
+	// Percent of new generations run by the canary; 0 disables it.
+	Percent int `yaml:"percent"`
+}
+
+// LawCache configures caching of e-Gov law-list and keyword search
+// responses, and of the law bodies read by searchInLaw, tableOfContents,
+// and cite. A zero TTL disables the cache.
+type LawCache struct {
+	// TTL is how long a response is served without refreshing.
+	TTL time.Duration `yaml:"ttl"`
+	// StaleTTL is how much longer a response is served while it is
+	// refreshed in the background.
+	StaleTTL time.Duration `yaml:"staleTtl"`
+	// Size is the maximum number of cached responses per endpoint.
+	Size int `yaml:"size"`
+	// BodySize is the maximum number of cached law bodies, which are much
+	// larger than list responses.
+	BodySize int `yaml:"bodySize"`
+}
+
+// LawIndex configures the in-memory index of law titles that serves
+// suggestLaws.
+type LawIndex struct {
+	// Interval is how often the index is rebuilt from the e-Gov law list.
+	// Zero disables the index.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Upstream configures the e-Gov API and the tracking of calls to it against
+// its rate limit.
+type Upstream struct {
+	// Mode is egov for the API at BaseURL, mock for an embedded server
+	// with canned fixtures, for development without network access, record
+	// to also save the responses of BaseURL in Fixtures, or replay to serve
+	// the responses saved there.
+	Mode    string `yaml:"mode"`
+	BaseURL string `yaml:"baseUrl"`
+	// Fixtures is the directory of recorded responses.
+	Fixtures string `yaml:"fixtures"`
+	// RateLimit is the number of requests allowed per RateWindow. Zero
+	// records calls without estimating the remaining budget.
+	RateLimit  int           `yaml:"rateLimit"`
+	RateWindow time.Duration `yaml:"rateWindow"`
+	// PageConcurrency is the number of pages of one multi-page listing
+	// fetched at once.
+	PageConcurrency int `yaml:"pageConcurrency"`
+}
+
+// WarmUp configures pre-generation of popular EPUBs.
+type WarmUp struct {
+	// LawIDs are law IDs, law numbers, or revision IDs to keep generated.
+	LawIDs []string `yaml:"lawIds"`
+	// TopN adds the most requested EPUBs from the job store.
+	TopN int `yaml:"topN"`
+	// Interval runs a warm-up periodically when positive. Deployments that
+	// scale to zero should call POST /admin/warmup from Cloud Scheduler
+	// instead.
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Revalidate configures the detection of EPUBs outdated by amendments.
+type Revalidate struct {
+	// Interval runs a revalidation periodically when positive. Deployments
+	// that scale to zero should call POST /admin/revalidate from Cloud
+	// Scheduler instead.
+	Interval time.Duration `yaml:"interval"`
+	// Lookback is the update window checked when no earlier run is known.
+	Lookback time.Duration `yaml:"lookback"`
+	// Regenerate replaces stale EPUBs immediately instead of on their next
+	// request.
+	Regenerate bool `yaml:"regenerate"`
+}
+
+// Cleanup configures the deletion of generated artifacts that are no
+// longer needed.
+type Cleanup struct {
+	// Interval runs a cleanup periodically when positive. Deployments that
+	// scale to zero should call POST /admin/cleanup from Cloud Scheduler
+	// instead.
+	Interval time.Duration `yaml:"interval"`
+	// MaxIdle deletes generated EPUBs not requested for this long.
+	MaxIdle time.Duration `yaml:"maxIdle"`
+	// StatusMaxAge deletes the status files and job records of jobs without
+	// an EPUB, such as failed ones, not updated for this long.
+	StatusMaxAge time.Duration `yaml:"statusMaxAge"`
+}
+
+// AccessLog configures the format and destination of access logs and
+// their GraphQL details.
+type AccessLog struct {
+	// Format is apache, json, or cloud for Cloud Logging entries.
+	Format string `yaml:"format"`
+	// Output is stdout, stderr, file:/path, syslog, syslog://host:port, or
+	// syslog+tcp://host:port. Empty sends apache lines to the standard
+	// logger and JSON to stdout.
+	Output string `yaml:"output"`
+	// MaxFileSize rotates a file output when it reaches this many bytes,
+	// keeping MaxFileBackups older files. Zero disables rotation.
+	MaxFileSize    int64 `yaml:"maxFileSize"`
+	MaxFileBackups int   `yaml:"maxFileBackups"`
+	// JSONFields renames fields of the json format; "-" omits a field.
+	JSONFields map[string]string `yaml:"jsonFields"`
+	// MaxBodySize bounds the GraphQL request bodies buffered to log their
+	// operation; larger requests are logged without details.
+	MaxBodySize int64 `yaml:"maxBodySize"`
+	// Variables logs GraphQL variable values instead of their count.
+	Variables bool `yaml:"variables"`
+	// RedactVariables replaces the logged values of variables and input
+	// fields whose names contain one of these, ignoring case.
+	RedactVariables []string `yaml:"redactVariables"`
+}
+
+// GraphQL configures the GraphQL transports.
+type GraphQL struct {
+	// WebsocketKeepAlive is the interval between keepalive messages on
+	// subscription websockets.
+	WebsocketKeepAlive time.Duration `yaml:"websocketKeepAlive"`
+	// WebsocketInitTimeout bounds the wait for connection_init.
+	WebsocketInitTimeout time.Duration `yaml:"websocketInitTimeout"`
+	// WebsocketToken, when set, must be sent as a bearer token in the
+	// connection_init payload.
+	WebsocketToken string `yaml:"websocketToken"`
+	// MaxUploadSize limits multipart request bodies in bytes.
+	MaxUploadSize int64 `yaml:"maxUploadSize"`
+	// ResponseCacheSize is the number of publicly cacheable query responses
+	// kept in memory. Zero disables the response cache.
+	ResponseCacheSize int `yaml:"responseCacheSize"`
+	// Introspection allows schema introspection queries: on, off, or admin
+	// for requests sending the admin token.
+	Introspection string `yaml:"introspection"`
+	// Playground serves the GraphiQL playground at /graphiql: on, off, or
+	// admin.
+	Playground string `yaml:"playground"`
+	// OperationAllowList checks operations against OperationManifest: off,
+	// report to log and count unlisted operations, or enforce to reject
+	// them.
+	OperationAllowList string `yaml:"operationAllowList"`
+	// SlowQueryThreshold logs queries and mutations taking at least this
+	// long with their slowest resolvers. Zero disables timing.
+	SlowQueryThreshold time.Duration `yaml:"slowQueryThreshold"`
+	// OperationManifest is a JSON manifest of approved operations, loaded
+	// at startup.
+	OperationManifest string `yaml:"operationManifest"`
+}
+
+// Converter bounds the in-process conversions of uploads, furigana,
+// accessible, and diff EPUBs, and HTML rendering.
+type Converter struct {
+	// Workers is the number of conversions running at once.
+	Workers int `yaml:"workers"`
+	// MemoryLimit is the memory in bytes shared by running conversions,
+	// which is estimated from the size of each document.
+	MemoryLimit int64 `yaml:"memoryLimit"`
+	// QueueWait is how long a conversion waits for a worker before the
+	// request is answered with 503 Service Unavailable.
+	QueueWait time.Duration `yaml:"queueWait"`
+	// Timeout abandons a conversion that runs longer.
+	Timeout time.Duration `yaml:"timeout"`
+	// FontDir is a local directory or gs://bucket/prefix location of the
+	// fonts that converted EPUBs can embed, loaded at startup.
+	FontDir string `yaml:"fontDir"`
+}
+
+// Quota configures per-client request quotas. A zero limit disables that
+// window.
+type Quota struct {
+	Daily   int64 `yaml:"daily"`
+	Monthly int64 `yaml:"monthly"`
+	// Store is memory or firestore; firestore shares counters between
+	// instances.
+	Store      string `yaml:"store"`
+	Collection string `yaml:"collection"`
+	// APIKeys are the X-API-Key values that get a quota of their own.
+	APIKeys []string `yaml:"apiKeys"`
+	// HighPriorityDaily limits the generations a tenant, user, or API key
+	// may request with HIGH priority per day; 0 disables the limit.
+	// Anonymous clients cannot request HIGH priority.
+	HighPriorityDaily int64 `yaml:"highPriorityDaily"`
+	// NotifyDaily limits the epub queries with notifyEmail that a tenant,
+	// user, or API key may send per day; 0 disables the limit. Anonymous
+	// clients cannot pass notifyEmail.
+	NotifyDaily int64 `yaml:"notifyDaily"`
+}
+
+// CORSRoute overrides the CORS options of a route. Empty fields keep the
+// options the route is registered with.
+type CORSRoute struct {
+	Methods       []string `yaml:"methods"`
+	Headers       []string `yaml:"headers"`
+	ExposeHeaders []string `yaml:"exposeHeaders"`
+	// MaxAge is how many seconds browsers may cache the preflight
+	// response.
+	MaxAge int `yaml:"maxAge"`
+}
+
+// Tenants configures multi-tenant operation, where the X-API-Key of a
+// request identifies a tenant whose documents, quotas, and usage
+// statistics are kept apart from those of other tenants.
+type Tenants struct {
+	// Store is file or firestore; empty disables tenants.
+	Store string `yaml:"store"`
+	// File is the YAML tenants definition file for the file store.
+	File       string `yaml:"file"`
+	Collection string `yaml:"collection"`
+}
+
+// OIDC configures sign-in with OpenID Connect providers, whose ID tokens
+// clients send as bearer tokens to identify the user.
+type OIDC struct {
+	// Providers lists the accepted providers; none disables sign-in.
+	Providers []OIDCProvider `yaml:"providers"`
+}
+
+// OIDCProvider is an OpenID Connect provider such as Google or Auth0.
+type OIDCProvider struct {
+	// Issuer is the iss claim of the provider's ID tokens.
+	Issuer string `yaml:"issuer"`
+	// Audiences are the accepted aud claims, such as OAuth client IDs.
+	Audiences []string `yaml:"audiences"`
+}
+
+// Mail configures email notifications of finished EPUB generations.
+type Mail struct {
+	// Provider is smtp or sendgrid; empty disables email.
+	Provider string `yaml:"provider"`
+	// From is the sender address.
+	From           string `yaml:"from"`
+	SMTP           SMTP   `yaml:"smtp"`
+	SendGridAPIKey string `yaml:"sendGridApiKey"`
+}
+
+// Webhook configures callbacks posted when EPUB generations finish.
+type Webhook struct {
+	// Secret keys the HMAC signature of callbacks; empty disables them.
+	Secret  string        `yaml:"secret"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// SMTP locates the SMTP server of the smtp mail provider.
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// Furigana configures the morphological analyzer that adds ruby readings
+// to EPUB and HTML output on request.
+type Furigana struct {
+	// Analyzer is mecab or kakasi; empty disables furigana.
+	Analyzer string `yaml:"analyzer"`
+	// Command is the analyzer executable, by default its name on PATH.
+	Command string `yaml:"command"`
+}
+
+// Default returns the settings used when nothing is configured. The bucket
+// name and project ID have no defaults and must be provided.
+func Default() *Config {
+	return &Config{
+		Port:               "8080",
+		Region:             "asia-northeast1",
+		JobName:            "epub-generator",
+		JobStore:           "bucket",
+		JobStoreCollection: "epubJobs",
+		PresetCollection:   "epubPresets",
+		LibraryCollection:  "libraries",
+		AuditLog:           "stdout",
+		TLS: TLS{
+			AutocertCacheDir: "autocert-cache",
+		},
+		// Loopback, private, and link-local networks, where Cloud Run's
+		// front end and local reverse proxies connect from.
+		TrustedProxies: []string{"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "169.254.0.0/16", "fc00::/7", "fe80::/10"},
+		Retry: Retry{
+			MaxAttempts: 3,
+			Backoff:     time.Minute,
+			MaxBackoff:  30 * time.Minute,
+		},
+		Dispatch: Dispatch{
+			Interval: 10 * time.Second,
+		},
+		LawCache: LawCache{
+			TTL:      5 * time.Minute,
+			StaleTTL: time.Hour,
+			Size:     1000,
+			BodySize: 50,
+		},
+		LawIndex: LawIndex{
+			Interval: 24 * time.Hour,
+		},
+		Upstream: Upstream{
+			Mode:            "egov",
+			BaseURL:         "https://laws.e-gov.go.jp/api/2",
+			RateLimit:       1000,
+			RateWindow:      time.Hour,
+			PageConcurrency: 4,
+		},
+		Revalidate: Revalidate{
+			Lookback: 48 * time.Hour,
+		},
+		Cleanup: Cleanup{
+			MaxIdle:      90 * 24 * time.Hour,
+			StatusMaxAge: 30 * 24 * time.Hour,
+		},
+		AccessLog: AccessLog{
+			Format:          "apache",
+			MaxFileSize:     100 << 20,
+			MaxFileBackups:  5,
+			MaxBodySize:     64 << 10,
+			RedactVariables: []string{"token", "password", "secret", "authorization", "apiKey", "email"},
+		},
+		GraphQL: GraphQL{
+			WebsocketKeepAlive:   10 * time.Second,
+			WebsocketInitTimeout: 30 * time.Second,
+			MaxUploadSize:        32 << 20,
+			Introspection:        "on",
+			Playground:           "on",
+			OperationAllowList:   "off",
+			SlowQueryThreshold:   time.Second,
+		},
+		Converter: Converter{
+			Workers:     4,
+			MemoryLimit: 1 << 30,
+			QueueWait:   5 * time.Second,
+			Timeout:     time.Minute,
+		},
+		Quota: Quota{
+			Store:             "memory",
+			Collection:        "quotas",
+			HighPriorityDaily: 10,
+			NotifyDaily:       20,
+		},
+		Tenants: Tenants{
+			Collection: "tenants",
+		},
+		Mail: Mail{
+			SMTP: SMTP{Port: 587},
+		},
+		Webhook: Webhook{
+			Timeout: 10 * time.Second,
+		},
+		NotifyInterval: time.Minute,
+	}
+}
+
+// Load parses command-line arguments (without the program name) and
+// resolves the configuration. The YAML file is read from -config or
+// CONFIG_FILE when set.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("jplaw2epub-api", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "Path to a YAML configuration file")
+	port := fs.String("port", "", "Port to listen on, or 0 for an available port (default: 8080)")
+	listen := fs.String("listen", "", "Address to listen on instead of -port: unix:/path.sock, systemd, or host:port")
+	grpcPort := fs.String("grpc-port", "", "Port for the gRPC API (default: disabled)")
+	corsOrigins := fs.String("cors-origins", "", "Comma-separated list of allowed CORS origins (e.g., 'https://example.com,https://app.example.com')")
+	disableAccessLog := fs.Bool("disable-access-log", false, "Disable Apache format access logging")
+	tlsCert := fs.String("tls-cert", "", "PEM certificate file for HTTPS (default: plain HTTP)")
+	tlsKey := fs.String("tls-key", "", "PEM private key file for HTTPS")
+	upstreamMode := fs.String("upstream", "", "e-Gov API to use: egov, mock, record, or replay (default: egov)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Port = *port
+		case "listen":
+			cfg.Listen = *listen
+		case "grpc-port":
+			cfg.GRPCPort = *grpcPort
+		case "cors-origins":
+			cfg.CORSOrigins = splitList(*corsOrigins)
+		case "disable-access-log":
+			cfg.DisableAccessLog = *disableAccessLog
+		case "tls-cert":
+			cfg.TLS.CertFile = *tlsCert
+		case "tls-key":
+			cfg.TLS.KeyFile = *tlsKey
+		case "upstream":
+			cfg.Upstream.Mode = *upstreamMode
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	stringVars := map[string]*string{
+		"PORT":                        &c.Port,
+		"LISTEN_ADDRESS":              &c.Listen,
+		"UPSTREAM_MODE":               &c.Upstream.Mode,
+		"UPSTREAM_BASE_URL":           &c.Upstream.BaseURL,
+		"UPSTREAM_FIXTURES":           &c.Upstream.Fixtures,
+		"GRPC_PORT":                   &c.GRPCPort,
+		"PROJECT_ID":                  &c.ProjectID,
+		"REGION":                      &c.Region,
+		"EPUB_BUCKET_NAME":            &c.BucketName,
+		"EPUB_JOB_NAME":               &c.JobName,
+		"EPUB_CANARY_VERSION":         &c.Canary.Version,
+		"EPUB_CANARY_JOB_NAME":        &c.Canary.JobName,
+		"EPUB_FILENAME_TEMPLATE":      &c.FilenameTemplate,
+		"JOB_STORE":                   &c.JobStore,
+		"JOB_STORE_COLLECTION":        &c.JobStoreCollection,
+		"PRESET_COLLECTION":           &c.PresetCollection,
+		"LIBRARY_COLLECTION":          &c.LibraryCollection,
+		"GRAPHQL_WS_TOKEN":            &c.GraphQL.WebsocketToken,
+		"GRAPHQL_INTROSPECTION":       &c.GraphQL.Introspection,
+		"GRAPHQL_PLAYGROUND":          &c.GraphQL.Playground,
+		"GRAPHQL_OPERATION_ALLOWLIST": &c.GraphQL.OperationAllowList,
+		"GRAPHQL_OPERATION_MANIFEST":  &c.GraphQL.OperationManifest,
+		"AUDIT_LOG":                   &c.AuditLog,
+		"ACCESS_LOG_FORMAT":           &c.AccessLog.Format,
+		"ACCESS_LOG_OUTPUT":           &c.AccessLog.Output,
+		"ADMIN_TOKEN":                 &c.AdminToken,
+		"QUOTA_STORE":                 &c.Quota.Store,
+		"QUOTA_COLLECTION":            &c.Quota.Collection,
+		"TENANT_STORE":                &c.Tenants.Store,
+		"TENANTS_FILE":                &c.Tenants.File,
+		"TENANT_COLLECTION":           &c.Tenants.Collection,
+		"TRANSLATIONS_FILE":           &c.TranslationsFile,
+		"FURIGANA_ANALYZER":           &c.Furigana.Analyzer,
+		"FURIGANA_COMMAND":            &c.Furigana.Command,
+		"CONVERT_FONT_DIR":            &c.Converter.FontDir,
+		"MAIL_PROVIDER":               &c.Mail.Provider,
+		"MAIL_FROM":                   &c.Mail.From,
+		"SMTP_HOST":                   &c.Mail.SMTP.Host,
+		"SMTP_USERNAME":               &c.Mail.SMTP.Username,
+		"SMTP_PASSWORD":               &c.Mail.SMTP.Password,
+		"SENDGRID_API_KEY":            &c.Mail.SendGridAPIKey,
+		"WEBHOOK_SECRET":              &c.Webhook.Secret,
+		"TLS_CERT_FILE":               &c.TLS.CertFile,
+		"TLS_KEY_FILE":                &c.TLS.KeyFile,
+		"TLS_AUTOCERT_CACHE":          &c.TLS.AutocertCacheDir,
+		"TLS_AUTOCERT_EMAIL":          &c.TLS.AutocertEmail,
+	}
+	for name, target := range stringVars {
+		if v := os.Getenv(name); v != "" {
+			*target = v
+		}
+	}
+
+	if v := os.Getenv("ACCESS_LOG_JSON_FIELDS"); v != "" {
+		fields := make(map[string]string)
+		for _, item := range splitList(v) {
+			name, renamed, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("invalid ACCESS_LOG_JSON_FIELDS entry %q: expected field=name", item)
+			}
+			fields[strings.TrimSpace(name)] = strings.TrimSpace(renamed)
+		}
+		c.AccessLog.JSONFields = fields
+	}
+	if v := os.Getenv("EPUB_JOB_VERSIONS"); v != "" {
+		versions := make(map[string]string)
+		for _, item := range splitList(v) {
+			version, job, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("invalid EPUB_JOB_VERSIONS entry %q: expected version=job", item)
+			}
+			versions[strings.TrimSpace(version)] = strings.TrimSpace(job)
+		}
+		c.JobVersions = versions
+	}
+	if v := os.Getenv("ACCESS_LOG_REDACT_VARIABLES"); v != "" {
+		c.AccessLog.RedactVariables = splitList(v)
+	}
+	if v := os.Getenv("CORS_ORIGINS"); v != "" {
+		c.CORSOrigins = splitList(v)
+	}
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		// "none" trusts no proxy headers at all.
+		c.TrustedProxies = nil
+		if v != "none" {
+			c.TrustedProxies = splitList(v)
+		}
+	}
+	if v := os.Getenv("TLS_AUTOCERT_DOMAINS"); v != "" {
+		c.TLS.AutocertDomains = splitList(v)
+	}
+	if v := os.Getenv("QUOTA_API_KEYS"); v != "" {
+		c.Quota.APIKeys = splitList(v)
+	}
+	if v := os.Getenv("OIDC_ISSUER"); v != "" {
+		c.OIDC.Providers = append(c.OIDC.Providers, OIDCProvider{
+			Issuer:    v,
+			Audiences: splitList(os.Getenv("OIDC_AUDIENCE")),
+		})
+	}
+	if v := os.Getenv("WARMUP_LAW_IDS"); v != "" {
+		c.WarmUp.LawIDs = splitList(v)
+	}
+
+	intVars := map[string]*int{
+		"EPUB_RETRY_MAX_ATTEMPTS":     &c.Retry.MaxAttempts,
+		"EPUB_JOB_CONCURRENCY":        &c.Dispatch.Concurrency,
+		"EPUB_CANARY_PERCENT":         &c.Canary.Percent,
+		"LAW_CACHE_SIZE":              &c.LawCache.Size,
+		"LAW_CACHE_BODY_SIZE":         &c.LawCache.BodySize,
+		"UPSTREAM_RATE_LIMIT":         &c.Upstream.RateLimit,
+		"UPSTREAM_PAGE_CONCURRENCY":   &c.Upstream.PageConcurrency,
+		"WARMUP_TOP_N":                &c.WarmUp.TopN,
+		"SMTP_PORT":                   &c.Mail.SMTP.Port,
+		"CONVERT_WORKERS":             &c.Converter.Workers,
+		"ACCESS_LOG_MAX_FILE_BACKUPS": &c.AccessLog.MaxFileBackups,
+		"GRAPHQL_RESPONSE_CACHE_SIZE": &c.GraphQL.ResponseCacheSize,
+	}
+	for name, target := range intVars {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, v, err)
+		}
+		*target = n
+	}
+
+	int64Vars := map[string]*int64{
+		"ACCESS_LOG_MAX_BODY_SIZE":  &c.AccessLog.MaxBodySize,
+		"ACCESS_LOG_MAX_FILE_SIZE":  &c.AccessLog.MaxFileSize,
+		"GRAPHQL_MAX_UPLOAD_SIZE":   &c.GraphQL.MaxUploadSize,
+		"QUOTA_DAILY":               &c.Quota.Daily,
+		"QUOTA_MONTHLY":             &c.Quota.Monthly,
+		"QUOTA_HIGH_PRIORITY_DAILY": &c.Quota.HighPriorityDaily,
+		"QUOTA_NOTIFY_DAILY":        &c.Quota.NotifyDaily,
+		"CONVERT_MEMORY_LIMIT":      &c.Converter.MemoryLimit,
+	}
+	for name, target := range int64Vars {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, v, err)
+		}
+		*target = n
+	}
+
+	boolVars := map[string]*bool{
+		"REVALIDATE_REGENERATE": &c.Revalidate.Regenerate,
+		"H2C":                   &c.TLS.H2C,
+		"ACCESS_LOG_VARIABLES":  &c.AccessLog.Variables,
+	}
+	for name, target := range boolVars {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, v, err)
+		}
+		*target = b
+	}
+
+	durationVars := map[string]*time.Duration{
+		"EPUB_RETRY_BACKOFF":           &c.Retry.Backoff,
+		"EPUB_RETRY_MAX_BACKOFF":       &c.Retry.MaxBackoff,
+		"EPUB_DISPATCH_INTERVAL":       &c.Dispatch.Interval,
+		"GRAPHQL_WS_KEEPALIVE":         &c.GraphQL.WebsocketKeepAlive,
+		"GRAPHQL_WS_INIT_TIMEOUT":      &c.GraphQL.WebsocketInitTimeout,
+		"GRAPHQL_SLOW_QUERY_THRESHOLD": &c.GraphQL.SlowQueryThreshold,
+		"LAW_CACHE_TTL":                &c.LawCache.TTL,
+		"LAW_CACHE_STALE_TTL":          &c.LawCache.StaleTTL,
+		"LAW_INDEX_INTERVAL":           &c.LawIndex.Interval,
+		"UPSTREAM_RATE_WINDOW":         &c.Upstream.RateWindow,
+		"WARMUP_INTERVAL":              &c.WarmUp.Interval,
+		"REVALIDATE_INTERVAL":          &c.Revalidate.Interval,
+		"REVALIDATE_LOOKBACK":          &c.Revalidate.Lookback,
+		"CLEANUP_INTERVAL":             &c.Cleanup.Interval,
+		"CLEANUP_MAX_IDLE":             &c.Cleanup.MaxIdle,
+		"CLEANUP_STATUS_MAX_AGE":       &c.Cleanup.StatusMaxAge,
+		"WEBHOOK_TIMEOUT":              &c.Webhook.Timeout,
+		"NOTIFY_INTERVAL":              &c.NotifyInterval,
+		"CONVERT_QUEUE_WAIT":           &c.Converter.QueueWait,
+		"CONVERT_TIMEOUT":              &c.Converter.Timeout,
+	}
+	for name, target := range durationVars {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %v", name, v, err)
+		}
+		*target = d
+	}
+
+	return nil
+}
+
+// validateAccessMode checks a setting that is on, off, or admin; admin
+// needs an admin token to compare requests with.
+func validateAccessMode(name, mode, adminToken string) error {
+	switch mode {
+	case "on", "off":
+		return nil
+	case "admin":
+		if adminToken == "" {
+			return fmt.Errorf("%s=admin requires ADMIN_TOKEN", name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%s must be on, off, or admin, got %q", name, mode)
+	}
+}
+
+// Validate reports every missing or invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	for pattern, route := range c.CORSRoutes {
+		if route.MaxAge < 0 {
+			errs = append(errs, fmt.Errorf("corsRoutes[%q].maxAge must not be negative, got %d", pattern, route.MaxAge))
+		}
+	}
+
+	switch c.JobStore {
+	case "bucket", "firestore":
+		// EPUB generation needs the bucket and the Cloud Run Job project.
+		if c.BucketName == "" {
+			errs = append(errs, errors.New("EPUB_BUCKET_NAME is required (set JOB_STORE=memory for local development)"))
+		}
+		if c.ProjectID == "" {
+			errs = append(errs, errors.New("PROJECT_ID is required (set JOB_STORE=memory for local development)"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("JOB_STORE must be bucket, firestore, or memory, got %q", c.JobStore))
+	}
+
+	if c.Listen == "" && c.Port != "0" && !validPort(c.Port) {
+		errs = append(errs, fmt.Errorf("PORT must be 0 or a number between 1 and 65535, got %q", c.Port))
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, err := netip.ParsePrefix(proxy); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES must list CIDRs or IP addresses, got %q", proxy))
+		}
+	}
+	if c.Listen == "unix:" {
+		errs = append(errs, errors.New("LISTEN_ADDRESS must name a socket path after unix:"))
+	}
+	if c.GRPCPort != "" {
+		if !validPort(c.GRPCPort) {
+			errs = append(errs, fmt.Errorf("GRPC_PORT must be a number between 1 and 65535, got %q", c.GRPCPort))
+		} else if c.GRPCPort == c.Port {
+			errs = append(errs, fmt.Errorf("GRPC_PORT must differ from PORT, both are %q", c.Port))
+		}
+	}
+	if c.Region == "" {
+		errs = append(errs, errors.New("REGION must not be empty"))
+	}
+	if c.JobName == "" {
+		errs = append(errs, errors.New("EPUB_JOB_NAME must not be empty"))
+	}
+	for version, job := range c.JobVersions {
+		if !converterVersion.MatchString(version) {
+			errs = append(errs, fmt.Errorf("EPUB_JOB_VERSIONS: %q is not a version such as v1.0.0", version))
+		}
+		if job == "" {
+			errs = append(errs, fmt.Errorf("EPUB_JOB_VERSIONS: %s has no job name", version))
+		}
+	}
+	if c.JobStoreCollection == "" {
+		errs = append(errs, errors.New("JOB_STORE_COLLECTION must not be empty"))
+	}
+	if c.PresetCollection == "" {
+		errs = append(errs, errors.New("PRESET_COLLECTION must not be empty"))
+	}
+	if c.LibraryCollection == "" {
+		errs = append(errs, errors.New("LIBRARY_COLLECTION must not be empty"))
+	}
+	if c.Retry.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("EPUB_RETRY_MAX_ATTEMPTS must be at least 1, got %d", c.Retry.MaxAttempts))
+	}
+	if c.Retry.Backoff <= 0 {
+		errs = append(errs, fmt.Errorf("EPUB_RETRY_BACKOFF must be positive, got %v", c.Retry.Backoff))
+	}
+	if c.Retry.MaxBackoff < c.Retry.Backoff {
+		errs = append(errs, fmt.Errorf("EPUB_RETRY_MAX_BACKOFF must not be less than EPUB_RETRY_BACKOFF, got %v", c.Retry.MaxBackoff))
+	}
+	if c.Dispatch.Concurrency < 0 {
+		errs = append(errs, fmt.Errorf("EPUB_JOB_CONCURRENCY must not be negative, got %d", c.Dispatch.Concurrency))
+	}
+	if c.Dispatch.Concurrency > 0 && c.Dispatch.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("EPUB_DISPATCH_INTERVAL must be positive, got %v", c.Dispatch.Interval))
+	}
+	if c.Canary.Percent < 0 || c.Canary.Percent > 100 {
+		errs = append(errs, fmt.Errorf("EPUB_CANARY_PERCENT must be between 0 and 100, got %d", c.Canary.Percent))
+	}
+	if c.Canary.Percent > 0 {
+		if !converterVersion.MatchString(c.Canary.Version) {
+			errs = append(errs, fmt.Errorf("EPUB_CANARY_VERSION must be a version such as v1.0.0, got %q", c.Canary.Version))
+		}
+		if c.Canary.JobName == "" {
+			errs = append(errs, errors.New("EPUB_CANARY_JOB_NAME must not be empty when EPUB_CANARY_PERCENT is set"))
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if c.TLS.CertFile != "" && len(c.TLS.AutocertDomains) > 0 {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_AUTOCERT_DOMAINS cannot be combined"))
+	}
+	if len(c.TLS.AutocertDomains) > 0 && c.TLS.AutocertCacheDir == "" {
+		errs = append(errs, errors.New("TLS_AUTOCERT_CACHE must not be empty"))
+	}
+	if c.TLS.H2C && (c.TLS.CertFile != "" || len(c.TLS.AutocertDomains) > 0) {
+		errs = append(errs, errors.New("H2C applies to plain HTTP only; HTTPS always offers HTTP/2"))
+	}
+	if c.LawCache.TTL < 0 {
+		errs = append(errs, fmt.Errorf("LAW_CACHE_TTL must not be negative, got %v", c.LawCache.TTL))
+	}
+	if c.LawCache.StaleTTL < 0 {
+		errs = append(errs, fmt.Errorf("LAW_CACHE_STALE_TTL must not be negative, got %v", c.LawCache.StaleTTL))
+	}
+	if c.LawCache.Size < 1 {
+		errs = append(errs, fmt.Errorf("LAW_CACHE_SIZE must be at least 1, got %d", c.LawCache.Size))
+	}
+	if c.LawCache.BodySize < 1 {
+		errs = append(errs, fmt.Errorf("LAW_CACHE_BODY_SIZE must be at least 1, got %d", c.LawCache.BodySize))
+	}
+	switch c.Upstream.Mode {
+	case "egov", "mock":
+	case "record", "replay":
+		if c.Upstream.Fixtures == "" {
+			errs = append(errs, fmt.Errorf("UPSTREAM_FIXTURES is required when UPSTREAM_MODE is %s", c.Upstream.Mode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("UPSTREAM_MODE must be egov, mock, record, or replay, got %q", c.Upstream.Mode))
+	}
+	if u, err := url.Parse(c.Upstream.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("UPSTREAM_BASE_URL must be an http or https URL, got %q", c.Upstream.BaseURL))
+	}
+	if c.Upstream.RateLimit < 0 {
+		errs = append(errs, fmt.Errorf("UPSTREAM_RATE_LIMIT must not be negative, got %d", c.Upstream.RateLimit))
+	}
+	if c.Upstream.RateLimit > 0 && c.Upstream.RateWindow <= 0 {
+		errs = append(errs, fmt.Errorf("UPSTREAM_RATE_WINDOW must be positive, got %v", c.Upstream.RateWindow))
+	}
+	if c.Upstream.PageConcurrency < 1 {
+		errs = append(errs, fmt.Errorf("UPSTREAM_PAGE_CONCURRENCY must be at least 1, got %d", c.Upstream.PageConcurrency))
+	}
+	if c.WarmUp.TopN < 0 {
+		errs = append(errs, fmt.Errorf("WARMUP_TOP_N must not be negative, got %d", c.WarmUp.TopN))
+	}
+	if c.WarmUp.Interval < 0 {
+		errs = append(errs, fmt.Errorf("WARMUP_INTERVAL must not be negative, got %v", c.WarmUp.Interval))
+	}
+	if c.Revalidate.Interval < 0 {
+		errs = append(errs, fmt.Errorf("REVALIDATE_INTERVAL must not be negative, got %v", c.Revalidate.Interval))
+	}
+	if c.Revalidate.Lookback <= 0 {
+		errs = append(errs, fmt.Errorf("REVALIDATE_LOOKBACK must be positive, got %v", c.Revalidate.Lookback))
+	}
+	if c.Cleanup.Interval < 0 {
+		errs = append(errs, fmt.Errorf("CLEANUP_INTERVAL must not be negative, got %v", c.Cleanup.Interval))
+	}
+	if c.Cleanup.MaxIdle <= 0 {
+		errs = append(errs, fmt.Errorf("CLEANUP_MAX_IDLE must be positive, got %v", c.Cleanup.MaxIdle))
+	}
+	if c.Cleanup.StatusMaxAge <= 0 {
+		errs = append(errs, fmt.Errorf("CLEANUP_STATUS_MAX_AGE must be positive, got %v", c.Cleanup.StatusMaxAge))
+	}
+	if _, err := naming.Parse(c.FilenameTemplate); err != nil {
+		errs = append(errs, fmt.Errorf("EPUB_FILENAME_TEMPLATE: %v", err))
+	}
+	if c.AuditLog != "stdout" && c.AuditLog != "none" {
+		errs = append(errs, fmt.Errorf("AUDIT_LOG must be stdout or none, got %q", c.AuditLog))
+	}
+	switch c.AccessLog.Format {
+	case "apache", "json", "cloud":
+	default:
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_FORMAT must be apache, json, or cloud, got %q", c.AccessLog.Format))
+	}
+	if c.AccessLog.MaxFileSize < 0 {
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_MAX_FILE_SIZE must not be negative, got %d", c.AccessLog.MaxFileSize))
+	}
+	if c.AccessLog.MaxFileBackups < 0 {
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_MAX_FILE_BACKUPS must not be negative, got %d", c.AccessLog.MaxFileBackups))
+	}
+	if c.AccessLog.MaxBodySize < 0 {
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_MAX_BODY_SIZE must not be negative, got %d", c.AccessLog.MaxBodySize))
+	}
+	if c.GraphQL.WebsocketKeepAlive < 0 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_WS_KEEPALIVE must not be negative, got %v", c.GraphQL.WebsocketKeepAlive))
+	}
+	if c.GraphQL.WebsocketInitTimeout < 0 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_WS_INIT_TIMEOUT must not be negative, got %v", c.GraphQL.WebsocketInitTimeout))
+	}
+	if c.GraphQL.MaxUploadSize < 1 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_MAX_UPLOAD_SIZE must be positive, got %d", c.GraphQL.MaxUploadSize))
+	}
+	if c.GraphQL.SlowQueryThreshold < 0 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_SLOW_QUERY_THRESHOLD must not be negative, got %v", c.GraphQL.SlowQueryThreshold))
+	}
+	if c.GraphQL.ResponseCacheSize < 0 {
+		errs = append(errs, fmt.Errorf("GRAPHQL_RESPONSE_CACHE_SIZE must not be negative, got %d", c.GraphQL.ResponseCacheSize))
+	}
+	if err := validateAccessMode("GRAPHQL_INTROSPECTION", c.GraphQL.Introspection, c.AdminToken); err != nil {
+		errs = append(errs, err)
+	}
+	if err := validateAccessMode("GRAPHQL_PLAYGROUND", c.GraphQL.Playground, c.AdminToken); err != nil {
+		errs = append(errs, err)
+	}
+	switch c.GraphQL.OperationAllowList {
+	case "off":
+	case "report", "enforce":
+		if c.GraphQL.OperationManifest == "" {
+			errs = append(errs, fmt.Errorf("GRAPHQL_OPERATION_ALLOWLIST=%s requires GRAPHQL_OPERATION_MANIFEST", c.GraphQL.OperationAllowList))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("GRAPHQL_OPERATION_ALLOWLIST must be off, report, or enforce, got %q", c.GraphQL.OperationAllowList))
+	}
+	if c.Converter.Workers < 1 {
+		errs = append(errs, fmt.Errorf("CONVERT_WORKERS must be at least 1, got %d", c.Converter.Workers))
+	}
+	if c.Converter.MemoryLimit < 1 {
+		errs = append(errs, fmt.Errorf("CONVERT_MEMORY_LIMIT must be positive, got %d", c.Converter.MemoryLimit))
+	}
+	if c.Converter.QueueWait < 0 {
+		errs = append(errs, fmt.Errorf("CONVERT_QUEUE_WAIT must not be negative, got %v", c.Converter.QueueWait))
+	}
+	if c.Converter.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("CONVERT_TIMEOUT must be positive, got %v", c.Converter.Timeout))
+	}
+	if c.Quota.Daily < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_DAILY must not be negative, got %d", c.Quota.Daily))
+	}
+	if c.Quota.Monthly < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_MONTHLY must not be negative, got %d", c.Quota.Monthly))
+	}
+	if c.Quota.HighPriorityDaily < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_HIGH_PRIORITY_DAILY must not be negative, got %d", c.Quota.HighPriorityDaily))
+	}
+	if c.Quota.NotifyDaily < 0 {
+		errs = append(errs, fmt.Errorf("QUOTA_NOTIFY_DAILY must not be negative, got %d", c.Quota.NotifyDaily))
+	}
+	switch c.Quota.Store {
+	case "firestore":
+		if c.ProjectID == "" {
+			errs = append(errs, errors.New("PROJECT_ID is required for QUOTA_STORE=firestore"))
+		}
+		if c.Quota.Collection == "" {
+			errs = append(errs, errors.New("QUOTA_COLLECTION must not be empty"))
+		}
+	case "memory":
+	default:
+		errs = append(errs, fmt.Errorf("QUOTA_STORE must be firestore or memory, got %q", c.Quota.Store))
+	}
+
+	switch c.Tenants.Store {
+	case "":
+	case "file":
+		if c.Tenants.File == "" {
+			errs = append(errs, errors.New("TENANTS_FILE is required for TENANT_STORE=file"))
+		}
+	case "firestore":
+		if c.ProjectID == "" {
+			errs = append(errs, errors.New("PROJECT_ID is required for TENANT_STORE=firestore"))
+		}
+		if c.Tenants.Collection == "" {
+			errs = append(errs, errors.New("TENANT_COLLECTION must not be empty"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("TENANT_STORE must be file or firestore, got %q", c.Tenants.Store))
+	}
+
+	for _, provider := range c.OIDC.Providers {
+		if !strings.HasPrefix(provider.Issuer, "https://") {
+			errs = append(errs, fmt.Errorf("OIDC_ISSUER must be an https URL, got %q", provider.Issuer))
+		}
+		if len(provider.Audiences) == 0 {
+			errs = append(errs, fmt.Errorf("OIDC_AUDIENCE is required for issuer %s", provider.Issuer))
+		}
+	}
+
+	switch c.Mail.Provider {
+	case "":
+	case "smtp":
+		if c.Mail.SMTP.Host == "" {
+			errs = append(errs, errors.New("SMTP_HOST is required for MAIL_PROVIDER=smtp"))
+		}
+		if c.Mail.SMTP.Port < 1 || c.Mail.SMTP.Port > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT must be a number between 1 and 65535, got %d", c.Mail.SMTP.Port))
+		}
+	case "sendgrid":
+		if c.Mail.SendGridAPIKey == "" {
+			errs = append(errs, errors.New("SENDGRID_API_KEY is required for MAIL_PROVIDER=sendgrid"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_PROVIDER must be smtp or sendgrid, got %q", c.Mail.Provider))
+	}
+	if c.Mail.Provider != "" && c.Mail.From == "" {
+		errs = append(errs, errors.New("MAIL_FROM is required when MAIL_PROVIDER is set"))
+	}
+	if c.Webhook.Secret != "" && c.Webhook.Timeout <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_TIMEOUT must be positive, got %v", c.Webhook.Timeout))
+	}
+	if (c.Mail.Provider != "" || c.Webhook.Secret != "") && c.NotifyInterval <= 0 {
+		errs = append(errs, fmt.Errorf("NOTIFY_INTERVAL must be positive, got %v", c.NotifyInterval))
+	}
+
+	switch c.Furigana.Analyzer {
+	case "", "mecab", "kakasi":
+	default:
+		errs = append(errs, fmt.Errorf("FURIGANA_ANALYZER must be mecab or kakasi, got %q", c.Furigana.Analyzer))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %v", errors.Join(errs...))
+	}
+	return nil
+}
+
+// converterVersion matches the versions of JobVersions.
+var converterVersion = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
@@ -0,0 +1,192 @@
+// Package fonts loads the font files that EPUBs can embed, from a local
+// directory or a Cloud Storage bucket.
+package fonts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// MaxSize is the largest font file that is loaded, in bytes.
+const MaxSize = 32 << 20
+
+// mediaTypes are the EPUB core media types of font files by extension.
+var mediaTypes = map[string]string{
+	".otf":   "font/otf",
+	".ttf":   "font/ttf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// namePattern limits font names, which become CSS family names and file
+// names in EPUBs.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+
+// Font is a font file that EPUBs can embed.
+type Font struct {
+	// Name is the file name without its extension, such as
+	// NotoSerifJP-Regular, by which requests select the font.
+	Name string
+	// File is the file name, such as NotoSerifJP-Regular.otf.
+	File      string
+	MediaType string
+	Data      []byte
+	// Sum is the hex SHA-256 digest of Data, which tells versions of a
+	// font apart in cache keys.
+	Sum string
+}
+
+// Library holds the fonts that EPUBs can embed. A nil or empty library has
+// none.
+type Library struct {
+	byName map[string]*Font
+}
+
+// Load reads the OpenType, TrueType, and WOFF fonts in dir, a local
+// directory or a gs://bucket/prefix location. Files of other types are
+// skipped. An empty dir returns an empty library.
+func Load(ctx context.Context, dir string) (*Library, error) {
+	switch {
+	case dir == "":
+		return &Library{}, nil
+	case strings.HasPrefix(dir, "gs://"):
+		return loadBucket(ctx, strings.TrimPrefix(dir, "gs://"))
+	default:
+		return loadDir(dir)
+	}
+}
+
+func loadDir(dir string) (*Library, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read font directory: %v", err)
+	}
+	lib := &Library{byName: make(map[string]*Font)}
+	for _, entry := range entries {
+		if entry.IsDir() || mediaTypes[strings.ToLower(filepath.Ext(entry.Name()))] == "" {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open font: %v", err)
+		}
+		err = lib.add(entry.Name(), f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lib, nil
+}
+
+func loadBucket(ctx context.Context, location string) (*Library, error) {
+	bucketName, prefix, _ := strings.Cut(location, "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage client: %v", err)
+	}
+	defer client.Close()
+	bucket := client.Bucket(bucketName)
+
+	lib := &Library{byName: make(map[string]*Font)}
+	it := bucket.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list fonts in gs://%s: %v", location, err)
+		}
+		file := path.Base(attrs.Name)
+		if attrs.Name == "" || mediaTypes[strings.ToLower(path.Ext(file))] == "" {
+			continue
+		}
+		reader, err := bucket.Object(attrs.Name).NewReader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read font %s: %v", attrs.Name, err)
+		}
+		err = lib.add(file, reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lib, nil
+}
+
+// add reads the font file named file from r.
+func (l *Library) add(file string, r io.Reader) error {
+	ext := filepath.Ext(file)
+	name := strings.TrimSuffix(file, ext)
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid font file name %q (expected letters, digits, hyphens, and underscores)", file)
+	}
+	if _, ok := l.byName[name]; ok {
+		return fmt.Errorf("font %s is in more than one file", name)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read font %s: %v", file, err)
+	}
+	if len(data) > MaxSize {
+		return fmt.Errorf("font %s is larger than %d bytes", file, MaxSize)
+	}
+	sum := sha256.Sum256(data)
+	l.byName[name] = &Font{
+		Name:      name,
+		File:      file,
+		MediaType: mediaTypes[strings.ToLower(ext)],
+		Data:      data,
+		Sum:       hex.EncodeToString(sum[:]),
+	}
+	return nil
+}
+
+// Get returns the font with the name.
+func (l *Library) Get(name string) (*Font, bool) {
+	if l == nil {
+		return nil, false
+	}
+	font, ok := l.byName[name]
+	return font, ok
+}
+
+// List returns the fonts ordered by name.
+func (l *Library) List() []*Font {
+	if l == nil {
+		return nil
+	}
+	fonts := make([]*Font, 0, len(l.byName))
+	for _, font := range l.byName {
+		fonts = append(fonts, font)
+	}
+	sort.Slice(fonts, func(i, k int) bool {
+		return fonts[i].Name < fonts[k].Name
+	})
+	return fonts
+}
+
+// Len returns the number of fonts.
+func (l *Library) Len() int {
+	if l == nil {
+		return 0
+	}
+	return len(l.byName)
+}
@@ -0,0 +1,148 @@
+package furigana
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"unicode"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+// Token is a word of analyzed text and its reading in hiragana. Tokens
+// without kanji may have no reading.
+type Token struct {
+	Surface string
+	Reading string
+}
+
+// Analyzer splits lines of text into words with readings, such as a
+// morphological analyzer. It returns the tokens of every line in order.
+type Analyzer interface {
+	Analyze(lines []string) ([][]Token, error)
+}
+
+// New returns an annotator backed by the named analyzer, "mecab" or
+// "kakasi", run as command (the analyzer name when empty). It returns nil
+// for an empty name.
+func New(analyzer, command string) (*Annotator, error) {
+	if command == "" {
+		command = analyzer
+	}
+	var a Analyzer
+	switch analyzer {
+	case "mecab":
+		a = &Mecab{Command: command}
+	case "kakasi":
+		a = &Kakasi{Command: command}
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown furigana analyzer %q (expected mecab or kakasi)", analyzer)
+	}
+	if _, err := exec.LookPath(command); err != nil {
+		return nil, fmt.Errorf("furigana analyzer %s is not available: %v", command, err)
+	}
+	return NewAnnotator(a), nil
+}
+
+// Annotator adds readings to words containing difficult kanji. It
+// implements lawdata.Annotator.
+type Annotator struct {
+	analyzer Analyzer
+}
+
+func NewAnnotator(analyzer Analyzer) *Annotator {
+	return &Annotator{analyzer: analyzer}
+}
+
+// Annotate analyzes texts in one batch. Readings cover only the kanji of a
+// word, so 定める is annotated as 定(さだ)める.
+func (a *Annotator) Annotate(texts []string) ([][]lawdata.RubySegment, error) {
+	// Analyzers read one text per line.
+	lines := make([]string, len(texts))
+	for i, text := range texts {
+		lines[i] = strings.Map(func(r rune) rune {
+			if r == '\n' || r == '\r' {
+				return ' '
+			}
+			return r
+		}, text)
+	}
+
+	tokens, err := a.analyzer.Analyze(lines)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) != len(texts) {
+		return nil, fmt.Errorf("analyzer returned %d lines for %d texts", len(tokens), len(texts))
+	}
+
+	result := make([][]lawdata.RubySegment, len(texts))
+	for i := range texts {
+		var segments []lawdata.RubySegment
+		plain := func(text string) {
+			if text == "" {
+				return
+			}
+			if n := len(segments); n > 0 && segments[n-1].Reading == "" {
+				segments[n-1].Text += text
+				return
+			}
+			segments = append(segments, lawdata.RubySegment{Text: text})
+		}
+		for _, token := range tokens[i] {
+			if !difficult(token.Surface) || token.Reading == "" {
+				plain(token.Surface)
+				continue
+			}
+			prefix, base, reading, suffix := splitKana(token.Surface, token.Reading)
+			plain(prefix)
+			segments = append(segments, lawdata.RubySegment{Text: base, Reading: reading})
+			plain(suffix)
+		}
+		result[i] = segments
+	}
+	return result, nil
+}
+
+// easyKanji are the first-grade kanji, which are not annotated.
+const easyKanji = "一右雨円王音下火花貝学気九休玉金空月犬見五口校左三山子四糸字耳七車手十出女小上森人水正生青夕石赤千川先早草足村大男竹中虫町天田土二日入年白八百文木本名目立力林六"
+
+// difficult reports whether a word contains kanji beyond the first grade.
+func difficult(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Han, r) && !strings.ContainsRune(easyKanji, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitKana moves kana shared by the word and its reading out of the
+// reading, such as the okurigana める of 定める.
+func splitKana(surface, reading string) (prefix, base, baseReading, suffix string) {
+	word := []rune(surface)
+	kana := []rune(reading)
+	start := 0
+	for start < len(word)-1 && start < len(kana)-1 && !unicode.Is(unicode.Han, word[start]) && hiragana(word[start]) == kana[start] {
+		start++
+	}
+	end := 0
+	for end < len(word)-start-1 && end < len(kana)-start-1 && !unicode.Is(unicode.Han, word[len(word)-1-end]) && hiragana(word[len(word)-1-end]) == kana[len(kana)-1-end] {
+		end++
+	}
+	return string(word[:start]), string(word[start : len(word)-end]), string(kana[start : len(kana)-end]), string(word[len(word)-end:])
+}
+
+// toHiragana converts katakana readings to hiragana.
+func toHiragana(s string) string {
+	return strings.Map(hiragana, s)
+}
+
+func hiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 'ァ' + 'ぁ'
+	}
+	return r
+}
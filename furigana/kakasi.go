@@ -0,0 +1,63 @@
+package furigana
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kakasi runs the KAKASI command in furigana mode, which writes readings
+// in brackets after each kanji word: 法律[ほうりつ].
+type Kakasi struct {
+	Command string
+}
+
+func (k *Kakasi) Analyze(lines []string) ([][]Token, error) {
+	out, err := run(k.Command, lines, "-JH", "-f", "-iutf8", "-outf8")
+	if err != nil {
+		return nil, err
+	}
+
+	output := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	result := make([][]Token, len(output))
+	for i, line := range output {
+		result[i] = parseKakasi(line)
+	}
+	return result, nil
+}
+
+// parseKakasi splits a line of furigana-mode output into the kanji words
+// followed by a bracketed reading and the text between them.
+func parseKakasi(line string) []Token {
+	var tokens []Token
+	var pending []rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '[' {
+			pending = append(pending, runes[i])
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && runes[end] != ']' {
+			end++
+		}
+		start := len(pending)
+		for start > 0 && (unicode.Is(unicode.Han, pending[start-1]) || pending[start-1] == '々') {
+			start--
+		}
+		if end == len(runes) || start == len(pending) {
+			// Not a reading: keep the bracket as text.
+			pending = append(pending, runes[i])
+			continue
+		}
+		if start > 0 {
+			tokens = append(tokens, Token{Surface: string(pending[:start])})
+		}
+		tokens = append(tokens, Token{Surface: string(pending[start:]), Reading: string(runes[i+1 : end])})
+		pending = nil
+		i = end
+	}
+	if len(pending) > 0 {
+		tokens = append(tokens, Token{Surface: string(pending)})
+	}
+	return tokens
+}
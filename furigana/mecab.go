@@ -0,0 +1,69 @@
+package furigana
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandTimeout bounds one analyzer run over a whole law.
+const commandTimeout = time.Minute
+
+// Mecab runs the MeCab command with a dictionary that has the reading as
+// its eighth feature, such as IPADIC.
+type Mecab struct {
+	Command string
+}
+
+func (m *Mecab) Analyze(lines []string) ([][]Token, error) {
+	out, err := run(m.Command, lines,
+		`--node-format=%m\t%f[7]\n`,
+		`--unk-format=%m\t\n`,
+		`--eos-format=EOS\n`,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]Token
+	var tokens []Token
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "EOS" {
+			result = append(result, tokens)
+			tokens = nil
+			continue
+		}
+		surface, reading, _ := strings.Cut(line, "\t")
+		if reading == "*" {
+			reading = ""
+		}
+		tokens = append(tokens, Token{Surface: surface, Reading: toHiragana(reading)})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read mecab output: %v", err)
+	}
+	return result, nil
+}
+
+// run feeds lines to command on standard input and returns its output.
+func run(command string, lines []string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %v: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
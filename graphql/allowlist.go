@@ -0,0 +1,168 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+// operationMetrics counts the operations checked against the allow-list:
+// allowed, rejected, and unlisted ones let through in report mode. It is
+// published with expvar.
+var operationMetrics = expvar.NewMap("graphql_allowlist")
+
+// AllowList restricts GraphQL operations to a manifest of approved ones,
+// identified by the SHA-256 of their text as in automatic persisted
+// queries. A nil AllowList allows every operation.
+type AllowList struct {
+	// enforce rejects unlisted operations; otherwise they are only logged
+	// and counted.
+	enforce    bool
+	operations map[string]string
+}
+
+var (
+	_ graphql.HandlerExtension          = &AllowList{}
+	_ graphql.OperationParameterMutator = &AllowList{}
+)
+
+// apolloManifest is a persisted query manifest as written by Apollo's
+// generate-persisted-query-manifest.
+type apolloManifest struct {
+	Format     string `json:"format"`
+	Operations []struct {
+		Name string `json:"name"`
+		Body string `json:"body"`
+	} `json:"operations"`
+}
+
+// LoadAllowList reads the manifest at path for mode, which is off, report,
+// or enforce. The manifest is either an Apollo persisted query manifest or
+// a JSON object whose values are operations, as Relay writes; the IDs in
+// it are ignored. It returns nil when mode is off.
+func LoadAllowList(mode, path string) (*AllowList, error) {
+	if mode == "off" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operation manifest: %v", err)
+	}
+
+	var bodies []string
+	var apollo apolloManifest
+	if err := json.Unmarshal(data, &apollo); err == nil && apollo.Format == "apollo-persisted-query-manifest" {
+		for _, op := range apollo.Operations {
+			bodies = append(bodies, op.Body)
+		}
+	} else {
+		var relay map[string]string
+		if err := json.Unmarshal(data, &relay); err != nil {
+			return nil, fmt.Errorf("failed to parse operation manifest %s: %v", path, err)
+		}
+		for _, body := range relay {
+			bodies = append(bodies, body)
+		}
+	}
+	if len(bodies) == 0 {
+		return nil, fmt.Errorf("operation manifest %s lists no operations", path)
+	}
+
+	a := &AllowList{enforce: mode == "enforce", operations: make(map[string]string, len(bodies))}
+	for _, body := range bodies {
+		a.operations[operationHash(body)] = body
+	}
+	return a, nil
+}
+
+// Len returns the number of approved operations.
+func (a *AllowList) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.operations)
+}
+
+// Cache returns the automatic persisted query cache to use with the
+// allow-list: approved operations can be sent by hash alone without being
+// registered first, and others are left to next.
+func (a *AllowList) Cache(next graphql.Cache[string]) graphql.Cache[string] {
+	if a == nil {
+		return next
+	}
+	return manifestCache{operations: a.operations, next: next}
+}
+
+func (a *AllowList) ExtensionName() string {
+	return "OperationAllowList"
+}
+
+func (a *AllowList) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+// MutateOperationParameters checks the operation after automatic persisted
+// queries have filled in its text, so it must be used after that extension.
+func (a *AllowList) MutateOperationParameters(ctx context.Context, rawParams *graphql.RawParams) *gqlerror.Error {
+	if a == nil {
+		return nil
+	}
+	hash := operationHash(rawParams.Query)
+	if _, ok := a.operations[hash]; ok {
+		operationMetrics.Add("allowed", 1)
+		return nil
+	}
+
+	name := rawParams.OperationName
+	if name == "" {
+		name = "anonymous"
+	}
+	if !a.enforce {
+		operationMetrics.Add("unlisted", 1)
+		log.Printf("GraphQL operation %s (%s) is not in the manifest", name, hash[:12])
+		return nil
+	}
+	operationMetrics.Add("rejected", 1)
+	log.Printf("Rejected GraphQL operation %s (%s) not in the manifest", name, hash[:12])
+	return &gqlerror.Error{
+		Message: "operation is not in the manifest of approved operations",
+		Extensions: map[string]interface{}{
+			"code":      model1.ErrorCodeOperationNotAllowed,
+			"retryable": false,
+		},
+	}
+}
+
+// operationHash returns the hex SHA-256 of an operation's text.
+func operationHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// manifestCache serves approved operations by hash before consulting the
+// automatic persisted query cache.
+type manifestCache struct {
+	operations map[string]string
+	next       graphql.Cache[string]
+}
+
+func (c manifestCache) Get(ctx context.Context, key string) (string, bool) {
+	if query, ok := c.operations[key]; ok {
+		return query, true
+	}
+	return c.next.Get(ctx, key)
+}
+
+func (c manifestCache) Add(ctx context.Context, key string, value string) {
+	c.next.Add(ctx, key, value)
+}
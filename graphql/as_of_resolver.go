@@ -0,0 +1,71 @@
+package graphql
+
+import (
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+)
+
+// lawAsOf returns a law with the revision in force on date, or nil when the
+// law was not in force then.
+func (r *Resolver) lawAsOf(id string, date time.Time) (*lawapi.LawItem, error) {
+	parsed, err := parseLawID(id)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Kind == lawid.RevisionID {
+		return nil, codedErrorf(model1.ErrorCodeInvalidLawID, "lawAsOf takes a law ID or law number, got revision ID %s", id)
+	}
+
+	resp, err := r.lawRevisions(parsed.Value)
+	if err != nil {
+		return nil, err
+	}
+	revision := revisionAsOf(resp.Revisions, date)
+	if revision == nil {
+		return nil, nil
+	}
+
+	item := &lawapi.LawItem{LawInfo: &resp.LawInfo, RevisionInfo: revision}
+	for i := range resp.Revisions {
+		if status := resp.Revisions[i].CurrentRevisionStatus; status != nil && *status == lawapi.CurrentRevisionStatusCurrentenforced {
+			item.CurrentRevisionInfo = &resp.Revisions[i]
+		}
+	}
+	return item, nil
+}
+
+// revisionAsOf returns the revision with the latest enforcement date on or
+// before date, preferring the larger revision ID, whose amending law is the
+// later one, among revisions enforced on the same day. It returns nil
+// before the first enforcement and from a repeal, expiry, or loss of
+// effectiveness on; a suspended law keeps its revision.
+func revisionAsOf(revisions []lawapi.RevisionInfo, date time.Time) *lawapi.RevisionInfo {
+	var found *lawapi.RevisionInfo
+	for i := range revisions {
+		revision := &revisions[i]
+		if ended, ok := eventDate(revision.RepealDate); ok && !ended.After(date) && revision.RepealStatus != nil {
+			switch *revision.RepealStatus {
+			case lawapi.RepealStatusRepeal, lawapi.RepealStatusExpire, lawapi.RepealStatusLossofeffectiveness:
+				return nil
+			}
+		}
+
+		enforced, ok := eventDate(revision.AmendmentEnforcementDate)
+		if !ok || enforced.After(date) {
+			continue
+		}
+		if found == nil {
+			found = revision
+			continue
+		}
+		latest := time.Time(found.AmendmentEnforcementDate)
+		if enforced.After(latest) || enforced.Equal(latest) && revision.LawRevisionId > found.LawRevisionId {
+			found = revision
+		}
+	}
+	return found
+}
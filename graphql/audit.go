@@ -0,0 +1,24 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// recordAudit completes an audit entry with the requester, tenant, timing,
+// and error, and writes it to the audit log.
+func (r *Resolver) recordAudit(ctx context.Context, entry audit.Entry, start time.Time, err error) {
+	entry.Time = start
+	entry.Duration = r.clock.Now().Sub(start)
+	entry.Requester = handlers.ClientIPFromContext(ctx)
+	entry.Tenant = tenant.IDFromContext(ctx)
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	}
+	r.audit.Log(ctx, entry)
+}
@@ -0,0 +1,413 @@
+package graphql
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+const (
+	// maxBulkExportIDs caps the documents of one bulk export.
+	maxBulkExportIDs = 1000
+	// bulkExportTimeout bounds a whole bulk export.
+	bulkExportTimeout = 2 * time.Hour
+	// bulkExportProgressInterval throttles status object updates, which
+	// Cloud Storage limits to one write per second per object.
+	bulkExportProgressInterval = 5 * time.Second
+	// bulkExportRetryInterval is the wait before a conversion tries the
+	// saturated converter pool again.
+	bulkExportRetryInterval = time.Second
+)
+
+// requestBulkExport validates a bulk export, records it in the audit log,
+// and starts assembling the archive in the background.
+func (r *Resolver) requestBulkExport(ctx context.Context, ids []string, format model1.Format) (*model1.BulkExport, error) {
+	start := r.clock.Now()
+	export, err := r.startBulkExport(ctx, ids, format)
+
+	entry := audit.Entry{
+		Operation: "requestBulkExport",
+		Output:    string(format),
+	}
+	if export != nil {
+		entry.Filename = export.ID + ".zip"
+		entry.Result = string(export.Status)
+	}
+	r.recordAudit(ctx, entry, start, err)
+
+	return export, err
+}
+
+func (r *Resolver) startBulkExport(ctx context.Context, ids []string, format model1.Format) (*model1.BulkExport, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("bulk export")
+	}
+	if !format.IsValid() {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "unsupported format %s", format)
+	}
+
+	// Law numbers are normalized, so that spellings of the same number
+	// are exported once. Laws named by a law ID and by its number are
+	// only told apart once they are fetched.
+	var unique []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if parsed, err := lawid.Parse(id); err == nil {
+			id = parsed.Value
+		}
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) == 0 {
+		return nil, withCode(model1.ErrorCodeBadUserInput, errors.New("ids must name at least one law"))
+	}
+	if len(unique) > maxBulkExportIDs {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "ids accepts at most %d laws, got %d", maxBulkExportIDs, len(unique))
+	}
+	// Each law counts as a request against the client's quota, of which
+	// the request itself was the first.
+	if state := handlers.QuotaFromContext(ctx); state != nil && len(unique) > 1 {
+		usages, err := state.Consume(ctx, int64(len(unique)-1))
+		if err != nil {
+			// Like request quotas, the limit is not enforced without its
+			// store.
+			log.Printf("Bulk export quota check failed for %s: %v", state.Subject, err)
+		} else if usage, ok := quota.Tightest(usages); ok && usage.Exceeded() {
+			return nil, codedErrorf(model1.ErrorCodeQuotaExceeded, "%s quota of %d requests exceeded by an export of %d laws", usage.Window, usage.Limit, len(unique))
+		}
+	}
+
+	id, err := newExportID()
+	if err != nil {
+		return nil, err
+	}
+	now := r.clock.Now().UTC().Format(time.RFC3339)
+	export := &model1.BulkExport{
+		ID:        id,
+		Format:    format,
+		Status:    model1.EpubStatusPending,
+		Total:     len(unique),
+		Failures:  []model1.BulkExportFailure{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+	prefix := storagePrefix(ctx)
+	if err := writeBulkExportStatus(ctx, bucket, prefix, export); err != nil {
+		return nil, err
+	}
+
+	go r.runBulkExport(prefix, *export, unique)
+
+	return export, nil
+}
+
+// getBulkExport reads the status of a bulk export, with a signed URL once
+// it has completed. It returns nil for an unknown ID.
+func (r *Resolver) getBulkExport(ctx context.Context, id string) (*model1.BulkExport, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("bulk export")
+	}
+	if !validExportID(id) {
+		return nil, nil
+	}
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+	prefix := storagePrefix(ctx)
+
+	var export model1.BulkExport
+	if found, err := readStatus(ctx, bucket, bulkExportStatusPath(prefix, id), "bulk export", &export); err != nil || !found {
+		return nil, err
+	}
+
+	if export.Status == model1.EpubStatusCompleted {
+		signedURL, err := generateSignedURL(bucket, bulkExportArchivePath(prefix, id), 1*time.Hour, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signed URL: %v", err)
+		}
+		export.SignedURL = &signedURL
+		export.DownloadURL = downloadURL(id, APP_VERSION)
+	}
+	return &export, nil
+}
+
+// runBulkExport converts every document in-process and streams the ZIP
+// archive to the bucket below prefix, updating the status object as it
+// goes. Documents that fail are listed in the status and left out of the
+// archive, and documents of a revision already in it are skipped.
+func (r *Resolver) runBulkExport(prefix string, export model1.BulkExport, ids []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkExportTimeout)
+	defer cancel()
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		log.Printf("Bulk export %s: %v", export.ID, err)
+		return
+	}
+
+	update := func() {
+		export.UpdatedAt = r.clock.Now().UTC().Format(time.RFC3339)
+		if err := writeBulkExportStatus(ctx, bucket, prefix, &export); err != nil {
+			log.Printf("Bulk export %s: %v", export.ID, err)
+		}
+	}
+	fail := func(err error) {
+		message := err.Error()
+		export.Status = model1.EpubStatusFailed
+		export.Error = &message
+		update()
+	}
+
+	export.Status = model1.EpubStatusProcessing
+	update()
+
+	// Canceling the upload's context abandons a failed archive.
+	archivePath := bulkExportArchivePath(prefix, export.ID)
+	uploadCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	writer := bucket.NewWriter(uploadCtx, archivePath, objects.WriteOptions{
+		ContentType:        "application/zip",
+		ContentDisposition: fmt.Sprintf(`attachment; filename="%s.zip"`, export.ID),
+	})
+	discard := func() {
+		abort()
+		_ = writer.Close()
+	}
+	hash := sha256.New()
+	archive := zip.NewWriter(io.MultiWriter(writer, hash))
+
+	lastUpdate := r.clock.Now()
+	exported := make(map[string]bool)
+	for _, id := range ids {
+		name, data, err := r.exportDocument(ctx, id, export.Format, exported)
+		switch {
+		case ctx.Err() != nil:
+			discard()
+			fail(fmt.Errorf("bulk export timed out after %d of %d documents", export.Completed, export.Total))
+			return
+		case errors.Is(err, errDuplicateDocument):
+		case err != nil:
+			export.Failures = append(export.Failures, model1.BulkExportFailure{ID: id, Error: err.Error()})
+		default:
+			if err := writeZipEntry(archive, name, data); err != nil {
+				discard()
+				fail(err)
+				return
+			}
+		}
+		export.Completed++
+		if now := r.clock.Now(); now.Sub(lastUpdate) >= bulkExportProgressInterval {
+			update()
+			lastUpdate = now
+		}
+	}
+
+	if len(export.Failures) == len(ids) {
+		discard()
+		fail(errors.New("no documents could be exported"))
+		return
+	}
+	if err := archive.Close(); err != nil {
+		discard()
+		fail(fmt.Errorf("failed to write archive: %v", err))
+		return
+	}
+	if err := writer.Close(); err != nil {
+		fail(fmt.Errorf("failed to upload archive: %v", err))
+		return
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if _, err := bucket.Update(ctx, archivePath, map[string]string{checksumKey: sum}, objects.Conditions{}); err != nil {
+		fail(fmt.Errorf("failed to record checksum: %v", err))
+		return
+	}
+
+	size := int(writer.Attrs().Size)
+	export.Size = &size
+	export.Sha256 = &sum
+	export.Status = model1.EpubStatusCompleted
+	update()
+}
+
+// errDuplicateDocument is returned by exportDocument for a law whose
+// revision is already in the archive.
+var errDuplicateDocument = errors.New("document is already exported")
+
+// exportDocument fetches a law and renders it in the export format in the
+// converter pool, returning its file name in the archive. The revisions in
+// exported are skipped, and the law's is added.
+func (r *Resolver) exportDocument(ctx context.Context, id string, format model1.Format, exported map[string]bool) (string, []byte, error) {
+	name := strings.NewReplacer("/", "_", `\`, "_").Replace(id)
+
+	data, err := r.lawData.FetchLawData(ctx, id)
+	if errors.Is(err, lawdata.ErrNotFound) {
+		return "", nil, fmt.Errorf("law %s not found", id)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	// A law ID and a law number name the current revision, which may
+	// also be named by its revision ID.
+	revision := data.RevisionID
+	if revision == "" {
+		revision = id
+	}
+	if exported[revision] {
+		return "", nil, errDuplicateDocument
+	}
+	exported[revision] = true
+	if format == model1.FormatXML {
+		return name + ".xml", data.XML, nil
+	}
+
+	var buf bytes.Buffer
+	err = r.runExportConversion(ctx, sandbox.Estimate(len(data.XML)), func() error {
+		law, err := lawdata.ParseLawData(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse law %s: %v", id, err)
+		}
+		law.TitleEn = r.titleEn(id, law.LawNum)
+
+		switch format {
+		case model1.FormatEpub:
+			if err := lawdata.WriteEPUB(&buf, law, "urn:jplaw2epub:"+id, lawdata.Options{}); err != nil {
+				return err
+			}
+			_, err = lawdata.CheckEPUB(buf.Bytes())
+			return err
+		case model1.FormatHTML:
+			return lawdata.RenderHTML(&buf, law, nil)
+		case model1.FormatXML:
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if format == model1.FormatEpub {
+		return name + ".epub", buf.Bytes(), nil
+	}
+	return name + ".html", buf.Bytes(), nil
+}
+
+// runExportConversion runs a conversion of a bulk export in the converter
+// pool. The export runs in the background, so while the pool is saturated
+// it waits for the requests it would otherwise turn away.
+func (r *Resolver) runExportConversion(ctx context.Context, estimate int64, fn func() error) error {
+	for {
+		err := r.pool.Run(ctx, estimate, fn)
+		if !errors.Is(err, sandbox.ErrSaturated) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(bulkExportRetryInterval):
+		}
+	}
+}
+
+func writeZipEntry(archive *zip.Writer, name string, data []byte) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write archive: %v", err)
+	}
+	if _, err := io.Copy(entry, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write archive: %v", err)
+	}
+	return nil
+}
+
+func writeBulkExportStatus(ctx context.Context, bucket objects.Bucket, prefix string, export *model1.BulkExport) error {
+	return writeStatus(ctx, bucket, bulkExportStatusPath(prefix, export.ID), "bulk export", export)
+}
+
+// newExportID returns a random ID for a bulk export or statute book.
+func newExportID() (string, error) {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate export ID: %v", err)
+	}
+	return hex.EncodeToString(random), nil
+}
+
+// validExportID reports whether id could have been made by newExportID.
+func validExportID(id string) bool {
+	_, err := hex.DecodeString(id)
+	return err == nil && len(id) == 16
+}
+
+// writeStatus stores the status object of a background task, such as a
+// bulk export, at path.
+func writeStatus(ctx context.Context, bucket objects.Bucket, path, task string, status interface{}) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s status: %v", task, err)
+	}
+	writer := bucket.NewWriter(ctx, path, objects.WriteOptions{ContentType: "application/json"})
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return fmt.Errorf("failed to write %s status: %v", task, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to write %s status: %v", task, err)
+	}
+	return nil
+}
+
+// readStatus reads the status object written by writeStatus into status,
+// returning false when there is none.
+func readStatus(ctx context.Context, bucket objects.Bucket, path, task string, status interface{}) (bool, error) {
+	reader, err := bucket.NewReader(ctx, path)
+	if errors.Is(err, objects.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s status: %v", task, err)
+	}
+	defer reader.Close()
+
+	if err := json.NewDecoder(reader).Decode(status); err != nil {
+		return false, fmt.Errorf("failed to parse %s status: %v", task, err)
+	}
+	return true, nil
+}
+
+func bulkExportStatusPath(prefix, id string) string {
+	return fmt.Sprintf("%s/exports/%s.json", prefix, id)
+}
+
+func bulkExportArchivePath(prefix, id string) string {
+	return fmt.Sprintf("%s/exports/%s.zip", prefix, id)
+}
@@ -0,0 +1,107 @@
+package graphql_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/graphql"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+const requestBulkExport = `mutation ($ids: [String!]!) { requestBulkExport(ids: $ids, format: XML) { id total } }`
+
+func TestBulkExportSkipsDuplicateLaws(t *testing.T) {
+	s := testsupport.NewServer(t)
+	// The law ID, the number, and the revision ID of the constitution name
+	// the same revision.
+	ids := []string{"321CONSTITUTION", " 321CONSTITUTION", "昭和二十一年憲法", constitution, "129AC0000000089"}
+	resp := s.GraphQL(t, requestBulkExport, map[string]interface{}{"ids": ids}, false)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("requestBulkExport errors = %+v", resp.Errors)
+	}
+	var started struct {
+		RequestBulkExport struct {
+			ID    string
+			Total int
+		}
+	}
+	if err := json.Unmarshal(resp.Data, &started); err != nil {
+		t.Fatalf("Failed to decode export: %v", err)
+	}
+	if started.RequestBulkExport.Total != 4 {
+		t.Errorf("total = %d, want 4 distinct IDs", started.RequestBulkExport.Total)
+	}
+
+	var export struct {
+		Status    string
+		Completed int
+		Failures  []struct{ ID string }
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for export.Status != "COMPLETED" {
+		if time.Now().After(deadline) || export.Status == "FAILED" {
+			t.Fatalf("export = %+v, want COMPLETED", export)
+		}
+		time.Sleep(10 * time.Millisecond)
+		resp := s.GraphQL(t, `query ($id: String!) { bulkExport(id: $id) { status completed failures { id } } }`, map[string]interface{}{"id": started.RequestBulkExport.ID}, false)
+		var data struct{ BulkExport json.RawMessage }
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			t.Fatalf("Failed to decode export: %v", err)
+		}
+		if err := json.Unmarshal(data.BulkExport, &export); err != nil {
+			t.Fatalf("Failed to decode export: %v", err)
+		}
+	}
+	if export.Completed != 4 || len(export.Failures) != 0 {
+		t.Errorf("export = %+v, want 4 completed without failures", export)
+	}
+
+	archive, ok := s.Storage.Get(testsupport.Bucket, graphql.APP_VERSION+"/exports/"+started.RequestBulkExport.ID+".zip")
+	if !ok {
+		t.Fatal("archive was not stored")
+	}
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	want := []string{"129AC0000000089.xml", "321CONSTITUTION.xml"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("archive entries = %v, want %v", names, want)
+	}
+}
+
+func TestBulkExportCountsAgainstQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		ids      []string
+		wantCode string
+	}{
+		{name: "within quota", ids: []string{constitution, civilCode}},
+		{name: "over quota", ids: []string{constitution, civilCode, "321CONSTITUTION", "129AC0000000089"}, wantCode: "QUOTA_EXCEEDED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testsupport.NewServer(t, func(cfg *config.Config) {
+				cfg.Quota.Daily = 3
+			})
+			resp := s.GraphQL(t, requestBulkExport, map[string]interface{}{"ids": tt.ids}, false)
+			var code string
+			if len(resp.Errors) > 0 {
+				code, _ = resp.Errors[0].Extensions["code"].(string)
+			}
+			if code != tt.wantCode {
+				t.Errorf("error code = %q, want %q (%+v)", code, tt.wantCode, resp.Errors)
+			}
+		})
+	}
+}
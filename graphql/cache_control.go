@@ -0,0 +1,259 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/locale"
+)
+
+// maxCachedRequest bounds the request bodies read to compute a response
+// cache key; larger requests are not cached.
+const maxCachedRequest = 64 << 10
+
+// cachePolicy is the caching allowed for a response by the @cacheControl
+// hints of the fields it resolved.
+type cachePolicy struct {
+	mu sync.Mutex
+	// header receives Cache-Control before the response is written.
+	header http.Header
+	// maxAge is the smallest hint, or -1 before the first. On the policy
+	// of an HTTP request it is the lifetime of a shared cache entry.
+	maxAge  int
+	private bool
+}
+
+type cachePolicyKey struct{}
+
+// restrict lowers the policy to a field's hint.
+func (p *cachePolicy) restrict(maxAge int, scope model1.CacheControlScope) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.maxAge < 0 || maxAge < p.maxAge {
+		p.maxAge = maxAge
+	}
+	if scope == model1.CacheControlScopePrivate {
+		p.private = true
+	}
+}
+
+// cacheControl returns the Cache-Control header value and the lifetime of
+// a shared cache entry, which is zero for private and uncacheable
+// responses.
+func (p *cachePolicy) cacheControl() (string, time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case p.maxAge <= 0:
+		return "no-store", 0
+	case p.private:
+		return fmt.Sprintf("private, max-age=%d", p.maxAge), 0
+	default:
+		return fmt.Sprintf("public, max-age=%d", p.maxAge), time.Duration(p.maxAge) * time.Second
+	}
+}
+
+// CacheControl is a handler extension that applies the @cacheControl hints
+// of resolved fields to the Cache-Control header of query responses set up
+// by WithCacheControl.
+type CacheControl struct {
+	// PublicIntrospection lets responses that introspect the schema be
+	// cached, as when every caller may introspect. Otherwise they are
+	// uncacheable, since a shared cache would pass a schema only admins may
+	// see to anyone sending the same request.
+	PublicIntrospection bool
+}
+
+var (
+	_ graphql.HandlerExtension    = CacheControl{}
+	_ graphql.ResponseInterceptor = CacheControl{}
+	_ graphql.FieldInterceptor    = CacheControl{}
+)
+
+func (CacheControl) ExtensionName() string {
+	return "CacheControl"
+}
+
+func (CacheControl) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (CacheControl) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	holder, _ := ctx.Value(cachePolicyKey{}).(*cachePolicy)
+	if holder == nil || !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	// Each response of a batch or subscription gets its own policy.
+	policy := &cachePolicy{maxAge: -1}
+	resp := next(context.WithValue(ctx, cachePolicyKey{}, policy))
+
+	op := graphql.GetOperationContext(ctx).Operation
+	if op == nil || op.Operation != ast.Query || resp == nil || len(resp.Errors) > 0 {
+		policy.restrict(0, model1.CacheControlScopePublic)
+	}
+	value, ttl := policy.cacheControl()
+	holder.mu.Lock()
+	holder.maxAge = int(ttl / time.Second)
+	holder.mu.Unlock()
+	holder.header.Set("Cache-Control", value)
+	return resp
+}
+
+func (c CacheControl) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	policy, _ := ctx.Value(cachePolicyKey{}).(*cachePolicy)
+	fc := graphql.GetFieldContext(ctx)
+	if policy == nil || fc == nil || fc.Field.Field == nil || fc.Field.Definition == nil {
+		return next(ctx)
+	}
+	if hint := fc.Field.Definition.Directives.ForName("cacheControl"); hint != nil {
+		maxAge, scope := parseCacheHint(hint)
+		policy.restrict(maxAge, scope)
+	} else if fc.Object == "Query" && (!strings.HasPrefix(fc.Field.Name, "__") || isIntrospection(fc.Field.Name) && !c.PublicIntrospection) {
+		// Root fields are uncacheable unless they say otherwise.
+		policy.restrict(0, model1.CacheControlScopePublic)
+	}
+	return next(ctx)
+}
+
+// isIntrospection reports whether a root field introspects the schema.
+// __typename does not, and is answered to every caller.
+func isIntrospection(field string) bool {
+	return field == "__schema" || field == "__type"
+}
+
+// parseCacheHint reads the arguments of a @cacheControl directive.
+func parseCacheHint(hint *ast.Directive) (int, model1.CacheControlScope) {
+	maxAge := 0
+	if arg := hint.Arguments.ForName("maxAge"); arg != nil && arg.Value != nil {
+		maxAge, _ = strconv.Atoi(arg.Value.Raw)
+	}
+	scope := model1.CacheControlScopePublic
+	if arg := hint.Arguments.ForName("scope"); arg != nil && arg.Value != nil {
+		scope = model1.CacheControlScope(arg.Value.Raw)
+	}
+	return maxAge, scope
+}
+
+// cachedResponse is a query response kept by WithCacheControl.
+type cachedResponse struct {
+	body     []byte
+	storedAt time.Time
+	maxAge   time.Duration
+}
+
+// WithCacheControl lets the CacheControl extension set Cache-Control on
+// GraphQL responses. With a positive size, responses that are public for a
+// while are also kept in a server-side LRU cache of that many entries,
+// keyed by a hash of the query, operation name, variables, and the locale
+// of display names, and served without running the query again.
+func WithCacheControl(next http.Handler, size int) http.Handler {
+	var cache *lru.LRU[*cachedResponse]
+	if size > 0 {
+		cache = lru.New[*cachedResponse](size)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := &cachePolicy{header: w.Header()}
+		r = r.WithContext(context.WithValue(r.Context(), cachePolicyKey{}, policy))
+
+		if cache == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, ok := responseCacheKey(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if entry, found := cache.Get(r.Context(), key); found {
+			age := time.Since(entry.storedAt)
+			if age < entry.maxAge {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int((entry.maxAge-age)/time.Second)))
+				w.Header().Set("Age", strconv.Itoa(int(age/time.Second)))
+				_, _ = w.Write(entry.body)
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		policy.mu.Lock()
+		maxAge := time.Duration(policy.maxAge) * time.Second
+		policy.mu.Unlock()
+		if recorder.status == http.StatusOK && maxAge > 0 {
+			cache.Add(r.Context(), key, &cachedResponse{body: recorder.body.Bytes(), storedAt: time.Now(), maxAge: maxAge})
+		}
+	})
+}
+
+// responseCacheKey hashes the GraphQL request of a GET or JSON POST. Other
+// requests, such as uploads, websocket upgrades, and requests for
+// incremental delivery, are not cached.
+func responseCacheKey(r *http.Request) (string, bool) {
+	if strings.Contains(r.Header.Get("Accept"), "multipart/mixed") {
+		return "", false
+	}
+	h := sha256.New()
+	switch r.Method {
+	case http.MethodGet:
+		if r.Header.Get("Upgrade") != "" {
+			return "", false
+		}
+		h.Write([]byte(r.URL.RawQuery))
+	case http.MethodPost:
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != "application/json" {
+			return "", false
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCachedRequest+1))
+		// The body is restored for the GraphQL transport.
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		if err != nil || len(body) > maxCachedRequest {
+			return "", false
+		}
+		h.Write(body)
+	default:
+		return "", false
+	}
+	// Display names follow Accept-Language.
+	h.Write([]byte{0})
+	h.Write([]byte(locale.FromContext(r.Context())))
+	return r.Method + ":" + hex.EncodeToString(h.Sum(nil)), true
+}
+
+// responseRecorder copies a response while writing it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush passes flushes of streamed responses through.
+func (w *responseRecorder) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
@@ -0,0 +1,74 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+func TestIntrospectionResponseCache(t *testing.T) {
+	// An admin and then another caller send the same body, which the
+	// server-side cache keys responses by.
+	body := `{"query":"{ __schema { queryType { name } } lawBody(revisionId: \"` + constitution + `\") { lawTitle } }"}`
+	tests := []struct {
+		mode             string
+		wantCacheControl string
+		wantSchema       bool
+	}{
+		{mode: "on", wantCacheControl: "public, max-age=86400", wantSchema: true},
+		{mode: "admin", wantCacheControl: "no-store", wantSchema: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			s := testsupport.NewServer(t, func(cfg *config.Config) {
+				cfg.GraphQL.Introspection = tt.mode
+				cfg.GraphQL.ResponseCacheSize = 10
+			})
+			post := func(admin bool) (*http.Response, map[string]json.RawMessage) {
+				t.Helper()
+				req, err := http.NewRequest(http.MethodPost, s.URL+"/graphql", strings.NewReader(body))
+				if err != nil {
+					t.Fatalf("Failed to create request: %v", err)
+				}
+				req.Header.Set("Content-Type", "application/json")
+				if admin {
+					req.Header.Set("Authorization", "Bearer "+testsupport.AdminToken)
+				}
+				resp, err := s.Client().Do(req)
+				if err != nil {
+					t.Fatalf("GraphQL request failed: %v", err)
+				}
+				defer resp.Body.Close()
+				var result struct {
+					Data map[string]json.RawMessage `json:"data"`
+				}
+				if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+					t.Fatalf("Failed to decode GraphQL response: %v", err)
+				}
+				return resp, result.Data
+			}
+
+			resp, data := post(true)
+			if got := resp.Header.Get("Cache-Control"); got != tt.wantCacheControl {
+				t.Errorf("admin Cache-Control = %q, want %q", got, tt.wantCacheControl)
+			}
+			if !hasField(data, "__schema") {
+				t.Fatalf("admin response has no schema: %v", data)
+			}
+			_, data = post(false)
+			if got := hasField(data, "__schema"); got != tt.wantSchema {
+				t.Errorf("schema returned to a non-admin = %v, want %v", got, tt.wantSchema)
+			}
+		})
+	}
+}
+
+// hasField reports whether response data has a non-null field.
+func hasField(data map[string]json.RawMessage, name string) bool {
+	value, ok := data[name]
+	return ok && string(value) != "null"
+}
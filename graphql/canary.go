@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"math/rand/v2"
+	"sync"
+
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// canaryKey is the object metadata key tagging EPUBs generated by the
+// canary generator with its converter version.
+const canaryKey = "canaryVersion"
+
+// canaryRollout routes a share of new generations to the generator of an
+// upcoming converter version and compares their outcomes with those of the
+// current generator.
+type canaryRollout struct {
+	version string
+	percent int
+
+	mu sync.Mutex
+	// canary and current count the generations of each generator since the
+	// instance started.
+	canary  cohortStats
+	current cohortStats
+}
+
+// cohortStats counts the generations of one generator.
+type cohortStats struct {
+	started   int64
+	completed int64
+	failed    int64
+	bytes     int64
+	seconds   float64
+}
+
+type canaryMetrics struct {
+	Version string        `json:"version"`
+	Percent int           `json:"percent"`
+	Canary  cohortMetrics `json:"canary"`
+	Current cohortMetrics `json:"current"`
+}
+
+// cohortMetrics reports the generations of one generator. FailureRate is
+// the share of finished attempts that failed, and MeanBytes and
+// MeanSeconds average the EPUB sizes and generation times of the completed
+// ones.
+type cohortMetrics struct {
+	Started     int64   `json:"startedTotal"`
+	Completed   int64   `json:"completedTotal"`
+	Failed      int64   `json:"failedTotal"`
+	FailureRate float64 `json:"failureRate"`
+	MeanBytes   float64 `json:"meanBytes"`
+	MeanSeconds float64 `json:"meanSeconds"`
+}
+
+// newCanaryRollout returns a rollout sending percent of new generations to
+// the generator of version, or nil when percent is not positive.
+func newCanaryRollout(version string, percent int) *canaryRollout {
+	if percent <= 0 {
+		return nil
+	}
+	return &canaryRollout{version: version, percent: percent}
+}
+
+// assign routes a new job of the current converter version to the canary
+// generator by chance, and counts it for its generator.
+func (c *canaryRollout) assign(job *jobs.Job) {
+	if c == nil || job.Version != "" {
+		return
+	}
+	if rand.IntN(100) < c.percent {
+		job.Canary = c.version
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cohort(job).started++
+}
+
+// observe counts a finished attempt of a job: a completion with the EPUB
+// of attrs, or a failure when attrs is nil.
+func (c *canaryRollout) observe(job *jobs.Job, attrs *objects.Attrs) {
+	if c == nil || job.Version != "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.cohort(job)
+	if attrs == nil {
+		stats.failed++
+		return
+	}
+	stats.completed++
+	stats.bytes += attrs.Size
+	if !job.StartedAt.IsZero() && attrs.Created.After(job.StartedAt) {
+		stats.seconds += attrs.Created.Sub(job.StartedAt).Seconds()
+	}
+}
+
+// cohort returns the counts of the generator of a job. The caller holds mu.
+func (c *canaryRollout) cohort(job *jobs.Job) *cohortStats {
+	if job.Canary != "" {
+		return &c.canary
+	}
+	return &c.current
+}
+
+func (c *canaryRollout) metrics() canaryMetrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return canaryMetrics{
+		Version: c.version,
+		Percent: c.percent,
+		Canary:  c.canary.metrics(),
+		Current: c.current.metrics(),
+	}
+}
+
+func (s cohortStats) metrics() cohortMetrics {
+	m := cohortMetrics{Started: s.started, Completed: s.completed, Failed: s.failed}
+	if finished := s.completed + s.failed; finished > 0 {
+		m.FailureRate = float64(s.failed) / float64(finished)
+	}
+	if s.completed > 0 {
+		m.MeanBytes = float64(s.bytes) / float64(s.completed)
+		m.MeanSeconds = s.seconds / float64(s.completed)
+	}
+	return m
+}
+
+// PublishCanary makes the comparison of the canary and current generators
+// available under name with expvar, for the metrics endpoint. It does
+// nothing without EPUB_CANARY_PERCENT.
+func (r *Resolver) PublishCanary(name string) {
+	if r.canary == nil {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return r.canary.metrics()
+	}))
+}
+
+// generatorVersion returns the converter version whose generator runs a
+// job: the canary's for canary jobs, and otherwise the version the job's
+// EPUB is stored under.
+func generatorVersion(job *jobs.Job) string {
+	if job.Canary != "" {
+		return job.Canary
+	}
+	return jobVersion(job)
+}
+
+// tagCanaryEpub records the canary version in the object metadata of an
+// EPUB generated by a canary job, and updates attrs with the result.
+func (r *Resolver) tagCanaryEpub(ctx context.Context, job *jobs.Job, attrs *objects.Attrs) {
+	if job.Canary == "" || attrs.Metadata[canaryKey] == job.Canary {
+		return
+	}
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return
+	}
+	metadata := make(map[string]string, len(attrs.Metadata)+1)
+	for key, value := range attrs.Metadata {
+		metadata[key] = value
+	}
+	metadata[canaryKey] = job.Canary
+	updated, err := bucket.Update(ctx, attrs.Name, metadata, objects.Conditions{MetagenerationMatch: attrs.Metageneration})
+	if err != nil {
+		log.Printf("Failed to tag canary EPUB %s: %v", attrs.Name, err)
+		return
+	}
+	*attrs = *updated
+}
@@ -0,0 +1,58 @@
+package graphql
+
+import (
+	"context"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/locale"
+)
+
+// categoryCountsKey is the key of the counts in categoryCounts.
+const categoryCountsKey = "categories"
+
+// categories lists the e-Gov categories in code order with the number of
+// laws in each.
+func (r *Resolver) categories(ctx context.Context) ([]model1.Category, error) {
+	counts, err := r.categoryCounts.get(ctx, categoryCountsKey, func() (map[string]int, error) {
+		// The count pages through the law list and is shared, so it
+		// outlives the request that started it.
+		return r.countCategories(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+	names := handlers.CategoryNames()
+	result := make([]model1.Category, len(model1.AllCategoryCode))
+	for i, code := range model1.AllCategoryCode {
+		message, _ := locale.Lookup("CategoryCode", string(code))
+		result[i] = model1.Category{
+			Code:     code,
+			Name:     names[i],
+			NameEn:   message.Text(locale.English),
+			LawCount: counts[names[i]],
+		}
+	}
+	return result, nil
+}
+
+// countCategories counts the laws of each category name over the law list.
+func (r *Resolver) countCategories(ctx context.Context) (map[string]int, error) {
+	facets, err := r.lawFacets(ctx, &lawapi.GetLawsParams{})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(facets.Categories))
+	for _, facet := range facets.Categories {
+		counts[facet.Name] = facet.Count
+	}
+	return counts, nil
+}
+
+// categoryDescription describes a category in the requested locale.
+func categoryDescription(ctx context.Context, code model1.CategoryCode, arg *model1.Locale) string {
+	message, _ := locale.Lookup("CategoryCode", string(code))
+	return message.DescriptionText(requestLocale(ctx, arg))
+}
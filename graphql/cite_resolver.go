@@ -0,0 +1,34 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+// cite formats a citation of a law revision, or of an article of its main
+// provision.
+func (r *Resolver) cite(ctx context.Context, revisionID string, article *string, style model1.CitationStyle) (string, error) {
+	law, err := r.cachedLawBody(ctx, revisionID)
+	if err != nil {
+		return "", err
+	}
+
+	var cited *lawdata.Article
+	if article != nil {
+		num := strings.TrimSpace(*article)
+		found, ok := law.FindArticle(num)
+		if !ok {
+			return "", codedErrorf(model1.ErrorCodeBadUserInput, "article %q not found in the main provision of %s", num, law.RevisionID)
+		}
+		cited = &found
+	}
+
+	citation, err := law.Cite(lawdata.CitationStyle(style), cited)
+	if err != nil {
+		return "", withCode(model1.ErrorCodeBadUserInput, err)
+	}
+	return citation, nil
+}
@@ -0,0 +1,213 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// cleanupMetrics counts the objects deleted by cleanups and their bytes,
+// in total and by reason: idle EPUBs, stale jobs, and obsolete versions.
+// It is published with expvar.
+var cleanupMetrics = expvar.NewMap("storage_cleanup")
+
+// cleanupConfig controls the deletion of generated artifacts that are no
+// longer needed.
+type cleanupConfig struct {
+	// maxIdle is how long a generated EPUB is kept without requests.
+	maxIdle time.Duration
+	// statusMaxAge is how long a job without an EPUB is kept after its
+	// last update.
+	statusMaxAge time.Duration
+
+	mu sync.Mutex
+}
+
+// Cleanup deletes EPUBs not requested for the configured idle time, the
+// status of jobs that failed or were abandoned long ago, and the artifacts
+// that other converter versions generated in the bucket. Deleted EPUBs are
+// generated again on their next request. Only one run happens at a time.
+func (r *Resolver) Cleanup(ctx context.Context) (*handlers.CleanupResult, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("EPUB generation")
+	}
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+	if !r.cleanup.mu.TryLock() {
+		return nil, handlers.ErrAlreadyRunning
+	}
+	defer r.cleanup.mu.Unlock()
+
+	records, err := r.jobs.List(ctx, jobs.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %v", err)
+	}
+
+	now := r.clock.Now()
+	result := &handlers.CleanupResult{
+		IdleEpubs: []string{},
+		StaleJobs: []string{},
+	}
+	for _, job := range records {
+		var reason string
+		switch job.Status {
+		case jobs.StatusCompleted:
+			lastUsed := job.CompletedAt
+			if job.AccessedAt.After(lastUsed) {
+				lastUsed = job.AccessedAt
+			}
+			if now.Sub(lastUsed) < r.cleanup.maxIdle {
+				continue
+			}
+			reason = "idle"
+		default:
+			if now.Sub(job.UpdatedAt) < r.cleanup.statusMaxAge {
+				continue
+			}
+			reason = "stale"
+		}
+
+		size, err := r.deleteJob(ctx, bucket, job)
+		result.ReclaimedBytes += size
+		cleanupMetrics.Add(reason+"Bytes", size)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		if reason == "idle" {
+			result.IdleEpubs = append(result.IdleEpubs, job.ID)
+		} else {
+			result.StaleJobs = append(result.StaleJobs, job.ID)
+		}
+	}
+
+	if err := r.deleteObsoleteVersions(ctx, bucket, result); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	cleanupMetrics.Add("runs", 1)
+	cleanupMetrics.Add("reclaimedBytes", result.ReclaimedBytes)
+	return result, nil
+}
+
+// RunCleanup calls Cleanup every interval until ctx is canceled.
+func (r *Resolver) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := r.Cleanup(ctx)
+			if err != nil {
+				log.Printf("Cleanup failed: %v", err)
+				continue
+			}
+			log.Printf("Cleanup deleted %d idle EPUBs, %d stale jobs, and %d obsolete objects (%d bytes), %d errors",
+				len(result.IdleEpubs), len(result.StaleJobs), result.ObsoleteObjects, result.ReclaimedBytes, len(result.Errors))
+		}
+	}
+}
+
+// deleteJob deletes the EPUB, status, and manifest of a job and then its
+// record, and returns the bytes reclaimed. The record is kept when an
+// object could not be deleted, so that the next run tries again.
+func (r *Resolver) deleteJob(ctx context.Context, bucket objects.Bucket, job *jobs.Job) (int64, error) {
+	var reclaimed int64
+	for _, name := range []string{
+		jobObject(job, ".epub"),
+		jobObject(job, ".status"),
+		jobs.ManifestPath(jobVersion(job), outputID(job)),
+	} {
+		size, err := deleteObject(ctx, bucket, name)
+		reclaimed += size
+		if err != nil {
+			return reclaimed, err
+		}
+	}
+	if err := r.jobs.Delete(ctx, job.ID); err != nil {
+		return reclaimed, err
+	}
+	return reclaimed, nil
+}
+
+// deleteObsoleteVersions deletes the EPUBs, status files, and manifests
+// below the version directories other than APP_VERSION and the versions
+// clients can still pin. Other objects, such as users' presets and
+// libraries, bundles, and converted documents, are left alone.
+func (r *Resolver) deleteObsoleteVersions(ctx context.Context, bucket objects.Bucket, result *handlers.CleanupResult) error {
+	dirs, err := bucket.Dirs(ctx, "")
+	if err != nil {
+		return fmt.Errorf("failed to list version directories: %v", err)
+	}
+	var prefixes []string
+	for _, dir := range dirs {
+		version := strings.TrimSuffix(dir, "/")
+		if converterVersionPattern.MatchString(version) && version != APP_VERSION && !r.generator.versions[version] {
+			prefixes = append(prefixes, dir)
+		}
+	}
+
+	for _, prefix := range prefixes {
+		listed, err := bucket.List(ctx, prefix)
+		if err != nil {
+			return fmt.Errorf("failed to list objects of %s: %v", strings.TrimSuffix(prefix, "/"), err)
+		}
+		for _, attrs := range listed {
+			if !generatedArtifact(strings.TrimPrefix(attrs.Name, prefix)) {
+				continue
+			}
+			err = bucket.Delete(ctx, attrs.Name, objects.Conditions{GenerationMatch: attrs.Generation})
+			if err != nil && !errors.Is(err, objects.ErrNotExist) {
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to delete %s: %v", attrs.Name, err))
+				continue
+			}
+			result.ObsoleteObjects++
+			result.ReclaimedBytes += attrs.Size
+			cleanupMetrics.Add("obsoleteBytes", attrs.Size)
+		}
+	}
+	return nil
+}
+
+// generatedArtifact reports whether an object, named relative to its
+// version directory, is an EPUB, status file, manifest, or job record
+// written for a generation. Those are directly in the version directory or
+// in a tenant's; subdirectories such as bundles/ and converted/ hold books
+// that are kept.
+func generatedArtifact(name string) bool {
+	if _, rest := tenant.SplitID(name); strings.Contains(rest, "/") {
+		return false
+	}
+	return strings.HasSuffix(name, ".epub") || strings.HasSuffix(name, ".status") || strings.HasSuffix(name, ".job.json") || strings.HasSuffix(name, ".record.json")
+}
+
+// deleteObject deletes an object and returns its size. A missing object
+// is not an error.
+func deleteObject(ctx context.Context, bucket objects.Bucket, name string) (int64, error) {
+	attrs, err := bucket.Attrs(ctx, name)
+	if errors.Is(err, objects.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	err = bucket.Delete(ctx, name, objects.Conditions{GenerationMatch: attrs.Generation})
+	if err != nil && !errors.Is(err, objects.ErrNotExist) {
+		return 0, fmt.Errorf("failed to delete %s: %v", name, err)
+	}
+	return attrs.Size, nil
+}
@@ -0,0 +1,37 @@
+package graphql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+func TestCleanupKeepsBundlesAndConvertedDocuments(t *testing.T) {
+	s := testsupport.NewServer(t)
+	s.Clock.Set(time.Now().Add(s.Config.Cleanup.MaxIdle + time.Hour))
+	// The obsolete version's EPUB is deleted, the books beside it are not.
+	tests := []struct {
+		name string
+		kept bool
+	}{
+		{name: "v1.0.0/converted/0123456789abcdef.epub", kept: true},
+		{name: "v1.0.0/bundles/b-1.epub", kept: true},
+		{name: "v1.0.0/bundles/b-1.json", kept: true},
+		{name: "v0.9.0/" + constitution + ".epub", kept: false},
+		{name: "v0.9.0/bundles/b-0.epub", kept: true},
+	}
+	for _, tt := range tests {
+		s.Storage.Put(testsupport.Bucket, tt.name, []byte("PK"), "application/epub+zip")
+	}
+
+	if _, err := s.API.Resolver.Cleanup(context.Background()); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	for _, tt := range tests {
+		if _, ok := s.Storage.Get(testsupport.Bucket, tt.name); ok != tt.kept {
+			t.Errorf("%s kept = %v, want %v", tt.name, ok, tt.kept)
+		}
+	}
+}
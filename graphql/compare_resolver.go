@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+)
+
+// compareRevisions fetches two revisions of a law and returns the articles
+// and paragraphs that differ between them.
+func (r *Resolver) compareRevisions(ctx context.Context, lawID, from, to string) (*model1.RevisionComparison, error) {
+	for _, revisionID := range []string{from, to} {
+		if parsed, err := lawid.Parse(revisionID); err != nil || parsed.Kind != lawid.RevisionID || parsed.LawID != lawID {
+			return nil, codedErrorf(model1.ErrorCodeInvalidLawID, "invalid revision ID %q: expected a revision of law %s", revisionID, lawID)
+		}
+	}
+
+	before, err := r.getLawBody(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := r.getLawBody(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := lawdata.Compare(before, after)
+	articles := make([]model1.ArticleChange, 0, len(diffs))
+	for _, diff := range diffs {
+		paragraphs := make([]model1.ParagraphChange, 0, len(diff.Paragraphs))
+		for _, p := range diff.Paragraphs {
+			paragraphs = append(paragraphs, model1.ParagraphChange{
+				Num:    p.Num,
+				Change: convertChange(p.Change),
+				Before: optionalString(p.Before),
+				After:  optionalString(p.After),
+			})
+		}
+		articles = append(articles, model1.ArticleChange{
+			Provision:     diff.Provision,
+			Num:           diff.Num,
+			Title:         diff.Title,
+			Change:        convertChange(diff.Change),
+			CaptionBefore: optionalString(diff.CaptionBefore),
+			CaptionAfter:  optionalString(diff.CaptionAfter),
+			Paragraphs:    paragraphs,
+		})
+	}
+
+	return &model1.RevisionComparison{
+		LawID:    lawID,
+		From:     from,
+		To:       to,
+		Articles: articles,
+	}, nil
+}
+
+func convertChange(change lawdata.Change) model1.ChangeType {
+	switch {
+	case change == lawdata.ChangeAdded:
+		return model1.ChangeTypeAdded
+	case change == lawdata.ChangeRemoved:
+		return model1.ChangeTypeRemoved
+	default:
+		return model1.ChangeTypeModified
+	}
+}
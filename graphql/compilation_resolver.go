@@ -0,0 +1,172 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/library"
+)
+
+// maxCompilations bounds the compilations of a library.
+const maxCompilations = 50
+
+// myCompilations lists the caller's compilations, newest first, with the
+// current status of their books.
+func (r *Resolver) myCompilations(ctx context.Context) ([]model1.Compilation, error) {
+	lib, err := r.myLibrary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model1.Compilation, len(lib.Compilations))
+	for i, compilation := range lib.Compilations {
+		result[i] = convertCompilation(compilation)
+		if compilation.BookID == "" || r.generator.bucketName == "" {
+			continue
+		}
+		book, err := r.getEpubBundle(ctx, compilation.BookID)
+		if err != nil {
+			return nil, err
+		}
+		result[i].Book = book
+	}
+	return result, nil
+}
+
+// saveCompilation saves a compilation in the caller's library, replacing
+// the one with input.ID when given, records it in the audit log, and starts
+// generating its book in the background.
+func (r *Resolver) saveCompilation(ctx context.Context, input model1.CompilationInput) (*model1.Compilation, error) {
+	start := r.clock.Now()
+	compilation, err := r.startCompilation(ctx, input)
+
+	entry := audit.Entry{
+		Operation: "saveCompilation",
+	}
+	if compilation != nil {
+		entry.RevisionID = strings.Join(compilation.RevisionIds, ",")
+		if compilation.Book != nil {
+			entry.Filename = compilation.Book.ID + ".epub"
+			entry.Result = string(compilation.Book.Status)
+		}
+	}
+	r.recordAudit(ctx, entry, start, err)
+
+	return compilation, err
+}
+
+func (r *Resolver) startCompilation(ctx context.Context, input model1.CompilationInput) (*model1.Compilation, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("compilation")
+	}
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return nil, withCode(model1.ErrorCodeBadUserInput, errors.New("title must not be empty"))
+	}
+	if len(input.RevisionIds) == 0 {
+		return nil, withCode(model1.ErrorCodeBadUserInput, errors.New("revisionIds must not be empty"))
+	}
+	if len(input.RevisionIds) > maxBundleLaws {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "a compilation holds at most %d laws, got %d", maxBundleLaws, len(input.RevisionIds))
+	}
+	ids := make([]string, len(input.RevisionIds))
+	for i, id := range input.RevisionIds {
+		parsed, err := parseLawID(strings.TrimSpace(id))
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = parsed.Value
+	}
+
+	book, err := newBook(ids, r.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	book.Title = &title
+
+	now := r.clock.Now().UTC()
+	compilation := library.Compilation{
+		Title:     title,
+		IDs:       ids,
+		BookID:    book.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if input.ID != nil {
+		compilation.ID = *input.ID
+	} else {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate compilation ID: %v", err)
+		}
+		compilation.ID = hex.EncodeToString(random)
+	}
+
+	_, err = r.library.Update(ctx, tenantID, userID, func(lib *library.Library) error {
+		i := slices.IndexFunc(lib.Compilations, func(c library.Compilation) bool { return c.ID == compilation.ID })
+		if i >= 0 {
+			compilation.CreatedAt = lib.Compilations[i].CreatedAt
+			lib.Compilations[i] = compilation
+			return nil
+		}
+		if input.ID != nil {
+			return codedErrorf(model1.ErrorCodeNotFound, "compilation %q not found", compilation.ID)
+		}
+		if len(lib.Compilations) >= maxCompilations {
+			return codedErrorf(model1.ErrorCodeBadUserInput, "at most %d compilations can be saved", maxCompilations)
+		}
+		lib.Compilations = append([]library.Compilation{compilation}, lib.Compilations...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.startBook(ctx, book, true); err != nil {
+		return nil, err
+	}
+
+	result := convertCompilation(compilation)
+	result.Book = book
+	return &result, nil
+}
+
+// deleteCompilation removes a compilation. It reports false when there is
+// no such compilation.
+func (r *Resolver) deleteCompilation(ctx context.Context, id string) (bool, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return false, err
+	}
+	removed := false
+	_, err = r.library.Update(ctx, tenantID, userID, func(lib *library.Library) error {
+		before := len(lib.Compilations)
+		lib.Compilations = slices.DeleteFunc(lib.Compilations, func(c library.Compilation) bool { return c.ID == id })
+		removed = len(lib.Compilations) < before
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return removed, nil
+}
+
+func convertCompilation(compilation library.Compilation) model1.Compilation {
+	return model1.Compilation{
+		ID:          compilation.ID,
+		Title:       compilation.Title,
+		RevisionIds: slices.Clone(compilation.IDs),
+		CreatedAt:   compilation.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   compilation.UpdatedAt.Format(time.RFC3339),
+	}
+}
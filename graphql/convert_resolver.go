@@ -0,0 +1,185 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+// convertXML converts an uploaded law XML document to EPUB in-process and
+// returns it as a signed URL or an inline base64 payload. Every conversion
+// is recorded in the audit log.
+func (r *Resolver) convertXML(ctx context.Context, file graphql.Upload, output model1.ConvertOutput, furigana, accessible, vertical bool) (*model1.ConvertResult, error) {
+	start := r.clock.Now()
+	result, err := r.convertUpload(ctx, file, output, furigana, accessible, vertical)
+
+	entry := audit.Entry{
+		Operation: "convertXml",
+		Filename:  file.Filename,
+		Output:    string(output),
+	}
+	if result != nil {
+		entry.Result = string(model1.EpubStatusCompleted)
+	}
+	r.recordAudit(ctx, entry, start, err)
+
+	return result, err
+}
+
+// validateXML checks an uploaded law XML document against the schema
+// without converting it.
+func (r *Resolver) validateXML(ctx context.Context, file graphql.Upload) (*model1.XMLValidationResult, error) {
+	data, err := io.ReadAll(file.File)
+	if err != nil {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "failed to read upload: %v", err)
+	}
+
+	var errs []lawdata.ValidationError
+	err = r.pool.Run(ctx, sandbox.Estimate(len(data)), func() error {
+		errs = lawdata.ValidateDocument(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &model1.XMLValidationResult{
+		Valid:  len(errs) == 0,
+		Errors: make([]model1.XMLValidationError, 0, len(errs)),
+	}
+	for _, e := range errs {
+		result.Errors = append(result.Errors, model1.XMLValidationError{
+			Line:    e.Line,
+			Column:  e.Column,
+			Path:    e.Path,
+			Message: e.Message,
+		})
+	}
+	return result, nil
+}
+
+func (r *Resolver) convertUpload(ctx context.Context, file graphql.Upload, output model1.ConvertOutput, furigana, accessible, vertical bool) (*model1.ConvertResult, error) {
+	opts := lawdata.Options{Accessible: accessible, Vertical: vertical}
+	if furigana {
+		if r.furigana == nil {
+			return nil, withCode(model1.ErrorCodeNotConfigured, errors.New("furigana is not available: FURIGANA_ANALYZER is not set"))
+		}
+		opts.Ruby = r.furigana
+	}
+
+	data, err := io.ReadAll(file.File)
+	if err != nil {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "failed to read upload: %v", err)
+	}
+
+	// Identical uploads share an identifier and storage path.
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:16]
+	if furigana {
+		hash += "-furigana"
+	}
+	if accessible {
+		hash += "-accessible"
+	}
+	if vertical {
+		hash += "-vertical"
+	}
+
+	var law *lawdata.Law
+	var buf bytes.Buffer
+	err = r.pool.Run(ctx, sandbox.Estimate(len(data)), func() error {
+		xmlData, err := lawdata.DecodeLawDocument(data)
+		if err != nil {
+			return codedErrorf(model1.ErrorCodeConversionFailed, "failed to read law document: %v", err)
+		}
+		law, err = lawdata.ParseLaw(xmlData)
+		if err != nil {
+			return codedErrorf(model1.ErrorCodeConversionFailed, "failed to parse law XML: %v", err)
+		}
+		law.TitleEn = r.titles.TitleEn("", law.LawNum)
+		if err := lawdata.WriteEPUB(&buf, law, "urn:jplaw2epub:converted:"+hash, opts); err != nil {
+			return err
+		}
+		_, err = lawdata.CheckEPUB(buf.Bytes())
+		return withCode(model1.ErrorCodeConversionFailed, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The upload's name stands in for the ID in filenames.
+	name := strings.TrimSuffix(path.Base(file.Filename), path.Ext(file.Filename))
+	if file.Filename == "" {
+		name = hash
+	}
+	fields := naming.FromLaw(name, law)
+
+	result := &model1.ConvertResult{
+		Filename: r.filenames.Filename(fields),
+		LawTitle: law.LawTitle,
+		Size:     buf.Len(),
+	}
+
+	switch output {
+	case model1.ConvertOutputBase64:
+		encoded := base64.StdEncoding.EncodeToString(buf.Bytes())
+		result.Base64 = &encoded
+	case model1.ConvertOutputURL:
+		signedURL, err := r.storeConvertedEpub(ctx, hash, buf.Bytes(), fields)
+		if err != nil {
+			return nil, err
+		}
+		result.SignedURL = &signedURL
+	default:
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "unsupported output %s", output)
+	}
+
+	return result, nil
+}
+
+// storeConvertedEpub uploads a converted EPUB to the bucket with its naming
+// fields and returns a signed download URL.
+func (r *Resolver) storeConvertedEpub(ctx context.Context, hash string, data []byte, fields naming.Fields) (string, error) {
+	if r.generator.bucketName == "" {
+		return "", withCode(model1.ErrorCodeNotConfigured, errors.New("URL output is not configured: EPUB_BUCKET_NAME is not set (use BASE64 output)"))
+	}
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return "", err
+	}
+	objectPath := fmt.Sprintf("%s/converted/%s.epub", storagePrefix(ctx), hash)
+
+	metadata := fields.Metadata()
+	metadata[checksumKey] = checksum(data)
+	writer := bucket.NewWriter(ctx, objectPath, objects.WriteOptions{ContentType: "application/epub+zip", Metadata: metadata})
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return "", fmt.Errorf("failed to upload EPUB: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to upload EPUB: %v", err)
+	}
+
+	signedURL, err := generateSignedURL(bucket, objectPath, 1*time.Hour, r.filenames.ContentDisposition(fields))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate signed URL: %v", err)
+	}
+	return signedURL, nil
+}
@@ -0,0 +1,232 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/presets"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+// conversion selects the options of an EPUB converted in-process, which
+// the generator job does not offer.
+type conversion struct {
+	// DiffAgainst is the revision whose changes are marked.
+	DiffAgainst string
+	// Preset is the name of the preset applied.
+	Preset string
+	// Vertical overrides the writing mode of the preset when not nil.
+	Vertical *bool
+	// Cover overrides the cover of the preset when not nil.
+	Cover *model1.CoverStyle
+	// Font is the name of a configured font that overrides the font of
+	// the preset.
+	Font string
+	// StripFonts leaves out the font of the preset.
+	StripFonts bool
+}
+
+// inProcess reports whether an EPUB must be converted in-process.
+func (c conversion) inProcess() bool {
+	return c.DiffAgainst != "" || c.Preset != "" || c.Vertical != nil || c.Cover != nil || c.Font != "" || c.StripFonts
+}
+
+// name returns the name the converted EPUB of id is stored under.
+func (c conversion) name(id string) string {
+	name := id
+	if c.DiffAgainst != "" {
+		name += "-diff-" + c.DiffAgainst
+	}
+	if c.Preset != "" {
+		name += "-preset-" + c.Preset
+	}
+	if c.Vertical != nil {
+		name += "-" + writingMode(*c.Vertical)
+	}
+	if c.Cover != nil {
+		name += "-cover-" + strings.ToLower(string(*c.Cover))
+	}
+	if c.Font != "" {
+		name += "-font-" + c.Font
+	}
+	if c.StripFonts {
+		name += "-nofonts"
+	}
+	return name
+}
+
+// writingMode names the writing mode of vertical in EPUB names and URNs.
+func writingMode(vertical bool) string {
+	if vertical {
+		return "vertical"
+	}
+	return "horizontal"
+}
+
+// getConvertedEpub converts a revision in-process, with its changes from
+// diffAgainst marked and the options of a preset, a writing mode, a cover,
+// and a font applied when given, and records the request in the audit log.
+func (r *Resolver) getConvertedEpub(ctx context.Context, revisionID string, conv conversion, articles []string) (*model1.Epub, error) {
+	start := r.clock.Now()
+	epub, err := r.resolveConvertedEpub(ctx, revisionID, conv, articles)
+
+	entry := audit.Entry{
+		Operation:   "epub",
+		RevisionID:  revisionID,
+		Articles:    articles,
+		DiffAgainst: conv.DiffAgainst,
+		Preset:      conv.Preset,
+	}
+	if epub != nil {
+		entry.Result = string(epub.Status)
+	}
+	r.recordAudit(ctx, entry, start, err)
+
+	return epub, err
+}
+
+// resolveConvertedEpub converts in-process, since the generator job neither
+// compares revisions nor applies presets, writing modes, covers, or fonts,
+// and returns the book
+// completed with a signed URL.
+func (r *Resolver) resolveConvertedEpub(ctx context.Context, revisionID string, conv conversion, articles []string) (*model1.Epub, error) {
+	if len(articles) > 0 {
+		return nil, withCode(model1.ErrorCodeBadUserInput, errors.New("diffAgainst, preset, vertical, cover, and fonts cannot be combined with articles"))
+	}
+
+	var preset *presets.Preset
+	var opts lawdata.Options
+	cover := model1.CoverStyleNone
+	if conv.Preset != "" {
+		var err error
+		if preset, err = r.findPreset(ctx, conv.Preset); err != nil {
+			return nil, err
+		}
+		if opts, err = r.presetOptions(preset); err != nil {
+			return nil, err
+		}
+		if preset.Cover != "" {
+			cover = model1.CoverStyle(preset.Cover)
+		}
+	}
+	if conv.Vertical != nil {
+		opts.Vertical = *conv.Vertical
+	}
+	if conv.Cover != nil {
+		cover = *conv.Cover
+	}
+	var err error
+	switch {
+	case conv.StripFonts:
+		opts.Font = nil
+	case conv.Font != "":
+		if opts.Font, err = r.font(conv.Font); err != nil {
+			return nil, err
+		}
+	}
+	var logoSum string
+	if opts.Cover, logoSum, err = r.coverOptions(ctx, cover); err != nil {
+		return nil, err
+	}
+
+	// e-Gov is asked before taking a converter worker.
+	lawID, data, err := r.fetchLawBody(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	estimate := sandbox.Estimate(len(data.XML))
+
+	name := conv.name(revisionID)
+	urn := "urn:jplaw2epub:" + revisionID
+	etagParts := []string{revisionID, APP_VERSION, "application/epub+zip"}
+	var beforeID string
+	var beforeData *lawdata.LawData
+	if conv.DiffAgainst != "" {
+		if beforeID, beforeData, err = r.fetchLawBody(ctx, conv.DiffAgainst); err != nil {
+			return nil, err
+		}
+		estimate += sandbox.Estimate(len(beforeData.XML))
+		urn += ":diff:" + conv.DiffAgainst
+		etagParts = append(etagParts, "diff", conv.DiffAgainst)
+	}
+	if preset != nil {
+		urn += ":preset:" + preset.Name
+		// Editing a preset changes the books it produces.
+		etagParts = append(etagParts, "preset", preset.Name, preset.UpdatedAt.Format(time.RFC3339Nano))
+	}
+	if conv.Vertical != nil {
+		urn += ":" + writingMode(*conv.Vertical)
+		etagParts = append(etagParts, writingMode(*conv.Vertical))
+	}
+	if conv.Cover != nil {
+		urn += ":cover:" + strings.ToLower(string(*conv.Cover))
+	}
+	if opts.Cover != nil {
+		// Uploading another logo changes the cover.
+		etagParts = append(etagParts, "cover", string(cover), logoSum)
+	}
+	if conv.Font != "" {
+		urn += ":font:" + conv.Font
+	}
+	if conv.StripFonts {
+		urn += ":nofonts"
+	}
+	if opts.Font != nil {
+		// Replacing a font file changes the books that embed it.
+		etagParts = append(etagParts, "font", opts.Font.Name, opts.Font.Sum)
+	}
+
+	var buf bytes.Buffer
+	var fields naming.Fields
+	err = r.pool.Run(ctx, estimate, func() error {
+		law, err := r.parseLawBody(lawID, data)
+		if err != nil {
+			return err
+		}
+		fields = naming.FromLaw(name, law)
+		if beforeData != nil {
+			before, err := r.parseLawBody(beforeID, beforeData)
+			if err != nil {
+				return err
+			}
+			if err := lawdata.MarkChanges(before, law); err != nil {
+				return withCode(model1.ErrorCodeConversionFailed, err)
+			}
+		}
+		if err := lawdata.WriteEPUB(&buf, law, urn, opts); err != nil {
+			return withCode(model1.ErrorCodeConversionFailed, err)
+		}
+		_, err = lawdata.CheckEPUB(buf.Bytes())
+		return withCode(model1.ErrorCodeConversionFailed, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	signedURL, err := r.storeConvertedEpub(ctx, name, buf.Bytes(), fields)
+	if err != nil {
+		return nil, err
+	}
+
+	size := buf.Len()
+	sum := checksum(buf.Bytes())
+	etag := handlers.ComputeETag(etagParts...)
+	return &model1.Epub{
+		ID:          revisionID,
+		SignedURL:   &signedURL,
+		DownloadURL: downloadURL(name, APP_VERSION),
+		Size:        &size,
+		Etag:        &etag,
+		Sha256:      &sum,
+		Status:      model1.EpubStatusCompleted,
+		// Converted in-process by the current converter.
+		ConverterVersion: APP_VERSION,
+	}, nil
+}
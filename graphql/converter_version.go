@@ -0,0 +1,81 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+)
+
+// converterVersionPattern matches converter versions such as APP_VERSION,
+// which are also the top-level directories of the bucket.
+var converterVersionPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+type converterVersionKey struct{}
+
+// contextWithConverterVersion records the converter version pinned by the
+// request.
+func contextWithConverterVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, converterVersionKey{}, version)
+}
+
+// requestedVersion returns the converter version pinned by the request, or
+// APP_VERSION when none was.
+func requestedVersion(ctx context.Context) (string, error) {
+	version, _ := ctx.Value(converterVersionKey{}).(string)
+	if version == "" {
+		return APP_VERSION, nil
+	}
+	if !converterVersionPattern.MatchString(version) {
+		return "", codedErrorf(model1.ErrorCodeBadUserInput, "invalid converter version %q: expected a version such as %s", version, APP_VERSION)
+	}
+	return version, nil
+}
+
+// pinnableVersions returns the older converter versions of
+// EPUB_JOB_VERSIONS.
+func pinnableVersions(jobs map[string]string) map[string]bool {
+	versions := make(map[string]bool, len(jobs))
+	for version := range jobs {
+		if version != APP_VERSION {
+			versions[version] = true
+		}
+	}
+	return versions
+}
+
+// pinnedJobID returns the job ID of a document generated by an older
+// converter version, which is kept apart from the job of APP_VERSION.
+func pinnedJobID(jobID, version string) string {
+	if version == APP_VERSION {
+		return jobID
+	}
+	return jobID + "@" + version
+}
+
+// jobVersion returns the converter version generating a job.
+func jobVersion(job *jobs.Job) string {
+	if job.Version == "" {
+		return APP_VERSION
+	}
+	return job.Version
+}
+
+// outputID returns the ID the generator writes a job's document under,
+// which is the job ID without the pinned version.
+func outputID(job *jobs.Job) string {
+	if job.Version == "" {
+		return job.ID
+	}
+	return strings.TrimSuffix(job.ID, "@"+job.Version)
+}
+
+// jobObject returns the object name of a job's document, status file, or
+// manifest, by its extension, in the directory of the job's converter
+// version.
+func jobObject(job *jobs.Job, ext string) string {
+	return fmt.Sprintf("%s/%s%s", jobVersion(job), outputID(job), ext)
+}
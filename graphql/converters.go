@@ -1,6 +1,10 @@
 package graphql
 
 import (
+	"slices"
+	"sort"
+	"time"
+
 	"go.ngs.io/jplaw2epub-web-api/graphql/model"
 
 	jplaw "go.ngs.io/jplaw-api-v2"
@@ -241,3 +245,116 @@ func convertMissionToModel(m *jplaw.Mission) *model.Mission {
 	}
 	return &result
 }
+
+// convertDateToEraDate returns nil for dates missing upstream.
+func convertDateToEraDate(d jplaw.Date) *time.Time {
+	t := time.Time(d)
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// sortKey returns the sort key of a search, which defaults to RELEVANCE.
+func sortKey(key *model.LawSort) model.LawSort {
+	if key == nil || !key.IsValid() {
+		return model.LawSortRelevance
+	}
+	return *key
+}
+
+// sortOrder returns the order of a search, which defaults to ASC.
+func sortOrder(order *model.SortOrder) model.SortOrder {
+	if order == nil || !order.IsValid() {
+		return model.SortOrderAsc
+	}
+	return *order
+}
+
+// lawSortKey returns the value a law is sorted by, and false when the law
+// has none. RELEVANCE has no key; e-Gov order is kept.
+func lawSortKey(key model.LawSort, lawInfo *jplaw.LawInfo, revisionInfo, currentRevisionInfo *jplaw.RevisionInfo) (string, bool) {
+	switch key {
+	case model.LawSortPromulgationDate:
+		if lawInfo == nil || time.Time(lawInfo.PromulgationDate).IsZero() {
+			return "", false
+		}
+		return time.Time(lawInfo.PromulgationDate).Format(time.DateOnly), true
+	case model.LawSortEnforcementDate:
+		if revisionInfo == nil {
+			revisionInfo = currentRevisionInfo
+		}
+		if revisionInfo == nil || time.Time(revisionInfo.AmendmentEnforcementDate).IsZero() {
+			return "", false
+		}
+		return time.Time(revisionInfo.AmendmentEnforcementDate).Format(time.DateOnly), true
+	case model.LawSortTitleKana:
+		if revisionInfo == nil || revisionInfo.LawTitleKana == "" {
+			return "", false
+		}
+		return revisionInfo.LawTitleKana, true
+	case model.LawSortRelevance:
+		return "", false
+	default:
+		return "", false
+	}
+}
+
+// lessBySortKey orders two laws by their sort keys. Laws without a key come
+// last in either order, and ties are broken by law ID.
+func lessBySortKey(a, b string, aOK, bOK bool, order model.SortOrder, aID, bID string) bool {
+	switch {
+	case aOK != bOK:
+		return aOK
+	case a != b:
+		if order == model.SortOrderDesc {
+			return a > b
+		}
+		return a < b
+	default:
+		return aID < bID
+	}
+}
+
+// sortLawItems returns a sorted copy of items, which may be shared with the
+// upstream cache. RELEVANCE in descending order reverses e-Gov order.
+func sortLawItems(items []jplaw.LawItem, key model.LawSort, order model.SortOrder) []jplaw.LawItem {
+	sorted := append([]jplaw.LawItem(nil), items...)
+	if key == model.LawSortRelevance {
+		if order == model.SortOrderDesc {
+			slices.Reverse(sorted)
+		}
+		return sorted
+	}
+	sort.SliceStable(sorted, func(i, k int) bool {
+		a, aOK := lawSortKey(key, sorted[i].LawInfo, sorted[i].RevisionInfo, sorted[i].CurrentRevisionInfo)
+		b, bOK := lawSortKey(key, sorted[k].LawInfo, sorted[k].RevisionInfo, sorted[k].CurrentRevisionInfo)
+		return lessBySortKey(a, b, aOK, bOK, order, lawInfoID(sorted[i].LawInfo), lawInfoID(sorted[k].LawInfo))
+	})
+	return sorted
+}
+
+// sortKeywordItems returns a sorted copy of items, which may be shared with
+// the upstream cache.
+func sortKeywordItems(items []jplaw.KeywordItem, key model.LawSort, order model.SortOrder) []jplaw.KeywordItem {
+	sorted := append([]jplaw.KeywordItem(nil), items...)
+	if key == model.LawSortRelevance {
+		if order == model.SortOrderDesc {
+			slices.Reverse(sorted)
+		}
+		return sorted
+	}
+	sort.SliceStable(sorted, func(i, k int) bool {
+		a, aOK := lawSortKey(key, sorted[i].LawInfo, sorted[i].RevisionInfo, nil)
+		b, bOK := lawSortKey(key, sorted[k].LawInfo, sorted[k].RevisionInfo, nil)
+		return lessBySortKey(a, b, aOK, bOK, order, lawInfoID(sorted[i].LawInfo), lawInfoID(sorted[k].LawInfo))
+	})
+	return sorted
+}
+
+func lawInfoID(info *jplaw.LawInfo) string {
+	if info == nil {
+		return ""
+	}
+	return info.LawId
+}
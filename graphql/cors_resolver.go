@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"context"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+)
+
+// getCorsConfig reports the allowed origins and per-route CORS options the
+// server was started with. Only the admin may read them.
+func (r *Resolver) getCorsConfig(ctx context.Context) (*model1.CorsConfig, error) {
+	if !handlers.IsAdmin(ctx) {
+		return nil, errAdminRequired
+	}
+	origins := r.allowedOrigins
+	if origins == nil {
+		origins = []string{}
+	}
+
+	var registered []handlers.CORSRoute
+	if r.corsRoutes != nil {
+		registered = r.corsRoutes()
+	}
+	routes := make([]model1.CorsRoute, 0, len(registered))
+	for _, route := range registered {
+		routes = append(routes, model1.CorsRoute{
+			Path:          route.Path,
+			Methods:       nonNilStrings(route.Options.Methods),
+			Headers:       nonNilStrings(route.Options.Headers),
+			ExposeHeaders: nonNilStrings(route.Options.ExposeHeaders),
+			MaxAge:        route.Options.MaxAge,
+		})
+	}
+
+	return &model1.CorsConfig{Origins: origins, Routes: routes}, nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
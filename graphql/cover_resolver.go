@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// coverOptions returns the cover of a style, with the logo of the caller's
+// tenant for LOGO, and the digest of the logo, which is empty without one.
+func (r *Resolver) coverOptions(ctx context.Context, style model1.CoverStyle) (*lawdata.Cover, string, error) {
+	switch style {
+	case model1.CoverStyleTitle:
+		return &lawdata.Cover{}, "", nil
+	case model1.CoverStyleLogo:
+		bucket, err := r.epubBucket()
+		if err != nil {
+			return nil, "", err
+		}
+		reader, err := bucket.NewReader(ctx, coverLogoPath(tenant.IDFromContext(ctx)))
+		if errors.Is(err, objects.ErrNotExist) {
+			return &lawdata.Cover{}, "", nil
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read cover logo: %v", err)
+		}
+		defer reader.Close()
+		logo, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read cover logo: %v", err)
+		}
+		return &lawdata.Cover{Logo: logo}, checksum(logo), nil
+	default:
+		return nil, "", nil
+	}
+}
+
+// logoTenant returns the tenant whose cover logo an admin request changes:
+// the one named by tenantArg, or none for requests without a tenant.
+func logoTenant(ctx context.Context, tenantArg *string) (string, error) {
+	if !handlers.IsAdmin(ctx) {
+		return "", errAdminRequired
+	}
+	if tenantArg == nil || *tenantArg == "" {
+		return "", nil
+	}
+	if err := tenant.ValidateID(*tenantArg); err != nil {
+		return "", withCode(model1.ErrorCodeBadUserInput, err)
+	}
+	return *tenantArg, nil
+}
+
+// uploadCoverLogo validates and stores the cover logo of a tenant.
+func (r *Resolver) uploadCoverLogo(ctx context.Context, file graphql.Upload, tenantArg *string) (*model1.CoverLogo, error) {
+	tenantID, err := logoTenant(ctx, tenantArg)
+	if err != nil {
+		return nil, err
+	}
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file.File, lawdata.MaxLogoSize+1))
+	if err != nil {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "failed to read upload: %v", err)
+	}
+	mediaType, err := lawdata.ValidateLogo(data)
+	if err != nil {
+		return nil, withCode(model1.ErrorCodeBadUserInput, err)
+	}
+
+	sum := checksum(data)
+	uploadCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	writer := bucket.NewWriter(uploadCtx, coverLogoPath(tenantID), objects.WriteOptions{ContentType: mediaType, Metadata: map[string]string{checksumKey: sum}})
+	if _, err := writer.Write(data); err != nil {
+		abort()
+		_ = writer.Close()
+		return nil, fmt.Errorf("failed to upload cover logo: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to upload cover logo: %v", err)
+	}
+
+	return &model1.CoverLogo{
+		Tenant:    optionalString(tenantID),
+		MediaType: mediaType,
+		Size:      len(data),
+		Sha256:    sum,
+		UpdatedAt: r.clock.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// deleteCoverLogo removes the cover logo of a tenant. It reports false when
+// there is none.
+func (r *Resolver) deleteCoverLogo(ctx context.Context, tenantArg *string) (bool, error) {
+	tenantID, err := logoTenant(ctx, tenantArg)
+	if err != nil {
+		return false, err
+	}
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return false, err
+	}
+	err = bucket.Delete(ctx, coverLogoPath(tenantID), objects.Conditions{})
+	if errors.Is(err, objects.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete cover logo: %v", err)
+	}
+	return true, nil
+}
+
+// coverLogoPath is the object holding the cover logo of a tenant, next to
+// its presets.
+func coverLogoPath(tenantID string) string {
+	return tenant.Prefix(APP_VERSION, tenantID) + "/cover-logo"
+}
@@ -0,0 +1,113 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	run "cloud.google.com/go/run/apiv2"
+	"cloud.google.com/go/run/apiv2/runpb"
+	jplaw "go.ngs.io/jplaw-api-v2"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// Dependencies are the external services the resolver calls, which tests
+// can replace with fakes. NewResolver fills in a nil LawAPI, JobRunner, or
+// Clock; without Storage, features that store documents are unavailable.
+type Dependencies struct {
+	LawAPI    LawAPI
+	Storage   Storage
+	JobRunner JobRunner
+	Clock     Clock
+}
+
+// LawAPI is the e-Gov law list, revision, and keyword search API, as
+// implemented by the jplaw client.
+type LawAPI interface {
+	GetLaws(params *jplaw.GetLawsParams) (*jplaw.LawsResponse, error)
+	GetRevisions(lawIdOrNumOrRevisionId string, params *jplaw.GetRevisionsParams) (*jplaw.LawRevisionsResponse, error)
+	GetKeyword(params *jplaw.GetKeywordParams) (*jplaw.KeywordResponse, error)
+}
+
+// Storage opens buckets of the object store where EPUBs are kept, with the
+// object operations of objects.Bucket. An *objects.Client implements it for
+// Cloud Storage; tests can pass an in-memory store.
+type Storage interface {
+	Bucket(name string) objects.Bucket
+}
+
+// JobRunner starts an execution of the EPUB generator of a converter
+// version, APP_VERSION or a pinned older one, with the given arguments and
+// environment variables, and returns a name identifying it.
+type JobRunner interface {
+	RunGenerator(ctx context.Context, version string, args []string, env map[string]string) (string, error)
+}
+
+// Clock tells the time for job records, retries, and staleness checks.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// cloudRunJobs runs the generator as a Cloud Run Job: jobName for
+// APP_VERSION, and the job of EPUB_JOB_VERSIONS for older versions.
+type cloudRunJobs struct {
+	projectID string
+	region    string
+	jobName   string
+	versions  map[string]string
+}
+
+func (c cloudRunJobs) RunGenerator(ctx context.Context, version string, args []string, env map[string]string) (string, error) {
+	if c.projectID == "" {
+		return "", fmt.Errorf("PROJECT_ID is not set")
+	}
+	jobName := c.jobName
+	if version != APP_VERSION {
+		jobName = c.versions[version]
+		if jobName == "" {
+			return "", fmt.Errorf("no job runs converter version %s", version)
+		}
+	}
+	jobsClient, err := run.NewJobsClient(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Cloud Run Jobs client: %v", err)
+	}
+	defer jobsClient.Close()
+
+	var envVars []*runpb.EnvVar
+	for name, value := range env {
+		envVars = append(envVars, &runpb.EnvVar{Name: name, Values: &runpb.EnvVar_Value{Value: value}})
+	}
+	// Create execution request with overrides for arguments.
+	req := &runpb.RunJobRequest{
+		Name: fmt.Sprintf("projects/%s/locations/%s/jobs/%s", c.projectID, c.region, jobName),
+		Overrides: &runpb.RunJobRequest_Overrides{
+			ContainerOverrides: []*runpb.RunJobRequest_Overrides_ContainerOverride{
+				{
+					Args: args,
+					Env:  envVars,
+				},
+			},
+		},
+	}
+	op, err := jobsClient.RunJob(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute Cloud Run Job: %v", err)
+	}
+	return op.Name(), nil
+}
+
+// epubBucket returns the bucket of EPUBs and converted documents.
+func (r *Resolver) epubBucket() (objects.Bucket, error) {
+	if r.storage == nil || r.generator.bucketName == "" {
+		return nil, notConfigured("storage")
+	}
+	return r.storage.Bucket(r.generator.bucketName), nil
+}
@@ -0,0 +1,517 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+)
+
+// dispatchSlotTimeout frees the slot of a generation that was not seen to
+// finish, such as one whose execution was lost. A job queued for longer is
+// taken over by any instance, as the instance that queued it has likely
+// stopped.
+const dispatchSlotTimeout = 30 * time.Minute
+
+// slotTablePath is the object in the EPUB bucket where every instance
+// publishes the generations holding its slots, so that instances count each
+// other's executions with one read instead of listing job records.
+const slotTablePath = APP_VERSION + "/dispatch/slots.json"
+
+// maxSlotTableAttempts bounds the retries of a slot table update that raced
+// with another instance.
+const maxSlotTableAttempts = 5
+
+// dispatcher caps the generator executions of all instances and keeps the
+// jobs of the instance waiting for a slot, which start by priority and then
+// in order of arrival.
+type dispatcher struct {
+	limit int
+	wake  chan struct{}
+	// instance identifies the instance in the slot table.
+	instance string
+
+	mu sync.Mutex
+	// running maps the IDs of jobs holding a slot to when they got it.
+	running map[string]time.Time
+	// external counts the generations executing for other instances, as
+	// of the last DispatchJobs.
+	external int
+	queue    []queuedJob
+	// started and queued count the generations started and queued since
+	// the instance started.
+	started int64
+	queued  int64
+}
+
+type queuedJob struct {
+	id       string
+	priority jobs.Priority
+	queuedAt time.Time
+}
+
+// newDispatcher returns a dispatcher for limit concurrent generations, or
+// nil for no limit.
+func newDispatcher(limit int) *dispatcher {
+	if limit <= 0 {
+		return nil
+	}
+	return &dispatcher{
+		limit:    limit,
+		wake:     make(chan struct{}, 1),
+		instance: newInstanceID(),
+		running:  make(map[string]time.Time),
+	}
+}
+
+// newInstanceID returns a random ID for the instance, or one made from the
+// time if no random bytes are available.
+func newInstanceID() string {
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(random)
+}
+
+// active reports whether a job holds a slot or waits for one.
+func (d *dispatcher) active(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.running[id]; ok {
+		return true
+	}
+	for _, q := range d.queue {
+		if q.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// start gives a job a slot when one is free and no waiting job of the same
+// or a higher priority is ahead of it.
+func (d *dispatcher) start(id string, priority jobs.Priority, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.full() {
+		return false
+	}
+	for _, q := range d.queue {
+		if !priority.Outranks(q.priority) {
+			return false
+		}
+	}
+	d.running[id] = now
+	d.started++
+	return true
+}
+
+// full reports whether every slot is taken. d.mu must be held.
+func (d *dispatcher) full() bool {
+	return len(d.running)+d.external >= d.limit
+}
+
+// enqueue adds a job to the queue and wakes the dispatch loop.
+func (d *dispatcher) enqueue(id string, priority jobs.Priority, queuedAt time.Time) {
+	d.mu.Lock()
+	d.queue = append(d.queue, queuedJob{id: id, priority: priority, queuedAt: queuedAt})
+	d.queued++
+	sort.SliceStable(d.queue, func(i, k int) bool {
+		if d.queue[i].priority != d.queue[k].priority {
+			return d.queue[i].priority.Outranks(d.queue[k].priority)
+		}
+		return d.queue[i].queuedAt.Before(d.queue[k].queuedAt)
+	})
+	d.mu.Unlock()
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// next takes the first waiting job and gives it a slot, if one is free.
+func (d *dispatcher) next(now time.Time) (string, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.queue) == 0 || d.full() {
+		return "", false
+	}
+	id := d.queue[0].id
+	d.queue = d.queue[1:]
+	d.running[id] = now
+	d.started++
+	return id, true
+}
+
+// release frees the slot of a job.
+func (d *dispatcher) release(id string) {
+	d.mu.Lock()
+	delete(d.running, id)
+	d.mu.Unlock()
+}
+
+// setExternal records the generations executing for other instances.
+func (d *dispatcher) setExternal(n int) {
+	d.mu.Lock()
+	d.external = n
+	d.mu.Unlock()
+}
+
+// dispatchMetrics is the state of the dispatcher on the metrics endpoint.
+type dispatchMetrics struct {
+	Limit int `json:"limit"`
+	// Running counts the generations started by the instance, and
+	// Executing those of all instances.
+	Running   int   `json:"running"`
+	Executing int   `json:"executing"`
+	Queued    int   `json:"queued"`
+	Started   int64 `json:"startedTotal"`
+	Enqueued  int64 `json:"queuedTotal"`
+}
+
+func (d *dispatcher) metrics() dispatchMetrics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return dispatchMetrics{
+		Limit:     d.limit,
+		Running:   len(d.running),
+		Executing: len(d.running) + d.external,
+		Queued:    len(d.queue),
+		Started:   d.started,
+		Enqueued:  d.queued,
+	}
+}
+
+// slots returns the jobs holding a slot and when they got it.
+func (d *dispatcher) slots() map[string]time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	result := make(map[string]time.Time, len(d.running))
+	for id, since := range d.running {
+		result[id] = since
+	}
+	return result
+}
+
+// startGeneration starts the generator for a pending job, or queues the job
+// when EPUB_JOB_CONCURRENCY generations are executing on all instances. A
+// job queued by another instance is left to it until dispatchSlotTimeout
+// has passed.
+func (r *Resolver) startGeneration(ctx context.Context, job *jobs.Job) {
+	now := r.clock.Now()
+	if r.dispatch != nil {
+		if r.dispatch.active(job.ID) {
+			return
+		}
+		if !job.QueuedAt.IsZero() && now.Sub(job.QueuedAt) < dispatchSlotTimeout {
+			return
+		}
+	}
+	if r.dispatch == nil || r.dispatch.start(job.ID, job.EffectivePriority(), now) {
+		if !job.QueuedAt.IsZero() {
+			// Queued before the cap was lifted or by a stopped instance.
+			job.QueuedAt = time.Time{}
+			job.StartedAt = now
+			job.UpdatedAt = now
+			if err := r.jobs.Put(ctx, job); err != nil {
+				log.Printf("Failed to update job record for %s: %v", job.ID, err)
+			}
+		}
+		go r.triggerEpubGeneratorJob(job)
+		return
+	}
+
+	if job.QueuedAt.IsZero() {
+		job.QueuedAt = now
+		job.UpdatedAt = now
+		if err := r.jobs.Put(ctx, job); err != nil {
+			log.Printf("Failed to update job record for %s: %v", job.ID, err)
+		}
+	}
+	r.dispatch.enqueue(job.ID, job.EffectivePriority(), job.QueuedAt)
+	log.Printf("Queued generation of %s with %s priority", job.ID, job.EffectivePriority())
+}
+
+// DispatchJobs frees the slots of finished generations, counts those
+// executing for other instances, and starts waiting jobs in the free slots.
+// It returns the number of jobs started.
+func (r *Resolver) DispatchJobs(ctx context.Context) (int, error) {
+	if r.dispatch == nil {
+		return 0, nil
+	}
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return 0, err
+	}
+
+	now := r.clock.Now()
+	for id, since := range r.dispatch.slots() {
+		if now.Sub(since) > dispatchSlotTimeout || r.generationFinished(ctx, bucket, id) {
+			r.dispatch.release(id)
+		}
+	}
+	external, err := r.countExecuting(ctx, bucket, r.dispatch.slots(), now)
+	if err != nil {
+		return 0, err
+	}
+	r.dispatch.setExternal(external)
+
+	started := 0
+	for {
+		id, ok := r.dispatch.next(now)
+		if !ok {
+			return started, nil
+		}
+		job, err := r.jobs.Get(ctx, id)
+		if err != nil || job.Status != jobs.StatusPending {
+			if err != nil && !errors.Is(err, jobs.ErrNotFound) {
+				log.Printf("Failed to load queued job %s: %v", id, err)
+			}
+			r.dispatch.release(id)
+			continue
+		}
+		log.Printf("Starting queued generation of %s after %v", id, now.Sub(job.QueuedAt).Round(time.Second))
+		job.QueuedAt = time.Time{}
+		job.StartedAt = now
+		job.UpdatedAt = now
+		if err := r.jobs.Put(ctx, job); err != nil {
+			log.Printf("Failed to update job record for %s: %v", id, err)
+		}
+		go r.triggerEpubGeneratorJob(job)
+		started++
+	}
+}
+
+// generationFinished reports whether the generation of a job holding a slot
+// has completed or failed, according to its record, its EPUB object, or the
+// status the generator wrote.
+func (r *Resolver) generationFinished(ctx context.Context, bucket objects.Bucket, id string) bool {
+	job, err := r.jobs.Get(ctx, id)
+	if errors.Is(err, jobs.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("Failed to load running job %s: %v", id, err)
+		return false
+	}
+	switch job.Status {
+	case jobs.StatusCompleted, jobs.StatusFailed, jobs.StatusDeadLetter:
+		return true
+	case jobs.StatusPending, jobs.StatusProcessing:
+	}
+	if _, err := bucket.Attrs(ctx, jobObject(job, ".epub")); err == nil {
+		return true
+	}
+	r.syncGeneratorStatus(ctx, bucket, jobObject(job, ".status"), job)
+	return job.Status == jobs.StatusFailed
+}
+
+// slotTable is the content of the object at slotTablePath: the slots of
+// each instance, by instance ID.
+type slotTable struct {
+	Instances map[string]instanceSlots `json:"instances"`
+}
+
+// instanceSlots are the jobs holding the slots of an instance and when they
+// got them, as of UpdatedAt.
+type instanceSlots struct {
+	UpdatedAt time.Time            `json:"updatedAt"`
+	Slots     map[string]time.Time `json:"slots"`
+}
+
+// countExecuting publishes the local slots of this instance in the slot
+// table and counts the slots of other instances taken within
+// dispatchSlotTimeout: the executions of other instances. Instances that
+// have not updated the table within dispatchSlotTimeout have likely stopped
+// and are dropped. The table is written only if it is unchanged since it
+// was read, and read again otherwise.
+func (r *Resolver) countExecuting(ctx context.Context, bucket objects.Bucket, local map[string]time.Time, now time.Time) (int, error) {
+	for attempt := 0; attempt < maxSlotTableAttempts; attempt++ {
+		table, generation, err := readSlotTable(ctx, bucket)
+		if err != nil {
+			return 0, err
+		}
+		n := 0
+		for instance, slots := range table.Instances {
+			if instance == r.dispatch.instance {
+				continue
+			}
+			if now.Sub(slots.UpdatedAt) > dispatchSlotTimeout {
+				delete(table.Instances, instance)
+				continue
+			}
+			for _, since := range slots.Slots {
+				if now.Sub(since) < dispatchSlotTimeout {
+					n++
+				}
+			}
+		}
+		table.Instances[r.dispatch.instance] = instanceSlots{UpdatedAt: now, Slots: local}
+
+		conditions := objects.Conditions{DoesNotExist: true}
+		if generation != 0 {
+			conditions = objects.Conditions{GenerationMatch: generation}
+		}
+		err = writeSlotTable(ctx, bucket, conditions, table)
+		if errors.Is(err, objects.ErrPreconditionFailed) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to write slot table: %v", err)
+		}
+		return n, nil
+	}
+	return 0, errors.New("slot table is updated by too many instances at once")
+}
+
+// readSlotTable returns the slot table and the generation of its object,
+// which is zero when there is none.
+func readSlotTable(ctx context.Context, bucket objects.Bucket) (*slotTable, int64, error) {
+	table := &slotTable{Instances: make(map[string]instanceSlots)}
+	reader, err := bucket.NewReader(ctx, slotTablePath)
+	if errors.Is(err, objects.ErrNotExist) {
+		return table, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read slot table: %v", err)
+	}
+	defer reader.Close()
+
+	if err := json.NewDecoder(reader).Decode(table); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode slot table: %v", err)
+	}
+	if table.Instances == nil {
+		table.Instances = make(map[string]instanceSlots)
+	}
+	return table, reader.Attrs.Generation, nil
+}
+
+func writeSlotTable(ctx context.Context, bucket objects.Bucket, conditions objects.Conditions, table *slotTable) error {
+	w := bucket.NewWriter(ctx, slotTablePath, objects.WriteOptions{ContentType: "application/json", If: conditions})
+	if err := json.NewEncoder(w).Encode(table); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// PublishDispatcher makes the state of the dispatcher available under name
+// with expvar, for the metrics endpoint. It does nothing without
+// EPUB_JOB_CONCURRENCY.
+func (r *Resolver) PublishDispatcher(name string) {
+	if r.dispatch == nil {
+		return
+	}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return r.dispatch.metrics()
+	}))
+}
+
+// RunDispatcher calls DispatchJobs right away, every interval, and when a
+// job is queued, until ctx is canceled.
+func (r *Resolver) RunDispatcher(ctx context.Context, interval time.Duration) {
+	if r.dispatch == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		// The first pass counts the executions of other instances.
+		if _, err := r.DispatchJobs(ctx); err != nil {
+			log.Printf("Job dispatch failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.dispatch.wake:
+		}
+	}
+}
+
+type priorityKey struct{}
+
+// contextWithPriority records the priority requested for new generations.
+func contextWithPriority(ctx context.Context, priority jobs.Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// requestedPriority returns the priority of a generation started by the
+// request: the one passed to contextWithPriority, PriorityNormal without
+// one, and PriorityLow for warm-ups. HIGH counts against the client's
+// QUOTA_HIGH_PRIORITY_DAILY unless the admin token was sent.
+func (r *Resolver) requestedPriority(ctx context.Context) (jobs.Priority, error) {
+	priority, ok := ctx.Value(priorityKey{}).(jobs.Priority)
+	if !ok {
+		if handlers.ClientIPFromContext(ctx) == warmUpRequester {
+			return jobs.PriorityLow, nil
+		}
+		return jobs.PriorityNormal, nil
+	}
+	if priority != jobs.PriorityHigh || handlers.IsAdmin(ctx) {
+		return priority, nil
+	}
+
+	client := handlers.ClientFromContext(ctx)
+	if !client.Identified() {
+		return "", withCode(model1.ErrorCodeForbidden, errors.New("HIGH priority requires an API key, a tenant key, or signing in"))
+	}
+	if r.priorityLimiter == nil || !r.priorityLimiter.Enabled() {
+		return priority, nil
+	}
+	// Counted on the wall clock, like request quotas.
+	usages, err := r.priorityLimiter.Consume(ctx, "priority|"+client.Key, time.Now())
+	if err != nil {
+		// Like request quotas, the limit is not enforced without its store.
+		log.Printf("High priority quota check failed for %s: %v", client.Kind, err)
+		return priority, nil
+	}
+	if usage, ok := quota.Tightest(usages); ok && usage.Exceeded() {
+		return "", codedErrorf(model1.ErrorCodeQuotaExceeded, "daily quota of %d HIGH priority generations exceeded", usage.Limit)
+	}
+	return priority, nil
+}
+
+// convertJobPriority maps a GraphQL job priority to a job record priority.
+func convertJobPriority(priority model1.JobPriority) jobs.Priority {
+	switch priority {
+	case model1.JobPriorityHigh:
+		return jobs.PriorityHigh
+	case model1.JobPriorityLow:
+		return jobs.PriorityLow
+	case model1.JobPriorityNormal:
+		return jobs.PriorityNormal
+	default:
+		return jobs.PriorityNormal
+	}
+}
+
+// convertJobPriorityToModel maps a job record priority to GraphQL.
+func convertJobPriorityToModel(priority jobs.Priority) model1.JobPriority {
+	switch priority {
+	case jobs.PriorityHigh:
+		return model1.JobPriorityHigh
+	case jobs.PriorityLow:
+		return model1.JobPriorityLow
+	case jobs.PriorityNormal:
+		return model1.JobPriorityNormal
+	default:
+		return model1.JobPriorityNormal
+	}
+}
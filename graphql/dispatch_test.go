@@ -0,0 +1,109 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/graphql"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+const (
+	civilCode    = "129AC0000000089_20250101_000000000000000"
+	constitution = "321CONSTITUTION_19470503_000000000000000"
+	slotTable    = graphql.APP_VERSION + "/dispatch/slots.json"
+)
+
+// newDispatchServer starts a server that runs one generation at a time.
+func newDispatchServer(t *testing.T) *testsupport.Server {
+	t.Helper()
+	return testsupport.NewServer(t, func(cfg *config.Config) {
+		cfg.Dispatch.Concurrency = 1
+	})
+}
+
+// requestEpub asks for the EPUB of a revision.
+func requestEpub(t *testing.T, s *testsupport.Server, id string) {
+	t.Helper()
+	resp := s.GraphQL(t, `query ($id: String!) { epub(id: $id) { status } }`, map[string]interface{}{"id": id}, false)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("epub(%s) errors = %+v", id, resp.Errors)
+	}
+}
+
+// waitForRuns waits until the generator was asked for n executions,
+// dispatching queued jobs meanwhile.
+func waitForRuns(t *testing.T, s *testsupport.Server, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(s.Jobs.Runs()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("generator executions = %d, want %d", len(s.Jobs.Runs()), n)
+		}
+		if _, err := s.API.Resolver.DispatchJobs(context.Background()); err != nil {
+			t.Fatalf("DispatchJobs() error = %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFailedExecutionFreesSlot(t *testing.T) {
+	s := newDispatchServer(t)
+	s.Jobs.OnRun(func(ctx context.Context, run testsupport.Run) error {
+		if run.Flag("--revision-id") == civilCode {
+			return errors.New("quota exceeded")
+		}
+		return nil
+	})
+
+	requestEpub(t, s, civilCode)
+	waitForRuns(t, s, 1)
+	requestEpub(t, s, constitution)
+	waitForRuns(t, s, 2)
+	if got := s.Jobs.Runs()[1].Flag("--revision-id"); got != constitution {
+		t.Errorf("second execution generates %s, want %s", got, constitution)
+	}
+}
+
+func TestDispatchCountsSlotsOfOtherInstances(t *testing.T) {
+	s := newDispatchServer(t)
+	table := map[string]interface{}{
+		"instances": map[string]interface{}{
+			"other": map[string]interface{}{
+				"updatedAt": s.Clock.Now(),
+				"slots":     map[string]time.Time{"415AC0000000057_20250101_000000000000000": s.Clock.Now()},
+			},
+		},
+	}
+	data, err := json.Marshal(table)
+	if err != nil {
+		t.Fatalf("Failed to encode slot table: %v", err)
+	}
+	s.Storage.Put(testsupport.Bucket, slotTable, data, "application/json")
+
+	if _, err := s.API.Resolver.DispatchJobs(context.Background()); err != nil {
+		t.Fatalf("DispatchJobs() error = %v", err)
+	}
+	requestEpub(t, s, civilCode)
+	if runs := s.Jobs.Runs(); len(runs) != 0 {
+		t.Errorf("generator executions = %d while another instance holds the slot, want 0", len(runs))
+	}
+
+	data, ok := s.Storage.Get(testsupport.Bucket, slotTable)
+	if !ok {
+		t.Fatal("slot table was deleted")
+	}
+	var published struct {
+		Instances map[string]json.RawMessage `json:"instances"`
+	}
+	if err := json.Unmarshal(data, &published); err != nil {
+		t.Fatalf("Failed to decode slot table: %v", err)
+	}
+	if len(published.Instances) != 2 {
+		t.Errorf("slot table instances = %d, want the other instance and this one", len(published.Instances))
+	}
+}
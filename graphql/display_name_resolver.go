@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/locale"
+)
+
+// localizedEnums are the enums with display names, in the order
+// displayNames lists them.
+var localizedEnums = []string{"CategoryCode", "LawType", "LawNumEra", "CurrentRevisionStatus", "RepealStatus", "EpubStatus"}
+
+// requestLocale returns the locale of display names: the one passed, or
+// else the one the Accept-Language header prefers.
+func requestLocale(ctx context.Context, arg *model1.Locale) locale.Locale {
+	if arg != nil && arg.IsValid() {
+		return locale.Locale(strings.ToLower(string(*arg)))
+	}
+	return locale.FromContext(ctx)
+}
+
+// displayName returns the display name of an enum value.
+func displayName[T ~string](ctx context.Context, arg *model1.Locale, enum string, value T) string {
+	return locale.DisplayName(requestLocale(ctx, arg), enum, string(value))
+}
+
+// optionalDisplayName returns the display name of an optional enum value,
+// or nil without one.
+func optionalDisplayName[T ~string](ctx context.Context, arg *model1.Locale, enum string, value *T) *string {
+	if value == nil {
+		return nil
+	}
+	name := displayName(ctx, arg, enum, *value)
+	return &name
+}
+
+// categoryDisplayName returns the display name of an e-Gov category name,
+// or the name itself for a category this server does not know.
+func categoryDisplayName(ctx context.Context, arg *model1.Locale, name string) string {
+	i := slices.Index(handlers.CategoryNames(), name)
+	if i < 0 {
+		return name
+	}
+	for code, cd := range categoryCodeMap {
+		if cd == lawapi.CategoryCd(fmt.Sprintf("%03d", i+1)) {
+			return displayName(ctx, arg, "CategoryCode", code)
+		}
+	}
+	return name
+}
+
+// displayNames lists the display names of the values of an enum, or of
+// every localized enum when enum is nil.
+func displayNames(ctx context.Context, enum *string, arg *model1.Locale) ([]model1.DisplayName, error) {
+	enums := localizedEnums
+	if enum != nil {
+		if !slices.Contains(localizedEnums, *enum) {
+			return nil, codedErrorf(model1.ErrorCodeBadUserInput, "unknown enum %q (expected one of %s)", *enum, strings.Join(localizedEnums, ", "))
+		}
+		enums = []string{*enum}
+	}
+	l := requestLocale(ctx, arg)
+	result := []model1.DisplayName{}
+	for _, name := range enums {
+		for _, message := range locale.Messages(name) {
+			result = append(result, model1.DisplayName{Enum: name, Value: message.Value, DisplayName: message.Text(l)})
+		}
+	}
+	return result, nil
+}
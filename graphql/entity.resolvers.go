@@ -0,0 +1,26 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+
+	"go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+// FindEpubByID is the resolver for the findEpubByID field.
+func (r *entityResolver) FindEpubByID(ctx context.Context, id string) (*model.Epub, error) {
+	return r.Resolver.findEpub(ctx, id)
+}
+
+// FindLawByID is the resolver for the findLawByID field.
+func (r *entityResolver) FindLawByID(ctx context.Context, id string) (*model.Law, error) {
+	return r.Resolver.findLaw(id)
+}
+
+// Entity returns EntityResolver implementation.
+func (r *Resolver) Entity() EntityResolver { return &entityResolver{r} }
+
+type entityResolver struct{ *Resolver }
@@ -0,0 +1,318 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+const (
+	// maxBundleLaws caps the laws of one statute book.
+	maxBundleLaws = 100
+	// bundleTimeout bounds the assembly of a statute book.
+	bundleTimeout = 30 * time.Minute
+)
+
+// requestEpubBundle validates a statute book, records it in the audit log,
+// and starts assembling it in the background.
+func (r *Resolver) requestEpubBundle(ctx context.Context, rootLawID string, includeSubordinate bool) (*model1.EpubBundle, error) {
+	start := r.clock.Now()
+	bundle, err := r.startEpubBundle(ctx, rootLawID, includeSubordinate)
+
+	entry := audit.Entry{
+		Operation:  "epubBundle",
+		RevisionID: rootLawID,
+	}
+	if bundle != nil {
+		entry.Filename = bundle.ID + ".epub"
+		entry.Result = string(bundle.Status)
+	}
+	r.recordAudit(ctx, entry, start, err)
+
+	return bundle, err
+}
+
+func (r *Resolver) startEpubBundle(ctx context.Context, rootLawID string, includeSubordinate bool) (*model1.EpubBundle, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("statute book")
+	}
+	rootLawID = strings.TrimSpace(rootLawID)
+	parsed, err := parseLawID(rootLawID)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Kind == lawid.RevisionID {
+		return nil, codedErrorf(model1.ErrorCodeInvalidLawID, "epubBundle takes a law ID or law number, got revision ID %s", rootLawID)
+	}
+
+	lawIDs := []string{parsed.Value}
+	if includeSubordinate {
+		related, err := r.relatedLaws(ctx, rootLawID)
+		if err != nil {
+			return nil, err
+		}
+		lawIDs = append(lawIDs, bundleOrder(related)...)
+	}
+	if len(lawIDs) > maxBundleLaws {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "%s has %d subordinate laws, but a statute book holds at most %d laws", rootLawID, len(lawIDs)-1, maxBundleLaws)
+	}
+
+	bundle, err := newBook(lawIDs, r.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := r.startBook(ctx, bundle, false); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// newBook returns the pending status of a book of the laws with lawIDs,
+// the first of which is its root law, created at now.
+func newBook(lawIDs []string, now time.Time) (*model1.EpubBundle, error) {
+	id, err := newExportID()
+	if err != nil {
+		return nil, err
+	}
+	created := now.UTC().Format(time.RFC3339)
+	return &model1.EpubBundle{
+		ID:        id,
+		RootLawID: lawIDs[0],
+		Status:    model1.EpubStatusPending,
+		LawIds:    lawIDs,
+		Total:     len(lawIDs),
+		Failures:  []model1.BulkExportFailure{},
+		CreatedAt: created,
+		UpdatedAt: created,
+	}, nil
+}
+
+// startBook stores the status of a new book and starts assembling it in
+// the background.
+func (r *Resolver) startBook(ctx context.Context, bundle *model1.EpubBundle, compiled bool) error {
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return err
+	}
+	prefix := storagePrefix(ctx)
+	if err := writeStatus(ctx, bucket, bundleStatusPath(prefix, bundle.ID), "statute book", bundle); err != nil {
+		return err
+	}
+
+	go r.runEpubBundle(prefix, *bundle, compiled)
+
+	return nil
+}
+
+// bundleOrder returns the law IDs of the subordinate laws among related in
+// the order of a statute book: cabinet orders before ministerial
+// ordinances and rules, each oldest first.
+func bundleOrder(related []model1.RelatedLaw) []string {
+	var subordinates []model1.RelatedLaw
+	for _, law := range related {
+		if law.Relation == model1.LawRelationSubordinate {
+			subordinates = append(subordinates, law)
+		}
+	}
+	rank := func(law model1.RelatedLaw) int {
+		if law.LawType == nil {
+			return len(lawRanks)
+		}
+		return lawRanks[convertLawType([]model1.LawType{*law.LawType})[0]]
+	}
+	// Law IDs begin with the era and year of promulgation.
+	sort.SliceStable(subordinates, func(i, k int) bool {
+		a, b := subordinates[i], subordinates[k]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		return a.LawID < b.LawID
+	})
+
+	ids := make([]string, len(subordinates))
+	for i, law := range subordinates {
+		ids[i] = law.LawID
+	}
+	return ids
+}
+
+// getEpubBundle reads the status of a statute book, with a signed URL once
+// it has completed. It returns nil for an unknown ID.
+func (r *Resolver) getEpubBundle(ctx context.Context, id string) (*model1.EpubBundle, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("statute book")
+	}
+	if !validExportID(id) {
+		return nil, nil
+	}
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+	prefix := storagePrefix(ctx)
+
+	var bundle model1.EpubBundle
+	if found, err := readStatus(ctx, bucket, bundleStatusPath(prefix, id), "statute book", &bundle); err != nil || !found {
+		return nil, err
+	}
+
+	if bundle.Status == model1.EpubStatusCompleted {
+		signedURL, err := generateSignedURL(bucket, bundlePath(prefix, id), 1*time.Hour, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signed URL: %v", err)
+		}
+		bundle.SignedURL = &signedURL
+		bundle.DownloadURL = downloadURL(id, APP_VERSION)
+	}
+	return &bundle, nil
+}
+
+// runEpubBundle fetches every law of a book, converts them in-process into
+// one EPUB, and uploads it to the bucket below prefix, updating the status
+// object as it goes. A statute book is titled after its root law and fails
+// without it; a compiled book keeps its title and fails only when none of
+// its laws can be fetched.
+func (r *Resolver) runEpubBundle(prefix string, bundle model1.EpubBundle, compiled bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), bundleTimeout)
+	defer cancel()
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		log.Printf("Statute book %s: %v", bundle.ID, err)
+		return
+	}
+
+	update := func() {
+		bundle.UpdatedAt = r.clock.Now().UTC().Format(time.RFC3339)
+		if err := writeStatus(ctx, bucket, bundleStatusPath(prefix, bundle.ID), "statute book", &bundle); err != nil {
+			log.Printf("Statute book %s: %v", bundle.ID, err)
+		}
+	}
+	fail := func(err error) {
+		message := err.Error()
+		bundle.Status = model1.EpubStatusFailed
+		bundle.Error = &message
+		update()
+	}
+
+	bundle.Status = model1.EpubStatusProcessing
+	update()
+
+	var laws []*lawdata.Law
+	var estimate int64
+	lastUpdate := r.clock.Now()
+	for i, id := range bundle.LawIds {
+		law, size, err := r.fetchBundledLaw(ctx, id)
+		switch {
+		case ctx.Err() != nil:
+			fail(fmt.Errorf("statute book timed out after %d of %d laws", bundle.Completed, bundle.Total))
+			return
+		case err != nil && i == 0 && !compiled:
+			fail(err)
+			return
+		case err != nil:
+			bundle.Failures = append(bundle.Failures, model1.BulkExportFailure{ID: id, Error: err.Error()})
+		default:
+			laws = append(laws, law)
+			estimate += sandbox.Estimate(size)
+		}
+		if bundle.Title == nil {
+			title := law.LawTitle
+			if len(bundle.LawIds) > 1 {
+				title += "関係法令集"
+			}
+			bundle.Title = &title
+		}
+		bundle.Completed++
+		if now := r.clock.Now(); now.Sub(lastUpdate) >= bulkExportProgressInterval {
+			update()
+			lastUpdate = now
+		}
+	}
+	if len(laws) == 0 {
+		fail(errors.New("no laws could be fetched"))
+		return
+	}
+
+	var buf bytes.Buffer
+	err = r.pool.Run(ctx, estimate, func() error {
+		if err := lawdata.WriteBundleEPUB(&buf, laws, *bundle.Title, "urn:jplaw2epub:bundle:"+bundle.ID, lawdata.Options{}); err != nil {
+			return err
+		}
+		_, err := lawdata.CheckEPUB(buf.Bytes())
+		return err
+	})
+	if err != nil {
+		fail(fmt.Errorf("failed to convert statute book: %v", err))
+		return
+	}
+
+	fields := naming.FromLaw(bundle.ID, laws[0])
+	fields.LawTitle = *bundle.Title
+	sum := checksum(buf.Bytes())
+	metadata := fields.Metadata()
+	metadata[checksumKey] = sum
+	uploadCtx, abort := context.WithCancel(ctx)
+	defer abort()
+	writer := bucket.NewWriter(uploadCtx, bundlePath(prefix, bundle.ID), objects.WriteOptions{
+		ContentType:        "application/epub+zip",
+		ContentDisposition: r.filenames.ContentDisposition(fields),
+		Metadata:           metadata,
+	})
+	if _, err := writer.Write(buf.Bytes()); err != nil {
+		abort()
+		_ = writer.Close()
+		fail(fmt.Errorf("failed to upload statute book: %v", err))
+		return
+	}
+	if err := writer.Close(); err != nil {
+		fail(fmt.Errorf("failed to upload statute book: %v", err))
+		return
+	}
+
+	size := buf.Len()
+	bundle.Size = &size
+	bundle.Sha256 = &sum
+	bundle.Status = model1.EpubStatusCompleted
+	update()
+}
+
+// fetchBundledLaw fetches and parses a law of a book, the current revision
+// for a law ID or law number, returning the size of its XML.
+func (r *Resolver) fetchBundledLaw(ctx context.Context, id string) (*lawdata.Law, int, error) {
+	id, data, err := r.fetchLawBody(ctx, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	revisionID := data.RevisionID
+	if revisionID == "" {
+		revisionID = id
+	}
+	law, err := r.parseLawBody(revisionID, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	return law, len(data.XML), nil
+}
+
+func bundleStatusPath(prefix, id string) string {
+	return fmt.Sprintf("%s/bundles/%s.json", prefix, id)
+}
+
+func bundlePath(prefix, id string) string {
+	return fmt.Sprintf("%s/bundles/%s.epub", prefix, id)
+}
@@ -0,0 +1,121 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+)
+
+const maxEpubJobs = 500
+
+// listEpubJobs returns job summaries from the metadata store, most recently
+// updated first, for operators. Jobs of every tenant are listed, so only
+// the admin may read them.
+func (r *Resolver) listEpubJobs(ctx context.Context, status *model1.EpubStatus, first *int) ([]model1.EpubJob, error) {
+	if !handlers.IsAdmin(ctx) {
+		return nil, errAdminRequired
+	}
+	var jobStatus jobs.Status
+	if status != nil {
+		jobStatus = jobs.Status(*status)
+	}
+	return r.listJobs(ctx, jobStatus, first)
+}
+
+// listFailedJobs returns the dead-lettered jobs for operators.
+func (r *Resolver) listFailedJobs(ctx context.Context, first *int) ([]model1.EpubJob, error) {
+	if !handlers.IsAdmin(ctx) {
+		return nil, errAdminRequired
+	}
+	return r.listJobs(ctx, jobs.StatusDeadLetter, first)
+}
+
+// retryJob starts a failed or dead-lettered job again with a fresh set of
+// attempts.
+func (r *Resolver) retryJob(ctx context.Context, id string) (*model1.EpubJob, error) {
+	if !handlers.IsAdmin(ctx) {
+		return nil, errAdminRequired
+	}
+	job, err := r.jobs.Get(ctx, id)
+	if errors.Is(err, jobs.ErrNotFound) {
+		return nil, codedErrorf(model1.ErrorCodeNotFound, "job %q not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job record: %v", err)
+	}
+	if job.Status != jobs.StatusFailed && job.Status != jobs.StatusDeadLetter {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "job %s is %s; only failed jobs can be retried", id, job.Status)
+	}
+
+	log.Printf("Retrying job %s on request after %d attempts", job.ID, job.Attempts)
+	now := r.clock.Now()
+	job.Retry(now)
+	if err := r.jobs.Put(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to update job record: %v", err)
+	}
+	r.startGeneration(ctx, job)
+
+	result := convertJobToModel(job, now)
+	return &result, nil
+}
+
+// listJobs returns up to first jobs with the status, or of every status
+// when it is empty.
+func (r *Resolver) listJobs(ctx context.Context, status jobs.Status, first *int) ([]model1.EpubJob, error) {
+	limit := 50
+	if first != nil {
+		limit = *first
+	}
+	if limit <= 0 {
+		return []model1.EpubJob{}, nil
+	}
+	if limit > maxEpubJobs {
+		limit = maxEpubJobs
+	}
+
+	records, err := r.jobs.List(ctx, jobs.ListOptions{Status: status, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.clock.Now()
+	result := make([]model1.EpubJob, 0, len(records))
+	for _, job := range records {
+		result = append(result, convertJobToModel(job, now))
+	}
+	return result, nil
+}
+
+func convertJobToModel(job *jobs.Job, now time.Time) model1.EpubJob {
+	result := model1.EpubJob{
+		ID:             job.ID,
+		RevisionID:     job.RevisionID,
+		Articles:       job.Articles,
+		Status:         convertJobStatusToModel(job.Status),
+		CreatedAt:      formatOptionalTime(job.CreatedAt),
+		UpdatedAt:      job.UpdatedAt.Format(time.RFC3339),
+		NextRetryAt:    formatOptionalTime(job.NextRetryAt),
+		DeadLetteredAt: formatOptionalTime(job.DeadLetteredAt),
+		Priority:       convertJobPriorityToModel(job.EffectivePriority()),
+		QueuedAt:       formatOptionalTime(job.QueuedAt),
+	}
+	if job.Attempts > 0 {
+		attempts := job.Attempts
+		result.Attempts = &attempts
+	}
+	if !job.CreatedAt.IsZero() {
+		duration := job.Duration(now).Seconds()
+		result.DurationSeconds = &duration
+	}
+	if job.Error != "" {
+		errorMsg := job.Error
+		result.Error = &errorMsg
+	}
+	return result
+}
@@ -3,231 +3,740 @@ package graphql
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
-	run "cloud.google.com/go/run/apiv2"
-	"cloud.google.com/go/run/apiv2/runpb"
-	"cloud.google.com/go/storage"
-
+	"go.ngs.io/jplaw2epub-web-api/audit"
 	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
 )
 
 const APP_VERSION = "v1.0.0"
 
-func (r *Resolver) getEpub(ctx context.Context, id string) (*model1.Epub, error) {
-	bucketName := os.Getenv("EPUB_BUCKET_NAME")
-	if bucketName == "" {
-		bucketName = "epub-storage"
+// generatorSpecTimeout bounds the law lookup for a job spec, after which
+// the generator is started without the law's title and number.
+const generatorSpecTimeout = 10 * time.Second
+
+// storagePrefix returns the object prefix of the caller's documents, which
+// is the tenant's directory below APP_VERSION for tenant requests.
+func storagePrefix(ctx context.Context) string {
+	return tenant.Prefix(APP_VERSION, tenant.IDFromContext(ctx))
+}
+
+// scopedJobID returns the job ID of a document of the caller's tenant.
+// Job IDs are relative to APP_VERSION, so the EPUB of a job is always
+// stored at {APP_VERSION}/{job ID}.epub.
+func scopedJobID(ctx context.Context, id string) string {
+	return tenant.ScopedID(tenant.IDFromContext(ctx), id)
+}
+
+// GetEpub returns the generation state of an EPUB for use outside GraphQL,
+// such as the /epubs/ handler.
+func (r *Resolver) GetEpub(ctx context.Context, id string, articles []string) (*model1.Epub, error) {
+	return r.getEpub(ctx, id, articles)
+}
+
+// getEpub resolves an EPUB request and records it in the audit log.
+func (r *Resolver) getEpub(ctx context.Context, revisionID string, articles []string) (*model1.Epub, error) {
+	start := r.clock.Now()
+	epub, err := r.resolveEpub(ctx, revisionID, articles)
+
+	entry := audit.Entry{
+		Operation:  "epub",
+		RevisionID: revisionID,
+		Articles:   articles,
 	}
+	if epub != nil {
+		entry.Result = string(epub.Status)
+	}
+	r.recordAudit(ctx, entry, start, err)
 
-	epubPath := fmt.Sprintf("%s/%s.epub", APP_VERSION, id)
-	statusPath := fmt.Sprintf("%s/%s.status", APP_VERSION, id)
+	return epub, err
+}
 
-	client, err := storage.NewClient(ctx)
+func (r *Resolver) resolveEpub(ctx context.Context, revisionID string, articles []string) (*model1.Epub, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("EPUB generation")
+	}
+
+	parsed, err := parseLawID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	revisionID = parsed.Value
+	articles, err = normalizeArticles(articles)
+	if err != nil {
+		return nil, err
+	}
+	id := excerptID(revisionID, articles)
+	version, err := requestedVersion(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create storage client: %v", err)
+		return nil, err
 	}
-	defer client.Close()
+	jobID := pinnedJobID(scopedJobID(ctx, id), version)
+	etag := epubETag(revisionID, articles, version, 0)
 
-	bucket := client.Bucket(bucketName)
+	// EPUBs of older converter versions are in their version's directory.
+	epubPath := fmt.Sprintf("%s/%s.epub", version, scopedJobID(ctx, id))
+	statusPath := fmt.Sprintf("%s/%s.status", version, scopedJobID(ctx, id))
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
 
 	// Check if EPUB file exists.
-	epubObj := bucket.Object(epubPath)
-	attrs, err := epubObj.Attrs(ctx)
+	attrs, err := bucket.Attrs(ctx, epubPath)
 
 	if err == nil {
+		job, err := r.jobs.Get(ctx, jobID)
+		if err != nil {
+			job = nil
+		}
+		if job != nil && !job.StaleAt.IsZero() {
+			// The law was amended after generation - replace the EPUB.
+			if err := r.regenerateEpub(ctx, bucket, job); err != nil {
+				return nil, err
+			}
+			return jobEpub(job, articles, etag), nil
+		}
+
+		attrs, err = checkGeneratedEpub(ctx, bucket, attrs, naming.FromRevision(id, revisionID, ""))
+		if err != nil {
+			err = r.rejectEpub(ctx, bucket, attrs, job, err)
+			if job == nil {
+				return nil, err
+			}
+			r.handleFailedJob(ctx, job)
+			return jobEpub(job, articles, etag), nil
+		}
+
 		// EPUB exists - generate signed URL.
-		signedURL, signErr := generateSignedURL(bucket, epubPath, 1*time.Hour)
-		if signErr != nil {
-			return nil, fmt.Errorf("failed to generate signed URL: %v", signErr)
+		if job != nil {
+			job.RecordAccess(r.clock.Now())
+			r.recordCompletion(ctx, job, attrs)
+			r.notifyJob(ctx, bucket, job, attrs)
 		}
+		return r.completedEpub(bucket, attrs, id, articles, epubETag(revisionID, articles, version, attrs.Generation))
+	}
 
-		// Convert size from int64 to *int for GraphQL.
-		size := int(attrs.Size)
+	job, err := r.jobs.Get(ctx, jobID)
+	if errors.Is(err, jobs.ErrNotFound) {
+		if version != APP_VERSION && !r.generator.versions[version] {
+			return nil, codedErrorf(model1.ErrorCodeNotFound, "no EPUB of %s by converter version %s is stored, and that version cannot generate one", id, version)
+		}
+		priority, err := r.requestedPriority(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// First request - record the job and trigger Cloud Run Job.
+		now := r.clock.Now()
+		job = &jobs.Job{
+			ID:         jobID,
+			RevisionID: revisionID,
+			Articles:   articles,
+			Status:     jobs.StatusPending,
+			Attempts:   1,
+			Requester:  handlers.ClientIPFromContext(ctx),
+			OutputPath: epubPath,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			StartedAt:  now,
+			Priority:   priority,
+			// Recorded for the estimates of later generations.
+			ArticleCount: r.countArticles(ctx, revisionID, articles),
+		}
+		if version != APP_VERSION {
+			job.Version = version
+		}
+		r.canary.assign(job)
+		if err := r.jobs.Put(ctx, job); err != nil {
+			return nil, fmt.Errorf("failed to create job record: %v", err)
+		}
+
+		// Trigger Cloud Run Job asynchronously, or queue it.
+		r.startGeneration(ctx, job)
 
 		return &model1.Epub{
-			ID:        id,
-			SignedURL: &signedURL,
-			Size:      &size,
-			Status:    model1.EpubStatusCompleted,
+			ID:               id,
+			Articles:         articles,
+			Etag:             etag,
+			Status:           model1.EpubStatusPending,
+			Attempts:         &job.Attempts,
+			ConverterVersion: version,
 		}, nil
 	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job record: %v", err)
+	}
 
-	// Check status file.
-	statusObj := bucket.Object(statusPath)
-	statusReader, err := statusObj.NewReader(ctx)
+	// Processing or failed.
+	r.syncGeneratorStatus(ctx, bucket, statusPath, job)
+	switch job.Status {
+	case jobs.StatusPending:
+		r.handlePendingJob(ctx, job)
+	case jobs.StatusFailed:
+		r.handleFailedJob(ctx, job)
+	case jobs.StatusProcessing, jobs.StatusCompleted, jobs.StatusDeadLetter:
+	}
 
-	if err == nil {
-		// Processing or failed.
-		defer statusReader.Close()
-		return handleExistingStatus(ctx, statusObj, statusReader, id)
+	return jobEpub(job, articles, etag), nil
+}
+
+// GetEpubStatus reports the generation state of an EPUB without starting or
+// retrying generation. It returns jobs.ErrNotFound when the EPUB has never
+// been requested.
+func (r *Resolver) GetEpubStatus(ctx context.Context, revisionID string, articles []string) (*model1.Epub, error) {
+	return r.epubStatus(ctx, revisionID, articles, false)
+}
+
+// PollEpub reports the generation state of an EPUB that GetEpub started,
+// and retries stale and failed generations like GetEpub, for clients that
+// wait for it to finish. Polls are not audited, and only the one that
+// finds the EPUB completed counts a download.
+func (r *Resolver) PollEpub(ctx context.Context, revisionID string, articles []string) (*model1.Epub, error) {
+	return r.epubStatus(ctx, revisionID, articles, true)
+}
+
+// epubStatus reports the generation state of an EPUB, advancing pending
+// and failed jobs and recording the completion of the job when advance is
+// set.
+func (r *Resolver) epubStatus(ctx context.Context, revisionID string, articles []string, advance bool) (*model1.Epub, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("EPUB generation")
 	}
 
-	// First request - create status file and trigger Cloud Run Job.
-	statusData := map[string]string{
-		"status":    "PENDING",
-		"createdAt": time.Now().Format(time.RFC3339),
+	parsed, err := parseLawID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	revisionID = parsed.Value
+	articles, err = normalizeArticles(articles)
+	if err != nil {
+		return nil, err
+	}
+	id := excerptID(revisionID, articles)
+	version, err := requestedVersion(ctx)
+	if err != nil {
+		return nil, err
 	}
+	jobID := pinnedJobID(scopedJobID(ctx, id), version)
+	etag := epubETag(revisionID, articles, version, 0)
 
-	// Create status file.
-	w := statusObj.NewWriter(ctx)
-	if err := json.NewEncoder(w).Encode(statusData); err != nil {
-		return nil, fmt.Errorf("failed to create status file: %v", err)
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
 	}
-	if err := w.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close status writer: %v", err)
+	if attrs, err := bucket.Attrs(ctx, fmt.Sprintf("%s/%s.epub", version, scopedJobID(ctx, id))); err == nil {
+		attrs, err = checkGeneratedEpub(ctx, bucket, attrs, naming.FromRevision(id, revisionID, ""))
+		if err == nil && !advance {
+			r.recordAccess(ctx, jobID)
+			return r.completedEpub(bucket, attrs, id, articles, epubETag(revisionID, articles, version, attrs.Generation))
+		}
+		if err == nil {
+			if job, err := r.jobs.Get(ctx, jobID); err == nil {
+				job.RecordAccess(r.clock.Now())
+				r.recordCompletion(ctx, job, attrs)
+				r.notifyJob(ctx, bucket, job, attrs)
+			}
+			return r.completedEpub(bucket, attrs, id, articles, epubETag(revisionID, articles, version, attrs.Generation))
+		}
+		job, jobErr := r.jobs.Get(ctx, jobID)
+		if jobErr != nil {
+			return nil, r.rejectEpub(ctx, bucket, attrs, nil, err)
+		}
+		_ = r.rejectEpub(ctx, bucket, attrs, job, err)
+		return jobEpub(job, articles, etag), nil
 	}
 
-	// Trigger Cloud Run Job asynchronously.
-	go triggerEpubGeneratorJob(id)
+	job, err := r.jobs.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	r.syncGeneratorStatus(ctx, bucket, jobObject(job, ".status"), job)
+	if advance {
+		switch job.Status {
+		case jobs.StatusPending:
+			r.handlePendingJob(ctx, job)
+		case jobs.StatusFailed:
+			r.handleFailedJob(ctx, job)
+		case jobs.StatusProcessing, jobs.StatusCompleted, jobs.StatusDeadLetter:
+		}
+	}
 
-	return &model1.Epub{
-		ID:     id,
-		Status: model1.EpubStatusPending,
-	}, nil
+	return jobEpub(job, articles, etag), nil
 }
 
-func handleExistingStatus(ctx context.Context, statusObj *storage.ObjectHandle, statusReader io.Reader, id string) (*model1.Epub, error) {
-	var status map[string]interface{}
-	if err := json.NewDecoder(statusReader).Decode(&status); err != nil {
-		return nil, fmt.Errorf("failed to decode status: %v", err)
+// completedEpub describes a generated EPUB with a signed download URL.
+func (r *Resolver) completedEpub(bucket objects.Bucket, attrs *objects.Attrs, id string, articles []string, etag *string) (*model1.Epub, error) {
+	signedURL, err := generateSignedURL(bucket, attrs.Name, 1*time.Hour, r.epubDisposition(id, attrs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signed URL: %v", err)
 	}
 
-	epubStatus := model1.EpubStatusPending
-	statusStr, ok := status["status"].(string)
-	if !ok {
-		statusStr = "PENDING"
+	// Convert size from int64 to *int for GraphQL.
+	size := int(attrs.Size)
+	// Objects are stored below the directory of their converter version,
+	// and those of the canary generator are tagged with its version.
+	version, _, _ := strings.Cut(attrs.Name, "/")
+	converterVersion := version
+	if canary := attrs.Metadata[canaryKey]; canary != "" {
+		converterVersion = canary
 	}
 
-	switch statusStr {
-	case "PROCESSING":
-		epubStatus = model1.EpubStatusProcessing
-	case "FAILED":
-		epubStatus = model1.EpubStatusFailed
-	case "PENDING":
-		epubStatus = model1.EpubStatusPending
-		handlePendingStatus(ctx, status, statusObj, id)
+	return &model1.Epub{
+		ID:               id,
+		Articles:         articles,
+		Etag:             etag,
+		SignedURL:        &signedURL,
+		DownloadURL:      downloadURL(id, version),
+		Size:             &size,
+		Sha256:           optionalString(attrs.Metadata[checksumKey]),
+		Status:           model1.EpubStatusCompleted,
+		ConverterVersion: converterVersion,
+	}, nil
+}
+
+// downloadURL is the path of a stored document on the /download/{id}
+// proxy, which serves older converter versions by query parameter.
+func downloadURL(id, version string) *string {
+	path := "/download/" + url.PathEscape(id)
+	if version != APP_VERSION {
+		path += "?converterVersion=" + url.QueryEscape(version)
 	}
+	return &path
+}
+
+// jobEpub describes an EPUB that is still being generated or has failed.
+// Its ID leaves out the tenant directory and pinned version of the job ID.
+func jobEpub(job *jobs.Job, articles []string, etag *string) *model1.Epub {
+	_, id := tenant.SplitID(outputID(job))
 
 	var errorMsg *string
-	if e, ok := status["error"].(string); ok && e != "" {
-		errorMsg = &e
+	if job.Error != "" {
+		errorMsg = &job.Error
 	}
 
+	// Only validation failures are classified; the generator reports
+	// other failures as plain messages.
+	var errorCode *model1.ErrorCode
+	if len(job.ValidationErrors) > 0 {
+		code := model1.ErrorCodeConversionFailed
+		errorCode = &code
+	}
+
+	attempts := job.Attempts
+
 	return &model1.Epub{
-		ID:     id,
-		Status: epubStatus,
-		Error:  errorMsg,
-	}, nil
+		ID:               id,
+		Articles:         articles,
+		Etag:             etag,
+		Status:           convertJobStatusToModel(job.Status),
+		Stage:            convertJobStage(job),
+		Error:            errorMsg,
+		ErrorCode:        errorCode,
+		ValidationErrors: job.ValidationErrors,
+		Attempts:         &attempts,
+		NextRetryAt:      formatOptionalTime(job.NextRetryAt),
+		ConverterVersion: generatorVersion(job),
+	}
 }
 
-func handlePendingStatus(ctx context.Context, status map[string]interface{}, statusObj *storage.ObjectHandle, id string) {
-	// Check if status file is stale (older than 5 minutes).
-	createdAt, ok := status["createdAt"].(string)
-	if !ok {
-		// No createdAt field - trigger job for backward compatibility.
-		log.Printf("PENDING status without createdAt for %s, triggering job", id)
-		go triggerEpubGeneratorJob(id)
-		return
+// convertJobStage returns the stage the generator reported for a
+// processing job, or nil in other states and for stages this server does
+// not know.
+func convertJobStage(job *jobs.Job) *model1.EpubStage {
+	stage := model1.EpubStage(strings.ToUpper(string(job.Stage)))
+	if job.Status != jobs.StatusProcessing || !stage.IsValid() {
+		return nil
+	}
+	return &stage
+}
+
+// epubETag identifies an EPUB by revision, converter version, excerpt
+// selection, and the generation of its stored object, which is zero before
+// generation finishes. Requests by law ID or law number keep their ID
+// when the law is amended, so only the generation of the regenerated EPUB
+// tells the revisions apart.
+func epubETag(revisionID string, articles []string, version string, generation int64) *string {
+	etag := handlers.ComputeETag(revisionID, version, "application/epub+zip", strings.Join(articles, ","), strconv.FormatInt(generation, 10))
+	return &etag
+}
+
+// recordCompletion marks the job record completed the first time the EPUB
+// object is observed and counts later requests as cache hits.
+func (r *Resolver) recordCompletion(ctx context.Context, job *jobs.Job, attrs *objects.Attrs) {
+	if job.Status == jobs.StatusCompleted {
+		job.CacheHits++
+	} else {
+		r.tagCanaryEpub(ctx, job, attrs)
+		r.canary.observe(job, attrs)
+		job.Status = jobs.StatusCompleted
+		job.Stage = ""
+		job.OutputPath = attrs.Name
+		job.CompletedAt = attrs.Created
+		job.UpdatedAt = r.clock.Now()
+		job.Error = ""
+		job.ValidationErrors = nil
+	}
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to record completion for %s: %v", job.ID, err)
 	}
+}
+
+// RecordDownload counts a download of the EPUB with the given ID in the
+// caller's tenant, generated by the given converter version, such as one
+// served by the /download/ handler.
+func (r *Resolver) RecordDownload(ctx context.Context, id, version string) {
+	r.recordAccess(ctx, pinnedJobID(scopedJobID(ctx, id), version))
+}
 
-	created, err := time.Parse(time.RFC3339, createdAt)
+// recordAccess counts a download of a finished EPUB on its job record,
+// which cleanup and warm-up read. EPUBs without a record are not tracked.
+func (r *Resolver) recordAccess(ctx context.Context, jobID string) {
+	job, err := r.jobs.Get(ctx, jobID)
 	if err != nil {
+		if !errors.Is(err, jobs.ErrNotFound) {
+			log.Printf("Failed to load job record for %s: %v", jobID, err)
+		}
 		return
 	}
-
-	if time.Since(created) > 5*time.Minute {
-		// Stale PENDING status - trigger a new job.
-		log.Printf("Stale PENDING status for %s (created %v ago), triggering new job", id, time.Since(created))
-		go triggerEpubGeneratorJob(id)
-		updateStatusTimestamp(ctx, statusObj)
+	job.RecordAccess(r.clock.Now())
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to record access for %s: %v", jobID, err)
 	}
 }
 
-func updateStatusTimestamp(ctx context.Context, statusObj *storage.ObjectHandle) {
-	// Update status file with new timestamp.
-	statusData := map[string]string{
-		"status":    "PENDING",
-		"createdAt": time.Now().Format(time.RFC3339),
+// rejectEpub deletes a generated EPUB that failed validation with err, and
+// fails its job, if any, with the validator's problems so that the retry
+// policy applies. It returns err classified as CONVERSION_FAILED.
+func (r *Resolver) rejectEpub(ctx context.Context, bucket objects.Bucket, attrs *objects.Attrs, job *jobs.Job, err error) error {
+	log.Printf("Rejecting invalid EPUB %s: %v", attrs.Name, err)
+	if err := bucket.Delete(ctx, attrs.Name, objects.Conditions{GenerationMatch: attrs.Generation}); err != nil && !errors.Is(err, objects.ErrNotExist) {
+		log.Printf("Failed to delete invalid EPUB %s: %v", attrs.Name, err)
 	}
-	w := statusObj.NewWriter(ctx)
-	if err := json.NewEncoder(w).Encode(statusData); err != nil {
-		log.Printf("Failed to update status file: %v", err)
-	} else {
-		_ = w.Close()
+	if job != nil {
+		var invalid *lawdata.EPUBError
+		if errors.As(err, &invalid) {
+			job.ValidationErrors = invalid.Problems
+		}
+		r.canary.observe(job, nil)
+		job.Status = jobs.StatusFailed
+		job.Error = err.Error()
+		job.UpdatedAt = r.clock.Now()
+		job.NextRetryAt = time.Time{}
+		if err := r.jobs.Put(ctx, job); err != nil {
+			log.Printf("Failed to update job record for %s: %v", job.ID, err)
+		}
 	}
+	return withCode(model1.ErrorCodeConversionFailed, err)
 }
 
-func generateSignedURL(bucket *storage.BucketHandle, objectName string, expiration time.Duration) (string, error) {
-	opts := &storage.SignedURLOptions{
-		Scheme:  storage.SigningSchemeV4,
-		Method:  "GET",
-		Expires: time.Now().Add(expiration),
+// syncGeneratorStatus copies progress written by the generator job into the
+// status object at statusPath onto the job record: its status, the stage
+// of a processing attempt, and its error.
+func (r *Resolver) syncGeneratorStatus(ctx context.Context, bucket objects.Bucket, statusPath string, job *jobs.Job) {
+	if job.Status == jobs.StatusDeadLetter {
+		// The generator's last status predates the dead letter.
+		return
 	}
+	reader, err := bucket.NewReader(ctx, statusPath)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
 
-	url, err := bucket.SignedURL(objectName, opts)
+	// Ignore progress left over from a previous attempt.
+	if reader.Attrs.Updated.Before(job.StartedAt) {
+		return
+	}
+
+	status, err := jobs.ParseStatusFile(reader)
 	if err != nil {
-		return "", err
+		log.Printf("Ignoring unreadable status for %s: %v", job.ID, err)
+		return
+	}
+
+	if status.Status != jobs.StatusProcessing && status.Status != jobs.StatusFailed {
+		return
+	}
+	if status.Status == job.Status && status.Stage == job.Stage && status.Error == job.Error {
+		return
 	}
 
-	return url, nil
+	if status.Status == jobs.StatusFailed && job.Status != jobs.StatusFailed {
+		r.canary.observe(job, nil)
+	}
+	job.Status = status.Status
+	job.Stage = status.Stage
+	job.Error = status.Error
+	job.UpdatedAt = r.clock.Now()
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to update job record for %s: %v", job.ID, err)
+	}
 }
 
-func triggerEpubGeneratorJob(id string) {
-	ctx := context.Background()
+func (r *Resolver) handlePendingJob(ctx context.Context, job *jobs.Job) {
+	if job.StartedAt.IsZero() {
+		// No start time recorded - trigger job for backward compatibility.
+		log.Printf("PENDING job without start time for %s, triggering job", job.ID)
+		r.startGeneration(ctx, job)
+		return
+	}
+	if !job.QueuedAt.IsZero() {
+		// Waiting for a generator slot, which is not a stale start.
+		r.startGeneration(ctx, job)
+		return
+	}
 
-	projectID := os.Getenv("PROJECT_ID")
-	if projectID == "" {
-		log.Printf("PROJECT_ID not set, cannot trigger Cloud Run Job")
+	if r.clock.Now().Sub(job.StartedAt) > 5*time.Minute {
+		if job.Attempts >= r.retry.MaxAttempts {
+			// The generator never reported back; stop re-triggering it.
+			if job.Error == "" {
+				job.Error = fmt.Sprintf("generation did not start after %d attempts", job.Attempts)
+			}
+			r.deadLetter(ctx, job)
+			return
+		}
+
+		// Stale PENDING status - trigger a new job in place of the lost one.
+		log.Printf("Stale PENDING status for %s (started %v ago), triggering new job", job.ID, r.clock.Now().Sub(job.StartedAt))
+		if r.dispatch != nil {
+			r.dispatch.release(job.ID)
+		}
+		now := r.clock.Now()
+		job.Attempts++
+		job.StartedAt = now
+		job.UpdatedAt = now
+		r.startGeneration(ctx, job)
+		if err := r.jobs.Put(ctx, job); err != nil {
+			log.Printf("Failed to update job record: %v", err)
+		}
+	}
+}
+
+// handleFailedJob schedules or performs an automatic retry according to the
+// resolver's retry policy, and moves the job to the dead letter state when
+// no attempts are left.
+func (r *Resolver) handleFailedJob(ctx context.Context, job *jobs.Job) {
+	hadRetry := !job.NextRetryAt.IsZero()
+	if !r.retry.ScheduleRetry(job, job.UpdatedAt) {
+		r.deadLetter(ctx, job)
 		return
 	}
 
-	region := os.Getenv("REGION")
-	if region == "" {
-		region = "asia-northeast1"
+	if r.clock.Now().Before(job.NextRetryAt) {
+		if !hadRetry {
+			if err := r.jobs.Put(ctx, job); err != nil {
+				log.Printf("Failed to update job record: %v", err)
+			}
+		}
+		return
+	}
+
+	log.Printf("Retrying failed job for %s (attempt %d of %d)", job.ID, job.Attempts+1, r.retry.MaxAttempts)
+	now := r.clock.Now()
+	job.Status = jobs.StatusPending
+	job.Attempts++
+	job.Stage = ""
+	job.Error = ""
+	job.ValidationErrors = nil
+	job.StartedAt = now
+	job.UpdatedAt = now
+	job.NextRetryAt = time.Time{}
+	r.startGeneration(ctx, job)
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to update job record: %v", err)
+	}
+}
+
+// deadLetter stops retrying a job that failed every attempt until an
+// operator retries it with retryJob.
+func (r *Resolver) deadLetter(ctx context.Context, job *jobs.Job) {
+	log.Printf("Moving job %s to the dead letter state after %d attempts: %s", job.ID, job.Attempts, job.Error)
+	job.DeadLetter(r.clock.Now())
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to update job record: %v", err)
 	}
+}
 
-	jobName := os.Getenv("EPUB_JOB_NAME")
-	if jobName == "" {
-		jobName = "epub-generator"
+func formatOptionalTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
 	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
 
-	// Create Cloud Run Jobs client.
-	jobsClient, err := run.NewJobsClient(ctx)
+func convertJobStatusToModel(s jobs.Status) model1.EpubStatus {
+	switch s {
+	case jobs.StatusProcessing:
+		return model1.EpubStatusProcessing
+	case jobs.StatusCompleted:
+		return model1.EpubStatusCompleted
+	case jobs.StatusFailed, jobs.StatusDeadLetter:
+		return model1.EpubStatusFailed
+	case jobs.StatusPending:
+		return model1.EpubStatusPending
+	default:
+		return model1.EpubStatusPending
+	}
+}
+
+// epubDisposition returns the Content-Disposition of a stored EPUB, named
+// by the filename template from its object metadata.
+func (r *Resolver) epubDisposition(id string, attrs *objects.Attrs) string {
+	return r.filenames.ContentDisposition(naming.FromMetadata(id, attrs.Metadata))
+}
+
+// generateSignedURL signs a download URL of an object, which is served
+// with a non-empty disposition as its Content-Disposition.
+func generateSignedURL(bucket objects.Bucket, objectName string, expiration time.Duration, disposition string) (string, error) {
+	return bucket.SignedURL(objectName, time.Now().Add(expiration), disposition)
+}
+
+// generatorSpec describes a job with the title and law number from the law
+// index, or else from the law-list cache, which holds the law when it was
+// just searched for. They are the law's current ones, which an old revision
+// may predate.
+func (r *Resolver) generatorSpec(job *jobs.Job, titleEn string) jobs.Spec {
+	spec := jobs.Spec{
+		RevisionID: job.RevisionID,
+		LawTitleEn: titleEn,
+		Articles:   job.Articles,
+		Version:    jobVersion(job),
+	}
+	if outputID(job) != job.RevisionID {
+		spec.OutputID = outputID(job)
+	}
+	parsed, err := lawid.Parse(job.RevisionID)
+	if err != nil || parsed.LawID == "" {
+		return spec
+	}
+	spec.LawID = parsed.LawID
+
+	if r.lawIndex != nil {
+		if entry, ok := r.lawIndex.Get(parsed.LawID); ok {
+			spec.LawTitle, spec.LawNum = entry.Title, entry.LawNum
+			return spec
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), generatorSpecTimeout)
+	defer cancel()
+	item, err := r.getLaw(ctx, parsed.LawID)
 	if err != nil {
-		log.Printf("Failed to create Cloud Run Jobs client: %v", err)
-		return
+		log.Printf("Failed to look up law %s for the job spec of %s: %v", parsed.LawID, job.ID, err)
+		return spec
+	}
+	if item != nil && item.LawInfo != nil {
+		spec.LawNum = item.LawInfo.LawNum
+	}
+	if item != nil && item.RevisionInfo != nil {
+		spec.LawTitle = item.RevisionInfo.LawTitle
 	}
-	defer jobsClient.Close()
+	return spec
+}
 
-	// Construct the job name.
-	fullJobName := fmt.Sprintf("projects/%s/locations/%s/jobs/%s", projectID, region, jobName)
+func (r *Resolver) triggerEpubGeneratorJob(job *jobs.Job) {
+	// Excerpts and tenant documents are written under the job ID rather
+	// than the revision ID, and pinned versions in their own directory.
+	args := []string{
+		"--revision-id", job.RevisionID,
+		"--version", jobVersion(job),
+	}
+	if len(job.Articles) > 0 {
+		args = append(args, "--articles", strings.Join(job.Articles, ","))
+	}
+	if outputID(job) != job.RevisionID {
+		args = append(args, "--output-id", outputID(job))
+	}
 
-	// Create execution request with overrides for arguments.
-	req := &runpb.RunJobRequest{
-		Name: fullJobName,
-		Overrides: &runpb.RunJobRequest_Overrides{
-			ContainerOverrides: []*runpb.RunJobRequest_Overrides_ContainerOverride{
-				{
-					Args: []string{
-						"--revision-id", id,
-						"--version", APP_VERSION,
-					},
-				},
-			},
-		},
+	// The generator adds the English title to the EPUB metadata when set.
+	env := map[string]string{}
+	if title := r.titleEn(job.RevisionID, ""); title != "" {
+		env["LAW_TITLE_EN"] = title
+	}
+	manifest := jobs.NewManifest(job, r.generatorSpec(job, env["LAW_TITLE_EN"]), r.clock.Now())
+	spec, err := json.Marshal(manifest.Spec)
+	if err != nil {
+		log.Printf("Failed to encode job spec for %s: %v", job.ID, err)
+	} else {
+		env["EPUB_JOB_SPEC"] = string(spec)
 	}
+	if uri, err := r.writeManifest(context.Background(), jobs.ManifestPath(jobVersion(job), outputID(job)), manifest); err != nil {
+		// The arguments and spec still describe the job.
+		log.Printf("Failed to write job manifest for %s: %v", job.ID, err)
+	} else {
+		env["EPUB_JOB_MANIFEST"] = uri
+	}
+
+	// Canary jobs write to the directory of the current version.
+	name, err := r.runner.RunGenerator(context.Background(), generatorVersion(job), args, env)
+	if err != nil {
+		log.Printf("Failed to trigger EPUB generation for %s: %v", job.ID, err)
+		// Nothing runs in the slot; the job is started again once a
+		// poll finds it stale.
+		if r.dispatch != nil {
+			r.dispatch.release(job.ID)
+		}
+		return
+	}
+	log.Printf("Triggered EPUB generation for %s: %s", job.ID, name)
+	r.recordExecution(job, name)
+}
 
-	// Execute the job.
-	op, err := jobsClient.RunJob(ctx, req)
+// recordExecution stores the name of the generator execution of a job's
+// attempt on its record, so that operators can find its logs. The record
+// is left alone while it holds another attempt, such as when the caller
+// that started this one has not stored it yet.
+func (r *Resolver) recordExecution(job *jobs.Job, name string) {
+	ctx := context.Background()
+	stored, err := r.jobs.Get(ctx, job.ID)
 	if err != nil {
-		log.Printf("Failed to execute Cloud Run Job: %v", err)
+		log.Printf("Failed to load job record for %s: %v", job.ID, err)
+		return
+	}
+	// Stores may keep timestamps at second precision.
+	if stored.Attempts != job.Attempts || stored.StartedAt.Unix() != job.StartedAt.Unix() {
 		return
 	}
+	stored.ExecutionName = name
+	if err := r.jobs.Put(ctx, stored); err != nil {
+		log.Printf("Failed to record execution of %s: %v", job.ID, err)
+	}
+}
 
-	log.Printf("Successfully triggered Cloud Run Job for revision ID %s, operation: %s", id, op.Name())
+// writeManifest stores the manifest of a job execution at objectPath, next
+// to its status file, replacing the one of the previous execution, and
+// returns its gs:// URI.
+func (r *Resolver) writeManifest(ctx context.Context, objectPath string, manifest *jobs.Manifest) (string, error) {
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	writer := bucket.NewWriter(ctx, objectPath, objects.WriteOptions{ContentType: "application/json"})
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return "", fmt.Errorf("failed to upload manifest: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to upload manifest: %v", err)
+	}
+	return fmt.Sprintf("gs://%s/%s", r.generator.bucketName, objectPath), nil
 }
@@ -0,0 +1,116 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"go.ngs.io/jplaw2epub-web-api/graphql"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+// storeEpub stores a checked EPUB of a revision, as the generator leaves
+// it.
+func storeEpub(t *testing.T, s *testsupport.Server, id, revisionID string, data []byte) {
+	t.Helper()
+	name := graphql.APP_VERSION + "/" + id + ".epub"
+	s.Storage.Put(testsupport.Bucket, name, data, "application/epub+zip")
+	metadata := naming.FromRevision(id, revisionID, "日本国憲法").Metadata()
+	metadata["sha256"] = "checked"
+	if _, err := s.Storage.Bucket(testsupport.Bucket).Update(context.Background(), name, metadata, objects.Conditions{}); err != nil {
+		t.Fatalf("Failed to store metadata of %s: %v", name, err)
+	}
+}
+
+func TestEpubETagFollowsRegeneration(t *testing.T) {
+	s := testsupport.NewServer(t)
+	etag := func() string {
+		t.Helper()
+		resp := s.GraphQL(t, `query ($id: String!) { epub(id: $id) { status etag } }`, map[string]interface{}{"id": "321CONSTITUTION"}, false)
+		if len(resp.Errors) > 0 {
+			t.Fatalf("epub errors = %+v", resp.Errors)
+		}
+		var data struct {
+			Epub struct {
+				Status string
+				Etag   string
+			}
+		}
+		if err := json.Unmarshal(resp.Data, &data); err != nil {
+			t.Fatalf("Failed to decode epub: %v", err)
+		}
+		if data.Epub.Status != "COMPLETED" {
+			t.Fatalf("epub status = %s, want COMPLETED", data.Epub.Status)
+		}
+		return data.Epub.Etag
+	}
+
+	// A law ID keeps its EPUB path when the law is amended; the EPUB
+	// generated again for the new revision replaces the stored one.
+	storeEpub(t, s, "321CONSTITUTION", constitution, []byte("first revision"))
+	before := etag()
+	if again := etag(); again != before {
+		t.Errorf("etag changed without regeneration: %s, then %s", before, again)
+	}
+	storeEpub(t, s, "321CONSTITUTION", "321CONSTITUTION_20260101_000000000000000", []byte("amended revision"))
+	if after := etag(); after == before {
+		t.Errorf("etag after regeneration = %s, want a new one", after)
+	}
+}
+
+func TestEpubReportsGeneratorStage(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		wantStatus string
+		wantStage  *string
+	}{
+		{name: "parse", status: `{"status":"processing","stage":"parse"}`, wantStatus: "PROCESSING", wantStage: ptr("PARSE")},
+		{name: "convert", status: `{"status":"processing","stage":"convert"}`, wantStatus: "PROCESSING", wantStage: ptr("CONVERT")},
+		{name: "write", status: `{"status":"processing","stage":"write"}`, wantStatus: "PROCESSING", wantStage: ptr("WRITE")},
+		{name: "without stage", status: `{"status":"processing"}`, wantStatus: "PROCESSING"},
+		{name: "unknown stage", status: `{"status":"processing","stage":"upload"}`, wantStatus: "PROCESSING"},
+		{name: "failed", status: `{"status":"failed","stage":"write","error":"disk full"}`, wantStatus: "FAILED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testsupport.NewServer(t)
+			requestEpub(t, s, constitution)
+			s.Storage.Put(testsupport.Bucket, graphql.APP_VERSION+"/"+constitution+".status", []byte(tt.status), "application/json")
+
+			resp := s.GraphQL(t, `query ($id: String!) { epub(id: $id) { status stage } }`, map[string]interface{}{"id": constitution}, false)
+			if len(resp.Errors) > 0 {
+				t.Fatalf("epub errors = %+v", resp.Errors)
+			}
+			var data struct {
+				Epub struct {
+					Status string
+					Stage  *string
+				}
+			}
+			if err := json.Unmarshal(resp.Data, &data); err != nil {
+				t.Fatalf("Failed to decode epub: %v", err)
+			}
+			if data.Epub.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", data.Epub.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(data.Epub.Stage, tt.wantStage) {
+				t.Errorf("stage = %v, want %v", deref(data.Epub.Stage), deref(tt.wantStage))
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return "null"
+	}
+	return *s
+}
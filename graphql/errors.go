@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+// codedError classifies an error with a code that the error presenter
+// reports in the "code" extension.
+type codedError struct {
+	code model1.ErrorCode
+	err  error
+}
+
+func (e *codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *codedError) Unwrap() error {
+	return e.err
+}
+
+// ErrorCode returns the code, which REST handlers map to HTTP statuses.
+func (e *codedError) ErrorCode() model1.ErrorCode {
+	return e.code
+}
+
+// withCode classifies err. It returns nil when err is nil.
+func withCode(code model1.ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// codedErrorf formats an error like fmt.Errorf and classifies it.
+func codedErrorf(code model1.ErrorCode, format string, args ...interface{}) error {
+	return &codedError{code: code, err: fmt.Errorf(format, args...)}
+}
+
+// notConfigured reports a feature that needs EPUB_BUCKET_NAME.
+func notConfigured(feature string) error {
+	return codedErrorf(model1.ErrorCodeNotConfigured, "%s is not configured: EPUB_BUCKET_NAME is not set", feature)
+}
+
+// upstreamError classifies a failed e-Gov request as a timeout or an
+// upstream failure.
+func upstreamError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, lawdata.ErrTimeout) || (errors.As(err, &timeout) && timeout.Timeout()) {
+		return withCode(model1.ErrorCodeUpstreamTimeout, err)
+	}
+	return withCode(model1.ErrorCodeUpstreamError, err)
+}
+
+// errorCode returns the code attached to err, or one inferred from
+// well-known errors.
+func errorCode(err error) model1.ErrorCode {
+	var coded *codedError
+	var invalid *lawdata.EPUBError
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.As(err, &invalid):
+		return model1.ErrorCodeConversionFailed
+	case errors.Is(err, errAdminRequired):
+		return model1.ErrorCodeForbidden
+	case introspectionDisabled(err):
+		return model1.ErrorCodeForbidden
+	case errors.Is(err, lawdata.ErrNotFound):
+		return model1.ErrorCodeLawNotFound
+	case errors.Is(err, sandbox.ErrSaturated):
+		return model1.ErrorCodeServerBusy
+	case errors.Is(err, sandbox.ErrTooLarge), errors.Is(err, sandbox.ErrTimeout):
+		return model1.ErrorCodeConversionFailed
+	case errors.Is(err, lawdata.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return model1.ErrorCodeUpstreamTimeout
+	default:
+		return model1.ErrorCodeInternalServerError
+	}
+}
+
+// introspectionDisabled reports whether err is gqlgen's refusal of an
+// introspection query turned off by Introspection, which is a plain error.
+func introspectionDisabled(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if err.Error() == "introspection disabled" {
+			return true
+		}
+	}
+	return false
+}
+
+// retryable reports whether the same request may succeed later.
+func retryable(code model1.ErrorCode) bool {
+	switch code {
+	case model1.ErrorCodeQuotaExceeded, model1.ErrorCodeUpstreamTimeout, model1.ErrorCodeUpstreamError, model1.ErrorCodeServerBusy:
+		return true
+	case model1.ErrorCodeLawNotFound, model1.ErrorCodeInvalidLawID, model1.ErrorCodeNotFound,
+		model1.ErrorCodeBadUserInput, model1.ErrorCodeForbidden, model1.ErrorCodeConversionFailed,
+		model1.ErrorCodeNotConfigured, model1.ErrorCodeOperationNotAllowed, model1.ErrorCodeInternalServerError:
+		return false
+	default:
+		return false
+	}
+}
+
+// presentError adds the "code" and "retryable" extensions to resolver
+// errors, so that clients need not parse messages. Codes set by gqlgen
+// are kept.
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+	if _, ok := gqlErr.Extensions["code"]; ok {
+		return gqlErr
+	}
+	if gqlErr.Extensions == nil {
+		gqlErr.Extensions = make(map[string]interface{})
+	}
+	code := errorCode(err)
+	gqlErr.Extensions["code"] = code
+	gqlErr.Extensions["retryable"] = retryable(code)
+	var invalid *lawdata.EPUBError
+	if errors.As(err, &invalid) {
+		gqlErr.Extensions["validationErrors"] = invalid.Problems
+	}
+	return gqlErr
+}
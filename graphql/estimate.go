@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+// estimateSamples caps the completed jobs that estimates are drawn from.
+const estimateSamples = 500
+
+// estimateRefresh is how long the generation times are reused before the
+// job store is listed again.
+const estimateRefresh = 10 * time.Minute
+
+// estimateNeighbors is how many jobs of the closest article counts an
+// estimate is the median of.
+const estimateNeighbors = 9
+
+// articleCountTimeout bounds the law data fetch that counts the articles
+// of a new job.
+const articleCountTimeout = 3 * time.Second
+
+// generationSample is the generation time of a completed job.
+type generationSample struct {
+	articles int
+	seconds  float64
+}
+
+// durationEstimator holds the generation times of recent completed jobs.
+type durationEstimator struct {
+	mu       sync.Mutex
+	samples  []generationSample
+	loadedAt time.Time
+}
+
+// estimatedSeconds returns the seconds the generation of a pending or
+// processing EPUB is expected to take from now, or nil for other EPUBs and
+// without history.
+func (r *Resolver) estimatedSeconds(ctx context.Context, epub *model1.Epub) *float64 {
+	switch epub.Status {
+	case model1.EpubStatusCompleted, model1.EpubStatusFailed:
+		return nil
+	case model1.EpubStatusPending, model1.EpubStatusProcessing:
+	}
+
+	job, err := r.jobs.Get(ctx, pinnedJobID(scopedJobID(ctx, epub.ID), epub.ConverterVersion))
+	if err != nil {
+		if !errors.Is(err, jobs.ErrNotFound) {
+			log.Printf("Failed to load job record for %s: %v", epub.ID, err)
+		}
+		return nil
+	}
+	total, ok := estimateDuration(r.generationSamples(ctx), job.ArticleCount)
+	if !ok {
+		return nil
+	}
+
+	remaining := total
+	if job.QueuedAt.IsZero() && !job.StartedAt.IsZero() {
+		remaining -= r.clock.Now().Sub(job.StartedAt).Seconds()
+	}
+	// An overdue generation is expected to finish at any moment.
+	remaining = math.Max(math.Round(remaining), 0)
+	return &remaining
+}
+
+// generationSamples returns the generation times of the last
+// estimateSamples completed jobs, listing them at most every
+// estimateRefresh. The previous samples are kept when listing fails.
+func (r *Resolver) generationSamples(ctx context.Context) []generationSample {
+	e := &r.estimates
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := r.clock.Now()
+	if !e.loadedAt.IsZero() && now.Sub(e.loadedAt) < estimateRefresh {
+		return e.samples
+	}
+	records, err := r.jobs.List(ctx, jobs.ListOptions{Status: jobs.StatusCompleted, Limit: estimateSamples})
+	if err != nil {
+		log.Printf("Failed to list completed jobs for estimates: %v", err)
+		return e.samples
+	}
+	samples := make([]generationSample, 0, len(records))
+	for _, job := range records {
+		if job.StartedAt.IsZero() || !job.CompletedAt.After(job.StartedAt) {
+			continue
+		}
+		samples = append(samples, generationSample{
+			articles: job.ArticleCount,
+			seconds:  job.CompletedAt.Sub(job.StartedAt).Seconds(),
+		})
+	}
+	e.samples, e.loadedAt = samples, now
+	return samples
+}
+
+// estimateDuration returns the median generation time of the samples with
+// the estimateNeighbors closest article counts within a factor of two of
+// articles, or of all samples when articles is unknown or no sample is that
+// close.
+func estimateDuration(samples []generationSample, articles int) (float64, bool) {
+	if articles > 0 {
+		var similar []generationSample
+		for _, s := range samples {
+			if s.articles > 0 && sizeDistance(s.articles, articles) <= math.Ln2 {
+				similar = append(similar, s)
+			}
+		}
+		sort.SliceStable(similar, func(i, k int) bool {
+			return sizeDistance(similar[i].articles, articles) < sizeDistance(similar[k].articles, articles)
+		})
+		if len(similar) > estimateNeighbors {
+			similar = similar[:estimateNeighbors]
+		}
+		if len(similar) > 0 {
+			return medianSeconds(similar), true
+		}
+	}
+	if len(samples) == 0 {
+		return 0, false
+	}
+	return medianSeconds(samples), true
+}
+
+// sizeDistance compares article counts by their ratio.
+func sizeDistance(a, b int) float64 {
+	return math.Abs(math.Log(float64(a) / float64(b)))
+}
+
+func medianSeconds(samples []generationSample) float64 {
+	seconds := make([]float64, len(samples))
+	for i, s := range samples {
+		seconds[i] = s.seconds
+	}
+	sort.Float64s(seconds)
+	n := len(seconds)
+	if n%2 == 1 {
+		return seconds[n/2]
+	}
+	return (seconds[n/2-1] + seconds[n/2]) / 2
+}
+
+// countArticles returns the number of articles in a document: one for an
+// excerpt of a single article, or those of the law, whose data is fetched
+// within articleCountTimeout. It returns zero when the count is unknown,
+// such as for other excerpts.
+func (r *Resolver) countArticles(ctx context.Context, revisionID string, articles []string) int {
+	switch {
+	case len(articles) == 1 && labelUnit(articles[0]) == "条":
+		return 1
+	case len(articles) > 0:
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, articleCountTimeout)
+	defer cancel()
+	data, err := r.lawData.FetchLawData(ctx, revisionID)
+	if err != nil {
+		log.Printf("Failed to fetch law %s to count its articles: %v", revisionID, err)
+		return 0
+	}
+	law, err := lawdata.ParseLawData(data)
+	if err != nil {
+		log.Printf("Failed to parse law %s to count its articles: %v", revisionID, err)
+		return 0
+	}
+	return law.ArticleCount()
+}
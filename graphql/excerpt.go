@@ -0,0 +1,61 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+// provisionLabelPattern matches article and division labels such as 第1条,
+// 第十二条の2, or 第3章.
+var provisionLabelPattern = regexp.MustCompile(`^第[0-9０-９一二三四五六七八九十百千]+(条|編|章|節|款|目)(の[0-9０-９一二三四五六七八九十百千]+)*$`)
+
+// normalizeArticles validates an excerpt selection. A single label selects
+// one article or division; two labels select the inclusive range between
+// them. An empty selection means the full law.
+func normalizeArticles(articles []string) ([]string, error) {
+	if len(articles) == 0 {
+		return nil, nil
+	}
+	if len(articles) > 2 {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "articles accepts a single label or a start and end label, got %d", len(articles))
+	}
+
+	result := make([]string, 0, len(articles))
+	for _, label := range articles {
+		label = strings.TrimSpace(label)
+		if !provisionLabelPattern.MatchString(label) {
+			return nil, codedErrorf(model1.ErrorCodeBadUserInput, "invalid article label: %q", label)
+		}
+		result = append(result, label)
+	}
+
+	if len(result) == 2 {
+		if labelUnit(result[0]) != labelUnit(result[1]) {
+			return nil, codedErrorf(model1.ErrorCodeBadUserInput, "article range must use the same unit: %s, %s", result[0], result[1])
+		}
+		if result[0] == result[1] {
+			result = result[:1]
+		}
+	}
+	return result, nil
+}
+
+// labelUnit returns the unit character (条, 章, ...) of a label.
+func labelUnit(label string) string {
+	return provisionLabelPattern.FindStringSubmatch(label)[1]
+}
+
+// excerptID returns the job ID for a revision and article selection. Full
+// laws keep the revision ID so existing objects remain valid.
+func excerptID(revisionID string, articles []string) string {
+	if len(articles) == 0 {
+		return revisionID
+	}
+	sum := sha256.Sum256([]byte(strings.Join(articles, "\n")))
+	return fmt.Sprintf("%s-%s", revisionID, hex.EncodeToString(sum[:])[:12])
+}
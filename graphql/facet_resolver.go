@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+)
+
+// maxFacetResults is the number of results counted for facets. Pages of
+// maxSortedResults are fetched through the law-list cache, so repeated
+// facet queries do not reach e-Gov.
+const maxFacetResults = 10000
+
+// lawFacets counts the laws matching params by category, law type, and era.
+// The pages after the first, which tells the total, are fetched
+// concurrently.
+func (r *Resolver) lawFacets(ctx context.Context, params *lawapi.GetLawsParams) (*model1.LawFacets, error) {
+	page := *params
+	limit, offset := int32(maxSortedResults), int32(0)
+	page.Limit, page.Offset = &limit, &offset
+	first, err := r.getLaws(ctx, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	items := first.Laws
+	if len(first.Laws) > 0 {
+		end := int(min(first.TotalCount, maxFacetResults))
+		rest, err := fetchPages(ctx, r.upstream, r.pageConcurrency, maxSortedResults, len(first.Laws), end, func(ctx context.Context, offset int32) ([]lawapi.LawItem, error) {
+			next := page
+			next.Offset = &offset
+			resp, err := r.getLaws(ctx, &next)
+			if err != nil {
+				return nil, err
+			}
+			return resp.Laws, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(slices.Clip(items), rest...)
+	}
+
+	counts := newFacetCounts()
+	for _, item := range items {
+		counts.add(item)
+	}
+	return counts.result(first.TotalCount), nil
+}
+
+// facetCounts accumulates the facets of laws.
+type facetCounts struct {
+	counted    int
+	categories map[string]int
+	lawTypes   map[model1.LawType]int
+	eras       map[model1.LawNumEra]int
+}
+
+func newFacetCounts() *facetCounts {
+	return &facetCounts{
+		categories: make(map[string]int),
+		lawTypes:   make(map[model1.LawType]int),
+		eras:       make(map[model1.LawNumEra]int),
+	}
+}
+
+func (c *facetCounts) add(item lawapi.LawItem) {
+	c.counted++
+	revision := item.RevisionInfo
+	if revision == nil {
+		revision = item.CurrentRevisionInfo
+	}
+	if revision != nil && revision.Category != "" {
+		c.categories[revision.Category]++
+	}
+	if item.LawInfo == nil {
+		return
+	}
+	if lawType := convertLawTypeToModel(item.LawInfo.LawType); lawType != nil {
+		c.lawTypes[*lawType]++
+	}
+	if era := convertLawNumEraToModel(item.LawInfo.LawNumEra); era != nil {
+		c.eras[*era]++
+	}
+}
+
+// result returns the facets in the order of the enums, with categories in
+// e-Gov code order followed by unknown names in name order.
+func (c *facetCounts) result(total int64) *model1.LawFacets {
+	facets := &model1.LawFacets{
+		TotalCount: int(total),
+		Counted:    c.counted,
+		Categories: []model1.CategoryFacet{},
+		LawTypes:   []model1.LawTypeFacet{},
+		Eras:       []model1.EraFacet{},
+	}
+
+	codes := make(map[lawapi.CategoryCd]model1.CategoryCode, len(categoryCodeMap))
+	for code, cd := range categoryCodeMap {
+		codes[cd] = code
+	}
+	known := make(map[string]bool)
+	for i, name := range handlers.CategoryNames() {
+		known[name] = true
+		count := c.categories[name]
+		if count == 0 {
+			continue
+		}
+		facet := model1.CategoryFacet{Name: name, Count: count}
+		if code, ok := codes[lawapi.CategoryCd(fmt.Sprintf("%03d", i+1))]; ok {
+			facet.Code = &code
+		}
+		facets.Categories = append(facets.Categories, facet)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.categories)) {
+		if !known[name] {
+			facets.Categories = append(facets.Categories, model1.CategoryFacet{Name: name, Count: c.categories[name]})
+		}
+	}
+
+	for _, lawType := range model1.AllLawType {
+		if count := c.lawTypes[lawType]; count > 0 {
+			facets.LawTypes = append(facets.LawTypes, model1.LawTypeFacet{LawType: lawType, Count: count})
+		}
+	}
+	for _, era := range model1.AllLawNumEra {
+		if count := c.eras[era]; count > 0 {
+			facets.Eras = append(facets.Eras, model1.EraFacet{Era: era, Count: count})
+		}
+	}
+	return facets
+}
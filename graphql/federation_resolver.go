@@ -0,0 +1,50 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+)
+
+// findLaw resolves a federation reference to a law. The law is fetched only
+// when a field of it is selected.
+func (r *Resolver) findLaw(id string) (*model1.Law, error) {
+	parsed, err := parseLawID(id)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Kind != lawid.LawID {
+		return nil, codedErrorf(model1.ErrorCodeInvalidLawID, "%s is not a law ID", id)
+	}
+	return &model1.Law{ID: parsed.Value}, nil
+}
+
+// lawEntityItem returns the law of a Law entity, or nil when e-Gov has none.
+func (r *Resolver) lawEntityItem(ctx context.Context, law *model1.Law) (*lawapi.LawItem, error) {
+	return r.getLaw(ctx, law.ID)
+}
+
+// findEpub resolves a federation reference to an EPUB by the ID the epub
+// query returned, reporting its state without starting generation. The job
+// record recalls the articles of an excerpt, whose ID is a digest of them.
+func (r *Resolver) findEpub(ctx context.Context, id string) (*model1.Epub, error) {
+	revisionID, articles := id, []string(nil)
+	job, err := r.jobs.Get(ctx, scopedJobID(ctx, id))
+	switch {
+	case err == nil:
+		revisionID, articles = job.RevisionID, job.Articles
+	case !errors.Is(err, jobs.ErrNotFound):
+		return nil, fmt.Errorf("failed to load job record: %v", err)
+	}
+
+	epub, err := r.GetEpubStatus(ctx, revisionID, articles)
+	if errors.Is(err, jobs.ErrNotFound) {
+		return nil, codedErrorf(model1.ErrorCodeNotFound, "EPUB %s has not been requested", id)
+	}
+	return epub, err
+}
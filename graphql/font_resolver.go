@@ -0,0 +1,36 @@
+package graphql
+
+import (
+	"errors"
+
+	"go.ngs.io/jplaw2epub-web-api/fonts"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+// listFonts lists the fonts that converted EPUBs can embed, ordered by
+// name.
+func (r *Resolver) listFonts() []model1.Font {
+	list := r.fonts.List()
+	result := make([]model1.Font, len(list))
+	for i, font := range list {
+		result[i] = model1.Font{
+			Name:      font.Name,
+			MediaType: font.MediaType,
+			Size:      len(font.Data),
+			Sha256:    font.Sum,
+		}
+	}
+	return result
+}
+
+// font returns the configured font with the name.
+func (r *Resolver) font(name string) (*fonts.Font, error) {
+	if r.fonts.Len() == 0 {
+		return nil, withCode(model1.ErrorCodeNotConfigured, errors.New("fonts are not available: CONVERT_FONT_DIR is not set"))
+	}
+	font, ok := r.fonts.Get(name)
+	if !ok {
+		return nil, codedErrorf(model1.ErrorCodeNotFound, "font %q not found", name)
+	}
+	return font, nil
+}
@@ -0,0 +1,154 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// checksumKey is the object metadata key holding the hex SHA-256 digest of
+// a stored document.
+const checksumKey = "sha256"
+
+// checksum returns the hex SHA-256 digest of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hashObject reads a generation of a stored object and returns its hex
+// SHA-256 digest.
+func hashObject(ctx context.Context, bucket objects.Bucket, name string, generation int64) (string, error) {
+	reader, err := bucket.NewRangeReader(ctx, name, generation, 0, -1)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checkGeneratedEpub validates an EPUB uploaded by the generator job the
+// first time it is observed, and stores its digest and the naming fields,
+// with the title read from the EPUB, in its object metadata so that the
+// check is not repeated. The update is skipped if the object changed since
+// attrs were read. An EPUB that fails lawdata.CheckEPUB is returned as an
+// *lawdata.EPUBError; other failures are logged and the original attrs
+// returned.
+func checkGeneratedEpub(ctx context.Context, bucket objects.Bucket, attrs *objects.Attrs, fields naming.Fields) (*objects.Attrs, error) {
+	if attrs.Metadata[checksumKey] != "" && naming.FromMetadata(fields.ID, attrs.Metadata).RevisionID != "" {
+		return attrs, nil
+	}
+
+	data, err := readObject(ctx, bucket, attrs.Name, attrs.Generation)
+	if err != nil {
+		log.Printf("Failed to check %s: %v", attrs.Name, err)
+		return attrs, nil
+	}
+	summary, err := lawdata.CheckEPUB(data)
+	if err != nil {
+		return attrs, err
+	}
+	fields.LawTitle = summary.Title
+	metadata := fields.Metadata()
+	for key, value := range attrs.Metadata {
+		metadata[key] = value
+	}
+	metadata[checksumKey] = checksum(data)
+	updated, err := bucket.Update(ctx, attrs.Name, metadata, objects.Conditions{MetagenerationMatch: attrs.Metageneration})
+	if err != nil {
+		log.Printf("Failed to record checksum of %s: %v", attrs.Name, err)
+		return attrs, nil
+	}
+	return updated, nil
+}
+
+// readObject reads a generation of a stored object.
+func readObject(ctx context.Context, bucket objects.Bucket, name string, generation int64) ([]byte, error) {
+	reader, err := bucket.NewRangeReader(ctx, name, generation, 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return data, nil
+}
+
+// VerifyDocument re-reads a stored document and compares it with the digest
+// recorded when it was written. The id is an EPUB ID as returned by the
+// epub query, the name of a converted EPUB, a bulk export ID, or a statute
+// book ID of the caller's tenant, as for downloads. It returns nil when no
+// such document is stored.
+func (r *Resolver) VerifyDocument(ctx context.Context, id string) (*handlers.VerifyResult, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("verification")
+	}
+	// The id is joined below the tenant's prefix.
+	if err := handlers.ValidateDocumentID(id); err != nil {
+		return nil, withCode(model1.ErrorCodeBadUserInput, err)
+	}
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := storagePrefix(ctx)
+	paths := []string{
+		fmt.Sprintf("%s/%s.epub", prefix, id),
+		fmt.Sprintf("%s/converted/%s.epub", prefix, id),
+		bulkExportArchivePath(prefix, id),
+		bundlePath(prefix, id),
+	}
+	for _, path := range paths {
+		attrs, err := bucket.Attrs(ctx, path)
+		if errors.Is(err, objects.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		computed, err := hashObject(ctx, bucket, path, attrs.Generation)
+		if err != nil {
+			return nil, err
+		}
+		result := &handlers.VerifyResult{
+			ID:         id,
+			Path:       path,
+			Size:       attrs.Size,
+			Recorded:   attrs.Metadata[checksumKey],
+			Computed:   computed,
+			VerifiedAt: r.clock.Now().UTC().Format(time.RFC3339),
+		}
+		switch {
+		case result.Recorded == "":
+			result.Status = handlers.VerifyUnrecorded
+		case result.Recorded == computed:
+			result.Status = handlers.VerifyOK
+		default:
+			result.Status = handlers.VerifyMismatch
+		}
+		return result, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,75 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+// getLawBody fetches the law XML for a revision and parses its article
+// structure. Attachments are listed from the same response.
+func (r *Resolver) getLawBody(ctx context.Context, revisionID string) (*lawdata.Law, error) {
+	revisionID, data, err := r.fetchLawBody(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	return r.parseLawBody(revisionID, data)
+}
+
+// fetchLawBody fetches the law XML for a revision, returning the revision
+// ID normalized.
+func (r *Resolver) fetchLawBody(ctx context.Context, revisionID string) (string, *lawdata.LawData, error) {
+	parsed, err := parseLawID(revisionID)
+	if err != nil {
+		return "", nil, err
+	}
+	revisionID = parsed.Value
+
+	data, err := r.lawData.FetchLawData(ctx, revisionID)
+	if errors.Is(err, lawdata.ErrNotFound) {
+		return "", nil, codedErrorf(model1.ErrorCodeLawNotFound, "law revision %s not found", revisionID)
+	}
+	if err != nil {
+		return "", nil, upstreamError(err)
+	}
+	return revisionID, data, nil
+}
+
+// parseLawBody parses law XML fetched by fetchLawBody.
+func (r *Resolver) parseLawBody(revisionID string, data *lawdata.LawData) (*lawdata.Law, error) {
+	law, err := lawdata.ParseLawData(data)
+	if err != nil {
+		return nil, codedErrorf(model1.ErrorCodeConversionFailed, "failed to parse law %s: %v", revisionID, err)
+	}
+	law.RevisionID = revisionID
+	law.TitleEn = r.titleEn(revisionID, law.LawNum)
+	for i := range law.Attachments {
+		if law.Attachments[i].LawRevisionID == "" {
+			law.Attachments[i].LawRevisionID = revisionID
+		}
+	}
+
+	return law, nil
+}
+
+// tableOfContents lists the divisions, articles, and supplementary
+// provisions of a revision with their anchors, parents first.
+func (r *Resolver) tableOfContents(ctx context.Context, revisionID string) ([]model1.TableOfContentsEntry, error) {
+	law, err := r.cachedLawBody(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []model1.TableOfContentsEntry
+	var visit func(list []lawdata.ContentsEntry, level int)
+	visit = func(list []lawdata.ContentsEntry, level int) {
+		for _, entry := range list {
+			entries = append(entries, model1.TableOfContentsEntry{Anchor: entry.Anchor, Label: entry.Label, Level: level})
+			visit(entry.Children, level+1)
+		}
+	}
+	visit(law.TableOfContents(), 1)
+	return entries, nil
+}
@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+)
+
+// parseLawID validates a law ID, law number, or revision ID before it is
+// sent to e-Gov or the generator.
+func parseLawID(id string) (lawid.ID, error) {
+	parsed, err := lawid.Parse(id)
+	if err != nil {
+		return lawid.ID{}, withCode(model1.ErrorCodeInvalidLawID, err)
+	}
+	return parsed, nil
+}
+
+// lawsParams returns the e-Gov parameters for the filters of a laws query.
+func lawsParams(lawID, lawNum, lawTitle, lawTitleKana *string, lawType []model1.LawType, asof *time.Time, categoryCode []model1.CategoryCode, promulgateDateFrom, promulgateDateTo *time.Time) *lawapi.GetLawsParams {
+	params := &lawapi.GetLawsParams{
+		LawId:        lawID,
+		LawNum:       lawNum,
+		LawTitle:     lawTitle,
+		LawTitleKana: lawTitleKana,
+	}
+	if len(lawType) > 0 {
+		converted := convertLawType(lawType)
+		params.LawType = &converted
+	}
+	if asof != nil {
+		date := lawapi.Date(*asof)
+		params.Asof = &date
+	}
+	if len(categoryCode) > 0 {
+		converted := convertCategoryCode(categoryCode)
+		params.CategoryCd = &converted
+	}
+	if promulgateDateFrom != nil {
+		date := lawapi.Date(*promulgateDateFrom)
+		params.PromulgationDateFrom = &date
+	}
+	if promulgateDateTo != nil {
+		date := lawapi.Date(*promulgateDateTo)
+		params.PromulgationDateTo = &date
+	}
+	return params
+}
+
+// SearchLaws lists laws for use outside GraphQL, such as the gRPC server.
+func (r *Resolver) SearchLaws(ctx context.Context, params *lawapi.GetLawsParams) (*lawapi.LawsResponse, error) {
+	return r.getLaws(ctx, params)
+}
+
+// maxSortedResults is the largest result set sorted by a key other than
+// relevance. e-Gov returns results in its own order, so the whole set is
+// fetched and sorted before the requested page is cut out.
+const maxSortedResults = 1000
+
+// searchLaws lists laws in the requested order.
+func (r *Resolver) searchLaws(ctx context.Context, params *lawapi.GetLawsParams, key model1.LawSort, order model1.SortOrder) (*lawapi.LawsResponse, error) {
+	if key == model1.LawSortRelevance && order == model1.SortOrderAsc {
+		return r.getLaws(ctx, params)
+	}
+
+	all := *params
+	all.Limit, all.Offset = sortedWindow()
+	resp, err := r.getLaws(ctx, &all)
+	if err != nil {
+		return nil, err
+	}
+	if resp.TotalCount > maxSortedResults {
+		return nil, tooManyToSort(resp.TotalCount)
+	}
+	items := sortLawItems(resp.Laws, key, order)
+	start, end := pageBounds(params.Limit, params.Offset, len(items))
+	return &lawapi.LawsResponse{
+		TotalCount: resp.TotalCount,
+		Count:      int64(end - start),
+		NextOffset: int64(end),
+		Laws:       items[start:end],
+	}, nil
+}
+
+// searchKeyword runs a keyword search in the requested order.
+func (r *Resolver) searchKeyword(ctx context.Context, params *lawapi.GetKeywordParams, key model1.LawSort, order model1.SortOrder) (*lawapi.KeywordResponse, error) {
+	if key == model1.LawSortRelevance && order == model1.SortOrderAsc {
+		return r.getKeyword(ctx, params)
+	}
+
+	all := *params
+	all.Limit, all.Offset = sortedWindow()
+	resp, err := r.getKeyword(ctx, &all)
+	if err != nil {
+		return nil, err
+	}
+	if resp.TotalCount > maxSortedResults {
+		return nil, tooManyToSort(resp.TotalCount)
+	}
+	items := sortKeywordItems(resp.Items, key, order)
+	start, end := pageBounds(params.Limit, params.Offset, len(items))
+	page := items[start:end]
+	var sentences int64
+	for _, item := range page {
+		sentences += int64(len(item.Sentences))
+	}
+	return &lawapi.KeywordResponse{
+		TotalCount:    resp.TotalCount,
+		SentenceCount: sentences,
+		NextOffset:    int64(end),
+		Items:         page,
+	}, nil
+}
+
+// sortedWindow returns the limit and offset that fetch a whole result set
+// for sorting.
+func sortedWindow() (*int32, *int32) {
+	limit, offset := int32(maxSortedResults), int32(0)
+	return &limit, &offset
+}
+
+// pageBounds returns the slice bounds of the page a client asked for, with
+// e-Gov's defaults of limit 100 and offset 0.
+func pageBounds(limit, offset *int32, n int) (int, int) {
+	start, size := 0, 100
+	if offset != nil && *offset > 0 {
+		start = int(*offset)
+	}
+	if limit != nil && *limit >= 0 {
+		size = int(*limit)
+	}
+	start = min(start, n)
+	return start, min(start+size, n)
+}
+
+func tooManyToSort(total int64) error {
+	return codedErrorf(model1.ErrorCodeBadUserInput, "%d results are too many to sort (at most %d); narrow the search or sort by RELEVANCE", total, maxSortedResults)
+}
+
+// GetLaw looks up a single law for use outside GraphQL. It returns nil when
+// no law matches.
+func (r *Resolver) GetLaw(ctx context.Context, id string) (*lawapi.LawItem, error) {
+	return r.getLaw(ctx, id)
+}
+
+// getLaw looks up a single law by law ID or law number. Only metadata is
+// fetched; the law body is never requested. It returns nil when no law
+// matches.
+func (r *Resolver) getLaw(ctx context.Context, id string) (*lawapi.LawItem, error) {
+	limit := int32(1)
+	params := &lawapi.GetLawsParams{
+		Limit: &limit,
+	}
+	parsed, err := lawid.Parse(id)
+	if err != nil {
+		// A malformed identifier cannot match any law.
+		return nil, nil
+	}
+	switch parsed.Kind {
+	case lawid.LawID:
+		params.LawId = &parsed.Value
+	case lawid.LawNum:
+		params.LawNum = &parsed.Value
+	case lawid.RevisionID:
+		// Laws are looked up by their current revision only.
+		return nil, nil
+	}
+
+	resp, err := r.getLaws(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Laws) == 0 {
+		return nil, nil
+	}
+	return &resp.Laws[0], nil
+}
+
+// lawItemRevision returns the revision ID of a law item's revisionInfo,
+// falling back to its current revision, or "" when it has neither.
+func lawItemRevision(item *lawapi.LawItem) string {
+	if item.RevisionInfo != nil && item.RevisionInfo.LawRevisionId != "" {
+		return item.RevisionInfo.LawRevisionId
+	}
+	if item.CurrentRevisionInfo != nil {
+		return item.CurrentRevisionInfo.LawRevisionId
+	}
+	return ""
+}
@@ -0,0 +1,275 @@
+package graphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/library"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+const (
+	// maxBookmarks and maxSavedSearches bound the size of a library.
+	maxBookmarks     = 500
+	maxSavedSearches = 100
+)
+
+// libraryOwner returns the tenant and user whose library the caller uses.
+func libraryOwner(ctx context.Context) (string, string, error) {
+	userID := tenant.UserIDFromContext(ctx)
+	if userID == "" {
+		return "", "", withCode(model1.ErrorCodeForbidden, errors.New("bookmarks and saved searches require signing in, or a tenant API key and an X-User-Id header"))
+	}
+	return tenant.IDFromContext(ctx), userID, nil
+}
+
+// myLibrary returns the caller's library.
+func (r *Resolver) myLibrary(ctx context.Context) (*library.Library, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.library.Get(ctx, tenantID, userID)
+}
+
+// myBookmarks lists the caller's bookmarks, newest first.
+func (r *Resolver) myBookmarks(ctx context.Context) ([]model1.Bookmark, error) {
+	lib, err := r.myLibrary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model1.Bookmark, len(lib.Bookmarks))
+	for i, bookmark := range lib.Bookmarks {
+		result[i] = convertBookmark(bookmark)
+	}
+	return result, nil
+}
+
+// mySavedSearches lists the caller's saved searches, newest first.
+func (r *Resolver) mySavedSearches(ctx context.Context) ([]model1.SavedSearch, error) {
+	lib, err := r.myLibrary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]model1.SavedSearch, len(lib.Searches))
+	for i, search := range lib.Searches {
+		result[i] = convertSavedSearch(search)
+	}
+	return result, nil
+}
+
+// bookmarkLaw adds a law to the caller's bookmarks, or updates the note of
+// an existing bookmark.
+func (r *Resolver) bookmarkLaw(ctx context.Context, id string, note *string) (*model1.Bookmark, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	item, err := r.getLaw(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.LawInfo == nil {
+		return nil, codedErrorf(model1.ErrorCodeLawNotFound, "law %s not found", id)
+	}
+	bookmark := library.Bookmark{LawID: item.LawInfo.LawId, CreatedAt: r.clock.Now().UTC()}
+	if revision := item.CurrentRevisionInfo; revision != nil {
+		bookmark.Title = revision.LawTitle
+	} else if item.RevisionInfo != nil {
+		bookmark.Title = item.RevisionInfo.LawTitle
+	}
+	if note != nil {
+		bookmark.Note = *note
+	}
+
+	_, err = r.library.Update(ctx, tenantID, userID, func(lib *library.Library) error {
+		i := slices.IndexFunc(lib.Bookmarks, func(b library.Bookmark) bool { return b.LawID == bookmark.LawID })
+		if i >= 0 {
+			bookmark.CreatedAt = lib.Bookmarks[i].CreatedAt
+			if note == nil {
+				bookmark.Note = lib.Bookmarks[i].Note
+			}
+			lib.Bookmarks[i] = bookmark
+			return nil
+		}
+		if len(lib.Bookmarks) >= maxBookmarks {
+			return codedErrorf(model1.ErrorCodeBadUserInput, "at most %d laws can be bookmarked", maxBookmarks)
+		}
+		lib.Bookmarks = append([]library.Bookmark{bookmark}, lib.Bookmarks...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := convertBookmark(bookmark)
+	return &result, nil
+}
+
+// removeBookmark removes a law from the caller's bookmarks. It reports
+// false when the law was not bookmarked.
+func (r *Resolver) removeBookmark(ctx context.Context, id string) (bool, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return false, err
+	}
+	parsed, err := parseLawID(id)
+	if err != nil {
+		return false, err
+	}
+	lawID := parsed.LawID
+	if lawID == "" {
+		// Bookmarks are kept by law ID, so a law number is looked up.
+		item, err := r.getLaw(ctx, parsed.Value)
+		if err != nil {
+			return false, err
+		}
+		if item == nil || item.LawInfo == nil {
+			return false, nil
+		}
+		lawID = item.LawInfo.LawId
+	}
+
+	removed := false
+	_, err = r.library.Update(ctx, tenantID, userID, func(lib *library.Library) error {
+		before := len(lib.Bookmarks)
+		lib.Bookmarks = slices.DeleteFunc(lib.Bookmarks, func(b library.Bookmark) bool { return b.LawID == lawID })
+		removed = len(lib.Bookmarks) < before
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return removed, nil
+}
+
+// saveSearch saves a search in the caller's library, replacing the one with
+// input.ID when given.
+func (r *Resolver) saveSearch(ctx context.Context, input model1.SavedSearchInput) (*model1.SavedSearch, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name == "" {
+		return nil, withCode(model1.ErrorCodeBadUserInput, errors.New("name must not be empty"))
+	}
+
+	search := library.SavedSearch{
+		Name:      input.Name,
+		Sort:      string(sortKey(input.Sort)),
+		Order:     string(sortOrder(input.Order)),
+		CreatedAt: r.clock.Now().UTC(),
+	}
+	if input.Keyword != nil {
+		search.Keyword = *input.Keyword
+	}
+	if input.LawTitle != nil {
+		search.LawTitle = *input.LawTitle
+	}
+	if input.LawNum != nil {
+		search.LawNum = *input.LawNum
+	}
+	for _, lawType := range input.LawType {
+		search.LawTypes = append(search.LawTypes, string(lawType))
+	}
+	for _, code := range input.CategoryCode {
+		search.Categories = append(search.Categories, string(code))
+	}
+	if input.ID != nil {
+		search.ID = *input.ID
+	} else {
+		random := make([]byte, 8)
+		if _, err := rand.Read(random); err != nil {
+			return nil, fmt.Errorf("failed to generate saved search ID: %v", err)
+		}
+		search.ID = hex.EncodeToString(random)
+	}
+
+	_, err = r.library.Update(ctx, tenantID, userID, func(lib *library.Library) error {
+		i := slices.IndexFunc(lib.Searches, func(s library.SavedSearch) bool { return s.ID == search.ID })
+		if i >= 0 {
+			search.CreatedAt = lib.Searches[i].CreatedAt
+			lib.Searches[i] = search
+			return nil
+		}
+		if input.ID != nil {
+			return codedErrorf(model1.ErrorCodeNotFound, "saved search %q not found", search.ID)
+		}
+		if len(lib.Searches) >= maxSavedSearches {
+			return codedErrorf(model1.ErrorCodeBadUserInput, "at most %d searches can be saved", maxSavedSearches)
+		}
+		lib.Searches = append([]library.SavedSearch{search}, lib.Searches...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := convertSavedSearch(search)
+	return &result, nil
+}
+
+// deleteSavedSearch removes a saved search. It reports false when there is
+// no such search.
+func (r *Resolver) deleteSavedSearch(ctx context.Context, id string) (bool, error) {
+	tenantID, userID, err := libraryOwner(ctx)
+	if err != nil {
+		return false, err
+	}
+	removed := false
+	_, err = r.library.Update(ctx, tenantID, userID, func(lib *library.Library) error {
+		before := len(lib.Searches)
+		lib.Searches = slices.DeleteFunc(lib.Searches, func(s library.SavedSearch) bool { return s.ID == id })
+		removed = len(lib.Searches) < before
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return removed, nil
+}
+
+func convertBookmark(bookmark library.Bookmark) model1.Bookmark {
+	return model1.Bookmark{
+		LawID:     bookmark.LawID,
+		Title:     optionalString(bookmark.Title),
+		Note:      optionalString(bookmark.Note),
+		CreatedAt: bookmark.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func convertSavedSearch(search library.SavedSearch) model1.SavedSearch {
+	result := model1.SavedSearch{
+		ID:           search.ID,
+		Name:         search.Name,
+		Keyword:      optionalString(search.Keyword),
+		LawTitle:     optionalString(search.LawTitle),
+		LawNum:       optionalString(search.LawNum),
+		LawType:      []model1.LawType{},
+		CategoryCode: []model1.CategoryCode{},
+		Sort:         model1.LawSort(search.Sort),
+		Order:        model1.SortOrder(search.Order),
+		CreatedAt:    search.CreatedAt.Format(time.RFC3339),
+	}
+	for _, lawType := range search.LawTypes {
+		if t := model1.LawType(lawType); t.IsValid() {
+			result.LawType = append(result.LawType, t)
+		}
+	}
+	for _, category := range search.Categories {
+		if code := model1.CategoryCode(category); code.IsValid() {
+			result.CategoryCode = append(result.CategoryCode, code)
+		}
+	}
+	if !result.Sort.IsValid() {
+		result.Sort = model1.LawSortRelevance
+	}
+	if !result.Order.IsValid() {
+		result.Order = model1.SortOrderAsc
+	}
+	return result
+}
@@ -0,0 +1,223 @@
+package graphql
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/auth"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/library"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// maxHistory bounds the EPUB history kept in a library.
+const maxHistory = 50
+
+// me returns the profile and usage of the caller, or nil when the request
+// names no user.
+func (r *Resolver) me(ctx context.Context) (*model1.Me, error) {
+	userID := tenant.UserIDFromContext(ctx)
+	if userID == "" {
+		return nil, nil
+	}
+	lib, err := r.library.Get(ctx, tenant.IDFromContext(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model1.Me{
+		ID:               userID,
+		Tenant:           optionalString(tenant.IDFromContext(ctx)),
+		Quota:            getQuota(ctx),
+		BookmarkCount:    len(lib.Bookmarks),
+		SavedSearchCount: len(lib.Searches),
+		History:          make([]model1.Generation, len(lib.History)),
+	}
+	if user := auth.UserFromContext(ctx); user != nil {
+		result.Issuer = optionalString(user.Issuer)
+		result.Subject = optionalString(user.Subject)
+		result.Email = optionalString(user.Email)
+		result.EmailVerified = user.EmailVerified
+		result.Name = optionalString(user.Name)
+		result.Picture = optionalString(user.Picture)
+	}
+	for i, generation := range lib.History {
+		result.History[i] = convertGeneration(generation)
+	}
+	return result, nil
+}
+
+// recordGeneration adds an EPUB request to the caller's history, moving a
+// repeated request to the top. Failures are logged and do not fail the
+// request.
+func (r *Resolver) recordGeneration(ctx context.Context, id string, articles []string, conv conversion) {
+	userID := tenant.UserIDFromContext(ctx)
+	if userID == "" {
+		return
+	}
+	generation := library.Generation{
+		ID:          id,
+		Articles:    articles,
+		DiffAgainst: conv.DiffAgainst,
+		Preset:      conv.Preset,
+		Vertical:    conv.Vertical,
+		Font:        conv.Font,
+		StripFonts:  conv.StripFonts,
+		RequestedAt: r.clock.Now().UTC(),
+	}
+	if conv.Cover != nil {
+		generation.Cover = string(*conv.Cover)
+	}
+	_, err := r.library.Update(ctx, tenant.IDFromContext(ctx), userID, func(lib *library.Library) error {
+		lib.History = slices.DeleteFunc(lib.History, generation.Same)
+		lib.History = append([]library.Generation{generation}, lib.History...)
+		if len(lib.History) > maxHistory {
+			lib.History = lib.History[:maxHistory]
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Failed to record EPUB history of user %s: %v", userID, err)
+	}
+}
+
+// myEpubs returns a page of the caller's EPUB history with the current
+// state of each document. The cursor is the request time of the last item,
+// so pages stay in place when new requests are added to the top.
+func (r *Resolver) myEpubs(ctx context.Context, first *int, after *string) (*model1.EpubHistoryPage, error) {
+	limit := 20
+	if first != nil {
+		limit = *first
+	}
+	if limit < 1 || limit > maxHistory {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "first must be between 1 and %d", maxHistory)
+	}
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("EPUB generation")
+	}
+	lib, err := r.myLibrary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	history := lib.History
+	if after != nil {
+		cursor, err := parseHistoryCursor(*after)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(history, func(g library.Generation) bool { return g.RequestedAt.Before(cursor) })
+		if i < 0 {
+			i = len(history)
+		}
+		history = history[i:]
+	}
+
+	page := &model1.EpubHistoryPage{
+		Items:       []model1.EpubHistoryItem{},
+		HasNextPage: len(history) > limit,
+	}
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	for _, generation := range history {
+		epub, err := r.historyEpub(ctx, generation)
+		if err != nil {
+			return nil, err
+		}
+		request := convertGeneration(generation)
+		page.Items = append(page.Items, model1.EpubHistoryItem{Request: &request, Epub: epub})
+	}
+	if len(history) > 0 {
+		cursor := historyCursor(history[len(history)-1].RequestedAt)
+		page.EndCursor = &cursor
+	}
+	return page, nil
+}
+
+// historyEpub reports the current state of a document in the history
+// without starting generation, or nil when it no longer exists.
+func (r *Resolver) historyEpub(ctx context.Context, generation library.Generation) (*model1.Epub, error) {
+	conv := generationConversion(generation)
+	if !conv.inProcess() {
+		epub, err := r.GetEpubStatus(ctx, generation.ID, generation.Articles)
+		if errors.Is(err, jobs.ErrNotFound) {
+			return nil, nil
+		}
+		return epub, err
+	}
+
+	// Redline, preset, and cover EPUBs have no job record; they are kept
+	// under the name resolveConvertedEpub gives them.
+	name := conv.name(generation.ID)
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := bucket.Attrs(ctx, fmt.Sprintf("%s/converted/%s.epub", storagePrefix(ctx), name))
+	if errors.Is(err, objects.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read converted EPUB %s: %v", name, err)
+	}
+	epub, err := r.completedEpub(bucket, attrs, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	epub.ID = generation.ID
+	return epub, nil
+}
+
+// historyCursor encodes the request time of a history item as an opaque
+// cursor.
+func historyCursor(requestedAt time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(requestedAt.Format(time.RFC3339Nano)))
+}
+
+func parseHistoryCursor(cursor string) (time.Time, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, codedErrorf(model1.ErrorCodeBadUserInput, "invalid cursor %q", cursor)
+	}
+	t, err := time.Parse(time.RFC3339Nano, string(decoded))
+	if err != nil {
+		return time.Time{}, codedErrorf(model1.ErrorCodeBadUserInput, "invalid cursor %q", cursor)
+	}
+	return t, nil
+}
+
+func convertGeneration(generation library.Generation) model1.Generation {
+	articles := generation.Articles
+	if articles == nil {
+		articles = []string{}
+	}
+	return model1.Generation{
+		ID:          generation.ID,
+		Articles:    articles,
+		DiffAgainst: optionalString(generation.DiffAgainst),
+		Preset:      optionalString(generation.Preset),
+		Vertical:    generation.Vertical,
+		Cover:       generationConversion(generation).Cover,
+		Font:        optionalString(generation.Font),
+		StripFonts:  generation.StripFonts,
+		RequestedAt: generation.RequestedAt.Format(time.RFC3339),
+	}
+}
+
+// generationConversion returns the in-process conversion options of a
+// request in the history.
+func generationConversion(generation library.Generation) conversion {
+	conv := conversion{DiffAgainst: generation.DiffAgainst, Preset: generation.Preset, Vertical: generation.Vertical, Font: generation.Font, StripFonts: generation.StripFonts}
+	if cover := model1.CoverStyle(generation.Cover); cover.IsValid() {
+		conv.Cover = &cover
+	}
+	return conv
+}
@@ -7,19 +7,599 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
 )
 
+type TimelineEvent interface {
+	IsTimelineEvent()
+	GetKind() TimelineEventKind
+	GetDate() time.Time
+	GetRevisionID() *string
+}
+
+type AmendmentEvent struct {
+	Kind                    TimelineEventKind `json:"kind"`
+	Date                    time.Time         `json:"date"`
+	RevisionID              *string           `json:"revisionId,omitempty"`
+	AmendmentLawID          string            `json:"amendmentLawId"`
+	AmendmentLawTitle       string            `json:"amendmentLawTitle"`
+	AmendmentLawNum         string            `json:"amendmentLawNum"`
+	AmendmentPromulgateDate *time.Time        `json:"amendmentPromulgateDate,omitempty"`
+	Scheduled               bool              `json:"scheduled"`
+}
+
+func (AmendmentEvent) IsTimelineEvent()                {}
+func (this AmendmentEvent) GetKind() TimelineEventKind { return this.Kind }
+func (this AmendmentEvent) GetDate() time.Time         { return this.Date }
+func (this AmendmentEvent) GetRevisionID() *string     { return this.RevisionID }
+
+type ArticleChange struct {
+	Provision     string            `json:"provision"`
+	Num           string            `json:"num"`
+	Title         string            `json:"title"`
+	Change        ChangeType        `json:"change"`
+	CaptionBefore *string           `json:"captionBefore,omitempty"`
+	CaptionAfter  *string           `json:"captionAfter,omitempty"`
+	Paragraphs    []ParagraphChange `json:"paragraphs"`
+}
+
+type Bookmark struct {
+	LawID     string  `json:"lawId"`
+	Title     *string `json:"title,omitempty"`
+	Note      *string `json:"note,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type BulkExport struct {
+	ID                string              `json:"id"`
+	Format            Format              `json:"format"`
+	Status            EpubStatus          `json:"status"`
+	StatusDisplayName string              `json:"statusDisplayName"`
+	Total             int                 `json:"total"`
+	Completed         int                 `json:"completed"`
+	Failures          []BulkExportFailure `json:"failures"`
+	SignedURL         *string             `json:"signedUrl,omitempty"`
+	DownloadURL       *string             `json:"downloadUrl,omitempty"`
+	Size              *int                `json:"size,omitempty"`
+	Sha256            *string             `json:"sha256,omitempty"`
+	Error             *string             `json:"error,omitempty"`
+	CreatedAt         string              `json:"createdAt"`
+	UpdatedAt         string              `json:"updatedAt"`
+}
+
+type BulkExportFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+type Category struct {
+	Code        CategoryCode `json:"code"`
+	Name        string       `json:"name"`
+	NameEn      string       `json:"nameEn"`
+	Description string       `json:"description"`
+	LawCount    int          `json:"lawCount"`
+}
+
+type CategoryFacet struct {
+	Code        *CategoryCode `json:"code,omitempty"`
+	Name        string        `json:"name"`
+	DisplayName string        `json:"displayName"`
+	Count       int           `json:"count"`
+}
+
+type Compilation struct {
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	RevisionIds []string    `json:"revisionIds"`
+	Book        *EpubBundle `json:"book,omitempty"`
+	CreatedAt   string      `json:"createdAt"`
+	UpdatedAt   string      `json:"updatedAt"`
+}
+
+type CompilationInput struct {
+	ID          *string  `json:"id,omitempty"`
+	Title       string   `json:"title"`
+	RevisionIds []string `json:"revisionIds"`
+}
+
+type ConvertResult struct {
+	Filename  string  `json:"filename"`
+	LawTitle  string  `json:"lawTitle"`
+	Size      int     `json:"size"`
+	SignedURL *string `json:"signedUrl,omitempty"`
+	Base64    *string `json:"base64,omitempty"`
+}
+
+type CorsConfig struct {
+	Origins []string    `json:"origins"`
+	Routes  []CorsRoute `json:"routes"`
+}
+
+type CorsRoute struct {
+	Path          string   `json:"path"`
+	Methods       []string `json:"methods"`
+	Headers       []string `json:"headers"`
+	ExposeHeaders []string `json:"exposeHeaders"`
+	MaxAge        int      `json:"maxAge"`
+}
+
+type CoverLogo struct {
+	Tenant    *string `json:"tenant,omitempty"`
+	MediaType string  `json:"mediaType"`
+	Size      int     `json:"size"`
+	Sha256    string  `json:"sha256"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+type DailyUsage struct {
+	Date      string `json:"date"`
+	Requested int    `json:"requested"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+}
+
+type DisplayName struct {
+	Enum        string `json:"enum"`
+	Value       string `json:"value"`
+	DisplayName string `json:"displayName"`
+}
+
+type EnforcementEvent struct {
+	Kind       TimelineEventKind `json:"kind"`
+	Date       time.Time         `json:"date"`
+	RevisionID *string           `json:"revisionId,omitempty"`
+	Scheduled  bool              `json:"scheduled"`
+}
+
+func (EnforcementEvent) IsTimelineEvent()                {}
+func (this EnforcementEvent) GetKind() TimelineEventKind { return this.Kind }
+func (this EnforcementEvent) GetDate() time.Time         { return this.Date }
+func (this EnforcementEvent) GetRevisionID() *string     { return this.RevisionID }
+
 type Epub struct {
-	ID        string     `json:"id"`
-	SignedURL *string    `json:"signedUrl,omitempty"`
-	Size      *int       `json:"size,omitempty"`
-	Status    EpubStatus `json:"status"`
-	Error     *string    `json:"error,omitempty"`
+	ID                string     `json:"id"`
+	Articles          []string   `json:"articles,omitempty"`
+	SignedURL         *string    `json:"signedUrl,omitempty"`
+	DownloadURL       *string    `json:"downloadUrl,omitempty"`
+	Size              *int       `json:"size,omitempty"`
+	Etag              *string    `json:"etag,omitempty"`
+	Sha256            *string    `json:"sha256,omitempty"`
+	Status            EpubStatus `json:"status"`
+	StatusDisplayName string     `json:"statusDisplayName"`
+	Stage             *EpubStage `json:"stage,omitempty"`
+	Error             *string    `json:"error,omitempty"`
+	ErrorCode         *ErrorCode `json:"errorCode,omitempty"`
+	ValidationErrors  []string   `json:"validationErrors,omitempty"`
+	Attempts          *int       `json:"attempts,omitempty"`
+	NextRetryAt       *string    `json:"nextRetryAt,omitempty"`
+	EstimatedSeconds  *float64   `json:"estimatedSeconds,omitempty"`
+	ConverterVersion  string     `json:"converterVersion"`
+}
+
+func (Epub) IsEntity() {}
+
+type EpubBundle struct {
+	ID                string              `json:"id"`
+	RootLawID         string              `json:"rootLawId"`
+	Title             *string             `json:"title,omitempty"`
+	Status            EpubStatus          `json:"status"`
+	StatusDisplayName string              `json:"statusDisplayName"`
+	LawIds            []string            `json:"lawIds"`
+	Total             int                 `json:"total"`
+	Completed         int                 `json:"completed"`
+	Failures          []BulkExportFailure `json:"failures"`
+	SignedURL         *string             `json:"signedUrl,omitempty"`
+	DownloadURL       *string             `json:"downloadUrl,omitempty"`
+	Size              *int                `json:"size,omitempty"`
+	Sha256            *string             `json:"sha256,omitempty"`
+	Error             *string             `json:"error,omitempty"`
+	CreatedAt         string              `json:"createdAt"`
+	UpdatedAt         string              `json:"updatedAt"`
+}
+
+type EpubHistoryItem struct {
+	Request *Generation `json:"request"`
+	Epub    *Epub       `json:"epub,omitempty"`
+}
+
+type EpubHistoryPage struct {
+	Items       []EpubHistoryItem `json:"items"`
+	EndCursor   *string           `json:"endCursor,omitempty"`
+	HasNextPage bool              `json:"hasNextPage"`
+}
+
+type EpubJob struct {
+	ID                string      `json:"id"`
+	RevisionID        string      `json:"revisionId"`
+	Articles          []string    `json:"articles,omitempty"`
+	Status            EpubStatus  `json:"status"`
+	StatusDisplayName string      `json:"statusDisplayName"`
+	CreatedAt         *string     `json:"createdAt,omitempty"`
+	UpdatedAt         string      `json:"updatedAt"`
+	DurationSeconds   *float64    `json:"durationSeconds,omitempty"`
+	Error             *string     `json:"error,omitempty"`
+	Attempts          *int        `json:"attempts,omitempty"`
+	NextRetryAt       *string     `json:"nextRetryAt,omitempty"`
+	DeadLetteredAt    *string     `json:"deadLetteredAt,omitempty"`
+	Priority          JobPriority `json:"priority"`
+	QueuedAt          *string     `json:"queuedAt,omitempty"`
+}
+
+type EraFacet struct {
+	Era         LawNumEra `json:"era"`
+	DisplayName string    `json:"displayName"`
+	Count       int       `json:"count"`
+}
+
+type FieldTiming struct {
+	Path       string  `json:"path"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+type Font struct {
+	Name      string `json:"name"`
+	MediaType string `json:"mediaType"`
+	Size      int    `json:"size"`
+	Sha256    string `json:"sha256"`
+}
+
+type Generation struct {
+	ID          string      `json:"id"`
+	Articles    []string    `json:"articles"`
+	DiffAgainst *string     `json:"diffAgainst,omitempty"`
+	Preset      *string     `json:"preset,omitempty"`
+	Vertical    *bool       `json:"vertical,omitempty"`
+	Cover       *CoverStyle `json:"cover,omitempty"`
+	Font        *string     `json:"font,omitempty"`
+	StripFonts  bool        `json:"stripFonts"`
+	RequestedAt string      `json:"requestedAt"`
+}
+
+type Law struct {
+	ID   string          `json:"id"`
+	Item *lawapi.LawItem `json:"item,omitempty"`
+}
+
+func (Law) IsEntity() {}
+
+type LawFacets struct {
+	TotalCount int             `json:"totalCount"`
+	Counted    int             `json:"counted"`
+	Categories []CategoryFacet `json:"categories"`
+	LawTypes   []LawTypeFacet  `json:"lawTypes"`
+	Eras       []EraFacet      `json:"eras"`
+}
+
+type LawSearchMatch struct {
+	Provision      string             `json:"provision"`
+	Article        string             `json:"article"`
+	ArticleCaption *string            `json:"articleCaption,omitempty"`
+	Paragraph      *string            `json:"paragraph,omitempty"`
+	Item           *string            `json:"item,omitempty"`
+	Anchor         string             `json:"anchor"`
+	Snippet        string             `json:"snippet"`
+	Highlights     []SnippetHighlight `json:"highlights"`
+}
+
+type LawSuggestion struct {
+	LawID     string `json:"lawId"`
+	LawNum    string `json:"lawNum"`
+	Title     string `json:"title"`
+	TitleKana string `json:"titleKana"`
+	Abbrev    string `json:"abbrev"`
+}
+
+type LawTypeFacet struct {
+	LawType     LawType `json:"lawType"`
+	DisplayName string  `json:"displayName"`
+	Count       int     `json:"count"`
+}
+
+type LawUpdate struct {
+	Kind             LawUpdateKind   `json:"kind"`
+	PromulgationDate string          `json:"promulgationDate"`
+	Law              *lawapi.LawItem `json:"law"`
+}
+
+type LawUsage struct {
+	RevisionID string `json:"revisionId"`
+	Requests   int    `json:"requests"`
+}
+
+type Me struct {
+	ID               string       `json:"id"`
+	Issuer           *string      `json:"issuer,omitempty"`
+	Subject          *string      `json:"subject,omitempty"`
+	Email            *string      `json:"email,omitempty"`
+	EmailVerified    bool         `json:"emailVerified"`
+	Name             *string      `json:"name,omitempty"`
+	Picture          *string      `json:"picture,omitempty"`
+	Tenant           *string      `json:"tenant,omitempty"`
+	Quota            *Quota       `json:"quota"`
+	BookmarkCount    int          `json:"bookmarkCount"`
+	SavedSearchCount int          `json:"savedSearchCount"`
+	History          []Generation `json:"history"`
+}
+
+type Mutation struct {
+}
+
+type ParagraphChange struct {
+	Num    string     `json:"num"`
+	Change ChangeType `json:"change"`
+	Before *string    `json:"before,omitempty"`
+	After  *string    `json:"after,omitempty"`
+}
+
+type Preset struct {
+	Name                string      `json:"name"`
+	Tenant              *string     `json:"tenant,omitempty"`
+	Description         *string     `json:"description,omitempty"`
+	BuiltIn             bool        `json:"builtIn"`
+	Vertical            bool        `json:"vertical"`
+	FontFamily          *FontFamily `json:"fontFamily,omitempty"`
+	Font                *string     `json:"font,omitempty"`
+	FontSize            *int        `json:"fontSize,omitempty"`
+	LineHeight          *int        `json:"lineHeight,omitempty"`
+	LetterSpacing       int         `json:"letterSpacing"`
+	HighContrast        bool        `json:"highContrast"`
+	Furigana            bool        `json:"furigana"`
+	Accessible          bool        `json:"accessible"`
+	OmitSupplProvisions bool        `json:"omitSupplProvisions"`
+	Cover               CoverStyle  `json:"cover"`
+	UpdatedAt           string      `json:"updatedAt"`
+}
+
+type PresetInput struct {
+	Name                string      `json:"name"`
+	Description         *string     `json:"description,omitempty"`
+	Vertical            *bool       `json:"vertical,omitempty"`
+	FontFamily          *FontFamily `json:"fontFamily,omitempty"`
+	Font                *string     `json:"font,omitempty"`
+	FontSize            *int        `json:"fontSize,omitempty"`
+	LineHeight          *int        `json:"lineHeight,omitempty"`
+	LetterSpacing       *int        `json:"letterSpacing,omitempty"`
+	HighContrast        *bool       `json:"highContrast,omitempty"`
+	Furigana            *bool       `json:"furigana,omitempty"`
+	Accessible          *bool       `json:"accessible,omitempty"`
+	OmitSupplProvisions *bool       `json:"omitSupplProvisions,omitempty"`
+	Cover               *CoverStyle `json:"cover,omitempty"`
+}
+
+type PromulgationEvent struct {
+	Kind       TimelineEventKind `json:"kind"`
+	Date       time.Time         `json:"date"`
+	RevisionID *string           `json:"revisionId,omitempty"`
+	LawNum     string            `json:"lawNum"`
 }
 
+func (PromulgationEvent) IsTimelineEvent()                {}
+func (this PromulgationEvent) GetKind() TimelineEventKind { return this.Kind }
+func (this PromulgationEvent) GetDate() time.Time         { return this.Date }
+func (this PromulgationEvent) GetRevisionID() *string     { return this.RevisionID }
+
 type Query struct {
 }
 
+type Quota struct {
+	Subject string       `json:"subject"`
+	Daily   *QuotaWindow `json:"daily,omitempty"`
+	Monthly *QuotaWindow `json:"monthly,omitempty"`
+}
+
+type QuotaWindow struct {
+	Limit     int    `json:"limit"`
+	Used      int    `json:"used"`
+	Remaining int    `json:"remaining"`
+	ResetAt   string `json:"resetAt"`
+}
+
+type Reference struct {
+	Provision     string  `json:"provision"`
+	SourceArticle string  `json:"sourceArticle"`
+	Text          string  `json:"text"`
+	LawNum        *string `json:"lawNum,omitempty"`
+	LawID         *string `json:"lawId,omitempty"`
+	Article       *string `json:"article,omitempty"`
+	Paragraph     *string `json:"paragraph,omitempty"`
+	Item          *string `json:"item,omitempty"`
+}
+
+type RelatedLaw struct {
+	Relation   LawRelation   `json:"relation"`
+	Basis      RelationBasis `json:"basis"`
+	LawID      string        `json:"lawId"`
+	LawNum     string        `json:"lawNum"`
+	Title      *string       `json:"title,omitempty"`
+	LawType    *LawType      `json:"lawType,omitempty"`
+	References int           `json:"references"`
+}
+
+type RepealEvent struct {
+	Kind       TimelineEventKind `json:"kind"`
+	Date       time.Time         `json:"date"`
+	RevisionID *string           `json:"revisionId,omitempty"`
+	Status     RepealStatus      `json:"status"`
+}
+
+func (RepealEvent) IsTimelineEvent()                {}
+func (this RepealEvent) GetKind() TimelineEventKind { return this.Kind }
+func (this RepealEvent) GetDate() time.Time         { return this.Date }
+func (this RepealEvent) GetRevisionID() *string     { return this.RevisionID }
+
+type RevisionComparison struct {
+	LawID    string          `json:"lawId"`
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Articles []ArticleChange `json:"articles"`
+}
+
+type SavedSearch struct {
+	ID           string         `json:"id"`
+	Name         string         `json:"name"`
+	Keyword      *string        `json:"keyword,omitempty"`
+	LawTitle     *string        `json:"lawTitle,omitempty"`
+	LawNum       *string        `json:"lawNum,omitempty"`
+	LawType      []LawType      `json:"lawType"`
+	CategoryCode []CategoryCode `json:"categoryCode"`
+	Sort         LawSort        `json:"sort"`
+	Order        SortOrder      `json:"order"`
+	CreatedAt    string         `json:"createdAt"`
+}
+
+type SavedSearchInput struct {
+	ID           *string        `json:"id,omitempty"`
+	Name         string         `json:"name"`
+	Keyword      *string        `json:"keyword,omitempty"`
+	LawTitle     *string        `json:"lawTitle,omitempty"`
+	LawNum       *string        `json:"lawNum,omitempty"`
+	LawType      []LawType      `json:"lawType,omitempty"`
+	CategoryCode []CategoryCode `json:"categoryCode,omitempty"`
+	Sort         *LawSort       `json:"sort,omitempty"`
+	Order        *SortOrder     `json:"order,omitempty"`
+}
+
+type SlowOperation struct {
+	OperationName *string       `json:"operationName,omitempty"`
+	OperationType string        `json:"operationType"`
+	Query         string        `json:"query"`
+	StartedAt     string        `json:"startedAt"`
+	DurationMs    float64       `json:"durationMs"`
+	Fields        []FieldTiming `json:"fields"`
+}
+
+type SnippetHighlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type StatusCount struct {
+	Status int `json:"status"`
+	Count  int `json:"count"`
+}
+
+type SuspensionEvent struct {
+	Kind       TimelineEventKind `json:"kind"`
+	Date       time.Time         `json:"date"`
+	RevisionID *string           `json:"revisionId,omitempty"`
+}
+
+func (SuspensionEvent) IsTimelineEvent()                {}
+func (this SuspensionEvent) GetKind() TimelineEventKind { return this.Kind }
+func (this SuspensionEvent) GetDate() time.Time         { return this.Date }
+func (this SuspensionEvent) GetRevisionID() *string     { return this.RevisionID }
+
+type TableOfContentsEntry struct {
+	Anchor string `json:"anchor"`
+	Label  string `json:"label"`
+	Level  int    `json:"level"`
+}
+
+type UpstreamEndpoint struct {
+	Name             string        `json:"name"`
+	Calls            int           `json:"calls"`
+	Errors           int           `json:"errors"`
+	Throttled        int           `json:"throttled"`
+	AverageLatencyMs float64       `json:"averageLatencyMs"`
+	MaxLatencyMs     float64       `json:"maxLatencyMs"`
+	Statuses         []StatusCount `json:"statuses"`
+}
+
+type UpstreamUsage struct {
+	RateLimit       int                `json:"rateLimit"`
+	WindowSeconds   int                `json:"windowSeconds"`
+	Used            int                `json:"used"`
+	Remaining       *int               `json:"remaining,omitempty"`
+	ResetsAt        *string            `json:"resetsAt,omitempty"`
+	Throttled       int                `json:"throttled"`
+	LastThrottledAt *string            `json:"lastThrottledAt,omitempty"`
+	Endpoints       []UpstreamEndpoint `json:"endpoints"`
+}
+
+type UsageStats struct {
+	From                     string       `json:"from"`
+	To                       string       `json:"to"`
+	Tenant                   *string      `json:"tenant,omitempty"`
+	TotalJobs                int          `json:"totalJobs"`
+	CompletedJobs            int          `json:"completedJobs"`
+	FailedJobs               int          `json:"failedJobs"`
+	FailureRate              float64      `json:"failureRate"`
+	AverageGenerationSeconds *float64     `json:"averageGenerationSeconds,omitempty"`
+	CacheHits                int          `json:"cacheHits"`
+	CacheHitRate             float64      `json:"cacheHitRate"`
+	TopLaws                  []LawUsage   `json:"topLaws"`
+	Daily                    []DailyUsage `json:"daily"`
+}
+
+type XMLValidationError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+type XMLValidationResult struct {
+	Valid  bool                 `json:"valid"`
+	Errors []XMLValidationError `json:"errors"`
+}
+
+type CacheControlScope string
+
+const (
+	CacheControlScopePublic  CacheControlScope = "PUBLIC"
+	CacheControlScopePrivate CacheControlScope = "PRIVATE"
+)
+
+var AllCacheControlScope = []CacheControlScope{
+	CacheControlScopePublic,
+	CacheControlScopePrivate,
+}
+
+func (e CacheControlScope) IsValid() bool {
+	switch e {
+	case CacheControlScopePublic, CacheControlScopePrivate:
+		return true
+	}
+	return false
+}
+
+func (e CacheControlScope) String() string {
+	return string(e)
+}
+
+func (e *CacheControlScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CacheControlScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CacheControlScope", str)
+	}
+	return nil
+}
+
+func (e CacheControlScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CacheControlScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CacheControlScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type CategoryCode string
 
 const (
@@ -171,52 +751,50 @@ func (e CategoryCode) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-type CurrentRevisionStatus string
+type ChangeType string
 
 const (
-	CurrentRevisionStatusCurrentEnforced  CurrentRevisionStatus = "CURRENT_ENFORCED"
-	CurrentRevisionStatusUnenforced       CurrentRevisionStatus = "UNENFORCED"
-	CurrentRevisionStatusPreviousEnforced CurrentRevisionStatus = "PREVIOUS_ENFORCED"
-	CurrentRevisionStatusRepeal           CurrentRevisionStatus = "REPEAL"
+	ChangeTypeAdded    ChangeType = "ADDED"
+	ChangeTypeRemoved  ChangeType = "REMOVED"
+	ChangeTypeModified ChangeType = "MODIFIED"
 )
 
-var AllCurrentRevisionStatus = []CurrentRevisionStatus{
-	CurrentRevisionStatusCurrentEnforced,
-	CurrentRevisionStatusUnenforced,
-	CurrentRevisionStatusPreviousEnforced,
-	CurrentRevisionStatusRepeal,
+var AllChangeType = []ChangeType{
+	ChangeTypeAdded,
+	ChangeTypeRemoved,
+	ChangeTypeModified,
 }
 
-func (e CurrentRevisionStatus) IsValid() bool {
+func (e ChangeType) IsValid() bool {
 	switch e {
-	case CurrentRevisionStatusCurrentEnforced, CurrentRevisionStatusUnenforced, CurrentRevisionStatusPreviousEnforced, CurrentRevisionStatusRepeal:
+	case ChangeTypeAdded, ChangeTypeRemoved, ChangeTypeModified:
 		return true
 	}
 	return false
 }
 
-func (e CurrentRevisionStatus) String() string {
+func (e ChangeType) String() string {
 	return string(e)
 }
 
-func (e *CurrentRevisionStatus) UnmarshalGQL(v any) error {
+func (e *ChangeType) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = CurrentRevisionStatus(str)
+	*e = ChangeType(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid CurrentRevisionStatus", str)
+		return fmt.Errorf("%s is not a valid ChangeType", str)
 	}
 	return nil
 }
 
-func (e CurrentRevisionStatus) MarshalGQL(w io.Writer) {
+func (e ChangeType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *CurrentRevisionStatus) UnmarshalJSON(b []byte) error {
+func (e *ChangeType) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -224,58 +802,56 @@ func (e *CurrentRevisionStatus) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e CurrentRevisionStatus) MarshalJSON() ([]byte, error) {
+func (e ChangeType) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type EpubStatus string
+type CitationStyle string
 
 const (
-	EpubStatusPending    EpubStatus = "PENDING"
-	EpubStatusProcessing EpubStatus = "PROCESSING"
-	EpubStatusCompleted  EpubStatus = "COMPLETED"
-	EpubStatusFailed     EpubStatus = "FAILED"
+	CitationStyleJapanese CitationStyle = "JAPANESE"
+	CitationStyleBluebook CitationStyle = "BLUEBOOK"
+	CitationStyleBibtex   CitationStyle = "BIBTEX"
 )
 
-var AllEpubStatus = []EpubStatus{
-	EpubStatusPending,
-	EpubStatusProcessing,
-	EpubStatusCompleted,
-	EpubStatusFailed,
+var AllCitationStyle = []CitationStyle{
+	CitationStyleJapanese,
+	CitationStyleBluebook,
+	CitationStyleBibtex,
 }
 
-func (e EpubStatus) IsValid() bool {
+func (e CitationStyle) IsValid() bool {
 	switch e {
-	case EpubStatusPending, EpubStatusProcessing, EpubStatusCompleted, EpubStatusFailed:
+	case CitationStyleJapanese, CitationStyleBluebook, CitationStyleBibtex:
 		return true
 	}
 	return false
 }
 
-func (e EpubStatus) String() string {
+func (e CitationStyle) String() string {
 	return string(e)
 }
 
-func (e *EpubStatus) UnmarshalGQL(v any) error {
+func (e *CitationStyle) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = EpubStatus(str)
+	*e = CitationStyle(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid EpubStatus", str)
+		return fmt.Errorf("%s is not a valid CitationStyle", str)
 	}
 	return nil
 }
 
-func (e EpubStatus) MarshalGQL(w io.Writer) {
+func (e CitationStyle) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *EpubStatus) UnmarshalJSON(b []byte) error {
+func (e *CitationStyle) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -283,60 +859,54 @@ func (e *EpubStatus) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e EpubStatus) MarshalJSON() ([]byte, error) {
+func (e CitationStyle) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type LawNumEra string
+type ConvertOutput string
 
 const (
-	LawNumEraMeiji  LawNumEra = "MEIJI"
-	LawNumEraTaisho LawNumEra = "TAISHO"
-	LawNumEraShowa  LawNumEra = "SHOWA"
-	LawNumEraHeisei LawNumEra = "HEISEI"
-	LawNumEraReiwa  LawNumEra = "REIWA"
+	ConvertOutputURL    ConvertOutput = "URL"
+	ConvertOutputBase64 ConvertOutput = "BASE64"
 )
 
-var AllLawNumEra = []LawNumEra{
-	LawNumEraMeiji,
-	LawNumEraTaisho,
-	LawNumEraShowa,
-	LawNumEraHeisei,
-	LawNumEraReiwa,
+var AllConvertOutput = []ConvertOutput{
+	ConvertOutputURL,
+	ConvertOutputBase64,
 }
 
-func (e LawNumEra) IsValid() bool {
+func (e ConvertOutput) IsValid() bool {
 	switch e {
-	case LawNumEraMeiji, LawNumEraTaisho, LawNumEraShowa, LawNumEraHeisei, LawNumEraReiwa:
+	case ConvertOutputURL, ConvertOutputBase64:
 		return true
 	}
 	return false
 }
 
-func (e LawNumEra) String() string {
+func (e ConvertOutput) String() string {
 	return string(e)
 }
 
-func (e *LawNumEra) UnmarshalGQL(v any) error {
+func (e *ConvertOutput) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = LawNumEra(str)
+	*e = ConvertOutput(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid LawNumEra", str)
+		return fmt.Errorf("%s is not a valid ConvertOutput", str)
 	}
 	return nil
 }
 
-func (e LawNumEra) MarshalGQL(w io.Writer) {
+func (e ConvertOutput) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *LawNumEra) UnmarshalJSON(b []byte) error {
+func (e *ConvertOutput) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -344,64 +914,56 @@ func (e *LawNumEra) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e LawNumEra) MarshalJSON() ([]byte, error) {
+func (e ConvertOutput) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type LawNumType string
+type CoverStyle string
 
 const (
-	LawNumTypeConstitution         LawNumType = "CONSTITUTION"
-	LawNumTypeAct                  LawNumType = "ACT"
-	LawNumTypeCabinetOrder         LawNumType = "CABINET_ORDER"
-	LawNumTypeImperialOrder        LawNumType = "IMPERIAL_ORDER"
-	LawNumTypeMinisterialOrdinance LawNumType = "MINISTERIAL_ORDINANCE"
-	LawNumTypeRule                 LawNumType = "RULE"
-	LawNumTypeMisc                 LawNumType = "MISC"
+	CoverStyleNone  CoverStyle = "NONE"
+	CoverStyleTitle CoverStyle = "TITLE"
+	CoverStyleLogo  CoverStyle = "LOGO"
 )
 
-var AllLawNumType = []LawNumType{
-	LawNumTypeConstitution,
-	LawNumTypeAct,
-	LawNumTypeCabinetOrder,
-	LawNumTypeImperialOrder,
-	LawNumTypeMinisterialOrdinance,
-	LawNumTypeRule,
-	LawNumTypeMisc,
+var AllCoverStyle = []CoverStyle{
+	CoverStyleNone,
+	CoverStyleTitle,
+	CoverStyleLogo,
 }
 
-func (e LawNumType) IsValid() bool {
+func (e CoverStyle) IsValid() bool {
 	switch e {
-	case LawNumTypeConstitution, LawNumTypeAct, LawNumTypeCabinetOrder, LawNumTypeImperialOrder, LawNumTypeMinisterialOrdinance, LawNumTypeRule, LawNumTypeMisc:
+	case CoverStyleNone, CoverStyleTitle, CoverStyleLogo:
 		return true
 	}
 	return false
 }
 
-func (e LawNumType) String() string {
+func (e CoverStyle) String() string {
 	return string(e)
 }
 
-func (e *LawNumType) UnmarshalGQL(v any) error {
+func (e *CoverStyle) UnmarshalGQL(v any) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
 	}
 
-	*e = LawNumType(str)
+	*e = CoverStyle(str)
 	if !e.IsValid() {
-		return fmt.Errorf("%s is not a valid LawNumType", str)
+		return fmt.Errorf("%s is not a valid CoverStyle", str)
 	}
 	return nil
 }
 
-func (e LawNumType) MarshalGQL(w io.Writer) {
+func (e CoverStyle) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *LawNumType) UnmarshalJSON(b []byte) error {
+func (e *CoverStyle) UnmarshalJSON(b []byte) error {
 	s, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
@@ -409,25 +971,688 @@ func (e *LawNumType) UnmarshalJSON(b []byte) error {
 	return e.UnmarshalGQL(s)
 }
 
-func (e LawNumType) MarshalJSON() ([]byte, error) {
+func (e CoverStyle) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
 
-type LawType string
+type CurrentRevisionStatus string
 
 const (
-	LawTypeConstitution         LawType = "CONSTITUTION"
-	LawTypeAct                  LawType = "ACT"
-	LawTypeCabinetOrder         LawType = "CABINET_ORDER"
-	LawTypeImperialOrder        LawType = "IMPERIAL_ORDER"
-	LawTypeMinisterialOrdinance LawType = "MINISTERIAL_ORDINANCE"
-	LawTypeRule                 LawType = "RULE"
-	LawTypeMisc                 LawType = "MISC"
+	CurrentRevisionStatusCurrentEnforced  CurrentRevisionStatus = "CURRENT_ENFORCED"
+	CurrentRevisionStatusUnenforced       CurrentRevisionStatus = "UNENFORCED"
+	CurrentRevisionStatusPreviousEnforced CurrentRevisionStatus = "PREVIOUS_ENFORCED"
+	CurrentRevisionStatusRepeal           CurrentRevisionStatus = "REPEAL"
 )
 
-var AllLawType = []LawType{
+var AllCurrentRevisionStatus = []CurrentRevisionStatus{
+	CurrentRevisionStatusCurrentEnforced,
+	CurrentRevisionStatusUnenforced,
+	CurrentRevisionStatusPreviousEnforced,
+	CurrentRevisionStatusRepeal,
+}
+
+func (e CurrentRevisionStatus) IsValid() bool {
+	switch e {
+	case CurrentRevisionStatusCurrentEnforced, CurrentRevisionStatusUnenforced, CurrentRevisionStatusPreviousEnforced, CurrentRevisionStatusRepeal:
+		return true
+	}
+	return false
+}
+
+func (e CurrentRevisionStatus) String() string {
+	return string(e)
+}
+
+func (e *CurrentRevisionStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CurrentRevisionStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CurrentRevisionStatus", str)
+	}
+	return nil
+}
+
+func (e CurrentRevisionStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *CurrentRevisionStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e CurrentRevisionStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type EpubStage string
+
+const (
+	EpubStageParse   EpubStage = "PARSE"
+	EpubStageConvert EpubStage = "CONVERT"
+	EpubStageWrite   EpubStage = "WRITE"
+)
+
+var AllEpubStage = []EpubStage{
+	EpubStageParse,
+	EpubStageConvert,
+	EpubStageWrite,
+}
+
+func (e EpubStage) IsValid() bool {
+	switch e {
+	case EpubStageParse, EpubStageConvert, EpubStageWrite:
+		return true
+	}
+	return false
+}
+
+func (e EpubStage) String() string {
+	return string(e)
+}
+
+func (e *EpubStage) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EpubStage(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EpubStage", str)
+	}
+	return nil
+}
+
+func (e EpubStage) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *EpubStage) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e EpubStage) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type EpubStatus string
+
+const (
+	EpubStatusPending    EpubStatus = "PENDING"
+	EpubStatusProcessing EpubStatus = "PROCESSING"
+	EpubStatusCompleted  EpubStatus = "COMPLETED"
+	EpubStatusFailed     EpubStatus = "FAILED"
+)
+
+var AllEpubStatus = []EpubStatus{
+	EpubStatusPending,
+	EpubStatusProcessing,
+	EpubStatusCompleted,
+	EpubStatusFailed,
+}
+
+func (e EpubStatus) IsValid() bool {
+	switch e {
+	case EpubStatusPending, EpubStatusProcessing, EpubStatusCompleted, EpubStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e EpubStatus) String() string {
+	return string(e)
+}
+
+func (e *EpubStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = EpubStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid EpubStatus", str)
+	}
+	return nil
+}
+
+func (e EpubStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *EpubStatus) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e EpubStatus) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type ErrorCode string
+
+const (
+	ErrorCodeLawNotFound         ErrorCode = "LAW_NOT_FOUND"
+	ErrorCodeInvalidLawID        ErrorCode = "INVALID_LAW_ID"
+	ErrorCodeNotFound            ErrorCode = "NOT_FOUND"
+	ErrorCodeBadUserInput        ErrorCode = "BAD_USER_INPUT"
+	ErrorCodeForbidden           ErrorCode = "FORBIDDEN"
+	ErrorCodeUnauthenticated     ErrorCode = "UNAUTHENTICATED"
+	ErrorCodeQuotaExceeded       ErrorCode = "QUOTA_EXCEEDED"
+	ErrorCodeUpstreamTimeout     ErrorCode = "UPSTREAM_TIMEOUT"
+	ErrorCodeUpstreamError       ErrorCode = "UPSTREAM_ERROR"
+	ErrorCodeServerBusy          ErrorCode = "SERVER_BUSY"
+	ErrorCodeConversionFailed    ErrorCode = "CONVERSION_FAILED"
+	ErrorCodeNotConfigured       ErrorCode = "NOT_CONFIGURED"
+	ErrorCodeOperationNotAllowed ErrorCode = "OPERATION_NOT_ALLOWED"
+	ErrorCodeInternalServerError ErrorCode = "INTERNAL_SERVER_ERROR"
+)
+
+var AllErrorCode = []ErrorCode{
+	ErrorCodeLawNotFound,
+	ErrorCodeInvalidLawID,
+	ErrorCodeNotFound,
+	ErrorCodeBadUserInput,
+	ErrorCodeForbidden,
+	ErrorCodeUnauthenticated,
+	ErrorCodeQuotaExceeded,
+	ErrorCodeUpstreamTimeout,
+	ErrorCodeUpstreamError,
+	ErrorCodeServerBusy,
+	ErrorCodeConversionFailed,
+	ErrorCodeNotConfigured,
+	ErrorCodeOperationNotAllowed,
+	ErrorCodeInternalServerError,
+}
+
+func (e ErrorCode) IsValid() bool {
+	switch e {
+	case ErrorCodeLawNotFound, ErrorCodeInvalidLawID, ErrorCodeNotFound, ErrorCodeBadUserInput, ErrorCodeForbidden, ErrorCodeUnauthenticated, ErrorCodeQuotaExceeded, ErrorCodeUpstreamTimeout, ErrorCodeUpstreamError, ErrorCodeServerBusy, ErrorCodeConversionFailed, ErrorCodeNotConfigured, ErrorCodeOperationNotAllowed, ErrorCodeInternalServerError:
+		return true
+	}
+	return false
+}
+
+func (e ErrorCode) String() string {
+	return string(e)
+}
+
+func (e *ErrorCode) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ErrorCode(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ErrorCode", str)
+	}
+	return nil
+}
+
+func (e ErrorCode) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *ErrorCode) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e ErrorCode) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type FontFamily string
+
+const (
+	FontFamilySerif     FontFamily = "SERIF"
+	FontFamilySansSerif FontFamily = "SANS_SERIF"
+)
+
+var AllFontFamily = []FontFamily{
+	FontFamilySerif,
+	FontFamilySansSerif,
+}
+
+func (e FontFamily) IsValid() bool {
+	switch e {
+	case FontFamilySerif, FontFamilySansSerif:
+		return true
+	}
+	return false
+}
+
+func (e FontFamily) String() string {
+	return string(e)
+}
+
+func (e *FontFamily) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = FontFamily(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid FontFamily", str)
+	}
+	return nil
+}
+
+func (e FontFamily) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *FontFamily) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e FontFamily) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Format string
+
+const (
+	FormatEpub Format = "EPUB"
+	FormatHTML Format = "HTML"
+	FormatXML  Format = "XML"
+)
+
+var AllFormat = []Format{
+	FormatEpub,
+	FormatHTML,
+	FormatXML,
+}
+
+func (e Format) IsValid() bool {
+	switch e {
+	case FormatEpub, FormatHTML, FormatXML:
+		return true
+	}
+	return false
+}
+
+func (e Format) String() string {
+	return string(e)
+}
+
+func (e *Format) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Format(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Format", str)
+	}
+	return nil
+}
+
+func (e Format) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Format) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Format) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type JobPriority string
+
+const (
+	JobPriorityHigh   JobPriority = "HIGH"
+	JobPriorityNormal JobPriority = "NORMAL"
+	JobPriorityLow    JobPriority = "LOW"
+)
+
+var AllJobPriority = []JobPriority{
+	JobPriorityHigh,
+	JobPriorityNormal,
+	JobPriorityLow,
+}
+
+func (e JobPriority) IsValid() bool {
+	switch e {
+	case JobPriorityHigh, JobPriorityNormal, JobPriorityLow:
+		return true
+	}
+	return false
+}
+
+func (e JobPriority) String() string {
+	return string(e)
+}
+
+func (e *JobPriority) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = JobPriority(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid JobPriority", str)
+	}
+	return nil
+}
+
+func (e JobPriority) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *JobPriority) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e JobPriority) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LawNumEra string
+
+const (
+	LawNumEraMeiji  LawNumEra = "MEIJI"
+	LawNumEraTaisho LawNumEra = "TAISHO"
+	LawNumEraShowa  LawNumEra = "SHOWA"
+	LawNumEraHeisei LawNumEra = "HEISEI"
+	LawNumEraReiwa  LawNumEra = "REIWA"
+)
+
+var AllLawNumEra = []LawNumEra{
+	LawNumEraMeiji,
+	LawNumEraTaisho,
+	LawNumEraShowa,
+	LawNumEraHeisei,
+	LawNumEraReiwa,
+}
+
+func (e LawNumEra) IsValid() bool {
+	switch e {
+	case LawNumEraMeiji, LawNumEraTaisho, LawNumEraShowa, LawNumEraHeisei, LawNumEraReiwa:
+		return true
+	}
+	return false
+}
+
+func (e LawNumEra) String() string {
+	return string(e)
+}
+
+func (e *LawNumEra) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LawNumEra(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LawNumEra", str)
+	}
+	return nil
+}
+
+func (e LawNumEra) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LawNumEra) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LawNumEra) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LawNumType string
+
+const (
+	LawNumTypeConstitution         LawNumType = "CONSTITUTION"
+	LawNumTypeAct                  LawNumType = "ACT"
+	LawNumTypeCabinetOrder         LawNumType = "CABINET_ORDER"
+	LawNumTypeImperialOrder        LawNumType = "IMPERIAL_ORDER"
+	LawNumTypeMinisterialOrdinance LawNumType = "MINISTERIAL_ORDINANCE"
+	LawNumTypeRule                 LawNumType = "RULE"
+	LawNumTypeMisc                 LawNumType = "MISC"
+)
+
+var AllLawNumType = []LawNumType{
+	LawNumTypeConstitution,
+	LawNumTypeAct,
+	LawNumTypeCabinetOrder,
+	LawNumTypeImperialOrder,
+	LawNumTypeMinisterialOrdinance,
+	LawNumTypeRule,
+	LawNumTypeMisc,
+}
+
+func (e LawNumType) IsValid() bool {
+	switch e {
+	case LawNumTypeConstitution, LawNumTypeAct, LawNumTypeCabinetOrder, LawNumTypeImperialOrder, LawNumTypeMinisterialOrdinance, LawNumTypeRule, LawNumTypeMisc:
+		return true
+	}
+	return false
+}
+
+func (e LawNumType) String() string {
+	return string(e)
+}
+
+func (e *LawNumType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LawNumType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LawNumType", str)
+	}
+	return nil
+}
+
+func (e LawNumType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LawNumType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LawNumType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LawRelation string
+
+const (
+	LawRelationParent      LawRelation = "PARENT"
+	LawRelationSubordinate LawRelation = "SUBORDINATE"
+)
+
+var AllLawRelation = []LawRelation{
+	LawRelationParent,
+	LawRelationSubordinate,
+}
+
+func (e LawRelation) IsValid() bool {
+	switch e {
+	case LawRelationParent, LawRelationSubordinate:
+		return true
+	}
+	return false
+}
+
+func (e LawRelation) String() string {
+	return string(e)
+}
+
+func (e *LawRelation) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LawRelation(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LawRelation", str)
+	}
+	return nil
+}
+
+func (e LawRelation) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LawRelation) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LawRelation) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LawSort string
+
+const (
+	LawSortRelevance        LawSort = "RELEVANCE"
+	LawSortPromulgationDate LawSort = "PROMULGATION_DATE"
+	LawSortEnforcementDate  LawSort = "ENFORCEMENT_DATE"
+	LawSortTitleKana        LawSort = "TITLE_KANA"
+)
+
+var AllLawSort = []LawSort{
+	LawSortRelevance,
+	LawSortPromulgationDate,
+	LawSortEnforcementDate,
+	LawSortTitleKana,
+}
+
+func (e LawSort) IsValid() bool {
+	switch e {
+	case LawSortRelevance, LawSortPromulgationDate, LawSortEnforcementDate, LawSortTitleKana:
+		return true
+	}
+	return false
+}
+
+func (e LawSort) String() string {
+	return string(e)
+}
+
+func (e *LawSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LawSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LawSort", str)
+	}
+	return nil
+}
+
+func (e LawSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LawSort) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LawSort) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type LawType string
+
+const (
+	LawTypeConstitution         LawType = "CONSTITUTION"
+	LawTypeAct                  LawType = "ACT"
+	LawTypeCabinetOrder         LawType = "CABINET_ORDER"
+	LawTypeImperialOrder        LawType = "IMPERIAL_ORDER"
+	LawTypeMinisterialOrdinance LawType = "MINISTERIAL_ORDINANCE"
+	LawTypeRule                 LawType = "RULE"
+	LawTypeMisc                 LawType = "MISC"
+)
+
+var AllLawType = []LawType{
 	LawTypeConstitution,
 	LawTypeAct,
 	LawTypeCabinetOrder,
@@ -480,6 +1705,116 @@ func (e LawType) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type LawUpdateKind string
+
+const (
+	LawUpdateKindNewLaw    LawUpdateKind = "NEW_LAW"
+	LawUpdateKindAmendment LawUpdateKind = "AMENDMENT"
+)
+
+var AllLawUpdateKind = []LawUpdateKind{
+	LawUpdateKindNewLaw,
+	LawUpdateKindAmendment,
+}
+
+func (e LawUpdateKind) IsValid() bool {
+	switch e {
+	case LawUpdateKindNewLaw, LawUpdateKindAmendment:
+		return true
+	}
+	return false
+}
+
+func (e LawUpdateKind) String() string {
+	return string(e)
+}
+
+func (e *LawUpdateKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LawUpdateKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LawUpdateKind", str)
+	}
+	return nil
+}
+
+func (e LawUpdateKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LawUpdateKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LawUpdateKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type Locale string
+
+const (
+	LocaleJa Locale = "JA"
+	LocaleEn Locale = "EN"
+)
+
+var AllLocale = []Locale{
+	LocaleJa,
+	LocaleEn,
+}
+
+func (e Locale) IsValid() bool {
+	switch e {
+	case LocaleJa, LocaleEn:
+		return true
+	}
+	return false
+}
+
+func (e Locale) String() string {
+	return string(e)
+}
+
+func (e *Locale) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Locale(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Locale", str)
+	}
+	return nil
+}
+
+func (e Locale) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *Locale) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e Locale) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type Mission string
 
 const (
@@ -535,6 +1870,61 @@ func (e Mission) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type RelationBasis string
+
+const (
+	RelationBasisTitle          RelationBasis = "TITLE"
+	RelationBasisCrossReference RelationBasis = "CROSS_REFERENCE"
+)
+
+var AllRelationBasis = []RelationBasis{
+	RelationBasisTitle,
+	RelationBasisCrossReference,
+}
+
+func (e RelationBasis) IsValid() bool {
+	switch e {
+	case RelationBasisTitle, RelationBasisCrossReference:
+		return true
+	}
+	return false
+}
+
+func (e RelationBasis) String() string {
+	return string(e)
+}
+
+func (e *RelationBasis) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RelationBasis(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RelationBasis", str)
+	}
+	return nil
+}
+
+func (e RelationBasis) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *RelationBasis) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e RelationBasis) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type RepealStatus string
 
 const (
@@ -595,3 +1985,176 @@ func (e RepealStatus) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type SortOrder string
+
+const (
+	SortOrderAsc  SortOrder = "ASC"
+	SortOrderDesc SortOrder = "DESC"
+)
+
+var AllSortOrder = []SortOrder{
+	SortOrderAsc,
+	SortOrderDesc,
+}
+
+func (e SortOrder) IsValid() bool {
+	switch e {
+	case SortOrderAsc, SortOrderDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortOrder) String() string {
+	return string(e)
+}
+
+func (e *SortOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortOrder", str)
+	}
+	return nil
+}
+
+func (e SortOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *SortOrder) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e SortOrder) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type StatsRange string
+
+const (
+	StatsRangeLast24Hours StatsRange = "LAST_24_HOURS"
+	StatsRangeLast7Days   StatsRange = "LAST_7_DAYS"
+	StatsRangeLast30Days  StatsRange = "LAST_30_DAYS"
+)
+
+var AllStatsRange = []StatsRange{
+	StatsRangeLast24Hours,
+	StatsRangeLast7Days,
+	StatsRangeLast30Days,
+}
+
+func (e StatsRange) IsValid() bool {
+	switch e {
+	case StatsRangeLast24Hours, StatsRangeLast7Days, StatsRangeLast30Days:
+		return true
+	}
+	return false
+}
+
+func (e StatsRange) String() string {
+	return string(e)
+}
+
+func (e *StatsRange) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = StatsRange(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid StatsRange", str)
+	}
+	return nil
+}
+
+func (e StatsRange) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *StatsRange) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e StatsRange) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TimelineEventKind string
+
+const (
+	TimelineEventKindPromulgation TimelineEventKind = "PROMULGATION"
+	TimelineEventKindEnforcement  TimelineEventKind = "ENFORCEMENT"
+	TimelineEventKindAmendment    TimelineEventKind = "AMENDMENT"
+	TimelineEventKindSuspension   TimelineEventKind = "SUSPENSION"
+	TimelineEventKindRepeal       TimelineEventKind = "REPEAL"
+)
+
+var AllTimelineEventKind = []TimelineEventKind{
+	TimelineEventKindPromulgation,
+	TimelineEventKindEnforcement,
+	TimelineEventKindAmendment,
+	TimelineEventKindSuspension,
+	TimelineEventKindRepeal,
+}
+
+func (e TimelineEventKind) IsValid() bool {
+	switch e {
+	case TimelineEventKindPromulgation, TimelineEventKindEnforcement, TimelineEventKindAmendment, TimelineEventKindSuspension, TimelineEventKindRepeal:
+		return true
+	}
+	return false
+}
+
+func (e TimelineEventKind) String() string {
+	return string(e)
+}
+
+func (e *TimelineEventKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TimelineEventKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TimelineEventKind", str)
+	}
+	return nil
+}
+
+func (e TimelineEventKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TimelineEventKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TimelineEventKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,334 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/auth"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/mailer"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+	"go.ngs.io/jplaw2epub-web-api/webhook"
+)
+
+const (
+	// maxNotify and maxCallbacks bound the addresses and URLs awaiting one
+	// generation.
+	maxNotify    = 20
+	maxCallbacks = 10
+	// notificationLinkTTL is how long the signed URL in a completion email
+	// or callback stays valid, the longest V4 signed URLs allow.
+	notificationLinkTTL = 7 * 24 * time.Hour
+)
+
+// notificationRecipient returns the address to notify for an epub query:
+// notifyEmail when given, the verified email of the signed-in user when
+// notify is set, or empty for no notification. Since notifyEmail can be
+// any address, it requires an identified client and counts against the
+// client's QUOTA_NOTIFY_DAILY unless the admin token was sent.
+func (r *Resolver) notificationRecipient(ctx context.Context, notify *bool, notifyEmail *string) (string, error) {
+	requested := notifyEmail != nil && *notifyEmail != ""
+	if !requested && (notify == nil || !*notify) {
+		return "", nil
+	}
+	if r.mailer == nil {
+		return "", notConfigured("email notification")
+	}
+	if requested {
+		address, err := mail.ParseAddress(*notifyEmail)
+		if err != nil {
+			return "", codedErrorf(model1.ErrorCodeBadUserInput, "invalid notifyEmail %q", *notifyEmail)
+		}
+		if err := r.limitNotifyEmail(ctx); err != nil {
+			return "", err
+		}
+		return address.Address, nil
+	}
+	user := auth.UserFromContext(ctx)
+	if user == nil || user.Email == "" || !user.EmailVerified {
+		return "", withCode(model1.ErrorCodeBadUserInput, errors.New("notify requires signing in with a verified email address; pass notifyEmail instead"))
+	}
+	return user.Email, nil
+}
+
+// limitNotifyEmail checks that the client of a request may have a
+// completion email sent to an address of its choice.
+func (r *Resolver) limitNotifyEmail(ctx context.Context) error {
+	if handlers.IsAdmin(ctx) {
+		return nil
+	}
+	client := handlers.ClientFromContext(ctx)
+	if !client.Identified() {
+		return withCode(model1.ErrorCodeForbidden, errors.New("notifyEmail requires an API key, a tenant key, or signing in"))
+	}
+	if r.notifyLimiter == nil || !r.notifyLimiter.Enabled() {
+		return nil
+	}
+	// Counted on the wall clock, like request quotas.
+	usages, err := r.notifyLimiter.Consume(ctx, "notify|"+client.Key, time.Now())
+	if err != nil {
+		// Like request quotas, the limit is not enforced without its store.
+		log.Printf("Notification quota check failed for %s: %v", client.Kind, err)
+		return nil
+	}
+	if usage, ok := quota.Tightest(usages); ok && usage.Exceeded() {
+		return codedErrorf(model1.ErrorCodeQuotaExceeded, "daily quota of %d notifyEmail requests exceeded", usage.Limit)
+	}
+	return nil
+}
+
+// callbackTarget validates the callbackUrl of an epub query.
+func (r *Resolver) callbackTarget(callbackURL *string) (string, error) {
+	if callbackURL == nil || *callbackURL == "" {
+		return "", nil
+	}
+	if r.webhooks == nil {
+		return "", notConfigured("callbacks")
+	}
+	if err := webhook.ValidateURL(*callbackURL); err != nil {
+		return "", withCode(model1.ErrorCodeBadUserInput, err)
+	}
+	return *callbackURL, nil
+}
+
+// requestNotification adds an email recipient or a callback URL, either of
+// which may be empty, to the job of an EPUB that is still being generated.
+// Finished EPUBs are answered with their link or error right away, so
+// nothing is sent for them. Failures are logged and do not fail the request.
+func (r *Resolver) requestNotification(ctx context.Context, id string, articles []string, epub *model1.Epub, recipient, callback string) {
+	if epub.Status == model1.EpubStatusCompleted || (epub.Status == model1.EpubStatusFailed && epub.NextRetryAt == nil) {
+		return
+	}
+	parsed, err := parseLawID(id)
+	if err != nil {
+		return
+	}
+	articles, err = normalizeArticles(articles)
+	if err != nil {
+		return
+	}
+	jobID := pinnedJobID(scopedJobID(ctx, excerptID(parsed.Value, articles)), epub.ConverterVersion)
+
+	job, err := r.jobs.Get(ctx, jobID)
+	if err != nil {
+		log.Printf("Failed to request notification for %s: %v", jobID, err)
+		return
+	}
+	changed := false
+	if recipient != "" && !slices.Contains(job.Notify, recipient) && len(job.Notify) < maxNotify {
+		job.Notify = append(slices.Clone(job.Notify), recipient)
+		changed = true
+	}
+	if callback != "" && !slices.Contains(job.Callbacks, callback) && len(job.Callbacks) < maxCallbacks {
+		job.Callbacks = append(slices.Clone(job.Callbacks), callback)
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to request notification for %s: %v", jobID, err)
+	}
+}
+
+// CheckNotifications looks for finished generations awaited by an email or
+// a callback and sends them. It returns the number of jobs notified.
+func (r *Resolver) CheckNotifications(ctx context.Context) (int, error) {
+	if (r.mailer == nil && r.webhooks == nil) || r.generator.bucketName == "" {
+		return 0, nil
+	}
+
+	var waiting []*jobs.Job
+	for _, status := range []jobs.Status{jobs.StatusPending, jobs.StatusProcessing, jobs.StatusFailed} {
+		records, err := r.jobs.List(ctx, jobs.ListOptions{Status: status})
+		if err != nil {
+			return 0, err
+		}
+		for _, job := range records {
+			if job.Awaited() {
+				waiting = append(waiting, job)
+			}
+		}
+	}
+	if len(waiting) == 0 {
+		return 0, nil
+	}
+
+	bucket, err := r.epubBucket()
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	for _, job := range waiting {
+		if attrs, err := bucket.Attrs(ctx, jobObject(job, ".epub")); err == nil {
+			_, id := tenant.SplitID(outputID(job))
+			if attrs, err = checkGeneratedEpub(ctx, bucket, attrs, naming.FromRevision(id, job.RevisionID, "")); err == nil {
+				r.recordCompletion(ctx, job, attrs)
+				r.notifyJob(ctx, bucket, job, attrs)
+				notified++
+				continue
+			}
+			_ = r.rejectEpub(ctx, bucket, attrs, job, err)
+		}
+
+		// Nobody may poll the job while its requester waits for the email,
+		// so stale and failed generations are retried here.
+		r.syncGeneratorStatus(ctx, bucket, jobObject(job, ".status"), job)
+		switch job.Status {
+		case jobs.StatusPending:
+			r.handlePendingJob(ctx, job)
+		case jobs.StatusFailed:
+			r.handleFailedJob(ctx, job)
+		case jobs.StatusProcessing, jobs.StatusCompleted, jobs.StatusDeadLetter:
+		}
+		if job.Status == jobs.StatusDeadLetter {
+			r.notifyJob(ctx, bucket, job, nil)
+			notified++
+		}
+	}
+	return notified, nil
+}
+
+// RunNotifications calls CheckNotifications every interval until ctx is
+// canceled.
+func (r *Resolver) RunNotifications(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := r.CheckNotifications(ctx)
+			if err != nil {
+				log.Printf("Notification check failed: %v", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Sent notifications for %d finished generations", n)
+			}
+		}
+	}
+}
+
+// callbackPayload is the JSON body of a callback.
+type callbackPayload struct {
+	// Event is epub.completed or epub.failed.
+	Event       string   `json:"event"`
+	ID          string   `json:"id"`
+	RevisionID  string   `json:"revisionId"`
+	Articles    []string `json:"articles,omitempty"`
+	Status      string   `json:"status"`
+	SignedURL   string   `json:"signedUrl,omitempty"`
+	DownloadURL string   `json:"downloadUrl,omitempty"`
+	Size        int64    `json:"size,omitempty"`
+	Sha256      string   `json:"sha256,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Attempts    int      `json:"attempts"`
+}
+
+// notifyJob emails the addresses and posts to the callbacks awaiting a job
+// that has finished, with a signed download link when attrs describes its
+// EPUB and the error otherwise. They are cleared before sending, so that a
+// failed delivery is not retried by every instance.
+func (r *Resolver) notifyJob(ctx context.Context, bucket objects.Bucket, job *jobs.Job, attrs *objects.Attrs) {
+	if !job.Awaited() {
+		return
+	}
+	recipients, callbacks := job.Notify, job.Callbacks
+	job.Notify, job.Callbacks = nil, nil
+	if err := r.jobs.Put(ctx, job); err != nil {
+		log.Printf("Failed to clear notifications of %s: %v", job.ID, err)
+		return
+	}
+
+	_, id := tenant.SplitID(outputID(job))
+	payload := callbackPayload{
+		Event:      "epub.failed",
+		ID:         id,
+		RevisionID: job.RevisionID,
+		Articles:   job.Articles,
+		Status:     string(job.Status),
+		Error:      job.Error,
+		Attempts:   job.Attempts,
+	}
+	if attrs != nil {
+		signedURL, err := generateSignedURL(bucket, attrs.Name, notificationLinkTTL, r.epubDisposition(id, attrs))
+		if err != nil {
+			log.Printf("Failed to sign download link of %s: %v", job.ID, err)
+			return
+		}
+		payload.Event = "epub.completed"
+		payload.Status = string(jobs.StatusCompleted)
+		payload.SignedURL = signedURL
+		payload.DownloadURL = *downloadURL(id, jobVersion(job))
+		payload.Size = attrs.Size
+		payload.Sha256 = attrs.Metadata[checksumKey]
+	}
+
+	// Deliveries are retried and may be slow, so they do not hold up the
+	// request that observed the completion.
+	go r.deliverNotifications(context.Background(), recipients, callbacks, payload)
+}
+
+// deliverNotifications posts the result of a generation to callbacks and
+// emails it to recipients.
+func (r *Resolver) deliverNotifications(ctx context.Context, recipients, callbacks []string, payload callbackPayload) {
+	if r.webhooks != nil {
+		for _, callback := range callbacks {
+			if err := r.webhooks.Post(ctx, callback, payload); err != nil {
+				log.Printf("Failed to post callback of %s: %v", payload.ID, err)
+			}
+		}
+	}
+	if r.mailer == nil || len(recipients) == 0 {
+		return
+	}
+
+	document := payload.ID
+	if len(payload.Articles) > 0 {
+		document += " (" + strings.Join(payload.Articles, ", ") + ")"
+	}
+	var msg mailer.Message
+	if payload.SignedURL != "" {
+		msg = mailer.Message{
+			Subject: "Your EPUB is ready: " + document,
+			Body: fmt.Sprintf("The EPUB you requested has been generated.\n\nDocument: %s\nDownload: %s\n\nThe link expires in 7 days. Request the EPUB again for a new link.\n",
+				document, payload.SignedURL),
+		}
+	} else {
+		msg = mailer.Message{
+			Subject: "EPUB generation failed: " + document,
+			Body: fmt.Sprintf("The EPUB you requested could not be generated after %d attempts.\n\nDocument: %s\nError: %s\n",
+				payload.Attempts, document, payload.Error),
+		}
+	}
+
+	for _, recipient := range recipients {
+		msg.To = recipient
+		if err := r.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Failed to notify about %s: %v", payload.ID, err)
+		}
+	}
+}
+
+// newWebhookSender returns the sender of callbacks, or nil when no secret
+// is configured.
+func newWebhookSender(secret string, timeout time.Duration) *webhook.Sender {
+	if secret == "" {
+		return nil
+	}
+	return webhook.NewSender(secret, timeout)
+}
@@ -0,0 +1,84 @@
+package graphql_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+func TestNotifyEmailRequiresIdentifiedClient(t *testing.T) {
+	const apiKey = "notify-key"
+	tests := []struct {
+		name     string
+		apiKey   string
+		admin    bool
+		requests int
+		wantCode string
+	}{
+		{name: "anonymous", requests: 1, wantCode: "FORBIDDEN"},
+		{name: "unknown key", apiKey: "other-key", requests: 1, wantCode: "FORBIDDEN"},
+		{name: "API key", apiKey: apiKey, requests: 2},
+		{name: "API key over quota", apiKey: apiKey, requests: 3, wantCode: "QUOTA_EXCEEDED"},
+		{name: "admin", admin: true, requests: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testsupport.NewServer(t, func(cfg *config.Config) {
+				cfg.Mail.Provider = "smtp"
+				cfg.Mail.From = "noreply@example.com"
+				cfg.Mail.SMTP.Host = "127.0.0.1"
+				cfg.Quota.APIKeys = []string{apiKey}
+				cfg.Quota.NotifyDaily = 2
+			})
+			var code string
+			for range tt.requests {
+				code = requestNotifyEmail(t, s, tt.apiKey, tt.admin)
+			}
+			if code != tt.wantCode {
+				t.Errorf("error code of request %d = %q, want %q", tt.requests, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// requestNotifyEmail asks to be emailed about an EPUB, with an API key when
+// apiKey is not empty, and returns the code of the error, if any.
+func requestNotifyEmail(t *testing.T, s *testsupport.Server, apiKey string, admin bool) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     `query ($id: String!) { epub(id: $id, notifyEmail: "reader@example.com") { status } }`,
+		"variables": map[string]interface{}{"id": constitution},
+	})
+	if err != nil {
+		t.Fatalf("Failed to encode GraphQL request: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	if admin {
+		req.Header.Set("Authorization", "Bearer "+testsupport.AdminToken)
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GraphQL request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var result testsupport.GraphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode GraphQL response: %v", err)
+	}
+	if len(result.Errors) == 0 {
+		return ""
+	}
+	code, _ := result.Errors[0].Extensions["code"].(string)
+	return code
+}
@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.ngs.io/jplaw2epub-web-api/upstream"
+)
+
+// fetchPages fetches the pages of an e-Gov listing from offset start up to
+// offset end, pageSize results each, and returns their results in order.
+// Up to concurrency pages are fetched at once, fewer when little of the
+// tracker's budget is left or e-Gov recently throttled a request. The
+// first error cancels the fetches still running.
+func fetchPages[T any](ctx context.Context, tracker *upstream.Tracker, concurrency, pageSize, start, end int, fetch func(ctx context.Context, offset int32) ([]T, error)) ([]T, error) {
+	if pageSize <= 0 || start >= end {
+		return nil, nil
+	}
+	pages := make([][]T, (end-start+pageSize-1)/pageSize)
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(tracker.Parallelism(min(concurrency, len(pages))))
+	for i := range pages {
+		offset := int32(start + i*pageSize)
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			items, err := fetch(ctx, offset)
+			pages[i] = items
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	var items []T
+	for _, page := range pages {
+		items = append(items, page...)
+	}
+	return items, nil
+}
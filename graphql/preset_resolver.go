@@ -0,0 +1,245 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/presets"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// presetScope returns the tenant whose presets the caller may change:
+// its own for tenant requests, and the one named by tenantArg, or the
+// shared presets, for admin requests.
+func presetScope(ctx context.Context, tenantArg *string) (string, error) {
+	caller := tenant.IDFromContext(ctx)
+	switch {
+	case caller != "":
+		if tenantArg != nil && *tenantArg != caller {
+			return "", errAdminRequired
+		}
+		return caller, nil
+	case handlers.IsAdmin(ctx):
+		if tenantArg == nil || *tenantArg == "" {
+			return "", nil
+		}
+		if err := tenant.ValidateID(*tenantArg); err != nil {
+			return "", withCode(model1.ErrorCodeBadUserInput, err)
+		}
+		return *tenantArg, nil
+	default:
+		return "", errAdminRequired
+	}
+}
+
+// listPresets returns the built-in presets, the presets of the caller's
+// tenant, and the shared presets not hidden by one of them, ordered by name.
+func (r *Resolver) listPresets(ctx context.Context) ([]model1.Preset, error) {
+	shared, err := r.presets.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*presets.Preset, len(shared))
+	for _, preset := range shared {
+		byName[preset.Name] = preset
+	}
+	if caller := tenant.IDFromContext(ctx); caller != "" {
+		own, err := r.presets.List(ctx, caller)
+		if err != nil {
+			return nil, err
+		}
+		for _, preset := range own {
+			byName[preset.Name] = preset
+		}
+	}
+
+	for _, preset := range presets.BuiltIns() {
+		byName[preset.Name] = preset
+	}
+
+	result := make([]model1.Preset, 0, len(byName))
+	for _, preset := range byName {
+		result = append(result, *convertPreset(preset))
+	}
+	sort.Slice(result, func(i, k int) bool {
+		return result[i].Name < result[k].Name
+	})
+	return result, nil
+}
+
+// findPreset returns the built-in preset with the name, the preset of the
+// caller's tenant, or else the shared one.
+func (r *Resolver) findPreset(ctx context.Context, name string) (*presets.Preset, error) {
+	if preset, ok := presets.BuiltIn(name); ok {
+		return preset, nil
+	}
+	if caller := tenant.IDFromContext(ctx); caller != "" {
+		preset, err := r.presets.Get(ctx, caller, name)
+		if err == nil {
+			return preset, nil
+		}
+		if !errors.Is(err, presets.ErrNotFound) {
+			return nil, err
+		}
+	}
+	preset, err := r.presets.Get(ctx, "", name)
+	if errors.Is(err, presets.ErrNotFound) {
+		return nil, codedErrorf(model1.ErrorCodeNotFound, "preset %q not found", name)
+	}
+	return preset, err
+}
+
+// savePreset validates and stores a preset in the caller's scope.
+func (r *Resolver) savePreset(ctx context.Context, input model1.PresetInput, tenantArg *string) (*model1.Preset, error) {
+	scope, err := presetScope(ctx, tenantArg)
+	if err != nil {
+		return nil, err
+	}
+	if err := presets.ValidateName(input.Name); err != nil {
+		return nil, withCode(model1.ErrorCodeBadUserInput, err)
+	}
+
+	preset := &presets.Preset{
+		Tenant:              scope,
+		Name:                input.Name,
+		Vertical:            input.Vertical != nil && *input.Vertical,
+		Furigana:            input.Furigana != nil && *input.Furigana,
+		Accessible:          input.Accessible != nil && *input.Accessible,
+		OmitSupplProvisions: input.OmitSupplProvisions != nil && *input.OmitSupplProvisions,
+		HighContrast:        input.HighContrast != nil && *input.HighContrast,
+		UpdatedAt:           r.clock.Now().UTC(),
+	}
+	if input.Description != nil {
+		preset.Description = *input.Description
+	}
+	if input.FontFamily != nil {
+		preset.FontFamily = fontFamilyName(*input.FontFamily)
+	}
+	if input.Font != nil {
+		preset.Font = *input.Font
+	}
+	if input.FontSize != nil {
+		preset.FontSize = *input.FontSize
+	}
+	if input.LineHeight != nil {
+		preset.LineHeight = *input.LineHeight
+	}
+	if input.LetterSpacing != nil {
+		preset.LetterSpacing = *input.LetterSpacing
+	}
+	if input.Cover != nil && *input.Cover != model1.CoverStyleNone {
+		preset.Cover = string(*input.Cover)
+	}
+	if _, err := r.presetOptions(preset); err != nil {
+		return nil, err
+	}
+
+	if err := r.presets.Put(ctx, preset); err != nil {
+		return nil, err
+	}
+	return convertPreset(preset), nil
+}
+
+// deletePreset removes a preset in the caller's scope. It reports false
+// when there is no such preset.
+func (r *Resolver) deletePreset(ctx context.Context, name string, tenantArg *string) (bool, error) {
+	scope, err := presetScope(ctx, tenantArg)
+	if err != nil {
+		return false, err
+	}
+	err = r.presets.Delete(ctx, scope, name)
+	if errors.Is(err, presets.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// presetOptions returns the converter options of a preset, failing for
+// options this server cannot apply.
+func (r *Resolver) presetOptions(preset *presets.Preset) (lawdata.Options, error) {
+	opts := lawdata.Options{
+		Accessible:          preset.Accessible,
+		Vertical:            preset.Vertical,
+		FontFamily:          preset.FontFamily,
+		FontSize:            preset.FontSize,
+		LineHeight:          preset.LineHeight,
+		LetterSpacing:       preset.LetterSpacing,
+		HighContrast:        preset.HighContrast,
+		OmitSupplProvisions: preset.OmitSupplProvisions,
+	}
+	if preset.Furigana {
+		if r.furigana == nil {
+			return lawdata.Options{}, withCode(model1.ErrorCodeNotConfigured, errors.New("furigana is not available: FURIGANA_ANALYZER is not set"))
+		}
+		opts.Ruby = r.furigana
+	}
+	if preset.Font != "" {
+		font, err := r.font(preset.Font)
+		if err != nil {
+			return lawdata.Options{}, err
+		}
+		opts.Font = font
+	}
+	if err := lawdata.ValidateLayout(opts); err != nil {
+		return lawdata.Options{}, withCode(model1.ErrorCodeBadUserInput, err)
+	}
+	return opts, nil
+}
+
+func convertPreset(preset *presets.Preset) *model1.Preset {
+	result := &model1.Preset{
+		Name:                preset.Name,
+		Tenant:              optionalString(preset.Tenant),
+		Description:         optionalString(preset.Description),
+		Font:                optionalString(preset.Font),
+		Vertical:            preset.Vertical,
+		Furigana:            preset.Furigana,
+		Accessible:          preset.Accessible,
+		OmitSupplProvisions: preset.OmitSupplProvisions,
+		LetterSpacing:       preset.LetterSpacing,
+		HighContrast:        preset.HighContrast,
+		Cover:               model1.CoverStyleNone,
+		UpdatedAt:           preset.UpdatedAt.Format(time.RFC3339),
+	}
+	if cover := model1.CoverStyle(preset.Cover); cover.IsValid() {
+		result.Cover = cover
+	}
+	switch preset.FontFamily {
+	case "serif":
+		family := model1.FontFamilySerif
+		result.FontFamily = &family
+	case "sans-serif":
+		family := model1.FontFamilySansSerif
+		result.FontFamily = &family
+	}
+	if preset.FontSize != 0 {
+		size := preset.FontSize
+		result.FontSize = &size
+	}
+	if preset.LineHeight != 0 {
+		height := preset.LineHeight
+		result.LineHeight = &height
+	}
+	_, result.BuiltIn = presets.BuiltIn(preset.Name)
+	return result
+}
+
+// fontFamilyName returns the CSS generic family of a FontFamily.
+func fontFamilyName(family model1.FontFamily) string {
+	switch family {
+	case model1.FontFamilySerif:
+		return "serif"
+	case model1.FontFamilySansSerif:
+		return "sans-serif"
+	default:
+		return ""
+	}
+}
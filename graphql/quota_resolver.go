@@ -0,0 +1,37 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+)
+
+// getQuota reports the usage that the quota middleware counted for this
+// request, so reading it needs no further store access.
+func getQuota(ctx context.Context) *model1.Quota {
+	result := &model1.Quota{}
+	state := handlers.QuotaFromContext(ctx)
+	if state == nil {
+		return result
+	}
+
+	result.Subject = state.Subject
+	for _, usage := range state.Usages {
+		window := &model1.QuotaWindow{
+			Limit:     int(usage.Limit),
+			Used:      int(usage.Used),
+			Remaining: int(usage.Remaining()),
+			ResetAt:   usage.ResetAt.Format(time.RFC3339),
+		}
+		switch usage.Window {
+		case quota.Daily:
+			result.Daily = window
+		case quota.Monthly:
+			result.Monthly = window
+		}
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"context"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// listReferences returns the cross-references in a law revision.
+func (r *Resolver) listReferences(ctx context.Context, revisionID string) ([]model1.Reference, error) {
+	law, err := r.getLawBody(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	citations := law.Citations()
+	references := make([]model1.Reference, 0, len(citations))
+	for _, citation := range citations {
+		reference := model1.Reference{
+			Provision:     citation.Provision,
+			SourceArticle: citation.SourceArticle,
+			Text:          citation.Text,
+			LawNum:        optionalString(citation.LawNum),
+			Article:       optionalString(citation.Article),
+			Paragraph:     optionalString(citation.Paragraph),
+			Item:          optionalString(citation.Item),
+		}
+		if id, ok := lawref.LawID(citation.LawNum); ok {
+			reference.LawID = &id
+		}
+		references = append(references, reference)
+	}
+	return references, nil
+}
@@ -0,0 +1,193 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/lawindex"
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// lawRanks orders the law types that implement one another, from the
+// constitution down. Misc laws are left out.
+var lawRanks = map[lawapi.LawType]int{
+	lawapi.LawTypeConstitution:         0,
+	lawapi.LawTypeAct:                  1,
+	lawapi.LawTypeCabinetorder:         2,
+	lawapi.LawTypeImperialorder:        2,
+	lawapi.LawTypeMinisterialordinance: 3,
+	lawapi.LawTypeRule:                 3,
+}
+
+// lawIDTypes are the law types of the law IDs lawref.LawID derives.
+var lawIDTypes = map[string]lawapi.LawType{
+	"AC": lawapi.LawTypeAct,
+	"CO": lawapi.LawTypeCabinetorder,
+}
+
+// relatedLaws returns the parent laws and subordinate regulations of a law,
+// parents first.
+func (r *Resolver) relatedLaws(ctx context.Context, id string) ([]model1.RelatedLaw, error) {
+	if r.lawIndex == nil {
+		return nil, codedErrorf(model1.ErrorCodeNotConfigured, "related laws are not configured: LAW_INDEX_INTERVAL is 0")
+	}
+	parsed, err := parseLawID(id)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Kind == lawid.RevisionID {
+		return nil, codedErrorf(model1.ErrorCodeInvalidLawID, "relatedLaws takes a law ID or law number, got revision ID %s", id)
+	}
+
+	resp, err := r.lawRevisions(parsed.Value)
+	if err != nil {
+		return nil, err
+	}
+	revision := latestRevision(resp.Revisions)
+	if revision == nil {
+		return nil, codedErrorf(model1.ErrorCodeLawNotFound, "law %s has no revisions", id)
+	}
+	lawType := revision.LawType
+	if lawType == nil {
+		lawType = resp.LawInfo.LawType
+	}
+	if lawType == nil {
+		return []model1.RelatedLaw{}, nil
+	}
+	rank, ok := lawRanks[*lawType]
+	if !ok {
+		return []model1.RelatedLaw{}, nil
+	}
+
+	related := relatedByTitle(r.lawIndex, resp.LawInfo.LawId, revision.LawTitle, rank)
+	if rank > lawRanks[lawapi.LawTypeAct] {
+		law, err := r.cachedLawBody(ctx, revision.LawRevisionId)
+		if err != nil {
+			return nil, err
+		}
+		related = addCitedParents(related, r.lawIndex, resp.LawInfo.LawId, rank, law.Citations())
+	}
+
+	laws := make([]model1.RelatedLaw, 0, len(related))
+	for _, law := range related {
+		laws = append(laws, *law)
+	}
+	sort.Slice(laws, func(i, k int) bool {
+		a, b := laws[i], laws[k]
+		switch {
+		case a.Relation != b.Relation:
+			return a.Relation == model1.LawRelationParent
+		case a.Basis != b.Basis:
+			return a.Basis == model1.RelationBasisTitle
+		case a.References != b.References:
+			return a.References > b.References
+		default:
+			return a.LawID < b.LawID
+		}
+	})
+	return laws, nil
+}
+
+// latestRevision returns the revision currently in force, or the one
+// enforced last when none is, as for a repealed law.
+func latestRevision(revisions []lawapi.RevisionInfo) *lawapi.RevisionInfo {
+	var latest *lawapi.RevisionInfo
+	for i := range revisions {
+		revision := &revisions[i]
+		if status := revision.CurrentRevisionStatus; status != nil && *status == lawapi.CurrentRevisionStatusCurrentenforced {
+			return revision
+		}
+		if latest == nil || time.Time(revision.AmendmentEnforcementDate).After(time.Time(latest.AmendmentEnforcementDate)) {
+			latest = revision
+		}
+	}
+	return latest
+}
+
+// relatedByTitle relates a law to the indexed laws of higher rank whose
+// titles begin its title, and to those of lower rank whose titles begin
+// with it. Amending laws, whose titles also begin with the title of the
+// law they amend, are left out.
+func relatedByTitle(index *lawindex.Index, lawID, title string, rank int) map[string]*model1.RelatedLaw {
+	related := make(map[string]*model1.RelatedLaw)
+	add := func(entry lawindex.Entry, relation model1.LawRelation) {
+		if entry.LawID == lawID || strings.Contains(entry.Title, "を改正する") {
+			return
+		}
+		entryRank, ok := lawRanks[lawapi.LawType(entry.LawType)]
+		if !ok || relation == model1.LawRelationParent && entryRank >= rank || relation == model1.LawRelationSubordinate && entryRank <= rank {
+			return
+		}
+		related[entry.LawID] = relatedLaw(entry, relation, model1.RelationBasisTitle)
+	}
+
+	for i := range title {
+		if i == 0 {
+			continue
+		}
+		for _, entry := range index.Titled(title[:i]) {
+			add(entry, model1.LawRelationParent)
+		}
+	}
+	for _, entry := range index.TitledAfter(title) {
+		add(entry, model1.LawRelationSubordinate)
+	}
+	return related
+}
+
+// addCitedParents adds to related the acts, and for ministerial ordinances
+// and rules the cabinet orders, cited with their law numbers in the main
+// provision of a law of rank. Citations in supplementary provisions are
+// left out, as they are mostly of amending laws.
+func addCitedParents(related map[string]*model1.RelatedLaw, index *lawindex.Index, lawID string, rank int, citations []lawdata.Citation) map[string]*model1.RelatedLaw {
+	for _, citation := range citations {
+		if citation.Provision != "" || citation.LawNum == "" {
+			continue
+		}
+		id, ok := lawref.LawID(citation.LawNum)
+		if !ok || id == lawID {
+			continue
+		}
+		lawType := lawIDTypes[id[3:5]]
+		if lawRanks[lawType] >= rank {
+			continue
+		}
+
+		law, ok := related[id]
+		if !ok {
+			entry, indexed := index.Get(id)
+			if !indexed {
+				entry = lawindex.Entry{LawID: id, LawNum: citation.LawNum}
+			}
+			law = relatedLaw(entry, model1.LawRelationParent, model1.RelationBasisCrossReference)
+			if law.LawType == nil {
+				law.LawType = convertLawTypeToModel(&lawType)
+			}
+			related[id] = law
+		}
+		law.References++
+	}
+	return related
+}
+
+func relatedLaw(entry lawindex.Entry, relation model1.LawRelation, basis model1.RelationBasis) *model1.RelatedLaw {
+	law := &model1.RelatedLaw{
+		Relation: relation,
+		Basis:    basis,
+		LawID:    entry.LawID,
+		LawNum:   entry.LawNum,
+		Title:    optionalString(entry.Title),
+	}
+	if entry.LawType != "" {
+		lawType := lawapi.LawType(entry.LawType)
+		law.LawType = convertLawTypeToModel(&lawType)
+	}
+	return law
+}
@@ -5,15 +5,201 @@ package graphql
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 import (
+	"maps"
+	"sync"
+
 	jplaw "go.ngs.io/jplaw-api-v2"
+
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/fonts"
+	"go.ngs.io/jplaw2epub-web-api/furigana"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawindex"
+	"go.ngs.io/jplaw2epub-web-api/library"
+	"go.ngs.io/jplaw2epub-web-api/mailer"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/presets"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+	"go.ngs.io/jplaw2epub-web-api/translation"
+	"go.ngs.io/jplaw2epub-web-api/upstream"
+	"go.ngs.io/jplaw2epub-web-api/webhook"
 )
 
 type Resolver struct {
-	client *jplaw.Client
+	client         LawAPI
+	storage        Storage
+	runner         JobRunner
+	clock          Clock
+	upstream       *upstream.Tracker
+	lawData        *lawdata.Client
+	jobs           jobs.Store
+	presets        presets.Store
+	library        library.Store
+	retry          jobs.RetryPolicy
+	generator      generatorConfig
+	allowedOrigins []string
+	corsRoutes     func() []handlers.CORSRoute
+	audit          audit.Logger
+	lawsCache      *upstreamCache[*jplaw.LawsResponse]
+	keywordCache   *upstreamCache[*jplaw.KeywordResponse]
+	lawIndex       *lawindex.Index
+	warmUp         warmUpConfig
+	warmUpMu       sync.Mutex
+	revalidate     revalidateConfig
+	cleanup        cleanupConfig
+	titles         *translation.Table
+	furigana       *furigana.Annotator
+	mailer         mailer.Mailer
+	webhooks       *webhook.Sender
+	pool           *sandbox.Pool
+	slowQueries    *SlowQueryLog
+	// filenames names downloaded EPUBs.
+	filenames *naming.Template
+	// dispatch caps concurrent generations; nil starts them right away.
+	dispatch *dispatcher
+	// priorityLimiter counts HIGH priority generations per client.
+	priorityLimiter *quota.Limiter
+	// notifyLimiter counts epub queries with notifyEmail per client.
+	notifyLimiter *quota.Limiter
+	// estimates holds the generation times behind estimatedSeconds.
+	estimates durationEstimator
+	// canary routes a share of new generations to the canary generator;
+	// nil runs them all with the current one.
+	canary *canaryRollout
+	// pageConcurrency is the number of pages of an e-Gov listing fetched
+	// at once.
+	pageConcurrency int
+	// bodyCache holds the parsed law bodies of searchInLaw,
+	// tableOfContents, and cite, by revision ID. They are shared between requests
+	// and must not be modified.
+	bodyCache *upstreamCache[*lawdata.Law]
+	// fonts are the fonts that converted EPUBs can embed.
+	fonts *fonts.Library
+	// categoryCounts holds the number of laws in each category, by e-Gov
+	// category name, under a single key.
+	categoryCounts *upstreamCache[map[string]int]
+}
+
+// generatorConfig locates the EPUB bucket that the generator fills.
+type generatorConfig struct {
+	bucketName string
+	// versions are the older converter versions that can be generated.
+	versions map[string]bool
+}
+
+// Deps are the stores and helpers that the server builds from the
+// configuration for the resolver, next to the external services in
+// Dependencies.
+type Deps struct {
+	Jobs    jobs.Store
+	Presets presets.Store
+	Library library.Store
+	// CORSRoutes returns the routes reported by the corsConfig query, as
+	// registered once the server is built.
+	CORSRoutes func() []handlers.CORSRoute
+	Audit      audit.Logger
+	// Titles translates law titles; nil leaves them untranslated.
+	Titles *translation.Table
+	// Furigana is nil when no analyzer is configured.
+	Furigana *furigana.Annotator
+	Mailer   mailer.Mailer
+	// Pool bounds in-process conversions.
+	Pool *sandbox.Pool
+	// Tracker counts calls to the e-Gov API at UpstreamURL.
+	Tracker     *upstream.Tracker
+	UpstreamURL string
+	// Filenames names downloaded EPUBs.
+	Filenames *naming.Template
+	// PriorityLimiter counts HIGH priority generations per client.
+	PriorityLimiter *quota.Limiter
+	// NotifyLimiter counts epub queries with notifyEmail per client.
+	NotifyLimiter *quota.Limiter
+	// Fonts are the fonts that converted EPUBs can embed.
+	Fonts *fonts.Library
 }
 
-func NewResolver() *Resolver {
+// NewResolver returns the resolver of the schema, calling the services in
+// services and using what the server built in deps.
+func NewResolver(cfg *config.Config, services Dependencies, deps Deps) *Resolver {
+	tracker := deps.Tracker
+	if services.LawAPI == nil {
+		services.LawAPI = upstream.NewClient(jplaw.NewClient(), tracker)
+	}
+	if services.JobRunner == nil {
+		jobNames := maps.Clone(cfg.JobVersions)
+		if cfg.Canary.Percent > 0 {
+			if jobNames == nil {
+				jobNames = make(map[string]string)
+			}
+			jobNames[cfg.Canary.Version] = cfg.Canary.JobName
+		}
+		services.JobRunner = cloudRunJobs{projectID: cfg.ProjectID, region: cfg.Region, jobName: cfg.JobName, versions: jobNames}
+	}
+	if services.Clock == nil {
+		services.Clock = systemClock{}
+	}
 	return &Resolver{
-		client: jplaw.NewClient(),
+		client:   services.LawAPI,
+		storage:  services.Storage,
+		runner:   services.JobRunner,
+		clock:    services.Clock,
+		upstream: tracker,
+		lawData:  upstream.NewLawDataClient(tracker, deps.UpstreamURL),
+		jobs:     deps.Jobs,
+		presets:  deps.Presets,
+		library:  deps.Library,
+		retry: jobs.RetryPolicy{
+			MaxAttempts:    cfg.Retry.MaxAttempts,
+			InitialBackoff: cfg.Retry.Backoff,
+			MaxBackoff:     cfg.Retry.MaxBackoff,
+		},
+		generator: generatorConfig{
+			bucketName: cfg.BucketName,
+			versions:   pinnableVersions(cfg.JobVersions),
+		},
+		allowedOrigins: cfg.CORSOrigins,
+		corsRoutes:     deps.CORSRoutes,
+		audit:          deps.Audit,
+		lawsCache:      newUpstreamCache[*jplaw.LawsResponse](cfg.LawCache.Size, cfg.LawCache.TTL, cfg.LawCache.StaleTTL),
+		keywordCache:   newUpstreamCache[*jplaw.KeywordResponse](cfg.LawCache.Size, cfg.LawCache.TTL, cfg.LawCache.StaleTTL),
+		lawIndex:       newLawIndex(cfg.LawIndex.Interval),
+		warmUp: warmUpConfig{
+			lawIDs: cfg.WarmUp.LawIDs,
+			topN:   cfg.WarmUp.TopN,
+		},
+		revalidate: revalidateConfig{
+			lookback:   cfg.Revalidate.Lookback,
+			regenerate: cfg.Revalidate.Regenerate,
+		},
+		cleanup: cleanupConfig{
+			maxIdle:      cfg.Cleanup.MaxIdle,
+			statusMaxAge: cfg.Cleanup.StatusMaxAge,
+		},
+		titles:   deps.Titles,
+		furigana: deps.Furigana,
+		mailer:   deps.Mailer,
+		webhooks: newWebhookSender(cfg.Webhook.Secret, cfg.Webhook.Timeout),
+		pool:     deps.Pool,
+		// Installed on the server by NewServer through SlowQueries.
+		slowQueries:     NewSlowQueryLog(cfg.GraphQL.SlowQueryThreshold),
+		filenames:       deps.Filenames,
+		dispatch:        newDispatcher(cfg.Dispatch.Concurrency),
+		priorityLimiter: deps.PriorityLimiter,
+		notifyLimiter:   deps.NotifyLimiter,
+		canary:          newCanaryRollout(cfg.Canary.Version, cfg.Canary.Percent),
+		pageConcurrency: cfg.Upstream.PageConcurrency,
+		bodyCache:       newUpstreamCache[*lawdata.Law](cfg.LawCache.BodySize, cfg.LawCache.TTL, cfg.LawCache.StaleTTL),
+		fonts:           deps.Fonts,
+		categoryCounts:  newUpstreamCache[map[string]int](1, cfg.LawCache.TTL, cfg.LawCache.StaleTTL),
 	}
 }
+
+// SlowQueries returns the log of slow operations that slowOperations
+// reads, for NewServer to install.
+func (r *Resolver) SlowQueries() *SlowQueryLog {
+	return r.slowQueries
+}
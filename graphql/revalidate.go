@@ -0,0 +1,199 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// revalidateConfig controls the reconciliation of generated EPUBs with
+// amendments published by e-Gov.
+type revalidateConfig struct {
+	// lookback is the update window checked when no earlier run is known,
+	// for example after a restart.
+	lookback time.Duration
+	// regenerate starts regeneration of stale EPUBs immediately instead of
+	// on their next request.
+	regenerate bool
+
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+// Revalidate asks e-Gov for revisions updated since the last run of every
+// law with a generated EPUB and marks EPUBs generated before the update as
+// stale. Requests by law ID follow the current revision, so any updated
+// revision of the law makes them stale. Only one run happens at a time.
+func (r *Resolver) Revalidate(ctx context.Context) (*handlers.RevalidateResult, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("EPUB generation")
+	}
+	if !r.revalidate.mu.TryLock() {
+		return nil, handlers.ErrAlreadyRunning
+	}
+	defer r.revalidate.mu.Unlock()
+
+	now := r.clock.Now()
+	since := r.revalidate.lastRun
+	if since.IsZero() {
+		since = now.Add(-r.revalidate.lookback)
+	}
+
+	records, err := r.jobs.List(ctx, jobs.ListOptions{Status: jobs.StatusCompleted})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %v", err)
+	}
+	byLaw := make(map[string][]*jobs.Job)
+	for _, job := range records {
+		if !job.StaleAt.IsZero() {
+			continue
+		}
+		if lawID, ok := lawIDOf(job.RevisionID); ok {
+			byLaw[lawID] = append(byLaw[lawID], job)
+		}
+	}
+	lawIDs := make([]string, 0, len(byLaw))
+	for lawID := range byLaw {
+		lawIDs = append(lawIDs, lawID)
+	}
+	sort.Strings(lawIDs)
+
+	result := &handlers.RevalidateResult{
+		Since:       since.Format(time.RFC3339),
+		CheckedLaws: len(lawIDs),
+		Stale:       []string{},
+		Regenerated: []string{},
+	}
+
+	var bucket objects.Bucket
+	if r.revalidate.regenerate {
+		var err error
+		if bucket, err = r.epubBucket(); err != nil {
+			return nil, err
+		}
+	}
+
+	updatedFrom := lawapi.Date(since)
+	for _, lawID := range lawIDs {
+		resp, err := r.client.GetRevisions(lawID, &lawapi.GetRevisionsParams{UpdatedFrom: &updatedFrom})
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to get revisions of %s: %v", lawID, err))
+			continue
+		}
+
+		for _, job := range byLaw[lawID] {
+			if !outdated(job, resp.Revisions) {
+				continue
+			}
+			job.StaleAt = now
+			job.UpdatedAt = now
+			if err := r.jobs.Put(ctx, job); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("failed to mark %s stale: %v", job.ID, err))
+				continue
+			}
+			result.Stale = append(result.Stale, job.ID)
+
+			if bucket == nil {
+				continue
+			}
+			if err := r.regenerateEpub(ctx, bucket, job); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				continue
+			}
+			result.Regenerated = append(result.Regenerated, job.ID)
+		}
+	}
+
+	// Keep the window open until every law was checked successfully.
+	if len(result.Errors) == 0 {
+		r.revalidate.lastRun = now
+	}
+	return result, nil
+}
+
+// RunRevalidation calls Revalidate every interval until ctx is canceled.
+func (r *Resolver) RunRevalidation(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := r.Revalidate(ctx)
+			if err != nil {
+				log.Printf("Revalidation failed: %v", err)
+				continue
+			}
+			log.Printf("Revalidation checked %d laws: %d stale, %d regenerated, %d errors",
+				result.CheckedLaws, len(result.Stale), len(result.Regenerated), len(result.Errors))
+		}
+	}
+}
+
+// regenerateEpub deletes an outdated EPUB and starts generating it again,
+// so that it is not served while the new one is built.
+func (r *Resolver) regenerateEpub(ctx context.Context, bucket objects.Bucket, job *jobs.Job) error {
+	err := bucket.Delete(ctx, jobObject(job, ".epub"), objects.Conditions{})
+	if err != nil && !errors.Is(err, objects.ErrNotExist) {
+		return fmt.Errorf("failed to delete outdated EPUB %s: %v", job.ID, err)
+	}
+
+	log.Printf("Regenerating outdated EPUB %s (stale since %v)", job.ID, job.StaleAt)
+	now := r.clock.Now()
+	job.Status = jobs.StatusPending
+	job.Attempts = 1
+	job.Stage = ""
+	job.Error = ""
+	job.StaleAt = time.Time{}
+	job.StartedAt = now
+	job.UpdatedAt = now
+	job.CompletedAt = time.Time{}
+	job.NextRetryAt = time.Time{}
+	job.QueuedAt = time.Time{}
+	job.Priority = jobs.PriorityLow
+	if err := r.jobs.Put(ctx, job); err != nil {
+		return fmt.Errorf("failed to update job record for %s: %v", job.ID, err)
+	}
+
+	r.startGeneration(ctx, job)
+	return nil
+}
+
+// outdated reports whether a revision of the job's law was updated after
+// the EPUB was generated.
+func outdated(job *jobs.Job, revisions []lawapi.RevisionInfo) bool {
+	followsCurrent := !strings.Contains(job.RevisionID, "_")
+	for _, revision := range revisions {
+		if !time.Time(revision.Updated).After(job.CompletedAt) {
+			continue
+		}
+		if followsCurrent || revision.LawRevisionId == job.RevisionID {
+			return true
+		}
+	}
+	return false
+}
+
+// lawIDOf extracts the law ID from a revision ID such as
+// 129AC0000000089_20230401_503AC0000000061, or returns a bare law ID.
+// Requests by law number are not revalidated.
+func lawIDOf(id string) (string, bool) {
+	parsed, err := lawid.Parse(id)
+	if err != nil || parsed.Kind == lawid.LawNum {
+		return "", false
+	}
+	return parsed.LawID, true
+}
@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+)
+
+// MarshalDate writes the Date scalar as YYYY-MM-DD.
+func MarshalDate(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(t.Format(jpdate.Layout)))
+	})
+}
+
+// UnmarshalDate reads the Date scalar from an ISO date or a Japanese era
+// date such as 令和5年4月1日.
+func UnmarshalDate(v interface{}) (time.Time, error) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, codedErrorf(model1.ErrorCodeBadUserInput, "date must be a string, got %T", v)
+	}
+	return jpdate.Parse(s)
+}
+
+// MarshalEraDate writes the EraDate scalar as a Japanese era date such as
+// 令和5年4月1日.
+func MarshalEraDate(t time.Time) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(jpdate.FormatEra(t)))
+	})
+}
+
+// UnmarshalEraDate reads the EraDate scalar in any format accepted by Date.
+func UnmarshalEraDate(v interface{}) (time.Time, error) {
+	return UnmarshalDate(v)
+}
+
+// MarshalLawNum writes a law number as stored by e-Gov.
+func MarshalLawNum(s string) graphql.Marshaler {
+	return graphql.MarshalString(s)
+}
+
+// UnmarshalLawNum validates a law number and normalizes it to the form
+// stored by e-Gov, such as 令和五年法律第三十六号 for 令和5年法律第36号.
+func UnmarshalLawNum(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", codedErrorf(model1.ErrorCodeBadUserInput, "law number must be a string, got %T", v)
+	}
+	return jpdate.NormalizeLawNum(s)
+}
@@ -8,10 +8,70 @@ import (
 	"context"
 	"time"
 
+	"github.com/99designs/gqlgen/graphql"
 	lawapi "go.ngs.io/jplaw-api-v2"
 	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
 )
 
+// StatusDisplayName is the resolver for the statusDisplayName field.
+func (r *bulkExportResolver) StatusDisplayName(ctx context.Context, obj *model1.BulkExport, locale *model1.Locale) (string, error) {
+	return displayName(ctx, locale, "EpubStatus", obj.Status), nil
+}
+
+// Description is the resolver for the description field.
+func (r *categoryResolver) Description(ctx context.Context, obj *model1.Category, locale *model1.Locale) (string, error) {
+	return categoryDescription(ctx, obj.Code, locale), nil
+}
+
+// DisplayName is the resolver for the displayName field.
+func (r *categoryFacetResolver) DisplayName(ctx context.Context, obj *model1.CategoryFacet, locale *model1.Locale) (string, error) {
+	if obj.Code == nil {
+		return obj.Name, nil
+	}
+	return displayName(ctx, locale, "CategoryCode", *obj.Code), nil
+}
+
+// StatusDisplayName is the resolver for the statusDisplayName field.
+func (r *epubResolver) StatusDisplayName(ctx context.Context, obj *model1.Epub, locale *model1.Locale) (string, error) {
+	return displayName(ctx, locale, "EpubStatus", obj.Status), nil
+}
+
+// EstimatedSeconds is the resolver for the estimatedSeconds field.
+func (r *epubResolver) EstimatedSeconds(ctx context.Context, obj *model1.Epub) (*float64, error) {
+	return r.Resolver.estimatedSeconds(ctx, obj), nil
+}
+
+// StatusDisplayName is the resolver for the statusDisplayName field.
+func (r *epubBundleResolver) StatusDisplayName(ctx context.Context, obj *model1.EpubBundle, locale *model1.Locale) (string, error) {
+	return displayName(ctx, locale, "EpubStatus", obj.Status), nil
+}
+
+// StatusDisplayName is the resolver for the statusDisplayName field.
+func (r *epubJobResolver) StatusDisplayName(ctx context.Context, obj *model1.EpubJob, locale *model1.Locale) (string, error) {
+	return displayName(ctx, locale, "EpubStatus", obj.Status), nil
+}
+
+// DisplayName is the resolver for the displayName field.
+func (r *eraFacetResolver) DisplayName(ctx context.Context, obj *model1.EraFacet, locale *model1.Locale) (string, error) {
+	return displayName(ctx, locale, "LawNumEra", obj.Era), nil
+}
+
+// TitleEn is the resolver for the titleEn field.
+func (r *keywordItemResolver) TitleEn(ctx context.Context, obj *lawapi.KeywordItem) (*string, error) {
+	return optionalString(r.Resolver.TitleEn(&lawapi.LawItem{LawInfo: obj.LawInfo})), nil
+}
+
+// Item is the resolver for the item field.
+func (r *lawResolver) Item(ctx context.Context, obj *model1.Law) (*lawapi.LawItem, error) {
+	return r.Resolver.lawEntityItem(ctx, obj)
+}
+
+// TitleEn is the resolver for the titleEn field.
+func (r *lawBodyResolver) TitleEn(ctx context.Context, obj *lawdata.Law) (*string, error) {
+	return optionalString(obj.TitleEn), nil
+}
+
 // LawNumEra is the resolver for the lawNumEra field.
 func (r *lawInfoResolver) LawNumEra(ctx context.Context, obj *lawapi.LawInfo) (*model1.LawNumEra, error) {
 	return convertLawNumEraToModel(obj.LawNumEra), nil
@@ -27,53 +87,140 @@ func (r *lawInfoResolver) LawType(ctx context.Context, obj *lawapi.LawInfo) (*mo
 	return convertLawTypeToModel(obj.LawType), nil
 }
 
+// LawTypeDisplayName is the resolver for the lawTypeDisplayName field.
+func (r *lawInfoResolver) LawTypeDisplayName(ctx context.Context, obj *lawapi.LawInfo, locale *model1.Locale) (*string, error) {
+	return optionalDisplayName(ctx, locale, "LawType", convertLawTypeToModel(obj.LawType)), nil
+}
+
 // PromulgationDate is the resolver for the promulgationDate field.
 func (r *lawInfoResolver) PromulgationDate(ctx context.Context, obj *lawapi.LawInfo) (string, error) {
 	return obj.PromulgationDate.String(), nil
 }
 
-// Laws is the resolver for the laws field.
-func (r *queryResolver) Laws(ctx context.Context, lawID *string, lawNum *string, lawTitle *string, lawTitleKana *string, lawType []model1.LawType, asof *string, categoryCode []model1.CategoryCode, promulgateDateFrom *string, promulgateDateTo *string, limit *int, offset *int) (*lawapi.LawsResponse, error) {
-	params := &lawapi.GetLawsParams{}
+// PromulgationEraDate is the resolver for the promulgationEraDate field.
+func (r *lawInfoResolver) PromulgationEraDate(ctx context.Context, obj *lawapi.LawInfo) (*time.Time, error) {
+	return convertDateToEraDate(obj.PromulgationDate), nil
+}
 
-	if lawID != nil {
-		params.LawId = lawID
-	}
-	if lawNum != nil {
-		params.LawNum = lawNum
-	}
-	if lawTitle != nil {
-		params.LawTitle = lawTitle
-	}
-	if lawTitleKana != nil {
-		params.LawTitleKana = lawTitleKana
-	}
-	if len(lawType) > 0 {
-		converted := convertLawType(lawType)
-		params.LawType = &converted
-	}
-	if asof != nil {
-		if t, err := time.Parse("2006-01-02", *asof); err == nil {
-			date := lawapi.Date(t)
-			params.Asof = &date
-		}
+// TitleEn is the resolver for the titleEn field.
+func (r *lawItemResolver) TitleEn(ctx context.Context, obj *lawapi.LawItem) (*string, error) {
+	return optionalString(r.Resolver.TitleEn(obj)), nil
+}
+
+// Body is the resolver for the body field.
+func (r *lawItemResolver) Body(ctx context.Context, obj *lawapi.LawItem) (*lawdata.Law, error) {
+	revisionID := lawItemRevision(obj)
+	if revisionID == "" {
+		return nil, nil
 	}
-	if len(categoryCode) > 0 {
-		converted := convertCategoryCode(categoryCode)
-		params.CategoryCd = &converted
+	return r.Resolver.getLawBody(ctx, revisionID)
+}
+
+// References is the resolver for the references field.
+func (r *lawItemResolver) References(ctx context.Context, obj *lawapi.LawItem) ([]model1.Reference, error) {
+	revisionID := lawItemRevision(obj)
+	if revisionID == "" {
+		return nil, nil
 	}
-	if promulgateDateFrom != nil {
-		if t, err := time.Parse("2006-01-02", *promulgateDateFrom); err == nil {
-			date := lawapi.Date(t)
-			params.PromulgationDateFrom = &date
-		}
+	return r.Resolver.listReferences(ctx, revisionID)
+}
+
+// Timeline is the resolver for the timeline field.
+func (r *lawItemResolver) Timeline(ctx context.Context, obj *lawapi.LawItem) ([]model1.TimelineEvent, error) {
+	return r.Resolver.lawTimeline(obj)
+}
+
+// DisplayName is the resolver for the displayName field.
+func (r *lawTypeFacetResolver) DisplayName(ctx context.Context, obj *model1.LawTypeFacet, locale *model1.Locale) (string, error) {
+	return displayName(ctx, locale, "LawType", obj.LawType), nil
+}
+
+// ConvertXML is the resolver for the convertXml field.
+func (r *mutationResolver) ConvertXML(ctx context.Context, file graphql.Upload, output *model1.ConvertOutput, furigana *bool, accessible *bool, vertical *bool) (*model1.ConvertResult, error) {
+	format := model1.ConvertOutputURL
+	if output != nil {
+		format = *output
 	}
-	if promulgateDateTo != nil {
-		if t, err := time.Parse("2006-01-02", *promulgateDateTo); err == nil {
-			date := lawapi.Date(t)
-			params.PromulgationDateTo = &date
-		}
+	return r.Resolver.convertXML(ctx, file, format, furigana != nil && *furigana, accessible != nil && *accessible, vertical != nil && *vertical)
+}
+
+// ValidateXML is the resolver for the validateXml field.
+func (r *mutationResolver) ValidateXML(ctx context.Context, file graphql.Upload) (*model1.XMLValidationResult, error) {
+	return r.Resolver.validateXML(ctx, file)
+}
+
+// RequestBulkExport is the resolver for the requestBulkExport field.
+func (r *mutationResolver) RequestBulkExport(ctx context.Context, ids []string, format *model1.Format) (*model1.BulkExport, error) {
+	f := model1.FormatEpub
+	if format != nil {
+		f = *format
 	}
+	return r.Resolver.requestBulkExport(ctx, ids, f)
+}
+
+// EpubBundle is the resolver for the epubBundle field.
+func (r *mutationResolver) EpubBundle(ctx context.Context, rootLawID string, includeSubordinate *bool) (*model1.EpubBundle, error) {
+	return r.Resolver.requestEpubBundle(ctx, rootLawID, includeSubordinate == nil || *includeSubordinate)
+}
+
+// SavePreset is the resolver for the savePreset field.
+func (r *mutationResolver) SavePreset(ctx context.Context, input model1.PresetInput, tenant *string) (*model1.Preset, error) {
+	return r.Resolver.savePreset(ctx, input, tenant)
+}
+
+// DeletePreset is the resolver for the deletePreset field.
+func (r *mutationResolver) DeletePreset(ctx context.Context, name string, tenant *string) (bool, error) {
+	return r.Resolver.deletePreset(ctx, name, tenant)
+}
+
+// UploadCoverLogo is the resolver for the uploadCoverLogo field.
+func (r *mutationResolver) UploadCoverLogo(ctx context.Context, file graphql.Upload, tenant *string) (*model1.CoverLogo, error) {
+	return r.Resolver.uploadCoverLogo(ctx, file, tenant)
+}
+
+// DeleteCoverLogo is the resolver for the deleteCoverLogo field.
+func (r *mutationResolver) DeleteCoverLogo(ctx context.Context, tenant *string) (bool, error) {
+	return r.Resolver.deleteCoverLogo(ctx, tenant)
+}
+
+// BookmarkLaw is the resolver for the bookmarkLaw field.
+func (r *mutationResolver) BookmarkLaw(ctx context.Context, lawID string, note *string) (*model1.Bookmark, error) {
+	return r.Resolver.bookmarkLaw(ctx, lawID, note)
+}
+
+// RemoveBookmark is the resolver for the removeBookmark field.
+func (r *mutationResolver) RemoveBookmark(ctx context.Context, lawID string) (bool, error) {
+	return r.Resolver.removeBookmark(ctx, lawID)
+}
+
+// SaveSearch is the resolver for the saveSearch field.
+func (r *mutationResolver) SaveSearch(ctx context.Context, input model1.SavedSearchInput) (*model1.SavedSearch, error) {
+	return r.Resolver.saveSearch(ctx, input)
+}
+
+// DeleteSavedSearch is the resolver for the deleteSavedSearch field.
+func (r *mutationResolver) DeleteSavedSearch(ctx context.Context, id string) (bool, error) {
+	return r.Resolver.deleteSavedSearch(ctx, id)
+}
+
+// SaveCompilation is the resolver for the saveCompilation field.
+func (r *mutationResolver) SaveCompilation(ctx context.Context, input model1.CompilationInput) (*model1.Compilation, error) {
+	return r.Resolver.saveCompilation(ctx, input)
+}
+
+// DeleteCompilation is the resolver for the deleteCompilation field.
+func (r *mutationResolver) DeleteCompilation(ctx context.Context, id string) (bool, error) {
+	return r.Resolver.deleteCompilation(ctx, id)
+}
+
+// RetryJob is the resolver for the retryJob field.
+func (r *mutationResolver) RetryJob(ctx context.Context, id string) (*model1.EpubJob, error) {
+	return r.Resolver.retryJob(ctx, id)
+}
+
+// Laws is the resolver for the laws field.
+func (r *queryResolver) Laws(ctx context.Context, lawID *string, lawNum *string, lawTitle *string, lawTitleKana *string, lawType []model1.LawType, asof *time.Time, categoryCode []model1.CategoryCode, promulgateDateFrom *time.Time, promulgateDateTo *time.Time, limit *int, offset *int, sort *model1.LawSort, order *model1.SortOrder) (*lawapi.LawsResponse, error) {
+	params := lawsParams(lawID, lawNum, lawTitle, lawTitleKana, lawType, asof, categoryCode, promulgateDateFrom, promulgateDateTo)
 	if limit != nil {
 		limit32 := int32(*limit)
 		params.Limit = &limit32
@@ -83,11 +230,27 @@ func (r *queryResolver) Laws(ctx context.Context, lawID *string, lawNum *string,
 		params.Offset = &offset32
 	}
 
-	return r.Resolver.client.GetLaws(params)
+	return r.Resolver.searchLaws(ctx, params, sortKey(sort), sortOrder(order))
+}
+
+// SuggestLaws is the resolver for the suggestLaws field.
+func (r *queryResolver) SuggestLaws(ctx context.Context, prefix string, limit *int) ([]model1.LawSuggestion, error) {
+	return r.Resolver.suggestLaws(prefix, limit)
+}
+
+// LawTitleSuggestions is the resolver for the lawTitleSuggestions field.
+func (r *queryResolver) LawTitleSuggestions(ctx context.Context, query string, limit *int) ([]model1.LawSuggestion, error) {
+	return r.Resolver.lawTitleSuggestions(query, limit)
+}
+
+// LawFacets is the resolver for the lawFacets field.
+func (r *queryResolver) LawFacets(ctx context.Context, lawID *string, lawNum *string, lawTitle *string, lawTitleKana *string, lawType []model1.LawType, asof *time.Time, categoryCode []model1.CategoryCode, promulgateDateFrom *time.Time, promulgateDateTo *time.Time) (*model1.LawFacets, error) {
+	params := lawsParams(lawID, lawNum, lawTitle, lawTitleKana, lawType, asof, categoryCode, promulgateDateFrom, promulgateDateTo)
+	return r.Resolver.lawFacets(ctx, params)
 }
 
 // Revisions is the resolver for the revisions field.
-func (r *queryResolver) Revisions(ctx context.Context, lawID string, lawTitle *string, lawTitleKana *string, amendmentLawID *string, amendmentDateFrom *string, amendmentDateTo *string, categoryCode []model1.CategoryCode, updatedFrom *string, updatedTo *string) (*lawapi.LawRevisionsResponse, error) {
+func (r *queryResolver) Revisions(ctx context.Context, lawID string, lawTitle *string, lawTitleKana *string, amendmentLawID *string, amendmentDateFrom *time.Time, amendmentDateTo *time.Time, categoryCode []model1.CategoryCode, updatedFrom *time.Time, updatedTo *time.Time) (*lawapi.LawRevisionsResponse, error) {
 	params := &lawapi.GetRevisionsParams{}
 
 	if lawTitle != nil {
@@ -100,39 +263,31 @@ func (r *queryResolver) Revisions(ctx context.Context, lawID string, lawTitle *s
 		params.AmendmentLawId = amendmentLawID
 	}
 	if amendmentDateFrom != nil {
-		if t, err := time.Parse("2006-01-02", *amendmentDateFrom); err == nil {
-			date := lawapi.Date(t)
-			params.AmendmentDateFrom = &date
-		}
+		date := lawapi.Date(*amendmentDateFrom)
+		params.AmendmentDateFrom = &date
 	}
 	if amendmentDateTo != nil {
-		if t, err := time.Parse("2006-01-02", *amendmentDateTo); err == nil {
-			date := lawapi.Date(t)
-			params.AmendmentDateTo = &date
-		}
+		date := lawapi.Date(*amendmentDateTo)
+		params.AmendmentDateTo = &date
 	}
 	if len(categoryCode) > 0 {
 		converted := convertCategoryCode(categoryCode)
 		params.CategoryCd = &converted
 	}
 	if updatedFrom != nil {
-		if t, err := time.Parse("2006-01-02", *updatedFrom); err == nil {
-			date := lawapi.Date(t)
-			params.UpdatedFrom = &date
-		}
+		date := lawapi.Date(*updatedFrom)
+		params.UpdatedFrom = &date
 	}
 	if updatedTo != nil {
-		if t, err := time.Parse("2006-01-02", *updatedTo); err == nil {
-			date := lawapi.Date(t)
-			params.UpdatedTo = &date
-		}
+		date := lawapi.Date(*updatedTo)
+		params.UpdatedTo = &date
 	}
 
 	return r.Resolver.client.GetRevisions(lawID, params)
 }
 
 // Keyword is the resolver for the keyword field.
-func (r *queryResolver) Keyword(ctx context.Context, keyword string, lawNum *string, lawType []model1.LawType, asof *string, categoryCode []model1.CategoryCode, promulgateDateFrom *string, promulgateDateTo *string, limit *int, offset *int, sentencesLimit *int) (*lawapi.KeywordResponse, error) {
+func (r *queryResolver) Keyword(ctx context.Context, keyword string, lawNum *string, lawType []model1.LawType, asof *time.Time, categoryCode []model1.CategoryCode, promulgateDateFrom *time.Time, promulgateDateTo *time.Time, limit *int, offset *int, sentencesLimit *int, sort *model1.LawSort, order *model1.SortOrder) (*lawapi.KeywordResponse, error) {
 	params := &lawapi.GetKeywordParams{
 		Keyword: keyword,
 	}
@@ -145,26 +300,20 @@ func (r *queryResolver) Keyword(ctx context.Context, keyword string, lawNum *str
 		params.LawType = &converted
 	}
 	if asof != nil {
-		if t, err := time.Parse("2006-01-02", *asof); err == nil {
-			date := lawapi.Date(t)
-			params.Asof = &date
-		}
+		date := lawapi.Date(*asof)
+		params.Asof = &date
 	}
 	if len(categoryCode) > 0 {
 		converted := convertCategoryCode(categoryCode)
 		params.CategoryCd = &converted
 	}
 	if promulgateDateFrom != nil {
-		if t, err := time.Parse("2006-01-02", *promulgateDateFrom); err == nil {
-			date := lawapi.Date(t)
-			params.PromulgationDateFrom = &date
-		}
+		date := lawapi.Date(*promulgateDateFrom)
+		params.PromulgationDateFrom = &date
 	}
 	if promulgateDateTo != nil {
-		if t, err := time.Parse("2006-01-02", *promulgateDateTo); err == nil {
-			date := lawapi.Date(t)
-			params.PromulgationDateTo = &date
-		}
+		date := lawapi.Date(*promulgateDateTo)
+		params.PromulgationDateTo = &date
 	}
 	if limit != nil {
 		limit32 := int32(*limit)
@@ -179,12 +328,222 @@ func (r *queryResolver) Keyword(ctx context.Context, keyword string, lawNum *str
 		params.SentencesLimit = &limit32
 	}
 
-	return r.Resolver.client.GetKeyword(params)
+	return r.Resolver.searchKeyword(ctx, params, sortKey(sort), sortOrder(order))
+}
+
+// Law is the resolver for the law field.
+func (r *queryResolver) Law(ctx context.Context, id string) (*lawapi.LawItem, error) {
+	return r.Resolver.getLaw(ctx, id)
+}
+
+// LawAsOf is the resolver for the lawAsOf field.
+func (r *queryResolver) LawAsOf(ctx context.Context, lawID string, date time.Time) (*lawapi.LawItem, error) {
+	return r.Resolver.lawAsOf(lawID, date)
+}
+
+// LawBody is the resolver for the lawBody field.
+func (r *queryResolver) LawBody(ctx context.Context, revisionID string) (*lawdata.Law, error) {
+	return r.Resolver.getLawBody(ctx, revisionID)
+}
+
+// Cite is the resolver for the cite field.
+func (r *queryResolver) Cite(ctx context.Context, revisionID string, article *string, style model1.CitationStyle) (string, error) {
+	return r.Resolver.cite(ctx, revisionID, article, style)
+}
+
+// TableOfContents is the resolver for the tableOfContents field.
+func (r *queryResolver) TableOfContents(ctx context.Context, revisionID string) ([]model1.TableOfContentsEntry, error) {
+	return r.Resolver.tableOfContents(ctx, revisionID)
+}
+
+// DocumentMetadata is the resolver for the documentMetadata field.
+func (r *queryResolver) DocumentMetadata(ctx context.Context, revisionID string) (*lawdata.Metadata, error) {
+	law, err := r.Resolver.getLawBody(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	metadata := law.Metadata()
+	return &metadata, nil
+}
+
+// References is the resolver for the references field.
+func (r *queryResolver) References(ctx context.Context, revisionID string) ([]model1.Reference, error) {
+	return r.Resolver.listReferences(ctx, revisionID)
+}
+
+// RelatedLaws is the resolver for the relatedLaws field.
+func (r *queryResolver) RelatedLaws(ctx context.Context, lawID string) ([]model1.RelatedLaw, error) {
+	return r.Resolver.relatedLaws(ctx, lawID)
+}
+
+// SearchInLaw is the resolver for the searchInLaw field.
+func (r *queryResolver) SearchInLaw(ctx context.Context, revisionID string, query string, limit *int) ([]model1.LawSearchMatch, error) {
+	return r.Resolver.searchInLaw(ctx, revisionID, query, limit)
+}
+
+// BulkExport is the resolver for the bulkExport field.
+func (r *queryResolver) BulkExport(ctx context.Context, id string) (*model1.BulkExport, error) {
+	return r.Resolver.getBulkExport(ctx, id)
+}
+
+// EpubBundleStatus is the resolver for the epubBundleStatus field.
+func (r *queryResolver) EpubBundleStatus(ctx context.Context, id string) (*model1.EpubBundle, error) {
+	return r.Resolver.getEpubBundle(ctx, id)
+}
+
+// CompareRevisions is the resolver for the compareRevisions field.
+func (r *queryResolver) CompareRevisions(ctx context.Context, lawID string, from string, to string) (*model1.RevisionComparison, error) {
+	return r.Resolver.compareRevisions(ctx, lawID, from, to)
 }
 
 // Epub is the resolver for the epub field.
-func (r *queryResolver) Epub(ctx context.Context, id string) (*model1.Epub, error) {
-	return r.Resolver.getEpub(ctx, id)
+func (r *queryResolver) Epub(ctx context.Context, id string, articles []string, diffAgainst *string, preset *string, vertical *bool, cover *model1.CoverStyle, font *string, stripFonts *bool, notify *bool, notifyEmail *string, callbackURL *string, priority *model1.JobPriority, converterVersion *string) (*model1.Epub, error) {
+	conv := conversion{Vertical: vertical, Cover: cover}
+	if diffAgainst != nil {
+		conv.DiffAgainst = *diffAgainst
+	}
+	if preset != nil {
+		conv.Preset = *preset
+	}
+	if font != nil {
+		conv.Font = *font
+	}
+	conv.StripFonts = stripFonts != nil && *stripFonts
+	if conv.Font != "" && conv.StripFonts {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "font cannot be combined with stripFonts")
+	}
+	recipient, err := r.Resolver.notificationRecipient(ctx, notify, notifyEmail)
+	if err != nil {
+		return nil, err
+	}
+	callback, err := r.Resolver.callbackTarget(callbackURL)
+	if err != nil {
+		return nil, err
+	}
+	if priority != nil {
+		ctx = contextWithPriority(ctx, convertJobPriority(*priority))
+	}
+	if converterVersion != nil && *converterVersion != "" {
+		if conv.inProcess() {
+			return nil, codedErrorf(model1.ErrorCodeBadUserInput, "converterVersion cannot be combined with diffAgainst, preset, vertical, cover, font, or stripFonts")
+		}
+		ctx = contextWithConverterVersion(ctx, *converterVersion)
+	}
+	var result *model1.Epub
+	if conv.inProcess() {
+		result, err = r.Resolver.getConvertedEpub(ctx, id, conv, articles)
+	} else {
+		result, err = r.Resolver.getEpub(ctx, id, articles)
+	}
+	if err == nil {
+		r.Resolver.recordGeneration(ctx, id, articles, conv)
+		if recipient != "" || callback != "" {
+			r.Resolver.requestNotification(ctx, id, articles, result, recipient, callback)
+		}
+	}
+	return result, err
+}
+
+// Presets is the resolver for the presets field.
+func (r *queryResolver) Presets(ctx context.Context) ([]model1.Preset, error) {
+	return r.Resolver.listPresets(ctx)
+}
+
+// DisplayNames is the resolver for the displayNames field.
+func (r *queryResolver) DisplayNames(ctx context.Context, enum *string, locale *model1.Locale) ([]model1.DisplayName, error) {
+	return displayNames(ctx, enum, locale)
+}
+
+// Categories is the resolver for the categories field.
+func (r *queryResolver) Categories(ctx context.Context) ([]model1.Category, error) {
+	return r.Resolver.categories(ctx)
+}
+
+// Fonts is the resolver for the fonts field.
+func (r *queryResolver) Fonts(ctx context.Context) ([]model1.Font, error) {
+	return r.Resolver.listFonts(), nil
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*model1.Me, error) {
+	return r.Resolver.me(ctx)
+}
+
+// MyBookmarks is the resolver for the myBookmarks field.
+func (r *queryResolver) MyBookmarks(ctx context.Context) ([]model1.Bookmark, error) {
+	return r.Resolver.myBookmarks(ctx)
+}
+
+// MySavedSearches is the resolver for the mySavedSearches field.
+func (r *queryResolver) MySavedSearches(ctx context.Context) ([]model1.SavedSearch, error) {
+	return r.Resolver.mySavedSearches(ctx)
+}
+
+// MyCompilations is the resolver for the myCompilations field.
+func (r *queryResolver) MyCompilations(ctx context.Context) ([]model1.Compilation, error) {
+	return r.Resolver.myCompilations(ctx)
+}
+
+// MyEpubs is the resolver for the myEpubs field.
+func (r *queryResolver) MyEpubs(ctx context.Context, first *int, after *string) (*model1.EpubHistoryPage, error) {
+	return r.Resolver.myEpubs(ctx, first, after)
+}
+
+// EpubJobs is the resolver for the epubJobs field.
+func (r *queryResolver) EpubJobs(ctx context.Context, status *model1.EpubStatus, first *int) ([]model1.EpubJob, error) {
+	return r.Resolver.listEpubJobs(ctx, status, first)
+}
+
+// FailedJobs is the resolver for the failedJobs field.
+func (r *queryResolver) FailedJobs(ctx context.Context, first *int) ([]model1.EpubJob, error) {
+	return r.Resolver.listFailedJobs(ctx, first)
+}
+
+// CorsConfig is the resolver for the corsConfig field.
+func (r *queryResolver) CorsConfig(ctx context.Context) (*model1.CorsConfig, error) {
+	return r.Resolver.getCorsConfig(ctx)
+}
+
+// UsageStats is the resolver for the usageStats field.
+func (r *queryResolver) UsageStats(ctx context.Context, rangeArg *model1.StatsRange, tenant *string) (*model1.UsageStats, error) {
+	statsRange := model1.StatsRangeLast7Days
+	if rangeArg != nil {
+		statsRange = *rangeArg
+	}
+	return r.Resolver.usageStats(ctx, statsRange, tenant)
+}
+
+// Quota is the resolver for the quota field.
+func (r *queryResolver) Quota(ctx context.Context) (*model1.Quota, error) {
+	return getQuota(ctx), nil
+}
+
+// SlowOperations is the resolver for the slowOperations field.
+func (r *queryResolver) SlowOperations(ctx context.Context, first *int) ([]model1.SlowOperation, error) {
+	return r.Resolver.slowOperations(ctx, first)
+}
+
+// UpstreamUsage is the resolver for the upstreamUsage field.
+func (r *queryResolver) UpstreamUsage(ctx context.Context) (*model1.UpstreamUsage, error) {
+	return r.Resolver.upstreamUsage(ctx)
+}
+
+// RecentUpdates is the resolver for the recentUpdates field.
+func (r *queryResolver) RecentUpdates(ctx context.Context, since *time.Time, lawType []model1.LawType, first *int) ([]model1.LawUpdate, error) {
+	var from time.Time
+	if since != nil {
+		from = *since
+	}
+	limit := 50
+	if first != nil {
+		limit = *first
+	}
+	return r.Resolver.ListRecentUpdates(ctx, from, convertLawType(lawType), limit)
+}
+
+// CategoryDisplayName is the resolver for the categoryDisplayName field.
+func (r *revisionInfoResolver) CategoryDisplayName(ctx context.Context, obj *lawapi.RevisionInfo, locale *model1.Locale) (string, error) {
+	return categoryDisplayName(ctx, locale, obj.Category), nil
 }
 
 // LawType is the resolver for the lawType field.
@@ -192,16 +551,31 @@ func (r *revisionInfoResolver) LawType(ctx context.Context, obj *lawapi.Revision
 	return convertLawTypeToModel(obj.LawType), nil
 }
 
+// LawTypeDisplayName is the resolver for the lawTypeDisplayName field.
+func (r *revisionInfoResolver) LawTypeDisplayName(ctx context.Context, obj *lawapi.RevisionInfo, locale *model1.Locale) (*string, error) {
+	return optionalDisplayName(ctx, locale, "LawType", convertLawTypeToModel(obj.LawType)), nil
+}
+
 // AmendmentPromulgateDate is the resolver for the amendmentPromulgateDate field.
 func (r *revisionInfoResolver) AmendmentPromulgateDate(ctx context.Context, obj *lawapi.RevisionInfo) (string, error) {
 	return obj.AmendmentPromulgateDate.String(), nil
 }
 
+// AmendmentPromulgateEraDate is the resolver for the amendmentPromulgateEraDate field.
+func (r *revisionInfoResolver) AmendmentPromulgateEraDate(ctx context.Context, obj *lawapi.RevisionInfo) (*time.Time, error) {
+	return convertDateToEraDate(obj.AmendmentPromulgateDate), nil
+}
+
 // AmendmentEnforcementDate is the resolver for the amendmentEnforcementDate field.
 func (r *revisionInfoResolver) AmendmentEnforcementDate(ctx context.Context, obj *lawapi.RevisionInfo) (string, error) {
 	return obj.AmendmentEnforcementDate.String(), nil
 }
 
+// AmendmentEnforcementEraDate is the resolver for the amendmentEnforcementEraDate field.
+func (r *revisionInfoResolver) AmendmentEnforcementEraDate(ctx context.Context, obj *lawapi.RevisionInfo) (*time.Time, error) {
+	return convertDateToEraDate(obj.AmendmentEnforcementDate), nil
+}
+
 // RepealDate is the resolver for the repealDate field.
 func (r *revisionInfoResolver) RepealDate(ctx context.Context, obj *lawapi.RevisionInfo) (string, error) {
 	return obj.RepealDate.String(), nil
@@ -217,25 +591,87 @@ func (r *revisionInfoResolver) CurrentRevisionStatus(ctx context.Context, obj *l
 	return convertCurrentRevisionStatusToModel(obj.CurrentRevisionStatus), nil
 }
 
+// CurrentRevisionStatusDisplayName is the resolver for the currentRevisionStatusDisplayName field.
+func (r *revisionInfoResolver) CurrentRevisionStatusDisplayName(ctx context.Context, obj *lawapi.RevisionInfo, locale *model1.Locale) (*string, error) {
+	return optionalDisplayName(ctx, locale, "CurrentRevisionStatus", convertCurrentRevisionStatusToModel(obj.CurrentRevisionStatus)), nil
+}
+
 // RepealStatus is the resolver for the repealStatus field.
 func (r *revisionInfoResolver) RepealStatus(ctx context.Context, obj *lawapi.RevisionInfo) (*model1.RepealStatus, error) {
 	return convertRepealStatusToModel(obj.RepealStatus), nil
 }
 
+// RepealStatusDisplayName is the resolver for the repealStatusDisplayName field.
+func (r *revisionInfoResolver) RepealStatusDisplayName(ctx context.Context, obj *lawapi.RevisionInfo, locale *model1.Locale) (*string, error) {
+	return optionalDisplayName(ctx, locale, "RepealStatus", convertRepealStatusToModel(obj.RepealStatus)), nil
+}
+
 // Mission is the resolver for the mission field.
 func (r *revisionInfoResolver) Mission(ctx context.Context, obj *lawapi.RevisionInfo) (*model1.Mission, error) {
 	return convertMissionToModel(obj.Mission), nil
 }
 
+// BulkExport returns BulkExportResolver implementation.
+func (r *Resolver) BulkExport() BulkExportResolver { return &bulkExportResolver{r} }
+
+// Category returns CategoryResolver implementation.
+func (r *Resolver) Category() CategoryResolver { return &categoryResolver{r} }
+
+// CategoryFacet returns CategoryFacetResolver implementation.
+func (r *Resolver) CategoryFacet() CategoryFacetResolver { return &categoryFacetResolver{r} }
+
+// Epub returns EpubResolver implementation.
+func (r *Resolver) Epub() EpubResolver { return &epubResolver{r} }
+
+// EpubBundle returns EpubBundleResolver implementation.
+func (r *Resolver) EpubBundle() EpubBundleResolver { return &epubBundleResolver{r} }
+
+// EpubJob returns EpubJobResolver implementation.
+func (r *Resolver) EpubJob() EpubJobResolver { return &epubJobResolver{r} }
+
+// EraFacet returns EraFacetResolver implementation.
+func (r *Resolver) EraFacet() EraFacetResolver { return &eraFacetResolver{r} }
+
+// KeywordItem returns KeywordItemResolver implementation.
+func (r *Resolver) KeywordItem() KeywordItemResolver { return &keywordItemResolver{r} }
+
+// Law returns LawResolver implementation.
+func (r *Resolver) Law() LawResolver { return &lawResolver{r} }
+
+// LawBody returns LawBodyResolver implementation.
+func (r *Resolver) LawBody() LawBodyResolver { return &lawBodyResolver{r} }
+
 // LawInfo returns LawInfoResolver implementation.
 func (r *Resolver) LawInfo() LawInfoResolver { return &lawInfoResolver{r} }
 
+// LawItem returns LawItemResolver implementation.
+func (r *Resolver) LawItem() LawItemResolver { return &lawItemResolver{r} }
+
+// LawTypeFacet returns LawTypeFacetResolver implementation.
+func (r *Resolver) LawTypeFacet() LawTypeFacetResolver { return &lawTypeFacetResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
 // Query returns QueryResolver implementation.
 func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
 
 // RevisionInfo returns RevisionInfoResolver implementation.
 func (r *Resolver) RevisionInfo() RevisionInfoResolver { return &revisionInfoResolver{r} }
 
+type bulkExportResolver struct{ *Resolver }
+type categoryResolver struct{ *Resolver }
+type categoryFacetResolver struct{ *Resolver }
+type epubResolver struct{ *Resolver }
+type epubBundleResolver struct{ *Resolver }
+type epubJobResolver struct{ *Resolver }
+type eraFacetResolver struct{ *Resolver }
+type keywordItemResolver struct{ *Resolver }
+type lawResolver struct{ *Resolver }
+type lawBodyResolver struct{ *Resolver }
 type lawInfoResolver struct{ *Resolver }
+type lawItemResolver struct{ *Resolver }
+type lawTypeFacetResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
 type revisionInfoResolver struct{ *Resolver }
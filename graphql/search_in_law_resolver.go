@@ -0,0 +1,74 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+const (
+	// maxSearchMatches caps the limit of searchInLaw.
+	maxSearchMatches = 500
+	// maxSearchQuery caps the length of a searchInLaw query in characters.
+	maxSearchQuery = 200
+)
+
+// searchInLaw finds the captions, paragraphs, and items of a law revision
+// containing every term of query, searching the law body server-side so
+// that readers need not download it.
+func (r *Resolver) searchInLaw(ctx context.Context, revisionID, query string, limit *int) ([]model1.LawSearchMatch, error) {
+	n := 100
+	if limit != nil {
+		n = *limit
+	}
+	if n < 1 || n > maxSearchMatches {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "limit must be between 1 and %d", maxSearchMatches)
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "query must not be empty")
+	}
+	if len([]rune(query)) > maxSearchQuery {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "query must be at most %d characters", maxSearchQuery)
+	}
+
+	law, err := r.cachedLawBody(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	found := law.Search(query, n)
+	matches := make([]model1.LawSearchMatch, len(found))
+	for i, match := range found {
+		highlights := make([]model1.SnippetHighlight, len(match.Highlights))
+		for k, h := range match.Highlights {
+			highlights[k] = model1.SnippetHighlight{Start: h.Start, End: h.End}
+		}
+		matches[i] = model1.LawSearchMatch{
+			Provision:      match.Provision,
+			Article:        match.Article,
+			ArticleCaption: optionalString(match.Caption),
+			Paragraph:      optionalString(match.Paragraph),
+			Item:           optionalString(match.Item),
+			Anchor:         match.Anchor,
+			Snippet:        match.Snippet,
+			Highlights:     highlights,
+		}
+	}
+	return matches, nil
+}
+
+// cachedLawBody returns the parsed law body of a revision from bodyCache,
+// fetching it on a miss. The result is shared and must not be modified.
+func (r *Resolver) cachedLawBody(ctx context.Context, revisionID string) (*lawdata.Law, error) {
+	parsed, err := parseLawID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+	return r.bodyCache.get(ctx, parsed.Value, func() (*lawdata.Law, error) {
+		// A stale entry is refreshed after the request has finished.
+		return r.getLawBody(context.WithoutCancel(ctx), parsed.Value)
+	})
+}
@@ -0,0 +1,169 @@
+package graphql
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+)
+
+// NewServer builds the GraphQL HTTP handler with explicit transports:
+// POST, GET, multipart uploads, multipart/mixed responses delivering
+// @defer fragments incrementally, and websockets speaking both graphql-ws
+// and graphql-transport-ws. Keepalive, upload limits, and websocket
+// authentication follow cfg. Errors carry a machine-readable code, and
+// query responses a Cache-Control header from the @cacheControl hints of
+// their fields when served through WithCacheControl. A non-nil allowList
+// restricts operations to its manifest, and a non-nil slowQueries logs
+// slow operations.
+func NewServer(es graphql.ExecutableSchema, cfg *config.Config, allowList *AllowList, slowQueries *SlowQueryLog) *handler.Server {
+	srv := handler.New(es)
+
+	srv.AddTransport(transport.Websocket{
+		Upgrader: websocket.Upgrader{
+			CheckOrigin: websocketOriginChecker(cfg.CORSOrigins),
+		},
+		InitFunc:              WebsocketInitFunc(cfg.GraphQL.WebsocketToken),
+		InitTimeout:           cfg.GraphQL.WebsocketInitTimeout,
+		KeepAlivePingInterval: cfg.GraphQL.WebsocketKeepAlive,
+	})
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	// Before POST, which would also accept requests for incremental
+	// delivery and wait for deferred fragments.
+	srv.AddTransport(multipartMixed{})
+	srv.AddTransport(transport.POST{})
+	srv.AddTransport(transport.MultipartForm{
+		MaxUploadSize: cfg.GraphQL.MaxUploadSize,
+		MaxMemory:     cfg.GraphQL.MaxUploadSize,
+	})
+
+	srv.SetQueryCache(lru.New[*ast.QueryDocument](1000))
+	srv.SetErrorPresenter(presentError)
+
+	srv.Use(Introspection{Mode: cfg.GraphQL.Introspection})
+	srv.Use(CacheControl{PublicIntrospection: cfg.GraphQL.Introspection == "on"})
+	if slowQueries != nil {
+		srv.Use(slowQueries)
+	}
+	srv.Use(extension.AutomaticPersistedQuery{
+		Cache: allowList.Cache(lru.New[string](100)),
+	})
+	if allowList != nil {
+		// After persisted queries, which fill in the text to check.
+		srv.Use(allowList)
+	}
+
+	return srv
+}
+
+// multipartWriteTimeout bounds the writing of each part of a
+// multipart/mixed response. It replaces the server's write timeout, which
+// counts from the request and would cut off fragments deferred for longer.
+const multipartWriteTimeout = 15 * time.Second
+
+// multipartMixed is transport.MultipartMixed with the write deadline
+// extended before each part is written.
+type multipartMixed struct {
+	transport.MultipartMixed
+}
+
+func (t multipartMixed) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	t.MultipartMixed.Do(&partDeadlineWriter{ResponseWriter: w, rc: http.NewResponseController(w)}, r, exec)
+}
+
+// partDeadlineWriter moves the write deadline to multipartWriteTimeout from
+// every write and flush. Writers without deadlines keep the server's.
+type partDeadlineWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (w *partDeadlineWriter) Write(p []byte) (int, error) {
+	_ = w.rc.SetWriteDeadline(time.Now().Add(multipartWriteTimeout))
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *partDeadlineWriter) Flush() {
+	_ = w.rc.SetWriteDeadline(time.Now().Add(multipartWriteTimeout))
+	_ = w.rc.Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *partDeadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Introspection allows schema introspection queries according to Mode: on
+// for every request, admin for requests authenticated by
+// handlers.WithAdminToken, and off for none.
+type Introspection struct {
+	Mode string
+}
+
+var (
+	_ graphql.HandlerExtension        = Introspection{}
+	_ graphql.OperationContextMutator = Introspection{}
+)
+
+func (Introspection) ExtensionName() string {
+	return "Introspection"
+}
+
+func (Introspection) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (i Introspection) MutateOperationContext(ctx context.Context, oc *graphql.OperationContext) *gqlerror.Error {
+	switch i.Mode {
+	case "on":
+		oc.DisableIntrospection = false
+	case "admin":
+		oc.DisableIntrospection = !handlers.IsAdmin(ctx)
+	default:
+		oc.DisableIntrospection = true
+	}
+	return nil
+}
+
+// WebsocketInitFunc returns the hook run on every websocket connection_init
+// message. When token is empty all connections are accepted; otherwise the
+// init payload must carry "Authorization": "Bearer <token>".
+func WebsocketInitFunc(token string) transport.WebsocketInitFunc {
+	if token == "" {
+		return nil
+	}
+	expected := []byte("Bearer " + token)
+	return func(ctx context.Context, payload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		if subtle.ConstantTimeCompare([]byte(payload.Authorization()), expected) != 1 {
+			return ctx, nil, errors.New("unauthorized")
+		}
+		return ctx, nil, nil
+	}
+}
+
+// websocketOriginChecker applies the CORS allow-list to websocket upgrades.
+// Without an allow-list the upgrader's same-origin check is used.
+func websocketOriginChecker(allowedOrigins []string) func(r *http.Request) bool {
+	if len(allowedOrigins) == 0 {
+		return nil
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		// Non-browser clients do not send an Origin header.
+		return origin == "" || handlers.IsOriginAllowed(origin, allowedOrigins)
+	}
+}
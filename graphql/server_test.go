@@ -0,0 +1,46 @@
+package graphql_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+func TestMultipartOutlivesWriteTimeout(t *testing.T) {
+	s := testsupport.NewServer(t)
+	// The response starts after the server's write timeout has passed, as
+	// when a deferred fragment resolves slowly.
+	const writeTimeout = 200 * time.Millisecond
+	slow := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(writeTimeout + 100*time.Millisecond)
+		s.API.Handler.ServeHTTP(w, r)
+	}))
+	slow.Config.WriteTimeout = writeTimeout
+	slow.Start()
+	t.Cleanup(slow.Close)
+
+	body := `{"query":"{ ... @defer { lawBody(revisionId: \"` + constitution + `\") { lawTitle } } }"}`
+	req, err := http.NewRequest(http.MethodPost, slow.URL+"/graphql", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "multipart/mixed")
+	resp, err := slow.Client().Do(req)
+	if err != nil {
+		t.Fatalf("multipart request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read multipart response: %v", err)
+	}
+	if !strings.Contains(string(data), "日本国憲法") {
+		t.Errorf("multipart response = %s, want the deferred law title", data)
+	}
+}
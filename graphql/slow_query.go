@@ -0,0 +1,211 @@
+package graphql
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+)
+
+const (
+	// slowOperationsKept is how many recent slow operations are kept for
+	// the slowOperations query.
+	slowOperationsKept = 100
+	// slowFieldsKept and slowFieldsLogged bound the field timings kept and
+	// logged for each slow operation.
+	slowFieldsKept   = 10
+	slowFieldsLogged = 5
+)
+
+// SlowQueryLog is a handler extension that times the resolvers of every
+// query and mutation, and logs and keeps those operations that take longer
+// than a threshold. A nil SlowQueryLog does nothing.
+type SlowQueryLog struct {
+	threshold time.Duration
+
+	mu sync.Mutex
+	// recent is a ring of the latest slow operations; next is where the
+	// following one goes.
+	recent []slowOperation
+	next   int
+}
+
+var (
+	_ graphql.HandlerExtension    = &SlowQueryLog{}
+	_ graphql.ResponseInterceptor = &SlowQueryLog{}
+	_ graphql.FieldInterceptor    = &SlowQueryLog{}
+)
+
+// slowOperation is a logged operation with its slowest fields.
+type slowOperation struct {
+	name     string
+	opType   string
+	query    string
+	start    time.Time
+	duration time.Duration
+	fields   []fieldTiming
+}
+
+type fieldTiming struct {
+	path     string
+	duration time.Duration
+}
+
+// operationTrace collects the field timings of one operation.
+type operationTrace struct {
+	mu     sync.Mutex
+	fields []fieldTiming
+}
+
+type operationTraceKey struct{}
+
+// NewSlowQueryLog returns a log of operations slower than threshold, or nil
+// when threshold is zero.
+func NewSlowQueryLog(threshold time.Duration) *SlowQueryLog {
+	if threshold <= 0 {
+		return nil
+	}
+	return &SlowQueryLog{threshold: threshold}
+}
+
+func (l *SlowQueryLog) ExtensionName() string {
+	return "SlowQueryLog"
+}
+
+func (l *SlowQueryLog) Validate(graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (l *SlowQueryLog) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	if l == nil || !graphql.HasOperationContext(ctx) {
+		return next(ctx)
+	}
+	oc := graphql.GetOperationContext(ctx)
+	if oc.Operation == nil || oc.Operation.Operation == ast.Subscription {
+		return next(ctx)
+	}
+
+	trace := &operationTrace{}
+	resp := next(context.WithValue(ctx, operationTraceKey{}, trace))
+	start := oc.Stats.OperationStart
+	if start.IsZero() {
+		return resp
+	}
+	if duration := time.Since(start); duration >= l.threshold {
+		l.record(slowOperation{
+			name:     oc.Operation.Name,
+			opType:   string(oc.Operation.Operation),
+			query:    oc.RawQuery,
+			start:    start,
+			duration: duration,
+			fields:   trace.slowest(slowFieldsKept),
+		})
+	}
+	return resp
+}
+
+func (l *SlowQueryLog) InterceptField(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+	trace, _ := ctx.Value(operationTraceKey{}).(*operationTrace)
+	fc := graphql.GetFieldContext(ctx)
+	// Fields read from a struct take no time worth reporting.
+	if trace == nil || fc == nil || !fc.IsResolver {
+		return next(ctx)
+	}
+	start := time.Now()
+	res, err := next(ctx)
+	trace.add(fieldTiming{path: fc.Path().String(), duration: time.Since(start)})
+	return res, err
+}
+
+// record logs a slow operation and keeps it for slowOperations.
+func (l *SlowQueryLog) record(op slowOperation) {
+	name := op.name
+	if name == "" {
+		name = "anonymous"
+	}
+	var fields []string
+	for _, field := range op.fields[:min(len(op.fields), slowFieldsLogged)] {
+		fields = append(fields, fmt.Sprintf("%s %v", field.path, field.duration.Round(time.Millisecond)))
+	}
+	log.Printf("Slow GraphQL %s %s took %v; slowest fields: %s", op.opType, name, op.duration.Round(time.Millisecond), strings.Join(fields, ", "))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.recent) < slowOperationsKept {
+		l.recent = append(l.recent, op)
+		return
+	}
+	l.recent[l.next] = op
+	l.next = (l.next + 1) % slowOperationsKept
+}
+
+// slowest returns up to first of the kept operations, slowest first.
+func (l *SlowQueryLog) slowest(first int) []slowOperation {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	ops := slices.Clone(l.recent)
+	l.mu.Unlock()
+	slices.SortFunc(ops, func(a, b slowOperation) int { return cmp.Compare(b.duration, a.duration) })
+	return ops[:min(len(ops), first)]
+}
+
+func (t *operationTrace) add(field fieldTiming) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fields = append(t.fields, field)
+}
+
+// slowest returns up to n field timings, slowest first.
+func (t *operationTrace) slowest(n int) []fieldTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	slices.SortFunc(t.fields, func(a, b fieldTiming) int { return cmp.Compare(b.duration, a.duration) })
+	return slices.Clone(t.fields[:min(len(t.fields), n)])
+}
+
+// slowOperations lists the slowest recent operations for admins.
+func (r *Resolver) slowOperations(ctx context.Context, first *int) ([]model1.SlowOperation, error) {
+	if !handlers.IsAdmin(ctx) {
+		return nil, errAdminRequired
+	}
+	limit := 20
+	if first != nil {
+		limit = *first
+	}
+	if limit < 1 || limit > slowOperationsKept {
+		return nil, codedErrorf(model1.ErrorCodeBadUserInput, "first must be between 1 and %d", slowOperationsKept)
+	}
+
+	result := []model1.SlowOperation{}
+	for _, op := range r.slowQueries.slowest(limit) {
+		item := model1.SlowOperation{
+			OperationName: optionalString(op.name),
+			OperationType: op.opType,
+			Query:         op.query,
+			StartedAt:     op.start.UTC().Format(time.RFC3339),
+			DurationMs:    milliseconds(op.duration),
+			Fields:        make([]model1.FieldTiming, len(op.fields)),
+		}
+		for i, field := range op.fields {
+			item.Fields[i] = model1.FieldTiming{Path: field.path, DurationMs: milliseconds(field.duration)}
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
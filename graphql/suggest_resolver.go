@@ -0,0 +1,161 @@
+package graphql
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawindex"
+)
+
+// maxSuggestions caps the limit of suggestLaws.
+const maxSuggestions = 50
+
+// newLawIndex returns an empty index, or nil when it is disabled.
+func newLawIndex(interval time.Duration) *lawindex.Index {
+	if interval <= 0 {
+		return nil
+	}
+	return lawindex.New()
+}
+
+// suggestLaws returns laws matching a partially typed title or law number.
+func (r *Resolver) suggestLaws(prefix string, limit *int) ([]model1.LawSuggestion, error) {
+	n, err := r.suggestionLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertSuggestions(r.lawIndex.Suggest(prefix, n)), nil
+}
+
+// lawTitleSuggestions returns laws whose title matches a partially typed
+// query in kanji, kana, or romaji.
+func (r *Resolver) lawTitleSuggestions(query string, limit *int) ([]model1.LawSuggestion, error) {
+	n, err := r.suggestionLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertSuggestions(r.lawIndex.SuggestTitles(query, n)), nil
+}
+
+// suggestionLimit checks that the index is enabled and returns the limit
+// of a suggestion query.
+func (r *Resolver) suggestionLimit(limit *int) (int, error) {
+	if r.lawIndex == nil {
+		return 0, codedErrorf(model1.ErrorCodeNotConfigured, "law suggestions are not configured: LAW_INDEX_INTERVAL is 0")
+	}
+	n := 10
+	if limit != nil {
+		n = *limit
+	}
+	if n < 1 || n > maxSuggestions {
+		return 0, codedErrorf(model1.ErrorCodeBadUserInput, "limit must be between 1 and %d", maxSuggestions)
+	}
+	return n, nil
+}
+
+func convertSuggestions(entries []lawindex.Entry) []model1.LawSuggestion {
+	suggestions := make([]model1.LawSuggestion, len(entries))
+	for i, entry := range entries {
+		suggestions[i] = model1.LawSuggestion{
+			LawID:     entry.LawID,
+			LawNum:    entry.LawNum,
+			Title:     entry.Title,
+			TitleKana: entry.TitleKana,
+			Abbrev:    entry.Abbrev,
+		}
+	}
+	return suggestions
+}
+
+// SyncLawIndex rebuilds the law index from the whole e-Gov law list and
+// returns the number of laws indexed. The index is kept as is when e-Gov
+// fails part way.
+func (r *Resolver) SyncLawIndex(ctx context.Context) (int, error) {
+	if r.lawIndex == nil {
+		return 0, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	limit := int32(maxSortedResults)
+	first, err := r.client.GetLaws(&lawapi.GetLawsParams{Limit: &limit})
+	if err != nil {
+		return 0, upstreamError(err)
+	}
+	items := first.Laws
+	if len(first.Laws) > 0 {
+		// The pages after the first, which tells the total, are fetched
+		// concurrently.
+		rest, err := fetchPages(ctx, r.upstream, r.pageConcurrency, maxSortedResults, len(first.Laws), int(first.TotalCount), func(ctx context.Context, offset int32) ([]lawapi.LawItem, error) {
+			resp, err := r.client.GetLaws(&lawapi.GetLawsParams{Limit: &limit, Offset: &offset})
+			if err != nil {
+				return nil, upstreamError(err)
+			}
+			return resp.Laws, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		items = append(slices.Clip(items), rest...)
+	}
+
+	var entries []lawindex.Entry
+	for _, item := range items {
+		if entry, ok := indexEntry(item); ok {
+			entries = append(entries, entry)
+		}
+	}
+	r.lawIndex.Replace(entries)
+	return len(entries), nil
+}
+
+// RunLawIndexSync builds the law index now and then every interval until
+// ctx is canceled.
+func (r *Resolver) RunLawIndexSync(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		count, err := r.SyncLawIndex(ctx)
+		if err != nil {
+			log.Printf("Law index sync failed: %v", err)
+		} else {
+			log.Printf("Law index synced %d laws", count)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// indexEntry returns the index entry of a law in the law list.
+func indexEntry(item lawapi.LawItem) (lawindex.Entry, bool) {
+	revision := item.CurrentRevisionInfo
+	if revision == nil {
+		revision = item.RevisionInfo
+	}
+	if item.LawInfo == nil || revision == nil {
+		return lawindex.Entry{}, false
+	}
+	var lawType lawapi.LawType
+	if revision.LawType != nil {
+		lawType = *revision.LawType
+	} else if item.LawInfo.LawType != nil {
+		lawType = *item.LawInfo.LawType
+	}
+	return lawindex.Entry{
+		LawID:     item.LawInfo.LawId,
+		LawNum:    item.LawInfo.LawNum,
+		Title:     revision.LawTitle,
+		TitleKana: revision.LawTitleKana,
+		Abbrev:    revision.Abbrev,
+		LawType:   string(lawType),
+	}, true
+}
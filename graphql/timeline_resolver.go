@@ -0,0 +1,104 @@
+package graphql
+
+import (
+	"sort"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+// lawRevisions lists every revision of a law known to e-Gov.
+func (r *Resolver) lawRevisions(lawID string) (*lawapi.LawRevisionsResponse, error) {
+	resp, err := r.client.GetRevisions(lawID, &lawapi.GetRevisionsParams{})
+	if err != nil {
+		return nil, upstreamError(err)
+	}
+	return resp, nil
+}
+
+// lawTimeline assembles the events of a law from its revisions: the
+// promulgation, the enforcement of the revision enacting the law and of
+// each amendment, and a suspension or repeal, sorted by date.
+func (r *Resolver) lawTimeline(item *lawapi.LawItem) ([]model1.TimelineEvent, error) {
+	if item.LawInfo == nil || item.LawInfo.LawId == "" {
+		return nil, nil
+	}
+	resp, err := r.lawRevisions(item.LawInfo.LawId)
+	if err != nil {
+		return nil, err
+	}
+	return timelineEvents(resp, r.clock.Now()), nil
+}
+
+// timelineEvents lists the events of a revision history in chronological
+// order; enforcements dated after now are scheduled.
+func timelineEvents(resp *lawapi.LawRevisionsResponse, now time.Time) []model1.TimelineEvent {
+	var events []model1.TimelineEvent
+	if date, ok := eventDate(resp.LawInfo.PromulgationDate); ok {
+		events = append(events, model1.PromulgationEvent{Kind: model1.TimelineEventKindPromulgation, Date: date, LawNum: resp.LawInfo.LawNum})
+	}
+
+	// A suspension or repeal is repeated on the revisions after it.
+	ended := make(map[model1.RepealStatus]bool)
+	for _, revision := range resp.Revisions {
+		revisionID := optionalString(revision.LawRevisionId)
+		if date, ok := eventDate(revision.AmendmentEnforcementDate); ok {
+			scheduled := date.After(now) ||
+				revision.CurrentRevisionStatus != nil && *revision.CurrentRevisionStatus == lawapi.CurrentRevisionStatusUnenforced
+			if revision.Mission != nil && *revision.Mission == lawapi.MissionNew {
+				events = append(events, model1.EnforcementEvent{
+					Kind:       model1.TimelineEventKindEnforcement,
+					Date:       date,
+					RevisionID: revisionID,
+					Scheduled:  scheduled,
+				})
+			} else {
+				events = append(events, model1.AmendmentEvent{
+					Kind:                    model1.TimelineEventKindAmendment,
+					Date:                    date,
+					RevisionID:              revisionID,
+					AmendmentLawID:          revision.AmendmentLawId,
+					AmendmentLawTitle:       revision.AmendmentLawTitle,
+					AmendmentLawNum:         revision.AmendmentLawNum,
+					AmendmentPromulgateDate: convertDateToEraDate(revision.AmendmentPromulgateDate),
+					Scheduled:               scheduled,
+				})
+			}
+		}
+
+		status := convertRepealStatusToModel(revision.RepealStatus)
+		date, ok := eventDate(revision.RepealDate)
+		if status == nil || *status == model1.RepealStatusNone || !ok || ended[*status] {
+			continue
+		}
+		ended[*status] = true
+		if *status == model1.RepealStatusSuspend {
+			events = append(events, model1.SuspensionEvent{Kind: model1.TimelineEventKindSuspension, Date: date})
+		} else {
+			events = append(events, model1.RepealEvent{Kind: model1.TimelineEventKindRepeal, Date: date, Status: *status})
+		}
+	}
+
+	// Events of a day keep the order of their kinds, so that a law is
+	// promulgated before it is enforced.
+	order := make(map[model1.TimelineEventKind]int, len(model1.AllTimelineEventKind))
+	for i, kind := range model1.AllTimelineEventKind {
+		order[kind] = i
+	}
+	sort.SliceStable(events, func(i, k int) bool {
+		a, b := events[i], events[k]
+		if !a.GetDate().Equal(b.GetDate()) {
+			return a.GetDate().Before(b.GetDate())
+		}
+		return order[a.GetKind()] < order[b.GetKind()]
+	})
+	return events
+}
+
+// eventDate returns a date reported by e-Gov, and false when it is missing.
+func eventDate(d lawapi.Date) (time.Time, bool) {
+	t := time.Time(d)
+	return t, !t.IsZero()
+}
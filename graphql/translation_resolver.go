@@ -0,0 +1,32 @@
+package graphql
+
+import (
+	lawapi "go.ngs.io/jplaw-api-v2"
+)
+
+// TitleEn returns the English title of a law from the translation table, or
+// an empty string when none is known.
+func (r *Resolver) TitleEn(item *lawapi.LawItem) string {
+	if item == nil || item.LawInfo == nil {
+		return ""
+	}
+	return r.titles.TitleEn(item.LawInfo.LawId, item.LawInfo.LawNum)
+}
+
+// titleEn looks up the English title for a law ID, revision ID, or law
+// number as accepted by the epub query.
+func (r *Resolver) titleEn(id, lawNum string) string {
+	lawID, ok := lawIDOf(id)
+	if !ok {
+		lawID, lawNum = "", id
+	}
+	return r.titles.TitleEn(lawID, lawNum)
+}
+
+// optionalString returns nil for an empty string.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+const (
+	// defaultUpdatesWindow is the lookback when no start time is given.
+	defaultUpdatesWindow = 7 * 24 * time.Hour
+	maxRecentUpdates     = 500
+	updatesPageSize      = 100
+)
+
+// jst is the time zone of promulgation dates.
+var jst = time.FixedZone("JST", 9*60*60)
+
+// ListRecentUpdates returns laws promulgated on or after the day of since,
+// newest first, for use outside GraphQL such as the Atom feed. A zero since
+// covers the last seven days.
+func (r *Resolver) ListRecentUpdates(ctx context.Context, since time.Time, lawTypes []lawapi.LawType, limit int) ([]model1.LawUpdate, error) {
+	if since.IsZero() {
+		since = r.clock.Now().Add(-defaultUpdatesWindow)
+	}
+	if limit > maxRecentUpdates {
+		limit = maxRecentUpdates
+	}
+	if limit <= 0 {
+		return []model1.LawUpdate{}, nil
+	}
+
+	// Promulgation dates have day precision; truncating also keeps the
+	// upstream cache key stable during the day.
+	year, month, day := since.In(jst).Date()
+	from := lawapi.Date(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+
+	pageSize := int32(updatesPageSize)
+	getPage := func(ctx context.Context, offset int32) (*lawapi.LawsResponse, error) {
+		params := &lawapi.GetLawsParams{
+			PromulgationDateFrom: &from,
+			Limit:                &pageSize,
+			Offset:               &offset,
+		}
+		if len(lawTypes) > 0 {
+			params.LawType = &lawTypes
+		}
+		return r.getLaws(ctx, params)
+	}
+	first, err := getPage(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	items := first.Laws
+	if len(first.Laws) > 0 {
+		// The pages after the first, which tells the total, are fetched
+		// concurrently.
+		end := int(min(first.TotalCount, maxRecentUpdates))
+		rest, err := fetchPages(ctx, r.upstream, r.pageConcurrency, updatesPageSize, len(first.Laws), end, func(ctx context.Context, offset int32) ([]lawapi.LawItem, error) {
+			resp, err := getPage(ctx, offset)
+			if err != nil {
+				return nil, err
+			}
+			return resp.Laws, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(slices.Clip(items), rest...)
+	}
+
+	updates := make([]model1.LawUpdate, 0, len(items))
+	for i := range items {
+		item := &items[i]
+		if item.LawInfo == nil {
+			continue
+		}
+		updates = append(updates, model1.LawUpdate{
+			Kind:             updateKind(item),
+			PromulgationDate: item.LawInfo.PromulgationDate.String(),
+			Law:              item,
+		})
+	}
+	sort.SliceStable(updates, func(i, k int) bool {
+		if updates[i].PromulgationDate != updates[k].PromulgationDate {
+			return updates[i].PromulgationDate > updates[k].PromulgationDate
+		}
+		return updates[i].Law.LawInfo.LawId < updates[k].Law.LawInfo.LawId
+	})
+	if len(updates) > limit {
+		updates = updates[:limit]
+	}
+	return updates, nil
+}
+
+// updateKind tells amending acts, whose mission is Partial, from new laws.
+func updateKind(item *lawapi.LawItem) model1.LawUpdateKind {
+	revision := item.RevisionInfo
+	if revision == nil {
+		revision = item.CurrentRevisionInfo
+	}
+	if revision != nil && revision.Mission != nil && *revision.Mission == lawapi.MissionPartial {
+		return model1.LawUpdateKindAmendment
+	}
+	return model1.LawUpdateKindNewLaw
+}
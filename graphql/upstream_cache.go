@@ -0,0 +1,202 @@
+package graphql
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	lawapi "go.ngs.io/jplaw-api-v2"
+)
+
+// upstreamCache caches e-Gov API responses with stale-while-revalidate
+// semantics: fresh entries are served directly, stale entries are served
+// while a background fetch replaces them, and expired entries are fetched
+// before answering. A nil cache fetches every time.
+type upstreamCache[T any] struct {
+	entries *lru.LRU[*cacheEntry[T]]
+	// ttl is how long an entry is fresh; staleTTL is how much longer it
+	// may be served while refreshing.
+	ttl      time.Duration
+	staleTTL time.Duration
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+type cacheEntry[T any] struct {
+	value     T
+	fetchedAt time.Time
+}
+
+// newUpstreamCache returns nil when ttl is not positive.
+func newUpstreamCache[T any](size int, ttl, staleTTL time.Duration) *upstreamCache[T] {
+	if ttl <= 0 {
+		return nil
+	}
+	return &upstreamCache[T]{
+		entries:    lru.New[*cacheEntry[T]](size),
+		ttl:        ttl,
+		staleTTL:   staleTTL,
+		refreshing: make(map[string]bool),
+	}
+}
+
+func (c *upstreamCache[T]) get(ctx context.Context, key string, fetch func() (T, error)) (T, error) {
+	if c == nil {
+		return fetch()
+	}
+
+	if entry, ok := c.entries.Get(ctx, key); ok {
+		age := time.Since(entry.fetchedAt)
+		if age < c.ttl {
+			return entry.value, nil
+		}
+		if age < c.ttl+c.staleTTL {
+			c.refresh(key, fetch)
+			return entry.value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	c.entries.Add(ctx, key, &cacheEntry[T]{value: value, fetchedAt: time.Now()})
+	return value, nil
+}
+
+// refresh fetches key in the background unless a refresh is already
+// running. Failures keep the stale entry so the next request retries.
+func (c *upstreamCache[T]) refresh(key string, fetch func() (T, error)) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		value, err := fetch()
+		if err != nil {
+			log.Printf("Failed to refresh cached response %s: %v", key, err)
+			return
+		}
+		// The request that triggered the refresh may be gone.
+		c.entries.Add(context.Background(), key, &cacheEntry[T]{value: value, fetchedAt: time.Now()})
+	}()
+}
+
+// getLaws lists laws through the law-list cache.
+func (r *Resolver) getLaws(ctx context.Context, params *lawapi.GetLawsParams) (*lawapi.LawsResponse, error) {
+	return r.lawsCache.get(ctx, lawsCacheKey(params), func() (*lawapi.LawsResponse, error) {
+		resp, err := r.client.GetLaws(params)
+		return resp, upstreamError(err)
+	})
+}
+
+// getKeyword runs a keyword search through the search cache.
+func (r *Resolver) getKeyword(ctx context.Context, params *lawapi.GetKeywordParams) (*lawapi.KeywordResponse, error) {
+	return r.keywordCache.get(ctx, keywordCacheKey(params), func() (*lawapi.KeywordResponse, error) {
+		resp, err := r.client.GetKeyword(params)
+		return resp, upstreamError(err)
+	})
+}
+
+// lawsCacheKey normalizes law-list parameters so that equivalent queries
+// share an entry regardless of filter order.
+func lawsCacheKey(params *lawapi.GetLawsParams) string {
+	query := url.Values{}
+	addString(query, "lawId", params.LawId)
+	addString(query, "lawNum", params.LawNum)
+	addString(query, "lawTitle", params.LawTitle)
+	addString(query, "lawTitleKana", params.LawTitleKana)
+	if params.LawType != nil {
+		addSet(query, "lawType", lawTypeStrings(*params.LawType))
+	}
+	addDate(query, "asof", params.Asof)
+	if params.CategoryCd != nil {
+		addSet(query, "categoryCd", categoryCdStrings(*params.CategoryCd))
+	}
+	addDate(query, "promulgationDateFrom", params.PromulgationDateFrom)
+	addDate(query, "promulgationDateTo", params.PromulgationDateTo)
+	addInt(query, "limit", params.Limit)
+	addInt(query, "offset", params.Offset)
+	return "laws?" + query.Encode()
+}
+
+// keywordCacheKey normalizes keyword search parameters.
+func keywordCacheKey(params *lawapi.GetKeywordParams) string {
+	query := url.Values{}
+	query.Set("keyword", params.Keyword)
+	addString(query, "lawNum", params.LawNum)
+	if params.LawType != nil {
+		addSet(query, "lawType", lawTypeStrings(*params.LawType))
+	}
+	addDate(query, "asof", params.Asof)
+	if params.CategoryCd != nil {
+		addSet(query, "categoryCd", categoryCdStrings(*params.CategoryCd))
+	}
+	addDate(query, "promulgationDateFrom", params.PromulgationDateFrom)
+	addDate(query, "promulgationDateTo", params.PromulgationDateTo)
+	addInt(query, "limit", params.Limit)
+	addInt(query, "offset", params.Offset)
+	addInt(query, "sentencesLimit", params.SentencesLimit)
+	return "keyword?" + query.Encode()
+}
+
+func addString(query url.Values, name string, value *string) {
+	if value != nil {
+		query.Set(name, *value)
+	}
+}
+
+func addDate(query url.Values, name string, value *lawapi.Date) {
+	if value != nil {
+		query.Set(name, value.String())
+	}
+}
+
+func addInt(query url.Values, name string, value *int32) {
+	if value != nil {
+		query.Set(name, strconv.FormatInt(int64(*value), 10))
+	}
+}
+
+// addSet adds values sorted and without duplicates.
+func addSet(query url.Values, name string, values []string) {
+	sort.Strings(values)
+	for i, v := range values {
+		if i > 0 && v == values[i-1] {
+			continue
+		}
+		query.Add(name, v)
+	}
+}
+
+func lawTypeStrings(types []lawapi.LawType) []string {
+	result := make([]string, 0, len(types))
+	for _, t := range types {
+		result = append(result, string(t))
+	}
+	return result
+}
+
+func categoryCdStrings(codes []lawapi.CategoryCd) []string {
+	result := make([]string, 0, len(codes))
+	for _, c := range codes {
+		result = append(result, string(c))
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+)
+
+// upstreamUsage reports the calls made to the e-Gov API for admins.
+func (r *Resolver) upstreamUsage(ctx context.Context) (*model1.UpstreamUsage, error) {
+	if !handlers.IsAdmin(ctx) {
+		return nil, errAdminRequired
+	}
+	usage := r.upstream.Usage()
+	result := &model1.UpstreamUsage{
+		RateLimit:     usage.Limit,
+		WindowSeconds: int(usage.Window / time.Second),
+		Used:          usage.Used,
+		Throttled:     usage.Throttled,
+		Endpoints:     []model1.UpstreamEndpoint{},
+	}
+	if usage.Limit > 0 {
+		result.Remaining = &usage.Remaining
+	}
+	if !usage.ResetsAt.IsZero() {
+		resetsAt := usage.ResetsAt.UTC().Format(time.RFC3339)
+		result.ResetsAt = &resetsAt
+	}
+	if !usage.LastThrottledAt.IsZero() {
+		lastThrottledAt := usage.LastThrottledAt.UTC().Format(time.RFC3339)
+		result.LastThrottledAt = &lastThrottledAt
+	}
+	for _, e := range usage.Endpoints {
+		endpoint := model1.UpstreamEndpoint{
+			Name:             e.Name,
+			Calls:            e.Calls,
+			Errors:           e.Errors,
+			Throttled:        e.Throttled,
+			AverageLatencyMs: milliseconds(e.AverageLatency),
+			MaxLatencyMs:     milliseconds(e.MaxLatency),
+			Statuses:         []model1.StatusCount{},
+		}
+		for status, n := range e.Statuses {
+			endpoint.Statuses = append(endpoint.Statuses, model1.StatusCount{Status: status, Count: n})
+		}
+		sort.Slice(endpoint.Statuses, func(i, j int) bool { return endpoint.Statuses[i].Status < endpoint.Statuses[j].Status })
+		result.Endpoints = append(result.Endpoints, endpoint)
+	}
+	return result, nil
+}
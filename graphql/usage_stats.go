@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+const topLawsLimit = 10
+
+var errAdminRequired = errors.New("admin authorization required")
+
+// usageStats aggregates job records created within the range. Requests
+// authenticated with the admin token may read the statistics of every
+// tenant or, with tenantID, of a single one. Tenants may read their own.
+func (r *Resolver) usageStats(ctx context.Context, statsRange model1.StatsRange, tenantID *string) (*model1.UsageStats, error) {
+	// scope is nil when jobs of every tenant are counted.
+	var scope *string
+	caller := tenant.IDFromContext(ctx)
+	switch {
+	case handlers.IsAdmin(ctx):
+		scope = tenantID
+	case caller != "" && (tenantID == nil || *tenantID == caller):
+		scope = &caller
+	default:
+		return nil, errAdminRequired
+	}
+
+	to := r.clock.Now().UTC()
+	var from time.Time
+	switch statsRange {
+	case model1.StatsRangeLast24Hours:
+		from = to.Add(-24 * time.Hour)
+	case model1.StatsRangeLast7Days:
+		from = to.AddDate(0, 0, -7)
+	case model1.StatsRangeLast30Days:
+		from = to.AddDate(0, 0, -30)
+	default:
+		from = to.AddDate(0, 0, -7)
+	}
+
+	records, err := r.jobs.List(ctx, jobs.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	daily := newDailyUsage(from, to)
+	requestsByLaw := make(map[string]int)
+	stats := &model1.UsageStats{
+		From:   from.Format(time.RFC3339),
+		To:     to.Format(time.RFC3339),
+		Tenant: scope,
+	}
+	var totalGeneration time.Duration
+	for _, job := range records {
+		if job.CreatedAt.Before(from) {
+			continue
+		}
+		if jobTenant, _ := tenant.SplitID(job.ID); scope != nil && jobTenant != *scope {
+			continue
+		}
+
+		stats.TotalJobs++
+		stats.CacheHits += job.CacheHits
+		requestsByLaw[job.RevisionID] += 1 + job.CacheHits
+		daily.add(job.CreatedAt, func(d *model1.DailyUsage) { d.Requested++ })
+
+		switch job.Status {
+		case jobs.StatusCompleted:
+			stats.CompletedJobs++
+			totalGeneration += job.Duration(to)
+			daily.add(job.CompletedAt, func(d *model1.DailyUsage) { d.Completed++ })
+		case jobs.StatusFailed, jobs.StatusDeadLetter:
+			stats.FailedJobs++
+			daily.add(job.UpdatedAt, func(d *model1.DailyUsage) { d.Failed++ })
+		case jobs.StatusPending, jobs.StatusProcessing:
+		}
+	}
+
+	if finished := stats.CompletedJobs + stats.FailedJobs; finished > 0 {
+		stats.FailureRate = float64(stats.FailedJobs) / float64(finished)
+	}
+	if stats.CompletedJobs > 0 {
+		average := totalGeneration.Seconds() / float64(stats.CompletedJobs)
+		stats.AverageGenerationSeconds = &average
+	}
+	if requests := stats.TotalJobs + stats.CacheHits; requests > 0 {
+		stats.CacheHitRate = float64(stats.CacheHits) / float64(requests)
+	}
+	stats.TopLaws = topLaws(requestsByLaw, topLawsLimit)
+	stats.Daily = daily.days
+
+	return stats, nil
+}
+
+// dailyUsage holds one zero-filled entry per UTC day in the range.
+type dailyUsage struct {
+	first time.Time
+	days  []model1.DailyUsage
+}
+
+func newDailyUsage(from, to time.Time) *dailyUsage {
+	first := from.Truncate(24 * time.Hour)
+	var days []model1.DailyUsage
+	for day := first; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, model1.DailyUsage{Date: day.Format("2006-01-02")})
+	}
+	return &dailyUsage{first: first, days: days}
+}
+
+// add applies update to the day containing t, ignoring times outside the
+// range.
+func (d *dailyUsage) add(t time.Time, update func(*model1.DailyUsage)) {
+	if t.IsZero() || t.Before(d.first) {
+		return
+	}
+	index := int(t.Sub(d.first) / (24 * time.Hour))
+	if index < len(d.days) {
+		update(&d.days[index])
+	}
+}
+
+func topLaws(requestsByLaw map[string]int, limit int) []model1.LawUsage {
+	result := make([]model1.LawUsage, 0, len(requestsByLaw))
+	for revisionID, requests := range requestsByLaw {
+		result = append(result, model1.LawUsage{RevisionID: revisionID, Requests: requests})
+	}
+	sort.Slice(result, func(i, k int) bool {
+		if result[i].Requests != result[k].Requests {
+			return result[i].Requests > result[k].Requests
+		}
+		return result[i].RevisionID < result[k].RevisionID
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+)
+
+// warmUpRequester identifies warm-up requests in job records and the audit
+// log.
+const warmUpRequester = "warmup"
+
+// warmUpConfig selects the EPUBs to pre-generate.
+type warmUpConfig struct {
+	// lawIDs are law IDs, law numbers, or revision IDs.
+	lawIDs []string
+	// topN adds the most requested whole-law EPUBs from the job store.
+	topN int
+}
+
+// WarmUp starts generation of the configured and most requested EPUBs that
+// are not generated yet, so that their downloads are ready on first
+// request. Only one warm-up runs at a time.
+func (r *Resolver) WarmUp(ctx context.Context) ([]handlers.WarmUpResult, error) {
+	if r.generator.bucketName == "" {
+		return nil, notConfigured("EPUB generation")
+	}
+	if !r.warmUpMu.TryLock() {
+		return nil, handlers.ErrAlreadyRunning
+	}
+	defer r.warmUpMu.Unlock()
+
+	targets, results := r.warmUpTargets(ctx)
+	ctx = handlers.ContextWithClientIP(ctx, warmUpRequester)
+	for _, revisionID := range targets {
+		results = append(results, r.warmUpEpub(ctx, revisionID))
+	}
+	return results, nil
+}
+
+// RunWarmUp calls WarmUp every interval until ctx is canceled.
+func (r *Resolver) RunWarmUp(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			results, err := r.WarmUp(ctx)
+			if err != nil {
+				log.Printf("Warm-up failed: %v", err)
+				continue
+			}
+			log.Printf("Warm-up checked %d EPUBs", len(results))
+		}
+	}
+}
+
+// warmUpEpub starts generation unless the EPUB is already available, so
+// that warm-ups are not counted as cache hits.
+func (r *Resolver) warmUpEpub(ctx context.Context, revisionID string) handlers.WarmUpResult {
+	result := handlers.WarmUpResult{RevisionID: revisionID}
+
+	epub, err := r.GetEpubStatus(ctx, revisionID, nil)
+	if err == nil && epub.Status == model1.EpubStatusCompleted {
+		result.Status = string(epub.Status)
+		return result
+	}
+	if err != nil && !errors.Is(err, jobs.ErrNotFound) {
+		result.Error = err.Error()
+		return result
+	}
+
+	epub, err = r.getEpub(ctx, revisionID, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = string(epub.Status)
+	return result
+}
+
+// warmUpTargets resolves the configured laws to their current revisions
+// and appends the most requested revisions. Laws that cannot be resolved
+// are reported as results.
+func (r *Resolver) warmUpTargets(ctx context.Context) ([]string, []handlers.WarmUpResult) {
+	var targets []string
+	var failures []handlers.WarmUpResult
+	seen := make(map[string]bool)
+	add := func(revisionID string) {
+		if !seen[revisionID] {
+			seen[revisionID] = true
+			targets = append(targets, revisionID)
+		}
+	}
+
+	for _, id := range r.warmUp.lawIDs {
+		revisionID, err := r.currentRevisionID(ctx, id)
+		if err != nil {
+			failures = append(failures, handlers.WarmUpResult{RevisionID: id, Error: err.Error()})
+			continue
+		}
+		add(revisionID)
+	}
+
+	if r.warmUp.topN > 0 {
+		popular, err := r.popularRevisions(ctx, r.warmUp.topN)
+		if err != nil {
+			failures = append(failures, handlers.WarmUpResult{Error: err.Error()})
+		}
+		for _, revisionID := range popular {
+			add(revisionID)
+		}
+	}
+
+	return targets, failures
+}
+
+// currentRevisionID returns id unchanged when it is a revision ID and
+// otherwise looks up the current revision of the law.
+func (r *Resolver) currentRevisionID(ctx context.Context, id string) (string, error) {
+	if strings.Contains(id, "_") {
+		return id, nil
+	}
+
+	law, err := r.getLaw(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up law %s: %v", id, err)
+	}
+	if law == nil {
+		return "", fmt.Errorf("law %s not found", id)
+	}
+	revision := law.CurrentRevisionInfo
+	if revision == nil {
+		revision = law.RevisionInfo
+	}
+	if revision == nil || revision.LawRevisionId == "" {
+		return "", fmt.Errorf("law %s has no revision", id)
+	}
+	return revision.LawRevisionId, nil
+}
+
+// popularRevisions ranks whole-law EPUBs by their requests, counting the
+// generating request and later downloads.
+func (r *Resolver) popularRevisions(ctx context.Context, n int) ([]string, error) {
+	records, err := r.jobs.List(ctx, jobs.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %v", err)
+	}
+
+	requests := make(map[string]int)
+	for _, job := range records {
+		// Warm-up generates with the current converter only.
+		if len(job.Articles) > 0 || job.RevisionID == "" || job.Version != "" {
+			continue
+		}
+		requests[job.RevisionID] += job.Popularity()
+	}
+
+	revisions := make([]string, 0, len(requests))
+	for revisionID := range requests {
+		revisions = append(revisions, revisionID)
+	}
+	sort.Slice(revisions, func(i, k int) bool {
+		if requests[revisions[i]] != requests[revisions[k]] {
+			return requests[revisions[i]] > requests[revisions[k]]
+		}
+		return revisions[i] < revisions[k]
+	})
+	if len(revisions) > n {
+		revisions = revisions[:n]
+	}
+	return revisions, nil
+}
@@ -0,0 +1,235 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+	pb "go.ngs.io/jplaw2epub-web-api/proto/jplaw2epub/v1"
+)
+
+// Backend provides the operations exposed over gRPC. It is implemented by
+// the GraphQL resolver so both APIs share one code path.
+type Backend interface {
+	SearchLaws(ctx context.Context, params *lawapi.GetLawsParams) (*lawapi.LawsResponse, error)
+	GetLaw(ctx context.Context, id string) (*lawapi.LawItem, error)
+	GetEpub(ctx context.Context, id string, articles []string) (*model1.Epub, error)
+	GetEpubStatus(ctx context.Context, id string, articles []string) (*model1.Epub, error)
+}
+
+// Server implements jplaw2epub.v1.LawService.
+type Server struct {
+	pb.UnimplementedLawServiceServer
+	backend Backend
+}
+
+func NewServer(backend Backend) *Server {
+	return &Server{backend: backend}
+}
+
+// NewGRPCServer returns a gRPC server with LawService registered.
+func NewGRPCServer(backend Backend, proxies handlers.TrustedProxies) *grpc.Server {
+	s := grpc.NewServer(grpc.UnaryInterceptor(clientIPInterceptor(proxies)))
+	pb.RegisterLawServiceServer(s, NewServer(backend))
+	return s
+}
+
+func (s *Server) SearchLaws(ctx context.Context, req *pb.SearchLawsRequest) (*pb.SearchLawsResponse, error) {
+	params := &lawapi.GetLawsParams{}
+	if req.GetLawId() != "" {
+		lawID := req.GetLawId()
+		params.LawId = &lawID
+	}
+	if req.GetLawNum() != "" {
+		lawNum, err := jpdate.NormalizeLawNum(req.GetLawNum())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		params.LawNum = &lawNum
+	}
+	if req.GetLawTitle() != "" {
+		lawTitle := req.GetLawTitle()
+		params.LawTitle = &lawTitle
+	}
+	if req.GetLawTitleKana() != "" {
+		lawTitleKana := req.GetLawTitleKana()
+		params.LawTitleKana = &lawTitleKana
+	}
+	if req.GetAsof() != "" {
+		t, err := jpdate.Parse(req.GetAsof())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid asof: %v", err)
+		}
+		date := lawapi.Date(t)
+		params.Asof = &date
+	}
+	if req.GetLimit() < 0 || req.GetOffset() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit and offset must not be negative")
+	}
+	if req.GetLimit() > 0 {
+		limit := req.GetLimit()
+		params.Limit = &limit
+	}
+	if req.GetOffset() > 0 {
+		offset := req.GetOffset()
+		params.Offset = &offset
+	}
+
+	resp, err := s.backend.SearchLaws(ctx, params)
+	if err != nil {
+		log.Printf("gRPC SearchLaws failed: %v", err)
+		return nil, status.Errorf(codes.Unavailable, "failed to search laws: %v", err)
+	}
+
+	laws := make([]*pb.Law, 0, len(resp.Laws))
+	for i := range resp.Laws {
+		laws = append(laws, convertLaw(&resp.Laws[i]))
+	}
+	return &pb.SearchLawsResponse{
+		TotalCount: resp.TotalCount,
+		Count:      resp.Count,
+		NextOffset: resp.NextOffset,
+		Laws:       laws,
+	}, nil
+}
+
+func (s *Server) GetLaw(ctx context.Context, req *pb.GetLawRequest) (*pb.Law, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	law, err := s.backend.GetLaw(ctx, req.GetId())
+	if err != nil {
+		log.Printf("gRPC GetLaw failed for %s: %v", req.GetId(), err)
+		return nil, status.Errorf(codes.Unavailable, "failed to get law: %v", err)
+	}
+	if law == nil {
+		return nil, status.Errorf(codes.NotFound, "law %s not found", req.GetId())
+	}
+	return convertLaw(law), nil
+}
+
+func (s *Server) RequestEpub(ctx context.Context, req *pb.RequestEpubRequest) (*pb.Epub, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	epub, err := s.backend.GetEpub(ctx, req.GetId(), req.GetArticles())
+	if err != nil {
+		return nil, epubError(req.GetId(), err)
+	}
+	return convertEpub(epub), nil
+}
+
+func (s *Server) GetEpubStatus(ctx context.Context, req *pb.GetEpubStatusRequest) (*pb.Epub, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	epub, err := s.backend.GetEpubStatus(ctx, req.GetId(), req.GetArticles())
+	if errors.Is(err, jobs.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "EPUB for %s has not been requested", req.GetId())
+	}
+	if err != nil {
+		return nil, epubError(req.GetId(), err)
+	}
+	return convertEpub(epub), nil
+}
+
+func epubError(id string, err error) error {
+	log.Printf("gRPC EPUB request failed for %s: %v", id, err)
+	return status.Errorf(codes.Internal, "failed to get EPUB: %v", err)
+}
+
+func convertLaw(item *lawapi.LawItem) *pb.Law {
+	law := &pb.Law{}
+	if info := item.LawInfo; info != nil {
+		law.LawId = info.LawId
+		law.LawNum = info.LawNum
+		law.PromulgationDate = info.PromulgationDate.String()
+	}
+	revision := item.RevisionInfo
+	if revision == nil {
+		revision = item.CurrentRevisionInfo
+	}
+	if revision != nil {
+		law.LawRevisionId = revision.LawRevisionId
+		law.LawTitle = revision.LawTitle
+		law.LawTitleKana = revision.LawTitleKana
+		law.Abbrev = revision.Abbrev
+		law.Category = revision.Category
+	}
+	return law
+}
+
+func convertEpub(epub *model1.Epub) *pb.Epub {
+	result := &pb.Epub{
+		Id:       epub.ID,
+		Articles: epub.Articles,
+		Status:   convertEpubStatus(epub.Status),
+	}
+	if epub.SignedURL != nil {
+		result.SignedUrl = *epub.SignedURL
+	}
+	if epub.Size != nil {
+		result.Size = int64(*epub.Size)
+	}
+	if epub.Etag != nil {
+		result.Etag = *epub.Etag
+	}
+	if epub.Error != nil {
+		result.Error = *epub.Error
+	}
+	if epub.Attempts != nil {
+		result.Attempts = int32(*epub.Attempts)
+	}
+	if epub.NextRetryAt != nil {
+		result.NextRetryAt = *epub.NextRetryAt
+	}
+	return result
+}
+
+func convertEpubStatus(s model1.EpubStatus) pb.EpubStatus {
+	switch s {
+	case model1.EpubStatusPending:
+		return pb.EpubStatus_EPUB_STATUS_PENDING
+	case model1.EpubStatusProcessing:
+		return pb.EpubStatus_EPUB_STATUS_PROCESSING
+	case model1.EpubStatusCompleted:
+		return pb.EpubStatus_EPUB_STATUS_COMPLETED
+	case model1.EpubStatusFailed:
+		return pb.EpubStatus_EPUB_STATUS_FAILED
+	default:
+		return pb.EpubStatus_EPUB_STATUS_UNSPECIFIED
+	}
+}
+
+// clientIPInterceptor records the caller's address the same way
+// handlers.WithRealIP does for HTTP requests.
+func clientIPInterceptor(proxies handlers.TrustedProxies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var peerAddr, realIP string
+		var forwardedFor []string
+		if p, ok := peer.FromContext(ctx); ok {
+			peerAddr = p.Addr.String()
+		}
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			forwardedFor = md.Get("x-forwarded-for")
+			if values := md.Get("x-real-ip"); len(values) > 0 {
+				realIP = values[0]
+			}
+		}
+		return handler(handlers.ContextWithClientIP(ctx, proxies.RealIP(peerAddr, forwardedFor, realIP)), req)
+	}
+}
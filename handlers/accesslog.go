@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccessLogFormat selects the format of access log lines.
+type AccessLogFormat string
+
+const (
+	// AccessLogApache is Apache Combined Log Format with the GraphQL
+	// operation and the duration.
+	AccessLogApache AccessLogFormat = "apache"
+	// AccessLogJSON is one JSON object per request, whose field names can
+	// be changed.
+	AccessLogJSON AccessLogFormat = "json"
+	// AccessLogCloud is the structured entry of Google Cloud Logging, with
+	// an httpRequest field.
+	AccessLogCloud AccessLogFormat = "cloud"
+)
+
+// accessLogFields are the default field names of the json format.
+var accessLogFields = []string{
+	"time", "remote_addr", "remote_user", "method", "uri", "protocol",
+	"status", "size", "referer", "user_agent", "duration_us",
+	"graphql_type", "graphql_operation", "graphql_variable_count", "graphql_variables",
+}
+
+// AccessLogOptions configures WithAccessLog.
+type AccessLogOptions struct {
+	Format AccessLogFormat
+	// Output receives one line per request. When nil, lines go to the
+	// standard logger, which prefixes them with the time.
+	Output io.Writer
+	// JSONFields renames fields of the json format, from their default
+	// names to new ones; "-" omits a field.
+	JSONFields map[string]string
+	GraphQL    GraphQLLogOptions
+}
+
+// ValidateJSONFields reports renamed fields that the json format does not
+// have.
+func ValidateJSONFields(fields map[string]string) error {
+	var unknown []string
+	for name := range fields {
+		if !slices.Contains(accessLogFields, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown access log fields %s; fields are %s", strings.Join(unknown, ", "), strings.Join(accessLogFields, ", "))
+	}
+	return nil
+}
+
+// WithAccessLog logs every request in the format and to the output of opts.
+func WithAccessLog(next http.Handler, opts AccessLogOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Extract GraphQL info before processing.
+		graphqlInfo := extractGraphQLInfo(r, opts.GraphQL)
+
+		// Wrap the ResponseWriter to capture status and size.
+		wrapped := &responseWriter{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+		}
+
+		// Process request.
+		next.ServeHTTP(wrapped, r)
+
+		duration := time.Since(start)
+		var line string
+		switch opts.Format {
+		case AccessLogJSON:
+			line = jsonLine(r, wrapped, start, duration, graphqlInfo, opts.JSONFields)
+		case AccessLogCloud:
+			line = cloudLine(r, wrapped, start, duration, graphqlInfo)
+		default:
+			line = apacheLine(r, wrapped, duration, graphqlInfo)
+		}
+		if opts.Output == nil {
+			log.Print(line)
+			return
+		}
+		if _, err := io.WriteString(opts.Output, line+"\n"); err != nil {
+			log.Printf("Failed to write access log: %v", err)
+		}
+	})
+}
+
+// jsonLine formats a request as a JSON object with the default field names
+// replaced by those in rename.
+func jsonLine(r *http.Request, rw *responseWriter, start time.Time, duration time.Duration, graphqlInfo *graphQLInfo, rename map[string]string) string {
+	fields := map[string]interface{}{
+		"time":        start.UTC().Format(time.RFC3339Nano),
+		"remote_addr": RealIP(r),
+		"method":      r.Method,
+		"uri":         r.RequestURI,
+		"protocol":    r.Proto,
+		"status":      rw.status,
+		"size":        rw.size,
+		"duration_us": duration.Microseconds(),
+	}
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		fields["remote_user"] = user
+	}
+	if referer := r.Header.Get("Referer"); referer != "" {
+		fields["referer"] = referer
+	}
+	if userAgent := r.Header.Get("User-Agent"); userAgent != "" {
+		fields["user_agent"] = userAgent
+	}
+	if graphqlInfo != nil {
+		if graphqlInfo.Type != "" {
+			fields["graphql_type"] = graphqlInfo.Type
+		}
+		if graphqlInfo.Name != "" {
+			fields["graphql_operation"] = graphqlInfo.Name
+		}
+		fields["graphql_variable_count"] = graphqlInfo.VarCount
+		if graphqlInfo.Variables != "" {
+			fields["graphql_variables"] = graphqlInfo.Variables
+		}
+	}
+
+	entry := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		if renamed, ok := rename[name]; ok {
+			if renamed == "-" {
+				continue
+			}
+			name = renamed
+		}
+		entry[name] = value
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(data)
+}
+
+// cloudEntry is a structured log entry as Google Cloud Logging reads it
+// from stdout.
+type cloudEntry struct {
+	Severity    string           `json:"severity"`
+	Time        string           `json:"time"`
+	Message     string           `json:"message"`
+	HTTPRequest cloudHTTPRequest `json:"httpRequest"`
+	GraphQL     *cloudGraphQL    `json:"graphql,omitempty"`
+}
+
+// cloudHTTPRequest is the HttpRequest structure of Cloud Logging, in which
+// sizes are decimal strings and the latency is a duration in seconds.
+type cloudHTTPRequest struct {
+	RequestMethod string `json:"requestMethod"`
+	RequestURL    string `json:"requestUrl"`
+	RequestSize   string `json:"requestSize,omitempty"`
+	Status        int    `json:"status"`
+	ResponseSize  string `json:"responseSize"`
+	UserAgent     string `json:"userAgent,omitempty"`
+	RemoteIP      string `json:"remoteIp"`
+	Referer       string `json:"referer,omitempty"`
+	Latency       string `json:"latency"`
+	Protocol      string `json:"protocol"`
+}
+
+type cloudGraphQL struct {
+	OperationType string `json:"operationType,omitempty"`
+	OperationName string `json:"operationName,omitempty"`
+	VariableCount int    `json:"variableCount"`
+	Variables     string `json:"variables,omitempty"`
+}
+
+// cloudLine formats a request as a Cloud Logging entry whose severity
+// follows the status.
+func cloudLine(r *http.Request, rw *responseWriter, start time.Time, duration time.Duration, graphqlInfo *graphQLInfo) string {
+	severity := "INFO"
+	switch {
+	case rw.status >= 500:
+		severity = "ERROR"
+	case rw.status >= 400:
+		severity = "WARNING"
+	}
+	entry := cloudEntry{
+		Severity: severity,
+		Time:     start.UTC().Format(time.RFC3339Nano),
+		Message:  strings.TrimSpace(fmt.Sprintf("%s %s %d %s", r.Method, r.RequestURI, rw.status, graphqlInfo.String())),
+		HTTPRequest: cloudHTTPRequest{
+			RequestMethod: r.Method,
+			RequestURL:    r.RequestURI,
+			Status:        rw.status,
+			ResponseSize:  strconv.Itoa(rw.size),
+			UserAgent:     r.Header.Get("User-Agent"),
+			RemoteIP:      RealIP(r),
+			Referer:       r.Header.Get("Referer"),
+			Latency:       fmt.Sprintf("%.6fs", duration.Seconds()),
+			Protocol:      r.Proto,
+		},
+	}
+	if r.ContentLength > 0 {
+		entry.HTTPRequest.RequestSize = strconv.FormatInt(r.ContentLength, 10)
+	}
+	if graphqlInfo != nil {
+		entry.GraphQL = &cloudGraphQL{
+			OperationType: graphqlInfo.Type,
+			OperationName: graphqlInfo.Name,
+			VariableCount: graphqlInfo.VarCount,
+			Variables:     graphqlInfo.Variables,
+		}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf(`{"severity":"ERROR","message":%q}`, err.Error())
+	}
+	return string(data)
+}
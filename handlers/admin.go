@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// ErrAlreadyRunning is returned when a maintenance task is requested while
+// another run of it is still in progress.
+var ErrAlreadyRunning = errors.New("task already running")
+
+// WithAdminToken marks requests whose Authorization header carries the
+// admin bearer token so that resolvers can allow admin-only queries. An
+// empty token disables admin access.
+func WithAdminToken(next http.Handler, token string) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) == 1 {
+			r = r.WithContext(context.WithValue(r.Context(), adminKey, true))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsAdmin reports whether WithAdminToken authenticated the request.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey).(bool)
+	return admin
+}
+
+// adminTaskHandler runs a maintenance task on POST, for example from Cloud
+// Scheduler, and writes its result as JSON. It must be wrapped with
+// WithAdminToken; requests without the admin token are rejected.
+func adminTaskHandler(name string, run func(ctx context.Context) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !IsAdmin(r.Context()) {
+			writeJSONError(w, http.StatusUnauthorized, "admin authorization required")
+			return
+		}
+
+		result, err := run(r.Context())
+		if errors.Is(err, ErrAlreadyRunning) {
+			writeJSONError(w, http.StatusConflict, name+" already running")
+			return
+		}
+		if err != nil {
+			log.Printf("%s failed: %v", name, err)
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
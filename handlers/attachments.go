@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// AttachmentsHandler proxies law attachments from the e-Gov API and caches
+// them in Cloud Storage under attachments/{revisionId}/{src}.
+type AttachmentsHandler struct {
+	client *lawdata.Client
+	bucket objects.Bucket
+}
+
+// NewAttachmentsHandler returns a handler for the
+// /attachments/{revisionId}/{src...} route. Caching is skipped when bucket
+// is nil.
+func NewAttachmentsHandler(client *lawdata.Client, bucket objects.Bucket) *AttachmentsHandler {
+	return &AttachmentsHandler{client: client, bucket: bucket}
+}
+
+func (h *AttachmentsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	revisionID := r.PathValue("revisionId")
+	src := lawdata.CleanSrc(r.PathValue("src"))
+	if revisionID == "" || src == "" {
+		http.Error(w, "Invalid attachment path", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	objectPath := path.Join("attachments", revisionID, src)
+
+	data, contentType, err := h.readCache(ctx, objectPath)
+	if err != nil {
+		data, contentType, err = h.client.FetchAttachment(ctx, revisionID, src)
+		if errors.Is(err, lawdata.ErrNotFound) {
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to fetch attachment %s: %v", objectPath, err)
+			http.Error(w, "Failed to fetch attachment", http.StatusBadGateway)
+			return
+		}
+		h.writeCache(ctx, objectPath, data, contentType)
+	}
+
+	// Attachments are fixed per revision.
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+func (h *AttachmentsHandler) readCache(ctx context.Context, objectPath string) ([]byte, string, error) {
+	if h.bucket == nil {
+		return nil, "", errors.New("attachment cache disabled")
+	}
+
+	reader, err := h.bucket.NewReader(ctx, objectPath)
+	if err != nil {
+		return nil, "", err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read cached attachment: %v", err)
+	}
+	return data, reader.Attrs.ContentType, nil
+}
+
+func (h *AttachmentsHandler) writeCache(ctx context.Context, objectPath string, data []byte, contentType string) {
+	if h.bucket == nil {
+		return
+	}
+
+	w := h.bucket.NewWriter(ctx, objectPath, objects.WriteOptions{ContentType: contentType})
+	if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+		_ = w.Close()
+		log.Printf("Failed to cache attachment %s: %v", objectPath, err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Failed to cache attachment %s: %v", objectPath, err)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/auth"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// WithUserAuth verifies the OpenID Connect ID token sent as a bearer token
+// and records its user in the request context, for per-user bookmarks,
+// history, and quotas. Bearer values that are not JWTs, such as the admin
+// token, are passed on untouched, and requests without one stay anonymous.
+// Invalid tokens are answered with 401 Unauthorized, as a GraphQL error
+// response with the UNAUTHENTICATED code when graphQL is set. It returns
+// next unchanged when verifier is nil.
+func WithUserAuth(next http.Handler, verifier *auth.Verifier, graphQL bool) http.Handler {
+	if verifier == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.Count(token, ".") != 2 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, err := verifier.Verify(r.Context(), token)
+		if err != nil {
+			status, message, code := http.StatusUnauthorized, err.Error(), "UNAUTHENTICATED"
+			if errors.Is(err, auth.ErrInvalidToken) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			} else {
+				log.Printf("ID token verification failed: %v", err)
+				status, message, code = http.StatusServiceUnavailable, "failed to verify ID token", "UPSTREAM_ERROR"
+			}
+			if graphQL {
+				writeGraphQLError(w, status, message, code, status != http.StatusUnauthorized)
+				return
+			}
+			writeJSONError(w, status, message)
+			return
+		}
+
+		// Signed-in users own their bookmarks like the users tenants name
+		// in X-User-Id.
+		ctx := auth.WithUser(r.Context(), user)
+		ctx = tenant.WithUser(ctx, user.ID())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+)
+
+// CleanupResult reports one cleanup run.
+type CleanupResult struct {
+	// IdleEpubs lists the IDs of the EPUBs deleted because nobody requested
+	// them for too long.
+	IdleEpubs []string `json:"idleEpubs"`
+	// StaleJobs lists the IDs of the unfinished or failed jobs whose status
+	// was deleted.
+	StaleJobs []string `json:"staleJobs"`
+	// ObsoleteObjects counts the deleted artifacts of other converter
+	// versions.
+	ObsoleteObjects int `json:"obsoleteObjects"`
+	// ReclaimedBytes is the total size of the deleted objects.
+	ReclaimedBytes int64    `json:"reclaimedBytes"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// Cleaner deletes generated artifacts that are no longer needed.
+type Cleaner interface {
+	Cleanup(ctx context.Context) (*CleanupResult, error)
+}
+
+// NewCleanupHandler serves POST /admin/cleanup.
+func NewCleanupHandler(cleaner Cleaner) http.HandlerFunc {
+	return adminTaskHandler("Cleanup", func(ctx context.Context) (interface{}, error) {
+		return cleaner.Cleanup(ctx)
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	clientIPKey contextKey = iota
+	adminKey
+	quotaKey
+	clientKey
+)
+
+// TrustedProxies lists the networks of proxies whose X-Forwarded-For and
+// X-Real-IP headers are believed. Headers from other peers are ignored, so
+// clients cannot choose the address they are logged and rate limited by.
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses CIDRs and single addresses.
+func ParseTrustedProxies(values []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(values))
+	for _, v := range values {
+		if prefix, err := netip.ParsePrefix(v); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: expected a CIDR or an IP address", v)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+func (p TrustedProxies) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealIP returns the client address of a connection from peer, which is
+// host:port or, for Unix domain sockets, not an IP address at all. While
+// the connection comes from a trusted proxy, X-Forwarded-For is walked from
+// the right, and the first address not of a trusted proxy is the client.
+// X-Real-IP is used when a trusted proxy sends no X-Forwarded-For. Peers on
+// Unix domain sockets are local proxies and always trusted.
+func (p TrustedProxies) RealIP(peer string, forwardedFor []string, realIP string) string {
+	host := peer
+	if h, _, err := net.SplitHostPort(peer); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(host)
+	if err == nil && !p.trusts(addr) {
+		return addr.Unmap().String()
+	}
+
+	var hops []string
+	for _, header := range forwardedFor {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if len(hops) == 0 {
+		if realIP, err := netip.ParseAddr(strings.TrimSpace(realIP)); err == nil {
+			return realIP.Unmap().String()
+		}
+		return host
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(hops[i])
+		if err != nil {
+			// A malformed entry cannot name the client, so the nearest
+			// proxy stands in for it.
+			if i+1 < len(hops) {
+				return hops[i+1]
+			}
+			return host
+		}
+		if !p.trusts(hop) || i == 0 {
+			return hop.Unmap().String()
+		}
+	}
+	return host
+}
+
+// WithRealIP stores the client address, as RealIP returns it, in the
+// request context for every handler behind it, including the access log,
+// quotas, and resolvers.
+func WithRealIP(next http.Handler, proxies TrustedProxies) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := proxies.RealIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"), r.Header.Get("X-Real-IP"))
+		next.ServeHTTP(w, r.WithContext(ContextWithClientIP(r.Context(), ip)))
+	})
+}
+
+// RealIP returns the client address of a request stored by WithRealIP, or
+// the connection's address, ignoring proxy headers, without it.
+func RealIP(r *http.Request) string {
+	if ip := ClientIPFromContext(r.Context()); ip != "" {
+		return ip
+	}
+	return TrustedProxies(nil).RealIP(r.RemoteAddr, nil, "")
+}
+
+// ContextWithClientIP stores a client address for servers that do not use
+// net/http, such as the gRPC server.
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ClientIPFromContext returns the address stored by WithRealIP.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
@@ -1,84 +1,233 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
-	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-func ParseAllowedOrigins(corsOrigins string) []string {
-	if corsOrigins == "" {
-		corsOrigins = os.Getenv("CORS_ORIGINS")
-	}
-	if corsOrigins == "" {
-		return nil
+// CORSOptions configures the preflight response of a route.
+type CORSOptions struct {
+	Methods       []string
+	Headers       []string
+	ExposeHeaders []string
+	MaxAge        int
+}
+
+// CORSRoute pairs a route pattern with its CORS options.
+type CORSRoute struct {
+	Path    string
+	Options CORSOptions
+}
+
+// DefaultCORSOptions returns the options used by WithCORS and
+// WithCORSHandler.
+func DefaultCORSOptions() CORSOptions {
+	return CORSOptions{
+		Methods:       []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		Headers:       []string{"Content-Type", "Authorization", "X-API-Key", "X-User-Id"},
+		ExposeHeaders: []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"},
+		MaxAge:        3600,
 	}
+}
 
-	origins := strings.Split(corsOrigins, ",")
-	var cleanedOrigins []string
-	for _, origin := range origins {
-		trimmed := strings.TrimSpace(origin)
-		if trimmed != "" {
-			cleanedOrigins = append(cleanedOrigins, trimmed)
-		}
+// DownloadCORSOptions returns the options for document and attachment
+// downloads, which are read-only and revalidated with ETags.
+func DownloadCORSOptions() CORSOptions {
+	return CORSOptions{
+		Methods:       []string{http.MethodGet, http.MethodHead, http.MethodOptions},
+		Headers:       []string{"Accept", "Authorization", "If-None-Match", "If-Range", "Range", "X-API-Key"},
+		ExposeHeaders: []string{"ETag", "Retry-After", "Content-Length", "Content-Range", "Accept-Ranges", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+		MaxAge:        3600,
 	}
-	return cleanedOrigins
 }
 
-func IsOriginAllowed(origin string, allowedOrigins []string) bool {
-	if len(allowedOrigins) == 0 {
-		return false
+// override replaces the fields of o that are set in custom.
+func (o CORSOptions) override(custom CORSOptions) CORSOptions {
+	if custom.Methods != nil {
+		o.Methods = custom.Methods
 	}
-	for _, allowed := range allowedOrigins {
-		if allowed == "*" || allowed == origin {
-			return true
+	if custom.Headers != nil {
+		o.Headers = custom.Headers
+	}
+	if custom.ExposeHeaders != nil {
+		o.ExposeHeaders = custom.ExposeHeaders
+	}
+	if custom.MaxAge > 0 {
+		o.MaxAge = custom.MaxAge
+	}
+	return o
+}
+
+// CORSMux registers routes on a ServeMux behind WithCORSOptions and keeps
+// the options each route was registered with, so that they can be reported
+// as served.
+type CORSMux struct {
+	mux            *http.ServeMux
+	allowedOrigins []string
+	// overrides are the configured options of route patterns.
+	overrides map[string]CORSOptions
+	routes    []CORSRoute
+}
+
+// NewCORSMux returns a CORSMux registering on mux. The fields set in the
+// overrides of a pattern replace those of the options it is registered
+// with.
+func NewCORSMux(mux *http.ServeMux, allowedOrigins []string, overrides map[string]CORSOptions) *CORSMux {
+	return &CORSMux{mux: mux, allowedOrigins: allowedOrigins, overrides: overrides}
+}
+
+// Handle registers handler for pattern with CORS options.
+func (m *CORSMux) Handle(pattern string, handler http.Handler, opts CORSOptions) {
+	opts = opts.override(m.overrides[pattern])
+	m.routes = append(m.routes, CORSRoute{Path: pattern, Options: opts})
+	m.mux.Handle(pattern, WithCORSOptions(handler, m.allowedOrigins, opts))
+}
+
+// Routes returns the registered routes in the order they were registered.
+func (m *CORSMux) Routes() []CORSRoute {
+	return slices.Clone(m.routes)
+}
+
+// Unregistered returns the overridden patterns that no route was
+// registered for, sorted, so that misspelled patterns can be reported.
+func (m *CORSMux) Unregistered() []string {
+	var patterns []string
+	for pattern := range m.overrides {
+		if !slices.ContainsFunc(m.routes, func(route CORSRoute) bool { return route.Path == pattern }) {
+			patterns = append(patterns, pattern)
 		}
 	}
-	return false
+	sort.Strings(patterns)
+	return patterns
 }
 
-func WithCORS(handler http.HandlerFunc, allowedOrigins []string) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
+// originMatcher matches an Origin header against one allow-list entry.
+type originMatcher func(origin string) bool
 
-		if r.Method == http.MethodOptions {
-			if IsOriginAllowed(origin, allowedOrigins) {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-				w.Header().Set("Access-Control-Max-Age", "3600")
+// compileOrigin builds a matcher for an allow-list entry. Entries are "*",
+// an exact origin, a wildcard subdomain pattern such as
+// "https://*.example.com", or a regular expression enclosed in slashes such
+// as "/^https://pr-[0-9]+\.example\.com$/".
+func compileOrigin(entry string) (originMatcher, error) {
+	switch {
+	case entry == "*":
+		return func(string) bool { return true }, nil
+	case len(entry) > 2 && strings.HasPrefix(entry, "/") && strings.HasSuffix(entry, "/"):
+		re, err := regexp.Compile(entry[1 : len(entry)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CORS origin pattern %s: %v", entry, err)
+		}
+		return re.MatchString, nil
+	case strings.Contains(entry, "://*."):
+		prefix, suffix, _ := strings.Cut(entry, "*")
+		return func(origin string) bool {
+			if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+				return false
 			}
-			w.WriteHeader(http.StatusNoContent)
-			return
+			subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+			return subdomain != "" && !strings.ContainsAny(subdomain, "/:@")
+		}, nil
+	default:
+		return func(origin string) bool { return origin == entry }, nil
+	}
+}
+
+// ValidateAllowedOrigins reports the first entry that cannot be compiled.
+func ValidateAllowedOrigins(allowedOrigins []string) error {
+	_, err := compileOrigins(allowedOrigins)
+	return err
+}
+
+func compileOrigins(allowedOrigins []string) ([]originMatcher, error) {
+	matchers := make([]originMatcher, 0, len(allowedOrigins))
+	for _, entry := range allowedOrigins {
+		matcher, err := compileOrigin(entry)
+		if err != nil {
+			return nil, err
 		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
 
-		if IsOriginAllowed(origin, allowedOrigins) {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
+// compileValidOrigins skips invalid entries, which ValidateAllowedOrigins
+// reports at startup.
+func compileValidOrigins(allowedOrigins []string) []originMatcher {
+	matchers := make([]originMatcher, 0, len(allowedOrigins))
+	for _, entry := range allowedOrigins {
+		if matcher, err := compileOrigin(entry); err == nil {
+			matchers = append(matchers, matcher)
 		}
+	}
+	return matchers
+}
 
-		handler(w, r)
+func matchOrigin(origin string, matchers []originMatcher) bool {
+	if origin == "" {
+		return false
+	}
+	for _, matches := range matchers {
+		if matches(origin) {
+			return true
+		}
 	}
+	return false
+}
+
+func IsOriginAllowed(origin string, allowedOrigins []string) bool {
+	if len(allowedOrigins) == 0 {
+		return false
+	}
+	return matchOrigin(origin, compileValidOrigins(allowedOrigins))
+}
+
+func WithCORS(handler http.HandlerFunc, allowedOrigins []string) http.HandlerFunc {
+	return WithCORSOptions(handler, allowedOrigins, DefaultCORSOptions()).ServeHTTP
 }
 
 func WithCORSHandler(handler http.Handler, allowedOrigins []string) http.Handler {
+	return WithCORSOptions(handler, allowedOrigins, DefaultCORSOptions())
+}
+
+// WithCORSOptions applies CORS headers with route-specific methods and
+// headers. Origin patterns are compiled once when the handler is built.
+func WithCORSOptions(handler http.Handler, allowedOrigins []string, opts CORSOptions) http.Handler {
+	matchers := compileValidOrigins(allowedOrigins)
+	methods := strings.Join(opts.Methods, ", ")
+	headers := strings.Join(opts.Headers, ", ")
+	exposeHeaders := strings.Join(opts.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(opts.MaxAge)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
+		allowed := matchOrigin(origin, matchers)
+		if len(matchers) > 0 {
+			// The response differs per origin once patterns are involved.
+			w.Header().Add("Vary", "Origin")
+		}
 
 		if r.Method == http.MethodOptions {
-			if IsOriginAllowed(origin, allowedOrigins) {
+			if allowed {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-				w.Header().Set("Access-Control-Max-Age", "3600")
+				w.Header().Set("Access-Control-Allow-Methods", methods)
+				w.Header().Set("Access-Control-Allow-Headers", headers)
+				w.Header().Set("Access-Control-Max-Age", maxAge)
 			}
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		if IsOriginAllowed(origin, allowedOrigins) {
+		if allowed {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			if exposeHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
 		}
 
 		handler.ServeHTTP(w, r)
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// DownloadRecorder counts downloads of generated EPUBs by their ID in the
+// request's tenant and the converter version that generated them.
+type DownloadRecorder interface {
+	RecordDownload(ctx context.Context, id, version string)
+}
+
+// converterVersion matches the converter versions a download can ask for
+// with the converterVersion query parameter.
+var converterVersion = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
+
+// documentID matches the IDs of stored documents: revision IDs, excerpt
+// and converted EPUB names, and bulk export and statute book IDs.
+var documentID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// maxDocumentIDLength bounds document IDs well below the object name limit
+// of Cloud Storage.
+const maxDocumentIDLength = 512
+
+// ValidateDocumentID checks that id names a stored document and nothing
+// else. IDs are joined below the storage prefix of the caller's tenant, so
+// path separators and dot segments, which ServeMux decodes from %2F in a
+// path wildcard, would reach the documents of other tenants.
+func ValidateDocumentID(id string) error {
+	if len(id) > maxDocumentIDLength || !documentID.MatchString(id) {
+		return fmt.Errorf("invalid document ID %q (expected letters, digits, hyphens, and underscores)", id)
+	}
+	return nil
+}
+
+// DownloadHandler serves stored documents from Cloud Storage with range
+// requests, so interrupted downloads resume where they stopped instead of
+// failing once a signed URL expires.
+type DownloadHandler struct {
+	bucket objects.Bucket
+	// version is the object prefix of the current converter output.
+	version string
+	// filenames names EPUBs from their object metadata.
+	filenames *naming.Template
+	// downloads counts downloads of generated EPUBs; nil skips counting.
+	downloads DownloadRecorder
+}
+
+// NewDownloadHandler returns a handler for the /download/{id} route, where
+// id is an EPUB ID, the name of a converted EPUB, a bulk export ID, or a
+// statute book ID, looked up in the storage directory of the request's
+// tenant. Downloads are unavailable when bucket is nil.
+// EPUBs pinned to an older converter version are served with the
+// converterVersion query parameter. Downloads of generated EPUBs are
+// counted with downloads.
+func NewDownloadHandler(bucket objects.Bucket, version string, filenames *naming.Template, downloads DownloadRecorder) *DownloadHandler {
+	return &DownloadHandler{bucket: bucket, version: version, filenames: filenames, downloads: downloads}
+}
+
+func (h *DownloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.bucket == nil {
+		http.Error(w, "Downloads are not configured", http.StatusNotImplemented)
+		return
+	}
+
+	id := r.PathValue("id")
+	if err := ValidateDocumentID(id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	version := r.URL.Query().Get("converterVersion")
+	if version == "" {
+		version = h.version
+	}
+	if !converterVersion.MatchString(version) {
+		http.Error(w, "Invalid converter version", http.StatusBadRequest)
+		return
+	}
+	// The same path names another document for each tenant.
+	w.Header().Add("Vary", "X-API-Key")
+	tenantID := tenant.IDFromContext(r.Context())
+	prefix := tenant.Prefix(version, tenantID)
+	candidates := []downloadCandidate{
+		{fmt.Sprintf("%s/%s.epub", prefix, id), true, true},
+	}
+	// Converted EPUBs, exports, and statute books only exist for the
+	// current version.
+	if version == h.version {
+		candidates = append(candidates,
+			downloadCandidate{fmt.Sprintf("%s/converted/%s.epub", prefix, id), true, false},
+			downloadCandidate{fmt.Sprintf("%s/exports/%s.zip", prefix, id), false, false},
+			downloadCandidate{fmt.Sprintf("%s/bundles/%s.epub", prefix, id), true, false},
+		)
+	}
+	for _, candidate := range candidates {
+		attrs, err := h.bucket.Attrs(r.Context(), candidate.path)
+		if errors.Is(err, objects.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to read %s: %v", candidate.path, err)
+			http.Error(w, "Failed to read document", http.StatusBadGateway)
+			return
+		}
+
+		// Large downloads on slow networks outlive the server's write
+		// timeout.
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			log.Printf("Failed to clear write deadline for %s: %v", id, err)
+		}
+
+		// Reads are pinned to the generation whose size and ETag were
+		// sent, so a regenerated document cannot be mixed into a resumed
+		// download.
+		content := &objectReadSeeker{
+			ctx:        r.Context(),
+			bucket:     h.bucket,
+			name:       attrs.Name,
+			generation: attrs.Generation,
+			size:       attrs.Size,
+		}
+		defer content.Close()
+
+		w.Header().Set("Content-Type", attrs.ContentType)
+		disposition := fmt.Sprintf(`attachment; filename="%s.zip"`, id)
+		if candidate.epub {
+			disposition = h.filenames.ContentDisposition(naming.FromMetadata(id, attrs.Metadata))
+		}
+		w.Header().Set("Content-Disposition", disposition)
+		w.Header().Set("ETag", ComputeETag(attrs.Name, strconv.FormatInt(attrs.Generation, 10)))
+		// Shared caches may only keep documents outside any tenant.
+		if tenantID != "" {
+			w.Header().Set("Cache-Control", "private, max-age=2592000")
+		} else {
+			w.Header().Set("Cache-Control", "public, max-age=2592000")
+		}
+		// Resumed downloads and HEAD requests are not counted again.
+		if candidate.generated && h.downloads != nil && r.Method == http.MethodGet && r.Header.Get("Range") == "" {
+			h.downloads.RecordDownload(r.Context(), id, version)
+		}
+		// ServeContent answers Range, If-Range, and If-None-Match.
+		http.ServeContent(w, r, "", attrs.Updated, content)
+		return
+	}
+	http.Error(w, "Document not found", http.StatusNotFound)
+}
+
+// downloadCandidate is an object the /download/{id} route may serve.
+type downloadCandidate struct {
+	path string
+	epub bool
+	// generated is set for EPUBs of the generator, whose downloads are
+	// counted.
+	generated bool
+}
+
+// objectReadSeeker reads a generation of an object from any offset,
+// opening a range reader on the first read after each seek.
+type objectReadSeeker struct {
+	ctx        context.Context
+	bucket     objects.Bucket
+	name       string
+	generation int64
+	size       int64
+	offset     int64
+	reader     *objects.Reader
+}
+
+func (s *objectReadSeeker) Read(p []byte) (int, error) {
+	if s.offset >= s.size {
+		return 0, io.EOF
+	}
+	if s.reader == nil {
+		reader, err := s.bucket.NewRangeReader(s.ctx, s.name, s.generation, s.offset, -1)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %v", s.name, err)
+		}
+		s.reader = reader
+	}
+	n, err := s.reader.Read(p)
+	s.offset += int64(n)
+	return n, err
+}
+
+func (s *objectReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	var target int64
+	switch whence {
+	case io.SeekStart:
+		target = offset
+	case io.SeekCurrent:
+		target = s.offset + offset
+	case io.SeekEnd:
+		target = s.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if target < 0 {
+		return 0, errors.New("negative position")
+	}
+	if target != s.offset {
+		s.Close()
+		s.offset = target
+	}
+	return target, nil
+}
+
+// Close releases the open range reader, if any.
+func (s *objectReadSeeker) Close() {
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+}
@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/furigana"
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+const (
+	contentTypeEpub = "application/epub+zip"
+	contentTypeHTML = "text/html"
+	contentTypeXML  = "application/xml"
+	contentTypeText = "text/xml"
+)
+
+// EpubSource starts or polls EPUB generation for a revision.
+type EpubSource interface {
+	GetEpub(ctx context.Context, id string, articles []string) (*model1.Epub, error)
+	// PollEpub reports the progress of a generation GetEpub started,
+	// without recording another request.
+	PollEpub(ctx context.Context, id string, articles []string) (*model1.Epub, error)
+}
+
+// EpubsHandler serves /epubs/{id} in the format chosen by the Accept
+// header: EPUB (default), HTML, or the raw law XML. ?furigana=true adds
+// ruby readings to EPUB and HTML output, ?accessible=true adds screen
+// reader markup and metadata to EPUB output, ?vertical=true sets EPUB output
+// in vertical lines, and ?diffAgainst={revisionId} marks the changes from an
+// earlier revision in EPUB and HTML output.
+// In-process conversions run in a bounded pool; when it is saturated the
+// reply is 503 Service Unavailable with Retry-After.
+// Identifiers other than law IDs, law numbers, and revision IDs are
+// rejected with 400 Bad Request.
+type EpubsHandler struct {
+	epubs   EpubSource
+	lawData *lawdata.Client
+	// version identifies the converter output and is part of every ETag.
+	version string
+	// furigana is nil when no analyzer is configured.
+	furigana *furigana.Annotator
+	// pool bounds in-process conversions.
+	pool *sandbox.Pool
+	// filenames names converted EPUBs.
+	filenames *naming.Template
+}
+
+func NewEpubsHandler(epubs EpubSource, lawData *lawdata.Client, version string, annotator *furigana.Annotator, pool *sandbox.Pool, filenames *naming.Template) *EpubsHandler {
+	return &EpubsHandler{epubs: epubs, lawData: lawData, version: version, furigana: annotator, pool: pool, filenames: filenames}
+}
+
+func (h *EpubsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "Missing revision ID", http.StatusBadRequest)
+		return
+	}
+	// Malformed identifiers would only cost an e-Gov request or a
+	// generator job.
+	parsed, err := lawid.Parse(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id = parsed.Value
+
+	// Progress streams always track EPUB generation; EventSource clients
+	// send "Accept: text/event-stream".
+	if r.URL.Query().Get("progress") == "sse" {
+		h.serveEpubProgress(w, r, id)
+		return
+	}
+
+	ruby, ok := boolParam(w, r, "furigana")
+	if !ok {
+		return
+	}
+	accessible, ok := boolParam(w, r, "accessible")
+	if !ok {
+		return
+	}
+	vertical, ok := boolParam(w, r, "vertical")
+	if !ok {
+		return
+	}
+	if ruby && h.furigana == nil {
+		http.Error(w, "Furigana is not available", http.StatusNotImplemented)
+		return
+	}
+	c := conversion{ruby: ruby, accessible: accessible, vertical: vertical}
+	if v := r.URL.Query().Get("diffAgainst"); v != "" {
+		baseline, err := lawid.Parse(v)
+		if err != nil {
+			http.Error(w, "invalid diffAgainst: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.diffAgainst = baseline.Value
+	}
+
+	w.Header().Add("Vary", "Accept")
+	offers := []string{contentTypeEpub, contentTypeHTML, contentTypeXML, contentTypeText}
+	switch NegotiateContentType(r.Header.Get("Accept"), offers) {
+	case contentTypeEpub:
+		if c.converted() {
+			h.serveConvertedEpub(w, r, id, c)
+			return
+		}
+		h.serveEpub(w, r, id)
+	case contentTypeHTML:
+		h.serveHTML(w, r, id, c)
+	case contentTypeXML, contentTypeText:
+		h.serveXML(w, r, id)
+	default:
+		http.Error(w, "Supported types: "+strings.Join(offers, ", "), http.StatusNotAcceptable)
+	}
+}
+
+// serveEpub redirects to the generated EPUB, or reports generation progress
+// with 202 Accepted until it is ready.
+func (h *EpubsHandler) serveEpub(w http.ResponseWriter, r *http.Request, id string) {
+	epub, err := h.epubs.GetEpub(r.Context(), id, r.URL.Query()["articles"])
+	if err != nil {
+		log.Printf("Failed to get EPUB for %s: %v", id, err)
+		http.Error(w, err.Error(), errorStatus(w, err))
+		return
+	}
+
+	if epub.Status == model1.EpubStatusCompleted && epub.SignedURL != nil {
+		if epub.Etag != nil && CheckNotModified(w, r, *epub.Etag) {
+			return
+		}
+		http.Redirect(w, r, *epub.SignedURL, http.StatusFound)
+		return
+	}
+
+	status := http.StatusAccepted
+	if epub.Status == model1.EpubStatusFailed && epub.NextRetryAt == nil {
+		status = http.StatusInternalServerError
+	} else {
+		w.Header().Set("Retry-After", "5")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(epub)
+}
+
+func (h *EpubsHandler) serveHTML(w http.ResponseWriter, r *http.Request, id string, c conversion) {
+	// HTML is rendered in horizontal lines.
+	c.vertical = false
+	revision, data, ok := h.currentRevision(w, r, id)
+	if !ok {
+		return
+	}
+	if CheckNotModified(w, r, ComputeETag(append([]string{revision, h.version, contentTypeHTML}, c.features()...)...)) {
+		return
+	}
+
+	var annotator lawdata.Annotator
+	if c.ruby {
+		annotator = h.furigana
+	}
+	var buf bytes.Buffer
+	ok = h.convertLaw(w, r, id, data, c, func(law *lawdata.Law) error {
+		if err := lawdata.RenderHTML(&buf, law, annotator); err != nil {
+			return &convertError{status: http.StatusInternalServerError, message: "Failed to render law", err: err}
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// serveConvertedEpub converts the law in-process for options the generator
+// job does not support. Excerpts are not supported.
+func (h *EpubsHandler) serveConvertedEpub(w http.ResponseWriter, r *http.Request, id string, c conversion) {
+	if len(r.URL.Query()["articles"]) > 0 {
+		http.Error(w, "Furigana, accessible, vertical, and diff EPUBs are not available for excerpts", http.StatusBadRequest)
+		return
+	}
+	features := c.features()
+	revision, data, ok := h.currentRevision(w, r, id)
+	if !ok {
+		return
+	}
+	if CheckNotModified(w, r, ComputeETag(append([]string{revision, h.version, contentTypeEpub}, features...)...)) {
+		return
+	}
+
+	opts := lawdata.Options{Accessible: c.accessible, Vertical: c.vertical}
+	if c.ruby {
+		opts.Ruby = h.furigana
+	}
+	var buf bytes.Buffer
+	var fields naming.Fields
+	ok = h.convertLaw(w, r, id, data, c, func(law *lawdata.Law) error {
+		fields = naming.FromLaw(id+"-"+strings.Join(features, "-"), law)
+		if err := lawdata.WriteEPUB(&buf, law, "urn:jplaw2epub:"+id+":"+strings.Join(features, ":"), opts); err != nil {
+			return &convertError{status: http.StatusInternalServerError, message: "Failed to convert law", err: err}
+		}
+		if _, err := lawdata.CheckEPUB(buf.Bytes()); err != nil {
+			return &convertError{status: http.StatusInternalServerError, message: "Failed to convert law: " + err.Error(), err: err}
+		}
+		return nil
+	})
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeEpub)
+	w.Header().Set("Content-Disposition", h.filenames.ContentDisposition(fields))
+	_, _ = w.Write(buf.Bytes())
+}
+
+// conversion holds the options of an in-process conversion.
+type conversion struct {
+	ruby       bool
+	accessible bool
+	// vertical applies to EPUB output only.
+	vertical    bool
+	diffAgainst string
+}
+
+// converted reports whether an EPUB needs in-process conversion.
+func (c conversion) converted() bool {
+	return c.ruby || c.accessible || c.vertical || c.diffAgainst != ""
+}
+
+// features names the options for ETags and file names.
+func (c conversion) features() []string {
+	var features []string
+	if c.ruby {
+		features = append(features, "furigana")
+	}
+	if c.accessible {
+		features = append(features, "accessible")
+	}
+	if c.vertical {
+		features = append(features, "vertical")
+	}
+	if c.diffAgainst != "" {
+		features = append(features, "diff", c.diffAgainst)
+	}
+	return features
+}
+
+// convertLaw fetches the law unless data already holds it and, with
+// diffAgainst, the revision it is compared with. It then parses the law, marks its changes, and calls
+// render in the converter pool, replying with an error when any step
+// fails. The e-Gov requests are made before taking a worker.
+func (h *EpubsHandler) convertLaw(w http.ResponseWriter, r *http.Request, id string, data *lawdata.LawData, c conversion, render func(*lawdata.Law) error) bool {
+	ok := true
+	if data == nil {
+		if data, ok = h.fetchLawData(w, r, id); !ok {
+			return false
+		}
+	}
+	estimate := sandbox.Estimate(len(data.XML))
+	var baselineData *lawdata.LawData
+	if c.diffAgainst != "" {
+		if baselineData, ok = h.fetchLawData(w, r, c.diffAgainst); !ok {
+			return false
+		}
+		estimate += sandbox.Estimate(len(baselineData.XML))
+	}
+
+	extendWriteDeadline(w, h.pool, id)
+	err := h.pool.Run(r.Context(), estimate, func() error {
+		law, err := parseLaw(data)
+		if err != nil {
+			return err
+		}
+		if baselineData != nil {
+			baseline, err := parseLaw(baselineData)
+			if err != nil {
+				return err
+			}
+			baseline.RevisionID = c.diffAgainst
+			if err := lawdata.MarkChanges(baseline, law); err != nil {
+				return &convertError{status: http.StatusBadRequest, message: err.Error(), err: err}
+			}
+		}
+		return render(law)
+	})
+	if err != nil {
+		writeConvertError(w, id, err)
+		return false
+	}
+	return true
+}
+
+// writeMargin is the time left to send a reply after the longest
+// conversion the pool allows.
+const writeMargin = 15 * time.Second
+
+// extendWriteDeadline lets the reply to a conversion be written after the
+// server's write timeout, which is shorter than the conversion timeout.
+// Without a pool, conversions have no limit and neither has the reply.
+func extendWriteDeadline(w http.ResponseWriter, pool *sandbox.Pool, id string) {
+	var deadline time.Time
+	if d := pool.MaxDuration(); d > 0 {
+		deadline = time.Now().Add(d + writeMargin)
+	}
+	if err := http.NewResponseController(w).SetWriteDeadline(deadline); err != nil {
+		log.Printf("Failed to extend write deadline for %s: %v", id, err)
+	}
+}
+
+// convertError is a failed step of an in-process conversion and the reply
+// it calls for.
+type convertError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *convertError) Error() string {
+	return e.err.Error()
+}
+
+// writeConvertError replies to a failed in-process conversion. A saturated
+// converter is answered with 503 Service Unavailable and Retry-After.
+func writeConvertError(w http.ResponseWriter, id string, err error) {
+	var failed *convertError
+	switch {
+	case errors.As(err, &failed):
+		if failed.status >= http.StatusInternalServerError {
+			log.Printf("Failed to convert law %s: %v", id, failed.err)
+		}
+		http.Error(w, failed.message, failed.status)
+	case errors.Is(err, sandbox.ErrSaturated):
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "Converter is busy", http.StatusServiceUnavailable)
+	case errors.Is(err, sandbox.ErrTimeout):
+		log.Printf("Conversion of law %s timed out", id)
+		http.Error(w, "Conversion timed out", http.StatusServiceUnavailable)
+	default:
+		log.Printf("Failed to convert law %s: %v", id, err)
+		http.Error(w, "Failed to convert law", http.StatusInternalServerError)
+	}
+}
+
+// errorStatus returns the HTTP status of a failed EPUB request, matching
+// the code GraphQL clients get for the same error, and asks clients to
+// retry a busy server later.
+func errorStatus(w http.ResponseWriter, err error) int {
+	var coded interface{ ErrorCode() model1.ErrorCode }
+	code := model1.ErrorCodeInternalServerError
+	switch {
+	case errors.As(err, &coded):
+		code = coded.ErrorCode()
+	case errors.Is(err, lawdata.ErrNotFound):
+		code = model1.ErrorCodeLawNotFound
+	case errors.Is(err, sandbox.ErrSaturated):
+		code = model1.ErrorCodeServerBusy
+	}
+
+	switch code {
+	case model1.ErrorCodeLawNotFound, model1.ErrorCodeNotFound:
+		return http.StatusNotFound
+	case model1.ErrorCodeInvalidLawID, model1.ErrorCodeBadUserInput:
+		return http.StatusBadRequest
+	case model1.ErrorCodeUnauthenticated:
+		return http.StatusUnauthorized
+	case model1.ErrorCodeForbidden, model1.ErrorCodeOperationNotAllowed:
+		return http.StatusForbidden
+	case model1.ErrorCodeQuotaExceeded:
+		return http.StatusTooManyRequests
+	case model1.ErrorCodeServerBusy:
+		w.Header().Set("Retry-After", "5")
+		return http.StatusServiceUnavailable
+	case model1.ErrorCodeUpstreamError:
+		return http.StatusBadGateway
+	case model1.ErrorCodeUpstreamTimeout:
+		return http.StatusGatewayTimeout
+	case model1.ErrorCodeNotConfigured:
+		return http.StatusNotImplemented
+	case model1.ErrorCodeConversionFailed, model1.ErrorCodeInternalServerError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// boolParam reads an optional true or false query parameter, replying with
+// 400 Bad Request when it is neither.
+func boolParam(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, true
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s %q: expected true or false", name, v), http.StatusBadRequest)
+		return false, false
+	}
+	return b, true
+}
+
+func (h *EpubsHandler) serveXML(w http.ResponseWriter, r *http.Request, id string) {
+	revision, data, ok := h.currentRevision(w, r, id)
+	if !ok {
+		return
+	}
+	if CheckNotModified(w, r, ComputeETag(revision, h.version, contentTypeXML)) {
+		return
+	}
+
+	if data == nil {
+		if data, ok = h.fetchLawData(w, r, id); !ok {
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, _ = w.Write(data.XML)
+}
+
+// currentRevision returns the revision that a request for id is answered
+// with, which ETags are computed from so that they change when the law is
+// amended. A revision ID is its own revision. Law IDs and law numbers are
+// looked up on e-Gov, and the fetched law is returned for the reply.
+func (h *EpubsHandler) currentRevision(w http.ResponseWriter, r *http.Request, id string) (string, *lawdata.LawData, bool) {
+	if parsed, err := lawid.Parse(id); err == nil && parsed.Kind == lawid.RevisionID {
+		return id, nil, true
+	}
+	data, ok := h.fetchLawData(w, r, id)
+	if !ok {
+		return "", nil, false
+	}
+	if data.RevisionID == "" {
+		return id, data, true
+	}
+	return data.RevisionID, data, true
+}
+
+func (h *EpubsHandler) fetchLawData(w http.ResponseWriter, r *http.Request, id string) (*lawdata.LawData, bool) {
+	data, err := h.lawData.FetchLawData(r.Context(), id)
+	if errors.Is(err, lawdata.ErrNotFound) {
+		http.Error(w, "Law not found", http.StatusNotFound)
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("Failed to fetch law %s: %v", id, err)
+		http.Error(w, "Failed to fetch law", http.StatusBadGateway)
+		return nil, false
+	}
+	return data, true
+}
+
+func parseLaw(data *lawdata.LawData) (*lawdata.Law, error) {
+	law, err := lawdata.ParseLawData(data)
+	if err != nil {
+		return nil, &convertError{status: http.StatusBadGateway, message: "Failed to parse law", err: err}
+	}
+	return law, nil
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+const (
+	progressPollInterval = 2 * time.Second
+	progressTimeout      = 15 * time.Minute
+)
+
+// serveEpubProgress streams generation progress as server-sent events. A
+// "progress" event is sent whenever the status changes and a "stage" event
+// whenever the generator reports another stage (parse, convert, or write),
+// followed by a final "complete" event carrying the signed URL or an
+// "error" event. Only the first check is a request for the EPUB; later ones
+// poll its job.
+func (h *EpubsHandler) serveEpubProgress(w http.ResponseWriter, r *http.Request, id string) {
+	rc := http.NewResponseController(w)
+	// The stream outlives the server's write timeout.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Failed to clear write deadline for %s: %v", id, err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	articles := r.URL.Query()["articles"]
+	ticker := time.NewTicker(progressPollInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(progressTimeout)
+	defer timeout.Stop()
+
+	var lastStatus model1.EpubStatus
+	var lastStage *model1.EpubStage
+	get := h.epubs.GetEpub
+	for {
+		epub, err := get(r.Context(), id, articles)
+		if err != nil {
+			log.Printf("Failed to get EPUB for %s: %v", id, err)
+			writeEvent(w, rc, "error", errorBody{Error: err.Error()})
+			return
+		}
+
+		switch {
+		case epub.Status == model1.EpubStatusCompleted:
+			writeEvent(w, rc, "complete", epub)
+			return
+		case epub.Status == model1.EpubStatusFailed && epub.NextRetryAt == nil:
+			writeEvent(w, rc, "error", epub)
+			return
+		case epub.Status != lastStatus:
+			writeEvent(w, rc, "progress", epub)
+			lastStatus = epub.Status
+			if epub.Stage != nil {
+				writeEvent(w, rc, "stage", epub)
+			}
+			lastStage = epub.Stage
+		case epub.Stage != nil && (lastStage == nil || *epub.Stage != *lastStage):
+			writeEvent(w, rc, "stage", epub)
+			lastStage = epub.Stage
+		default:
+			// Keep proxies from closing an idle stream.
+			fmt.Fprint(w, ": waiting\n\n")
+			_ = rc.Flush()
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timeout.C:
+			writeEvent(w, rc, "error", errorBody{Error: "timed out waiting for EPUB generation"})
+			return
+		case <-ticker.C:
+		}
+		get = h.epubs.PollEpub
+	}
+}
+
+func writeEvent(w http.ResponseWriter, rc *http.ResponseController, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event, err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	_ = rc.Flush()
+}
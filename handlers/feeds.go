@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+)
+
+const (
+	contentTypeAtom = "application/atom+xml"
+	feedEntries     = 100
+	// eGovLawURL links feed entries to the law page on e-Gov.
+	eGovLawURL = "https://laws.e-gov.go.jp/law/"
+)
+
+// UpdatesSource lists recently promulgated laws.
+type UpdatesSource interface {
+	ListRecentUpdates(ctx context.Context, since time.Time, lawTypes []lawapi.LawType, limit int) ([]model1.LawUpdate, error)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title    string       `xml:"title"`
+	ID       string       `xml:"id"`
+	Updated  string       `xml:"updated"`
+	Link     atomLink     `xml:"link"`
+	Category atomCategory `xml:"category"`
+	Summary  string       `xml:"summary"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// NewUpdatesFeedHandler serves /feeds/updates.xml, an Atom feed of laws
+// promulgated in the last week, or since the date given as ?since=YYYY-MM-DD
+// or a Japanese era date such as 令和6年4月1日.
+// ?lawType=Act (repeatable) restricts the law types.
+func NewUpdatesFeedHandler(source UpdatesSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		var since time.Time
+		if v := query.Get("since"); v != "" {
+			t, err := jpdate.Parse(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+				return
+			}
+			since = t
+		}
+		var lawTypes []lawapi.LawType
+		for _, v := range query["lawType"] {
+			lawTypes = append(lawTypes, lawapi.LawType(v))
+		}
+
+		updates, err := source.ListRecentUpdates(r.Context(), since, lawTypes, feedEntries)
+		if err != nil {
+			log.Printf("Failed to list law updates: %v", err)
+			http.Error(w, "Failed to list law updates", http.StatusBadGateway)
+			return
+		}
+
+		self := requestURL(r)
+		feed := atomFeed{
+			Title:   "jplaw2epub: new and amended laws",
+			ID:      self,
+			Updated: time.Now().UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: "e-Gov", URI: "https://laws.e-gov.go.jp/"},
+			Links:   []atomLink{{Href: self, Rel: "self"}},
+			Entries: make([]atomEntry, 0, len(updates)),
+		}
+		if len(updates) > 0 {
+			feed.Updated = feedDate(updates[0].PromulgationDate)
+		}
+		for _, update := range updates {
+			feed.Entries = append(feed.Entries, feedEntry(update))
+		}
+
+		data, err := xml.MarshalIndent(feed, "", "  ")
+		if err != nil {
+			log.Printf("Failed to encode updates feed: %v", err)
+			http.Error(w, "Failed to encode feed", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeAtom+"; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=900")
+		_, _ = w.Write([]byte(xml.Header))
+		_, _ = w.Write(data)
+	}
+}
+
+func feedEntry(update model1.LawUpdate) atomEntry {
+	info := update.Law.LawInfo
+	revision := update.Law.RevisionInfo
+	if revision == nil {
+		revision = update.Law.CurrentRevisionInfo
+	}
+
+	title := info.LawNum
+	if revision != nil && revision.LawTitle != "" {
+		title = revision.LawTitle
+	}
+	summary := fmt.Sprintf("New law %s promulgated on %s.", info.LawNum, update.PromulgationDate)
+	if update.Kind == model1.LawUpdateKindAmendment {
+		summary = fmt.Sprintf("Amending act %s promulgated on %s.", info.LawNum, update.PromulgationDate)
+	}
+
+	return atomEntry{
+		Title:    title,
+		ID:       eGovLawURL + info.LawId,
+		Updated:  feedDate(update.PromulgationDate),
+		Link:     atomLink{Href: eGovLawURL + info.LawId},
+		Category: atomCategory{Term: string(update.Kind)},
+		Summary:  summary,
+	}
+}
+
+// feedDate converts a YYYY-MM-DD promulgation date, which is in Japan
+// time, to an Atom timestamp.
+func feedDate(date string) string {
+	t, err := time.ParseInLocation("2006-01-02", date, time.FixedZone("JST", 9*60*60))
+	if err != nil {
+		return time.Now().UTC().Format(time.RFC3339)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// requestURL reconstructs the public URL of a request behind a proxy.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
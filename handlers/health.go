@@ -1,17 +1,25 @@
 package handlers
 
-import (
-	"fmt"
-	"net/http"
-)
+import "net/http"
 
-func HealthHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+// healthStatus is the body of /health.
+type healthStatus struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	// Port is the TCP port the server listens on, which is useful when it
+	// was started with -port 0. It is omitted for Unix domain sockets.
+	Port int `json:"port,omitempty"`
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `{"status":"ok","service":"jplaw2epub-api"}`)
+// NewHealthHandler serves /health for a server listening on port, or 0 for
+// a Unix domain socket.
+func NewHealthHandler(port int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, healthStatus{Status: "ok", Service: "jplaw2epub-api", Port: port})
+	}
 }
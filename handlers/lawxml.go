@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// LawXMLHandler serves the law XML decoded from e-Gov law data, with the
+// TmpRootTag wrapper removed, and caches it in Cloud Storage under
+// laws/{revisionId}.xml. Revisions never change, so requests by revision ID
+// are answered from the cache; requests by law ID or law number always ask
+// e-Gov for the current revision.
+type LawXMLHandler struct {
+	client *lawdata.Client
+	bucket objects.Bucket
+}
+
+// NewLawXMLHandler returns a handler for the /laws/{file} route, where file
+// is {id}.xml. Caching is skipped when bucket is nil.
+func NewLawXMLHandler(client *lawdata.Client, bucket objects.Bucket) *LawXMLHandler {
+	return &LawXMLHandler{client: client, bucket: bucket}
+}
+
+func (h *LawXMLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, ok := strings.CutSuffix(r.PathValue("file"), ".xml")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	parsed, err := lawid.Parse(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	var data []byte
+	revisionID := ""
+	if parsed.Kind == lawid.RevisionID {
+		revisionID = parsed.Value
+		w.Header().Set("X-Law-Revision-Id", revisionID)
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		if CheckNotModified(w, r, lawXMLETag(revisionID)) {
+			return
+		}
+		data, _ = h.readCache(ctx, revisionID)
+	}
+	if data == nil {
+		lawData, err := h.client.FetchLawData(ctx, parsed.Value)
+		if errors.Is(err, lawdata.ErrNotFound) {
+			http.Error(w, "Law not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to fetch law %s: %v", parsed.Value, err)
+			http.Error(w, "Failed to fetch law", http.StatusBadGateway)
+			return
+		}
+		data = lawData.XML
+		if lawData.RevisionID != "" {
+			revisionID = lawData.RevisionID
+			h.writeCache(ctx, revisionID, data)
+		}
+	}
+
+	if parsed.Kind != lawid.RevisionID && revisionID != "" {
+		// The current revision changes when the law is amended.
+		w.Header().Set("X-Law-Revision-Id", revisionID)
+		w.Header().Set("Content-Location", "/laws/"+revisionID+".xml")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		if CheckNotModified(w, r, lawXMLETag(revisionID)) {
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+// lawXMLETag identifies the XML of a revision, which never changes.
+func lawXMLETag(revisionID string) string {
+	return ComputeETag(revisionID, contentTypeXML)
+}
+
+func lawXMLPath(revisionID string) string {
+	return "laws/" + revisionID + ".xml"
+}
+
+func (h *LawXMLHandler) readCache(ctx context.Context, revisionID string) ([]byte, error) {
+	if h.bucket == nil {
+		return nil, errors.New("law XML cache disabled")
+	}
+
+	reader, err := h.bucket.NewReader(ctx, lawXMLPath(revisionID))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached law XML: %v", err)
+	}
+	return data, nil
+}
+
+func (h *LawXMLHandler) writeCache(ctx context.Context, revisionID string, data []byte) {
+	if h.bucket == nil {
+		return
+	}
+
+	// Law data of an invalid revision ID is never cached.
+	if parsed, err := lawid.Parse(revisionID); err != nil || parsed.Kind != lawid.RevisionID {
+		return
+	}
+	objectPath := lawXMLPath(revisionID)
+	w := h.bucket.NewWriter(ctx, objectPath, objects.WriteOptions{ContentType: "application/xml"})
+	if _, err := io.Copy(w, bytes.NewReader(data)); err != nil {
+		_ = w.Close()
+		log.Printf("Failed to cache law XML %s: %v", objectPath, err)
+		return
+	}
+	if err := w.Close(); err != nil {
+		log.Printf("Failed to cache law XML %s: %v", objectPath, err)
+	}
+}
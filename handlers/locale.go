@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"go.ngs.io/jplaw2epub-web-api/locale"
+)
+
+// WithLocale stores the locale that the Accept-Language header prefers in
+// the request context, for display names that a request does not ask for
+// in a locale of its own. Responses vary by the header.
+func WithLocale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Language")
+		ctx := locale.WithContext(r.Context(), locale.Negotiate(r.Header.Get("Accept-Language")))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"regexp"
 	"strings"
@@ -30,6 +33,28 @@ func (rw *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Unwrap lets http.ResponseController reach the underlying writer to flush
+// streams and adjust deadlines.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Flush lets streamed responses through the logger, for handlers that
+// assert http.Flusher, such as incremental GraphQL delivery.
+func (rw *responseWriter) Flush() {
+	_ = http.NewResponseController(rw.ResponseWriter).Flush()
+}
+
+// Hijack lets WebSocket upgrades through the logger.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	rw.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
 // ApacheLoggerMiddleware logs HTTP requests in Apache Combined Log Format.
 // Format: remote_addr - remote_user [time_local] "request" status size "referer" "user_agent".
 // Example: 127.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 [en] (Win98; I ;Nav)".
@@ -53,13 +78,7 @@ func ApacheLoggerMiddleware(next http.Handler) http.Handler {
 
 func logApacheFormat(r *http.Request, rw *responseWriter, _ time.Duration) {
 	// Get remote address.
-	remoteAddr := r.RemoteAddr
-	if xForwardedFor := r.Header.Get("X-Forwarded-For"); xForwardedFor != "" {
-		// Use the first IP in X-Forwarded-For if present.
-		remoteAddr = strings.Split(xForwardedFor, ",")[0]
-	} else if xRealIP := r.Header.Get("X-Real-IP"); xRealIP != "" {
-		remoteAddr = xRealIP
-	}
+	remoteAddr := RealIP(r)
 
 	// Get remote user (from Basic Auth if present).
 	remoteUser := "-"
@@ -115,24 +134,92 @@ type GraphQLRequest struct {
 	Variables     map[string]interface{} `json:"variables,omitempty"`
 }
 
-// extractGraphQLInfo extracts GraphQL operation details from the request.
-func extractGraphQLInfo(r *http.Request) string {
-	if r.Method != "POST" || !strings.Contains(r.URL.Path, "graphql") {
+// GraphQLLogOptions controls the GraphQL details of access logs.
+type GraphQLLogOptions struct {
+	// MaxBodySize bounds the request bodies buffered to read the
+	// operation; larger requests are logged without details.
+	MaxBodySize int64
+	// Variables logs variable values instead of their count.
+	Variables bool
+	// Redact lists variable names whose values are replaced when
+	// Variables is set. A variable, or a field of an input object, is
+	// redacted when its name contains one of them, ignoring case.
+	Redact []string
+}
+
+// maxLoggedVariables bounds the logged JSON of variables.
+const maxLoggedVariables = 256
+
+// bodyReader restores a partially read request body.
+type bodyReader struct {
+	io.Reader
+	io.Closer
+}
+
+// graphQLInfo is the GraphQL operation of a logged request.
+type graphQLInfo struct {
+	Type string
+	Name string
+	// VarCount is the number of variables, and Variables their redacted
+	// JSON when variables are logged.
+	VarCount  int
+	Variables string
+}
+
+// String formats the operation for the Apache request line, such as
+// "[query GetLaws 1 vars]".
+func (g *graphQLInfo) String() string {
+	if g == nil {
 		return ""
 	}
+	var info []string
+	if g.Type != "" {
+		info = append(info, g.Type)
+	}
+	if g.Name != "" {
+		info = append(info, g.Name)
+	}
+	// Add variables, or their count, if present.
+	switch {
+	case g.Variables != "":
+		info = append(info, "vars "+g.Variables)
+	case g.VarCount > 0:
+		info = append(info, fmt.Sprintf("%d vars", g.VarCount))
+	}
 
-	// Read body.
-	bodyBytes, err := io.ReadAll(r.Body)
-	if err != nil {
-		return ""
+	if len(info) > 0 {
+		return "[" + strings.Join(info, " ") + "]"
+	}
+	return ""
+}
+
+// extractGraphQLInfo extracts GraphQL operation details from the request,
+// or returns nil for other requests. Multipart uploads and bodies over
+// opts.MaxBodySize are not inspected.
+func extractGraphQLInfo(r *http.Request, opts GraphQLLogOptions) *graphQLInfo {
+	if r.Method != "POST" || !strings.Contains(r.URL.Path, "graphql") {
+		return nil
+	}
+	// Uploads are not buffered just for logging.
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+		return nil
+	}
+	if r.ContentLength > opts.MaxBodySize {
+		return nil
+	}
+
+	// Read at most one byte past the limit, and restore the body for
+	// downstream handlers.
+	bodyBytes, err := io.ReadAll(io.LimitReader(r.Body, opts.MaxBodySize+1))
+	r.Body = bodyReader{Reader: io.MultiReader(bytes.NewReader(bodyBytes), r.Body), Closer: r.Body}
+	if err != nil || int64(len(bodyBytes)) > opts.MaxBodySize {
+		return nil
 	}
-	// Restore body for downstream handlers.
-	r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 
 	// Parse GraphQL request.
 	var gqlReq GraphQLRequest
 	if err := json.Unmarshal(bodyBytes, &gqlReq); err != nil {
-		return ""
+		return nil
 	}
 
 	// Extract operation type and name.
@@ -142,24 +229,59 @@ func extractGraphQLInfo(r *http.Request) string {
 		operationName = extractOperationName(gqlReq.Query)
 	}
 
-	// Build GraphQL info string.
-	var info []string
-	if operationType != "" {
-		info = append(info, operationType)
+	info := &graphQLInfo{Type: operationType, Name: operationName, VarCount: len(gqlReq.Variables)}
+	if opts.Variables && len(gqlReq.Variables) > 0 {
+		info.Variables = loggedVariables(gqlReq.Variables, opts.Redact)
+	}
+	return info
+}
+
+// loggedVariables returns variables as JSON of at most maxLoggedVariables
+// bytes, with the values of names matching redact replaced.
+func loggedVariables(variables map[string]interface{}, redact []string) string {
+	data, err := json.Marshal(redactVariables(variables, redact))
+	if err != nil {
+		return "-"
 	}
-	if operationName != "" {
-		info = append(info, operationName)
+	if len(data) > maxLoggedVariables {
+		return string(data[:maxLoggedVariables]) + "..."
 	}
+	return string(data)
+}
 
-	// Add variables count if present.
-	if len(gqlReq.Variables) > 0 {
-		info = append(info, fmt.Sprintf("%d vars", len(gqlReq.Variables)))
+// redactVariables copies a variable value, replacing the values of object
+// fields whose names contain one of redact.
+func redactVariables(value interface{}, redact []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for name, field := range v {
+			if redacted(name, redact) {
+				result[name] = "[REDACTED]"
+			} else {
+				result[name] = redactVariables(field, redact)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = redactVariables(item, redact)
+		}
+		return result
+	default:
+		return value
 	}
+}
 
-	if len(info) > 0 {
-		return "[" + strings.Join(info, " ") + "]"
+func redacted(name string, redact []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range redact {
+		if strings.Contains(name, strings.ToLower(pattern)) {
+			return true
+		}
 	}
-	return ""
+	return false
 }
 
 // extractOperationType extracts the operation type (query/mutation/subscription) from GraphQL query.
@@ -203,37 +325,17 @@ func extractOperationName(query string) string {
 	return ""
 }
 
-// ApacheLoggerWithDuration includes response time at the end (Apache with %D).
-func ApacheLoggerWithDuration(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		// Extract GraphQL info before processing.
-		graphqlInfo := extractGraphQLInfo(r)
-
-		// Wrap the ResponseWriter to capture status and size.
-		wrapped := &responseWriter{
-			ResponseWriter: w,
-			status:         http.StatusOK,
-		}
-
-		// Process request.
-		next.ServeHTTP(wrapped, r)
-
-		// Log with duration.
-		duration := time.Since(start)
-		logApacheFormatWithDuration(r, wrapped, duration, graphqlInfo)
-	})
+// ApacheLoggerWithDuration includes response time at the end (Apache with %D)
+// and the GraphQL operation as opts allow.
+func ApacheLoggerWithDuration(next http.Handler, opts GraphQLLogOptions) http.Handler {
+	return WithAccessLog(next, AccessLogOptions{Format: AccessLogApache, GraphQL: opts})
 }
 
-func logApacheFormatWithDuration(r *http.Request, rw *responseWriter, duration time.Duration, graphqlInfo string) {
+// apacheLine formats a request in Apache Combined Log Format with the
+// GraphQL operation in the request line and the duration at the end.
+func apacheLine(r *http.Request, rw *responseWriter, duration time.Duration, graphqlInfo *graphQLInfo) string {
 	// Get remote address.
-	remoteAddr := r.RemoteAddr
-	if xForwardedFor := r.Header.Get("X-Forwarded-For"); xForwardedFor != "" {
-		remoteAddr = strings.Split(xForwardedFor, ",")[0]
-	} else if xRealIP := r.Header.Get("X-Real-IP"); xRealIP != "" {
-		remoteAddr = xRealIP
-	}
+	remoteAddr := RealIP(r)
 
 	// Get remote user.
 	remoteUser := "-"
@@ -246,8 +348,8 @@ func logApacheFormatWithDuration(r *http.Request, rw *responseWriter, duration t
 
 	// Build request line, optionally including GraphQL info.
 	requestLine := fmt.Sprintf("%s %s %s", r.Method, r.RequestURI, r.Proto)
-	if graphqlInfo != "" {
-		requestLine = fmt.Sprintf("%s %s %s %s", r.Method, r.RequestURI, r.Proto, graphqlInfo)
+	if info := graphqlInfo.String(); info != "" {
+		requestLine = fmt.Sprintf("%s %s %s %s", r.Method, r.RequestURI, r.Proto, info)
 	}
 
 	// Get referer.
@@ -275,8 +377,8 @@ func logApacheFormatWithDuration(r *http.Request, rw *responseWriter, duration t
 	// Duration in microseconds (Apache %D format).
 	durationMicros := duration.Microseconds()
 
-	// Log in Apache Combined Log Format with duration.
-	log.Printf("%s - %s %s \"%s\" %d %s %s %s %dµs",
+	// Apache Combined Log Format with duration.
+	return fmt.Sprintf("%s - %s %s \"%s\" %d %s %s %s %dµs",
 		remoteAddr,
 		remoteUser,
 		timeLocal,
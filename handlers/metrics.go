@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// NewMetricsHandler serves the counters published with expvar, such as
+// those of the GraphQL operation allow-list, as JSON. It must be wrapped
+// with WithAdminToken; requests without the admin token are rejected.
+func NewMetricsHandler() http.HandlerFunc {
+	vars := expvar.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !IsAdmin(r.Context()) {
+			writeJSONError(w, http.StatusUnauthorized, "admin authorization required")
+			return
+		}
+		vars.ServeHTTP(w, r)
+	}
+}
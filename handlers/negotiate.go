@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NegotiateContentType picks the offer that best matches an Accept header.
+// It also accepts Accept-Encoding values, whose offers have no subtype.
+// Offers are listed in server preference order, which breaks ties between
+// equal quality values and wildcards. An empty Accept header selects the
+// first offer. It returns an empty string when nothing is acceptable.
+func NegotiateContentType(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		if len(offers) == 0 {
+			return ""
+		}
+		return offers[0]
+	}
+
+	best := ""
+	bestQ := 0.0
+	bestSpecificity := -1
+	for _, offer := range offers {
+		q, specificity := acceptQuality(accept, offer)
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality value the Accept header assigns to a
+// media type, taken from the most specific matching range.
+func acceptQuality(accept, offer string) (float64, int) {
+	offerType, offerSubtype, _ := strings.Cut(offer, "/")
+
+	q := 0.0
+	specificity := -1
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+		rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+
+		var s int
+		switch {
+		case rangeType == offerType && rangeSubtype == offerSubtype:
+			s = 2
+		case rangeType == offerType && rangeSubtype == "*":
+			s = 1
+		case rangeType == "*" && (rangeSubtype == "*" || rangeSubtype == ""):
+			s = 0
+		default:
+			continue
+		}
+		if s <= specificity {
+			continue
+		}
+
+		specificity = s
+		q = 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = parsed
+			}
+		}
+	}
+	return q, specificity
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+)
+
+const (
+	contentTypeOPDSNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	contentTypeOPDSAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	contentTypeOpenSearch      = "application/opensearchdescription+xml"
+	// opdsPageSize is the number of laws per acquisition feed page.
+	opdsPageSize = 50
+	// relAcquisition marks links that download a publication.
+	relAcquisition = "http://opds-spec.org/acquisition"
+)
+
+// OPDSSource searches laws for the OPDS catalog. It is implemented by the
+// GraphQL resolver.
+type OPDSSource interface {
+	SearchLaws(ctx context.Context, params *lawapi.GetLawsParams) (*lawapi.LawsResponse, error)
+	TitleEn(item *lawapi.LawItem) string
+}
+
+type opdsFeed struct {
+	XMLName      xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	OpenSearch   string      `xml:"xmlns:opensearch,attr,omitempty"`
+	DC           string      `xml:"xmlns:dc,attr,omitempty"`
+	ID           string      `xml:"id"`
+	Title        string      `xml:"title"`
+	Updated      string      `xml:"updated"`
+	Author       atomAuthor  `xml:"author"`
+	TotalResults int64       `xml:"opensearch:totalResults,omitempty"`
+	ItemsPerPage int         `xml:"opensearch:itemsPerPage,omitempty"`
+	Links        []atomLink  `xml:"link"`
+	Entries      []opdsEntry `xml:"entry"`
+}
+
+type opdsEntry struct {
+	Title    string        `xml:"title"`
+	ID       string        `xml:"id"`
+	Updated  string        `xml:"updated"`
+	Issued   string        `xml:"dc:issued,omitempty"`
+	Language string        `xml:"dc:language,omitempty"`
+	Category *atomCategory `xml:"category"`
+	Content  *opdsContent  `xml:"content"`
+	Links    []atomLink    `xml:"link"`
+}
+
+type opdsContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type openSearchDescription struct {
+	XMLName     xml.Name      `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName   string        `xml:"ShortName"`
+	Description string        `xml:"Description"`
+	Language    string        `xml:"Language"`
+	URL         openSearchURL `xml:"Url"`
+}
+
+type openSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+type opdsCategory struct {
+	code lawapi.CategoryCd
+	name string
+}
+
+type opdsHandler struct {
+	source OPDSSource
+}
+
+// NewOPDSHandler serves an OPDS 1.2 catalog for e-reader apps under /opds:
+// a navigation feed of the e-Gov law categories, an acquisition feed of the
+// laws in each category, and title search described by OpenSearch. EPUBs
+// are acquired from /epubs/{id} with ?accessible=true, which converts them
+// on request instead of answering 202 Accepted while they are generated.
+func NewOPDSHandler(source OPDSSource) http.Handler {
+	h := &opdsHandler{source: source}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /opds", h.serveRoot)
+	mux.HandleFunc("GET /opds/categories/{code}", h.serveCategory)
+	mux.HandleFunc("GET /opds/search", h.serveSearch)
+	mux.HandleFunc("GET /opds/search.xml", h.serveOpenSearch)
+	return mux
+}
+
+func (h *opdsHandler) serveRoot(w http.ResponseWriter, r *http.Request) {
+	feed := newOPDSFeed(r, "jplaw2epub: 法令カタログ")
+	for _, category := range opdsCategories() {
+		href := "/opds/categories/" + string(category.code)
+		feed.Entries = append(feed.Entries, opdsEntry{
+			Title:   category.name,
+			ID:      "urn:jplaw2epub:opds:category:" + string(category.code),
+			Updated: feed.Updated,
+			Content: &opdsContent{Type: "text", Text: category.name + "に分類される法令"},
+			Links:   []atomLink{{Href: href, Rel: "subsection", Type: contentTypeOPDSAcquisition}},
+		})
+	}
+	writeOPDS(w, feed, contentTypeOPDSNavigation)
+}
+
+func (h *opdsHandler) serveCategory(w http.ResponseWriter, r *http.Request) {
+	code := lawapi.CategoryCd(r.PathValue("code"))
+	var name string
+	for _, category := range opdsCategories() {
+		if category.code == code {
+			name = category.name
+		}
+	}
+	if name == "" {
+		http.Error(w, fmt.Sprintf("unknown category %q", code), http.StatusNotFound)
+		return
+	}
+
+	params := &lawapi.GetLawsParams{CategoryCd: &[]lawapi.CategoryCd{code}}
+	h.serveLaws(w, r, params, "jplaw2epub: "+name)
+}
+
+func (h *opdsHandler) serveSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, "Missing search terms ?q=", http.StatusBadRequest)
+		return
+	}
+
+	params := &lawapi.GetLawsParams{LawTitle: &q}
+	h.serveLaws(w, r, params, fmt.Sprintf("jplaw2epub: 「%s」の検索結果", q))
+}
+
+// serveLaws writes a page of the laws matching params as an acquisition
+// feed. Pages are numbered from 1 by ?page=.
+func (h *opdsHandler) serveLaws(w http.ResponseWriter, r *http.Request, params *lawapi.GetLawsParams, title string) {
+	page := 1
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, fmt.Sprintf("invalid page %q: expected a positive integer", v), http.StatusBadRequest)
+			return
+		}
+		page = n
+	}
+	limit := int32(opdsPageSize)
+	offset := int32((page - 1) * opdsPageSize)
+	params.Limit = &limit
+	params.Offset = &offset
+
+	resp, err := h.source.SearchLaws(r.Context(), params)
+	if err != nil {
+		log.Printf("OPDS law search failed: %v", err)
+		http.Error(w, "Failed to search laws", http.StatusBadGateway)
+		return
+	}
+
+	feed := newOPDSFeed(r, title)
+	feed.TotalResults = resp.TotalCount
+	feed.ItemsPerPage = opdsPageSize
+	if page > 1 {
+		feed.Links = append(feed.Links, atomLink{Href: pageURL(r, page-1), Rel: "previous", Type: contentTypeOPDSAcquisition})
+	}
+	if int64(offset)+int64(len(resp.Laws)) < resp.TotalCount {
+		feed.Links = append(feed.Links, atomLink{Href: pageURL(r, page+1), Rel: "next", Type: contentTypeOPDSAcquisition})
+	}
+	for i := range resp.Laws {
+		if entry, ok := h.lawEntry(&resp.Laws[i]); ok {
+			feed.Entries = append(feed.Entries, entry)
+		}
+	}
+	writeOPDS(w, feed, contentTypeOPDSAcquisition)
+}
+
+// lawEntry describes a law revision with its acquisition link. Laws without
+// a revision have nothing to download and are skipped.
+func (h *opdsHandler) lawEntry(item *lawapi.LawItem) (opdsEntry, bool) {
+	revision := item.RevisionInfo
+	if revision == nil {
+		revision = item.CurrentRevisionInfo
+	}
+	if revision == nil || revision.LawRevisionId == "" || item.LawInfo == nil {
+		return opdsEntry{}, false
+	}
+	info := item.LawInfo
+
+	summary := info.LawNum
+	if titleEn := h.source.TitleEn(item); titleEn != "" {
+		summary += "\n" + titleEn
+	}
+	updated := feedDate(info.PromulgationDate.String())
+	if t := time.Time(revision.Updated); !t.IsZero() {
+		updated = t.UTC().Format(time.RFC3339)
+	}
+
+	entry := opdsEntry{
+		Title:    revision.LawTitle,
+		ID:       "urn:jplaw2epub:" + revision.LawRevisionId,
+		Updated:  updated,
+		Language: "ja",
+		Content:  &opdsContent{Type: "text", Text: summary},
+		Links: []atomLink{
+			{Href: "/epubs/" + url.PathEscape(revision.LawRevisionId) + "?accessible=true", Rel: relAcquisition, Type: contentTypeEpub},
+			{Href: eGovLawURL + info.LawId, Rel: "alternate", Type: "text/html"},
+		},
+	}
+	if !time.Time(info.PromulgationDate).IsZero() {
+		entry.Issued = info.PromulgationDate.String()
+	}
+	if revision.Category != "" {
+		entry.Category = &atomCategory{Term: revision.Category}
+	}
+	if entry.Title == "" {
+		entry.Title = info.LawNum
+	}
+	return entry, true
+}
+
+func (h *opdsHandler) serveOpenSearch(w http.ResponseWriter, r *http.Request) {
+	description := openSearchDescription{
+		ShortName:   "jplaw2epub",
+		Description: "法令名で法令を検索",
+		Language:    "ja",
+		URL:         openSearchURL{Type: contentTypeOPDSAcquisition, Template: "/opds/search?q={searchTerms}"},
+	}
+	data, err := xml.MarshalIndent(description, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode OpenSearch description: %v", err)
+		http.Error(w, "Failed to encode OpenSearch description", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypeOpenSearch+"; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}
+
+// newOPDSFeed starts a feed for the request with links to itself, the
+// catalog root, and search.
+func newOPDSFeed(r *http.Request, title string) opdsFeed {
+	self := requestURL(r)
+	return opdsFeed{
+		OpenSearch: "http://a9.com/-/spec/opensearch/1.1/",
+		DC:         "http://purl.org/dc/terms/",
+		ID:         self,
+		Title:      title,
+		Updated:    time.Now().UTC().Format(time.RFC3339),
+		Author:     atomAuthor{Name: "e-Gov", URI: "https://laws.e-gov.go.jp/"},
+		Links: []atomLink{
+			{Href: self, Rel: "self"},
+			{Href: "/opds", Rel: "start", Type: contentTypeOPDSNavigation},
+			{Href: "/opds/search.xml", Rel: "search", Type: contentTypeOpenSearch},
+		},
+	}
+}
+
+func writeOPDS(w http.ResponseWriter, feed opdsFeed, contentType string) {
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		log.Printf("Failed to encode OPDS feed: %v", err)
+		http.Error(w, "Failed to encode feed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType+";charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+}
+
+// pageURL is the request path with ?page= replaced.
+func pageURL(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page))
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// opdsCategories lists the e-Gov law categories in code order.
+func opdsCategories() []opdsCategory {
+	names := CategoryNames()
+	categories := make([]opdsCategory, len(names))
+	for i, name := range names {
+		categories[i] = opdsCategory{code: lawapi.CategoryCd(fmt.Sprintf("%03d", i+1)), name: name}
+	}
+	return categories
+}
+
+// CategoryNames returns the names of the e-Gov law categories in code
+// order, so the category with code 001 comes first.
+func CategoryNames() []string {
+	return []string{
+		"憲法", "刑事", "財務通則", "水産業", "観光", "国会", "警察", "国有財産", "鉱業", "郵務",
+		"行政組織", "消防", "国税", "工業", "電気通信", "国家公務員", "国土開発", "事業", "商業", "労働",
+		"行政手続", "土地", "国債", "金融・保険", "環境保全", "統計", "都市計画", "教育", "外国為替・貿易", "厚生",
+		"地方自治", "道路", "文化", "陸運", "社会福祉", "地方財政", "河川", "産業通則", "海運", "社会保険",
+		"司法", "災害対策", "農業", "航空", "防衛", "民事", "建築・住宅", "林業", "貨物運送", "外事",
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+)
+
+// openAPISchemas lists the response types documented under
+// components/schemas. Their schemas are generated from the Go types so the
+// spec follows the JSON the handlers actually write.
+func openAPISchemas() map[string]reflect.Type {
+	return map[string]reflect.Type{
+		"Law":     reflect.TypeOf(lawSummary{}),
+		"LawList": reflect.TypeOf(lawList{}),
+		"Epub":    reflect.TypeOf(model1.Epub{}),
+		"Error":   reflect.TypeOf(errorBody{}),
+	}
+}
+
+// OpenAPISpec returns the OpenAPI 3 document for the /v1 REST API.
+func OpenAPISpec(version string) map[string]interface{} {
+	types := openAPISchemas()
+	refs := make(map[reflect.Type]string, len(types))
+	for name, t := range types {
+		refs[t] = name
+	}
+	enums := map[reflect.Type][]string{
+		reflect.TypeOf(model1.EpubStatus("")): epubStatusValues(),
+	}
+
+	schemas := make(map[string]interface{}, len(types))
+	for name, t := range types {
+		schemas[name] = structSchema(t, refs, enums)
+	}
+
+	revisionID := pathParam("id", "Law revision ID")
+	articles := map[string]interface{}{
+		"name":        "articles",
+		"in":          "query",
+		"description": "Article numbers for an excerpt; omit for the whole law.",
+		"schema":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		"style":       "form",
+		"explode":     true,
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "jplaw2epub API",
+			"description": "REST access to Japanese law metadata and EPUB generation.",
+			"version":     version,
+		},
+		"paths": map[string]interface{}{
+			"/v1/laws": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "searchLaws",
+					"summary":     "Search laws",
+					"parameters": []interface{}{
+						queryParam("lawId", "string", "Law ID such as 129AC0000000089."),
+						queryParam("lawNum", "string", "Law number such as 昭和二十五年法律第百三十一号; digits such as 令和5年法律第36号 are accepted."),
+						queryParam("lawTitle", "string", "Partial law title."),
+						queryParam("lawTitleKana", "string", "Partial law title in kana."),
+						queryParam("asof", "string", "Date in YYYY-MM-DD format or a Japanese era date such as 令和5年4月1日."),
+						queryParam("promulgateDateFrom", "string", "Earliest promulgation date, in the same formats as asof."),
+						queryParam("promulgateDateTo", "string", "Latest promulgation date, in the same formats as asof."),
+						queryParam("limit", "integer", "Maximum number of results."),
+						queryParam("offset", "integer", "Number of results to skip."),
+					},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("Matching laws.", "LawList"),
+						"400": jsonResponse("Invalid parameters.", "Error"),
+						"502": jsonResponse("The e-Gov API failed.", "Error"),
+						"429": jsonResponse("A daily or monthly quota is used up.", "Error"),
+					},
+				},
+			},
+			"/v1/laws/{id}": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "getLaw",
+					"summary":     "Get a law by law ID or law number",
+					"parameters":  []interface{}{pathParam("id", "Law ID or law number")},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("The law.", "Law"),
+						"400": jsonResponse("The ID is not a law ID, law number, or revision ID.", "Error"),
+						"404": jsonResponse("No law matches.", "Error"),
+						"502": jsonResponse("The e-Gov API failed.", "Error"),
+						"429": jsonResponse("A daily or monthly quota is used up.", "Error"),
+					},
+				},
+			},
+			"/v1/epubs/{id}": map[string]interface{}{
+				"get": map[string]interface{}{
+					"operationId": "getEpubStatus",
+					"summary":     "Get EPUB generation status without starting generation",
+					"parameters":  []interface{}{revisionID, articles},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("Generation status, with a signed URL once completed.", "Epub"),
+						"400": jsonResponse("The ID is not a law ID, law number, or revision ID.", "Error"),
+						"404": jsonResponse("The EPUB has not been requested.", "Error"),
+						"500": jsonResponse("Generation is not configured or failed to start.", "Error"),
+						"429": jsonResponse("A daily or monthly quota is used up.", "Error"),
+					},
+				},
+				"post": map[string]interface{}{
+					"operationId": "requestEpub",
+					"summary":     "Request EPUB generation",
+					"parameters":  []interface{}{revisionID, articles},
+					"responses": map[string]interface{}{
+						"200": jsonResponse("The EPUB is ready.", "Epub"),
+						"202": jsonResponse("Generation is in progress; poll the Location header.", "Epub"),
+						"400": jsonResponse("The ID is not a law ID, law number, or revision ID.", "Error"),
+						"500": jsonResponse("Generation is not configured or failed to start.", "Error"),
+						"429": jsonResponse("A daily or monthly quota is used up.", "Error"),
+					},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+// OpenAPIHandler serves the OpenAPI document at /openapi.json.
+func OpenAPIHandler(version string) http.HandlerFunc {
+	spec := OpenAPISpec(version)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, spec)
+	}
+}
+
+func epubStatusValues() []string {
+	values := make([]string, 0, len(model1.AllEpubStatus))
+	for _, s := range model1.AllEpubStatus {
+		values = append(values, string(s))
+	}
+	return values
+}
+
+// structSchema describes a struct from its JSON tags. Fields without
+// omitempty are required.
+func structSchema(t reflect.Type, refs map[reflect.Type]string, enums map[reflect.Type][]string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type, refs, enums)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func typeSchema(t reflect.Type, refs map[reflect.Type]string, enums map[reflect.Type][]string) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if name, ok := refs[t]; ok {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	if values, ok := enums[t]; ok {
+		return map[string]interface{}{"type": "string", "enum": values}
+	}
+
+	switch kind := t.Kind(); {
+	case kind == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case kind == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case kind == reflect.Int, kind == reflect.Int32, kind == reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case kind == reflect.Float32, kind == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case kind == reflect.Slice, kind == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), refs, enums)}
+	case kind == reflect.Struct:
+		return structSchema(t, refs, enums)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func pathParam(name, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "path",
+		"required":    true,
+		"description": description,
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+func queryParam(name, typ, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"schema":      map[string]interface{}{"type": typ},
+	}
+}
+
+func jsonResponse(description, schema string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": "#/components/schemas/" + schema},
+			},
+		},
+	}
+}
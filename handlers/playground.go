@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/playground"
+)
+
+// NewPlaygroundHandler serves the GraphiQL playground for endpoint
+// according to mode: on for everyone, off for no one, and admin for
+// requests sending the admin token, either as a bearer token or as the
+// password of HTTP basic authentication so that browsers prompt for it.
+func NewPlaygroundHandler(endpoint, mode, token string) http.Handler {
+	page := playground.Handler("GraphQL playground", endpoint)
+	switch mode {
+	case "on":
+		return page
+	case "admin":
+		return WithAdminToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !IsAdmin(r.Context()) && !basicAuthAdmin(r, token) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="GraphQL playground"`)
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			page.ServeHTTP(w, r)
+		}), token)
+	default:
+		return http.NotFoundHandler()
+	}
+}
+
+// basicAuthAdmin reports whether the basic authentication password of r is
+// the admin token. The user name is ignored.
+func basicAuthAdmin(r *http.Request, token string) bool {
+	_, password, ok := r.BasicAuth()
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(password), []byte(token)) == 1
+}
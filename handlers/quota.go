@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/auth"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// QuotaState is the quota of the client making a request.
+type QuotaState struct {
+	// Subject is "tenant", "user", "key", "origin", or "ip" and tells how
+	// the client was identified.
+	Subject string
+	Usages  []quota.Usage
+
+	limiter *quota.Limiter
+	key     string
+}
+
+// Consume counts n more requests against the client's quotas, for
+// operations that do the work of several requests, and returns the
+// resulting usage, daily window first.
+func (s *QuotaState) Consume(ctx context.Context, n int64) ([]quota.Usage, error) {
+	// Counted on the wall clock, like the request itself.
+	return s.limiter.ConsumeN(ctx, s.key, n, time.Now())
+}
+
+// QuotaOptions identifies clients for quota accounting.
+type QuotaOptions struct {
+	// APIKeys are the accepted X-API-Key values, each with its own quota.
+	APIKeys []string
+	// AllowedOrigins are the CORS origins that get a quota per origin.
+	AllowedOrigins []string
+	// GraphQL answers exceeded quotas with a GraphQL error response whose
+	// "code" extension is QUOTA_EXCEEDED.
+	GraphQL bool
+}
+
+// Client identifies the client of a request for per-client limits.
+type Client struct {
+	// Kind is "tenant", "user", "key", "origin", or "ip", as the Subject of
+	// QuotaState.
+	Kind string
+	// Key is the quota counter key of the client, such as "key:" and the
+	// hash of an API key.
+	Key string
+}
+
+// Identified reports whether the client was identified by a tenant, a
+// signed-in user, or an API key rather than by origin or address.
+func (c Client) Identified() bool {
+	switch c.Kind {
+	case "tenant", "user", "key":
+		return true
+	default:
+		return false
+	}
+}
+
+// WithQuota counts each request against the daily and monthly quotas of
+// its client and answers 429 Too Many Requests once a quota is used up.
+// Clients are identified by the tenant recorded by WithTenant, then by the
+// signed-in user recorded by WithUserAuth, then by a known API key, then by an allowed origin, then by address. Requests are
+// let through if the counters cannot be updated. The client is recorded for
+// ClientFromContext even when quotas are disabled.
+func WithQuota(next http.Handler, limiter *quota.Limiter, opts QuotaOptions) http.Handler {
+	apiKeys := make([][]byte, 0, len(opts.APIKeys))
+	for _, key := range opts.APIKeys {
+		apiKeys = append(apiKeys, []byte(key))
+	}
+	matchers := compileValidOrigins(opts.AllowedOrigins)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientLimiter := limiter
+		kind, subject := quotaSubject(r, apiKeys, matchers)
+		if t := tenant.FromContext(r.Context()); t != nil {
+			clientLimiter = limiter.WithLimits(t.Daily, t.Monthly)
+			kind, subject = "tenant", "tenant:"+t.ID
+		} else if u := auth.UserFromContext(r.Context()); u != nil {
+			kind, subject = "user", "user:"+u.ID()
+		}
+		r = r.WithContext(context.WithValue(r.Context(), clientKey, Client{Kind: kind, Key: subject}))
+		if !clientLimiter.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		usages, err := clientLimiter.Consume(r.Context(), subject, time.Now())
+		if err != nil {
+			log.Printf("Quota check failed for %s: %v", kind, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tightest, _ := quota.Tightest(usages)
+		w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(tightest.Limit, 10))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(tightest.Remaining(), 10))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(tightest.ResetAt.Unix(), 10))
+		if tightest.Exceeded() {
+			retryAfter := int64(time.Until(tightest.ResetAt).Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+			message := fmt.Sprintf("%s quota of %d requests exceeded", tightest.Window, tightest.Limit)
+			if opts.GraphQL {
+				writeGraphQLError(w, http.StatusTooManyRequests, message, "QUOTA_EXCEEDED", true)
+				return
+			}
+			writeJSONError(w, http.StatusTooManyRequests, message)
+			return
+		}
+
+		state := &QuotaState{Subject: kind, Usages: usages, limiter: clientLimiter, key: subject}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), quotaKey, state)))
+	})
+}
+
+// writeGraphQLError writes a GraphQL response carrying a single error with
+// the "code" and "retryable" extensions set by the GraphQL server.
+func writeGraphQLError(w http.ResponseWriter, status int, message, code string, retryable bool) {
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]interface{}{{
+			"message": message,
+			"extensions": map[string]interface{}{
+				"code":      code,
+				"retryable": retryable,
+			},
+		}},
+	})
+}
+
+// QuotaFromContext returns the quota recorded by WithQuota, or nil when
+// quotas are disabled.
+func QuotaFromContext(ctx context.Context) *QuotaState {
+	state, _ := ctx.Value(quotaKey).(*QuotaState)
+	return state
+}
+
+// ClientFromContext returns the client recorded by WithQuota, or the zero
+// Client, which is not identified, without it.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey).(Client)
+	return client
+}
+
+// quotaSubject returns the kind of client and its counter key. API keys are
+// hashed so that they are not stored in plain text.
+func quotaSubject(r *http.Request, apiKeys [][]byte, matchers []originMatcher) (string, string) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		for _, known := range apiKeys {
+			if subtle.ConstantTimeCompare([]byte(key), known) == 1 {
+				sum := sha256.Sum256(known)
+				return "key", "key:" + hex.EncodeToString(sum[:])
+			}
+		}
+	}
+	if origin := r.Header.Get("Origin"); matchOrigin(origin, matchers) {
+		return "origin", "origin:" + origin
+	}
+	return "ip", "ip:" + RealIP(r)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	lawapi "go.ngs.io/jplaw-api-v2"
+
+	model1 "go.ngs.io/jplaw2epub-web-api/graphql/model"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+)
+
+// RESTBackend provides the operations behind the /v1 REST API. It is
+// implemented by the GraphQL resolver.
+type RESTBackend interface {
+	EpubSource
+	SearchLaws(ctx context.Context, params *lawapi.GetLawsParams) (*lawapi.LawsResponse, error)
+	GetLaw(ctx context.Context, id string) (*lawapi.LawItem, error)
+	GetEpubStatus(ctx context.Context, id string, articles []string) (*model1.Epub, error)
+	TitleEn(item *lawapi.LawItem) string
+}
+
+// lawSummary is the REST representation of a law and its revision.
+type lawSummary struct {
+	LawID            string `json:"lawId"`
+	LawNum           string `json:"lawNum"`
+	PromulgationDate string `json:"promulgationDate,omitempty"`
+	LawRevisionID    string `json:"lawRevisionId,omitempty"`
+	LawTitle         string `json:"lawTitle,omitempty"`
+	LawTitleKana     string `json:"lawTitleKana,omitempty"`
+	TitleEn          string `json:"titleEn,omitempty"`
+	Abbrev           string `json:"abbrev,omitempty"`
+	Category         string `json:"category,omitempty"`
+}
+
+// lawList is a page of search results.
+type lawList struct {
+	TotalCount int64        `json:"totalCount"`
+	Count      int64        `json:"count"`
+	NextOffset int64        `json:"nextOffset"`
+	Laws       []lawSummary `json:"laws"`
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+type restHandler struct {
+	backend RESTBackend
+}
+
+// NewRESTHandler serves the versioned REST API under /v1/.
+func NewRESTHandler(backend RESTBackend) http.Handler {
+	h := &restHandler{backend: backend}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/laws", h.searchLaws)
+	mux.HandleFunc("GET /v1/laws/{id}", h.getLaw)
+	mux.HandleFunc("GET /v1/epubs/{id}", h.getEpub)
+	mux.HandleFunc("POST /v1/epubs/{id}", h.requestEpub)
+	return mux
+}
+
+func (h *restHandler) searchLaws(w http.ResponseWriter, r *http.Request) {
+	params, err := lawsParams(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.backend.SearchLaws(r.Context(), params)
+	if err != nil {
+		log.Printf("REST law search failed: %v", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to search laws")
+		return
+	}
+
+	result := lawList{
+		TotalCount: resp.TotalCount,
+		Count:      resp.Count,
+		NextOffset: resp.NextOffset,
+		Laws:       make([]lawSummary, 0, len(resp.Laws)),
+	}
+	for i := range resp.Laws {
+		result.Laws = append(result.Laws, h.lawSummary(&resp.Laws[i]))
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *restHandler) getLaw(w http.ResponseWriter, r *http.Request) {
+	id, ok := lawIDParam(w, r)
+	if !ok {
+		return
+	}
+	law, err := h.backend.GetLaw(r.Context(), id)
+	if err != nil {
+		log.Printf("REST law lookup failed for %s: %v", id, err)
+		writeJSONError(w, http.StatusBadGateway, "failed to get law")
+		return
+	}
+	if law == nil {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("law %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, h.lawSummary(law))
+}
+
+// getEpub reports generation progress without starting generation.
+func (h *restHandler) getEpub(w http.ResponseWriter, r *http.Request) {
+	id, ok := lawIDParam(w, r)
+	if !ok {
+		return
+	}
+	epub, err := h.backend.GetEpubStatus(r.Context(), id, r.URL.Query()["articles"])
+	if errors.Is(err, jobs.ErrNotFound) {
+		writeJSONError(w, http.StatusNotFound, fmt.Sprintf("EPUB for %s has not been requested", id))
+		return
+	}
+	if err != nil {
+		log.Printf("REST EPUB status failed for %s: %v", id, err)
+		writeJSONError(w, errorStatus(w, err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, epub)
+}
+
+// requestEpub starts generation when needed. It answers 202 Accepted until
+// the EPUB is ready.
+func (h *restHandler) requestEpub(w http.ResponseWriter, r *http.Request) {
+	id, ok := lawIDParam(w, r)
+	if !ok {
+		return
+	}
+	epub, err := h.backend.GetEpub(r.Context(), id, r.URL.Query()["articles"])
+	if err != nil {
+		log.Printf("REST EPUB request failed for %s: %v", id, err)
+		writeJSONError(w, errorStatus(w, err), err.Error())
+		return
+	}
+
+	status := http.StatusOK
+	if epub.Status != model1.EpubStatusCompleted {
+		status = http.StatusAccepted
+		w.Header().Set("Location", "/v1/epubs/"+id)
+		w.Header().Set("Retry-After", "5")
+	}
+	writeJSON(w, status, epub)
+}
+
+func lawsParams(r *http.Request) (*lawapi.GetLawsParams, error) {
+	query := r.URL.Query()
+	params := &lawapi.GetLawsParams{}
+
+	stringParams := map[string]**string{
+		"lawId":        &params.LawId,
+		"lawTitle":     &params.LawTitle,
+		"lawTitleKana": &params.LawTitleKana,
+	}
+	for name, target := range stringParams {
+		if v := query.Get(name); v != "" {
+			*target = &v
+		}
+	}
+
+	if v := query.Get("lawNum"); v != "" {
+		lawNum, err := jpdate.NormalizeLawNum(v)
+		if err != nil {
+			return nil, err
+		}
+		params.LawNum = &lawNum
+	}
+
+	dateParams := map[string]**lawapi.Date{
+		"asof":               &params.Asof,
+		"promulgateDateFrom": &params.PromulgationDateFrom,
+		"promulgateDateTo":   &params.PromulgationDateTo,
+	}
+	for name, target := range dateParams {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := jpdate.Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		date := lawapi.Date(t)
+		*target = &date
+	}
+
+	intParams := map[string]**int32{
+		"limit":  &params.Limit,
+		"offset": &params.Offset,
+	}
+	for name, target := range intParams {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid %s %q: expected a non-negative integer", name, v)
+		}
+		n32 := int32(n)
+		*target = &n32
+	}
+
+	return params, nil
+}
+
+func (h *restHandler) lawSummary(item *lawapi.LawItem) lawSummary {
+	law := lawSummary{TitleEn: h.backend.TitleEn(item)}
+	if info := item.LawInfo; info != nil {
+		law.LawID = info.LawId
+		law.LawNum = info.LawNum
+		law.PromulgationDate = info.PromulgationDate.String()
+	}
+	revision := item.RevisionInfo
+	if revision == nil {
+		revision = item.CurrentRevisionInfo
+	}
+	if revision != nil {
+		law.LawRevisionID = revision.LawRevisionId
+		law.LawTitle = revision.LawTitle
+		law.LawTitleKana = revision.LawTitleKana
+		law.Abbrev = revision.Abbrev
+		law.Category = revision.Category
+	}
+	return law
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// lawIDParam reads the {id} path value, replying with 400 Bad Request when
+// it is not a law ID, law number, or revision ID.
+func lawIDParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	parsed, err := lawid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	return parsed.Value, true
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorBody{Error: message})
+}
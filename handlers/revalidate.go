@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+)
+
+// RevalidateResult reports one revalidation run.
+type RevalidateResult struct {
+	// Since is the start of the checked update window in RFC 3339 format.
+	Since       string `json:"since"`
+	CheckedLaws int    `json:"checkedLaws"`
+	// Stale lists the EPUB IDs marked outdated in this run.
+	Stale []string `json:"stale"`
+	// Regenerated lists the stale EPUBs whose regeneration was started.
+	Regenerated []string `json:"regenerated"`
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// Revalidator marks EPUBs of amended laws as stale.
+type Revalidator interface {
+	Revalidate(ctx context.Context) (*RevalidateResult, error)
+}
+
+// NewRevalidateHandler serves POST /admin/revalidate.
+func NewRevalidateHandler(revalidator Revalidator) http.HandlerFunc {
+	return adminTaskHandler("Revalidation", func(ctx context.Context) (interface{}, error) {
+		return revalidator.Revalidate(ctx)
+	})
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// WithTenant records the tenant owning the request's X-API-Key in the
+// request context, so that storage paths, quotas, and usage statistics are
+// scoped to it. Requests without a tenant key are served outside any
+// tenant. A tenant request may name the user it is made for in X-User-Id;
+// the tenant vouches for the user, so the header is ignored without a
+// tenant key. It returns next unchanged when store is nil.
+func WithTenant(next http.Handler, store tenant.Store) http.Handler {
+	if store == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t, err := store.Lookup(r.Context(), key)
+		if err != nil {
+			// Serving a tenant's request outside its scope would mix up
+			// its documents and accounting, so fail instead.
+			log.Printf("Tenant lookup failed: %v", err)
+			writeJSONError(w, http.StatusServiceUnavailable, "failed to authenticate tenant")
+			return
+		}
+		if t == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := tenant.WithContext(r.Context(), t)
+		if userID := r.Header.Get("X-User-Id"); userID != "" {
+			if err := tenant.ValidateUserID(userID); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			ctx = tenant.WithUser(ctx, userID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+)
+
+// XMLValidationResult is the outcome of validating an uploaded law XML
+// document.
+type XMLValidationResult struct {
+	Valid  bool                      `json:"valid"`
+	Errors []lawdata.ValidationError `json:"errors"`
+}
+
+// NewValidateHandler serves POST /convert/validate. The law XML or JSON is
+// the request body, or the "file" field of a multipart form. The document is
+// checked against the law XML schema without being converted, and the
+// problems found are returned with 200 OK whether or not it is valid.
+// Validation runs in the converter pool, and is answered with 503 Service
+// Unavailable and Retry-After when the pool is saturated.
+func NewValidateHandler(maxSize int64, pool *sandbox.Pool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+		data, err := readUpload(r)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("document exceeds %d bytes", maxSize))
+			return
+		}
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var errs []lawdata.ValidationError
+		extendWriteDeadline(w, pool, "upload")
+		err = pool.Run(r.Context(), sandbox.Estimate(len(data)), func() error {
+			errs = lawdata.ValidateDocument(data)
+			return nil
+		})
+		switch {
+		case errors.Is(err, sandbox.ErrSaturated):
+			w.Header().Set("Retry-After", "5")
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case errors.Is(err, sandbox.ErrTooLarge):
+			writeJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		case errors.Is(err, sandbox.ErrTimeout):
+			writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		case err != nil:
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if errs == nil {
+			errs = []lawdata.ValidationError{}
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, http.StatusOK, XMLValidationResult{Valid: len(errs) == 0, Errors: errs})
+	}
+}
+
+// readUpload returns the request body, or the "file" field of a multipart
+// form.
+func readUpload(r *http.Request) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return io.ReadAll(r.Body)
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read file field: %v", err)
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// Outcomes of a fixity check.
+const (
+	VerifyOK = "OK"
+	// VerifyMismatch means the stored content no longer matches the digest
+	// recorded when it was written.
+	VerifyMismatch = "MISMATCH"
+	// VerifyUnrecorded means no digest was recorded for the document.
+	VerifyUnrecorded = "UNRECORDED"
+)
+
+// VerifyResult reports a fixity check of a stored document. Digests are
+// hex SHA-256.
+type VerifyResult struct {
+	ID         string `json:"id"`
+	Path       string `json:"path"`
+	Size       int64  `json:"size"`
+	Status     string `json:"status"`
+	Recorded   string `json:"recordedSha256,omitempty"`
+	Computed   string `json:"computedSha256"`
+	VerifiedAt string `json:"verifiedAt"`
+}
+
+// Verifier re-checks stored documents against their recorded digests.
+type Verifier interface {
+	VerifyDocument(ctx context.Context, id string) (*VerifyResult, error)
+}
+
+// NewVerifyHandler serves GET /verify/{id}. It answers 200 OK when the
+// document matches its digest or has none recorded, and 409 Conflict when
+// it does not match.
+func NewVerifyHandler(verifier Verifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		id := r.PathValue("id")
+		if err := ValidateDocumentID(id); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		result, err := verifier.VerifyDocument(r.Context(), id)
+		if err != nil {
+			log.Printf("Failed to verify %s: %v", id, err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to verify document")
+			return
+		}
+		if result == nil {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("document %s not found", id))
+			return
+		}
+
+		status := http.StatusOK
+		if result.Status == VerifyMismatch {
+			status = http.StatusConflict
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		writeJSON(w, status, result)
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+)
+
+// WarmUpResult reports what a warm-up did for one EPUB.
+type WarmUpResult struct {
+	RevisionID string `json:"revisionId"`
+	// Status is the EPUB status after the warm-up; COMPLETED EPUBs were
+	// already generated and left untouched.
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// WarmUpper pre-generates popular EPUBs.
+type WarmUpper interface {
+	WarmUp(ctx context.Context) ([]WarmUpResult, error)
+}
+
+// NewWarmUpHandler serves POST /admin/warmup.
+func NewWarmUpHandler(warmer WarmUpper) http.HandlerFunc {
+	return adminTaskHandler("Warm-up", func(ctx context.Context) (interface{}, error) {
+		results, err := warmer.WarmUp(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"results": results}, nil
+	})
+}
@@ -0,0 +1,190 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// BucketStore keeps job metadata in `{prefix}/{id}.record.json` JSON
+// objects. The generator job overwrites `{prefix}/{id}.status` with its
+// progress, so the server's records are kept apart from it; status files
+// are only read for jobs without a record, as written by deployments that
+// predate the metadata store.
+type BucketStore struct {
+	bucket objects.Bucket
+	prefix string
+}
+
+// NewBucketStore returns a store in bucket, whose client is closed by the
+// caller.
+func NewBucketStore(bucket objects.Bucket, prefix string) *BucketStore {
+	return &BucketStore{bucket: bucket, prefix: prefix}
+}
+
+// recordSuffix ends the names of the objects holding job records.
+const recordSuffix = ".record.json"
+
+func (s *BucketStore) recordPath(id string) string {
+	return fmt.Sprintf("%s/%s%s", s.prefix, id, recordSuffix)
+}
+
+func (s *BucketStore) statusPath(id string) string {
+	return fmt.Sprintf("%s/%s.status", s.prefix, id)
+}
+
+// Get reads the record of a job, or its status file when it has none.
+func (s *BucketStore) Get(ctx context.Context, id string) (*Job, error) {
+	job, err := s.read(ctx, id, s.recordPath(id))
+	if !errors.Is(err, ErrNotFound) {
+		return job, err
+	}
+	job, err = s.read(ctx, id, s.statusPath(id))
+	if err == nil && job.Attempts == 0 {
+		// Status files of earlier releases and of the generator job
+		// without attempts stand for a first attempt.
+		job.Attempts = 1
+	}
+	return job, err
+}
+
+// read decodes the job record or status file at path.
+func (s *BucketStore) read(ctx context.Context, id, path string) (*Job, error) {
+	reader, err := s.bucket.NewReader(ctx, path)
+	if errors.Is(err, objects.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status for %s: %v", id, err)
+	}
+	defer reader.Close()
+
+	file, err := ParseStatusFile(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode status for %s: %v", id, err)
+	}
+
+	job := file.toJob(id)
+	if job.UpdatedAt.IsZero() {
+		attrs, err := s.bucket.Attrs(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read status attributes for %s: %v", id, err)
+		}
+		job.UpdatedAt = attrs.Updated
+	}
+	return job, nil
+}
+
+// Put writes the record of a job. The generator's status file is left
+// alone.
+func (s *BucketStore) Put(ctx context.Context, job *Job) error {
+	w := s.bucket.NewWriter(ctx, s.recordPath(job.ID), objects.WriteOptions{ContentType: "application/json"})
+	if err := json.NewEncoder(w).Encode(newStatusFile(job)); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("failed to write status for %s: %v", job.ID, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close status writer for %s: %v", job.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the record and the status file of a job, so that a job
+// recorded before the metadata store is not listed again.
+func (s *BucketStore) Delete(ctx context.Context, id string) error {
+	for _, path := range []string{s.recordPath(id), s.statusPath(id)} {
+		err := s.bucket.Delete(ctx, path, objects.Conditions{})
+		if err != nil && !errors.Is(err, objects.ErrNotExist) {
+			return fmt.Errorf("failed to delete status for %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// List scans the bucket prefix. Jobs are read from their records, or from
+// their status files when they have none. EPUB objects without either are
+// reported as completed jobs; only documents directly in the prefix or in a
+// tenant's directory are jobs, not the books of subdirectories such as
+// bundles/ and converted/.
+func (s *BucketStore) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	prefix := s.prefix + "/"
+	epubs := make(map[string]*objects.Attrs)
+	records := make(map[string]*objects.Attrs)
+
+	listed, err := s.bucket.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %v", err)
+	}
+	for _, attrs := range listed {
+		name := strings.TrimPrefix(attrs.Name, prefix)
+		switch {
+		case strings.HasSuffix(name, ".epub"):
+			id := strings.TrimSuffix(name, ".epub")
+			if _, docID := tenant.SplitID(id); strings.Contains(docID, "/") {
+				continue
+			}
+			epubs[id] = attrs
+		case strings.HasSuffix(name, recordSuffix):
+			records[strings.TrimSuffix(name, recordSuffix)] = attrs
+		case strings.HasSuffix(name, ".status"):
+			// The record of the job, listed before or after its status
+			// file, takes precedence.
+			id := strings.TrimSuffix(name, ".status")
+			if _, ok := records[id]; !ok {
+				records[id] = attrs
+			}
+		}
+	}
+
+	all := make([]*Job, 0, len(records)+len(epubs))
+	for id, attrs := range records {
+		job, err := s.Get(ctx, id)
+		if err != nil {
+			// Keep listing past unreadable status objects, which are not
+			// reported as jobs in any particular state.
+			log.Printf("Skipping job %s: %v", id, err)
+			continue
+		}
+		if job.UpdatedAt.IsZero() || attrs.Updated.After(job.UpdatedAt) {
+			job.UpdatedAt = attrs.Updated
+		}
+		if epub, ok := epubs[id]; ok {
+			markCompleted(job, epub)
+		}
+		all = append(all, job)
+	}
+	for id, epub := range epubs {
+		if _, ok := records[id]; ok {
+			continue
+		}
+		job := &Job{ID: id, RevisionID: id, CreatedAt: epub.Created, UpdatedAt: epub.Updated}
+		markCompleted(job, epub)
+		all = append(all, job)
+	}
+
+	return sortAndFilter(all, opts), nil
+}
+
+// markCompleted reflects an existing EPUB object on the job, which is
+// authoritative for completion regardless of the recorded status.
+func markCompleted(job *Job, epub *objects.Attrs) {
+	job.Status = StatusCompleted
+	job.OutputPath = epub.Name
+	if job.CompletedAt.IsZero() {
+		job.CompletedAt = epub.Created
+	}
+	if epub.Updated.After(job.UpdatedAt) {
+		job.UpdatedAt = epub.Updated
+	}
+}
+
+// Close does nothing; the bucket's client belongs to the caller.
+func (s *BucketStore) Close() error {
+	return nil
+}
@@ -0,0 +1,132 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+const prefix = "v1.0.0"
+
+func newBucketStore(t *testing.T) (*jobs.BucketStore, *testsupport.Storage) {
+	t.Helper()
+	storage := testsupport.NewStorage(t)
+	return jobs.NewBucketStore(storage.Bucket(testsupport.Bucket), prefix), storage
+}
+
+func TestBucketStoreRoundTrip(t *testing.T) {
+	store, _ := newBucketStore(t)
+	ctx := context.Background()
+	created := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	job := &jobs.Job{
+		ID:            "325AC0000000131_20250601_505AC0000000036",
+		RevisionID:    "325AC0000000131_20250601_505AC0000000036",
+		Status:        jobs.StatusProcessing,
+		Attempts:      2,
+		ExecutionName: "projects/p/locations/r/jobs/epub-generator/executions/abcde",
+		CreatedAt:     created,
+		UpdatedAt:     created.Add(time.Minute),
+		StartedAt:     created.Add(time.Minute),
+		Notify:        []string{"reader@example.com"},
+		Callbacks:     []string{"https://example.com/hook"},
+		Priority:      jobs.PriorityHigh,
+	}
+	if err := store.Put(ctx, job); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := store.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, job) {
+		t.Errorf("Get() = %+v, want %+v", got, job)
+	}
+
+	listed, err := store.List(ctx, jobs.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != job.ID || listed[0].Attempts != job.Attempts {
+		t.Errorf("List() = %+v, want the stored job", listed)
+	}
+
+	if err := store.Delete(ctx, job.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, job.ID); !errors.Is(err, jobs.ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBucketStoreKeepsRecordOverGeneratorStatus(t *testing.T) {
+	store, storage := newBucketStore(t)
+	ctx := context.Background()
+	job := &jobs.Job{
+		ID:         "129AC0000000089_20250101_000000000000000",
+		RevisionID: "129AC0000000089_20250101_000000000000000",
+		Status:     jobs.StatusPending,
+		Attempts:   3,
+		CreatedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Notify:     []string{"reader@example.com"},
+	}
+	if err := store.Put(ctx, job); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// The generator writes its progress without the server's fields.
+	storage.Put(testsupport.Bucket, prefix+"/"+job.ID+".status", []byte(`{"status":"processing"}`), "application/json")
+
+	got, err := store.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Attempts != 3 || !reflect.DeepEqual(got.Notify, job.Notify) {
+		t.Errorf("Get() = %+v, want the stored record", got)
+	}
+}
+
+func TestBucketStoreReadsStatusFileWithoutRecord(t *testing.T) {
+	store, storage := newBucketStore(t)
+	const id = "321CONSTITUTION_19470503_000000000000000"
+	storage.Put(testsupport.Bucket, prefix+"/"+id+".status", []byte(`{"status":"processing","createdAt":"2025-01-01T00:00:00Z"}`), "application/json")
+
+	got, err := store.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != jobs.StatusProcessing || got.Attempts != 1 || got.UpdatedAt.IsZero() {
+		t.Errorf("Get() = %+v, want a processing first attempt", got)
+	}
+}
+
+func TestBucketStoreListsOnlyJobDocuments(t *testing.T) {
+	store, storage := newBucketStore(t)
+	for _, name := range []string{
+		"321CONSTITUTION_19470503_000000000000000.epub",
+		"tenants/acme/129AC0000000089_20250101_000000000000000.epub",
+		"converted/0123456789abcdef.epub",
+		"bundles/b-1.epub",
+		"tenants/acme/bundles/b-2.epub",
+	} {
+		storage.Put(testsupport.Bucket, prefix+"/"+name, []byte("PK"), "application/epub+zip")
+	}
+
+	listed, err := store.List(context.Background(), jobs.ListOptions{})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var ids []string
+	for _, job := range listed {
+		ids = append(ids, job.ID)
+	}
+	sort.Strings(ids)
+	want := []string{"321CONSTITUTION_19470503_000000000000000", "tenants/acme/129AC0000000089_20250101_000000000000000"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("List() IDs = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// StoreConfig selects and configures a Store backend.
+type StoreConfig struct {
+	// Backend is "bucket", "firestore", or "memory".
+	Backend string
+	// Bucket and Prefix locate status objects for the bucket store.
+	Bucket objects.Bucket
+	Prefix string
+	// ProjectID and Collection locate documents for the firestore store.
+	ProjectID  string
+	Collection string
+}
+
+// NewStore creates the store selected by cfg.Backend. The bucket store keeps
+// status objects under `{bucket}/{prefix}/`.
+func NewStore(ctx context.Context, cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "bucket":
+		if cfg.Bucket == nil {
+			return nil, errors.New("the bucket job store needs a bucket")
+		}
+		return NewBucketStore(cfg.Bucket, cfg.Prefix), nil
+	case "firestore":
+		store, err := NewFirestoreStore(ctx, cfg.ProjectID, cfg.Collection)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown job store %q (expected bucket, firestore, or memory)", cfg.Backend)
+	}
+}
@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FirestoreStore keeps one document per job in a Firestore collection.
+// Listing by status requires a composite index on (status, updatedAt desc).
+type FirestoreStore struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreStore(ctx context.Context, projectID, collection string) (*FirestoreStore, error) {
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firestore client: %v", err)
+	}
+	return &FirestoreStore{client: client, collection: collection}, nil
+}
+
+func (s *FirestoreStore) Get(ctx context.Context, id string) (*Job, error) {
+	snap, err := s.client.Collection(s.collection).Doc(docID(id)).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job %s: %v", id, err)
+	}
+
+	var job Job
+	if err := snap.DataTo(&job); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %v", id, err)
+	}
+	job.ID = jobID(snap.Ref.ID)
+	if job.RevisionID == "" {
+		job.RevisionID = job.ID
+	}
+	return &job, nil
+}
+
+func (s *FirestoreStore) Put(ctx context.Context, job *Job) error {
+	if _, err := s.client.Collection(s.collection).Doc(docID(job.ID)).Set(ctx, job); err != nil {
+		return fmt.Errorf("failed to save job %s: %v", job.ID, err)
+	}
+	return nil
+}
+
+func (s *FirestoreStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.client.Collection(s.collection).Doc(docID(id)).Delete(ctx); err != nil {
+		return fmt.Errorf("failed to delete job %s: %v", id, err)
+	}
+	return nil
+}
+
+func (s *FirestoreStore) List(ctx context.Context, opts ListOptions) ([]*Job, error) {
+	query := s.client.Collection(s.collection).OrderBy("updatedAt", firestore.Desc)
+	if opts.Status != "" {
+		query = query.Where("status", "==", string(opts.Status))
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	snaps, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %v", err)
+	}
+
+	result := make([]*Job, 0, len(snaps))
+	for _, snap := range snaps {
+		var job Job
+		if err := snap.DataTo(&job); err != nil {
+			return nil, fmt.Errorf("failed to decode job %s: %v", snap.Ref.ID, err)
+		}
+		job.ID = jobID(snap.Ref.ID)
+		if job.RevisionID == "" {
+			job.RevisionID = job.ID
+		}
+		result = append(result, &job)
+	}
+	return result, nil
+}
+
+// docID names the document of a job. Job IDs of tenant documents contain
+// slashes, which Firestore reads as path separators, so they are replaced
+// with colons that never appear in job IDs.
+func docID(id string) string {
+	return strings.ReplaceAll(id, "/", ":")
+}
+
+// jobID is the inverse of docID.
+func jobID(docID string) string {
+	return strings.ReplaceAll(docID, ":", "/")
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
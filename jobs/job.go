@@ -0,0 +1,236 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Status is the lifecycle state of an EPUB generation job.
+type Status string
+
+const (
+	StatusPending    Status = "PENDING"
+	StatusProcessing Status = "PROCESSING"
+	StatusCompleted  Status = "COMPLETED"
+	StatusFailed     Status = "FAILED"
+	// StatusDeadLetter parks a job that failed every attempt. It is not
+	// retried automatically until an operator retries it.
+	StatusDeadLetter Status = "DEAD_LETTER"
+)
+
+// Stage is the step of a generation that the generator job reports in its
+// status file while it is processing.
+type Stage string
+
+const (
+	StageParse   Stage = "parse"
+	StageConvert Stage = "convert"
+	StageWrite   Stage = "write"
+)
+
+// Priority orders the jobs waiting for a generator slot when the number of
+// running generations is capped.
+type Priority string
+
+const (
+	PriorityHigh   Priority = "high"
+	PriorityNormal Priority = "normal"
+	// PriorityLow is for jobs started by the server itself, such as
+	// warm-ups and the regeneration of outdated EPUBs.
+	PriorityLow Priority = "low"
+)
+
+// rank returns a larger number for a higher priority, or -1 for an unknown
+// one.
+func (p Priority) rank() int {
+	switch p {
+	case PriorityHigh:
+		return 2
+	case PriorityNormal:
+		return 1
+	case PriorityLow:
+		return 0
+	default:
+		return -1
+	}
+}
+
+// Outranks reports whether p is started before other.
+func (p Priority) Outranks(other Priority) bool {
+	return p.rank() > other.rank()
+}
+
+// ErrNotFound is returned by Store.Get when no job exists for the ID.
+var ErrNotFound = errors.New("job not found")
+
+// Job is the metadata recorded for a single EPUB generation request.
+type Job struct {
+	ID            string    `firestore:"-"`
+	RevisionID    string    `firestore:"revisionId"`
+	Articles      []string  `firestore:"articles"`
+	Status        Status    `firestore:"status"`
+	Attempts      int       `firestore:"attempts"`
+	Requester     string    `firestore:"requester"`
+	OutputPath    string    `firestore:"outputPath"`
+	ExecutionName string    `firestore:"executionName"`
+	Error         string    `firestore:"error"`
+	CreatedAt     time.Time `firestore:"createdAt"`
+	UpdatedAt     time.Time `firestore:"updatedAt"`
+	StartedAt     time.Time `firestore:"startedAt"`
+	CompletedAt   time.Time `firestore:"completedAt"`
+	NextRetryAt   time.Time `firestore:"nextRetryAt"`
+	// Stage is the step the generator last reported for the running
+	// attempt; empty before it reports one.
+	Stage Stage `firestore:"stage"`
+	// CacheHits counts requests served from the finished EPUB.
+	CacheHits int `firestore:"cacheHits"`
+	// StaleAt is set when the law data changed after the EPUB was
+	// generated; the EPUB is regenerated on its next request.
+	StaleAt time.Time `firestore:"staleAt"`
+	// Notify lists the email addresses to notify when the job finishes;
+	// it is cleared once they are sent.
+	Notify []string `firestore:"notify"`
+	// Callbacks lists the URLs to post to when the job finishes; it is
+	// cleared once they are posted.
+	Callbacks []string `firestore:"callbacks"`
+	// DeadLetteredAt is when the job entered StatusDeadLetter.
+	DeadLetteredAt time.Time `firestore:"deadLetteredAt"`
+	// ValidationErrors lists the structural problems of a generated EPUB
+	// that was rejected; the job failed with them.
+	ValidationErrors []string `firestore:"validationErrors"`
+	// Priority orders the job among those waiting for a generator slot.
+	// Jobs recorded without one have PriorityNormal.
+	Priority Priority `firestore:"priority"`
+	// QueuedAt is set while the job waits for a generator slot, and is
+	// cleared when the generator is started.
+	QueuedAt time.Time `firestore:"queuedAt"`
+	// ArticleCount is the number of articles in the document, recorded
+	// for estimating generation times; zero when unknown.
+	ArticleCount int `firestore:"articleCount"`
+	// AccessedAt is when the finished EPUB was last served, for deleting
+	// EPUBs nobody requests.
+	AccessedAt time.Time `firestore:"accessedAt"`
+	// Version is the older converter version pinned by the request; empty
+	// for the current one.
+	Version string `firestore:"version"`
+	// Downloads counts the signed URLs issued for the finished EPUB and its
+	// downloads through the API.
+	Downloads int `firestore:"downloads"`
+	// Canary is the converter version of the canary generator the job was
+	// routed to; empty for the current generator. The EPUB is stored where
+	// the current generator would write it.
+	Canary string `firestore:"canary"`
+}
+
+// Popularity counts the requests of the EPUB: the generating one and each
+// later download. Records from before downloads were counted fall back to
+// CacheHits.
+func (j *Job) Popularity() int {
+	return 1 + max(j.Downloads, j.CacheHits)
+}
+
+// RecordAccess counts a download of the finished EPUB at now.
+func (j *Job) RecordAccess(now time.Time) {
+	j.AccessedAt = now
+	j.Downloads++
+}
+
+// EffectivePriority returns Priority, or PriorityNormal when it is unset.
+func (j *Job) EffectivePriority() Priority {
+	if j.Priority.rank() < 0 {
+		return PriorityNormal
+	}
+	return j.Priority
+}
+
+// DeadLetter moves a job that keeps failing to StatusDeadLetter.
+func (j *Job) DeadLetter(now time.Time) {
+	j.Status = StatusDeadLetter
+	j.DeadLetteredAt = now
+	j.UpdatedAt = now
+	j.NextRetryAt = time.Time{}
+}
+
+// Retry resets a failed or dead-lettered job for a new first attempt
+// started at now.
+func (j *Job) Retry(now time.Time) {
+	j.Status = StatusPending
+	j.Attempts = 1
+	j.Error = ""
+	j.ValidationErrors = nil
+	j.DeadLetteredAt = time.Time{}
+	j.NextRetryAt = time.Time{}
+	j.QueuedAt = time.Time{}
+	j.StartedAt = now
+	j.UpdatedAt = now
+}
+
+// Awaited reports whether emails or callbacks wait for the job to finish.
+func (j *Job) Awaited() bool {
+	return len(j.Notify) > 0 || len(j.Callbacks) > 0
+}
+
+// Duration returns how long the job has taken so far, or in total once it
+// has finished.
+func (j *Job) Duration(now time.Time) time.Duration {
+	if j.CreatedAt.IsZero() {
+		return 0
+	}
+
+	end := now
+	switch j.Status {
+	case StatusCompleted:
+		if !j.CompletedAt.IsZero() {
+			end = j.CompletedAt
+		}
+	case StatusFailed, StatusDeadLetter:
+		if !j.UpdatedAt.IsZero() {
+			end = j.UpdatedAt
+		}
+	case StatusPending, StatusProcessing:
+	}
+
+	if end.Before(j.CreatedAt) {
+		return 0
+	}
+	return end.Sub(j.CreatedAt)
+}
+
+// ListOptions filters and limits the jobs returned by Store.List.
+type ListOptions struct {
+	// Status restricts results to a single state when non-empty.
+	Status Status
+	// Limit caps the number of results; zero means no limit.
+	Limit int
+}
+
+// Store persists job metadata.
+type Store interface {
+	Get(ctx context.Context, id string) (*Job, error)
+	Put(ctx context.Context, job *Job) error
+	// Delete removes a job; deleting a missing job is not an error.
+	Delete(ctx context.Context, id string) error
+	// List returns jobs ordered by UpdatedAt, newest first.
+	List(ctx context.Context, opts ListOptions) ([]*Job, error)
+}
+
+// sortAndFilter applies ListOptions to an unordered set of jobs.
+func sortAndFilter(all []*Job, opts ListOptions) []*Job {
+	sort.Slice(all, func(i, k int) bool {
+		return all[i].UpdatedAt.After(all[k].UpdatedAt)
+	})
+
+	result := make([]*Job, 0, len(all))
+	for _, job := range all {
+		if opts.Status != "" && job.Status != opts.Status {
+			continue
+		}
+		result = append(result, job)
+		if opts.Limit > 0 && len(result) >= opts.Limit {
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ManifestVersion is the schemaVersion of the manifests written by this
+// server.
+const ManifestVersion = 1
+
+// FormatEPUB is the only output format of the generator.
+const FormatEPUB = "epub"
+
+// Spec is the request and the law metadata the API already knows, so that
+// the generator need not look them up again. Empty fields are unknown.
+type Spec struct {
+	RevisionID string   `json:"revisionId"`
+	LawID      string   `json:"lawId,omitempty"`
+	LawTitle   string   `json:"lawTitle,omitempty"`
+	LawNum     string   `json:"lawNum,omitempty"`
+	LawTitleEn string   `json:"lawTitleEn,omitempty"`
+	Articles   []string `json:"articles,omitempty"`
+	OutputID   string   `json:"outputId,omitempty"`
+	Version    string   `json:"version"`
+}
+
+// Manifest is the JSON layout of a `{id}.job.json` object, the input of a
+// generator execution. It is written next to the status file before each
+// execution, so that a job can be reproduced from it.
+type Manifest struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	JobID         string `json:"jobId"`
+	Spec
+	Format     string    `json:"format"`
+	Priority   Priority  `json:"priority"`
+	Requester  string    `json:"requester,omitempty"`
+	OutputPath string    `json:"outputPath"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// NewManifest returns the manifest of the next execution of a job.
+func NewManifest(job *Job, spec Spec, now time.Time) *Manifest {
+	return &Manifest{
+		SchemaVersion: ManifestVersion,
+		JobID:         job.ID,
+		Spec:          spec,
+		Format:        FormatEPUB,
+		Priority:      job.EffectivePriority(),
+		Requester:     job.Requester,
+		OutputPath:    job.OutputPath,
+		CreatedAt:     now,
+	}
+}
+
+// ManifestPath returns the object name of a job's manifest under prefix.
+func ManifestPath(prefix, id string) string {
+	return fmt.Sprintf("%s/%s.job.json", prefix, id)
+}
+
+// ParseManifest decodes a manifest. Newer schema versions and unknown
+// formats and priorities are errors.
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, err
+	}
+	if m.SchemaVersion < 1 || m.SchemaVersion > ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest schema version %d (expected 1 to %d)", m.SchemaVersion, ManifestVersion)
+	}
+	if m.Format != FormatEPUB {
+		return nil, fmt.Errorf("unsupported format %q", m.Format)
+	}
+	if m.Priority.rank() < 0 {
+		return nil, fmt.Errorf("unknown priority %q", m.Priority)
+	}
+	if m.JobID == "" || m.RevisionID == "" {
+		return nil, fmt.Errorf("manifest has no job or revision ID")
+	}
+	return &m, nil
+}
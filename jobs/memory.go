@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps jobs in process memory. It is intended for local
+// development; records are lost on restart and not shared between instances.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]Job
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &job, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = *job
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context, opts ListOptions) ([]*Job, error) {
+	s.mu.RLock()
+	all := make([]*Job, 0, len(s.jobs))
+	for id := range s.jobs {
+		job := s.jobs[id]
+		all = append(all, &job)
+	}
+	s.mu.RUnlock()
+
+	return sortAndFilter(all, opts), nil
+}
@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"time"
+)
+
+// RetryPolicy controls automatic re-triggering of failed generations.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is used when no overrides are configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     30 * time.Minute,
+	}
+}
+
+// Backoff returns the delay before the retry that follows the given number
+// of attempts, doubling from InitialBackoff up to MaxBackoff.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return backoff
+}
+
+// ScheduleRetry sets NextRetryAt on a failed job that has attempts left and
+// reports whether a retry is scheduled.
+func (p RetryPolicy) ScheduleRetry(job *Job, failedAt time.Time) bool {
+	if job.Status != StatusFailed || job.Attempts >= p.MaxAttempts {
+		job.NextRetryAt = time.Time{}
+		return false
+	}
+	if job.NextRetryAt.IsZero() {
+		job.NextRetryAt = failedAt.Add(p.Backoff(job.Attempts))
+	}
+	return true
+}
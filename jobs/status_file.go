@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// StatusFileVersion is the schemaVersion of the status files written by
+// this server. Files without schemaVersion were written before versioning,
+// by this server or the generator job, and are migrated on read.
+const StatusFileVersion = 1
+
+// StatusFile is the JSON layout of a `{id}.status` object.
+type StatusFile struct {
+	SchemaVersion int        `json:"schemaVersion,omitempty"`
+	Status        Status     `json:"status"`
+	Stage         Stage      `json:"stage,omitempty"`
+	RevisionID    string     `json:"revisionId,omitempty"`
+	Articles      []string   `json:"articles,omitempty"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt     *time.Time `json:"updatedAt,omitempty"`
+	StartedAt     *time.Time `json:"startedAt,omitempty"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	NextRetryAt   *time.Time `json:"nextRetryAt,omitempty"`
+	Attempts      int        `json:"attempts,omitempty"`
+	Requester     string     `json:"requester,omitempty"`
+	OutputPath    string     `json:"outputPath,omitempty"`
+	ExecutionName string     `json:"executionName,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CacheHits     int        `json:"cacheHits,omitempty"`
+	StaleAt       *time.Time `json:"staleAt,omitempty"`
+	Notify        []string   `json:"notify,omitempty"`
+	Callbacks     []string   `json:"callbacks,omitempty"`
+	// DeadLetteredAt is set with the DEAD_LETTER status.
+	DeadLetteredAt *time.Time `json:"deadLetteredAt,omitempty"`
+	Priority       Priority   `json:"priority,omitempty"`
+	QueuedAt       *time.Time `json:"queuedAt,omitempty"`
+	ArticleCount   int        `json:"articleCount,omitempty"`
+	AccessedAt     *time.Time `json:"accessedAt,omitempty"`
+	Downloads      int        `json:"downloads,omitempty"`
+	Version        string     `json:"version,omitempty"`
+	Canary         string     `json:"canary,omitempty"`
+}
+
+// ParseStatusFile decodes a status object and migrates it to
+// StatusFileVersion. Unknown statuses, malformed timestamps, and newer
+// schema versions are errors rather than being read as PENDING.
+func ParseStatusFile(r io.Reader) (*StatusFile, error) {
+	var file StatusFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	if err := file.migrate(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// migrate upgrades a decoded status file to StatusFileVersion.
+func (f *StatusFile) migrate() error {
+	if f.SchemaVersion > StatusFileVersion {
+		return fmt.Errorf("unsupported status schema version %d (expected at most %d)", f.SchemaVersion, StatusFileVersion)
+	}
+	if f.SchemaVersion == 0 {
+		// The first status files carried only status and createdAt, and
+		// generator jobs may write the status in lower case.
+		f.Status = Status(strings.ToUpper(string(f.Status)))
+		if f.Status == "" {
+			f.Status = StatusPending
+		}
+		if f.StartedAt == nil {
+			f.StartedAt = f.CreatedAt
+		}
+		// Attempts are left as written: the generator job never writes
+		// them, and its progress must not reset the count of a job.
+		f.SchemaVersion = StatusFileVersion
+	}
+
+	switch f.Status {
+	case StatusPending, StatusProcessing, StatusCompleted, StatusFailed, StatusDeadLetter:
+		return nil
+	default:
+		return fmt.Errorf("unknown status %q", f.Status)
+	}
+}
+
+func newStatusFile(job *Job) *StatusFile {
+	return &StatusFile{
+		SchemaVersion:  StatusFileVersion,
+		Status:         job.Status,
+		Stage:          job.Stage,
+		RevisionID:     job.RevisionID,
+		Articles:       job.Articles,
+		CreatedAt:      timestamp(job.CreatedAt),
+		UpdatedAt:      timestamp(job.UpdatedAt),
+		StartedAt:      timestamp(job.StartedAt),
+		CompletedAt:    timestamp(job.CompletedAt),
+		NextRetryAt:    timestamp(job.NextRetryAt),
+		Attempts:       job.Attempts,
+		Requester:      job.Requester,
+		OutputPath:     job.OutputPath,
+		ExecutionName:  job.ExecutionName,
+		Error:          job.Error,
+		CacheHits:      job.CacheHits,
+		StaleAt:        timestamp(job.StaleAt),
+		Notify:         job.Notify,
+		Callbacks:      job.Callbacks,
+		DeadLetteredAt: timestamp(job.DeadLetteredAt),
+		Priority:       job.Priority,
+		QueuedAt:       timestamp(job.QueuedAt),
+		ArticleCount:   job.ArticleCount,
+		AccessedAt:     timestamp(job.AccessedAt),
+		Downloads:      job.Downloads,
+		Version:        job.Version,
+		Canary:         job.Canary,
+	}
+}
+
+func (f *StatusFile) toJob(id string) *Job {
+	job := &Job{
+		ID:             id,
+		Status:         f.Status,
+		Stage:          f.Stage,
+		RevisionID:     f.RevisionID,
+		Articles:       f.Articles,
+		Attempts:       f.Attempts,
+		Requester:      f.Requester,
+		OutputPath:     f.OutputPath,
+		ExecutionName:  f.ExecutionName,
+		Error:          f.Error,
+		CreatedAt:      timeValue(f.CreatedAt),
+		UpdatedAt:      timeValue(f.UpdatedAt),
+		StartedAt:      timeValue(f.StartedAt),
+		CompletedAt:    timeValue(f.CompletedAt),
+		NextRetryAt:    timeValue(f.NextRetryAt),
+		CacheHits:      f.CacheHits,
+		StaleAt:        timeValue(f.StaleAt),
+		Notify:         f.Notify,
+		Callbacks:      f.Callbacks,
+		DeadLetteredAt: timeValue(f.DeadLetteredAt),
+		Priority:       f.Priority,
+		QueuedAt:       timeValue(f.QueuedAt),
+		ArticleCount:   f.ArticleCount,
+		AccessedAt:     timeValue(f.AccessedAt),
+		Downloads:      f.Downloads,
+		Version:        f.Version,
+		Canary:         f.Canary,
+	}
+	if job.RevisionID == "" {
+		// Status files written before excerpts were supported.
+		job.RevisionID = id
+	}
+	return job
+}
+
+// timestamp returns t at second precision, as status files have always
+// been written, or nil when t is zero.
+func timestamp(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC().Truncate(time.Second)
+	return &t
+}
+
+func timeValue(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
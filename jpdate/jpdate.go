@@ -0,0 +1,181 @@
+package jpdate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout is the ISO 8601 calendar date layout used by the e-Gov API.
+const Layout = "2006-01-02"
+
+// era is a Japanese era starting on its first day.
+type era struct {
+	name   string
+	abbrev string
+	start  time.Time
+}
+
+func eras() []era {
+	return []era{
+		{name: "明治", abbrev: "M", start: date(1868, time.October, 23)},
+		{name: "大正", abbrev: "T", start: date(1912, time.July, 30)},
+		{name: "昭和", abbrev: "S", start: date(1926, time.December, 25)},
+		{name: "平成", abbrev: "H", start: date(1989, time.January, 8)},
+		{name: "令和", abbrev: "R", start: date(2019, time.May, 1)},
+	}
+}
+
+var (
+	// eraPattern matches dates such as 令和5年4月1日, 令和元年五月一日, or
+	// those with full-width digits.
+	eraPattern = regexp.MustCompile(`^(明治|大正|昭和|平成|令和)\s*([0-9０-９〇一二三四五六七八九十]+|元)\s*年\s*([0-9０-９〇一二三四五六七八九十]+)\s*月\s*([0-9０-９〇一二三四五六七八九十]+)\s*日$`)
+	// abbrevPattern matches abbreviated era dates such as R5.4.1 or
+	// H31/4/30.
+	abbrevPattern = regexp.MustCompile(`^([MTSHRmtshr])\s*(\d{1,2})[./-](\d{1,2})[./-](\d{1,2})$`)
+)
+
+// Parse reads a date written as YYYY-MM-DD, YYYY/MM/DD, a Japanese era date
+// such as 令和5年4月1日 or 令和元年五月一日, or an abbreviated era date such
+// as R5.4.1. Era dates must fall within their era. The result is midnight
+// UTC of that calendar day.
+func Parse(value string) (time.Time, error) {
+	s := strings.TrimSpace(value)
+	if t, err := time.Parse("2006-1-2", strings.ReplaceAll(s, "/", "-")); err == nil {
+		return t, nil
+	}
+
+	var eraName, year, month, day string
+	if m := eraPattern.FindStringSubmatch(s); m != nil {
+		eraName, year, month, day = m[1], m[2], m[3], m[4]
+	} else if m := abbrevPattern.FindStringSubmatch(s); m != nil {
+		eraName, year, month, day = strings.ToUpper(m[1]), m[2], m[3], m[4]
+	} else {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or a Japanese era date such as 令和5年4月1日", value)
+	}
+
+	t, err := eraDate(eraName, year, month, day)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %v", value, err)
+	}
+	return t, nil
+}
+
+// FormatEra writes t as a Japanese era date such as 令和5年4月1日, using 元年
+// for the first year of an era.
+func FormatEra(t time.Time) string {
+	t = date(t.Date())
+	all := eras()
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if t.Before(e.start) {
+			continue
+		}
+		year := strconv.Itoa(t.Year() - e.start.Year() + 1)
+		if year == "1" {
+			year = "元"
+		}
+		return fmt.Sprintf("%s%s年%d月%d日", e.name, year, int(t.Month()), t.Day())
+	}
+	return t.Format(Layout)
+}
+
+func eraDate(eraName, year, month, day string) (time.Time, error) {
+	all := eras()
+	index := -1
+	for i, e := range all {
+		if e.name == eraName || e.abbrev == eraName {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return time.Time{}, fmt.Errorf("unknown era %s", eraName)
+	}
+	e := all[index]
+
+	y := 1
+	if year != "元" {
+		n, err := parseNumber(year)
+		if err != nil {
+			return time.Time{}, err
+		}
+		y = n
+	}
+	m, err := parseNumber(month)
+	if err != nil {
+		return time.Time{}, err
+	}
+	d, err := parseNumber(day)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if y < 1 {
+		return time.Time{}, fmt.Errorf("year must be at least 1")
+	}
+
+	t := date(e.start.Year()+y-1, time.Month(m), d)
+	if int(t.Month()) != m || t.Day() != d {
+		return time.Time{}, fmt.Errorf("no such day")
+	}
+	if t.Before(e.start) {
+		return time.Time{}, fmt.Errorf("%s began on %s", e.name, e.start.Format(Layout))
+	}
+	if index+1 < len(all) && !t.Before(all[index+1].start) {
+		return time.Time{}, fmt.Errorf("%s ended on %s", e.name, all[index+1].start.AddDate(0, 0, -1).Format(Layout))
+	}
+	return t, nil
+}
+
+// parseNumber reads ASCII or full-width digits, or kanji numerals up to 99
+// such as 十二 or 二十三.
+func parseNumber(s string) (int, error) {
+	var ascii strings.Builder
+	kanji := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			ascii.WriteRune(r)
+		case r >= '０' && r <= '９':
+			ascii.WriteRune('0' + (r - '０'))
+		default:
+			kanji = true
+		}
+	}
+	if !kanji {
+		return strconv.Atoi(ascii.String())
+	}
+	return parseKanjiNumber(s)
+}
+
+func parseKanjiNumber(s string) (int, error) {
+	digits := map[rune]int{'〇': 0, '一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	tens, ones := 0, 0
+	seenTen := false
+	for _, r := range s {
+		if r == '十' {
+			if seenTen {
+				return 0, fmt.Errorf("invalid number %s", s)
+			}
+			seenTen = true
+			tens = ones
+			if tens == 0 {
+				tens = 1
+			}
+			ones = 0
+			continue
+		}
+		n, ok := digits[r]
+		if !ok {
+			return 0, fmt.Errorf("invalid number %s", s)
+		}
+		ones = ones*10 + n
+	}
+	return tens*10 + ones, nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,77 @@
+package jpdate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2023-04-01", date(2023, time.April, 1)},
+		{"2023/4/1", date(2023, time.April, 1)},
+		{" 2023-04-01 ", date(2023, time.April, 1)},
+		{"令和5年4月1日", date(2023, time.April, 1)},
+		{"令和元年五月一日", date(2019, time.May, 1)},
+		{"令和５年４月１日", date(2023, time.April, 1)},
+		{"昭和二十五年五月二日", date(1950, time.May, 2)},
+		{"平成 31 年 4 月 30 日", date(2019, time.April, 30)},
+		{"明治元年十月二十三日", date(1868, time.October, 23)},
+		{"R5.4.1", date(2023, time.April, 1)},
+		{"H31/4/30", date(2019, time.April, 30)},
+		{"s64-1-7", date(1989, time.January, 7)},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("Parse(%q) = %s, want %s", tt.in, got.Format(Layout), tt.want.Format(Layout))
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"2023-13-01",
+		"令和5年2月30日",
+		// Heisei ended on 2019-04-30 and Showa on 1989-01-07.
+		"平成31年5月1日",
+		"昭和64年1月8日",
+		// Meiji began on 1868-10-23.
+		"明治元年1月1日",
+		"令和0年1月1日",
+		"令和五年十十月一日",
+		"西暦5年4月1日",
+		"X5.4.1",
+		"R5.4",
+	} {
+		if got, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", in, got.Format(Layout))
+		}
+	}
+}
+
+func TestFormatEra(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want string
+	}{
+		{date(2023, time.April, 1), "令和5年4月1日"},
+		{date(2019, time.May, 1), "令和元年5月1日"},
+		{date(2019, time.April, 30), "平成31年4月30日"},
+		{date(1989, time.January, 7), "昭和64年1月7日"},
+		{time.Date(2023, time.April, 1, 23, 0, 0, 0, time.UTC), "令和5年4月1日"},
+		{date(1868, time.October, 22), "1868-10-22"},
+	}
+	for _, tt := range tests {
+		if got := FormatEra(tt.in); got != tt.want {
+			t.Errorf("FormatEra(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package jpdate
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// lawNumeral is a year or number of a law number in digits or kanji.
+const lawNumeral = `[0-9０-９〇一二三四五六七八九十百千]+`
+
+// lawNumPattern captures the era, the year, the kind of law, and the
+// number of a law number.
+var lawNumPattern = regexp.MustCompile(`^(明治|大正|昭和|平成|令和)(元|` + lawNumeral + `)年([^0-9０-９第号]+)(?:第(` + lawNumeral + `)号)?$`)
+
+// NormalizeLawNum validates a law number such as 昭和二十五年法律第百三十一号
+// and rewrites it the way e-Gov stores it: whitespace is removed and ASCII or
+// full-width digits become kanji numerals, so 令和5年法律第36号 becomes
+// 令和五年法律第三十六号 and 令和1年 becomes 令和元年. Numbers without 第…号,
+// such as 昭和二十一年憲法, are accepted.
+func NormalizeLawNum(value string) (string, error) {
+	s := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, value)
+
+	m := lawNumPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("invalid law number %q: expected a number such as 昭和二十五年法律第百三十一号", value)
+	}
+
+	year := m[2]
+	if year != "元" {
+		n, err := kanjiNumeral(year)
+		if err != nil {
+			return "", fmt.Errorf("invalid law number %q: %v", value, err)
+		}
+		if n == "一" {
+			n = "元"
+		}
+		year = n
+	}
+	normalized := m[1] + year + "年" + m[3]
+	if m[4] != "" {
+		n, err := kanjiNumeral(m[4])
+		if err != nil {
+			return "", fmt.Errorf("invalid law number %q: %v", value, err)
+		}
+		normalized += "第" + n + "号"
+	}
+	return normalized, nil
+}
+
+// kanjiNumeral rewrites ASCII or full-width digits as kanji numerals such
+// as 百三十一 and returns kanji numerals unchanged.
+func kanjiNumeral(s string) (string, error) {
+	var ascii strings.Builder
+	digits, kanji := false, false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+			ascii.WriteRune(r)
+		case r >= '０' && r <= '９':
+			digits = true
+			ascii.WriteRune('0' + (r - '０'))
+		default:
+			kanji = true
+		}
+	}
+	if !digits {
+		return s, nil
+	}
+	if kanji {
+		return "", fmt.Errorf("number %s mixes digits and kanji", s)
+	}
+
+	n, err := strconv.Atoi(ascii.String())
+	if err != nil || n < 1 || n > 9999 {
+		return "", fmt.Errorf("number %s is out of range", s)
+	}
+	numerals := []string{"", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	units := []struct {
+		value int
+		name  string
+	}{{1000, "千"}, {100, "百"}, {10, "十"}}
+	var b strings.Builder
+	for _, unit := range units {
+		d := n / unit.value
+		n %= unit.value
+		if d == 0 {
+			continue
+		}
+		if d > 1 {
+			b.WriteString(numerals[d])
+		}
+		b.WriteString(unit.name)
+	}
+	b.WriteString(numerals[n])
+	return b.String(), nil
+}
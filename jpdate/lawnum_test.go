@@ -0,0 +1,47 @@
+package jpdate
+
+import "testing"
+
+func TestNormalizeLawNum(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"昭和二十五年法律第百三十一号", "昭和二十五年法律第百三十一号"},
+		{"昭和25年法律第131号", "昭和二十五年法律第百三十一号"},
+		{"令和5年法律第36号", "令和五年法律第三十六号"},
+		{"令和１年法律第１号", "令和元年法律第一号"},
+		{"令和元年 法律 第一号", "令和元年法律第一号"},
+		{"平成11年政令第1000号", "平成十一年政令第千号"},
+		{"平成11年政令第2019号", "平成十一年政令第二千十九号"},
+		{"昭和二十一年憲法", "昭和二十一年憲法"},
+		{"明治29年法律第89号", "明治二十九年法律第八十九号"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeLawNum(tt.in)
+		if err != nil {
+			t.Errorf("NormalizeLawNum(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeLawNum(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeLawNumInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"法律第百三十一号",
+		"西暦25年法律第131号",
+		"昭和25年第131号",
+		"昭和25年法律第131",
+		"昭和2五年法律第131号",
+		"昭和25年法律第0号",
+		"昭和25年法律第10000号",
+	} {
+		if got, err := NormalizeLawNum(in); err == nil {
+			t.Errorf("NormalizeLawNum(%q) = %q, want an error", in, got)
+		}
+	}
+}
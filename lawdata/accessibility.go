@@ -0,0 +1,156 @@
+package lawdata
+
+import (
+	"fmt"
+	"html/template"
+)
+
+// ContentsEntry is a table of contents entry pointing at the anchor of a
+// supplementary provision, division, or article.
+type ContentsEntry struct {
+	Anchor   string
+	Label    string
+	Children []ContentsEntry
+	// num is the article number of article entries in the main provision.
+	num string
+}
+
+// accessibilityFuncs adds the template functions for accessible output:
+// "accessible" reports whether it is enabled, "heading" writes a division heading at its
+// outline level, "divisionType" and "divisionRole" return the epub:type and
+// DPUB-ARIA role of a division, and "toc" lists the table of contents.
+// Without accessible, headings stay at h2 and the table of contents is
+// empty.
+func accessibilityFuncs(funcs template.FuncMap, law *Law, accessible bool) {
+	text := funcs["text"].(func(string) template.HTML)
+	levels := headingLevels(law)
+
+	funcs["accessible"] = func() bool { return accessible }
+	funcs["heading"] = func(kind, title string) template.HTML {
+		level := 2
+		if accessible {
+			level = levels[kind]
+		}
+		return template.HTML(fmt.Sprintf("<h%d>%s</h%d>", level, text(title), level))
+	}
+	funcs["divisionType"] = func(kind string) string {
+		switch kind {
+		case "Part":
+			return "part"
+		case "Chapter":
+			return "chapter"
+		case "Section":
+			return "subchapter"
+		default:
+			return "division"
+		}
+	}
+	funcs["divisionRole"] = func(kind string) string {
+		switch kind {
+		case "Part":
+			return "doc-part"
+		case "Chapter":
+			return "doc-chapter"
+		default:
+			return ""
+		}
+	}
+	funcs["toc"] = func() []ContentsEntry {
+		if !accessible {
+			return nil
+		}
+		return law.TableOfContents()
+	}
+}
+
+// headingLevels assigns consecutive heading levels from h2 to the division
+// kinds used in the law, so that no level is skipped.
+func headingLevels(law *Law) map[string]int {
+	used := make(map[string]bool)
+	var visit func(divisions []Division)
+	visit = func(divisions []Division) {
+		for _, division := range divisions {
+			used[division.Kind] = true
+			visit(division.Divisions)
+		}
+	}
+	for _, provision := range law.provisions() {
+		visit(provision.Divisions)
+	}
+
+	levels := make(map[string]int)
+	level := 2
+	for _, kind := range []string{"Part", "Chapter", "Section", "Subsection", "Division"} {
+		levels[kind] = level
+		if used[kind] && level < 6 {
+			level++
+		}
+	}
+	return levels
+}
+
+// TableOfContents lists the divisions and articles of the main provision,
+// followed by the supplementary provisions with theirs, in document order.
+func (l *Law) TableOfContents() []ContentsEntry {
+	articles := func(articles []Article, main bool) []ContentsEntry {
+		var entries []ContentsEntry
+		for _, article := range articles {
+			// Removed articles of a redline have no anchor.
+			if article.Anchor == "" {
+				continue
+			}
+			entry := ContentsEntry{Anchor: article.Anchor, Label: article.Title + article.Caption}
+			if main {
+				entry.num = article.Num
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+	var divisions func(divisions []Division, main bool) []ContentsEntry
+	divisions = func(list []Division, main bool) []ContentsEntry {
+		var entries []ContentsEntry
+		for _, division := range list {
+			entry := ContentsEntry{Anchor: division.Anchor, Label: division.Title}
+			entry.Children = append(divisions(division.Divisions, main), articles(division.Articles, main)...)
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+	provision := func(p Provision, main bool) []ContentsEntry {
+		return append(divisions(p.Divisions, main), articles(p.Articles, main)...)
+	}
+
+	var entries []ContentsEntry
+	if l.MainProvision != nil {
+		entries = provision(*l.MainProvision, true)
+	}
+	for _, suppl := range l.SupplProvisions {
+		entry := ContentsEntry{Anchor: suppl.Anchor, Label: suppl.heading()}
+		entry.Children = provision(suppl, false)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// articleIDs maps the numbers of the articles in the main provision to
+// their anchors.
+func (l *Law) articleIDs() map[string]string {
+	ids := make(map[string]string)
+	if l.MainProvision == nil {
+		return ids
+	}
+	for _, article := range l.MainProvision.allArticles() {
+		ids[article.Num] = article.Anchor
+	}
+	return ids
+}
+
+// provisions returns the main provision, if any, followed by the
+// supplementary provisions.
+func (l *Law) provisions() []Provision {
+	if l.MainProvision == nil {
+		return l.SupplProvisions
+	}
+	return append([]Provision{*l.MainProvision}, l.SupplProvisions...)
+}
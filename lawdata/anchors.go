@@ -0,0 +1,103 @@
+package lawdata
+
+import (
+	"strconv"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// Anchors identify the parts of a law with element IDs that stay the same
+// across output formats and requests, so that a position found through the
+// API can be linked into an HTML document or EPUB of the same revision:
+//
+//	law-{lawId}                      prefix of every anchor of the law
+//	law-{lawId}-a{article}           article of the main provision
+//	law-{lawId}-a{article}-p{para}   paragraph of an article
+//	law-{lawId}-chapter{num}         division, nested as -part1-chapter2
+//	law-{lawId}-suppl{n}             nth supplementary provision
+//	law-{lawId}-suppl{n}-a{article}  article of a supplementary provision
+//	law-{lawId}-p{para}              paragraph outside articles, also as
+//	                                 law-{lawId}-suppl{n}-p{para}
+//
+// article and num are the XML Num attributes, such as 3_2 for 第三条の二,
+// with characters other than ASCII letters, digits, and underscores
+// replaced by dots, and para is the paragraph number, counted from 1 when
+// the XML has none. The law ID is the e-Gov law ID, derived from the law
+// number when the XML came without one, and the prefix is just law when
+// neither is known.
+
+// assignAnchors sets the Anchor of every division, article, paragraph, and
+// supplementary provision of the law.
+func (l *Law) assignAnchors() {
+	prefix := "law"
+	lawID := l.LawID
+	if lawID == "" {
+		lawID, _ = lawref.LawID(l.LawNum)
+	}
+	if lawID != "" {
+		prefix += "-" + anchorPart(lawID)
+	}
+
+	seen := make(map[string]int)
+	unique := func(anchor string) string {
+		seen[anchor]++
+		if n := seen[anchor]; n > 1 {
+			return anchor + "." + strconv.Itoa(n)
+		}
+		return anchor
+	}
+
+	paragraphs := func(prefix string, list []Paragraph) {
+		for i := range list {
+			num := list[i].Num
+			if num == "" {
+				num = strconv.Itoa(i + 1)
+			}
+			list[i].Anchor = unique(prefix + "-p" + anchorPart(num))
+		}
+	}
+	articles := func(prefix string, list []Article) {
+		for i := range list {
+			list[i].Anchor = unique(prefix + "-a" + anchorPart(list[i].Num))
+			paragraphs(list[i].Anchor, list[i].Paragraphs)
+		}
+	}
+	// Articles are numbered throughout a provision, so their anchors do
+	// not include the divisions above them.
+	var divisions func(parent, provision string, list []Division)
+	divisions = func(parent, provision string, list []Division) {
+		for i := range list {
+			d := &list[i]
+			d.Anchor = unique(parent + "-" + strings.ToLower(d.Kind) + anchorPart(d.Num))
+			divisions(d.Anchor, provision, d.Divisions)
+			articles(provision, d.Articles)
+		}
+	}
+	provision := func(anchor string, p *Provision) {
+		divisions(anchor, anchor, p.Divisions)
+		articles(anchor, p.Articles)
+		paragraphs(anchor, p.Paragraphs)
+	}
+
+	if l.MainProvision != nil {
+		provision(prefix, l.MainProvision)
+	}
+	for i := range l.SupplProvisions {
+		p := &l.SupplProvisions[i]
+		p.Anchor = unique(prefix + "-suppl" + strconv.Itoa(i+1))
+		provision(p.Anchor, p)
+	}
+}
+
+// anchorPart replaces the characters of s that are not ASCII letters,
+// digits, or underscores with dots, so that anchors are valid XML IDs and
+// URL fragments.
+func anchorPart(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '.'
+	}, s)
+}
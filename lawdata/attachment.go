@@ -0,0 +1,93 @@
+package lawdata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// MaxAttachmentSize bounds the size of a single attachment download.
+const MaxAttachmentSize = 32 << 20
+
+// ErrInvalidSrc is returned for attachment paths that could escape the
+// law's attachment directory.
+var ErrInvalidSrc = errors.New("invalid attachment src")
+
+// Attachment is an appended figure, table, or form referenced from a law
+// body through a Fig src attribute.
+type Attachment struct {
+	LawRevisionID string `json:"law_revision_id"`
+	Src           string `json:"src"`
+	Updated       string `json:"updated"`
+}
+
+// URL returns the API path that proxies the attachment.
+func (a Attachment) URL() string {
+	return fmt.Sprintf("/attachments/%s/%s", url.PathEscape(a.LawRevisionID), CleanSrc(a.Src))
+}
+
+// CleanSrc normalizes an attachment src such as "./pict/H11HO031-001.jpg"
+// to a relative path without the leading "./". It returns an empty string
+// for paths containing parent references.
+func CleanSrc(src string) string {
+	src = strings.TrimPrefix(src, "./")
+	cleaned := path.Clean("/" + src)
+	if cleaned == "/" || cleaned != "/"+src {
+		return ""
+	}
+	return strings.TrimPrefix(cleaned, "/")
+}
+
+// FetchAttachment downloads an attachment of a law revision and returns its
+// content and content type.
+func (c *Client) FetchAttachment(ctx context.Context, revisionID, src string) ([]byte, string, error) {
+	cleaned := CleanSrc(src)
+	if cleaned == "" {
+		return nil, "", ErrInvalidSrc
+	}
+
+	endpoint := fmt.Sprintf("%s/attachment/%s?src=%s", c.BaseURL, url.PathEscape(revisionID), url.QueryEscape("./"+cleaned))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if os.IsTimeout(err) {
+		return nil, "", ErrTimeout
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch attachment: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, "", fmt.Errorf("attachment request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read attachment: %v", err)
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, "", fmt.Errorf("attachment exceeds %d bytes", MaxAttachmentSize)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
@@ -0,0 +1,123 @@
+package lawdata
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// bundleTarget is a law of a bundle that cross-references link to.
+type bundleTarget struct {
+	href       string
+	articleIDs map[string]string
+}
+
+// WriteBundleEPUB writes laws as one EPUB 3 book titled title, with a
+// content document per law in order, such as an act followed by its
+// enforcement order and regulations. The table of contents lists every law
+// with, in accessible books, its divisions and articles, and
+// cross-references to another law of the book link into it rather than to
+// e-Gov. The id becomes the book's unique identifier; the other metadata
+// is that of the first law. A cover shows the title alone.
+func WriteBundleEPUB(w io.Writer, laws []*Law, title, id string, opts Options) error {
+	if len(laws) == 0 {
+		return errors.New("no laws to bundle")
+	}
+
+	cover, err := newCoverPage(opts.Cover, title, "", "")
+	if err != nil {
+		return err
+	}
+
+	laws = slices.Clone(laws)
+	documents := make([]bookDocument, len(laws))
+	targets := make(map[string]bundleTarget)
+	for i, law := range laws {
+		if opts.OmitSupplProvisions {
+			law = law.withoutSupplProvisions()
+			laws[i] = law
+		}
+		n := strconv.Itoa(i + 1)
+		document := bookDocument{ID: "law" + n, Href: "law" + n + ".xhtml", Title: law.LawTitle}
+		if opts.Accessible {
+			document.Contents = navEntries(document.Href, law.TableOfContents())
+		}
+		documents[i] = document
+		if lawID := law.Metadata().LawID; lawID != "" {
+			if _, ok := targets[lawID]; !ok {
+				targets[lawID] = bundleTarget{href: document.Href, articleIDs: law.articleIDs()}
+			}
+		}
+	}
+
+	files := make([]bookFile, 2, len(laws)+2)
+	for i, law := range laws {
+		tmpl, err := bookTemplate(law, opts, bundleLinks(law, targets))
+		if err != nil {
+			return err
+		}
+		if i == 0 {
+			metadata := law.Metadata()
+			metadata.Title = title
+			metadata.TitleKana = ""
+			metadata.TitleEn = ""
+			opf, err := renderBookFile(tmpl, "OEBPS/content.opf", "opf", opfData{id, metadata, modified(), documents, cover, opts.Font})
+			if err != nil {
+				return err
+			}
+			nav := struct {
+				Title      string
+				Documents  []bookDocument
+				Bodymatter *bookDocument
+			}{Title: title, Documents: documents}
+			if law.MainProvision != nil {
+				nav.Bodymatter = &documents[0]
+			}
+			navData, err := renderBookFile(tmpl, "OEBPS/nav.xhtml", "bundleNav", nav)
+			if err != nil {
+				return err
+			}
+			files[0] = bookFile{"OEBPS/content.opf", opf}
+			files[1] = bookFile{"OEBPS/nav.xhtml", navData}
+			if cover != nil {
+				coverFiles, err := cover.files(tmpl)
+				if err != nil {
+					return err
+				}
+				files = append(files, coverFiles...)
+			}
+		}
+
+		name := "OEBPS/" + documents[i].Href
+		data, err := renderBookFile(tmpl, name, "xhtml", law)
+		if err != nil {
+			return err
+		}
+		files = append(files, bookFile{name, data})
+	}
+	files = append(files, fontFile(opts)...)
+	return writeBook(w, files)
+}
+
+// bundleLinks links the cross-references of a law of a bundle as lawLinks
+// does, except that references to the laws in targets, by law ID, link to
+// their documents.
+func bundleLinks(law *Law, targets map[string]bundleTarget) func(lawref.Reference) string {
+	links := lawLinks(law.articleIDs())
+	return func(ref lawref.Reference) string {
+		if ref.LawNum != "" {
+			if id, ok := lawref.LawID(ref.LawNum); ok {
+				if target, ok := targets[id]; ok {
+					if anchor, ok := target.articleIDs[ref.Article]; ok {
+						return target.href + "#" + anchor
+					}
+					return target.href
+				}
+			}
+		}
+		return links(ref)
+	}
+}
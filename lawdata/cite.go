@@ -0,0 +1,160 @@
+package lawdata
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+	"go.ngs.io/jplaw2epub-web-api/text"
+)
+
+// CitationStyle is a format of the citations written by Cite.
+type CitationStyle string
+
+const (
+	// CitationJapanese follows Japanese legal writing: the title, the law
+	// number in parentheses, and the article, as in
+	// 電波法（昭和二十五年法律第百三十一号）第四条.
+	CitationJapanese CitationStyle = "JAPANESE"
+	// CitationBluebook romanizes the title after the Bluebook's rule for
+	// Japanese statutes, as in Denpahō [Radio Act], Act No. 131 of 1950,
+	// art. 4 (Japan).
+	CitationBluebook CitationStyle = "BLUEBOOK"
+	// CitationBibTeX writes a @misc entry.
+	CitationBibTeX CitationStyle = "BIBTEX"
+)
+
+// lawTypesEn names the law types in the law numbers of Bluebook citations.
+var lawTypesEn = map[string]string{
+	"Act":                  "Act",
+	"CabinetOrder":         "Cabinet Order",
+	"ImperialOrder":        "Imperial Ordinance",
+	"MinisterialOrdinance": "Ministerial Ordinance",
+	"Rule":                 "Rule",
+}
+
+// FindArticle returns the article of the main provision with the Num
+// attribute num, such as 3_2, or the title given in kanji, such as 第三条の二.
+func (l *Law) FindArticle(num string) (Article, bool) {
+	if refs := lawref.Find(num); len(refs) == 1 && refs[0].Text == num && refs[0].Article != "" {
+		num = refs[0].Article
+	}
+	if l.MainProvision == nil {
+		return Article{}, false
+	}
+	for _, article := range l.MainProvision.allArticles() {
+		if article.Num == num {
+			return article, true
+		}
+	}
+	return Article{}, false
+}
+
+// Cite formats a citation of the law, or of one of its articles when
+// article is not nil.
+func (l *Law) Cite(style CitationStyle, article *Article) (string, error) {
+	switch style {
+	case CitationJapanese:
+		return l.citeJapanese(article), nil
+	case CitationBluebook:
+		return l.citeBluebook(article), nil
+	case CitationBibTeX:
+		return l.citeBibTeX(article), nil
+	default:
+		return "", fmt.Errorf("unknown citation style %q", style)
+	}
+}
+
+func (l *Law) citeJapanese(article *Article) string {
+	var b strings.Builder
+	b.WriteString(l.LawTitle)
+	// The Constitution is cited without its promulgation.
+	if l.LawType != "Constitution" && l.LawNum != "" {
+		b.WriteString("（" + l.LawNum + "）")
+	}
+	if article != nil {
+		b.WriteString(article.Title)
+	}
+	return b.String()
+}
+
+func (l *Law) citeBluebook(article *Article) string {
+	title := capitalize(text.KanaToRomaji(l.LawTitleKana))
+	if title == "" {
+		title = l.LawTitle
+	}
+	parts := []string{title}
+	if l.TitleEn != "" {
+		parts[0] += " [" + l.TitleEn + "]"
+	}
+	if lawType, ok := lawTypesEn[l.LawType]; ok && l.Num != "" {
+		number := lawType + " No. " + l.Num
+		if !l.PromulgationDate.IsZero() {
+			number += " of " + strconv.Itoa(l.PromulgationDate.Year())
+		}
+		parts = append(parts, number)
+	}
+	if article != nil {
+		parts = append(parts, "art. "+strings.ReplaceAll(article.Num, "_", "-"))
+	}
+	return strings.Join(parts, ", ") + " (Japan)."
+}
+
+func (l *Law) citeBibTeX(article *Article) string {
+	m := l.Metadata()
+	key := m.LawID
+	if key == "" {
+		key = anchorPart(m.Identifier)
+	}
+	title := l.LawTitle
+	if article != nil {
+		key += ":art" + article.Num
+		title += " " + article.Title
+	}
+
+	fields := [][2]string{{"title", title}}
+	if l.LawNum != "" {
+		fields = append(fields, [2]string{"note", l.LawNum})
+	}
+	if m.PromulgationDate != nil {
+		fields = append(fields,
+			[2]string{"year", strconv.Itoa(m.PromulgationDate.Year())},
+			[2]string{"month", strconv.Itoa(int(m.PromulgationDate.Month()))},
+		)
+	}
+	fields = append(fields, [2]string{"publisher", m.Publisher})
+	if m.Source != "" {
+		fields = append(fields, [2]string{"howpublished", "e-Gov法令検索"}, [2]string{"url", m.Source})
+	}
+	fields = append(fields, [2]string{"language", "japanese"})
+
+	var b strings.Builder
+	b.WriteString("@misc{" + key + ",\n")
+	for _, field := range fields {
+		value := field[1]
+		// URLs are read verbatim by the url package.
+		if field[0] != "url" {
+			value = bibTeXEscape(value)
+		}
+		fmt.Fprintf(&b, "  %s = {%s},\n", field[0], value)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// bibTeXEscape escapes the characters that BibTeX treats specially in a
+// field value.
+func bibTeXEscape(s string) string {
+	return strings.NewReplacer(`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "%", `\%`, "&", `\&`, "#", `\#`, "_", `\_`, "$", `\$`).Replace(s)
+}
+
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,147 @@
+package lawdata
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// DefaultBaseURL is the e-Gov Law API v2 endpoint.
+const DefaultBaseURL = "https://laws.e-gov.go.jp/api/2"
+
+// ErrNotFound is returned when the upstream API has no law for the ID.
+var ErrNotFound = errors.New("law not found")
+
+// ErrTimeout is returned when the upstream API does not answer in time.
+var ErrTimeout = errors.New("e-Gov request timed out")
+
+// Client fetches law bodies from the e-Gov law_data endpoint, which the
+// jplaw client does not cover.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{
+		BaseURL:    DefaultBaseURL,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// lawDataResponse is the subset of the law_data response used here.
+type lawDataResponse struct {
+	LawFullText       json.RawMessage `json:"law_full_text"`
+	AttachedFilesInfo *struct {
+		AttachedFiles []Attachment `json:"attached_files"`
+	} `json:"attached_files_info"`
+	LawInfo *struct {
+		LawID string `json:"law_id"`
+	} `json:"law_info"`
+	RevisionInfo *struct {
+		LawRevisionID string `json:"law_revision_id"`
+		Category      string `json:"category"`
+	} `json:"revision_info"`
+}
+
+// LawData is a law body together with its attachment list.
+type LawData struct {
+	XML         []byte
+	Attachments []Attachment
+	// LawID, RevisionID, and Category describe the returned revision.
+	LawID      string
+	RevisionID string
+	Category   string
+}
+
+// FetchXML returns the law XML for a law ID, law number, or revision ID.
+func (c *Client) FetchXML(ctx context.Context, id string) ([]byte, error) {
+	data, err := c.FetchLawData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return data.XML, nil
+}
+
+// FetchLawData returns the law XML and the attachments it references.
+func (c *Client) FetchLawData(ctx context.Context, id string) (*LawData, error) {
+	endpoint := fmt.Sprintf("%s/law_data/%s?law_full_text_format=xml", c.BaseURL, url.PathEscape(id))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if os.IsTimeout(err) {
+		return nil, ErrTimeout
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch law data: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("law data request failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	var data lawDataResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode law data: %v", err)
+	}
+
+	xmlContent, err := extractXMLContent(data.LawFullText)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LawData{XML: xmlContent}
+	if data.AttachedFilesInfo != nil {
+		result.Attachments = data.AttachedFilesInfo.AttachedFiles
+	}
+	if data.LawInfo != nil {
+		result.LawID = data.LawInfo.LawID
+	}
+	if data.RevisionInfo != nil {
+		result.RevisionID = data.RevisionInfo.LawRevisionID
+		result.Category = data.RevisionInfo.Category
+	}
+	return result, nil
+}
+
+// extractXMLContent returns the law XML of a law_full_text payload, which
+// is base64 XML or, from endpoints that only serve the JSON law format, an
+// element tree. The TmpRootTag wrapper the API adds around the Law element
+// is removed.
+func extractXMLContent(raw json.RawMessage) ([]byte, error) {
+	if trimmed := bytes.TrimSpace(raw); bytes.HasPrefix(trimmed, []byte("{")) {
+		return LawJSONToXML(trimmed)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return nil, errors.New("invalid XML format: law_full_text is neither base64 XML nor a JSON law")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XML format: %v", err)
+	}
+
+	decoded = bytes.ReplaceAll(decoded, []byte("<TmpRootTag>"), nil)
+	decoded = bytes.ReplaceAll(decoded, []byte("</TmpRootTag>"), nil)
+	return bytes.TrimSpace(decoded), nil
+}
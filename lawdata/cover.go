@@ -0,0 +1,190 @@
+package lawdata
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+)
+
+// MaxLogoSize is the largest logo image a cover accepts, in bytes.
+const MaxLogoSize = 1 << 20
+
+// Layout of the cover image, in SVG user units.
+const (
+	coverTitleLine  = 11 // characters
+	coverTitleLines = 6
+	coverTitleSize  = 44
+	coverTitleTop   = 200
+	coverLineHeight = 58
+)
+
+// Cover draws a cover page: the title, the law number, and the year of
+// promulgation in the Japanese era and the Western calendar, as an SVG
+// image that reading systems also show in their libraries.
+type Cover struct {
+	// Logo is a PNG or JPEG image, such as the logo of a tenant, drawn
+	// below the text; nil draws none.
+	Logo []byte
+}
+
+// coverPage is the data of the cover templates.
+type coverPage struct {
+	Title string
+	Lines []coverLine
+	Logo  *coverLogo
+}
+
+// coverLine is a line of text of the cover image.
+type coverLine struct {
+	Text string
+	Y    int
+	Size int
+}
+
+// coverLogo is the logo image of a cover, stored next to it.
+type coverLogo struct {
+	Href      string
+	MediaType string
+	data      []byte
+}
+
+const coverSVGTemplate = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" width="600" height="800" viewBox="0 0 600 800">
+<rect width="600" height="800" fill="#fbfaf6"/>
+<rect x="30" y="30" width="540" height="740" fill="none" stroke="#24344d" stroke-width="4"/>
+<g fill="#1a1a1a" font-family="serif" text-anchor="middle">
+{{range .Lines}}<text x="300" y="{{.Y}}" font-size="{{.Size}}">{{.Text}}</text>
+{{end}}</g>
+{{with .Logo}}<image x="180" y="620" width="240" height="120" preserveAspectRatio="xMidYMid meet" xlink:href="{{.Href}}"/>
+{{end}}</svg>
+`
+
+const coverXHTMLTemplate = `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="ja" xml:lang="ja">
+<head>
+<meta charset="utf-8"/>
+<title>{{.Title}}</title>
+<style>html, body { height: 100%; margin: 0; padding: 0; } img { display: block; height: 100%; margin: 0 auto; }</style>
+</head>
+<body epub:type="cover">
+<img src="cover.svg" alt="{{.Title}}"/>
+</body>
+</html>
+`
+
+// ValidateLogo checks that data is a PNG or JPEG image a cover can show,
+// and returns its media type.
+func ValidateLogo(data []byte) (string, error) {
+	if len(data) > MaxLogoSize {
+		return "", fmt.Errorf("logo must be at most %d bytes, got %d", MaxLogoSize, len(data))
+	}
+	mediaType := http.DetectContentType(data)
+	if mediaType != "image/png" && mediaType != "image/jpeg" {
+		return "", fmt.Errorf("logo must be a PNG or JPEG image, got %s", mediaType)
+	}
+	if _, _, err := image.DecodeConfig(bytes.NewReader(data)); err != nil {
+		return "", fmt.Errorf("failed to read logo: %v", err)
+	}
+	return mediaType, nil
+}
+
+// newCoverPage lays out the cover of a book titled title; lawNum and year
+// are left out when empty. It returns nil without a cover.
+func newCoverPage(cover *Cover, title, lawNum, year string) (*coverPage, error) {
+	if cover == nil {
+		return nil, nil
+	}
+	page := &coverPage{Title: title}
+	y := coverTitleTop
+	for _, line := range wrapTitle(title) {
+		page.Lines = append(page.Lines, coverLine{Text: line, Y: y, Size: coverTitleSize})
+		y += coverLineHeight
+	}
+	y += 20
+	for _, text := range []string{lawNum, year} {
+		if text != "" {
+			page.Lines = append(page.Lines, coverLine{Text: text, Y: y, Size: 22})
+			y += 40
+		}
+	}
+	if cover.Logo != nil {
+		mediaType, err := ValidateLogo(cover.Logo)
+		if err != nil {
+			return nil, err
+		}
+		href := "logo.png"
+		if mediaType == "image/jpeg" {
+			href = "logo.jpg"
+		}
+		page.Logo = &coverLogo{Href: href, MediaType: mediaType, data: cover.Logo}
+	}
+	return page, nil
+}
+
+// lawCoverPage lays out the cover of a law.
+func lawCoverPage(cover *Cover, law *Law) (*coverPage, error) {
+	return newCoverPage(cover, law.LawTitle, law.LawNum, promulgationYear(law))
+}
+
+// promulgationYear writes the year a law was promulgated, such as
+// 昭和25年（1950年）公布, or returns "" when it is unknown.
+func promulgationYear(law *Law) string {
+	era := eraName(law.Era)
+	year, err := strconv.Atoi(law.Year)
+	switch {
+	case era != "" && err == nil && !law.PromulgationDate.IsZero():
+		return fmt.Sprintf("%s%s年（%d年）公布", era, eraYear(year), law.PromulgationDate.Year())
+	case era != "" && err == nil:
+		return fmt.Sprintf("%s%s年公布", era, eraYear(year))
+	case !law.PromulgationDate.IsZero():
+		return fmt.Sprintf("%d年公布", law.PromulgationDate.Year())
+	default:
+		return ""
+	}
+}
+
+// eraYear writes the year of an era, 元 for the first.
+func eraYear(year int) string {
+	if year == 1 {
+		return "元"
+	}
+	return strconv.Itoa(year)
+}
+
+// wrapTitle breaks a title into the lines of the cover, shortening it when
+// it does not fit.
+func wrapTitle(title string) []string {
+	runes := []rune(title)
+	var lines []string
+	for len(runes) > 0 {
+		if len(lines) == coverTitleLines-1 && len(runes) > coverTitleLine {
+			lines = append(lines, string(runes[:coverTitleLine-1])+"…")
+			break
+		}
+		n := min(len(runes), coverTitleLine)
+		lines = append(lines, string(runes[:n]))
+		runes = runes[n:]
+	}
+	return lines
+}
+
+// files renders the cover image, its page, and the logo.
+func (p *coverPage) files(tmpl *template.Template) ([]bookFile, error) {
+	svg, err := renderBookFile(tmpl, "OEBPS/cover.svg", "coverSVG", p)
+	if err != nil {
+		return nil, err
+	}
+	xhtml, err := renderBookFile(tmpl, "OEBPS/cover.xhtml", "coverXHTML", p)
+	if err != nil {
+		return nil, err
+	}
+	files := []bookFile{{"OEBPS/cover.svg", svg}, {"OEBPS/cover.xhtml", xhtml}}
+	if p.Logo != nil {
+		files = append(files, bookFile{"OEBPS/" + p.Logo.Href, p.Logo.data})
+	}
+	return files, nil
+}
@@ -0,0 +1,219 @@
+package lawdata
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Change is how an article or paragraph differs between two revisions.
+type Change string
+
+const (
+	ChangeAdded    Change = "ADDED"
+	ChangeRemoved  Change = "REMOVED"
+	ChangeModified Change = "MODIFIED"
+)
+
+// ArticleDiff is an article that was added, removed, or modified.
+// Provision is empty for the main provision, or the label and amending law
+// number of a supplementary provision. Provisions without articles are
+// compared as a single article with an empty Num.
+type ArticleDiff struct {
+	Provision     string
+	Num           string
+	Title         string
+	Change        Change
+	CaptionBefore string
+	CaptionAfter  string
+	Paragraphs    []ParagraphDiff
+}
+
+// ParagraphDiff is a paragraph that was added, removed, or modified. Before
+// and After hold the paragraph text followed by its items, one per line.
+type ParagraphDiff struct {
+	Num    string
+	Change Change
+	Before string
+	After  string
+}
+
+// diffArticle is an article with the key identifying it across revisions.
+type diffArticle struct {
+	key       string
+	provision string
+	article   Article
+}
+
+// Compare returns the articles that differ between two revisions of a law,
+// in the order of the newer revision with removed articles after their
+// predecessors. Articles are matched by provision and article number, and
+// paragraphs by paragraph number.
+func Compare(from, to *Law) []ArticleDiff {
+	before := from.diffArticles()
+	after := to.diffArticles()
+
+	beforeIndex := make(map[string]int, len(before))
+	for i, a := range before {
+		beforeIndex[a.key] = i
+	}
+	afterKeys := make(map[string]bool, len(after))
+	for _, a := range after {
+		afterKeys[a.key] = true
+	}
+
+	var diffs []ArticleDiff
+	next := 0
+	flushRemoved := func(until int) {
+		for ; next < until; next++ {
+			if a := before[next]; !afterKeys[a.key] {
+				diffs = append(diffs, articleDiff(a, ChangeRemoved, &a.article, nil))
+			}
+		}
+	}
+	for _, a := range after {
+		i, ok := beforeIndex[a.key]
+		if !ok {
+			diffs = append(diffs, articleDiff(a, ChangeAdded, nil, &a.article))
+			continue
+		}
+		flushRemoved(i)
+		if next <= i {
+			next = i + 1
+		}
+		if diff := articleDiff(a, ChangeModified, &before[i].article, &a.article); diff.CaptionBefore != diff.CaptionAfter || len(diff.Paragraphs) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	flushRemoved(len(before))
+	return diffs
+}
+
+func articleDiff(a diffArticle, change Change, before, after *Article) ArticleDiff {
+	diff := ArticleDiff{
+		Provision: a.provision,
+		Num:       a.article.Num,
+		Title:     a.article.Title,
+		Change:    change,
+	}
+	var beforeParagraphs, afterParagraphs []Paragraph
+	if before != nil {
+		diff.CaptionBefore = before.Caption
+		beforeParagraphs = before.Paragraphs
+	}
+	if after != nil {
+		diff.CaptionAfter = after.Caption
+		afterParagraphs = after.Paragraphs
+	}
+	if change == ChangeModified && diff.CaptionBefore == diff.CaptionAfter {
+		diff.CaptionBefore, diff.CaptionAfter = "", ""
+	}
+	diff.Paragraphs = compareParagraphs(beforeParagraphs, afterParagraphs)
+	return diff
+}
+
+func compareParagraphs(before, after []Paragraph) []ParagraphDiff {
+	num := func(i int, p Paragraph) string {
+		if p.Num != "" {
+			return p.Num
+		}
+		return strconv.Itoa(i + 1)
+	}
+	beforeText := make(map[string]string, len(before))
+	for i, p := range before {
+		beforeText[num(i, p)] = paragraphText(p)
+	}
+
+	var diffs []ParagraphDiff
+	seen := make(map[string]bool, len(after))
+	for i, p := range after {
+		n := num(i, p)
+		seen[n] = true
+		text := paragraphText(p)
+		old, ok := beforeText[n]
+		switch {
+		case !ok:
+			diffs = append(diffs, ParagraphDiff{Num: n, Change: ChangeAdded, After: text})
+		case old != text:
+			diffs = append(diffs, ParagraphDiff{Num: n, Change: ChangeModified, Before: old, After: text})
+		}
+	}
+	for i, p := range before {
+		if n := num(i, p); !seen[n] {
+			diffs = append(diffs, ParagraphDiff{Num: n, Change: ChangeRemoved, Before: beforeText[n]})
+		}
+	}
+	return diffs
+}
+
+// paragraphText returns the paragraph text and its items, one per line,
+// each item prefixed with its title.
+func paragraphText(p Paragraph) string {
+	lines := []string{p.Text()}
+	var addItems func(items []Item)
+	addItems = func(items []Item) {
+		for _, item := range items {
+			lines = append(lines, item.Title+"　"+item.Text())
+			addItems(item.Subitems)
+		}
+	}
+	addItems(p.Items)
+	return strings.Join(lines, "\n")
+}
+
+// diffArticles lists the articles of every provision in document order.
+func (l *Law) diffArticles() []diffArticle {
+	var articles []diffArticle
+	add := func(key, provision string, p Provision) {
+		for _, article := range p.allArticles() {
+			articles = append(articles, diffArticle{key: key + ":" + article.Num, provision: provision, article: article})
+		}
+		if len(p.Paragraphs) > 0 {
+			title := provision
+			if title == "" {
+				title = "本則"
+			}
+			articles = append(articles, diffArticle{key: key + ":", provision: provision, article: Article{Title: title, Paragraphs: p.Paragraphs}})
+		}
+	}
+
+	if l.MainProvision != nil {
+		add("main", "", *l.MainProvision)
+	}
+	for _, suppl := range l.SupplProvisions {
+		add(supplKey(suppl), suppl.heading(), suppl)
+	}
+	return articles
+}
+
+// allArticles lists the articles of a provision in document order.
+func (p Provision) allArticles() []Article {
+	var articles []Article
+	var addDivisions func(divisions []Division)
+	addDivisions = func(divisions []Division) {
+		for _, division := range divisions {
+			addDivisions(division.Divisions)
+			articles = append(articles, division.Articles...)
+		}
+	}
+	addDivisions(p.Divisions)
+	return append(articles, p.Articles...)
+}
+
+// supplKey identifies a supplementary provision across revisions by the
+// law that added it.
+func supplKey(p Provision) string {
+	return "suppl:" + p.AmendLawNum
+}
+
+// heading returns the heading of a supplementary provision: its label, 附則
+// by default, followed by the amending law number.
+func (p Provision) heading() string {
+	heading := p.Label
+	if heading == "" {
+		heading = "附則"
+	}
+	if p.AmendLawNum != "" {
+		heading += "（" + p.AmendLawNum + "）"
+	}
+	return heading
+}
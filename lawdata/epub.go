@@ -0,0 +1,372 @@
+package lawdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/fonts"
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// xmlDeclaration is written outside the templates because html/template
+// escapes "<?".
+const xmlDeclaration = `<?xml version="1.0" encoding="utf-8"?>
+`
+
+const xhtmlTemplate = `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="ja" xml:lang="ja">
+<head>
+<meta charset="utf-8"/>
+<title>{{.LawTitle}}</title>
+{{with stylesheet}}<style>{{.}}</style>
+{{end}}{{if .Baseline}}<style>ins { text-decoration: underline; } del { text-decoration: line-through; }</style>
+{{end}}</head>
+<body>
+<header>
+<h1>{{text .LawTitle}}</h1>
+{{with .TitleEn}}<p class="title-en" lang="en">{{.}}</p>
+{{end}}<p class="law-num">{{.LawNum}}</p>
+{{with .Baseline}}<p class="baseline">{{.}}からの改正箇所（追加は下線、削除は取り消し線）</p>
+{{end}}</header>
+{{with .MainProvision}}<main{{if accessible}} id="main" epub:type="bodymatter"{{end}}>{{template "provision" .}}</main>{{end}}
+{{range .SupplProvisions}}<section class="suppl-provision"{{with .Anchor}} id="{{.}}"{{end}}{{if accessible}} epub:type="appendix" role="doc-appendix"{{end}}>
+<h2>{{if .Label}}{{.Label}}{{else}}附則{{end}}{{with .AmendLawNum}}（{{.}}）{{end}}</h2>
+{{template "provision" .}}
+</section>
+{{end}}
+</body>
+</html>
+`
+
+const navTemplate = `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="ja" xml:lang="ja">
+<head>
+<meta charset="utf-8"/>
+<title>{{.LawTitle}}</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc"{{if accessible}} role="doc-toc"{{end}}>
+<h1>目次</h1>
+<ol>
+<li><a href="law.xhtml">{{.LawTitle}}</a>{{with toc}}
+{{template "tocEntries" (navEntries "law.xhtml" .)}}{{end}}</li>
+</ol>
+</nav>
+{{if accessible}}<nav epub:type="landmarks" id="landmarks" hidden="hidden">
+<h1>ランドマーク</h1>
+<ol>
+<li><a epub:type="toc" href="nav.xhtml#toc">目次</a></li>
+{{if cover}}<li><a epub:type="cover" href="cover.xhtml">表紙</a></li>
+{{end}}{{if .MainProvision}}<li><a epub:type="bodymatter" href="law.xhtml#main">{{.LawTitle}}</a></li>
+{{end}}
+</ol>
+</nav>
+{{end}}
+</body>
+</html>
+`
+
+const tocEntriesTemplate = `<ol>
+{{range .}}<li><a href="{{.Href}}">{{.Label}}</a>{{with .Children}}
+{{template "tocEntries" .}}{{end}}</li>
+{{end}}</ol>
+`
+
+const opfTemplate = `<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="ja">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+{{with .Metadata}}<dc:identifier id="book-id">{{$.ID}}</dc:identifier>
+<dc:identifier id="revision-id">{{.Identifier}}</dc:identifier>
+<dc:title id="title">{{.Title}}</dc:title>
+{{with .TitleKana}}<meta refines="#title" property="file-as">{{.}}</meta>
+{{end}}{{with .TitleEn}}<dc:title xml:lang="en">{{.}}</dc:title>
+{{end}}<dc:language>{{.Language}}</dc:language>
+<dc:publisher>{{.Publisher}}</dc:publisher>
+{{with .PromulgationDate}}<dc:date>{{.Format "2006-01-02"}}</dc:date>
+{{end}}{{range .Subjects}}<dc:subject>{{.}}</dc:subject>
+{{end}}<dc:description>{{.LawNum}}{{with .PromulgationDate}}（{{eraDate .}}公布）{{end}}</dc:description>
+<meta property="dcterms:bibliographicCitation">{{.LawNum}}</meta>
+{{with .Source}}<dc:source>{{.}}</dc:source>
+{{end}}<dc:rights>{{.Rights}}</dc:rights>
+{{end}}<meta property="dcterms:modified">{{.Modified}}</meta>
+{{if .Cover}}<meta name="cover" content="cover-image"/>
+{{end}}{{if accessible}}<meta property="schema:accessMode">textual</meta>
+<meta property="schema:accessModeSufficient">textual</meta>
+<meta property="schema:accessibilityFeature">structuralNavigation</meta>
+<meta property="schema:accessibilityFeature">tableOfContents</meta>
+<meta property="schema:accessibilityFeature">readingOrder</meta>
+{{if hasRuby}}<meta property="schema:accessibilityFeature">rubyAnnotations</meta>
+{{end}}<meta property="schema:accessibilityHazard">none</meta>
+<meta property="schema:accessibilitySummary">Semantic markup identifies the parts, chapters, articles, and supplementary provisions of the law, and the table of contents links to each of them in reading order.</meta>
+{{end}}</metadata>
+<manifest>
+<item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+{{with .Cover}}<item id="cover-image" href="cover.svg" media-type="image/svg+xml" properties="cover-image"/>
+<item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+{{with .Logo}}<item id="logo" href="{{.Href}}" media-type="{{.MediaType}}"/>
+{{end}}{{end}}{{with .Font}}<item id="font" href="{{fontHref .}}" media-type="{{.MediaType}}"/>
+{{end}}{{range .Documents}}<item id="{{.ID}}" href="{{.Href}}" media-type="application/xhtml+xml"/>
+{{end}}</manifest>
+<spine{{if vertical}} page-progression-direction="rtl"{{end}}>
+{{if .Cover}}<itemref idref="cover"/>
+{{end}}{{range .Documents}}<itemref idref="{{.ID}}"/>
+{{end}}</spine>
+</package>
+`
+
+const bundleNavTemplate = `<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="ja" xml:lang="ja">
+<head>
+<meta charset="utf-8"/>
+<title>{{.Title}}</title>
+</head>
+<body>
+<nav epub:type="toc" id="toc"{{if accessible}} role="doc-toc"{{end}}>
+<h1>目次</h1>
+<ol>
+{{range .Documents}}<li><a href="{{.Href}}">{{.Title}}</a>{{with .Contents}}
+{{template "tocEntries" .}}{{end}}</li>
+{{end}}</ol>
+</nav>
+{{if accessible}}<nav epub:type="landmarks" id="landmarks" hidden="hidden">
+<h1>ランドマーク</h1>
+<ol>
+<li><a epub:type="toc" href="nav.xhtml#toc">目次</a></li>
+{{if cover}}<li><a epub:type="cover" href="cover.xhtml">表紙</a></li>
+{{end}}{{with .Bodymatter}}<li><a epub:type="bodymatter" href="{{.Href}}#main">{{.Title}}</a></li>
+{{end}}
+</ol>
+</nav>
+{{end}}
+</body>
+</html>
+`
+
+const containerXML = `<?xml version="1.0" encoding="utf-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+// Options selects optional features of an EPUB book.
+type Options struct {
+	// Ruby adds ruby readings to the text when non-nil.
+	Ruby Annotator
+	// Accessible adds epub:type and ARIA role markup, a table of contents
+	// of every division and article, landmarks, and accessibility metadata
+	// for screen readers and text-to-speech.
+	Accessible bool
+	// Vertical typesets the text in vertical lines read right to left, as
+	// in Japanese print.
+	Vertical bool
+	// FontFamily is "serif" (mincho) or "sans-serif" (gothic); empty
+	// leaves the typeface to the reading system.
+	FontFamily string
+	// FontSize scales the text in percent, such as 150 for large print;
+	// zero keeps the reading system's size.
+	FontSize int
+	// LineHeight spaces the lines in percent of the font size, such as 180;
+	// zero keeps the reading system's spacing.
+	LineHeight int
+	// LetterSpacing adds space between characters in percent of the font
+	// size.
+	LetterSpacing int
+	// HighContrast sets black text on white and underlines links.
+	HighContrast bool
+	// OmitSupplProvisions leaves out the supplementary provisions.
+	OmitSupplProvisions bool
+	// Cover adds a cover page when non-nil.
+	Cover *Cover
+	// Font is embedded and used for the text when non-nil, before
+	// FontFamily.
+	Font *fonts.Font
+}
+
+// bookDocument is a content document of an EPUB book.
+type bookDocument struct {
+	ID    string
+	Href  string
+	Title string
+	// Contents lists the parts of the document for the table of contents
+	// of a bundle.
+	Contents []navEntry
+}
+
+// navEntry is a table of contents entry linking to a part of a content
+// document.
+type navEntry struct {
+	Href     string
+	Label    string
+	Children []navEntry
+}
+
+// navEntries links entries to their anchors in the document href.
+func navEntries(href string, entries []ContentsEntry) []navEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	nav := make([]navEntry, len(entries))
+	for i, entry := range entries {
+		nav[i] = navEntry{Href: href + "#" + entry.Anchor, Label: entry.Label, Children: navEntries(href, entry.Children)}
+	}
+	return nav
+}
+
+// opfData is the data of the package document template.
+type opfData struct {
+	ID        string
+	Metadata  Metadata
+	Modified  string
+	Documents []bookDocument
+	// Cover is nil for a book without a cover.
+	Cover *coverPage
+	// Font is nil for a book without an embedded font.
+	Font *fonts.Font
+}
+
+// bookFile is a rendered file of an EPUB book.
+type bookFile struct {
+	name string
+	data []byte
+}
+
+// WriteEPUB writes the law as a single-document EPUB 3 book with links for
+// cross-references. The id becomes the book's unique identifier.
+func WriteEPUB(w io.Writer, law *Law, id string, opts Options) error {
+	if opts.OmitSupplProvisions {
+		law = law.withoutSupplProvisions()
+	}
+	tmpl, err := bookTemplate(law, opts, lawLinks(law.articleIDs()))
+	if err != nil {
+		return err
+	}
+	cover, err := lawCoverPage(opts.Cover, law)
+	if err != nil {
+		return err
+	}
+
+	opf := opfData{id, law.Metadata(), modified(), []bookDocument{{ID: "law", Href: "law.xhtml"}}, cover, opts.Font}
+
+	files := []struct {
+		name     string
+		template string
+		data     interface{}
+	}{
+		{"OEBPS/content.opf", "opf", opf},
+		{"OEBPS/nav.xhtml", "nav", law},
+		{"OEBPS/law.xhtml", "xhtml", law},
+	}
+	rendered := make([]bookFile, 0, len(files))
+	for _, file := range files {
+		data, err := renderBookFile(tmpl, file.name, file.template, file.data)
+		if err != nil {
+			return err
+		}
+		rendered = append(rendered, bookFile{file.name, data})
+	}
+	if cover != nil {
+		files, err := cover.files(tmpl)
+		if err != nil {
+			return err
+		}
+		rendered = append(rendered, files...)
+	}
+	rendered = append(rendered, fontFile(opts)...)
+	return writeBook(w, rendered)
+}
+
+// withoutSupplProvisions returns a copy of the law without its
+// supplementary provisions.
+func (l *Law) withoutSupplProvisions() *Law {
+	omitted := *l
+	omitted.SupplProvisions = nil
+	return &omitted
+}
+
+// bookTemplate parses the templates of an EPUB book with the functions
+// rendering law, whose cross-references link to the targets links returns.
+func bookTemplate(law *Law, opts Options, links func(lawref.Reference) string) (*template.Template, error) {
+	funcs, err := textFuncs(law, opts.Ruby, links)
+	if err != nil {
+		return nil, err
+	}
+	accessibilityFuncs(funcs, law, opts.Accessible)
+	redlineFuncs(funcs)
+	if err := layoutFuncs(funcs, opts); err != nil {
+		return nil, err
+	}
+	funcs["hasRuby"] = func() bool { return opts.Ruby != nil }
+	funcs["cover"] = func() bool { return opts.Cover != nil }
+	funcs["eraDate"] = func(t *time.Time) string { return jpdate.FormatEra(*t) }
+	funcs["navEntries"] = navEntries
+	funcs["fontHref"] = fontHref
+	tmpl, err := template.New("law").Funcs(funcs).Parse(htmlTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML template: %v", err)
+	}
+	for name, text := range map[string]string{"xhtml": xhtmlTemplate, "nav": navTemplate, "bundleNav": bundleNavTemplate, "tocEntries": tocEntriesTemplate, "opf": opfTemplate, "coverSVG": coverSVGTemplate, "coverXHTML": coverXHTMLTemplate} {
+		if _, err := tmpl.New(name).Parse(text); err != nil {
+			return nil, fmt.Errorf("failed to parse %s template: %v", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// renderBookFile renders the file name of a book with the template
+// templateName.
+func renderBookFile(tmpl *template.Template, name, templateName string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xmlDeclaration)
+	if err := tmpl.ExecuteTemplate(&buf, templateName, data); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %v", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// modified is the dcterms:modified time of a book written now.
+func modified() string {
+	return time.Now().UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// writeBook writes an EPUB archive of the rendered files.
+func writeBook(w io.Writer, files []bookFile) error {
+	zw := zip.NewWriter(w)
+
+	// The mimetype entry must come first and be stored uncompressed.
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return fmt.Errorf("failed to write EPUB: %v", err)
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return fmt.Errorf("failed to write EPUB: %v", err)
+	}
+
+	container, err := zw.Create("META-INF/container.xml")
+	if err != nil {
+		return fmt.Errorf("failed to write EPUB: %v", err)
+	}
+	if _, err := io.WriteString(container, containerXML); err != nil {
+		return fmt.Errorf("failed to write EPUB: %v", err)
+	}
+
+	for _, file := range files {
+		entry, err := zw.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("failed to write EPUB: %v", err)
+		}
+		if _, err := entry.Write(file.data); err != nil {
+			return fmt.Errorf("failed to write EPUB: %v", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write EPUB: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package lawdata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+const epubMediaType = "application/epub+zip"
+
+// EPUBError lists the structural problems CheckEPUB found in an EPUB.
+type EPUBError struct {
+	Problems []string
+}
+
+func (e *EPUBError) Error() string {
+	return "invalid EPUB: " + strings.Join(e.Problems, "; ")
+}
+
+// EPUBSummary describes an EPUB that passed CheckEPUB.
+type EPUBSummary struct {
+	Version string
+	// Title is the first dc:title.
+	Title         string
+	ManifestItems int
+	SpineItems    int
+}
+
+// ocfContainer is META-INF/container.xml.
+type ocfContainer struct {
+	Rootfiles []struct {
+		FullPath  string `xml:"full-path,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// packageDocument is the parts of the OPF package document that are
+// checked.
+type packageDocument struct {
+	Version  string `xml:"version,attr"`
+	Metadata struct {
+		Identifiers []string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+		Titles      []string `xml:"http://purl.org/dc/elements/1.1/ title"`
+		Languages   []string `xml:"http://purl.org/dc/elements/1.1/ language"`
+	} `xml:"metadata"`
+	Manifest []struct {
+		ID         string `xml:"id,attr"`
+		Href       string `xml:"href,attr"`
+		MediaType  string `xml:"media-type,attr"`
+		Properties string `xml:"properties,attr"`
+	} `xml:"manifest>item"`
+	Spine struct {
+		TOC      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+// CheckEPUB checks the structure of an EPUB along the lines of epubcheck:
+// the OCF container, the package document and its required metadata, that
+// the manifest and spine are consistent with the archive, that there is a
+// navigation document, and that content documents are well-formed. Every
+// problem found is returned in an *EPUBError.
+func CheckEPUB(data []byte) (*EPUBSummary, error) {
+	summary, problems := checkEPUB(data)
+	if len(problems) > 0 {
+		return nil, &EPUBError{Problems: problems}
+	}
+	return summary, nil
+}
+
+func checkEPUB(data []byte) (*EPUBSummary, []string) {
+	fail := func(format string, args ...interface{}) []string {
+		return []string{fmt.Sprintf(format, args...)}
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fail("not a ZIP archive: %v", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	if len(archive.File) == 0 || archive.File[0].Name != "mimetype" {
+		return nil, fail("mimetype is not the first entry")
+	}
+	if archive.File[0].Method != zip.Store {
+		return nil, fail("mimetype is compressed")
+	}
+	mimetype, err := readEntry(archive.File[0])
+	if err != nil {
+		return nil, fail("%v", err)
+	}
+	if string(mimetype) != epubMediaType {
+		return nil, fail("mimetype is %q", mimetype)
+	}
+
+	var c ocfContainer
+	if err := readXML(files, "META-INF/container.xml", &c); err != nil {
+		return nil, fail("%v", err)
+	}
+	if len(c.Rootfiles) == 0 || c.Rootfiles[0].FullPath == "" {
+		return nil, fail("META-INF/container.xml has no rootfile")
+	}
+	opfPath := c.Rootfiles[0].FullPath
+	var opf packageDocument
+	if err := readXML(files, opfPath, &opf); err != nil {
+		return nil, fail("%v", err)
+	}
+
+	var problems []string
+	if len(opf.Metadata.Identifiers) == 0 {
+		problems = append(problems, opfPath+": no dc:identifier")
+	}
+	if len(opf.Metadata.Titles) == 0 {
+		problems = append(problems, opfPath+": no dc:title")
+	}
+	if len(opf.Metadata.Languages) == 0 {
+		problems = append(problems, opfPath+": no dc:language")
+	}
+
+	dir := path.Dir(opfPath)
+	ids := make(map[string]string, len(opf.Manifest))
+	hasNav := false
+	for _, item := range opf.Manifest {
+		if _, ok := ids[item.ID]; ok {
+			problems = append(problems, fmt.Sprintf("%s: duplicate manifest ID %q", opfPath, item.ID))
+		}
+		ids[item.ID] = item.MediaType
+		name := path.Join(dir, item.Href)
+		f, ok := files[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("manifest item %s is missing", name))
+			continue
+		}
+		if strings.Contains(" "+item.Properties+" ", " nav ") {
+			hasNav = true
+		}
+		if item.MediaType == "application/xhtml+xml" {
+			if err := checkWellFormed(f); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	if !hasNav && (opf.Spine.TOC == "" || ids[opf.Spine.TOC] != "application/x-dtbncx+xml") {
+		problems = append(problems, opfPath+": no navigation document or NCX")
+	}
+	if len(opf.Spine.Itemrefs) == 0 {
+		problems = append(problems, opfPath+": empty spine")
+	}
+	for _, itemref := range opf.Spine.Itemrefs {
+		if _, ok := ids[itemref.IDRef]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: spine item %q is not in the manifest", opfPath, itemref.IDRef))
+		}
+	}
+
+	return &EPUBSummary{
+		Version:       opf.Version,
+		Title:         firstOf(opf.Metadata.Titles),
+		ManifestItems: len(opf.Manifest),
+		SpineItems:    len(opf.Spine.Itemrefs),
+	}, problems
+}
+
+func firstOf(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[0])
+}
+
+func readEntry(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", f.Name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", f.Name, err)
+	}
+	return data, nil
+}
+
+func readXML(files map[string]*zip.File, name string, v interface{}) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("%s is missing", name)
+	}
+	data, err := readEntry(f)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", name, err)
+	}
+	return nil
+}
+
+// checkWellFormed reads a content document through to the end.
+func checkWellFormed(f *zip.File) error {
+	data, err := readEntry(f)
+	if err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	// XHTML named entities are not defined without the DTD.
+	decoder.Entity = xml.HTMLEntity
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s is not well-formed: %v", f.Name, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package lawdata
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.LawTitle}}</title>
+{{if .Baseline}}<style>ins { text-decoration: underline; } del { text-decoration: line-through; }</style>
+{{end}}</head>
+<body>
+<header>
+<h1>{{text .LawTitle}}</h1>
+{{with .TitleEn}}<p class="title-en" lang="en">{{.}}</p>
+{{end}}<p class="law-num">{{.LawNum}}</p>
+{{with .Baseline}}<p class="baseline">{{.}}からの改正箇所（追加は下線、削除は取り消し線）</p>
+{{end}}</header>
+{{with .MainProvision}}<main>{{template "provision" .}}</main>{{end}}
+{{range .SupplProvisions}}<section class="suppl-provision"{{with .Anchor}} id="{{.}}"{{end}}>
+<h2>{{if .Label}}{{.Label}}{{else}}附則{{end}}{{with .AmendLawNum}}（{{.}}）{{end}}</h2>
+{{template "provision" .}}
+</section>
+{{end}}
+</body>
+</html>
+{{define "provision"}}{{range .Divisions}}{{template "division" .}}{{end}}{{range .Articles}}{{template "article" .}}{{end}}{{range .Paragraphs}}{{template "paragraph" .}}{{end}}{{end}}
+{{define "division"}}<section class="{{.Kind}}"{{with .Anchor}} id="{{.}}"{{end}}{{if accessible}} epub:type="{{divisionType .Kind}}"{{with divisionRole .Kind}} role="{{.}}"{{end}}{{end}}>
+{{heading .Kind .Title}}
+{{range .Divisions}}{{template "division" .}}{{end}}{{range .Articles}}{{template "article" .}}{{end}}</section>
+{{end}}
+{{define "article"}}<section class="article"{{with .Anchor}} id="{{.}}"{{end}}{{if accessible}} aria-label="{{.Title}}"{{end}}>
+{{if or .Caption .CaptionDiff}}<p class="caption">{{marked .Change .CaptionDiff .Caption}}</p>
+{{end}}{{range $i, $p := .Paragraphs}}{{if eq $i 0}}<p class="paragraph"{{with $p.Anchor}} id="{{.}}"{{end}}><strong>{{markedLabel $.Change $.Title}}</strong>　{{marked $p.Change $p.Diff $p.Text}}</p>
+{{range $p.Items}}{{template "item" .}}{{end}}{{else}}{{template "paragraph" $p}}{{end}}{{end}}</section>
+{{end}}
+{{define "paragraph"}}<p class="paragraph"{{with .Anchor}} id="{{.}}"{{end}}>{{with .NumText}}{{markedLabel $.Change .}}　{{end}}{{marked .Change .Diff .Text}}</p>
+{{range .Items}}{{template "item" .}}{{end}}{{end}}
+{{define "item"}}<div class="item"><p>{{markedLabel .Change .Title}}　{{marked .Change .Diff .Text}}</p>
+{{range .Subitems}}{{template "item" .}}{{end}}</div>
+{{end}}`
+
+// RenderHTML writes the law as a standalone HTML document with links for
+// cross-references. A non-nil annotator adds ruby readings to the text.
+func RenderHTML(w io.Writer, law *Law, annotator Annotator) error {
+	funcs, err := textFuncs(law, annotator, lawLinks(law.articleIDs()))
+	if err != nil {
+		return err
+	}
+	accessibilityFuncs(funcs, law, false)
+	redlineFuncs(funcs)
+	tmpl, err := template.New("law").Funcs(funcs).Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML template: %v", err)
+	}
+	if err := tmpl.Execute(w, law); err != nil {
+		return fmt.Errorf("failed to render HTML: %v", err)
+	}
+	return nil
+}
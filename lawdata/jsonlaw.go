@@ -0,0 +1,145 @@
+package lawdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// jsonElement is an element of the e-Gov JSON law format, in which
+// law_full_text is a tree of {"tag", "attr", "children"} objects whose
+// children are elements or strings.
+type jsonElement struct {
+	Tag      string            `json:"tag"`
+	Attr     map[string]string `json:"attr"`
+	Children []json.RawMessage `json:"children"`
+}
+
+// LawJSONToXML converts a law in the e-Gov JSON format to law XML. The
+// TmpRootTag wrapper the API adds around the Law element is removed.
+func LawJSONToXML(data []byte) ([]byte, error) {
+	var root jsonElement
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON law format: %v", err)
+	}
+	if root.Tag == "TmpRootTag" {
+		unwrapped, err := onlyChild(root)
+		if err != nil {
+			return nil, err
+		}
+		root = *unwrapped
+	}
+	if root.Tag == "" {
+		return nil, errors.New("invalid JSON law format: root element has no tag")
+	}
+
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	if err := encodeJSONElement(encoder, root, 0); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write law XML: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeLawDocument returns the law XML of an uploaded document, which is
+// law XML, a law in the e-Gov JSON format, or a law_data response carrying
+// either.
+func DecodeLawDocument(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("{")) {
+		return data, nil
+	}
+
+	var response lawDataResponse
+	if err := json.Unmarshal(trimmed, &response); err != nil {
+		return nil, fmt.Errorf("invalid JSON law format: %v", err)
+	}
+	if len(response.LawFullText) > 0 {
+		return extractXMLContent(response.LawFullText)
+	}
+	return LawJSONToXML(trimmed)
+}
+
+// onlyChild returns the single element child of a wrapper element.
+func onlyChild(wrapper jsonElement) (*jsonElement, error) {
+	var found *jsonElement
+	for _, raw := range wrapper.Children {
+		if isJSONString(raw) {
+			continue
+		}
+		var child jsonElement
+		if err := json.Unmarshal(raw, &child); err != nil {
+			return nil, fmt.Errorf("invalid JSON law format: %v", err)
+		}
+		if found != nil {
+			return nil, fmt.Errorf("invalid JSON law format: %s wraps more than one element", wrapper.Tag)
+		}
+		found = &child
+	}
+	if found == nil {
+		return nil, fmt.Errorf("invalid JSON law format: %s wraps no element", wrapper.Tag)
+	}
+	return found, nil
+}
+
+// encodeJSONElement writes an element and its descendants. Attributes are
+// written in name order, since JSON objects are unordered.
+func encodeJSONElement(encoder *xml.Encoder, element jsonElement, depth int) error {
+	// Law XML nests far less deeply; this only guards against malicious
+	// input.
+	if depth > 100 {
+		return errors.New("invalid JSON law format: elements are nested too deeply")
+	}
+	if element.Tag == "" {
+		return errors.New("invalid JSON law format: element has no tag")
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: element.Tag}}
+	names := make([]string, 0, len(element.Attr))
+	for name := range element.Attr {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: name}, Value: element.Attr[name]})
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return fmt.Errorf("invalid JSON law format: %v", err)
+	}
+
+	for _, raw := range element.Children {
+		if isJSONString(raw) {
+			var text string
+			if err := json.Unmarshal(raw, &text); err != nil {
+				return fmt.Errorf("invalid JSON law format: %v", err)
+			}
+			if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+				return fmt.Errorf("invalid JSON law format: %v", err)
+			}
+			continue
+		}
+		var child jsonElement
+		if err := json.Unmarshal(raw, &child); err != nil {
+			return fmt.Errorf("invalid JSON law format: %v", err)
+		}
+		if err := encodeJSONElement(encoder, child, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if err := encoder.EncodeToken(start.End()); err != nil {
+		return fmt.Errorf("invalid JSON law format: %v", err)
+	}
+	return nil
+}
+
+func isJSONString(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	return len(trimmed) > 0 && trimmed[0] == '"'
+}
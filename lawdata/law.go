@@ -0,0 +1,326 @@
+package lawdata
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+)
+
+// Law is the parsed structure of a law XML document.
+type Law struct {
+	RevisionID   string
+	Era          string
+	Year         string
+	Num          string
+	LawType      string
+	LawNum       string
+	LawTitle     string
+	LawTitleKana string
+	// PromulgationDate is read from the era, year, month, and day
+	// attributes, and is zero when they are incomplete.
+	PromulgationDate time.Time
+	// LawID and Category are filled in from the law_data response by
+	// ParseLawData.
+	LawID    string
+	Category string
+	// TitleEn is the English title, filled in by callers that know it.
+	TitleEn string
+	// Baseline is the revision whose differences MarkChanges recorded.
+	Baseline        string
+	MainProvision   *Provision
+	SupplProvisions []Provision
+	Attachments     []Attachment
+}
+
+// Provision is the main provision or a supplementary provision.
+type Provision struct {
+	Label       string
+	AmendLawNum string
+	Divisions   []Division
+	Articles    []Article
+	Paragraphs  []Paragraph
+	// Anchor is the element ID of a supplementary provision (see
+	// assignAnchors), and empty for the main provision.
+	Anchor string
+}
+
+// Division is a grouping above articles: Part, Chapter, Section,
+// Subsection, or Division.
+type Division struct {
+	Kind      string
+	Num       string
+	Title     string
+	Divisions []Division
+	Articles  []Article
+	// Anchor is the element ID of the division (see assignAnchors).
+	Anchor string
+}
+
+type Article struct {
+	Num        string
+	Caption    string
+	Title      string
+	Paragraphs []Paragraph
+	// Change and CaptionDiff are set by MarkChanges.
+	Change      Change
+	CaptionDiff []DiffSegment
+	// Anchor is the element ID of the article (see assignAnchors).
+	Anchor string
+}
+
+type Paragraph struct {
+	Num       string
+	NumText   string
+	Sentences []string
+	Items     []Item
+	// Change and Diff are set by MarkChanges.
+	Change Change
+	Diff   []DiffSegment
+	// Anchor is the element ID of the paragraph (see assignAnchors).
+	Anchor string
+}
+
+// Text returns the paragraph sentences joined as they appear in print.
+func (p Paragraph) Text() string {
+	return strings.Join(p.Sentences, "")
+}
+
+// Item is a numbered item (号) or any level of sub-item (イ, (1), ...).
+type Item struct {
+	Num       string
+	Title     string
+	Sentences []string
+	Subitems  []Item
+	// Change and Diff are set by MarkChanges.
+	Change Change
+	Diff   []DiffSegment
+}
+
+// Text returns the item sentences joined as they appear in print.
+func (i Item) Text() string {
+	return strings.Join(i.Sentences, "")
+}
+
+// isDivision reports whether an element name is a grouping above articles.
+func isDivision(name string) bool {
+	switch name {
+	case "Part", "Chapter", "Section", "Subsection", "Division":
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseLaw parses law XML into its article structure.
+func ParseLaw(data []byte) (*Law, error) {
+	root, err := ParseXML(data)
+	if err != nil {
+		return nil, err
+	}
+	if root.Name != "Law" {
+		return nil, errors.New("invalid XML format: root element is not Law")
+	}
+
+	body := root.Child("LawBody")
+	title := body.Child("LawTitle")
+
+	law := &Law{
+		Era:          root.Attr("Era"),
+		Year:         root.Attr("Year"),
+		Num:          root.Attr("Num"),
+		LawType:      root.Attr("LawType"),
+		LawNum:       root.Child("LawNum").Text(),
+		LawTitle:     title.Text(),
+		LawTitleKana: title.Attr("Kana"),
+	}
+	law.PromulgationDate = promulgationDate(law.Era, law.Year, root.Attr("PromulgateMonth"), root.Attr("PromulgateDay"))
+
+	if body == nil {
+		return law, nil
+	}
+	for _, child := range body.Children {
+		switch child.Name {
+		case "MainProvision":
+			provision := parseProvision(child)
+			law.MainProvision = &provision
+		case "SupplProvision":
+			provision := parseProvision(child)
+			provision.Label = child.Child("SupplProvisionLabel").Text()
+			provision.AmendLawNum = child.Attr("AmendLawNum")
+			law.SupplProvisions = append(law.SupplProvisions, provision)
+		}
+	}
+	law.assignAnchors()
+
+	return law, nil
+}
+
+// ParseLawData parses a law_data response, filling in the law ID, revision
+// ID, and category it reports and the attachment list.
+func ParseLawData(data *LawData) (*Law, error) {
+	law, err := ParseLaw(data.XML)
+	if err != nil {
+		return nil, err
+	}
+	law.LawID = data.LawID
+	law.RevisionID = data.RevisionID
+	law.Category = data.Category
+	law.Attachments = data.Attachments
+	// Anchors are assigned again with the law ID.
+	law.assignAnchors()
+	return law, nil
+}
+
+// ArticleCount returns the number of articles in the main and
+// supplementary provisions.
+func (l *Law) ArticleCount() int {
+	n := 0
+	if l.MainProvision != nil {
+		n += len(l.MainProvision.allArticles())
+	}
+	for _, suppl := range l.SupplProvisions {
+		n += len(suppl.allArticles())
+	}
+	return n
+}
+
+// promulgationDate converts the Era, Year, PromulgateMonth, and
+// PromulgateDay attributes of a law, such as Showa, 25, 05, and 04.
+func promulgationDate(era, year, month, day string) time.Time {
+	name := eraName(era)
+	if name == "" || year == "" || month == "" || day == "" {
+		return time.Time{}
+	}
+	t, err := jpdate.Parse(name + year + "年" + month + "月" + day + "日")
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// eraName returns the Japanese name of an Era attribute such as Showa, or
+// an empty string for an unknown era.
+func eraName(era string) string {
+	names := map[string]string{"Meiji": "明治", "Taisho": "大正", "Showa": "昭和", "Heisei": "平成", "Reiwa": "令和"}
+	return names[era]
+}
+
+func parseProvision(n *Node) Provision {
+	var provision Provision
+	for _, child := range n.Children {
+		switch {
+		case isDivision(child.Name):
+			provision.Divisions = append(provision.Divisions, parseDivision(child))
+		case child.Name == "Article":
+			provision.Articles = append(provision.Articles, parseArticle(child))
+		case child.Name == "Paragraph":
+			provision.Paragraphs = append(provision.Paragraphs, parseParagraph(child))
+		}
+	}
+	return provision
+}
+
+func parseDivision(n *Node) Division {
+	division := Division{
+		Kind:  n.Name,
+		Num:   n.Attr("Num"),
+		Title: n.Child(n.Name + "Title").Text(),
+	}
+	for _, child := range n.Children {
+		switch {
+		case isDivision(child.Name):
+			division.Divisions = append(division.Divisions, parseDivision(child))
+		case child.Name == "Article":
+			division.Articles = append(division.Articles, parseArticle(child))
+		}
+	}
+	return division
+}
+
+func parseArticle(n *Node) Article {
+	article := Article{
+		Num:     n.Attr("Num"),
+		Caption: n.Child("ArticleCaption").Text(),
+		Title:   n.Child("ArticleTitle").Text(),
+	}
+	for _, child := range n.Children {
+		if child.Name == "Paragraph" {
+			article.Paragraphs = append(article.Paragraphs, parseParagraph(child))
+		}
+	}
+	return article
+}
+
+func parseParagraph(n *Node) Paragraph {
+	paragraph := Paragraph{
+		Num:       n.Attr("Num"),
+		NumText:   n.Child("ParagraphNum").Text(),
+		Sentences: sentences(n.Child("ParagraphSentence")),
+	}
+	for _, child := range n.Children {
+		if child.Name == "Item" {
+			paragraph.Items = append(paragraph.Items, parseItem(child, "Item"))
+		}
+	}
+	return paragraph
+}
+
+// parseItem handles Item and Subitem1 through Subitem10, which share the
+// same shape with level-specific element names.
+func parseItem(n *Node, name string) Item {
+	item := Item{
+		Num:       n.Attr("Num"),
+		Title:     n.Child(name + "Title").Text(),
+		Sentences: sentences(n.Child(name + "Sentence")),
+	}
+
+	next := "Subitem1"
+	if strings.HasPrefix(name, "Subitem") {
+		next = nextSubitemName(name)
+	}
+	for _, child := range n.Children {
+		if child.Name == next {
+			item.Subitems = append(item.Subitems, parseItem(child, next))
+		}
+	}
+	return item
+}
+
+func nextSubitemName(name string) string {
+	levels := []string{
+		"Subitem1", "Subitem2", "Subitem3", "Subitem4", "Subitem5",
+		"Subitem6", "Subitem7", "Subitem8", "Subitem9", "Subitem10",
+	}
+	for i, level := range levels[:len(levels)-1] {
+		if level == name {
+			return levels[i+1]
+		}
+	}
+	return ""
+}
+
+// sentences collects Sentence text from a *Sentence element. Columns are
+// flattened into a single entry with their parts separated by a full-width
+// space, as they are laid out in print.
+func sentences(n *Node) []string {
+	if n == nil {
+		return nil
+	}
+
+	var result []string
+	var columns []string
+	for _, child := range n.Children {
+		switch child.Name {
+		case "Sentence":
+			result = append(result, child.Text())
+		case "Column":
+			columns = append(columns, child.Text())
+		}
+	}
+	if len(columns) > 0 {
+		result = append(result, strings.Join(columns, "　"))
+	}
+	return result
+}
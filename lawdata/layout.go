@@ -0,0 +1,109 @@
+package lawdata
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/fonts"
+)
+
+// Bounds of Options.FontSize in percent.
+const (
+	MinFontSize = 50
+	MaxFontSize = 300
+)
+
+// Bounds of Options.LineHeight in percent of the font size.
+const (
+	MinLineHeight = 100
+	MaxLineHeight = 300
+)
+
+// MaxLetterSpacing bounds Options.LetterSpacing in percent of the font
+// size.
+const MaxLetterSpacing = 50
+
+// ValidateLayout reports layout options that WriteEPUB cannot apply.
+func ValidateLayout(opts Options) error {
+	switch opts.FontFamily {
+	case "", "serif", "sans-serif":
+	default:
+		return fmt.Errorf("unsupported font family %q (expected serif or sans-serif)", opts.FontFamily)
+	}
+	if opts.FontSize != 0 && (opts.FontSize < MinFontSize || opts.FontSize > MaxFontSize) {
+		return fmt.Errorf("font size must be between %d and %d percent, got %d", MinFontSize, MaxFontSize, opts.FontSize)
+	}
+	if opts.LineHeight != 0 && (opts.LineHeight < MinLineHeight || opts.LineHeight > MaxLineHeight) {
+		return fmt.Errorf("line height must be between %d and %d percent, got %d", MinLineHeight, MaxLineHeight, opts.LineHeight)
+	}
+	if opts.LetterSpacing < 0 || opts.LetterSpacing > MaxLetterSpacing {
+		return fmt.Errorf("letter spacing must be between 0 and %d percent, got %d", MaxLetterSpacing, opts.LetterSpacing)
+	}
+	return nil
+}
+
+// layoutFuncs adds the template functions for the writing mode, typeface,
+// spacing, and colors of an EPUB book.
+func layoutFuncs(funcs template.FuncMap, opts Options) error {
+	if err := ValidateLayout(opts); err != nil {
+		return err
+	}
+
+	var rules []string
+	if opts.Vertical {
+		rules = append(rules, "html { writing-mode: vertical-rl; -epub-writing-mode: vertical-rl; }")
+	}
+	var body []string
+	switch {
+	case opts.Font != nil:
+		// Font names are limited to letters, digits, hyphens, and
+		// underscores.
+		rules = append(rules, fmt.Sprintf(`@font-face { font-family: "%s"; src: url("%s"); }`, opts.Font.Name, fontHref(opts.Font)))
+		fallback := opts.FontFamily
+		if fallback == "" {
+			fallback = "serif"
+		}
+		body = append(body, fmt.Sprintf(`font-family: "%s", %s;`, opts.Font.Name, fallback))
+	case opts.FontFamily != "":
+		body = append(body, "font-family: "+opts.FontFamily+";")
+	}
+	if opts.FontSize != 0 {
+		body = append(body, fmt.Sprintf("font-size: %d%%;", opts.FontSize))
+	}
+	if opts.LineHeight != 0 {
+		body = append(body, fmt.Sprintf("line-height: %d%%;", opts.LineHeight))
+	}
+	if opts.LetterSpacing != 0 {
+		body = append(body, fmt.Sprintf("letter-spacing: %.2fem;", float64(opts.LetterSpacing)/100))
+	}
+	if opts.HighContrast {
+		body = append(body, "color: #000; background-color: #fff;")
+	}
+	if len(body) > 0 {
+		rules = append(rules, "body { "+strings.Join(body, " ")+" }")
+	}
+	if opts.HighContrast {
+		// Links and marked changes are told apart by more than color.
+		rules = append(rules, "a, ins, del { color: #000; } a { text-decoration: underline; } ins { font-weight: bold; }")
+	}
+
+	// The rules are built from validated options only.
+	stylesheet := template.CSS(strings.Join(rules, "\n"))
+	funcs["stylesheet"] = func() template.CSS { return stylesheet }
+	funcs["vertical"] = func() bool { return opts.Vertical }
+	return nil
+}
+
+// fontHref is the path of an embedded font in an EPUB book.
+func fontHref(font *fonts.Font) string {
+	return "fonts/" + font.File
+}
+
+// fontFile returns the embedded font of a book, or nil without one.
+func fontFile(opts Options) []bookFile {
+	if opts.Font == nil {
+		return nil
+	}
+	return []bookFile{{"OEBPS/" + fontHref(opts.Font), opts.Font.Data}}
+}
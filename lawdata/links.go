@@ -0,0 +1,153 @@
+package lawdata
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// eGovLawURL is the e-Gov page of a law by its law ID.
+const eGovLawURL = "https://laws.e-gov.go.jp/law/"
+
+// Citation is a cross-reference found in a law. Provision is empty for the
+// main provision, or the heading of a supplementary provision, and
+// SourceArticle is the title of the article containing the reference.
+type Citation struct {
+	Provision     string
+	SourceArticle string
+	lawref.Reference
+}
+
+// Citations lists the cross-references in the paragraphs and items of the
+// law in document order.
+func (l *Law) Citations() []Citation {
+	var citations []Citation
+	for _, a := range l.diffArticles() {
+		add := func(text string) {
+			for _, ref := range lawref.Find(text) {
+				citations = append(citations, Citation{Provision: a.provision, SourceArticle: a.article.Title, Reference: ref})
+			}
+		}
+		var addItems func(items []Item)
+		addItems = func(items []Item) {
+			for _, item := range items {
+				add(item.Text())
+				addItems(item.Subitems)
+			}
+		}
+		for _, p := range a.article.Paragraphs {
+			add(p.Text())
+			addItems(p.Items)
+		}
+	}
+	return citations
+}
+
+// textFuncs returns the template function "text", which writes a text with
+// ruby readings from annotator, if any, and links for its cross-references
+// to the targets links returns.
+func textFuncs(law *Law, annotator Annotator, links func(lawref.Reference) string) (template.FuncMap, error) {
+	annotated, err := annotate(law, annotator)
+	if err != nil {
+		return nil, err
+	}
+
+	return template.FuncMap{
+		"text": func(text string) template.HTML {
+			segments, ok := annotated[text]
+			if !ok {
+				segments = []RubySegment{{Text: text}}
+			}
+			return linkedHTML(segments, lawref.Find(text), links)
+		},
+	}, nil
+}
+
+// lawLinks links the cross-references of a law: articles of its main
+// provision to their IDs in articleIDs, and acts and cabinet orders to their
+// page on e-Gov.
+func lawLinks(articleIDs map[string]string) func(lawref.Reference) string {
+	return func(ref lawref.Reference) string {
+		if ref.LawNum != "" {
+			if id, ok := lawref.LawID(ref.LawNum); ok {
+				return eGovLawURL + id
+			}
+			return ""
+		}
+		if id, ok := articleIDs[ref.Article]; ok {
+			return "#" + id
+		}
+		return ""
+	}
+}
+
+// linkedHTML writes segments as rubyHTML does, wrapping each reference with
+// a link target in <a>. A link grows to whole segments with readings.
+func linkedHTML(segments []RubySegment, refs []lawref.Reference, href func(lawref.Reference) string) template.HTML {
+	var links []lawref.Reference
+	var targets []string
+	for _, ref := range refs {
+		if target := href(ref); target != "" {
+			links = append(links, ref)
+			targets = append(targets, target)
+		}
+	}
+	if len(links) == 0 {
+		return rubyHTML(segments)
+	}
+
+	// Plain segments are split at link boundaries.
+	var bounds []int
+	for _, link := range links {
+		bounds = append(bounds, link.Start, link.End)
+	}
+	sort.Ints(bounds)
+	var split []RubySegment
+	pos := 0
+	for _, segment := range segments {
+		if segment.Reading != "" {
+			split = append(split, segment)
+			pos += len(segment.Text)
+			continue
+		}
+		text := segment.Text
+		for _, bound := range bounds {
+			if bound > pos && bound < pos+len(text) {
+				split = append(split, RubySegment{Text: text[:bound-pos]})
+				text = text[bound-pos:]
+				pos = bound
+			}
+		}
+		split = append(split, RubySegment{Text: text})
+		pos += len(text)
+	}
+
+	var b strings.Builder
+	pos = 0
+	k := 0
+	open := false
+	for _, segment := range split {
+		end := pos + len(segment.Text)
+		for !open && k < len(links) && links[k].End <= pos {
+			k++
+		}
+		if !open && k < len(links) && links[k].Start < end {
+			fmt.Fprintf(&b, `<a href="%s">`, template.HTMLEscapeString(targets[k]))
+			open = true
+		}
+		b.WriteString(string(rubyHTML([]RubySegment{segment})))
+		pos = end
+		if open && pos >= links[k].End {
+			b.WriteString("</a>")
+			open = false
+			k++
+		}
+	}
+	if open {
+		b.WriteString("</a>")
+	}
+	return template.HTML(b.String())
+}
@@ -0,0 +1,85 @@
+package lawdata
+
+import (
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/lawref"
+)
+
+// Publisher is the publisher recorded for every law.
+const Publisher = "日本国政府"
+
+// Metadata is the bibliographic description of a law written to the EPUB
+// package document as Dublin Core terms.
+type Metadata struct {
+	// Identifier is the revision ID, or the law number when the revision
+	// is unknown.
+	Identifier string
+	Title      string
+	TitleKana  string
+	TitleEn    string
+	// LawID is empty when it is neither reported by e-Gov nor derivable
+	// from the law number.
+	LawID  string
+	LawNum string
+	// LawType and Era are the Japanese names, such as 法律 and 昭和.
+	LawType          string
+	Era              string
+	PromulgationDate *time.Time
+	// Subjects are the e-Gov category and the law type.
+	Subjects  []string
+	Publisher string
+	Language  string
+	// Source is the page of the law on e-Gov.
+	Source string
+	// Rights notes that laws are not subject to copyright under Article 13
+	// of the Copyright Act.
+	Rights string
+}
+
+// Metadata describes the law for catalogs and EPUB metadata.
+func (l *Law) Metadata() Metadata {
+	lawTypes := map[string]string{
+		"Constitution":         "憲法",
+		"Act":                  "法律",
+		"CabinetOrder":         "政令",
+		"ImperialOrder":        "勅令",
+		"MinisterialOrdinance": "府省令",
+		"Rule":                 "規則",
+		"Misc":                 "その他",
+	}
+
+	m := Metadata{
+		Identifier: l.RevisionID,
+		Title:      l.LawTitle,
+		TitleKana:  l.LawTitleKana,
+		TitleEn:    l.TitleEn,
+		LawID:      l.LawID,
+		LawNum:     l.LawNum,
+		LawType:    lawTypes[l.LawType],
+		Era:        eraName(l.Era),
+		Subjects:   []string{},
+		Publisher:  Publisher,
+		Language:   "ja",
+		Rights:     "著作権法第十三条により著作権の目的となりません。",
+	}
+	if m.Identifier == "" {
+		m.Identifier = l.LawNum
+	}
+	if m.LawID == "" {
+		m.LawID, _ = lawref.LawID(l.LawNum)
+	}
+	if m.LawID != "" {
+		m.Source = eGovLawURL + m.LawID
+	}
+	if !l.PromulgationDate.IsZero() {
+		date := l.PromulgationDate
+		m.PromulgationDate = &date
+	}
+	for _, subject := range []string{l.Category, m.LawType} {
+		if subject != "" {
+			m.Subjects = append(m.Subjects, subject)
+		}
+	}
+	return m
+}
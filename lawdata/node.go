@@ -0,0 +1,119 @@
+package lawdata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Node is a generic XML element. The law XML schema nests elements in many
+// optional combinations, so it is walked as a tree rather than decoded into
+// fixed structs.
+type Node struct {
+	Name     string
+	Attrs    map[string]string
+	Children []*Node
+	// Segments holds character data and child elements in document order.
+	Segments []Segment
+}
+
+// Segment is either character data or a child element.
+type Segment struct {
+	Text  string
+	Child *Node
+}
+
+// ParseXML parses a document into a Node tree rooted at its first element.
+func ParseXML(data []byte) (*Node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*Node
+	var root *Node
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML format: %v", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &Node{Name: t.Name.Local, Attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+				parent.Segments = append(parent.Segments, Segment{Child: node})
+			} else if root == nil {
+				root = node
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Segments = append(parent.Segments, Segment{Text: string(t)})
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, errors.New("invalid XML format: no root element")
+	}
+	return root, nil
+}
+
+// Child returns the first direct child with the given name.
+func (n *Node) Child(name string) *Node {
+	if n == nil {
+		return nil
+	}
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// Text returns the element's text content. Ruby readings (Rt) are omitted
+// so that the result reads as the base text.
+func (n *Node) Text() string {
+	if n == nil {
+		return ""
+	}
+	var sb strings.Builder
+	n.writeText(&sb)
+	return strings.TrimSpace(sb.String())
+}
+
+func (n *Node) writeText(sb *strings.Builder) {
+	for _, seg := range n.Segments {
+		if seg.Child == nil {
+			sb.WriteString(seg.Text)
+			continue
+		}
+		if seg.Child.Name == "Rt" {
+			continue
+		}
+		seg.Child.writeText(sb)
+	}
+}
+
+// Attr returns an attribute value or an empty string.
+func (n *Node) Attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	return n.Attrs[name]
+}
@@ -0,0 +1,337 @@
+package lawdata
+
+import (
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+)
+
+// maxDiffCells bounds the work of a character diff; longer texts are shown
+// as deleted and inserted whole.
+const maxDiffCells = 4 << 20
+
+// DiffSegment is a run of text that is unchanged, inserted (ChangeAdded),
+// or deleted (ChangeRemoved).
+type DiffSegment struct {
+	Text   string
+	Change Change
+}
+
+// MarkChanges records in to how it differs from the earlier revision from,
+// for rendering as a redline: articles, paragraphs, and items are marked as
+// added or carry a character diff of their text, and removed ones are
+// inserted where they used to be. Division titles are not compared. Both
+// revisions must be of the same law.
+func MarkChanges(from, to *Law) error {
+	if from.LawNum != to.LawNum {
+		return fmt.Errorf("cannot compare %s with %s: revisions are of different laws", from.LawNum, to.LawNum)
+	}
+
+	to.Baseline = from.RevisionID
+	if to.Baseline == "" {
+		to.Baseline = from.LawNum
+	}
+
+	if to.MainProvision != nil {
+		markProvision(from.MainProvision, to.MainProvision)
+	}
+	supplByKey := make(map[string]*Provision, len(from.SupplProvisions))
+	for i := range from.SupplProvisions {
+		supplByKey[supplKey(from.SupplProvisions[i])] = &from.SupplProvisions[i]
+	}
+	for i := range to.SupplProvisions {
+		markProvision(supplByKey[supplKey(to.SupplProvisions[i])], &to.SupplProvisions[i])
+	}
+	return nil
+}
+
+// markProvision marks the changes of a provision from old, which is nil for
+// a new provision.
+func markProvision(old, p *Provision) {
+	if old == nil {
+		old = &Provision{}
+	}
+
+	oldArticles := old.allArticles()
+	oldByNum := make(map[string]Article, len(oldArticles))
+	oldKeys := make([]string, len(oldArticles))
+	for i, article := range oldArticles {
+		oldByNum[article.Num] = article
+		oldKeys[i] = article.Num
+	}
+	newArticles := p.allArticles()
+	newKeys := make([]string, len(newArticles))
+	for i, article := range newArticles {
+		newKeys[i] = article.Num
+	}
+	removed := removedPositions(oldKeys, newKeys)
+
+	// Articles are visited in the order of allArticles; removed articles
+	// follow their predecessor, or open the first list when they have none.
+	index := 0
+	headPlaced := false
+	markArticles := func(articles []Article) []Article {
+		var result []Article
+		if !headPlaced {
+			headPlaced = true
+			for _, i := range removed[-1] {
+				result = append(result, removedArticle(oldArticles[i]))
+			}
+		}
+		for _, article := range articles {
+			if before, ok := oldByNum[article.Num]; ok {
+				markArticle(before, &article)
+			} else {
+				article = addedArticle(article)
+			}
+			result = append(result, article)
+			for _, i := range removed[index] {
+				result = append(result, removedArticle(oldArticles[i]))
+			}
+			index++
+		}
+		return result
+	}
+	var markDivisions func(divisions []Division)
+	markDivisions = func(divisions []Division) {
+		for i := range divisions {
+			markDivisions(divisions[i].Divisions)
+			divisions[i].Articles = markArticles(divisions[i].Articles)
+		}
+	}
+	markDivisions(p.Divisions)
+	p.Articles = markArticles(p.Articles)
+	p.Paragraphs = markParagraphs(old.Paragraphs, p.Paragraphs)
+}
+
+func markArticle(before Article, article *Article) {
+	if before.Caption != article.Caption {
+		article.CaptionDiff = diffText(before.Caption, article.Caption)
+	}
+	article.Paragraphs = markParagraphs(before.Paragraphs, article.Paragraphs)
+}
+
+func addedArticle(article Article) Article {
+	article.Change = ChangeAdded
+	article.Paragraphs = markParagraphs(nil, article.Paragraphs)
+	return article
+}
+
+// removedArticle marks an article of the earlier revision. It has no
+// anchor, as it is not part of the revision rendered.
+func removedArticle(article Article) Article {
+	article.Change = ChangeRemoved
+	article.Anchor = ""
+	article.Paragraphs = markParagraphs(article.Paragraphs, nil)
+	return article
+}
+
+// markParagraphs returns the paragraphs of the new revision, matched with
+// the old ones by number, with removed paragraphs after their predecessor.
+func markParagraphs(old, paragraphs []Paragraph) []Paragraph {
+	key := func(i int, p Paragraph) string {
+		if p.Num != "" {
+			return p.Num
+		}
+		return strconv.Itoa(i + 1)
+	}
+	oldByKey := make(map[string]Paragraph, len(old))
+	oldKeys := make([]string, len(old))
+	for i, p := range old {
+		oldKeys[i] = key(i, p)
+		oldByKey[oldKeys[i]] = p
+	}
+	newKeys := make([]string, len(paragraphs))
+	for i, p := range paragraphs {
+		newKeys[i] = key(i, p)
+	}
+	removed := removedPositions(oldKeys, newKeys)
+
+	remove := func(p Paragraph) Paragraph {
+		p.Change = ChangeRemoved
+		p.Anchor = ""
+		p.Items = markItems(p.Items, nil)
+		return p
+	}
+	var result []Paragraph
+	for _, i := range removed[-1] {
+		result = append(result, remove(old[i]))
+	}
+	for i, p := range paragraphs {
+		if before, ok := oldByKey[newKeys[i]]; ok {
+			if before.Text() != p.Text() {
+				p.Diff = diffText(before.Text(), p.Text())
+			}
+			p.Items = markItems(before.Items, p.Items)
+		} else {
+			p.Change = ChangeAdded
+			p.Items = markItems(nil, p.Items)
+		}
+		result = append(result, p)
+		for _, j := range removed[i] {
+			result = append(result, remove(old[j]))
+		}
+	}
+	return result
+}
+
+// markItems does for items and their subitems what markParagraphs does for
+// paragraphs.
+func markItems(old, items []Item) []Item {
+	key := func(i int, item Item) string {
+		if item.Num != "" {
+			return item.Num
+		}
+		return strconv.Itoa(i + 1)
+	}
+	oldByKey := make(map[string]Item, len(old))
+	oldKeys := make([]string, len(old))
+	for i, item := range old {
+		oldKeys[i] = key(i, item)
+		oldByKey[oldKeys[i]] = item
+	}
+	newKeys := make([]string, len(items))
+	for i, item := range items {
+		newKeys[i] = key(i, item)
+	}
+	removed := removedPositions(oldKeys, newKeys)
+
+	remove := func(item Item) Item {
+		item.Change = ChangeRemoved
+		item.Subitems = markItems(item.Subitems, nil)
+		return item
+	}
+	var result []Item
+	for _, i := range removed[-1] {
+		result = append(result, remove(old[i]))
+	}
+	for i, item := range items {
+		if before, ok := oldByKey[newKeys[i]]; ok {
+			if before.Text() != item.Text() {
+				item.Diff = diffText(before.Text(), item.Text())
+			}
+			item.Subitems = markItems(before.Subitems, item.Subitems)
+		} else {
+			item.Change = ChangeAdded
+			item.Subitems = markItems(nil, item.Subitems)
+		}
+		result = append(result, item)
+		for _, j := range removed[i] {
+			result = append(result, remove(old[j]))
+		}
+	}
+	return result
+}
+
+// removedPositions maps the index of each new element to the indexes of the
+// old elements removed right after it, with -1 for those removed before the
+// first remaining element.
+func removedPositions(oldKeys, newKeys []string) map[int][]int {
+	newIndex := make(map[string]int, len(newKeys))
+	for i, key := range newKeys {
+		newIndex[key] = i
+	}
+	positions := make(map[int][]int)
+	last := -1
+	for i, key := range oldKeys {
+		if j, ok := newIndex[key]; ok {
+			last = j
+			continue
+		}
+		positions[last] = append(positions[last], i)
+	}
+	return positions
+}
+
+// diffText returns a character diff from a to b along their longest common
+// subsequence.
+func diffText(a, b string) []DiffSegment {
+	x, y := []rune(a), []rune(b)
+	if len(x)*len(y) > maxDiffCells {
+		return appendSegment(appendSegment(nil, a, ChangeRemoved), b, ChangeAdded)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var segments []DiffSegment
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			segments = appendSegment(segments, string(x[i]), "")
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			segments = appendSegment(segments, string(x[i]), ChangeRemoved)
+			i++
+		default:
+			segments = appendSegment(segments, string(y[j]), ChangeAdded)
+			j++
+		}
+	}
+	return segments
+}
+
+// appendSegment appends text, merging it into the last segment when the
+// change is the same.
+func appendSegment(segments []DiffSegment, text string, change Change) []DiffSegment {
+	if text == "" {
+		return segments
+	}
+	if n := len(segments); n > 0 && segments[n-1].Change == change {
+		segments[n-1].Text += text
+		return segments
+	}
+	return append(segments, DiffSegment{Text: text, Change: change})
+}
+
+// redlineFuncs adds the template functions "marked", which writes a text
+// with the change marks recorded by MarkChanges: insertions as <ins> and
+// deletions as <del>, and "markedLabel", which does the same for article
+// titles and numbers. Unmarked text is written with "text", and labels
+// escaped as is.
+func redlineFuncs(funcs template.FuncMap) {
+	write := funcs["text"].(func(string) template.HTML)
+	funcs["marked"] = func(change Change, diff []DiffSegment, text string) template.HTML {
+		if diff != nil {
+			var b strings.Builder
+			for _, segment := range diff {
+				b.WriteString(string(markHTML(segment.Change, template.HTML(template.HTMLEscapeString(segment.Text)))))
+			}
+			return template.HTML(b.String())
+		}
+		if text == "" {
+			return ""
+		}
+		return markHTML(change, write(text))
+	}
+	funcs["markedLabel"] = func(change Change, label string) template.HTML {
+		return markHTML(change, template.HTML(template.HTMLEscapeString(label)))
+	}
+}
+
+func markHTML(change Change, html template.HTML) template.HTML {
+	switch {
+	case change == ChangeAdded:
+		return "<ins>" + html + "</ins>"
+	case change == ChangeRemoved:
+		return "<del>" + html + "</del>"
+	default:
+		return html
+	}
+}
@@ -0,0 +1,110 @@
+package lawdata
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+// RubySegment is a run of law text with the reading to show above it, if
+// any.
+type RubySegment struct {
+	Text    string
+	Reading string
+}
+
+// Annotator adds readings to law text, for example with a morphological
+// analyzer. It returns the segments of every text in order.
+type Annotator interface {
+	Annotate(texts []string) ([][]RubySegment, error)
+}
+
+// annotate returns the ruby segments of every text of the law from
+// annotator, or nil when annotator is nil. All texts are annotated in one
+// batch.
+func annotate(law *Law, annotator Annotator) (map[string][]RubySegment, error) {
+	if annotator == nil {
+		return nil, nil
+	}
+	texts := law.texts()
+	segments, err := annotator.Annotate(texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to annotate law text: %v", err)
+	}
+	if len(segments) != len(texts) {
+		return nil, fmt.Errorf("failed to annotate law text: got %d results for %d texts", len(segments), len(texts))
+	}
+	annotated := make(map[string][]RubySegment, len(texts))
+	for i, text := range texts {
+		annotated[text] = segments[i]
+	}
+	return annotated, nil
+}
+
+// rubyHTML writes segments with readings as <ruby> elements, with
+// parentheses for readers without ruby support.
+func rubyHTML(segments []RubySegment) template.HTML {
+	var b strings.Builder
+	for _, segment := range segments {
+		if segment.Reading == "" {
+			b.WriteString(template.HTMLEscapeString(segment.Text))
+			continue
+		}
+		fmt.Fprintf(&b, "<ruby>%s<rp>（</rp><rt>%s</rt><rp>）</rp></ruby>",
+			template.HTMLEscapeString(segment.Text), template.HTMLEscapeString(segment.Reading))
+	}
+	return template.HTML(b.String())
+}
+
+// texts lists the distinct titles, captions, and sentences rendered with
+// ruby.
+func (l *Law) texts() []string {
+	var texts []string
+	seen := make(map[string]bool)
+	add := func(text string) {
+		if text != "" && !seen[text] {
+			seen[text] = true
+			texts = append(texts, text)
+		}
+	}
+
+	var addItems func(items []Item)
+	addItems = func(items []Item) {
+		for _, item := range items {
+			add(item.Text())
+			addItems(item.Subitems)
+		}
+	}
+	addParagraphs := func(paragraphs []Paragraph) {
+		for _, paragraph := range paragraphs {
+			add(paragraph.Text())
+			addItems(paragraph.Items)
+		}
+	}
+	addArticles := func(articles []Article) {
+		for _, article := range articles {
+			add(article.Caption)
+			addParagraphs(article.Paragraphs)
+		}
+	}
+	var addDivisions func(divisions []Division)
+	addDivisions = func(divisions []Division) {
+		for _, division := range divisions {
+			add(division.Title)
+			addDivisions(division.Divisions)
+			addArticles(division.Articles)
+		}
+	}
+
+	add(l.LawTitle)
+	provisions := l.SupplProvisions
+	if l.MainProvision != nil {
+		provisions = append([]Provision{*l.MainProvision}, provisions...)
+	}
+	for _, provision := range provisions {
+		addDivisions(provision.Divisions)
+		addArticles(provision.Articles)
+		addParagraphs(provision.Paragraphs)
+	}
+	return texts
+}
@@ -0,0 +1,167 @@
+package lawdata
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/text"
+)
+
+// snippetContext is the number of characters of a snippet kept before the
+// first and after the last highlighted term.
+const snippetContext = 40
+
+// Match is an article caption, paragraph, or item containing every term of
+// a search. Provision is empty for the main provision, or the heading of a
+// supplementary provision. Paragraph is empty for a caption match, and Item
+// lists the titles of the item and its parents, such as 一 イ, for an item
+// match. Anchor is the anchor of the paragraph, or of the article for a
+// caption match.
+type Match struct {
+	Provision  string
+	Article    string
+	Caption    string
+	Paragraph  string
+	Item       string
+	Anchor     string
+	Snippet    string
+	Highlights []Highlight
+}
+
+// Highlight is an occurrence of a search term in a snippet, as character
+// offsets: Start is included and End is not.
+type Highlight struct {
+	Start int
+	End   int
+}
+
+// Search finds the captions, paragraphs, and items of the law containing
+// every whitespace-separated term of query, in document order, stopping at
+// limit matches when it is positive. Terms are compared as text.Normalize
+// compares them, ignoring width, case, and katakana versus hiragana.
+func (l *Law) Search(query string, limit int) []Match {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if term := text.Normalize(field); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var matches []Match
+	full := func() bool { return limit > 0 && len(matches) >= limit }
+	for _, a := range l.diffArticles() {
+		add := func(m Match, s string) {
+			if full() {
+				return
+			}
+			if snippet, highlights, ok := highlight(s, terms); ok {
+				m.Provision = a.provision
+				m.Article = a.article.Title
+				m.Caption = a.article.Caption
+				m.Snippet = snippet
+				m.Highlights = highlights
+				matches = append(matches, m)
+			}
+		}
+		var addItems func(paragraph Match, parent string, items []Item)
+		addItems = func(paragraph Match, parent string, items []Item) {
+			for _, item := range items {
+				m := paragraph
+				m.Item = strings.TrimSpace(parent + " " + item.Title)
+				add(m, item.Text())
+				addItems(paragraph, m.Item, item.Subitems)
+			}
+		}
+
+		add(Match{Anchor: a.article.Anchor}, a.article.Caption)
+		for i, p := range a.article.Paragraphs {
+			num := p.Num
+			if num == "" {
+				num = strconv.Itoa(i + 1)
+			}
+			paragraph := Match{Paragraph: num, Anchor: p.Anchor}
+			add(paragraph, p.Text())
+			addItems(paragraph, "", p.Items)
+		}
+		if full() {
+			break
+		}
+	}
+	return matches
+}
+
+// highlight finds every term in s and returns the part of s around them
+// with their positions, or false when a term is missing.
+func highlight(s string, terms []string) (string, []Highlight, bool) {
+	// folded holds s normalized one character at a time, with the index
+	// of the character each byte of it came from.
+	var folded strings.Builder
+	var origin []int
+	runes := []rune(s)
+	for i, r := range runes {
+		f := text.Normalize(string(r))
+		folded.WriteString(f)
+		for range len(f) {
+			origin = append(origin, i)
+		}
+	}
+	haystack := folded.String()
+
+	var highlights []Highlight
+	for _, term := range terms {
+		found := false
+		for offset := 0; offset < len(haystack); {
+			i := strings.Index(haystack[offset:], term)
+			if i < 0 {
+				break
+			}
+			start := offset + i
+			end := start + len(term)
+			highlights = append(highlights, Highlight{Start: origin[start], End: origin[end-1] + 1})
+			found = true
+			offset = end
+		}
+		if !found {
+			return "", nil, false
+		}
+	}
+	highlights = mergeHighlights(highlights)
+
+	from := max(highlights[0].Start-snippetContext, 0)
+	to := min(highlights[len(highlights)-1].End+snippetContext, len(runes))
+	var snippet strings.Builder
+	shift := 0
+	if from > 0 {
+		snippet.WriteString("…")
+		shift = 1
+	}
+	snippet.WriteString(string(runes[from:to]))
+	if to < len(runes) {
+		snippet.WriteString("…")
+	}
+	for i := range highlights {
+		highlights[i].Start += shift - from
+		highlights[i].End += shift - from
+	}
+	return snippet.String(), highlights, true
+}
+
+// mergeHighlights sorts highlights and joins those that overlap.
+func mergeHighlights(highlights []Highlight) []Highlight {
+	sort.Slice(highlights, func(i, k int) bool {
+		return highlights[i].Start < highlights[k].Start
+	})
+	merged := highlights[:0]
+	for _, h := range highlights {
+		if n := len(merged); n > 0 && h.Start <= merged[n-1].End {
+			merged[n-1].End = max(merged[n-1].End, h.End)
+			continue
+		}
+		merged = append(merged, h)
+	}
+	return merged
+}
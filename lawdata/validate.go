@@ -0,0 +1,292 @@
+package lawdata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is a problem found in a law XML document by ValidateXML.
+type ValidationError struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Path locates the element, such as /Law/LawBody/MainProvision/Article[3].
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// elementRule is the part of the 法令標準XML schema checked for an element.
+type elementRule struct {
+	// attrs are required attributes.
+	attrs []string
+	// values restricts attribute values.
+	values map[string][]string
+	// children are required child elements.
+	children []string
+	// allowed lists the permitted child elements; nil permits any.
+	allowed []string
+	// text requires character data.
+	text bool
+}
+
+// validationRules returns the rules for the structural elements of the
+// schema. Inline and table markup is not checked.
+func validationRules() map[string]elementRule {
+	rules := map[string]elementRule{
+		"Law": {
+			attrs: []string{"Era", "Year", "Num", "LawType", "Lang"},
+			values: map[string][]string{
+				"Era":     {"Meiji", "Taisho", "Showa", "Heisei", "Reiwa"},
+				"LawType": {"Constitution", "Act", "CabinetOrder", "ImperialOrder", "MinisterialOrdinance", "Rule", "Misc"},
+				"Lang":    {"ja", "en"},
+			},
+			children: []string{"LawNum", "LawBody"},
+			allowed:  []string{"LawNum", "LawBody"},
+		},
+		"LawNum":   {text: true},
+		"LawTitle": {text: true},
+		"LawBody": {
+			children: []string{"LawTitle", "MainProvision"},
+			allowed: []string{
+				"LawTitle", "EnactStatement", "SubjectText", "TOC", "Preamble", "MainProvision", "SupplProvision",
+				"AppdxTable", "AppdxNote", "AppdxStyle", "Appdx", "AppdxFig", "AppdxFormat",
+			},
+		},
+		"MainProvision": {
+			allowed: []string{"Part", "Chapter", "Section", "Article", "Paragraph"},
+		},
+		"SupplProvision": {
+			children: []string{"SupplProvisionLabel"},
+			allowed: []string{
+				"SupplProvisionLabel", "Chapter", "Article", "Paragraph",
+				"SupplProvisionAppdxTable", "SupplProvisionAppdxStyle", "SupplProvisionAppdx",
+			},
+		},
+		"Part":       division("Part", "Chapter", "Article"),
+		"Chapter":    division("Chapter", "Section", "Article"),
+		"Section":    division("Section", "Subsection", "Division", "Article"),
+		"Subsection": division("Subsection", "Division", "Article"),
+		"Division":   division("Division", "Article"),
+		"Article": {
+			attrs:    []string{"Num"},
+			children: []string{"ArticleTitle", "Paragraph"},
+			allowed:  []string{"ArticleCaption", "ArticleTitle", "Paragraph", "SupplNote"},
+		},
+		"Paragraph": {
+			attrs:    []string{"Num"},
+			children: []string{"ParagraphNum", "ParagraphSentence"},
+			allowed: []string{
+				"ParagraphCaption", "ParagraphNum", "ParagraphSentence", "AmendProvision", "Class",
+				"TableStruct", "FigStruct", "StyleStruct", "Item", "List",
+			},
+		},
+		"ParagraphSentence": {
+			children: []string{"Sentence"},
+			allowed:  []string{"Sentence"},
+		},
+		"Item": item("Item", "Subitem1"),
+	}
+
+	for level := 1; level <= 10; level++ {
+		name := "Subitem" + strconv.Itoa(level)
+		next := ""
+		if level < 10 {
+			next = "Subitem" + strconv.Itoa(level+1)
+		}
+		rules[name] = item(name, next)
+	}
+	return rules
+}
+
+// division returns the rule of a Part, Chapter, or other division, which
+// needs a title and contains the given elements.
+func division(name string, allowed ...string) elementRule {
+	return elementRule{
+		attrs:    []string{"Num"},
+		children: []string{name + "Title"},
+		allowed:  append([]string{name + "Title"}, allowed...),
+	}
+}
+
+// item returns the rule of an Item or SubitemN element whose next level is
+// next, or none when next is empty.
+func item(name, next string) elementRule {
+	allowed := []string{name + "Title", name + "Sentence", "TableStruct", "FigStruct", "StyleStruct", "List"}
+	if next != "" {
+		allowed = append(allowed, next)
+	}
+	return elementRule{
+		attrs:    []string{"Num"},
+		children: []string{name + "Sentence"},
+		allowed:  allowed,
+	}
+}
+
+// validationFrame is an open element during validation.
+type validationFrame struct {
+	name   string
+	path   string
+	line   int
+	column int
+	rule   *elementRule
+	counts map[string]int
+	text   bool
+}
+
+// ValidateXML checks a law XML document against the structure of the
+// 法令標準XML schema: the root element, required attributes and their
+// values, and the elements each structural element requires and permits.
+// It returns nil for a valid document. A document that is not well-formed
+// yields a single error.
+func ValidateXML(data []byte) []ValidationError {
+	rules := validationRules()
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var errs []ValidationError
+	var stack []*validationFrame
+	rootSeen := false
+	for {
+		line, column := decoder.InputPos()
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				line = syntaxErr.Line
+				column = 0
+			}
+			return append(errs, ValidationError{Line: line, Column: column, Path: framePath(stack), Message: "malformed XML: " + err.Error()})
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			frame := &validationFrame{name: name, line: line, column: column, counts: make(map[string]int)}
+			if len(stack) == 0 {
+				if rootSeen {
+					continue
+				}
+				rootSeen = true
+				frame.path = "/" + name
+				if name != "Law" {
+					errs = append(errs, ValidationError{Line: line, Column: column, Path: frame.path, Message: "root element must be Law"})
+				}
+			} else {
+				parent := stack[len(stack)-1]
+				parent.counts[name]++
+				frame.path = fmt.Sprintf("%s/%s[%d]", parent.path, name, parent.counts[name])
+				if parent.rule != nil && parent.rule.allowed != nil && !slices.Contains(parent.rule.allowed, name) {
+					errs = append(errs, ValidationError{Line: line, Column: column, Path: frame.path, Message: fmt.Sprintf("element %s is not allowed in %s", name, parent.name)})
+				}
+			}
+
+			if rule, ok := rules[name]; ok {
+				frame.rule = &rule
+				errs = append(errs, checkAttrs(frame, t.Attr)...)
+			}
+			stack = append(stack, frame)
+		case xml.CharData:
+			if len(stack) > 0 && strings.TrimSpace(string(t)) != "" {
+				stack[len(stack)-1].text = true
+			}
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			frame := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				// Text in child elements counts for the parent.
+				stack[len(stack)-1].text = stack[len(stack)-1].text || frame.text
+			}
+			if frame.rule == nil {
+				continue
+			}
+			for _, child := range frame.rule.children {
+				if frame.counts[child] == 0 {
+					errs = append(errs, ValidationError{Line: frame.line, Column: frame.column, Path: frame.path, Message: fmt.Sprintf("%s must contain %s", frame.name, child)})
+				}
+			}
+			if frame.rule.text && !frame.text {
+				errs = append(errs, ValidationError{Line: frame.line, Column: frame.column, Path: frame.path, Message: fmt.Sprintf("%s must not be empty", frame.name)})
+			}
+		}
+	}
+
+	if !rootSeen {
+		errs = append(errs, ValidationError{Line: 1, Column: 1, Path: "/", Message: "document has no root element"})
+	}
+	return errs
+}
+
+// ValidateDocument validates an uploaded law document in any format
+// accepted by DecodeLawDocument. Documents in the JSON law format are
+// validated as the XML they convert to, so their errors have no line or
+// column.
+func ValidateDocument(data []byte) []ValidationError {
+	xmlData, err := DecodeLawDocument(data)
+	if err != nil {
+		return []ValidationError{{Path: "/", Message: err.Error()}}
+	}
+	errs := ValidateXML(xmlData)
+	if !bytes.Equal(xmlData, data) {
+		for i := range errs {
+			errs[i].Line, errs[i].Column = 0, 0
+		}
+	}
+	return errs
+}
+
+// checkAttrs reports missing and invalid attributes of an element.
+func checkAttrs(frame *validationFrame, attrs []xml.Attr) []ValidationError {
+	values := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		values[attr.Name.Local] = attr.Value
+	}
+
+	var errs []ValidationError
+	for _, name := range frame.rule.attrs {
+		if values[name] == "" {
+			errs = append(errs, ValidationError{Line: frame.line, Column: frame.column, Path: frame.path, Message: fmt.Sprintf("%s requires the %s attribute", frame.name, name)})
+		}
+	}
+	names := make([]string, 0, len(frame.rule.values))
+	for name := range frame.rule.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		allowed := frame.rule.values[name]
+		if v := values[name]; v != "" && !slices.Contains(allowed, v) {
+			errs = append(errs, ValidationError{Line: frame.line, Column: frame.column, Path: frame.path, Message: fmt.Sprintf("invalid %s %q (expected one of %s)", name, v, strings.Join(allowed, ", "))})
+		}
+	}
+	if frame.name == "Law" {
+		if year := values["Year"]; year != "" {
+			if n, err := strconv.Atoi(year); err != nil || n < 1 {
+				errs = append(errs, ValidationError{Line: frame.line, Column: frame.column, Path: frame.path, Message: fmt.Sprintf("invalid Year %q (expected a positive number)", year)})
+			}
+		}
+	}
+	return errs
+}
+
+// framePath returns the path of the innermost open element.
+func framePath(stack []*validationFrame) string {
+	if len(stack) == 0 {
+		return "/"
+	}
+	return stack[len(stack)-1].path
+}
@@ -0,0 +1,82 @@
+// Package lawid parses the identifiers e-Gov accepts for a law: law IDs
+// such as 325AC0000000131, law numbers such as 昭和二十五年法律第百三十一号,
+// and revision IDs such as 325AC0000000131_20250601_505AC0000000036.
+package lawid
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+)
+
+// Kind is the type of a law identifier.
+type Kind int
+
+const (
+	LawID Kind = iota + 1
+	LawNum
+	RevisionID
+)
+
+func (k Kind) String() string {
+	switch k {
+	case LawID:
+		return "law ID"
+	case LawNum:
+		return "law number"
+	case RevisionID:
+		return "revision ID"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// ID is a parsed law identifier.
+type ID struct {
+	Kind Kind
+	// Value is the identifier as e-Gov stores it. Law numbers are
+	// normalized by jpdate.NormalizeLawNum; other kinds are unchanged.
+	Value string
+	// LawID is the law of a law ID or revision ID, and empty for law
+	// numbers.
+	LawID string
+	// EnforcedOn is the enforcement date of a revision ID.
+	EnforcedOn time.Time
+}
+
+// lawIDSyntax is the syntax of a law ID: the era and year digits followed
+// by twelve letters and digits.
+const lawIDSyntax = `[0-9]{3}[0-9A-Z]{12}`
+
+var (
+	lawIDPattern = regexp.MustCompile(`^` + lawIDSyntax + `$`)
+	// revisionIDPattern captures the law ID, the enforcement date, and the
+	// amending law ID of a revision ID.
+	revisionIDPattern = regexp.MustCompile(`^(` + lawIDSyntax + `)_([0-9]{8})_(` + lawIDSyntax + `)$`)
+)
+
+// Parse tells which kind of identifier s is and rejects anything else, so
+// that malformed identifiers are not sent to e-Gov.
+func Parse(s string) (ID, error) {
+	if lawIDPattern.MatchString(s) {
+		return ID{Kind: LawID, Value: s, LawID: s}, nil
+	}
+
+	if m := revisionIDPattern.FindStringSubmatch(s); m != nil {
+		date, err := time.Parse("20060102", m[2])
+		if err != nil {
+			return ID{}, fmt.Errorf("invalid revision ID %q: bad enforcement date %s", s, m[2])
+		}
+		return ID{Kind: RevisionID, Value: s, LawID: m[1], EnforcedOn: date}, nil
+	}
+
+	// Identifiers become part of storage paths, so slashes are never
+	// accepted.
+	if lawNum, err := jpdate.NormalizeLawNum(s); err == nil && !strings.Contains(lawNum, "/") {
+		return ID{Kind: LawNum, Value: lawNum}, nil
+	}
+	return ID{}, fmt.Errorf("invalid law identifier %q: expected a law ID such as 325AC0000000131, a law number such as 昭和二十五年法律第百三十一号, or a revision ID such as 325AC0000000131_20250601_505AC0000000036", s)
+}
@@ -0,0 +1,68 @@
+package lawid
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want ID
+	}{
+		{"325AC0000000131", ID{Kind: LawID, Value: "325AC0000000131", LawID: "325AC0000000131"}},
+		{"321CONSTITUTION", ID{Kind: LawID, Value: "321CONSTITUTION", LawID: "321CONSTITUTION"}},
+		{
+			"325AC0000000131_20250601_505AC0000000036",
+			ID{Kind: RevisionID, Value: "325AC0000000131_20250601_505AC0000000036", LawID: "325AC0000000131", EnforcedOn: time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{"昭和二十五年法律第百三十一号", ID{Kind: LawNum, Value: "昭和二十五年法律第百三十一号"}},
+		{"昭和25年法律第131号", ID{Kind: LawNum, Value: "昭和二十五年法律第百三十一号"}},
+		{"昭和二十一年憲法", ID{Kind: LawNum, Value: "昭和二十一年憲法"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"325ac0000000131",
+		"325AC000000013",
+		"325AC0000000131_2025061_505AC0000000036",
+		"325AC0000000131_20251301_505AC0000000036",
+		"325AC0000000131/../x",
+		"tenants/acme/325AC0000000131",
+		"昭和二十五年法律/第百三十一号",
+		"西暦二十五年法律第百三十一号",
+	} {
+		if got, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", in, got)
+		}
+	}
+}
+
+func TestKindString(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want string
+	}{
+		{LawID, "law ID"},
+		{LawNum, "law number"},
+		{RevisionID, "revision ID"},
+		{Kind(0), "Kind(0)"},
+	}
+	for _, tt := range tests {
+		if got := tt.kind.String(); got != tt.want {
+			t.Errorf("Kind(%d).String() = %q, want %q", int(tt.kind), got, tt.want)
+		}
+	}
+}
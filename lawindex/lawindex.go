@@ -0,0 +1,304 @@
+package lawindex
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/agnivade/levenshtein"
+
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+	"go.ngs.io/jplaw2epub-web-api/text"
+)
+
+// Entry is a law in the index.
+type Entry struct {
+	LawID     string
+	LawNum    string
+	Title     string
+	TitleKana string
+	// Abbrev lists abbreviations of the title separated by 、 or commas, as
+	// e-Gov stores them.
+	Abbrev string
+	// LawType is the e-Gov law type, such as Act or Cabinetorder.
+	LawType string
+}
+
+// Index is an in-memory search index of law titles, title readings, law
+// numbers, and abbreviations for autocomplete. It is replaced as a whole by
+// each sync, and is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	entries  []indexedEntry
+	byID     map[string]int
+	syncedAt time.Time
+}
+
+// indexedEntry is an entry with its normalized search keys.
+type indexedEntry struct {
+	Entry
+	// titles are the title, reading, and abbreviations.
+	titles []string
+	kana   string
+	lawNum string
+}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{}
+}
+
+// Replace swaps the indexed laws for entries.
+func (ix *Index) Replace(entries []Entry) {
+	indexed := make([]indexedEntry, 0, len(entries))
+	byID := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		byID[entry.LawID] = len(indexed)
+		item := indexedEntry{Entry: entry, kana: text.Normalize(entry.TitleKana), lawNum: text.Normalize(entry.LawNum)}
+		for _, key := range append([]string{entry.Title, entry.TitleKana}, splitAbbrev(entry.Abbrev)...) {
+			if key = text.Normalize(key); key != "" {
+				item.titles = append(item.titles, key)
+			}
+		}
+		indexed = append(indexed, item)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.entries = indexed
+	ix.byID = byID
+	ix.syncedAt = time.Now()
+}
+
+// Get returns the law with a law ID.
+func (ix *Index) Get(lawID string) (Entry, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, ok := ix.byID[lawID]
+	if !ok {
+		return Entry{}, false
+	}
+	return ix.entries[i].Entry, true
+}
+
+// Len returns the number of indexed laws.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries)
+}
+
+// SyncedAt returns when the index was last replaced, or the zero time.
+func (ix *Index) SyncedAt() time.Time {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.syncedAt
+}
+
+// Titled returns the laws titled exactly title, ordered by law ID.
+func (ix *Index) Titled(title string) []Entry {
+	return ix.filter(func(entry *indexedEntry) bool {
+		return entry.Title == title
+	})
+}
+
+// TitledAfter returns the laws whose titles begin with title and go on,
+// such as 電波法施行規則 for 電波法, ordered by title and then law ID.
+func (ix *Index) TitledAfter(title string) []Entry {
+	if title == "" {
+		return nil
+	}
+	entries := ix.filter(func(entry *indexedEntry) bool {
+		return len(entry.Title) > len(title) && strings.HasPrefix(entry.Title, title)
+	})
+	sort.SliceStable(entries, func(i, k int) bool {
+		return entries[i].Title < entries[k].Title
+	})
+	return entries
+}
+
+// filter returns the entries for which keep returns true, ordered by law
+// ID.
+func (ix *Index) filter(keep func(*indexedEntry) bool) []Entry {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var result []Entry
+	for i := range ix.entries {
+		if entry := &ix.entries[i]; keep(entry) {
+			result = append(result, entry.Entry)
+		}
+	}
+	sort.Slice(result, func(i, k int) bool {
+		return result[i].LawID < result[k].LawID
+	})
+	return result
+}
+
+// match ranks an entry for a query; lower ranks come first.
+type match struct {
+	entry *indexedEntry
+	rank  int
+}
+
+// Ranks of the ways a query matches a key.
+const (
+	rankExact = iota
+	rankPrefix
+	rankContains
+	rankFuzzy
+)
+
+// Suggest returns up to limit laws whose title, reading, abbreviation, or
+// law number starts with or contains prefix, best matches first. Titles and
+// readings also match with a few typos when nothing better is found, so
+// 個人情報保護砲 still suggests 個人情報の保護に関する法律.
+func (ix *Index) Suggest(prefix string, limit int) []Entry {
+	query := text.Normalize(prefix)
+	if query == "" || limit <= 0 {
+		return nil
+	}
+	lawNum := query
+	if normalized, err := jpdate.NormalizeLawNum(prefix); err == nil {
+		lawNum = text.Normalize(normalized)
+	}
+	typos := allowedTypos(query)
+
+	return ix.search(limit, func(entry *indexedEntry) int {
+		rank := -1
+		if entry.lawNum != "" && strings.HasPrefix(entry.lawNum, lawNum) {
+			rank = rankPrefix
+			if entry.lawNum == lawNum {
+				rank = rankExact
+			}
+		}
+		return bestRank(rank, entry.titles, query, typos)
+	})
+}
+
+// SuggestTitles returns up to limit laws whose title, reading, or
+// abbreviation matches query, best matches first. The query may be partial
+// kanji, hiragana, katakana, or romaji; romaji such as kojinjouho is
+// matched against the readings.
+func (ix *Index) SuggestTitles(query string, limit int) []Entry {
+	normalized := text.Normalize(query)
+	if normalized == "" || limit <= 0 {
+		return nil
+	}
+	typos := allowedTypos(normalized)
+	kana, _, romaji := text.RomajiToHiragana(normalized)
+	romaji = romaji && kana != ""
+	kanaTypos := allowedTypos(kana)
+
+	return ix.search(limit, func(entry *indexedEntry) int {
+		rank := bestRank(-1, entry.titles, normalized, typos)
+		if romaji && entry.kana != "" {
+			rank = bestRank(rank, []string{entry.kana}, kana, kanaTypos)
+		}
+		return rank
+	})
+}
+
+// search returns up to limit entries ranked by rank, which returns -1 for
+// entries that do not match.
+func (ix *Index) search(limit int, rank func(*indexedEntry) int) []Entry {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var matches []match
+	for i := range ix.entries {
+		entry := &ix.entries[i]
+		if r := rank(entry); r >= 0 {
+			matches = append(matches, match{entry: entry, rank: r})
+		}
+	}
+
+	sort.Slice(matches, func(i, k int) bool {
+		a, b := matches[i], matches[k]
+		switch {
+		case a.rank != b.rank:
+			return a.rank < b.rank
+		case len(a.entry.Title) != len(b.entry.Title):
+			// Shorter titles are the more general laws.
+			return len(a.entry.Title) < len(b.entry.Title)
+		default:
+			return a.entry.LawID < b.entry.LawID
+		}
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	result := make([]Entry, len(matches))
+	for i, m := range matches {
+		result[i] = m.entry.Entry
+	}
+	return result
+}
+
+// bestRank returns the better of rank and the ranks of keys for query.
+func bestRank(rank int, keys []string, query string, typos int) int {
+	for _, key := range keys {
+		if r := matchRank(key, query, typos); r >= 0 && (rank < 0 || r < rank) {
+			rank = r
+		}
+	}
+	return rank
+}
+
+// matchRank returns how a normalized key matches a normalized query, or -1.
+func matchRank(key, query string, typos int) int {
+	switch {
+	case key == query:
+		return rankExact
+	case strings.HasPrefix(key, query):
+		return rankPrefix
+	case strings.Contains(key, query):
+		return rankContains
+	case typos > 0 && prefixDistance(key, query) <= typos:
+		return rankFuzzy
+	default:
+		return -1
+	}
+}
+
+// prefixDistance returns the edit distance between query and the start of
+// key, allowing the compared start to be one character longer or shorter.
+func prefixDistance(key, query string) int {
+	keyRunes := []rune(key)
+	n := utf8.RuneCountInString(query)
+	best := -1
+	for _, length := range []int{n - 1, n, n + 1} {
+		if length <= 0 || length > len(keyRunes) {
+			continue
+		}
+		d := levenshtein.ComputeDistance(string(keyRunes[:length]), query)
+		if best < 0 || d < best {
+			best = d
+		}
+	}
+	if best < 0 {
+		return n
+	}
+	return best
+}
+
+// allowedTypos returns the number of typos tolerated in a query; short
+// queries must match exactly.
+func allowedTypos(query string) int {
+	switch n := utf8.RuneCountInString(query); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func splitAbbrev(abbrev string) []string {
+	return strings.FieldsFunc(abbrev, func(r rune) bool {
+		return r == '、' || r == ',' || r == '，'
+	})
+}
@@ -0,0 +1,163 @@
+// Package lawref finds cross-references such as 第三条第二項 or
+// 民法（明治二十九年法律第八十九号）第九十条 in law text.
+package lawref
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Reference is a cross-reference in a text. Start and End are byte offsets
+// of Text in the searched text. LawNum is set for references to another
+// law and empty for references within the same law. Article is the target
+// article in the Num attribute format of law XML, such as 3_2 for 第三条の二,
+// and Paragraph and Item are the target paragraph and item numbers.
+type Reference struct {
+	Text      string
+	Start     int
+	End       int
+	LawNum    string
+	Article   string
+	Paragraph string
+	Item      string
+}
+
+// Find returns the references in text in order. References qualified by
+// 同法, 附則, 旧, or 新 are skipped because their target depends on context.
+func Find(text string) []Reference {
+	const number = `[〇一二三四五六七八九十百千]+`
+	lawNum := `(?:明治|大正|昭和|平成|令和)(?:元|` + number + `)年[^（）第、。]{1,12}第` + number + `号`
+	article := `第(` + number + `)条((?:の` + number + `)*)(?:第(` + number + `)項)?(?:第(` + number + `)号)?`
+	pattern := regexp.MustCompile(`（(` + lawNum + `)）(?:` + article + `)?|` + article)
+
+	var refs []Reference
+	for _, m := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		group := func(i int) string {
+			if m[2*i] < 0 {
+				return ""
+			}
+			return text[m[2*i]:m[2*i+1]]
+		}
+
+		ref := Reference{Text: text[m[0]:m[1]], Start: m[0], End: m[1]}
+		parts := []string{group(6), group(7), group(8), group(9)}
+		if group(1) != "" {
+			ref.LawNum = group(1)
+			parts = []string{group(2), group(3), group(4), group(5)}
+		} else if qualified(text[:m[0]]) {
+			continue
+		}
+
+		if parts[0] != "" {
+			num, ok := articleNum(parts[0], parts[1])
+			if !ok {
+				continue
+			}
+			ref.Article = num
+			ref.Paragraph = numberString(parts[2])
+			ref.Item = numberString(parts[3])
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// LawID derives the e-Gov law ID of an act or cabinet order from its law
+// number, such as 325AC0000000131 for 昭和二十五年法律第百三十一号. Other
+// kinds of laws have IDs that cannot be derived from the number alone.
+func LawID(lawNum string) (string, bool) {
+	const number = `[〇一二三四五六七八九十百千]+`
+	pattern := regexp.MustCompile(`^(明治|大正|昭和|平成|令和)(元|` + number + `)年(法律|政令)第(` + number + `)号$`)
+	m := pattern.FindStringSubmatch(lawNum)
+	if m == nil {
+		return "", false
+	}
+
+	eras := map[string]int{"明治": 1, "大正": 2, "昭和": 3, "平成": 4, "令和": 5}
+	types := map[string]string{"法律": "AC", "政令": "CO"}
+	year := 1
+	if m[2] != "元" {
+		n, ok := kanjiNumber(m[2])
+		if !ok || n > 99 {
+			return "", false
+		}
+		year = n
+	}
+	num, ok := kanjiNumber(m[4])
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%d%02d%s%010d", eras[m[1]], year, types[m[3]], num), true
+}
+
+// qualified reports whether a reference following prefix points into a law
+// or provision named earlier rather than the current law.
+func qualified(prefix string) bool {
+	for _, qualifier := range []string{"同法", "同令", "附則", "旧", "新"} {
+		if strings.HasSuffix(prefix, qualifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// articleNum converts 三 and の二の三 to the article Num 3_2_3.
+func articleNum(main, branches string) (string, bool) {
+	n, ok := kanjiNumber(main)
+	if !ok {
+		return "", false
+	}
+	num := strconv.Itoa(n)
+	for _, branch := range strings.Split(branches, "の")[1:] {
+		b, ok := kanjiNumber(branch)
+		if !ok {
+			return "", false
+		}
+		num += "_" + strconv.Itoa(b)
+	}
+	return num, true
+}
+
+// numberString converts kanji numerals to decimal digits, or returns an
+// empty string for an empty or invalid number.
+func numberString(s string) string {
+	if n, ok := kanjiNumber(s); ok {
+		return strconv.Itoa(n)
+	}
+	return ""
+}
+
+// kanjiNumber reads kanji numerals up to 9999 such as 百三十一 or 二〇.
+func kanjiNumber(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	digits := map[rune]int{'〇': 0, '一': 1, '二': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	units := map[rune]int{'十': 10, '百': 100, '千': 1000}
+
+	total, current := 0, -1
+	for _, r := range s {
+		if d, ok := digits[r]; ok {
+			if current < 0 {
+				current = 0
+			}
+			current = current*10 + d
+			continue
+		}
+		unit, ok := units[r]
+		if !ok {
+			return 0, false
+		}
+		if current < 0 {
+			current = 1
+		}
+		total += current * unit
+		current = -1
+	}
+	if current > 0 {
+		total += current
+	}
+	return total, total > 0
+}
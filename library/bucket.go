@@ -0,0 +1,96 @@
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// maxUpdateAttempts bounds the retries of an update that raced with
+// another one.
+const maxUpdateAttempts = 5
+
+// BucketStore keeps each library in a `libraries/{userId}.json` object below
+// the storage prefix of its tenant. Updates are written only if the object
+// is unchanged since it was read, and retried otherwise.
+type BucketStore struct {
+	bucket objects.Bucket
+	prefix string
+}
+
+func NewBucketStore(bucket objects.Bucket, prefix string) *BucketStore {
+	return &BucketStore{bucket: bucket, prefix: prefix}
+}
+
+func (s *BucketStore) path(tenantID, userID string) string {
+	return tenant.Prefix(s.prefix, tenantID) + "/libraries/" + userID + ".json"
+}
+
+func (s *BucketStore) Get(ctx context.Context, tenantID, userID string) (*Library, error) {
+	library, _, err := s.read(ctx, tenantID, userID)
+	return library, err
+}
+
+func (s *BucketStore) Update(ctx context.Context, tenantID, userID string, fn func(*Library) error) (*Library, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		library, generation, err := s.read(ctx, tenantID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := fn(library); err != nil {
+			return nil, err
+		}
+		library.UpdatedAt = time.Now().UTC()
+
+		conditions := objects.Conditions{DoesNotExist: true}
+		if generation != 0 {
+			conditions = objects.Conditions{GenerationMatch: generation}
+		}
+		err = s.write(ctx, s.path(tenantID, userID), conditions, library)
+		if errors.Is(err, objects.ErrPreconditionFailed) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to write library of %s: %v", userID, err)
+		}
+		return library, nil
+	}
+	return nil, ErrConflict
+}
+
+// read returns a library and the generation of its object, which is zero
+// when there is none.
+func (s *BucketStore) read(ctx context.Context, tenantID, userID string) (*Library, int64, error) {
+	reader, err := s.bucket.NewReader(ctx, s.path(tenantID, userID))
+	if errors.Is(err, objects.ErrNotExist) {
+		return emptyLibrary(tenantID, userID), 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read library of %s: %v", userID, err)
+	}
+	defer reader.Close()
+
+	library := emptyLibrary(tenantID, userID)
+	if err := json.NewDecoder(reader).Decode(library); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode library of %s: %v", userID, err)
+	}
+	return library, reader.Attrs.Generation, nil
+}
+
+func (s *BucketStore) write(ctx context.Context, path string, conditions objects.Conditions, library *Library) error {
+	w := s.bucket.NewWriter(ctx, path, objects.WriteOptions{ContentType: "application/json", If: conditions})
+	if err := json.NewEncoder(w).Encode(library); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (s *BucketStore) Close() error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// StoreConfig selects and configures a Store backend. Libraries use the
+// same backend as the job metadata store.
+type StoreConfig struct {
+	// Backend is "bucket", "firestore", or "memory".
+	Backend string
+	// Bucket and Prefix locate library objects for the bucket store.
+	Bucket objects.Bucket
+	Prefix string
+	// ProjectID and Collection locate documents for the firestore store.
+	ProjectID  string
+	Collection string
+}
+
+// NewStore creates the store selected by cfg.Backend.
+func NewStore(ctx context.Context, cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "bucket":
+		if cfg.Bucket == nil {
+			return nil, errors.New("the bucket library store needs a bucket")
+		}
+		return NewBucketStore(cfg.Bucket, cfg.Prefix), nil
+	case "firestore":
+		store, err := NewFirestoreStore(ctx, cfg.ProjectID, cfg.Collection)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown library store %q (expected bucket, firestore, or memory)", cfg.Backend)
+	}
+}
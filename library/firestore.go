@@ -0,0 +1,91 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// FirestoreStore keeps one document per user in a Firestore collection.
+// Updates run in transactions, so edits from several devices are not
+// lost.
+type FirestoreStore struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreStore(ctx context.Context, projectID, collection string) (*FirestoreStore, error) {
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firestore client: %v", err)
+	}
+	return &FirestoreStore{client: client, collection: collection}, nil
+}
+
+// doc returns the document of a user's library, named by the tenant-scoped
+// user ID with slashes, which Firestore reads as path separators, replaced.
+func (s *FirestoreStore) doc(tenantID, userID string) *firestore.DocumentRef {
+	return s.client.Collection(s.collection).Doc(strings.ReplaceAll(tenant.ScopedID(tenantID, userID), "/", ":"))
+}
+
+func (s *FirestoreStore) Get(ctx context.Context, tenantID, userID string) (*Library, error) {
+	snap, err := s.doc(tenantID, userID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return emptyLibrary(tenantID, userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library of %s: %v", userID, err)
+	}
+	return decode(snap, tenantID, userID)
+}
+
+func (s *FirestoreStore) Update(ctx context.Context, tenantID, userID string, fn func(*Library) error) (*Library, error) {
+	ref := s.doc(tenantID, userID)
+	var result *Library
+	var fnErr error
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		library := emptyLibrary(tenantID, userID)
+		snap, err := tx.Get(ref)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			if library, err = decode(snap, tenantID, userID); err != nil {
+				return err
+			}
+		}
+		if fnErr = fn(library); fnErr != nil {
+			return fnErr
+		}
+		library.UpdatedAt = time.Now().UTC()
+		result = library
+		return tx.Set(ref, library)
+	})
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update library of %s: %v", userID, err)
+	}
+	return result, nil
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
+
+func decode(snap *firestore.DocumentSnapshot, tenantID, userID string) (*Library, error) {
+	library := emptyLibrary(tenantID, userID)
+	if err := snap.DataTo(library); err != nil {
+		return nil, fmt.Errorf("failed to decode library of %s: %v", userID, err)
+	}
+	return library, nil
+}
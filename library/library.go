@@ -0,0 +1,108 @@
+package library
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrConflict is returned by Store.Update when the library kept changing
+// while it was updated.
+var ErrConflict = errors.New("library was modified concurrently")
+
+// Library holds the bookmarks, saved searches, compilations, and EPUB
+// history of a user, which the user's devices sync through the API.
+type Library struct {
+	Tenant       string        `json:"tenant" firestore:"tenant"`
+	User         string        `json:"user" firestore:"user"`
+	Bookmarks    []Bookmark    `json:"bookmarks" firestore:"bookmarks"`
+	Searches     []SavedSearch `json:"searches" firestore:"searches"`
+	Compilations []Compilation `json:"compilations" firestore:"compilations"`
+	History      []Generation  `json:"history" firestore:"history"`
+	UpdatedAt    time.Time     `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// Bookmark is a law the user marked, newest first in a library.
+type Bookmark struct {
+	LawID     string    `json:"lawId" firestore:"lawId"`
+	Title     string    `json:"title,omitempty" firestore:"title"`
+	Note      string    `json:"note,omitempty" firestore:"note"`
+	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// SavedSearch is a named set of law search filters. Law types and
+// categories are the names of the GraphQL enums.
+type SavedSearch struct {
+	ID         string    `json:"id" firestore:"id"`
+	Name       string    `json:"name" firestore:"name"`
+	Keyword    string    `json:"keyword,omitempty" firestore:"keyword"`
+	LawTitle   string    `json:"lawTitle,omitempty" firestore:"lawTitle"`
+	LawNum     string    `json:"lawNum,omitempty" firestore:"lawNum"`
+	LawTypes   []string  `json:"lawTypes,omitempty" firestore:"lawTypes"`
+	Categories []string  `json:"categories,omitempty" firestore:"categories"`
+	Sort       string    `json:"sort,omitempty" firestore:"sort"`
+	Order      string    `json:"order,omitempty" firestore:"order"`
+	CreatedAt  time.Time `json:"createdAt" firestore:"createdAt"`
+}
+
+// Compilation is a titled book of laws the user put together, newest first
+// in a library.
+type Compilation struct {
+	ID    string `json:"id" firestore:"id"`
+	Title string `json:"title" firestore:"title"`
+	// IDs are the law IDs, law numbers, or revision IDs of the laws in the
+	// order of the book.
+	IDs []string `json:"ids" firestore:"ids"`
+	// BookID is the ID of the EPUB last generated from the compilation.
+	BookID    string    `json:"bookId,omitempty" firestore:"bookId"`
+	CreatedAt time.Time `json:"createdAt" firestore:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// Generation is an EPUB the user requested, newest first in a library.
+type Generation struct {
+	// ID is the law ID, law number, or revision ID as requested.
+	ID          string   `json:"id" firestore:"id"`
+	Articles    []string `json:"articles,omitempty" firestore:"articles"`
+	DiffAgainst string   `json:"diffAgainst,omitempty" firestore:"diffAgainst"`
+	Preset      string   `json:"preset,omitempty" firestore:"preset"`
+	// Vertical is the writing mode the request passed, or nil when it
+	// passed none.
+	Vertical *bool `json:"vertical,omitempty" firestore:"vertical"`
+	// Cover is the name of a GraphQL CoverStyle, or empty when the request
+	// did not pass one.
+	Cover string `json:"cover,omitempty" firestore:"cover"`
+	// Font is the name of the font the request embedded.
+	Font        string    `json:"font,omitempty" firestore:"font"`
+	StripFonts  bool      `json:"stripFonts,omitempty" firestore:"stripFonts"`
+	RequestedAt time.Time `json:"requestedAt" firestore:"requestedAt"`
+}
+
+// Same reports whether g and other requested the same EPUB.
+func (g Generation) Same(other Generation) bool {
+	return g.ID == other.ID && slices.Equal(g.Articles, other.Articles) && g.DiffAgainst == other.DiffAgainst && g.Preset == other.Preset && equalBool(g.Vertical, other.Vertical) && g.Cover == other.Cover && g.Font == other.Font && g.StripFonts == other.StripFonts
+}
+
+// equalBool reports whether a and b are both nil or point to equal values.
+func equalBool(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Store persists libraries.
+type Store interface {
+	// Get returns the library of a user, which is empty when the user has
+	// none yet.
+	Get(ctx context.Context, tenantID, userID string) (*Library, error)
+	// Update applies fn to the library of a user and saves it, unless fn
+	// fails. Concurrent updates of a library are applied one after the
+	// other.
+	Update(ctx context.Context, tenantID, userID string, fn func(*Library) error) (*Library, error)
+}
+
+func emptyLibrary(tenantID, userID string) *Library {
+	return &Library{Tenant: tenantID, User: userID, Bookmarks: []Bookmark{}, Searches: []SavedSearch{}, Compilations: []Compilation{}, History: []Generation{}}
+}
@@ -0,0 +1,60 @@
+package library
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// MemoryStore keeps libraries in process memory. It is intended for local
+// development; libraries are lost on restart and not shared between
+// instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	libraries map[string]*Library
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{libraries: make(map[string]*Library)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, tenantID, userID string) (*Library, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	library, ok := s.libraries[tenant.ScopedID(tenantID, userID)]
+	if !ok {
+		return emptyLibrary(tenantID, userID), nil
+	}
+	return clone(library), nil
+}
+
+func (s *MemoryStore) Update(_ context.Context, tenantID, userID string, fn func(*Library) error) (*Library, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := tenant.ScopedID(tenantID, userID)
+	library := emptyLibrary(tenantID, userID)
+	if stored, ok := s.libraries[key]; ok {
+		library = clone(stored)
+	}
+	if err := fn(library); err != nil {
+		return nil, err
+	}
+	library.UpdatedAt = time.Now().UTC()
+	s.libraries[key] = clone(library)
+	return library, nil
+}
+
+// clone copies a library so that callers cannot change the stored one.
+func clone(library *Library) *Library {
+	copied := *library
+	copied.Bookmarks = slices.Clone(library.Bookmarks)
+	copied.Searches = slices.Clone(library.Searches)
+	copied.Compilations = slices.Clone(library.Compilations)
+	copied.History = slices.Clone(library.History)
+	return &copied
+}
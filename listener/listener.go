@@ -0,0 +1,116 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// firstSystemdFD is the first file descriptor passed by systemd socket
+// activation.
+const firstSystemdFD = 3
+
+// Listen opens the listener of the HTTP server. address is unix:/path.sock
+// for a Unix domain socket, systemd for the first socket passed by systemd
+// socket activation, or host:port for TCP. An empty address uses socket
+// activation when systemd passed sockets, and otherwise the TCP port; port
+// 0 picks an available port, which Port reports.
+func Listen(address, port string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(address, "unix:"):
+		return listenUnix(strings.TrimPrefix(address, "unix:"))
+	case address == "systemd":
+		l, err := systemdListener()
+		if err == nil && l == nil {
+			err = errors.New("no socket was passed by systemd (LISTEN_FDS is not set)")
+		}
+		return l, err
+	case address != "":
+		return listenTCP(address)
+	}
+
+	l, err := systemdListener()
+	if err != nil || l != nil {
+		return l, err
+	}
+	if port == "" {
+		return nil, errors.New("no port or listen address is configured")
+	}
+	return listenTCP(":" + port)
+}
+
+// Port returns the TCP port of a listener, or 0 for a Unix domain socket.
+func Port(l net.Listener) int {
+	if addr, ok := l.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
+
+// Describe names the address of a listener for logs.
+func Describe(l net.Listener) string {
+	addr := l.Addr()
+	if addr.Network() == "unix" {
+		return "unix:" + addr.String()
+	}
+	return addr.String()
+}
+
+func listenTCP(address string) (net.Listener, error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", address, err)
+	}
+	return l, nil
+}
+
+// listenUnix listens on a Unix domain socket, replacing a socket left
+// behind by an earlier run. The socket is removed when the listener is
+// closed.
+func listenUnix(path string) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("unix socket path must not be empty")
+	}
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %v", path, err)
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %v", path, err)
+	}
+	return l, nil
+}
+
+// systemdListener returns the first socket passed by systemd, or nil when
+// none was passed to this process. The activation variables are cleared so
+// that child processes do not inherit them.
+func systemdListener() (net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(firstSystemdFD, "systemd socket")
+	defer file.Close()
+	l, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use systemd socket: %v", err)
+	}
+	return l, nil
+}
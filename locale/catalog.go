@@ -0,0 +1,72 @@
+package locale
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+//go:embed catalog.json
+var catalogJSON []byte
+
+// Message is the display names of an enum value.
+type Message struct {
+	Value string `json:"value"`
+	Ja    string `json:"ja"`
+	En    string `json:"en"`
+	// Description explains the value by locale, for the values that have
+	// one.
+	Description map[Locale]string `json:"description,omitempty"`
+}
+
+// Text returns the display name in l, in Japanese when there is no
+// translation.
+func (m Message) Text(l Locale) string {
+	if l == English && m.En != "" {
+		return m.En
+	}
+	return m.Ja
+}
+
+// DescriptionText returns the description in l, in Japanese when there is
+// no translation.
+func (m Message) DescriptionText(l Locale) string {
+	if text := m.Description[l]; text != "" {
+		return text
+	}
+	return m.Description[Japanese]
+}
+
+// catalog holds the messages of each enum in the order of its values.
+var catalog = func() map[string][]Message {
+	var c map[string][]Message
+	if err := json.Unmarshal(catalogJSON, &c); err != nil {
+		panic(fmt.Sprintf("invalid message catalog: %v", err))
+	}
+	return c
+}()
+
+// Messages returns the messages of an enum, such as LawType, in the order
+// of its values, or nil for an enum the catalog does not hold.
+func Messages(enum string) []Message {
+	return catalog[enum]
+}
+
+// Lookup returns the message of an enum value.
+func Lookup(enum, value string) (Message, bool) {
+	for _, m := range catalog[enum] {
+		if m.Value == value {
+			return m, true
+		}
+	}
+	return Message{}, false
+}
+
+// DisplayName returns the display name of an enum value in l, or the
+// value itself when the catalog does not hold it.
+func DisplayName(l Locale, enum, value string) string {
+	if m, ok := Lookup(enum, value); ok {
+		return m.Text(l)
+	}
+	return value
+}
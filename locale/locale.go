@@ -0,0 +1,64 @@
+// Package locale picks the language of display names for a request and
+// looks them up in an embedded message catalog.
+package locale
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Locale is a language that display names are available in.
+type Locale string
+
+const (
+	Japanese Locale = "ja"
+	English  Locale = "en"
+)
+
+// Default is the locale of requests that ask for none this package
+// supports.
+const Default = Japanese
+
+type contextKey struct{}
+
+// WithContext returns a context carrying the locale of a request.
+func WithContext(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the locale of a request, or Default when none was
+// set.
+func FromContext(ctx context.Context) Locale {
+	if l, ok := ctx.Value(contextKey{}).(Locale); ok {
+		return l
+	}
+	return Default
+}
+
+// Negotiate picks the supported locale an Accept-Language header prefers,
+// such as English for "en-US,en;q=0.9,ja;q=0.8". Region subtags are
+// ignored, and ties go to the language listed first. It returns Default
+// when the header names no supported language.
+func Negotiate(acceptLanguage string) Locale {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		switch l := Locale(primary); l {
+		case Japanese, English:
+			if q > bestQ {
+				best, bestQ = l, q
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+)
+
+// Message is a plain-text email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures a mailer.
+type Config struct {
+	// Provider is smtp or sendgrid; empty disables email.
+	Provider string
+	// From is the sender address, such as "jplaw2epub <noreply@example.com>".
+	From string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	SendGridAPIKey string
+}
+
+// New returns the mailer of cfg.Provider. It returns nil when the provider
+// is empty.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Provider {
+	case "smtp":
+		return &SMTP{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		}, nil
+	case "sendgrid":
+		return NewSendGrid(cfg.SendGridAPIKey, cfg.From), nil
+	case "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q (expected smtp or sendgrid)", cfg.Provider)
+	}
+}
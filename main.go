@@ -1,62 +1,174 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"log"
+	"net"
 	"net/http"
+	"os"
 	"time"
 
-	"github.com/99designs/gqlgen/graphql/handler"
-	"github.com/99designs/gqlgen/graphql/playground"
+	"cloud.google.com/go/storage"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
+	"go.ngs.io/jplaw2epub-web-api/config"
 	"go.ngs.io/jplaw2epub-web-api/graphql"
-	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/grpcserver"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/listener"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/server"
+	"go.ngs.io/jplaw2epub-web-api/upstream"
+	"go.ngs.io/jplaw2epub-web-api/upstream/mock"
 )
 
 func main() {
-	portFlag := flag.String("port", "", "Port to listen on (default: find available port)")
-	corsOriginsFlag := flag.String("cors-origins", "", "Comma-separated list of allowed CORS origins (e.g., 'https://example.com,https://app.example.com')")
-	disableAccessLog := flag.Bool("disable-access-log", false, "Disable Apache format access logging")
-	flag.Parse()
-
-	port := handlers.DeterminePort(*portFlag)
-	allowedOrigins := handlers.ParseAllowedOrigins(*corsOriginsFlag)
-
-	// Create a new mux for better control over middleware.
-	mux := http.NewServeMux()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
 
-	// Register handlers with CORS middleware.
-	mux.HandleFunc("/health", handlers.WithCORS(handlers.HealthHandler, allowedOrigins))
+	// The listener is opened first, so that a busy port or socket fails
+	// before any background work starts. With -port 0 the chosen port is
+	// logged and reported by /health.
+	httpListener, err := listener.Listen(cfg.Listen, cfg.Port)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	// The EPUB bucket's client is shared by the resolver, the stores, the
+	// attachment cache, and downloads.
+	var deps graphql.Dependencies
+	if cfg.BucketName != "" {
+		storageClient, err := storage.NewClient(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to create storage client: %v", err)
+		}
+		deps.Storage = objects.NewClient(storageClient)
+	}
 
-	// GraphQL handlers.
-	srv := handler.NewDefaultServer(graphql.NewExecutableSchema(graphql.Config{Resolvers: graphql.NewResolver()}))
-	mux.Handle("/graphql", handlers.WithCORSHandler(srv, allowedOrigins))
-	mux.Handle("/graphiql", playground.Handler("GraphQL playground", "/graphql"))
+	// The e-Gov API, or a mock of it for development without network
+	// access.
+	if cfg.Upstream.Mode == "mock" {
+		mockServer, err := mock.NewServer()
+		if err != nil {
+			log.Fatalf("Failed to start mock e-Gov API: %v", err)
+		}
+		cfg.Upstream.BaseURL = mockServer.URL + "/api/2"
+		log.Printf("Using the mock e-Gov API at %s", cfg.Upstream.BaseURL)
+	}
+	upstreamURL := cfg.Upstream.BaseURL
+	switch cfg.Upstream.Mode {
+	case "record":
+		recorder, err := upstream.Record(http.DefaultTransport, upstreamURL, cfg.Upstream.Fixtures)
+		if err != nil {
+			log.Fatalf("Failed to record e-Gov API responses: %v", err)
+		}
+		http.DefaultTransport = recorder
+		log.Printf("Recording e-Gov API responses in %s", cfg.Upstream.Fixtures)
+	case "replay":
+		replayer, err := upstream.Replay(http.DefaultTransport, upstreamURL, cfg.Upstream.Fixtures)
+		if err != nil {
+			log.Fatalf("Failed to replay e-Gov API responses: %v", err)
+		}
+		http.DefaultTransport = replayer
+		log.Printf("Replaying e-Gov API responses from %s", cfg.Upstream.Fixtures)
+	}
+	if upstreamURL != lawdata.DefaultBaseURL {
+		redirect, err := upstream.Redirect(http.DefaultTransport, upstreamURL)
+		if err != nil {
+			log.Fatalf("Failed to configure the e-Gov API: %v", err)
+		}
+		http.DefaultTransport = redirect
+	}
 
-	// Wrap the entire mux with Apache logger middleware unless disabled.
-	var finalHandler http.Handler = mux
-	if !*disableAccessLog {
-		finalHandler = handlers.ApacheLoggerWithDuration(mux)
+	api, err := server.New(cfg, deps, listener.Port(httpListener))
+	if err != nil {
+		log.Fatalf("Failed to initialize the API: %v", err)
 	}
+	// Calls to the e-Gov API, generator executions, and the canary
+	// comparison are published on the metrics endpoint.
+	api.Tracker.Publish("upstream")
+	api.Resolver.PublishDispatcher("generator")
+	api.Resolver.PublishCanary("canary")
+	api.Start(context.Background())
 
-	server := &http.Server{
-		Addr:         ":" + port,
-		Handler:      finalHandler,
+	httpServer := &http.Server{
+		Handler:      api.Handler,
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	log.Printf("Server starting on port %s", port)
-	if len(allowedOrigins) > 0 {
-		log.Printf("CORS enabled for origins: %v", allowedOrigins)
+	// gRPC API on a second port, sharing the GraphQL resolver.
+	if cfg.GRPCPort != "" {
+		grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			log.Fatalf("Failed to listen on gRPC port %s: %v", cfg.GRPCPort, err)
+		}
+		grpcServer := grpcserver.NewGRPCServer(api.Resolver, api.TrustedProxies)
+		go func() {
+			log.Printf("gRPC server starting on port %s", cfg.GRPCPort)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				log.Fatalf("gRPC server failed: %v", err)
+			}
+		}()
+	}
+
+	log.Printf("Server starting on %s", listener.Describe(httpListener))
+	if len(cfg.CORSOrigins) > 0 {
+		log.Printf("CORS enabled for origins: %v", cfg.CORSOrigins)
 	} else {
 		log.Printf("CORS disabled (no origins specified)")
 	}
-	if !*disableAccessLog {
-		log.Printf("Apache format access logging enabled")
+	if !cfg.DisableAccessLog {
+		output := cfg.AccessLog.Output
+		if output == "" {
+			output = "default"
+		}
+		log.Printf("Access logging enabled (format: %s, output: %s)", cfg.AccessLog.Format, output)
+	}
+	if api.Limiter.Enabled() {
+		log.Printf("Request quotas enabled (daily: %d, monthly: %d, store: %s)", cfg.Quota.Daily, cfg.Quota.Monthly, cfg.Quota.Store)
 	}
-	if err := server.ListenAndServe(); err != nil {
+	if api.Tenants != nil {
+		log.Printf("Multi-tenant mode enabled (store: %s)", cfg.Tenants.Store)
+	}
+	if err := serve(httpServer, httpListener, cfg.TLS); err != nil {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
+
+// serve serves HTTPS on l with the configured certificate or with
+// certificates from Let's Encrypt, or plain HTTP, optionally accepting
+// HTTP/2 in cleartext.
+func serve(server *http.Server, l net.Listener, cfg config.TLS) error {
+	switch {
+	case cfg.CertFile != "":
+		log.Printf("HTTPS enabled with certificate %s", cfg.CertFile)
+		return server.ServeTLS(l, cfg.CertFile, cfg.KeyFile)
+	case len(cfg.AutocertDomains) > 0:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.AutocertDomains...),
+			Cache:      autocert.DirCache(cfg.AutocertCacheDir),
+			Email:      cfg.AutocertEmail,
+		}
+		// The manager answers TLS-ALPN-01 challenges on this listener.
+		server.TLSConfig = manager.TLSConfig()
+		log.Printf("HTTPS enabled with Let's Encrypt certificates for %v", cfg.AutocertDomains)
+		return server.ServeTLS(l, "", "")
+	default:
+		if cfg.H2C {
+			server.Handler = h2c.NewHandler(server.Handler, &http2.Server{IdleTimeout: server.IdleTimeout})
+			log.Printf("Cleartext HTTP/2 (h2c) enabled")
+		}
+		return server.Serve(l)
+	}
+}
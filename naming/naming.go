@@ -0,0 +1,317 @@
+// Package naming renders the filenames of downloaded documents from a
+// template such as {lawTitle}_{era}{year}_{revisionId}.epub, so that saved
+// files are recognizable instead of being named by their ID.
+package naming
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/lawid"
+)
+
+// DefaultTemplate names a document by its ID.
+const DefaultTemplate = "{id}.epub"
+
+// maxFilenameBytes keeps filenames below the 255-byte limit of common file
+// systems, with room for a browser's " (1)" suffix.
+const maxFilenameBytes = 200
+
+const extension = ".epub"
+
+// Fields are the values a template refers to. Empty fields are unknown.
+type Fields struct {
+	// ID is the document ID, such as an EPUB ID or the name of a converted
+	// EPUB.
+	ID         string
+	LawID      string
+	RevisionID string
+	LawTitle   string
+	LawNum     string
+	// Era and Year are the era of promulgation, such as 昭和, and the year
+	// in it. Without them they are read from LawID.
+	Era  string
+	Year string
+}
+
+// placeholders are the fields a template may refer to.
+var placeholders = map[string]func(Fields) string{
+	"id":         func(f Fields) string { return f.ID },
+	"lawId":      func(f Fields) string { return f.LawID },
+	"revisionId": func(f Fields) string { return f.RevisionID },
+	"lawTitle":   func(f Fields) string { return f.LawTitle },
+	"lawNum":     func(f Fields) string { return f.LawNum },
+	"era":        func(f Fields) string { era, _ := f.promulgation(); return era },
+	"year":       func(f Fields) string { _, year := f.promulgation(); return year },
+}
+
+// metadataKeys are the object metadata keys of the fields other than ID.
+var metadataKeys = []struct {
+	key   string
+	field func(*Fields) *string
+}{
+	{"lawId", func(f *Fields) *string { return &f.LawID }},
+	{"revisionId", func(f *Fields) *string { return &f.RevisionID }},
+	{"lawTitle", func(f *Fields) *string { return &f.LawTitle }},
+	{"lawNum", func(f *Fields) *string { return &f.LawNum }},
+	{"era", func(f *Fields) *string { return &f.Era }},
+	{"year", func(f *Fields) *string { return &f.Year }},
+}
+
+// FromLaw returns the fields of a document converted from law.
+func FromLaw(id string, law *lawdata.Law) Fields {
+	return Fields{
+		ID:         id,
+		LawID:      law.LawID,
+		RevisionID: law.RevisionID,
+		LawTitle:   law.LawTitle,
+		LawNum:     law.LawNum,
+		Era:        eraNames[law.Era],
+		Year:       strings.TrimLeft(law.Year, "0"),
+	}
+}
+
+// FromRevision returns the fields of a document generated from a revision
+// ID with a known title.
+func FromRevision(id, revisionID, title string) Fields {
+	fields := Fields{ID: id, RevisionID: revisionID, LawTitle: title}
+	if parsed, err := lawid.Parse(revisionID); err == nil {
+		fields.LawID = parsed.LawID
+	}
+	return fields
+}
+
+// FromMetadata returns the fields of a stored document from the object
+// metadata written by Metadata.
+func FromMetadata(id string, metadata map[string]string) Fields {
+	fields := Fields{ID: id}
+	for _, k := range metadataKeys {
+		*k.field(&fields) = metadata[k.key]
+	}
+	return fields
+}
+
+// Metadata returns the fields other than ID as object metadata, so that a
+// stored document is named by the template in use when it is downloaded.
+func (f Fields) Metadata() map[string]string {
+	metadata := make(map[string]string)
+	for _, k := range metadataKeys {
+		if v := *k.field(&f); v != "" {
+			metadata[k.key] = v
+		}
+	}
+	return metadata
+}
+
+// eraNames maps the Era attribute of law XML to era names.
+var eraNames = map[string]string{
+	"Meiji":  "明治",
+	"Taisho": "大正",
+	"Showa":  "昭和",
+	"Heisei": "平成",
+	"Reiwa":  "令和",
+}
+
+// promulgation returns Era and Year, or the era and year encoded in the
+// first three digits of the law ID.
+func (f Fields) promulgation() (string, string) {
+	if f.Era != "" && f.Year != "" {
+		return f.Era, f.Year
+	}
+	if len(f.LawID) < 3 {
+		return "", ""
+	}
+	var era string
+	switch f.LawID[0] {
+	case '1':
+		era = "明治"
+	case '2':
+		era = "大正"
+	case '3':
+		era = "昭和"
+	case '4':
+		era = "平成"
+	case '5':
+		era = "令和"
+	default:
+		return "", ""
+	}
+	year := strings.TrimLeft(f.LawID[1:3], "0")
+	if year == "" || strings.Trim(year, "0123456789") != "" {
+		return "", ""
+	}
+	return era, year
+}
+
+// Template is a parsed filename template.
+type Template struct {
+	source string
+	// parts alternate literal text and placeholder names, starting with
+	// literal text.
+	parts []string
+}
+
+// Parse reads a template of literal text and {placeholder}s: {id},
+// {lawId}, {revisionId}, {lawTitle}, {lawNum}, {era}, and {year}. An
+// empty template is DefaultTemplate, and .epub is appended when the
+// template does not end with it.
+func Parse(source string) (*Template, error) {
+	if source == "" {
+		source = DefaultTemplate
+	}
+	if !strings.HasSuffix(source, extension) {
+		source += extension
+	}
+
+	t := &Template{source: source}
+	rest := source
+	for {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			t.parts = append(t.parts, rest)
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("invalid filename template %q: unclosed {", source)
+		}
+		name := rest[open+1 : open+end]
+		if _, ok := placeholders[name]; !ok {
+			return nil, fmt.Errorf("invalid filename template %q: unknown placeholder {%s}", source, name)
+		}
+		t.parts = append(t.parts, rest[:open], name)
+		rest = rest[open+end+1:]
+	}
+	for i := 0; i < len(t.parts); i += 2 {
+		for _, r := range t.parts[i] {
+			if r == '}' || reserved(r) {
+				return nil, fmt.Errorf("invalid filename template %q: %q is not allowed in a filename", source, r)
+			}
+		}
+	}
+	return t, nil
+}
+
+// String returns the template with its extension.
+func (t *Template) String() string {
+	return t.source
+}
+
+// Filename renders the name of a document. Values are sanitized for file
+// systems, and the name is shortened to maxFilenameBytes. It falls back to
+// DefaultTemplate when a placeholder has no value, so that documents
+// stored without naming metadata keep being named by their ID.
+func (t *Template) Filename(f Fields) string {
+	name, ok := t.render(f)
+	if !ok {
+		name = sanitize(f.ID) + extension
+	}
+	return truncate(name)
+}
+
+func (t *Template) render(f Fields) (string, bool) {
+	var b strings.Builder
+	for i, part := range t.parts {
+		if i%2 == 0 {
+			b.WriteString(part)
+			continue
+		}
+		value := sanitize(placeholders[part](f))
+		if value == "" {
+			return "", false
+		}
+		b.WriteString(value)
+	}
+	return b.String(), true
+}
+
+// ContentDisposition returns an attachment Content-Disposition naming the
+// document. A filename that is not ASCII is sent in RFC 5987 encoding, with
+// the document ID as the fallback for clients that do not read it.
+func (t *Template) ContentDisposition(f Fields) string {
+	filename := t.Filename(f)
+	if asciiFallback(filename) == filename {
+		return fmt.Sprintf(`attachment; filename="%s"`, filename)
+	}
+	fallback := asciiFallback(truncate(sanitize(f.ID) + extension))
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, fallback, encodeExtValue(filename))
+}
+
+// encodeExtValue percent-encodes the bytes of s that are not attr-chars of
+// RFC 5987.
+func encodeExtValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < utf8.RuneSelf && (unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || strings.IndexByte("!#$&+-.^_`|~", c) >= 0) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// sanitize makes a value safe in a filename on common file systems: path
+// separators, characters reserved on Windows, and control characters become
+// underscores, as do runs of white space including the ideographic space.
+// Surrounding white space, leading dots, which hide files, and trailing
+// dots are removed.
+func sanitize(value string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range norm.NFC.String(strings.TrimSpace(value)) {
+		if reserved(r) {
+			if !underscore {
+				b.WriteByte('_')
+			}
+			underscore = true
+			continue
+		}
+		underscore = false
+		b.WriteRune(r)
+	}
+	return strings.TrimRight(strings.TrimLeft(b.String(), "."), ".")
+}
+
+// reserved reports whether r is replaced by sanitize.
+func reserved(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`/\<>:"|?*`, r) || r == utf8.RuneError
+}
+
+// asciiFallback replaces the non-ASCII runes of a filename.
+func asciiFallback(filename string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range filename {
+		if r < utf8.RuneSelf {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore {
+			b.WriteByte('_')
+		}
+		underscore = true
+	}
+	return b.String()
+}
+
+// truncate shortens the part of a filename before .epub to fit
+// maxFilenameBytes without splitting a character.
+func truncate(name string) string {
+	if len(name) <= maxFilenameBytes {
+		return name
+	}
+	base := strings.TrimSuffix(name, extension)
+	limit := maxFilenameBytes - len(extension)
+	for limit > 0 && !utf8.RuneStart(base[limit]) {
+		limit--
+	}
+	return base[:limit] + extension
+}
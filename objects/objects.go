@@ -0,0 +1,285 @@
+package objects
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+var (
+	// ErrNotExist is returned for an object that does not exist.
+	ErrNotExist = errors.New("object does not exist")
+	// ErrPreconditionFailed is returned when the conditions of a read,
+	// write, update, or deletion do not hold.
+	ErrPreconditionFailed = errors.New("object precondition failed")
+)
+
+// Attrs are the attributes of an object that the server uses.
+type Attrs struct {
+	Name        string
+	Size        int64
+	ContentType string
+	// Metadata is the custom metadata, such as the digest and naming
+	// fields of a document.
+	Metadata map[string]string
+	// Generation changes whenever the data is replaced, and
+	// Metageneration whenever the metadata of a generation is updated.
+	Generation     int64
+	Metageneration int64
+	Created        time.Time
+	Updated        time.Time
+}
+
+// Conditions are preconditions of a write, update, or deletion. Zero
+// fields are not checked.
+type Conditions struct {
+	// DoesNotExist requires that there is no object yet.
+	DoesNotExist bool
+	// GenerationMatch requires the object's generation.
+	GenerationMatch int64
+	// MetagenerationMatch requires the object's metageneration.
+	MetagenerationMatch int64
+}
+
+// WriteOptions are the attributes of a written object and the conditions
+// under which it replaces the stored one.
+type WriteOptions struct {
+	ContentType        string
+	ContentDisposition string
+	Metadata           map[string]string
+	If                 Conditions
+}
+
+// Reader is an open object. Attrs are those of the generation being read;
+// custom metadata is not included.
+type Reader struct {
+	io.ReadCloser
+	Attrs Attrs
+}
+
+// Writer uploads an object, which replaces the stored one once the writer
+// is closed. Canceling the context of NewWriter before Close abandons the
+// upload.
+type Writer interface {
+	io.WriteCloser
+	// Attrs returns the attributes of the written object after Close
+	// succeeded.
+	Attrs() *Attrs
+}
+
+// Bucket reads and writes the objects of one bucket. It is the part of an
+// object store that the resolver, the download and cache handlers, and the
+// bucket-backed stores use, so that they share the server's client and can
+// be given a fake.
+type Bucket interface {
+	// NewReader opens an object, failing with ErrNotExist when it is
+	// missing.
+	NewReader(ctx context.Context, name string) (*Reader, error)
+	// NewRangeReader opens length bytes of an object from offset, or the
+	// rest of it when length is negative. A nonzero generation pins the
+	// read to that generation, which is missing once it was replaced.
+	NewRangeReader(ctx context.Context, name string, generation, offset, length int64) (*Reader, error)
+	// NewWriter replaces an object with what is written once the writer is
+	// closed, failing on Close with ErrPreconditionFailed when the
+	// conditions of opts do not hold.
+	NewWriter(ctx context.Context, name string, opts WriteOptions) Writer
+	// Attrs returns the attributes of an object, failing with ErrNotExist
+	// when it is missing.
+	Attrs(ctx context.Context, name string) (*Attrs, error)
+	// Update sets the custom metadata of an object and returns its new
+	// attributes.
+	Update(ctx context.Context, name string, metadata map[string]string, conditions Conditions) (*Attrs, error)
+	// Delete removes an object, failing with ErrNotExist when it is
+	// missing.
+	Delete(ctx context.Context, name string, conditions Conditions) error
+	// List returns the objects whose names start with prefix.
+	List(ctx context.Context, prefix string) ([]*Attrs, error)
+	// Dirs returns the directories directly below prefix, each ending in
+	// a slash, where names are divided into directories at slashes.
+	Dirs(ctx context.Context, prefix string) ([]string, error)
+	// SignedURL returns a URL where an object can be downloaded without
+	// credentials until expires. A non-empty disposition is served as its
+	// Content-Disposition.
+	SignedURL(name string, expires time.Time, disposition string) (string, error)
+}
+
+// Client opens the buckets of a Cloud Storage client.
+type Client struct {
+	client *storage.Client
+}
+
+// NewClient returns a Client of a Cloud Storage client.
+func NewClient(client *storage.Client) *Client {
+	return &Client{client: client}
+}
+
+// Bucket returns the Bucket of the named bucket.
+func (c *Client) Bucket(name string) Bucket {
+	return GCS(c.client.Bucket(name))
+}
+
+// GCS returns the Bucket of a Cloud Storage bucket.
+func GCS(bucket *storage.BucketHandle) Bucket {
+	return gcsBucket{bucket: bucket}
+}
+
+type gcsBucket struct {
+	bucket *storage.BucketHandle
+}
+
+// object returns the handle of an object under conditions.
+func (b gcsBucket) object(name string, conditions Conditions) *storage.ObjectHandle {
+	obj := b.bucket.Object(name)
+	if conditions == (Conditions{}) {
+		return obj
+	}
+	return obj.If(storage.Conditions{
+		DoesNotExist:        conditions.DoesNotExist,
+		GenerationMatch:     conditions.GenerationMatch,
+		MetagenerationMatch: conditions.MetagenerationMatch,
+	})
+}
+
+func (b gcsBucket) NewReader(ctx context.Context, name string) (*Reader, error) {
+	return b.NewRangeReader(ctx, name, 0, 0, -1)
+}
+
+func (b gcsBucket) NewRangeReader(ctx context.Context, name string, generation, offset, length int64) (*Reader, error) {
+	obj := b.bucket.Object(name)
+	if generation != 0 {
+		obj = obj.Generation(generation)
+	}
+	reader, err := obj.NewRangeReader(ctx, offset, length)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return &Reader{
+		ReadCloser: reader,
+		Attrs: Attrs{
+			Name:           name,
+			Size:           reader.Attrs.Size,
+			ContentType:    reader.Attrs.ContentType,
+			Generation:     reader.Attrs.Generation,
+			Metageneration: reader.Attrs.Metageneration,
+			Updated:        reader.Attrs.LastModified,
+		},
+	}, nil
+}
+
+func (b gcsBucket) NewWriter(ctx context.Context, name string, opts WriteOptions) Writer {
+	w := b.object(name, opts.If).NewWriter(ctx)
+	w.ContentType = opts.ContentType
+	w.ContentDisposition = opts.ContentDisposition
+	w.Metadata = opts.Metadata
+	return gcsWriter{w}
+}
+
+type gcsWriter struct {
+	*storage.Writer
+}
+
+func (w gcsWriter) Close() error {
+	return gcsError(w.Writer.Close())
+}
+
+func (w gcsWriter) Attrs() *Attrs {
+	return newAttrs(w.Writer.Attrs())
+}
+
+func (b gcsBucket) Attrs(ctx context.Context, name string) (*Attrs, error) {
+	attrs, err := b.bucket.Object(name).Attrs(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return newAttrs(attrs), nil
+}
+
+func (b gcsBucket) Update(ctx context.Context, name string, metadata map[string]string, conditions Conditions) (*Attrs, error) {
+	attrs, err := b.object(name, conditions).Update(ctx, storage.ObjectAttrsToUpdate{Metadata: metadata})
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return newAttrs(attrs), nil
+}
+
+func (b gcsBucket) Delete(ctx context.Context, name string, conditions Conditions) error {
+	return gcsError(b.object(name, conditions).Delete(ctx))
+}
+
+func (b gcsBucket) List(ctx context.Context, prefix string) ([]*Attrs, error) {
+	var result []*Attrs
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, newAttrs(attrs))
+	}
+}
+
+func (b gcsBucket) Dirs(ctx context.Context, prefix string) ([]string, error) {
+	var result []string
+	it := b.bucket.Objects(ctx, &storage.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(attrs.Prefix, "/") {
+			result = append(result, attrs.Prefix)
+		}
+	}
+}
+
+func (b gcsBucket) SignedURL(name string, expires time.Time, disposition string) (string, error) {
+	opts := &storage.SignedURLOptions{
+		Scheme:  storage.SigningSchemeV4,
+		Method:  "GET",
+		Expires: expires,
+	}
+	if disposition != "" {
+		opts.QueryParameters = url.Values{"response-content-disposition": {disposition}}
+	}
+	return b.bucket.SignedURL(name, opts)
+}
+
+// gcsError returns ErrNotExist and ErrPreconditionFailed for the errors
+// of a missing object and a failed precondition.
+func gcsError(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return ErrNotExist
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusPreconditionFailed {
+		return ErrPreconditionFailed
+	}
+	return err
+}
+
+func newAttrs(attrs *storage.ObjectAttrs) *Attrs {
+	return &Attrs{
+		Name:           attrs.Name,
+		Size:           attrs.Size,
+		ContentType:    attrs.ContentType,
+		Metadata:       attrs.Metadata,
+		Generation:     attrs.Generation,
+		Metageneration: attrs.Metageneration,
+		Created:        attrs.Created,
+		Updated:        attrs.Updated,
+	}
+}
@@ -0,0 +1,97 @@
+package presets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// BucketStore keeps each preset in a `presets/{name}.json` object below the
+// storage prefix of its tenant, next to the tenant's documents.
+type BucketStore struct {
+	bucket objects.Bucket
+	prefix string
+}
+
+// NewBucketStore returns a store in bucket, whose client is closed by the
+// caller.
+func NewBucketStore(bucket objects.Bucket, prefix string) *BucketStore {
+	return &BucketStore{bucket: bucket, prefix: prefix}
+}
+
+func (s *BucketStore) dir(tenantID string) string {
+	return tenant.Prefix(s.prefix, tenantID) + "/presets/"
+}
+
+func (s *BucketStore) Get(ctx context.Context, tenantID, name string) (*Preset, error) {
+	reader, err := s.bucket.NewReader(ctx, s.dir(tenantID)+name+".json")
+	if errors.Is(err, objects.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset %s: %v", name, err)
+	}
+	defer reader.Close()
+
+	var preset Preset
+	if err := json.NewDecoder(reader).Decode(&preset); err != nil {
+		return nil, fmt.Errorf("failed to decode preset %s: %v", name, err)
+	}
+	return &preset, nil
+}
+
+func (s *BucketStore) Put(ctx context.Context, preset *Preset) error {
+	w := s.bucket.NewWriter(ctx, s.dir(preset.Tenant)+preset.Name+".json", objects.WriteOptions{ContentType: "application/json"})
+	if err := json.NewEncoder(w).Encode(preset); err != nil {
+		_ = w.Close()
+		return fmt.Errorf("failed to write preset %s: %v", preset.Name, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to close preset writer for %s: %v", preset.Name, err)
+	}
+	return nil
+}
+
+func (s *BucketStore) Delete(ctx context.Context, tenantID, name string) error {
+	err := s.bucket.Delete(ctx, s.dir(tenantID)+name+".json", objects.Conditions{})
+	if errors.Is(err, objects.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete preset %s: %v", name, err)
+	}
+	return nil
+}
+
+// List reads every preset object of the tenant. Presets are few, so they
+// are not indexed.
+func (s *BucketStore) List(ctx context.Context, tenantID string) ([]*Preset, error) {
+	dir := s.dir(tenantID)
+	listed, err := s.bucket.List(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presets: %v", err)
+	}
+	var result []*Preset
+	for _, attrs := range listed {
+		name, ok := strings.CutSuffix(strings.TrimPrefix(attrs.Name, dir), ".json")
+		if !ok || strings.Contains(name, "/") {
+			continue
+		}
+		preset, err := s.Get(ctx, tenantID, name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, preset)
+	}
+	return sortByName(result), nil
+}
+
+// Close does nothing; the bucket's client belongs to the caller.
+func (s *BucketStore) Close() error {
+	return nil
+}
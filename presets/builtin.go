@@ -0,0 +1,58 @@
+package presets
+
+import "time"
+
+// builtInUpdated is when the built-in presets last changed. It is part of
+// the ETags of their EPUBs, so it must move with every change below.
+var builtInUpdated = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+// builtIn are the accessibility presets every client can use. Their names
+// are uppercase, which ValidateName rejects, so stored presets never hide
+// them.
+var builtIn = []Preset{
+	{
+		Name:        "DYSLEXIA_FRIENDLY",
+		Description: "Gothic at 120% with wide line and letter spacing, for readers with dyslexia",
+		FontFamily:  "sans-serif",
+		FontSize:    120,
+		LineHeight:  200,
+		// Wider spacing keeps characters from crowding each other.
+		LetterSpacing: 10,
+	},
+	{
+		Name:         "HIGH_CONTRAST",
+		Description:  "Black gothic text on white with underlined links, for low vision",
+		FontFamily:   "sans-serif",
+		FontSize:     120,
+		LineHeight:   170,
+		HighContrast: true,
+	},
+	{
+		Name:        "LARGE_PRINT",
+		Description: "Mincho at 180% with wide line spacing, for large print",
+		FontFamily:  "serif",
+		FontSize:    180,
+		LineHeight:  180,
+	},
+}
+
+// BuiltIn returns the built-in preset with the name.
+func BuiltIn(name string) (*Preset, bool) {
+	for _, preset := range builtIn {
+		if preset.Name == name {
+			preset.UpdatedAt = builtInUpdated
+			return &preset, true
+		}
+	}
+	return nil, false
+}
+
+// BuiltIns returns the built-in presets ordered by name.
+func BuiltIns() []*Preset {
+	presets := make([]*Preset, len(builtIn))
+	for i, preset := range builtIn {
+		preset.UpdatedAt = builtInUpdated
+		presets[i] = &preset
+	}
+	return presets
+}
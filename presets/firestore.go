@@ -0,0 +1,89 @@
+package presets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// FirestoreStore keeps one document per preset in a Firestore collection.
+// Listing the presets of a tenant uses the default single-field index on
+// tenant.
+type FirestoreStore struct {
+	client     *firestore.Client
+	collection string
+}
+
+func NewFirestoreStore(ctx context.Context, projectID, collection string) (*FirestoreStore, error) {
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firestore client: %v", err)
+	}
+	return &FirestoreStore{client: client, collection: collection}, nil
+}
+
+// doc returns the document of a preset, named by its tenant-scoped name
+// with slashes, which Firestore reads as path separators, replaced.
+func (s *FirestoreStore) doc(tenantID, name string) *firestore.DocumentRef {
+	return s.client.Collection(s.collection).Doc(strings.ReplaceAll(tenant.ScopedID(tenantID, name), "/", ":"))
+}
+
+func (s *FirestoreStore) Get(ctx context.Context, tenantID, name string) (*Preset, error) {
+	snap, err := s.doc(tenantID, name).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset %s: %v", name, err)
+	}
+
+	var preset Preset
+	if err := snap.DataTo(&preset); err != nil {
+		return nil, fmt.Errorf("failed to decode preset %s: %v", name, err)
+	}
+	return &preset, nil
+}
+
+func (s *FirestoreStore) Put(ctx context.Context, preset *Preset) error {
+	if _, err := s.doc(preset.Tenant, preset.Name).Set(ctx, preset); err != nil {
+		return fmt.Errorf("failed to save preset %s: %v", preset.Name, err)
+	}
+	return nil
+}
+
+func (s *FirestoreStore) Delete(ctx context.Context, tenantID, name string) error {
+	if _, err := s.doc(tenantID, name).Delete(ctx, firestore.Exists); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete preset %s: %v", name, err)
+	}
+	return nil
+}
+
+func (s *FirestoreStore) List(ctx context.Context, tenantID string) ([]*Preset, error) {
+	snaps, err := s.client.Collection(s.collection).Where("tenant", "==", tenantID).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list presets: %v", err)
+	}
+
+	result := make([]*Preset, 0, len(snaps))
+	for _, snap := range snaps {
+		var preset Preset
+		if err := snap.DataTo(&preset); err != nil {
+			return nil, fmt.Errorf("failed to decode preset %s: %v", snap.Ref.ID, err)
+		}
+		result = append(result, &preset)
+	}
+	return sortByName(result), nil
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
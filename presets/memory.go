@@ -0,0 +1,65 @@
+package presets
+
+import (
+	"context"
+	"sync"
+
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+)
+
+// MemoryStore keeps presets in process memory. It is intended for local
+// development; presets are lost on restart and not shared between
+// instances.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	presets map[string]Preset
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{presets: make(map[string]Preset)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, tenantID, name string) (*Preset, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preset, ok := s.presets[tenant.ScopedID(tenantID, name)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &preset, nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, preset *Preset) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.presets[tenant.ScopedID(preset.Tenant, preset.Name)] = *preset
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, tenantID, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := tenant.ScopedID(tenantID, name)
+	if _, ok := s.presets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.presets, key)
+	return nil
+}
+
+func (s *MemoryStore) List(_ context.Context, tenantID string) ([]*Preset, error) {
+	s.mu.RLock()
+	var result []*Preset
+	for key := range s.presets {
+		preset := s.presets[key]
+		if preset.Tenant == tenantID {
+			result = append(result, &preset)
+		}
+	}
+	s.mu.RUnlock()
+
+	return sortByName(result), nil
+}
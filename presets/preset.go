@@ -0,0 +1,72 @@
+package presets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// ErrNotFound is returned by Store.Get and Store.Delete when no preset
+// exists for the name.
+var ErrNotFound = errors.New("preset not found")
+
+// Preset is a named set of converter options that EPUB requests refer to
+// by name. Presets of a tenant are visible to that tenant only; presets
+// without a tenant are shared by everyone.
+type Preset struct {
+	Tenant      string `json:"tenant,omitempty" firestore:"tenant"`
+	Name        string `json:"name" firestore:"name"`
+	Description string `json:"description,omitempty" firestore:"description"`
+	Vertical    bool   `json:"vertical,omitempty" firestore:"vertical"`
+	// FontFamily is "serif", "sans-serif", or empty.
+	FontFamily string `json:"fontFamily,omitempty" firestore:"fontFamily"`
+	// Font is the name of a configured font to embed, or empty for none.
+	Font string `json:"font,omitempty" firestore:"font"`
+	// FontSize is in percent; zero keeps the reading system's size.
+	FontSize int `json:"fontSize,omitempty" firestore:"fontSize"`
+	// LineHeight is in percent of the font size; zero keeps the reading
+	// system's spacing.
+	LineHeight int `json:"lineHeight,omitempty" firestore:"lineHeight"`
+	// LetterSpacing is in percent of the font size.
+	LetterSpacing       int  `json:"letterSpacing,omitempty" firestore:"letterSpacing"`
+	HighContrast        bool `json:"highContrast,omitempty" firestore:"highContrast"`
+	Furigana            bool `json:"furigana,omitempty" firestore:"furigana"`
+	Accessible          bool `json:"accessible,omitempty" firestore:"accessible"`
+	OmitSupplProvisions bool `json:"omitSupplProvisions,omitempty" firestore:"omitSupplProvisions"`
+	// Cover is the name of a GraphQL CoverStyle, or empty for none.
+	Cover     string    `json:"cover,omitempty" firestore:"cover"`
+	UpdatedAt time.Time `json:"updatedAt" firestore:"updatedAt"`
+}
+
+// ValidateName reports whether name can name a preset. Names become part
+// of storage paths, so they are limited to lowercase letters, digits, and
+// hyphens.
+func ValidateName(name string) error {
+	if !regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`).MatchString(name) {
+		return fmt.Errorf("invalid preset name %q (expected lowercase letters, digits, and hyphens)", name)
+	}
+	return nil
+}
+
+// Store persists presets.
+type Store interface {
+	// Get returns the preset of a tenant, or a shared preset when tenantID
+	// is empty.
+	Get(ctx context.Context, tenantID, name string) (*Preset, error)
+	// Put creates or replaces a preset.
+	Put(ctx context.Context, preset *Preset) error
+	Delete(ctx context.Context, tenantID, name string) error
+	// List returns the presets of a tenant, or the shared presets when
+	// tenantID is empty, ordered by name.
+	List(ctx context.Context, tenantID string) ([]*Preset, error)
+}
+
+func sortByName(presets []*Preset) []*Preset {
+	sort.Slice(presets, func(i, k int) bool {
+		return presets[i].Name < presets[k].Name
+	})
+	return presets
+}
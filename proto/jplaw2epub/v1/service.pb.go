@@ -0,0 +1,731 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v5.29.3
+// source: jplaw2epub/v1/service.proto
+
+package jplaw2epubv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EpubStatus int32
+
+const (
+	EpubStatus_EPUB_STATUS_UNSPECIFIED EpubStatus = 0
+	EpubStatus_EPUB_STATUS_PENDING     EpubStatus = 1
+	EpubStatus_EPUB_STATUS_PROCESSING  EpubStatus = 2
+	EpubStatus_EPUB_STATUS_COMPLETED   EpubStatus = 3
+	EpubStatus_EPUB_STATUS_FAILED      EpubStatus = 4
+)
+
+// Enum value maps for EpubStatus.
+var (
+	EpubStatus_name = map[int32]string{
+		0: "EPUB_STATUS_UNSPECIFIED",
+		1: "EPUB_STATUS_PENDING",
+		2: "EPUB_STATUS_PROCESSING",
+		3: "EPUB_STATUS_COMPLETED",
+		4: "EPUB_STATUS_FAILED",
+	}
+	EpubStatus_value = map[string]int32{
+		"EPUB_STATUS_UNSPECIFIED": 0,
+		"EPUB_STATUS_PENDING":     1,
+		"EPUB_STATUS_PROCESSING":  2,
+		"EPUB_STATUS_COMPLETED":   3,
+		"EPUB_STATUS_FAILED":      4,
+	}
+)
+
+func (x EpubStatus) Enum() *EpubStatus {
+	p := new(EpubStatus)
+	*p = x
+	return p
+}
+
+func (x EpubStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EpubStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_jplaw2epub_v1_service_proto_enumTypes[0].Descriptor()
+}
+
+func (EpubStatus) Type() protoreflect.EnumType {
+	return &file_jplaw2epub_v1_service_proto_enumTypes[0]
+}
+
+func (x EpubStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EpubStatus.Descriptor instead.
+func (EpubStatus) EnumDescriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{0}
+}
+
+type SearchLawsRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	LawId        string                 `protobuf:"bytes,1,opt,name=law_id,json=lawId,proto3" json:"law_id,omitempty"`
+	LawNum       string                 `protobuf:"bytes,2,opt,name=law_num,json=lawNum,proto3" json:"law_num,omitempty"`
+	LawTitle     string                 `protobuf:"bytes,3,opt,name=law_title,json=lawTitle,proto3" json:"law_title,omitempty"`
+	LawTitleKana string                 `protobuf:"bytes,4,opt,name=law_title_kana,json=lawTitleKana,proto3" json:"law_title_kana,omitempty"`
+	// Date in YYYY-MM-DD format or a Japanese era date such as 令和5年4月1日.
+	Asof          string `protobuf:"bytes,5,opt,name=asof,proto3" json:"asof,omitempty"`
+	Limit         int32  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchLawsRequest) Reset() {
+	*x = SearchLawsRequest{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchLawsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchLawsRequest) ProtoMessage() {}
+
+func (x *SearchLawsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchLawsRequest.ProtoReflect.Descriptor instead.
+func (*SearchLawsRequest) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchLawsRequest) GetLawId() string {
+	if x != nil {
+		return x.LawId
+	}
+	return ""
+}
+
+func (x *SearchLawsRequest) GetLawNum() string {
+	if x != nil {
+		return x.LawNum
+	}
+	return ""
+}
+
+func (x *SearchLawsRequest) GetLawTitle() string {
+	if x != nil {
+		return x.LawTitle
+	}
+	return ""
+}
+
+func (x *SearchLawsRequest) GetLawTitleKana() string {
+	if x != nil {
+		return x.LawTitleKana
+	}
+	return ""
+}
+
+func (x *SearchLawsRequest) GetAsof() string {
+	if x != nil {
+		return x.Asof
+	}
+	return ""
+}
+
+func (x *SearchLawsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchLawsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SearchLawsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalCount    int64                  `protobuf:"varint,1,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	NextOffset    int64                  `protobuf:"varint,3,opt,name=next_offset,json=nextOffset,proto3" json:"next_offset,omitempty"`
+	Laws          []*Law                 `protobuf:"bytes,4,rep,name=laws,proto3" json:"laws,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchLawsResponse) Reset() {
+	*x = SearchLawsResponse{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchLawsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchLawsResponse) ProtoMessage() {}
+
+func (x *SearchLawsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchLawsResponse.ProtoReflect.Descriptor instead.
+func (*SearchLawsResponse) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchLawsResponse) GetTotalCount() int64 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *SearchLawsResponse) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *SearchLawsResponse) GetNextOffset() int64 {
+	if x != nil {
+		return x.NextOffset
+	}
+	return 0
+}
+
+func (x *SearchLawsResponse) GetLaws() []*Law {
+	if x != nil {
+		return x.Laws
+	}
+	return nil
+}
+
+type GetLawRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Law ID such as 325AC0000000131, or a law number.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLawRequest) Reset() {
+	*x = GetLawRequest{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLawRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLawRequest) ProtoMessage() {}
+
+func (x *GetLawRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLawRequest.ProtoReflect.Descriptor instead.
+func (*GetLawRequest) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetLawRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type Law struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	LawId  string                 `protobuf:"bytes,1,opt,name=law_id,json=lawId,proto3" json:"law_id,omitempty"`
+	LawNum string                 `protobuf:"bytes,2,opt,name=law_num,json=lawNum,proto3" json:"law_num,omitempty"`
+	// Date in YYYY-MM-DD format.
+	PromulgationDate string `protobuf:"bytes,3,opt,name=promulgation_date,json=promulgationDate,proto3" json:"promulgation_date,omitempty"`
+	LawRevisionId    string `protobuf:"bytes,4,opt,name=law_revision_id,json=lawRevisionId,proto3" json:"law_revision_id,omitempty"`
+	LawTitle         string `protobuf:"bytes,5,opt,name=law_title,json=lawTitle,proto3" json:"law_title,omitempty"`
+	LawTitleKana     string `protobuf:"bytes,6,opt,name=law_title_kana,json=lawTitleKana,proto3" json:"law_title_kana,omitempty"`
+	Abbrev           string `protobuf:"bytes,7,opt,name=abbrev,proto3" json:"abbrev,omitempty"`
+	Category         string `protobuf:"bytes,8,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Law) Reset() {
+	*x = Law{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Law) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Law) ProtoMessage() {}
+
+func (x *Law) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Law.ProtoReflect.Descriptor instead.
+func (*Law) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Law) GetLawId() string {
+	if x != nil {
+		return x.LawId
+	}
+	return ""
+}
+
+func (x *Law) GetLawNum() string {
+	if x != nil {
+		return x.LawNum
+	}
+	return ""
+}
+
+func (x *Law) GetPromulgationDate() string {
+	if x != nil {
+		return x.PromulgationDate
+	}
+	return ""
+}
+
+func (x *Law) GetLawRevisionId() string {
+	if x != nil {
+		return x.LawRevisionId
+	}
+	return ""
+}
+
+func (x *Law) GetLawTitle() string {
+	if x != nil {
+		return x.LawTitle
+	}
+	return ""
+}
+
+func (x *Law) GetLawTitleKana() string {
+	if x != nil {
+		return x.LawTitleKana
+	}
+	return ""
+}
+
+func (x *Law) GetAbbrev() string {
+	if x != nil {
+		return x.Abbrev
+	}
+	return ""
+}
+
+func (x *Law) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+type RequestEpubRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Law revision ID.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Article numbers for an excerpt; empty for the whole law.
+	Articles      []string `protobuf:"bytes,2,rep,name=articles,proto3" json:"articles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestEpubRequest) Reset() {
+	*x = RequestEpubRequest{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestEpubRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestEpubRequest) ProtoMessage() {}
+
+func (x *RequestEpubRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestEpubRequest.ProtoReflect.Descriptor instead.
+func (*RequestEpubRequest) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RequestEpubRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RequestEpubRequest) GetArticles() []string {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+type GetEpubStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Law revision ID.
+	Id            string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Articles      []string `protobuf:"bytes,2,rep,name=articles,proto3" json:"articles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEpubStatusRequest) Reset() {
+	*x = GetEpubStatusRequest{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEpubStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEpubStatusRequest) ProtoMessage() {}
+
+func (x *GetEpubStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEpubStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetEpubStatusRequest) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetEpubStatusRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetEpubStatusRequest) GetArticles() []string {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+type Epub struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Articles []string               `protobuf:"bytes,2,rep,name=articles,proto3" json:"articles,omitempty"`
+	Status   EpubStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=jplaw2epub.v1.EpubStatus" json:"status,omitempty"`
+	// Set when status is EPUB_STATUS_COMPLETED.
+	SignedUrl string `protobuf:"bytes,4,opt,name=signed_url,json=signedUrl,proto3" json:"signed_url,omitempty"`
+	Size      int64  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Etag      string `protobuf:"bytes,6,opt,name=etag,proto3" json:"etag,omitempty"`
+	Error     string `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	Attempts  int32  `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// RFC 3339 time of the next automatic retry of a failed generation.
+	NextRetryAt   string `protobuf:"bytes,9,opt,name=next_retry_at,json=nextRetryAt,proto3" json:"next_retry_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Epub) Reset() {
+	*x = Epub{}
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Epub) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Epub) ProtoMessage() {}
+
+func (x *Epub) ProtoReflect() protoreflect.Message {
+	mi := &file_jplaw2epub_v1_service_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Epub.ProtoReflect.Descriptor instead.
+func (*Epub) Descriptor() ([]byte, []int) {
+	return file_jplaw2epub_v1_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Epub) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Epub) GetArticles() []string {
+	if x != nil {
+		return x.Articles
+	}
+	return nil
+}
+
+func (x *Epub) GetStatus() EpubStatus {
+	if x != nil {
+		return x.Status
+	}
+	return EpubStatus_EPUB_STATUS_UNSPECIFIED
+}
+
+func (x *Epub) GetSignedUrl() string {
+	if x != nil {
+		return x.SignedUrl
+	}
+	return ""
+}
+
+func (x *Epub) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Epub) GetEtag() string {
+	if x != nil {
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *Epub) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Epub) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Epub) GetNextRetryAt() string {
+	if x != nil {
+		return x.NextRetryAt
+	}
+	return ""
+}
+
+var File_jplaw2epub_v1_service_proto protoreflect.FileDescriptor
+
+const file_jplaw2epub_v1_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1bjplaw2epub/v1/service.proto\x12\rjplaw2epub.v1\"\xc8\x01\n" +
+	"\x11SearchLawsRequest\x12\x15\n" +
+	"\x06law_id\x18\x01 \x01(\tR\x05lawId\x12\x17\n" +
+	"\alaw_num\x18\x02 \x01(\tR\x06lawNum\x12\x1b\n" +
+	"\tlaw_title\x18\x03 \x01(\tR\blawTitle\x12$\n" +
+	"\x0elaw_title_kana\x18\x04 \x01(\tR\flawTitleKana\x12\x12\n" +
+	"\x04asof\x18\x05 \x01(\tR\x04asof\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x05R\x06offset\"\x94\x01\n" +
+	"\x12SearchLawsResponse\x12\x1f\n" +
+	"\vtotal_count\x18\x01 \x01(\x03R\n" +
+	"totalCount\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x1f\n" +
+	"\vnext_offset\x18\x03 \x01(\x03R\n" +
+	"nextOffset\x12&\n" +
+	"\x04laws\x18\x04 \x03(\v2\x12.jplaw2epub.v1.LawR\x04laws\"\x1f\n" +
+	"\rGetLawRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x81\x02\n" +
+	"\x03Law\x12\x15\n" +
+	"\x06law_id\x18\x01 \x01(\tR\x05lawId\x12\x17\n" +
+	"\alaw_num\x18\x02 \x01(\tR\x06lawNum\x12+\n" +
+	"\x11promulgation_date\x18\x03 \x01(\tR\x10promulgationDate\x12&\n" +
+	"\x0flaw_revision_id\x18\x04 \x01(\tR\rlawRevisionId\x12\x1b\n" +
+	"\tlaw_title\x18\x05 \x01(\tR\blawTitle\x12$\n" +
+	"\x0elaw_title_kana\x18\x06 \x01(\tR\flawTitleKana\x12\x16\n" +
+	"\x06abbrev\x18\a \x01(\tR\x06abbrev\x12\x1a\n" +
+	"\bcategory\x18\b \x01(\tR\bcategory\"@\n" +
+	"\x12RequestEpubRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\barticles\x18\x02 \x03(\tR\barticles\"B\n" +
+	"\x14GetEpubStatusRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\barticles\x18\x02 \x03(\tR\barticles\"\x82\x02\n" +
+	"\x04Epub\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1a\n" +
+	"\barticles\x18\x02 \x03(\tR\barticles\x121\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x19.jplaw2epub.v1.EpubStatusR\x06status\x12\x1d\n" +
+	"\n" +
+	"signed_url\x18\x04 \x01(\tR\tsignedUrl\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x06 \x01(\tR\x04etag\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\"\n" +
+	"\rnext_retry_at\x18\t \x01(\tR\vnextRetryAt*\x91\x01\n" +
+	"\n" +
+	"EpubStatus\x12\x1b\n" +
+	"\x17EPUB_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13EPUB_STATUS_PENDING\x10\x01\x12\x1a\n" +
+	"\x16EPUB_STATUS_PROCESSING\x10\x02\x12\x19\n" +
+	"\x15EPUB_STATUS_COMPLETED\x10\x03\x12\x16\n" +
+	"\x12EPUB_STATUS_FAILED\x10\x042\xad\x02\n" +
+	"\n" +
+	"LawService\x12Q\n" +
+	"\n" +
+	"SearchLaws\x12 .jplaw2epub.v1.SearchLawsRequest\x1a!.jplaw2epub.v1.SearchLawsResponse\x12:\n" +
+	"\x06GetLaw\x12\x1c.jplaw2epub.v1.GetLawRequest\x1a\x12.jplaw2epub.v1.Law\x12E\n" +
+	"\vRequestEpub\x12!.jplaw2epub.v1.RequestEpubRequest\x1a\x13.jplaw2epub.v1.Epub\x12I\n" +
+	"\rGetEpubStatus\x12#.jplaw2epub.v1.GetEpubStatusRequest\x1a\x13.jplaw2epub.v1.EpubB?Z=go.ngs.io/jplaw2epub-web-api/proto/jplaw2epub/v1;jplaw2epubv1b\x06proto3"
+
+var (
+	file_jplaw2epub_v1_service_proto_rawDescOnce sync.Once
+	file_jplaw2epub_v1_service_proto_rawDescData []byte
+)
+
+func file_jplaw2epub_v1_service_proto_rawDescGZIP() []byte {
+	file_jplaw2epub_v1_service_proto_rawDescOnce.Do(func() {
+		file_jplaw2epub_v1_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_jplaw2epub_v1_service_proto_rawDesc), len(file_jplaw2epub_v1_service_proto_rawDesc)))
+	})
+	return file_jplaw2epub_v1_service_proto_rawDescData
+}
+
+var file_jplaw2epub_v1_service_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_jplaw2epub_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_jplaw2epub_v1_service_proto_goTypes = []any{
+	(EpubStatus)(0),              // 0: jplaw2epub.v1.EpubStatus
+	(*SearchLawsRequest)(nil),    // 1: jplaw2epub.v1.SearchLawsRequest
+	(*SearchLawsResponse)(nil),   // 2: jplaw2epub.v1.SearchLawsResponse
+	(*GetLawRequest)(nil),        // 3: jplaw2epub.v1.GetLawRequest
+	(*Law)(nil),                  // 4: jplaw2epub.v1.Law
+	(*RequestEpubRequest)(nil),   // 5: jplaw2epub.v1.RequestEpubRequest
+	(*GetEpubStatusRequest)(nil), // 6: jplaw2epub.v1.GetEpubStatusRequest
+	(*Epub)(nil),                 // 7: jplaw2epub.v1.Epub
+}
+var file_jplaw2epub_v1_service_proto_depIdxs = []int32{
+	4, // 0: jplaw2epub.v1.SearchLawsResponse.laws:type_name -> jplaw2epub.v1.Law
+	0, // 1: jplaw2epub.v1.Epub.status:type_name -> jplaw2epub.v1.EpubStatus
+	1, // 2: jplaw2epub.v1.LawService.SearchLaws:input_type -> jplaw2epub.v1.SearchLawsRequest
+	3, // 3: jplaw2epub.v1.LawService.GetLaw:input_type -> jplaw2epub.v1.GetLawRequest
+	5, // 4: jplaw2epub.v1.LawService.RequestEpub:input_type -> jplaw2epub.v1.RequestEpubRequest
+	6, // 5: jplaw2epub.v1.LawService.GetEpubStatus:input_type -> jplaw2epub.v1.GetEpubStatusRequest
+	2, // 6: jplaw2epub.v1.LawService.SearchLaws:output_type -> jplaw2epub.v1.SearchLawsResponse
+	4, // 7: jplaw2epub.v1.LawService.GetLaw:output_type -> jplaw2epub.v1.Law
+	7, // 8: jplaw2epub.v1.LawService.RequestEpub:output_type -> jplaw2epub.v1.Epub
+	7, // 9: jplaw2epub.v1.LawService.GetEpubStatus:output_type -> jplaw2epub.v1.Epub
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_jplaw2epub_v1_service_proto_init() }
+func file_jplaw2epub_v1_service_proto_init() {
+	if File_jplaw2epub_v1_service_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_jplaw2epub_v1_service_proto_rawDesc), len(file_jplaw2epub_v1_service_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_jplaw2epub_v1_service_proto_goTypes,
+		DependencyIndexes: file_jplaw2epub_v1_service_proto_depIdxs,
+		EnumInfos:         file_jplaw2epub_v1_service_proto_enumTypes,
+		MessageInfos:      file_jplaw2epub_v1_service_proto_msgTypes,
+	}.Build()
+	File_jplaw2epub_v1_service_proto = out.File
+	file_jplaw2epub_v1_service_proto_goTypes = nil
+	file_jplaw2epub_v1_service_proto_depIdxs = nil
+}
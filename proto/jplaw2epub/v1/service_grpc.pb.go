@@ -0,0 +1,249 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: jplaw2epub/v1/service.proto
+
+package jplaw2epubv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LawService_SearchLaws_FullMethodName    = "/jplaw2epub.v1.LawService/SearchLaws"
+	LawService_GetLaw_FullMethodName        = "/jplaw2epub.v1.LawService/GetLaw"
+	LawService_RequestEpub_FullMethodName   = "/jplaw2epub.v1.LawService/RequestEpub"
+	LawService_GetEpubStatus_FullMethodName = "/jplaw2epub.v1.LawService/GetEpubStatus"
+)
+
+// LawServiceClient is the client API for LawService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LawService mirrors the main GraphQL operations for gRPC clients.
+type LawServiceClient interface {
+	// SearchLaws lists laws matching the given filters.
+	SearchLaws(ctx context.Context, in *SearchLawsRequest, opts ...grpc.CallOption) (*SearchLawsResponse, error)
+	// GetLaw looks up a single law by law ID or law number.
+	GetLaw(ctx context.Context, in *GetLawRequest, opts ...grpc.CallOption) (*Law, error)
+	// RequestEpub starts EPUB generation if needed and returns its state.
+	RequestEpub(ctx context.Context, in *RequestEpubRequest, opts ...grpc.CallOption) (*Epub, error)
+	// GetEpubStatus returns the state of a previously requested EPUB without
+	// starting generation.
+	GetEpubStatus(ctx context.Context, in *GetEpubStatusRequest, opts ...grpc.CallOption) (*Epub, error)
+}
+
+type lawServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLawServiceClient(cc grpc.ClientConnInterface) LawServiceClient {
+	return &lawServiceClient{cc}
+}
+
+func (c *lawServiceClient) SearchLaws(ctx context.Context, in *SearchLawsRequest, opts ...grpc.CallOption) (*SearchLawsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchLawsResponse)
+	err := c.cc.Invoke(ctx, LawService_SearchLaws_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lawServiceClient) GetLaw(ctx context.Context, in *GetLawRequest, opts ...grpc.CallOption) (*Law, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Law)
+	err := c.cc.Invoke(ctx, LawService_GetLaw_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lawServiceClient) RequestEpub(ctx context.Context, in *RequestEpubRequest, opts ...grpc.CallOption) (*Epub, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Epub)
+	err := c.cc.Invoke(ctx, LawService_RequestEpub_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lawServiceClient) GetEpubStatus(ctx context.Context, in *GetEpubStatusRequest, opts ...grpc.CallOption) (*Epub, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Epub)
+	err := c.cc.Invoke(ctx, LawService_GetEpubStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LawServiceServer is the server API for LawService service.
+// All implementations must embed UnimplementedLawServiceServer
+// for forward compatibility.
+//
+// LawService mirrors the main GraphQL operations for gRPC clients.
+type LawServiceServer interface {
+	// SearchLaws lists laws matching the given filters.
+	SearchLaws(context.Context, *SearchLawsRequest) (*SearchLawsResponse, error)
+	// GetLaw looks up a single law by law ID or law number.
+	GetLaw(context.Context, *GetLawRequest) (*Law, error)
+	// RequestEpub starts EPUB generation if needed and returns its state.
+	RequestEpub(context.Context, *RequestEpubRequest) (*Epub, error)
+	// GetEpubStatus returns the state of a previously requested EPUB without
+	// starting generation.
+	GetEpubStatus(context.Context, *GetEpubStatusRequest) (*Epub, error)
+	mustEmbedUnimplementedLawServiceServer()
+}
+
+// UnimplementedLawServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLawServiceServer struct{}
+
+func (UnimplementedLawServiceServer) SearchLaws(context.Context, *SearchLawsRequest) (*SearchLawsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchLaws not implemented")
+}
+func (UnimplementedLawServiceServer) GetLaw(context.Context, *GetLawRequest) (*Law, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLaw not implemented")
+}
+func (UnimplementedLawServiceServer) RequestEpub(context.Context, *RequestEpubRequest) (*Epub, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestEpub not implemented")
+}
+func (UnimplementedLawServiceServer) GetEpubStatus(context.Context, *GetEpubStatusRequest) (*Epub, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetEpubStatus not implemented")
+}
+func (UnimplementedLawServiceServer) mustEmbedUnimplementedLawServiceServer() {}
+func (UnimplementedLawServiceServer) testEmbeddedByValue()                    {}
+
+// UnsafeLawServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LawServiceServer will
+// result in compilation errors.
+type UnsafeLawServiceServer interface {
+	mustEmbedUnimplementedLawServiceServer()
+}
+
+func RegisterLawServiceServer(s grpc.ServiceRegistrar, srv LawServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLawServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LawService_ServiceDesc, srv)
+}
+
+func _LawService_SearchLaws_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchLawsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LawServiceServer).SearchLaws(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LawService_SearchLaws_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LawServiceServer).SearchLaws(ctx, req.(*SearchLawsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LawService_GetLaw_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLawRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LawServiceServer).GetLaw(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LawService_GetLaw_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LawServiceServer).GetLaw(ctx, req.(*GetLawRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LawService_RequestEpub_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestEpubRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LawServiceServer).RequestEpub(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LawService_RequestEpub_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LawServiceServer).RequestEpub(ctx, req.(*RequestEpubRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LawService_GetEpubStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetEpubStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LawServiceServer).GetEpubStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LawService_GetEpubStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LawServiceServer).GetEpubStatus(ctx, req.(*GetEpubStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LawService_ServiceDesc is the grpc.ServiceDesc for LawService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LawService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jplaw2epub.v1.LawService",
+	HandlerType: (*LawServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchLaws",
+			Handler:    _LawService_SearchLaws_Handler,
+		},
+		{
+			MethodName: "GetLaw",
+			Handler:    _LawService_GetLaw_Handler,
+		},
+		{
+			MethodName: "RequestEpub",
+			Handler:    _LawService_RequestEpub_Handler,
+		},
+		{
+			MethodName: "GetEpubStatus",
+			Handler:    _LawService_GetEpubStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "jplaw2epub/v1/service.proto",
+}
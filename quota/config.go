@@ -0,0 +1,31 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+)
+
+// StoreConfig selects and configures a Store backend.
+type StoreConfig struct {
+	// Backend is "firestore" or "memory".
+	Backend string
+	// ProjectID and Collection locate documents for the firestore store.
+	ProjectID  string
+	Collection string
+}
+
+// NewStore creates the store selected by cfg.Backend.
+func NewStore(ctx context.Context, cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "firestore":
+		store, err := NewFirestoreStore(ctx, cfg.ProjectID, cfg.Collection)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "memory":
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown quota store %q (expected firestore or memory)", cfg.Backend)
+	}
+}
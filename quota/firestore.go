@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FirestoreStore keeps one document per subject and period so counters are
+// shared between instances. A TTL policy on expiresAt removes old periods.
+type FirestoreStore struct {
+	client     *firestore.Client
+	collection string
+}
+
+// counterDoc is the stored form of a counter. Keys may contain characters
+// that are not allowed in document IDs, so documents are named by hash.
+type counterDoc struct {
+	Key       string    `firestore:"key"`
+	Count     int64     `firestore:"count"`
+	ExpiresAt time.Time `firestore:"expiresAt"`
+}
+
+func NewFirestoreStore(ctx context.Context, projectID, collection string) (*FirestoreStore, error) {
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firestore client: %v", err)
+	}
+	return &FirestoreStore{client: client, collection: collection}, nil
+}
+
+func (s *FirestoreStore) Increment(ctx context.Context, key string, n int64, expiresAt time.Time) (int64, error) {
+	sum := sha256.Sum256([]byte(key))
+	ref := s.client.Collection(s.collection).Doc(hex.EncodeToString(sum[:]))
+
+	var count int64
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc := counterDoc{Key: key, ExpiresAt: expiresAt}
+		snap, err := tx.Get(ref)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			if err := snap.DataTo(&doc); err != nil {
+				return err
+			}
+		}
+		doc.Count += n
+		count = doc.Count
+		return tx.Set(ref, doc)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment quota counter %s: %v", key, err)
+	}
+	return count, nil
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,54 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// MemoryStore keeps counters in process memory. It is intended for local
+// development and single-instance deployments; counters are lost on
+// restart and not shared between instances.
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]counter
+	lastPrune time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]counter)}
+}
+
+func (s *MemoryStore) Increment(_ context.Context, key string, n int64, expiresAt time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	c, ok := s.counters[key]
+	if !ok || now.After(c.expiresAt) {
+		c = counter{}
+		s.prune(now)
+	}
+	c.count += n
+	c.expiresAt = expiresAt
+	s.counters[key] = c
+	return c.count, nil
+}
+
+// prune drops expired counters at most once a minute.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	s.lastPrune = now
+	for key, c := range s.counters {
+		if now.After(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Window is a calendar period over which requests are counted. Periods
+// follow UTC calendar days and months.
+type Window string
+
+const (
+	Daily   Window = "daily"
+	Monthly Window = "monthly"
+)
+
+// Store keeps request counters. Keys identify a subject and a period.
+type Store interface {
+	// Increment adds n to the counter for key and returns the new total.
+	// The counter may be discarded after expiresAt.
+	Increment(ctx context.Context, key string, n int64, expiresAt time.Time) (int64, error)
+}
+
+// Usage is the state of one quota window for a subject.
+type Usage struct {
+	Window  Window
+	Limit   int64
+	Used    int64
+	ResetAt time.Time
+}
+
+// Remaining returns the requests left in the window, never below zero.
+func (u Usage) Remaining() int64 {
+	if u.Used >= u.Limit {
+		return 0
+	}
+	return u.Limit - u.Used
+}
+
+// Exceeded reports whether the request that brought the counter to Used
+// went over the limit.
+func (u Usage) Exceeded() bool {
+	return u.Used > u.Limit
+}
+
+// Limiter enforces daily and monthly request quotas per subject. A zero
+// limit disables that window.
+type Limiter struct {
+	store   Store
+	daily   int64
+	monthly int64
+}
+
+func NewLimiter(store Store, daily, monthly int64) *Limiter {
+	return &Limiter{store: store, daily: daily, monthly: monthly}
+}
+
+// WithLimits returns a limiter sharing the store of l whose non-zero
+// limits replace those of l.
+func (l *Limiter) WithLimits(daily, monthly int64) *Limiter {
+	result := *l
+	if daily > 0 {
+		result.daily = daily
+	}
+	if monthly > 0 {
+		result.monthly = monthly
+	}
+	return &result
+}
+
+// Enabled reports whether any window is limited.
+func (l *Limiter) Enabled() bool {
+	return l.daily > 0 || l.monthly > 0
+}
+
+// Consume counts one request for subject in every limited window and
+// returns the resulting usage, daily window first.
+func (l *Limiter) Consume(ctx context.Context, subject string, now time.Time) ([]Usage, error) {
+	return l.ConsumeN(ctx, subject, 1, now)
+}
+
+// ConsumeN counts n requests for subject like Consume.
+func (l *Limiter) ConsumeN(ctx context.Context, subject string, n int64, now time.Time) ([]Usage, error) {
+	now = now.UTC()
+	var usages []Usage
+	for _, window := range []Window{Daily, Monthly} {
+		limit := l.limit(window)
+		if limit <= 0 {
+			continue
+		}
+
+		period, resetAt := periodOf(window, now)
+		// Keep counters a day past the reset so late requests near the
+		// boundary still find them.
+		used, err := l.store.Increment(ctx, subject+"|"+period, n, resetAt.Add(24*time.Hour))
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s quota: %v", window, err)
+		}
+		usages = append(usages, Usage{Window: window, Limit: limit, Used: used, ResetAt: resetAt})
+	}
+	return usages, nil
+}
+
+func (l *Limiter) limit(window Window) int64 {
+	switch window {
+	case Daily:
+		return l.daily
+	case Monthly:
+		return l.monthly
+	default:
+		return 0
+	}
+}
+
+// Tightest returns the usage with the fewest remaining requests, preferring
+// the earlier reset on ties. It reports false when usages is empty.
+func Tightest(usages []Usage) (Usage, bool) {
+	if len(usages) == 0 {
+		return Usage{}, false
+	}
+	tightest := usages[0]
+	for _, u := range usages[1:] {
+		if u.Exceeded() && !tightest.Exceeded() {
+			tightest = u
+			continue
+		}
+		if u.Remaining() < tightest.Remaining() ||
+			(u.Remaining() == tightest.Remaining() && u.ResetAt.Before(tightest.ResetAt)) {
+			tightest = u
+		}
+	}
+	return tightest, true
+}
+
+// periodOf names the period containing now and returns when it ends.
+func periodOf(window Window, now time.Time) (string, time.Time) {
+	year, month, day := now.Date()
+	switch window {
+	case Daily:
+		start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return "d" + start.Format("2006-01-02"), start.AddDate(0, 0, 1)
+	case Monthly:
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return "m" + start.Format("2006-01"), start.AddDate(0, 1, 0)
+	default:
+		return string(window), now
+	}
+}
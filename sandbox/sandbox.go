@@ -0,0 +1,124 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/semaphore"
+)
+
+var (
+	// ErrSaturated is returned when no worker or memory became free within
+	// the queue wait; retrying later may succeed.
+	ErrSaturated = errors.New("converter is busy")
+	// ErrTooLarge is returned when a conversion is estimated to need more
+	// memory than the whole pool has.
+	ErrTooLarge = errors.New("document is too large to convert")
+	// ErrTimeout is returned when a conversion runs past the wall-clock
+	// limit.
+	ErrTimeout = errors.New("conversion timed out")
+)
+
+const (
+	// expansion is how many bytes of memory a conversion is assumed to
+	// need per input byte: the parsed tree, the rendered XHTML, and the
+	// zipped book.
+	expansion = 20
+	// overhead is the memory assumed for any conversion.
+	overhead = 4 << 20
+)
+
+// Pool bounds the conversions running at once by count and by estimated
+// memory, so that one pathological document cannot degrade all concurrent
+// requests. A nil Pool runs conversions without limits.
+type Pool struct {
+	workers   *semaphore.Weighted
+	memory    *semaphore.Weighted
+	limit     int64
+	queueWait time.Duration
+	timeout   time.Duration
+}
+
+// New returns a pool of workers conversions sharing memory bytes. A
+// conversion waits up to queueWait for capacity and is abandoned after
+// timeout.
+func New(workers int, memory int64, queueWait, timeout time.Duration) *Pool {
+	return &Pool{
+		workers:   semaphore.NewWeighted(int64(workers)),
+		memory:    semaphore.NewWeighted(memory),
+		limit:     memory,
+		queueWait: queueWait,
+		timeout:   timeout,
+	}
+}
+
+// Estimate returns the memory a conversion of a document of size bytes is
+// assumed to need.
+func Estimate(size int) int64 {
+	return int64(size)*expansion + overhead
+}
+
+// MaxDuration returns the longest Run waits and converts before it returns,
+// or 0 for a nil Pool, which sets no limit.
+func (p *Pool) MaxDuration() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.queueWait + p.timeout
+}
+
+// Run calls fn once a worker and estimate bytes are free. A conversion that
+// times out keeps its worker and memory until fn returns, since it cannot
+// be interrupted, so the pool never admits more work than it can hold.
+// Panics in fn are returned as errors.
+func (p *Pool) Run(ctx context.Context, estimate int64, fn func() error) error {
+	if p == nil {
+		return fn()
+	}
+	if estimate > p.limit {
+		return fmt.Errorf("%w: needs an estimated %d MiB of %d MiB", ErrTooLarge, estimate>>20, p.limit>>20)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, p.queueWait)
+	defer cancel()
+	if err := p.workers.Acquire(waitCtx, 1); err != nil {
+		return busy(ctx)
+	}
+	if err := p.memory.Acquire(waitCtx, estimate); err != nil {
+		p.workers.Release(1)
+		return busy(ctx)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer p.workers.Release(1)
+		defer p.memory.Release(estimate)
+		defer func() {
+			if v := recover(); v != nil {
+				done <- fmt.Errorf("conversion panicked: %v", v)
+			}
+		}()
+		done <- fn()
+	}()
+
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrTimeout
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// busy returns the caller's own cancellation in preference to ErrSaturated.
+func busy(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrSaturated
+}
@@ -0,0 +1,378 @@
+// Package server assembles the HTTP API from the configuration, so that the
+// command and tests serve the same routes and middleware.
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/accesslog"
+	"go.ngs.io/jplaw2epub-web-api/audit"
+	"go.ngs.io/jplaw2epub-web-api/auth"
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/fonts"
+	"go.ngs.io/jplaw2epub-web-api/furigana"
+	"go.ngs.io/jplaw2epub-web-api/graphql"
+	"go.ngs.io/jplaw2epub-web-api/handlers"
+	"go.ngs.io/jplaw2epub-web-api/jobs"
+	"go.ngs.io/jplaw2epub-web-api/library"
+	"go.ngs.io/jplaw2epub-web-api/mailer"
+	"go.ngs.io/jplaw2epub-web-api/naming"
+	"go.ngs.io/jplaw2epub-web-api/objects"
+	"go.ngs.io/jplaw2epub-web-api/presets"
+	"go.ngs.io/jplaw2epub-web-api/quota"
+	"go.ngs.io/jplaw2epub-web-api/sandbox"
+	"go.ngs.io/jplaw2epub-web-api/tenant"
+	"go.ngs.io/jplaw2epub-web-api/translation"
+	"go.ngs.io/jplaw2epub-web-api/upstream"
+)
+
+// Server is the HTTP API with the services behind it.
+type Server struct {
+	// Handler serves every route with the configured middleware.
+	Handler  http.Handler
+	Resolver *graphql.Resolver
+	// Tracker records the calls to the e-Gov API.
+	Tracker        *upstream.Tracker
+	Limiter        *quota.Limiter
+	Tenants        tenant.Store
+	TrustedProxies handlers.TrustedProxies
+
+	cfg  *config.Config
+	mail mailer.Mailer
+}
+
+// New builds the API for cfg with the resolver's dependencies in deps. The
+// EPUB bucket is opened through deps.Storage, and the law data endpoints
+// call cfg.Upstream.BaseURL. port is the one /health reports.
+func New(cfg *config.Config, deps graphql.Dependencies, port int) (*Server, error) {
+	allowedOrigins := cfg.CORSOrigins
+	if err := handlers.ValidateAllowedOrigins(allowedOrigins); err != nil {
+		return nil, fmt.Errorf("invalid CORS configuration: %v", err)
+	}
+	trustedProxies, err := handlers.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %v", err)
+	}
+	filenames, err := naming.Parse(cfg.FilenameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EPUB filename template: %v", err)
+	}
+
+	// Create a new mux for better control over middleware.
+	mux := http.NewServeMux()
+	// Routes for browsers are registered with their CORS options, as
+	// overridden by the configuration, and reported by the corsConfig
+	// query.
+	corsOverrides := make(map[string]handlers.CORSOptions, len(cfg.CORSRoutes))
+	for pattern, route := range cfg.CORSRoutes {
+		corsOverrides[pattern] = handlers.CORSOptions{
+			Methods:       route.Methods,
+			Headers:       route.Headers,
+			ExposeHeaders: route.ExposeHeaders,
+			MaxAge:        route.MaxAge,
+		}
+	}
+	cors := handlers.NewCORSMux(mux, allowedOrigins, corsOverrides)
+
+	// Register handlers with CORS middleware.
+	cors.Handle("/health", handlers.NewHealthHandler(port), handlers.DefaultCORSOptions())
+
+	// EPUB bucket for the bucket-backed stores, the attachment cache, and
+	// downloads, when storage is available.
+	var bucket objects.Bucket
+	if deps.Storage != nil && cfg.BucketName != "" {
+		bucket = deps.Storage.Bucket(cfg.BucketName)
+	}
+
+	// Job metadata store for EPUB generation.
+	jobStore, err := jobs.NewStore(context.Background(), jobs.StoreConfig{
+		Backend:    cfg.JobStore,
+		Bucket:     bucket,
+		Prefix:     graphql.APP_VERSION,
+		ProjectID:  cfg.ProjectID,
+		Collection: cfg.JobStoreCollection,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize job store: %v", err)
+	}
+
+	// Named converter presets, kept in the same backend as job metadata.
+	presetStore, err := presets.NewStore(context.Background(), presets.StoreConfig{
+		Backend:    cfg.JobStore,
+		Bucket:     bucket,
+		Prefix:     graphql.APP_VERSION,
+		ProjectID:  cfg.ProjectID,
+		Collection: cfg.PresetCollection,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize preset store: %v", err)
+	}
+
+	// Users' bookmarks and saved searches, kept in the same backend as job
+	// metadata.
+	libraryStore, err := library.NewStore(context.Background(), library.StoreConfig{
+		Backend:    cfg.JobStore,
+		Bucket:     bucket,
+		Prefix:     graphql.APP_VERSION,
+		ProjectID:  cfg.ProjectID,
+		Collection: cfg.LibraryCollection,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize library store: %v", err)
+	}
+
+	// Daily and monthly request quotas per API key, origin, or address.
+	quotaStore, err := quota.NewStore(context.Background(), quota.StoreConfig{
+		Backend:    cfg.Quota.Store,
+		ProjectID:  cfg.ProjectID,
+		Collection: cfg.Quota.Collection,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize quota store: %v", err)
+	}
+	limiter := quota.NewLimiter(quotaStore, cfg.Quota.Daily, cfg.Quota.Monthly)
+	// HIGH priority generations per client, counted in the same store.
+	priorityLimiter := quota.NewLimiter(quotaStore, cfg.Quota.HighPriorityDaily, 0)
+	// Completion emails to a notifyEmail address per client.
+	notifyLimiter := quota.NewLimiter(quotaStore, cfg.Quota.NotifyDaily, 0)
+
+	// Tenants identified by API key, with their own storage directory,
+	// quotas, and usage statistics.
+	var tenants tenant.Store
+	if cfg.Tenants.Store != "" {
+		tenants, err = tenant.NewStore(context.Background(), tenant.StoreConfig{
+			Backend:    cfg.Tenants.Store,
+			File:       cfg.Tenants.File,
+			ProjectID:  cfg.ProjectID,
+			Collection: cfg.Tenants.Collection,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize tenant store: %v", err)
+		}
+	}
+	// Users signed in with an OpenID Connect provider get their own quota,
+	// bookmarks, and history.
+	var verifier *auth.Verifier
+	if len(cfg.OIDC.Providers) > 0 {
+		providers := make([]auth.Provider, len(cfg.OIDC.Providers))
+		for i, provider := range cfg.OIDC.Providers {
+			providers[i] = auth.Provider{Issuer: provider.Issuer, Audiences: provider.Audiences}
+		}
+		verifier = auth.NewVerifier(providers)
+	}
+	quotaOptions := handlers.QuotaOptions{
+		APIKeys:        cfg.Quota.APIKeys,
+		AllowedOrigins: allowedOrigins,
+	}
+	withQuota := func(next http.Handler) http.Handler {
+		return handlers.WithTenant(handlers.WithUserAuth(handlers.WithQuota(next, limiter, quotaOptions), verifier, false), tenants)
+	}
+	// GraphQL clients get exceeded quotas as coded GraphQL errors.
+	graphqlQuotaOptions := quotaOptions
+	graphqlQuotaOptions.GraphQL = true
+	withGraphQLQuota := func(next http.Handler) http.Handler {
+		return handlers.WithTenant(handlers.WithUserAuth(handlers.WithQuota(next, limiter, graphqlQuotaOptions), verifier, true), tenants)
+	}
+
+	// Calls to the e-Gov API, counted against its rate limit.
+	upstreamURL := cfg.Upstream.BaseURL
+	tracker := upstream.NewTracker(cfg.Upstream.RateLimit, cfg.Upstream.RateWindow)
+
+	// Attachment proxy, cached in the EPUB bucket.
+	attachments := handlers.NewAttachmentsHandler(upstream.NewLawDataClient(tracker, upstreamURL), bucket)
+	cors.Handle("/attachments/{revisionId}/{src...}", withQuota(attachments), handlers.DownloadCORSOptions())
+
+	// Raw law XML for clients running their own converters, cached in the
+	// same bucket.
+	lawXML := handlers.NewLawXMLHandler(upstream.NewLawDataClient(tracker, upstreamURL), bucket)
+	cors.Handle("/laws/{file}", withQuota(lawXML), handlers.DownloadCORSOptions())
+
+	// Audit log of document generation requests.
+	auditLogger, err := audit.NewLogger(cfg.AuditLog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize audit log: %v", err)
+	}
+
+	// English law titles for results and EPUB metadata.
+	titles, err := translation.Load(cfg.TranslationsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load translations: %v", err)
+	}
+	if titles.Len() > 0 {
+		log.Printf("Loaded English titles of %d laws", titles.Len())
+	}
+
+	// Ruby readings for EPUB and HTML output on request.
+	annotator, err := furigana.New(cfg.Furigana.Analyzer, cfg.Furigana.Command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize furigana: %v", err)
+	}
+
+	// Fonts that converted EPUBs can embed.
+	fontLibrary, err := fonts.Load(context.Background(), cfg.Converter.FontDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fonts: %v", err)
+	}
+	if fontLibrary.Len() > 0 {
+		log.Printf("Loaded %d fonts", fontLibrary.Len())
+	}
+
+	// Completion emails for generations requested with a notification;
+	// callbacks are posted by the resolver when WEBHOOK_SECRET is set.
+	mail, err := mailer.New(mailer.Config{
+		Provider:       cfg.Mail.Provider,
+		From:           cfg.Mail.From,
+		SMTPHost:       cfg.Mail.SMTP.Host,
+		SMTPPort:       cfg.Mail.SMTP.Port,
+		SMTPUsername:   cfg.Mail.SMTP.Username,
+		SMTPPassword:   cfg.Mail.SMTP.Password,
+		SendGridAPIKey: cfg.Mail.SendGridAPIKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize mailer: %v", err)
+	}
+
+	// GraphQL handlers.
+	// In-process conversions share a bounded pool, so that one large or
+	// pathological document cannot starve the others.
+	pool := sandbox.New(cfg.Converter.Workers, cfg.Converter.MemoryLimit, cfg.Converter.QueueWait, cfg.Converter.Timeout)
+
+	resolver := graphql.NewResolver(cfg, deps, graphql.Deps{
+		Jobs:            jobStore,
+		Presets:         presetStore,
+		Library:         libraryStore,
+		CORSRoutes:      cors.Routes,
+		Audit:           auditLogger,
+		Titles:          titles,
+		Furigana:        annotator,
+		Mailer:          mail,
+		Pool:            pool,
+		Tracker:         tracker,
+		UpstreamURL:     upstreamURL,
+		Filenames:       filenames,
+		PriorityLimiter: priorityLimiter,
+		NotifyLimiter:   notifyLimiter,
+		Fonts:           fontLibrary,
+	})
+	allowList, err := graphql.LoadAllowList(cfg.GraphQL.OperationAllowList, cfg.GraphQL.OperationManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load operation allow-list: %v", err)
+	}
+	if allowList != nil {
+		log.Printf("GraphQL operations are checked against %d approved operations (%s)", allowList.Len(), cfg.GraphQL.OperationAllowList)
+	}
+	srv := graphql.NewServer(graphql.NewExecutableSchema(graphql.Config{Resolvers: resolver}), cfg, allowList, resolver.SlowQueries())
+	cors.Handle("/graphql", withGraphQLQuota(handlers.WithAdminToken(handlers.WithLocale(graphql.WithCacheControl(srv, cfg.GraphQL.ResponseCacheSize)), cfg.AdminToken)), handlers.DefaultCORSOptions())
+	mux.Handle("/graphiql", handlers.NewPlaygroundHandler("/graphql", cfg.GraphQL.Playground, cfg.AdminToken))
+
+	// Pre-generation of popular EPUBs, triggered by Cloud Scheduler or a
+	// ticker.
+	mux.Handle("/admin/warmup", handlers.WithAdminToken(handlers.NewWarmUpHandler(resolver), cfg.AdminToken))
+
+	// Detection of EPUBs outdated by amendments.
+	mux.Handle("/admin/revalidate", handlers.WithAdminToken(handlers.NewRevalidateHandler(resolver), cfg.AdminToken))
+	// Deletion of idle EPUBs, stale jobs, and obsolete versions.
+	mux.Handle("/admin/cleanup", handlers.WithAdminToken(handlers.NewCleanupHandler(resolver), cfg.AdminToken))
+	mux.Handle("/admin/metrics", handlers.WithAdminToken(handlers.NewMetricsHandler(), cfg.AdminToken))
+
+	// Law downloads with the format chosen by the Accept header.
+	epubs := handlers.NewEpubsHandler(resolver, upstream.NewLawDataClient(tracker, upstreamURL), graphql.APP_VERSION, annotator, pool, filenames)
+	cors.Handle("/epubs/{id}", withQuota(epubs), handlers.DownloadCORSOptions())
+
+	// Versioned REST API on top of the same resolver, described by an
+	// OpenAPI document.
+	cors.Handle("/v1/", withQuota(handlers.NewRESTHandler(resolver)), handlers.DefaultCORSOptions())
+	cors.Handle("/openapi.json", handlers.OpenAPIHandler(graphql.APP_VERSION), handlers.DefaultCORSOptions())
+
+	// Atom feed of new and amended laws.
+	cors.Handle("/feeds/updates.xml", handlers.NewUpdatesFeedHandler(resolver), handlers.DownloadCORSOptions())
+
+	// Resumable downloads of stored documents.
+	downloads := handlers.NewDownloadHandler(bucket, graphql.APP_VERSION, filenames, resolver)
+	cors.Handle("/download/{id}", withQuota(downloads), handlers.DownloadCORSOptions())
+
+	// Fixity checks of stored documents against their recorded SHA-256.
+	cors.Handle("/verify/{id}", withQuota(handlers.NewVerifyHandler(resolver)), handlers.DefaultCORSOptions())
+
+	// Validation of law XML before uploading it for conversion.
+	cors.Handle("/convert/validate", withQuota(handlers.NewValidateHandler(cfg.GraphQL.MaxUploadSize, pool)), handlers.DefaultCORSOptions())
+
+	// OPDS catalog for e-reader apps.
+	opds := withQuota(handlers.NewOPDSHandler(resolver))
+	cors.Handle("/opds", opds, handlers.DownloadCORSOptions())
+	cors.Handle("/opds/", opds, handlers.DownloadCORSOptions())
+	if unknown := cors.Unregistered(); len(unknown) > 0 {
+		return nil, fmt.Errorf("invalid CORS configuration: no route %s", strings.Join(unknown, ", "))
+	}
+
+	// Compress text responses, then wrap with Apache logger middleware
+	// unless disabled. The client address is resolved first, so that logs,
+	// quotas, and audit entries agree on it.
+	var finalHandler http.Handler = handlers.WithCompression(mux)
+	if !cfg.DisableAccessLog {
+		format := handlers.AccessLogFormat(cfg.AccessLog.Format)
+		output := cfg.AccessLog.Output
+		if output == "" && format != handlers.AccessLogApache {
+			// The standard logger's time prefix would break JSON lines.
+			output = "stdout"
+		}
+		if err := handlers.ValidateJSONFields(cfg.AccessLog.JSONFields); err != nil {
+			return nil, fmt.Errorf("invalid access log configuration: %v", err)
+		}
+		accessLog, err := accesslog.Open(output, cfg.AccessLog.MaxFileSize, cfg.AccessLog.MaxFileBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open access log: %v", err)
+		}
+		finalHandler = handlers.WithAccessLog(finalHandler, handlers.AccessLogOptions{
+			Format:     format,
+			Output:     accessLog,
+			JSONFields: cfg.AccessLog.JSONFields,
+			GraphQL: handlers.GraphQLLogOptions{
+				MaxBodySize: cfg.AccessLog.MaxBodySize,
+				Variables:   cfg.AccessLog.Variables,
+				Redact:      cfg.AccessLog.RedactVariables,
+			},
+		})
+	}
+	finalHandler = handlers.WithRealIP(finalHandler, trustedProxies)
+
+	return &Server{
+		Handler:        finalHandler,
+		Resolver:       resolver,
+		Tracker:        tracker,
+		Limiter:        limiter,
+		Tenants:        tenants,
+		TrustedProxies: trustedProxies,
+		cfg:            cfg,
+		mail:           mail,
+	}, nil
+}
+
+// Start runs the configured background work until ctx is done: the law
+// title index, notifications, warm-up, revalidation, cleanup, and the
+// dispatch of queued generations.
+func (s *Server) Start(ctx context.Context) {
+	// Autocomplete index of law titles.
+	if s.cfg.LawIndex.Interval > 0 {
+		go s.Resolver.RunLawIndexSync(ctx, s.cfg.LawIndex.Interval)
+	}
+	if s.mail != nil || s.cfg.Webhook.Secret != "" {
+		go s.Resolver.RunNotifications(ctx, s.cfg.NotifyInterval)
+	}
+	if s.cfg.WarmUp.Interval > 0 {
+		go s.Resolver.RunWarmUp(ctx, s.cfg.WarmUp.Interval)
+	}
+	if s.cfg.Revalidate.Interval > 0 {
+		go s.Resolver.RunRevalidation(ctx, s.cfg.Revalidate.Interval)
+	}
+	if s.cfg.Cleanup.Interval > 0 {
+		go s.Resolver.RunCleanup(ctx, s.cfg.Cleanup.Interval)
+	}
+	if s.cfg.Dispatch.Concurrency > 0 {
+		go s.Resolver.RunDispatcher(ctx, s.cfg.Dispatch.Interval)
+	}
+}
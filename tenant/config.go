@@ -0,0 +1,37 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+)
+
+// StoreConfig selects and configures a Store backend.
+type StoreConfig struct {
+	// Backend is "file" or "firestore".
+	Backend string
+	// File is the YAML definition file for the file store.
+	File string
+	// ProjectID and Collection locate documents for the firestore store.
+	ProjectID  string
+	Collection string
+}
+
+// NewStore creates the store selected by cfg.Backend.
+func NewStore(ctx context.Context, cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "file":
+		store, err := NewFileStore(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "firestore":
+		store, err := NewFirestoreStore(ctx, cfg.ProjectID, cfg.Collection)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("unknown tenant store %q (expected file or firestore)", cfg.Backend)
+	}
+}
@@ -0,0 +1,67 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileStore holds tenants read from a YAML definition file at startup:
+//
+//	tenants:
+//	  - id: law-school-a
+//	    name: Law School A
+//	    apiKeys: [key-1, key-2]
+//	    daily: 5000
+//	    monthly: 100000
+type FileStore struct {
+	byKey map[string]*Tenant
+}
+
+// definitionFile is the layout of a tenants definition file.
+type definitionFile struct {
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+// NewFileStore reads and validates a tenants definition file. Tenant IDs
+// and API keys must be unique.
+func NewFileStore(path string) (*FileStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants file: %v", err)
+	}
+	var file definitionFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants file %s: %v", path, err)
+	}
+
+	store := &FileStore{byKey: make(map[string]*Tenant)}
+	seen := make(map[string]bool)
+	for i := range file.Tenants {
+		t := &file.Tenants[i]
+		if err := ValidateID(t.ID); err != nil {
+			return nil, fmt.Errorf("tenants file %s: %v", path, err)
+		}
+		if seen[t.ID] {
+			return nil, fmt.Errorf("tenants file %s: duplicate tenant ID %q", path, t.ID)
+		}
+		seen[t.ID] = true
+		if t.Daily < 0 || t.Monthly < 0 {
+			return nil, fmt.Errorf("tenants file %s: tenant %q has a negative quota", path, t.ID)
+		}
+		for _, key := range t.APIKeys {
+			hash := HashKey(key)
+			if other, ok := store.byKey[hash]; ok {
+				return nil, fmt.Errorf("tenants file %s: tenants %q and %q share an API key", path, other.ID, t.ID)
+			}
+			store.byKey[hash] = t
+		}
+	}
+	return store, nil
+}
+
+func (s *FileStore) Lookup(_ context.Context, apiKey string) (*Tenant, error) {
+	return s.byKey[HashKey(apiKey)], nil
+}
@@ -0,0 +1,76 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// cacheTTL is how long a lookup result, including a miss, is reused before
+// Firestore is queried again.
+const cacheTTL = time.Minute
+
+// FirestoreStore reads tenants from a Firestore collection with one
+// document per tenant, named by tenant ID. API keys are stored as hex
+// SHA-256 digests in the apiKeyHashes array; looking them up requires the
+// single-field index that Firestore creates by default.
+type FirestoreStore struct {
+	client     *firestore.Client
+	collection string
+
+	mu    sync.Mutex
+	cache map[string]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant    *Tenant
+	expiresAt time.Time
+}
+
+func NewFirestoreStore(ctx context.Context, projectID, collection string) (*FirestoreStore, error) {
+	client, err := firestore.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create firestore client: %v", err)
+	}
+	return &FirestoreStore{client: client, collection: collection, cache: make(map[string]cachedTenant)}, nil
+}
+
+func (s *FirestoreStore) Lookup(ctx context.Context, apiKey string) (*Tenant, error) {
+	hash := HashKey(apiKey)
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.cache[hash]
+	s.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.tenant, nil
+	}
+
+	snaps, err := s.client.Collection(s.collection).Where("apiKeyHashes", "array-contains", hash).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up tenant: %v", err)
+	}
+	var t *Tenant
+	if len(snaps) > 0 {
+		t = &Tenant{}
+		if err := snaps[0].DataTo(t); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant %s: %v", snaps[0].Ref.ID, err)
+		}
+		t.ID = snaps[0].Ref.ID
+		if err := ValidateID(t.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[hash] = cachedTenant{tenant: t, expiresAt: now.Add(cacheTTL)}
+	s.mu.Unlock()
+	return t, nil
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
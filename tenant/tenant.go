@@ -0,0 +1,132 @@
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// dir is the storage directory holding one subdirectory per tenant.
+const dir = "tenants"
+
+type contextKey struct{}
+
+type userContextKey struct{}
+
+// Tenant is an organization whose documents, quotas, and usage statistics
+// are kept apart from those of other tenants. A zero limit falls back to
+// the server-wide quota for that window.
+type Tenant struct {
+	ID      string   `yaml:"id" firestore:"-"`
+	Name    string   `yaml:"name" firestore:"name"`
+	APIKeys []string `yaml:"apiKeys" firestore:"-"`
+	Daily   int64    `yaml:"daily" firestore:"daily"`
+	Monthly int64    `yaml:"monthly" firestore:"monthly"`
+}
+
+// Store resolves API keys to tenants.
+type Store interface {
+	// Lookup returns the tenant owning apiKey, or nil when no tenant does.
+	Lookup(ctx context.Context, apiKey string) (*Tenant, error)
+}
+
+var (
+	idPattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	userIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@+-]{0,127}$`)
+)
+
+// ValidateID reports whether id can name a tenant. IDs become part of
+// storage paths, so they are limited to lowercase letters, digits, and
+// hyphens.
+func ValidateID(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("invalid tenant ID %q (expected lowercase letters, digits, and hyphens)", id)
+	}
+	return nil
+}
+
+// ValidateUserID reports whether id can name a user of a tenant. User IDs
+// are chosen by the tenant's own sign-in, such as an OpenID subject, and
+// become part of storage paths.
+func ValidateUserID(id string) error {
+	if !userIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid user ID %q (expected up to 128 letters, digits, and . _ @ + -)", id)
+	}
+	return nil
+}
+
+// HashKey returns the hex SHA-256 digest under which an API key is
+// indexed, so that keys need not be stored in plain text.
+func HashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// WithContext returns a copy of ctx carrying the authenticated tenant.
+func WithContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the tenant recorded by WithContext, or nil for
+// requests outside any tenant.
+func FromContext(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(contextKey{}).(*Tenant)
+	return t
+}
+
+// IDFromContext returns the ID of the tenant in ctx, or "" when there is
+// none.
+func IDFromContext(ctx context.Context) string {
+	if t := FromContext(ctx); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// WithUser returns a copy of ctx carrying the ID of the tenant's user on
+// whose behalf the request is made.
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, userID)
+}
+
+// UserIDFromContext returns the user recorded by WithUser, or "" when the
+// request names no user.
+func UserIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(userContextKey{}).(string)
+	return id
+}
+
+// Prefix returns the storage prefix of a tenant's objects below base, or
+// base itself when tenantID is empty.
+func Prefix(base, tenantID string) string {
+	if tenantID == "" {
+		return base
+	}
+	return base + "/" + dir + "/" + tenantID
+}
+
+// ScopedID returns the job ID of a tenant's document, which is id within
+// the tenant's storage directory. Documents outside any tenant keep id.
+func ScopedID(tenantID, id string) string {
+	if tenantID == "" {
+		return id
+	}
+	return dir + "/" + tenantID + "/" + id
+}
+
+// SplitID is the inverse of ScopedID. It returns an empty tenant ID for
+// documents outside any tenant.
+func SplitID(scopedID string) (string, string) {
+	rest, ok := strings.CutPrefix(scopedID, dir+"/")
+	if !ok {
+		return "", scopedID
+	}
+	tenantID, id, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", scopedID
+	}
+	return tenantID, id
+}
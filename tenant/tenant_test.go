@@ -0,0 +1,69 @@
+package tenant
+
+import "testing"
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"acme", true},
+		{"law-school-a", true},
+		{"0", true},
+		{"a23456789012345678901234567890123456789012345678901234567890123", true},
+		{"", false},
+		{"-acme", false},
+		{"Acme", false},
+		{"acme_a", false},
+		{"acme/other", false},
+		{"..", false},
+		{"a234567890123456789012345678901234567890123456789012345678901234", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateID(tt.id); (err == nil) != tt.valid {
+			t.Errorf("ValidateID(%q) = %v, want valid %t", tt.id, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateUserID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"user@example.com", true},
+		{"1234567890", true},
+		{"a.b_c+d-e", true},
+		{"", false},
+		{".hidden", false},
+		{"a/b", false},
+		{"a\\b", false},
+		{"a b", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateUserID(tt.id); (err == nil) != tt.valid {
+			t.Errorf("ValidateUserID(%q) = %v, want valid %t", tt.id, err, tt.valid)
+		}
+	}
+}
+
+func TestScopedID(t *testing.T) {
+	tests := []struct {
+		tenantID, id, scoped string
+	}{
+		{"", "325AC0000000131", "325AC0000000131"},
+		{"acme", "325AC0000000131", "tenants/acme/325AC0000000131"},
+	}
+	for _, tt := range tests {
+		if got := ScopedID(tt.tenantID, tt.id); got != tt.scoped {
+			t.Errorf("ScopedID(%q, %q) = %q, want %q", tt.tenantID, tt.id, got, tt.scoped)
+		}
+		tenantID, id := SplitID(tt.scoped)
+		if tenantID != tt.tenantID || id != tt.id {
+			t.Errorf("SplitID(%q) = %q, %q, want %q, %q", tt.scoped, tenantID, id, tt.tenantID, tt.id)
+		}
+	}
+	if got := Prefix("v1.0.0", "acme"); got != "v1.0.0/tenants/acme" {
+		t.Errorf("Prefix(v1.0.0, acme) = %q", got)
+	}
+}
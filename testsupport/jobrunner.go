@@ -0,0 +1,79 @@
+package testsupport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Run is an execution of the EPUB generator requested from a JobRunner.
+type Run struct {
+	Name string
+	// Version is the converter version whose generator was asked for.
+	Version string
+	Args    []string
+	Env     map[string]string
+}
+
+// Flag returns the value of a flag such as --revision-id in the arguments,
+// or "" when it is not given.
+func (r Run) Flag(name string) string {
+	i := slices.Index(r.Args, name)
+	if i < 0 || i+1 >= len(r.Args) {
+		return ""
+	}
+	return r.Args[i+1]
+}
+
+// JobRunner records the generator executions it is asked for instead of
+// starting them. A handler set with OnRun can stand in for the generator,
+// for example by writing an EPUB to a Storage.
+type JobRunner struct {
+	mu      sync.Mutex
+	runs    []Run
+	handler func(ctx context.Context, run Run) error
+}
+
+// NewJobRunner returns a runner that accepts every execution.
+func NewJobRunner() *JobRunner {
+	return &JobRunner{}
+}
+
+// OnRun sets a function called with every execution before it is reported
+// as started; its error fails the execution.
+func (j *JobRunner) OnRun(handler func(ctx context.Context, run Run) error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.handler = handler
+}
+
+func (j *JobRunner) RunGenerator(ctx context.Context, version string, args []string, env map[string]string) (string, error) {
+	j.mu.Lock()
+	run := Run{
+		Name:    fmt.Sprintf("executions/fake-%d", len(j.runs)+1),
+		Version: version,
+		Args:    slices.Clone(args),
+		Env:     make(map[string]string, len(env)),
+	}
+	for name, value := range env {
+		run.Env[name] = value
+	}
+	j.runs = append(j.runs, run)
+	handler := j.handler
+	j.mu.Unlock()
+
+	if handler != nil {
+		if err := handler(ctx, run); err != nil {
+			return "", fmt.Errorf("failed to execute Cloud Run Job: %v", err)
+		}
+	}
+	return run.Name, nil
+}
+
+// Runs returns the executions requested so far, oldest first.
+func (j *JobRunner) Runs() []Run {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return slices.Clone(j.runs)
+}
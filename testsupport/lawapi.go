@@ -0,0 +1,202 @@
+package testsupport
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	jplaw "go.ngs.io/jplaw-api-v2"
+)
+
+// LawAPI is an in-memory e-Gov law list, revision, and keyword search API
+// serving the laws added to it.
+type LawAPI struct {
+	mu   sync.Mutex
+	laws []fakeLaw
+	err  error
+	// calls counts the calls by method name.
+	calls map[string]int
+}
+
+type fakeLaw struct {
+	item      jplaw.LawItem
+	revisions []jplaw.RevisionInfo
+	sentences []string
+}
+
+// NewLawAPI returns an API without laws.
+func NewLawAPI() *LawAPI {
+	return &LawAPI{calls: make(map[string]int)}
+}
+
+// AddLaw adds a law with its current revision in item, and the text of its
+// sentences for keyword search.
+func (a *LawAPI) AddLaw(item jplaw.LawItem, sentences ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.laws = append(a.laws, fakeLaw{item: item, sentences: sentences})
+}
+
+// AddRevision adds a past revision to the law with lawID. Revisions are
+// listed newest first, after the current one, in the order they are added.
+func (a *LawAPI) AddRevision(lawID string, revision jplaw.RevisionInfo) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	law := a.find(lawID)
+	if law == nil {
+		return fmt.Errorf("law %s has not been added", lawID)
+	}
+	law.revisions = append(law.revisions, revision)
+	return nil
+}
+
+// FailWith makes every call fail with err until it is called with nil. An
+// error mentioning 429 is counted as throttling.
+func (a *LawAPI) FailWith(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.err = err
+}
+
+// Calls returns how many times method, such as GetLaws, was called.
+func (a *LawAPI) Calls(method string) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.calls[method]
+}
+
+func (a *LawAPI) GetLaws(params *jplaw.GetLawsParams) (*jplaw.LawsResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls["GetLaws"]++
+	if a.err != nil {
+		return nil, a.err
+	}
+	if params == nil {
+		params = &jplaw.GetLawsParams{}
+	}
+	var matched []jplaw.LawItem
+	for _, law := range a.laws {
+		info, revision := law.item.LawInfo, law.item.RevisionInfo
+		if params.LawId != nil && (info == nil || info.LawId != *params.LawId) {
+			continue
+		}
+		if params.LawNum != nil && (info == nil || info.LawNum != *params.LawNum) {
+			continue
+		}
+		if params.LawTitle != nil && (revision == nil || !strings.Contains(revision.LawTitle, *params.LawTitle) && !strings.Contains(revision.Abbrev, *params.LawTitle)) {
+			continue
+		}
+		if params.LawType != nil && (info == nil || info.LawType == nil || !slices.Contains(*params.LawType, *info.LawType)) {
+			continue
+		}
+		matched = append(matched, law.item)
+	}
+	page, next := paginate(len(matched), params.Limit, params.Offset)
+	return &jplaw.LawsResponse{
+		TotalCount: int64(len(matched)),
+		Count:      int64(page.end - page.start),
+		NextOffset: next,
+		Laws:       matched[page.start:page.end],
+	}, nil
+}
+
+func (a *LawAPI) GetRevisions(lawIdOrNumOrRevisionId string, params *jplaw.GetRevisionsParams) (*jplaw.LawRevisionsResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls["GetRevisions"]++
+	if a.err != nil {
+		return nil, a.err
+	}
+	law := a.find(lawIdOrNumOrRevisionId)
+	if law == nil {
+		return nil, fmt.Errorf("unexpected status code 404: law %s not found", lawIdOrNumOrRevisionId)
+	}
+	resp := &jplaw.LawRevisionsResponse{}
+	if law.item.LawInfo != nil {
+		resp.LawInfo = *law.item.LawInfo
+	}
+	if law.item.RevisionInfo != nil {
+		resp.Revisions = append(resp.Revisions, *law.item.RevisionInfo)
+	}
+	resp.Revisions = append(resp.Revisions, law.revisions...)
+	return resp, nil
+}
+
+func (a *LawAPI) GetKeyword(params *jplaw.GetKeywordParams) (*jplaw.KeywordResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls["GetKeyword"]++
+	if a.err != nil {
+		return nil, a.err
+	}
+	if params == nil || params.Keyword == "" {
+		return nil, fmt.Errorf("unexpected status code 400: keyword is required")
+	}
+	var items []jplaw.KeywordItem
+	var sentenceCount int64
+	for _, law := range a.laws {
+		if params.LawNum != nil && (law.item.LawInfo == nil || law.item.LawInfo.LawNum != *params.LawNum) {
+			continue
+		}
+		var sentences []jplaw.KeywordSentence
+		for _, text := range law.sentences {
+			if strings.Contains(text, params.Keyword) {
+				sentences = append(sentences, jplaw.KeywordSentence{Position: "mainprovision", Text: text})
+			}
+		}
+		if len(sentences) == 0 {
+			continue
+		}
+		sentenceCount += int64(len(sentences))
+		items = append(items, jplaw.KeywordItem{LawInfo: law.item.LawInfo, RevisionInfo: law.item.RevisionInfo, Sentences: sentences})
+	}
+	page, next := paginate(len(items), params.Limit, params.Offset)
+	return &jplaw.KeywordResponse{
+		TotalCount:    int64(len(items)),
+		SentenceCount: sentenceCount,
+		NextOffset:    next,
+		Items:         items[page.start:page.end],
+	}, nil
+}
+
+// find returns the law with a law ID, law number, or current or past
+// revision ID.
+func (a *LawAPI) find(id string) *fakeLaw {
+	for i, law := range a.laws {
+		if info := law.item.LawInfo; info != nil && (id == info.LawId || id == info.LawNum) {
+			return &a.laws[i]
+		}
+		if revision := law.item.RevisionInfo; revision != nil && id == revision.LawRevisionId {
+			return &a.laws[i]
+		}
+		for _, revision := range law.revisions {
+			if id == revision.LawRevisionId {
+				return &a.laws[i]
+			}
+		}
+	}
+	return nil
+}
+
+type pageRange struct {
+	start, end int
+}
+
+// paginate applies a limit and offset to n results, and returns the offset
+// of the next page or 0 on the last page.
+func paginate(n int, limit, offset *int32) (pageRange, int64) {
+	start, size := 0, 100
+	if offset != nil {
+		start = min(max(int(*offset), 0), n)
+	}
+	if limit != nil && *limit > 0 {
+		size = int(*limit)
+	}
+	end := min(start+size, n)
+	if end < n {
+		return pageRange{start, end}, int64(end)
+	}
+	return pageRange{start, end}, 0
+}
@@ -0,0 +1,130 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/graphql"
+	"go.ngs.io/jplaw2epub-web-api/server"
+	"go.ngs.io/jplaw2epub-web-api/upstream/mock"
+)
+
+const (
+	// Bucket is the EPUB bucket of a Server.
+	Bucket = "test-epubs"
+	// AdminToken is the admin token of a Server.
+	AdminToken = "test-admin-token"
+)
+
+// Server is the full API served over HTTP with fakes behind it: the law
+// list and search API, the object store, the generator job, and the clock.
+// Law data, attachments, and EPUB conversion call the mock e-Gov API.
+type Server struct {
+	// URL is the base URL of the API, without a trailing slash.
+	URL     string
+	HTTP    *httptest.Server
+	API     *server.Server
+	Config  *config.Config
+	LawAPI  *LawAPI
+	Storage *Storage
+	Jobs    *JobRunner
+	Clock   *Clock
+}
+
+// GraphQLResponse is the response to a GraphQL request.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// NewServer starts a server that is closed with the test. The configuration
+// starts from the defaults with in-memory stores, no access or audit log,
+// Bucket, and AdminToken; configure functions can change it before the API
+// is built.
+func NewServer(tb testing.TB, configure ...func(*config.Config)) *Server {
+	tb.Helper()
+	upstreamServer, err := mock.NewServer()
+	if err != nil {
+		tb.Fatalf("Failed to start mock e-Gov API: %v", err)
+	}
+	tb.Cleanup(upstreamServer.Close)
+
+	cfg := config.Default()
+	cfg.BucketName = Bucket
+	cfg.JobStore = "memory"
+	cfg.AuditLog = "none"
+	cfg.DisableAccessLog = true
+	cfg.AdminToken = AdminToken
+	cfg.Upstream.BaseURL = upstreamServer.URL + "/api/2"
+	for _, fn := range configure {
+		fn(cfg)
+	}
+
+	s := &Server{
+		HTTP:    httptest.NewUnstartedServer(nil),
+		Config:  cfg,
+		LawAPI:  NewLawAPI(),
+		Storage: NewStorage(tb),
+		Jobs:    NewJobRunner(),
+		Clock:   NewClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+	deps := graphql.Dependencies{
+		LawAPI:    s.LawAPI,
+		Storage:   s.Storage,
+		JobRunner: s.Jobs,
+		Clock:     s.Clock,
+	}
+	s.API, err = server.New(cfg, deps, s.HTTP.Listener.Addr().(*net.TCPAddr).Port)
+	if err != nil {
+		s.HTTP.Listener.Close()
+		tb.Fatalf("Failed to initialize the API: %v", err)
+	}
+	s.HTTP.Config.Handler = s.API.Handler
+	s.HTTP.Start()
+	tb.Cleanup(s.HTTP.Close)
+	s.URL = s.HTTP.URL
+	return s
+}
+
+// Client returns an HTTP client for the server.
+func (s *Server) Client() *http.Client {
+	return s.HTTP.Client()
+}
+
+// GraphQL posts a query with variables to /graphql, with the admin token
+// when admin is true, and decodes the response.
+func (s *Server) GraphQL(tb testing.TB, query string, variables map[string]interface{}, admin bool) *GraphQLResponse {
+	tb.Helper()
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		tb.Fatalf("Failed to encode GraphQL request: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/graphql", bytes.NewReader(body))
+	if err != nil {
+		tb.Fatalf("Failed to create GraphQL request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if admin {
+		req.Header.Set("Authorization", "Bearer "+AdminToken)
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		tb.Fatalf("Failed to send GraphQL request: %v", err)
+	}
+	defer resp.Body.Close()
+	var result GraphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		tb.Fatalf("Failed to decode GraphQL response (status %d): %v", resp.StatusCode, err)
+	}
+	return &result
+}
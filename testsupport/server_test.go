@@ -0,0 +1,364 @@
+package testsupport_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/graphql"
+	"go.ngs.io/jplaw2epub-web-api/server"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+)
+
+// tenantsFile defines two tenants, each with one API key.
+const tenantsFile = `tenants:
+  - id: acme
+    name: Acme
+    apiKeys: [acme-key]
+  - id: globex
+    name: Globex
+    apiKeys: [globex-key]
+`
+
+// newTenantServer starts a server with the tenants of tenantsFile and an
+// EPUB stored for each of them.
+func newTenantServer(t *testing.T) *testsupport.Server {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tenants.yaml")
+	if err := os.WriteFile(path, []byte(tenantsFile), 0o600); err != nil {
+		t.Fatalf("Failed to write tenants file: %v", err)
+	}
+	s := testsupport.NewServer(t, func(cfg *config.Config) {
+		cfg.Tenants.Store = "file"
+		cfg.Tenants.File = path
+	})
+	s.Storage.Put(testsupport.Bucket, graphql.APP_VERSION+"/tenants/acme/acme-doc.epub", []byte("acme"), "application/epub+zip")
+	s.Storage.Put(testsupport.Bucket, graphql.APP_VERSION+"/tenants/globex/globex-doc.epub", []byte("globex"), "application/epub+zip")
+	return s
+}
+
+// get requests path, escaped as given, with the API key when it is set.
+func get(t *testing.T, s *testsupport.Server, path, apiKey string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, s.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request for %s: %v", path, err)
+	}
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	client := s.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response to %s: %v", path, err)
+	}
+	return resp, body
+}
+
+func TestDownloadIsTenantScoped(t *testing.T) {
+	s := newTenantServer(t)
+
+	s.Storage.Put(testsupport.Bucket, graphql.APP_VERSION+"/shared-doc.epub", []byte("shared"), "application/epub+zip")
+
+	tests := []struct {
+		name         string
+		path         string
+		apiKey       string
+		status       int
+		body         string
+		cacheControl string
+	}{
+		{name: "own document", path: "/download/acme-doc", apiKey: "acme-key", status: http.StatusOK, body: "acme", cacheControl: "private, max-age=2592000"},
+		{name: "document outside tenants", path: "/download/shared-doc", status: http.StatusOK, body: "shared", cacheControl: "public, max-age=2592000"},
+		{name: "other tenant's document", path: "/download/globex-doc", apiKey: "acme-key", status: http.StatusNotFound},
+		{name: "tenant document without key", path: "/download/acme-doc", status: http.StatusNotFound},
+		{name: "escaped separator", path: "/download/..%2Fglobex%2Fglobex-doc", apiKey: "acme-key", status: http.StatusBadRequest},
+		{name: "escaped separator below prefix", path: "/download/x%2Fglobex-doc", apiKey: "acme-key", status: http.StatusBadRequest},
+		{name: "backslash", path: "/download/..%5Cglobex-doc", apiKey: "acme-key", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := get(t, s, tt.path, tt.apiKey)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("GET %s status = %d, want %d (%s)", tt.path, resp.StatusCode, tt.status, body)
+			}
+			if tt.body != "" && string(body) != tt.body {
+				t.Errorf("GET %s body = %q, want %q", tt.path, body, tt.body)
+			}
+			if got := resp.Header.Get("Cache-Control"); tt.cacheControl != "" && got != tt.cacheControl {
+				t.Errorf("GET %s Cache-Control = %q, want %q", tt.path, got, tt.cacheControl)
+			}
+			// Documents found or missing depend on the key.
+			if got := resp.Header.Values("Vary"); tt.status != http.StatusBadRequest && !slices.Contains(got, "X-API-Key") {
+				t.Errorf("GET %s Vary = %q, want X-API-Key", tt.path, got)
+			}
+		})
+	}
+}
+
+func TestVerifyIsTenantScoped(t *testing.T) {
+	s := newTenantServer(t)
+
+	resp, body := get(t, s, "/verify/acme-doc", "acme-key")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("verify own document status = %d, want 200 (%s)", resp.StatusCode, body)
+	}
+	var result struct {
+		Path   string `json:"path"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to decode verify result: %v", err)
+	}
+	if want := graphql.APP_VERSION + "/tenants/acme/acme-doc.epub"; result.Path != want || result.Status != "UNRECORDED" {
+		t.Errorf("verify result = %+v, want %s UNRECORDED", result, want)
+	}
+
+	if resp, body := get(t, s, "/verify/globex-doc", "acme-key"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("verify other tenant's document status = %d, want 404 (%s)", resp.StatusCode, body)
+	}
+	if resp, body := get(t, s, "/verify/acme-doc", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("verify tenant document without key status = %d, want 404 (%s)", resp.StatusCode, body)
+	}
+	if resp, body := get(t, s, "/verify/..%2Fglobex%2Fglobex-doc", "acme-key"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("verify with escaped separator status = %d, want 400 (%s)", resp.StatusCode, body)
+	}
+}
+
+func TestEpubJobsRequiresAdmin(t *testing.T) {
+	s := testsupport.NewServer(t)
+	const query = `{ epubJobs { id status } }`
+
+	resp := s.GraphQL(t, query, nil, false)
+	if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != "FORBIDDEN" {
+		t.Errorf("epubJobs without admin token errors = %+v, want FORBIDDEN", resp.Errors)
+	}
+
+	resp = s.GraphQL(t, query, nil, true)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("epubJobs with admin token errors = %+v", resp.Errors)
+	}
+	if string(resp.Data) != `{"epubJobs":[]}` {
+		t.Errorf("epubJobs data = %s, want no jobs", resp.Data)
+	}
+}
+
+func TestEpubsETagFollowsCurrentRevision(t *testing.T) {
+	s := testsupport.NewServer(t)
+	// A law ID, its law number, and its current revision ID are answered
+	// with the same revision, and so the same ETag.
+	ids := []string{"321CONSTITUTION", url.PathEscape("昭和二十一年憲法"), "321CONSTITUTION_19470503_000000000000000"}
+	for _, accept := range []string{"text/html", "application/xml"} {
+		t.Run(accept, func(t *testing.T) {
+			var etags []string
+			for _, id := range ids {
+				req, err := http.NewRequest(http.MethodGet, s.URL+"/epubs/"+id, nil)
+				if err != nil {
+					t.Fatalf("Failed to create request for %s: %v", id, err)
+				}
+				req.Header.Set("Accept", accept)
+				resp, err := s.Client().Do(req)
+				if err != nil {
+					t.Fatalf("GET /epubs/%s: %v", id, err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("GET /epubs/%s status = %d, want 200", id, resp.StatusCode)
+				}
+				etags = append(etags, resp.Header.Get("ETag"))
+			}
+			for i, etag := range etags {
+				if etag == "" || etag != etags[0] {
+					t.Errorf("ETag of %s = %q, want %q", ids[i], etag, etags[0])
+				}
+			}
+		})
+	}
+}
+
+func TestEpubsNegotiatesFormat(t *testing.T) {
+	s := testsupport.NewServer(t)
+	tests := []struct {
+		accept      string
+		status      int
+		contentType string
+	}{
+		{accept: "", status: http.StatusAccepted, contentType: "application/json"},
+		{accept: "application/epub+zip", status: http.StatusAccepted, contentType: "application/json"},
+		{accept: "text/html", status: http.StatusOK, contentType: "text/html; charset=utf-8"},
+		{accept: "text/xml", status: http.StatusOK, contentType: "application/xml; charset=utf-8"},
+		{accept: "application/pdf, */*;q=0.8", status: http.StatusAccepted, contentType: "application/json"},
+		{accept: "application/pdf, text/html;q=0.5", status: http.StatusOK, contentType: "text/html; charset=utf-8"},
+		{accept: "application/pdf", status: http.StatusNotAcceptable, contentType: "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, s.URL+"/epubs/321CONSTITUTION_19470503_000000000000000", nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := s.Client().Do(req)
+			if err != nil {
+				t.Fatalf("GET /epubs/: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+		})
+	}
+}
+
+func TestEpubProgressStreamsStages(t *testing.T) {
+	s := testsupport.NewServer(t)
+	const id = "321CONSTITUTION_19470503_000000000000000"
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"/epubs/"+id+"?progress=sse", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET /epubs/%s?progress=sse: %v", id, err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewScanner(resp.Body)
+	// next returns the name and data of the next event.
+	next := func() (string, string) {
+		t.Helper()
+		var event, data string
+		for events.Scan() {
+			line := events.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			case line == "" && event != "":
+				return event, data
+			}
+		}
+		t.Fatalf("stream ended: %v", events.Err())
+		return "", ""
+	}
+
+	if event, data := next(); event != "progress" || !strings.Contains(data, `"status":"PENDING"`) {
+		t.Fatalf("first event = %s %s, want pending progress", event, data)
+	}
+	// The generator reports its stage in the status file.
+	s.Storage.Put(testsupport.Bucket, graphql.APP_VERSION+"/"+id+".status", []byte(`{"status":"processing","stage":"convert"}`), "application/json")
+	if event, data := next(); event != "progress" || !strings.Contains(data, `"status":"PROCESSING"`) {
+		t.Fatalf("event = %s %s, want processing progress", event, data)
+	}
+	if event, data := next(); event != "stage" || !strings.Contains(data, `"stage":"CONVERT"`) {
+		t.Fatalf("event = %s %s, want the convert stage", event, data)
+	}
+}
+
+func TestCORSRouteOverrides(t *testing.T) {
+	const origin = "https://app.example.com"
+	s := testsupport.NewServer(t, func(cfg *config.Config) {
+		cfg.CORSOrigins = []string{origin}
+		cfg.CORSRoutes = map[string]config.CORSRoute{
+			"/epubs/{id}": {Methods: []string{http.MethodGet}, MaxAge: 60},
+		}
+	})
+	tests := []struct {
+		path        string
+		pattern     string
+		wantMethods string
+		wantMaxAge  string
+	}{
+		{path: "/graphql", pattern: "/graphql", wantMethods: "GET, POST, OPTIONS", wantMaxAge: "3600"},
+		{path: "/epubs/321CONSTITUTION", pattern: "/epubs/{id}", wantMethods: "GET", wantMaxAge: "60"},
+		{path: "/opds", pattern: "/opds", wantMethods: "GET, HEAD, OPTIONS", wantMaxAge: "3600"},
+	}
+
+	resp := s.GraphQL(t, `{ corsConfig { routes { path methods maxAge } } }`, nil, true)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("corsConfig errors = %+v", resp.Errors)
+	}
+	type corsRoute struct {
+		Path    string   `json:"path"`
+		Methods []string `json:"methods"`
+		MaxAge  int      `json:"maxAge"`
+	}
+	var data struct {
+		CorsConfig struct {
+			Routes []corsRoute `json:"routes"`
+		} `json:"corsConfig"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("Failed to decode corsConfig: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodOptions, s.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			req.Header.Set("Origin", origin)
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			resp, err := s.Client().Do(req)
+			if err != nil {
+				t.Fatalf("OPTIONS %s: %v", tt.path, err)
+			}
+			resp.Body.Close()
+			if got := resp.Header.Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Errorf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := resp.Header.Get("Access-Control-Max-Age"); got != tt.wantMaxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.wantMaxAge)
+			}
+
+			i := slices.IndexFunc(data.CorsConfig.Routes, func(route corsRoute) bool { return route.Path == tt.pattern })
+			if i < 0 {
+				t.Fatalf("corsConfig does not report %s", tt.pattern)
+			}
+			route := data.CorsConfig.Routes[i]
+			if got := strings.Join(route.Methods, ", "); got != tt.wantMethods {
+				t.Errorf("reported methods = %q, want %q", got, tt.wantMethods)
+			}
+			if got := strconv.Itoa(route.MaxAge); got != tt.wantMaxAge {
+				t.Errorf("reported maxAge = %s, want %s", got, tt.wantMaxAge)
+			}
+		})
+	}
+}
+
+func TestCORSRouteOverrideOfUnknownRoute(t *testing.T) {
+	cfg := config.Default()
+	cfg.JobStore = "memory"
+	cfg.AuditLog = "none"
+	cfg.CORSRoutes = map[string]config.CORSRoute{"/epub/{id}": {MaxAge: 60}}
+	if _, err := server.New(cfg, graphql.Dependencies{}, 0); err == nil || !strings.Contains(err.Error(), "/epub/{id}") {
+		t.Errorf("New() error = %v, want one naming /epub/{id}", err)
+	}
+}
@@ -0,0 +1,303 @@
+package testsupport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.ngs.io/jplaw2epub-web-api/objects"
+)
+
+// Storage is an in-memory object store. Its buckets implement
+// objects.Bucket, with generation and metageneration preconditions, so a
+// Storage can be passed as the resolver's storage. Every bucket exists and
+// starts empty.
+//
+// Signed URLs point at storage.test and are not served.
+type Storage struct {
+	mu         sync.Mutex
+	buckets    map[string]map[string]*storedObject
+	generation int64
+}
+
+// storedObject is an object's data and attributes.
+type storedObject struct {
+	data  []byte
+	attrs objects.Attrs
+}
+
+// NewStorage returns an empty store. It takes the test for symmetry with
+// the other fakes.
+func NewStorage(tb testing.TB) *Storage {
+	tb.Helper()
+	return &Storage{buckets: make(map[string]map[string]*storedObject)}
+}
+
+// Bucket returns the named bucket.
+func (s *Storage) Bucket(name string) objects.Bucket {
+	return &memoryBucket{storage: s, name: name}
+}
+
+// Put stores an object as if it had been uploaded.
+func (s *Storage) Put(bucket, name string, data []byte, contentType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store(bucket, name, data, objects.WriteOptions{ContentType: contentType})
+}
+
+// Get returns the data of an object, or false when it does not exist.
+func (s *Storage) Get(bucket, name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.buckets[bucket][name]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), obj.data...), true
+}
+
+// Objects returns the names of the objects in a bucket, sorted.
+func (s *Storage) Objects(bucket string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.buckets[bucket]))
+	for name := range s.buckets[bucket] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// store replaces an object with a new generation.
+func (s *Storage) store(bucket, name string, data []byte, opts objects.WriteOptions) *storedObject {
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]*storedObject)
+	}
+	s.generation++
+	now := time.Now()
+	obj := &storedObject{
+		data: data,
+		attrs: objects.Attrs{
+			Name:           name,
+			Size:           int64(len(data)),
+			ContentType:    opts.ContentType,
+			Metadata:       copyMetadata(opts.Metadata),
+			Generation:     s.generation,
+			Metageneration: 1,
+			Created:        now,
+			Updated:        now,
+		},
+	}
+	if obj.attrs.ContentType == "" {
+		obj.attrs.ContentType = http.DetectContentType(data)
+	}
+	s.buckets[bucket][name] = obj
+	return obj
+}
+
+// memoryBucket is a bucket of a Storage.
+type memoryBucket struct {
+	storage *Storage
+	name    string
+}
+
+func (b *memoryBucket) objects() map[string]*storedObject {
+	return b.storage.buckets[b.name]
+}
+
+func (b *memoryBucket) NewReader(ctx context.Context, name string) (*objects.Reader, error) {
+	return b.NewRangeReader(ctx, name, 0, 0, -1)
+}
+
+func (b *memoryBucket) NewRangeReader(ctx context.Context, name string, generation, offset, length int64) (*objects.Reader, error) {
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+	obj, ok := b.objects()[name]
+	if !ok || generation != 0 && obj.attrs.Generation != generation {
+		return nil, objects.ErrNotExist
+	}
+	data := obj.data[min(offset, int64(len(obj.data))):]
+	if length >= 0 && length < int64(len(data)) {
+		data = data[:length]
+	}
+	attrs := obj.attrs
+	attrs.Metadata = nil
+	return &objects.Reader{
+		ReadCloser: io.NopCloser(bytes.NewReader(append([]byte(nil), data...))),
+		Attrs:      attrs,
+	}, nil
+}
+
+func (b *memoryBucket) NewWriter(ctx context.Context, name string, opts objects.WriteOptions) objects.Writer {
+	return &memoryWriter{ctx: ctx, bucket: b, name: name, opts: opts}
+}
+
+func (b *memoryBucket) Attrs(ctx context.Context, name string) (*objects.Attrs, error) {
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+	obj, ok := b.objects()[name]
+	if !ok {
+		return nil, objects.ErrNotExist
+	}
+	return obj.copyAttrs(), nil
+}
+
+func (b *memoryBucket) Update(ctx context.Context, name string, metadata map[string]string, conditions objects.Conditions) (*objects.Attrs, error) {
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+	obj, ok := b.objects()[name]
+	if !ok {
+		return nil, objects.ErrNotExist
+	}
+	if err := checkConditions(obj, conditions); err != nil {
+		return nil, err
+	}
+	// As in Cloud Storage, the keys are merged and empty values remove
+	// them.
+	if obj.attrs.Metadata == nil {
+		obj.attrs.Metadata = make(map[string]string)
+	}
+	for key, value := range metadata {
+		if value == "" {
+			delete(obj.attrs.Metadata, key)
+			continue
+		}
+		obj.attrs.Metadata[key] = value
+	}
+	obj.attrs.Metageneration++
+	obj.attrs.Updated = time.Now()
+	return obj.copyAttrs(), nil
+}
+
+func (b *memoryBucket) Delete(ctx context.Context, name string, conditions objects.Conditions) error {
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+	obj, ok := b.objects()[name]
+	if !ok {
+		return objects.ErrNotExist
+	}
+	if err := checkConditions(obj, conditions); err != nil {
+		return err
+	}
+	delete(b.objects(), name)
+	return nil
+}
+
+func (b *memoryBucket) List(ctx context.Context, prefix string) ([]*objects.Attrs, error) {
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+	var result []*objects.Attrs
+	for name, obj := range b.objects() {
+		if strings.HasPrefix(name, prefix) {
+			result = append(result, obj.copyAttrs())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (b *memoryBucket) Dirs(ctx context.Context, prefix string) ([]string, error) {
+	b.storage.mu.Lock()
+	defer b.storage.mu.Unlock()
+	seen := make(map[string]bool)
+	var result []string
+	for name := range b.objects() {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		dir, _, ok := strings.Cut(rest, "/")
+		if !ok || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		result = append(result, prefix+dir+"/")
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (b *memoryBucket) SignedURL(name string, expires time.Time, disposition string) (string, error) {
+	query := url.Values{"X-Goog-Expires": {strconv.FormatInt(expires.Unix(), 10)}}
+	if disposition != "" {
+		query.Set("response-content-disposition", disposition)
+	}
+	return fmt.Sprintf("https://storage.test/%s/%s?%s", b.name, url.PathEscape(name), query.Encode()), nil
+}
+
+// memoryWriter buffers an upload until it is closed.
+type memoryWriter struct {
+	ctx    context.Context
+	bucket *memoryBucket
+	name   string
+	opts   objects.WriteOptions
+	buf    bytes.Buffer
+	attrs  *objects.Attrs
+}
+
+func (w *memoryWriter) Write(p []byte) (int, error) {
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *memoryWriter) Close() error {
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+	s := w.bucket.storage
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkConditions(w.bucket.objects()[w.name], w.opts.If); err != nil {
+		return err
+	}
+	w.attrs = s.store(w.bucket.name, w.name, append([]byte(nil), w.buf.Bytes()...), w.opts).copyAttrs()
+	return nil
+}
+
+func (w *memoryWriter) Attrs() *objects.Attrs {
+	return w.attrs
+}
+
+// checkConditions checks preconditions against an object, which is nil
+// when it does not exist.
+func checkConditions(obj *storedObject, conditions objects.Conditions) error {
+	var generation, metageneration int64
+	if obj != nil {
+		generation, metageneration = obj.attrs.Generation, obj.attrs.Metageneration
+	}
+	switch {
+	case conditions.DoesNotExist && obj != nil,
+		conditions.GenerationMatch != 0 && conditions.GenerationMatch != generation,
+		conditions.MetagenerationMatch != 0 && conditions.MetagenerationMatch != metageneration:
+		return objects.ErrPreconditionFailed
+	}
+	return nil
+}
+
+func (o *storedObject) copyAttrs() *objects.Attrs {
+	attrs := o.attrs
+	attrs.Metadata = copyMetadata(o.attrs.Metadata)
+	return &attrs
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		copied[key] = value
+	}
+	return copied
+}
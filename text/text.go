@@ -0,0 +1,236 @@
+package text
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize folds the differences users do not type consistently: width
+// (NFKC), case, whitespace, and katakana versus hiragana.
+func Normalize(s string) string {
+	s = norm.NFKC.String(s)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(foldKana(r))
+	}, s)
+}
+
+// FoldKana rewrites katakana as hiragana, so カタカナ becomes かたかな. The
+// long vowel mark and characters without a hiragana form are kept.
+func FoldKana(s string) string {
+	return strings.Map(foldKana, s)
+}
+
+func foldKana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - 'ァ' + 'ぁ'
+	}
+	return r
+}
+
+// IsRomaji reports whether s consists of Latin letters, apostrophes, and
+// hyphens only, and so may be romaji.
+func IsRomaji(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '\'' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// RomajiToHiragana converts Hepburn or kunrei-shiki romaji to hiragana, so
+// kojinjouhou becomes こじんじょうほう. A trailing consonant that does not
+// complete a syllable yet, as in the partially typed kojinj, is returned as
+// rest, and so is a final n, which may start na or no. ok is false when s
+// is not romaji.
+func RomajiToHiragana(s string) (kana, rest string, ok bool) {
+	if !IsRomaji(s) {
+		return "", "", false
+	}
+	table := romajiTable()
+	s = strings.ToLower(s)
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '-':
+			b.WriteRune('ー')
+			i++
+			continue
+		case c == '\'':
+			i++
+			continue
+		case c == 'n' && i+1 < len(s) && s[i+1] == 'n':
+			// nn is ん, but in Hepburn konnichiha the second n starts に.
+			b.WriteRune('ん')
+			i++
+			if i+1 >= len(s) || !strings.ContainsRune("aiueoy", rune(s[i+1])) {
+				i++
+			}
+			continue
+		case c == 'n' && i+1 < len(s) && !strings.ContainsRune("aiueoy", rune(s[i+1])):
+			b.WriteRune('ん')
+			i++
+			continue
+		case c != 'n' && i+1 < len(s) && s[i+1] == c && !strings.ContainsRune("aiueo", rune(c)),
+			c == 't' && strings.HasPrefix(s[i+1:], "ch"):
+			// A doubled consonant, as in kitte or matcha, is a small tsu.
+			b.WriteRune('っ')
+			i++
+			continue
+		}
+
+		matched := false
+		for length := min(3, len(s)-i); length > 0; length-- {
+			if kana, found := table[s[i:i+length]]; found {
+				b.WriteString(kana)
+				i += length
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if strings.ContainsRune("aiueo", rune(c)) {
+				return "", "", false
+			}
+			// An incomplete syllable can only be the end of the input.
+			if len(s)-i > 2 {
+				return "", "", false
+			}
+			return b.String(), s[i:], true
+		}
+	}
+	return b.String(), "", true
+}
+
+// KanaToRomaji romanizes hiragana or katakana in Hepburn with macrons for
+// long vowels, so こじんじょうほう becomes kojinjōhō. ん is written n', as
+// in shin'yō, before a vowel or y. Other characters are kept.
+func KanaToRomaji(s string) string {
+	syllables := kanaTable()
+	runes := []rune(FoldKana(s))
+
+	var b strings.Builder
+	double := false
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch r {
+		case 'っ':
+			double = true
+			i++
+			continue
+		case 'ん':
+			b.WriteByte('n')
+			if i+1 < len(runes) {
+				if next, ok := syllables[string(runes[i+1])]; ok && strings.ContainsRune("aiueoy", rune(next[0])) {
+					b.WriteByte('\'')
+				}
+			}
+			i++
+			continue
+		}
+
+		romaji, length := "", 0
+		if i+1 < len(runes) {
+			if found, ok := syllables[string(runes[i:i+2])]; ok {
+				romaji, length = found, 2
+			}
+		}
+		if length == 0 {
+			if found, ok := syllables[string(r)]; ok {
+				romaji, length = found, 1
+			}
+		}
+		if length == 0 {
+			double = false
+			b.WriteRune(r)
+			i++
+			continue
+		}
+		if double {
+			// A small tsu doubles the consonant, or makes tch of ch.
+			if strings.HasPrefix(romaji, "ch") {
+				b.WriteByte('t')
+			} else if !strings.ContainsRune("aiueo", rune(romaji[0])) {
+				b.WriteByte(romaji[0])
+			}
+			double = false
+		}
+		b.WriteString(romaji)
+		i += length
+	}
+	return strings.NewReplacer("ou", "ō", "oo", "ō", "uu", "ū", "oー", "ō", "uー", "ū", "aー", "ā", "eー", "ē", "iー", "ī").Replace(b.String())
+}
+
+// kanaTable maps hiragana syllables to their Hepburn romaji.
+func kanaTable() map[string]string {
+	// Keys of romajiTable that are not Hepburn, such as si for し.
+	nonHepburn := map[string]bool{
+		"si": true, "ti": true, "tu": true, "hu": true, "zi": true, "di": true, "du": true,
+		"sya": true, "syu": true, "syo": true, "tya": true, "tyu": true, "tyo": true,
+		"zya": true, "zyu": true, "zyo": true, "jya": true, "jyu": true, "jyo": true, "jye": true,
+		"xtu": true, "ltu": true,
+	}
+	table := make(map[string]string)
+	for romaji, kana := range romajiTable() {
+		if !nonHepburn[romaji] {
+			table[kana] = romaji
+		}
+	}
+	table["ぢ"] = "ji"
+	table["づ"] = "zu"
+	table["を"] = "o"
+	return table
+}
+
+// romajiTable maps romaji syllables to hiragana.
+func romajiTable() map[string]string {
+	table := map[string]string{
+		"a": "あ", "i": "い", "u": "う", "e": "え", "o": "お",
+		"shi": "し", "chi": "ち", "tsu": "つ", "fu": "ふ", "ji": "じ",
+		"sha": "しゃ", "shu": "しゅ", "sho": "しょ", "she": "しぇ",
+		"cha": "ちゃ", "chu": "ちゅ", "cho": "ちょ", "che": "ちぇ",
+		"ja": "じゃ", "ju": "じゅ", "jo": "じょ", "je": "じぇ",
+		"jya": "じゃ", "jyu": "じゅ", "jyo": "じょ", "jye": "じぇ",
+		"tsa": "つぁ", "fa": "ふぁ", "fi": "ふぃ", "fe": "ふぇ", "fo": "ふぉ",
+		"ti": "ち", "tu": "つ", "si": "し", "zi": "じ", "hu": "ふ",
+		"di": "ぢ", "du": "づ", "wo": "を", "xtu": "っ", "ltu": "っ",
+		"va": "ゔぁ", "vi": "ゔぃ", "vu": "ゔ", "ve": "ゔぇ", "vo": "ゔぉ",
+	}
+	rows := map[string]string{
+		"k": "かきくけこ", "g": "がぎぐげご", "s": "さしすせそ", "z": "ざじずぜぞ",
+		"t": "たちつてと", "d": "だぢづでど", "n": "なにぬねの", "h": "はひふへほ",
+		"b": "ばびぶべぼ", "p": "ぱぴぷぺぽ", "m": "まみむめも", "r": "らりるれろ",
+		"y": "や\x00ゆ\x00よ", "w": "わ\x00\x00\x00を",
+	}
+	vowels := "aiueo"
+	small := []string{"ゃ", "ゅ", "ょ"}
+	for consonant, kana := range rows {
+		runes := []rune(kana)
+		for i, v := range vowels {
+			if runes[i] == 0 {
+				continue
+			}
+			key := consonant + string(v)
+			if _, exists := table[key]; !exists {
+				table[key] = string(runes[i])
+			}
+		}
+		// Contracted sounds such as kya and ryo.
+		if consonant != "y" && consonant != "w" {
+			for i, v := range "auo" {
+				table[consonant+"y"+string(v)] = string(runes[1]) + small[i]
+			}
+		}
+	}
+	return table
+}
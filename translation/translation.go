@@ -0,0 +1,126 @@
+package translation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.ngs.io/jplaw2epub-web-api/jpdate"
+)
+
+// Table maps laws to their English titles, such as those published by the
+// Japanese Law Translation database. A nil or empty table knows no titles.
+type Table struct {
+	byID  map[string]string
+	byNum map[string]string
+	size  int
+}
+
+// Load reads a CSV file with a header row naming the columns lawId, lawNum,
+// and titleEn. Either lawId or lawNum identifies the law; rows without an
+// English title are skipped. An empty path returns an empty table.
+func Load(path string) (*Table, error) {
+	if path == "" {
+		return &Table{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open translation table: %v", err)
+	}
+	defer f.Close()
+
+	table, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse translation table %s: %v", path, err)
+	}
+	return table, nil
+}
+
+// Parse reads a translation table in the CSV format described in Load.
+func Parse(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &Table{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{"lawId": -1, "lawNum": -1, "titleEn": -1}
+	for i, name := range header {
+		// Spreadsheet exports often start with a byte order mark.
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	if columns["titleEn"] < 0 || (columns["lawId"] < 0 && columns["lawNum"] < 0) {
+		return nil, errors.New("header must name titleEn and lawId or lawNum")
+	}
+
+	table := &Table{byID: make(map[string]string), byNum: make(map[string]string)}
+	field := func(record []string, name string) string {
+		i := columns[name]
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		title := field(record, "titleEn")
+		if title == "" || (field(record, "lawId") == "" && field(record, "lawNum") == "") {
+			continue
+		}
+		if id := field(record, "lawId"); id != "" {
+			table.byID[id] = title
+		}
+		if num := field(record, "lawNum"); num != "" {
+			normalized, err := jpdate.NormalizeLawNum(num)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			table.byNum[normalized] = title
+		}
+		table.size++
+	}
+	return table, nil
+}
+
+// Len returns the number of laws with an English title.
+func (t *Table) Len() int {
+	if t == nil {
+		return 0
+	}
+	return t.size
+}
+
+// TitleEn returns the English title of a law identified by its law ID or,
+// failing that, its law number, or an empty string when none is known.
+func (t *Table) TitleEn(lawID, lawNum string) string {
+	if t == nil {
+		return ""
+	}
+	if title, ok := t.byID[lawID]; ok {
+		return title
+	}
+	if lawNum == "" {
+		return ""
+	}
+	if normalized, err := jpdate.NormalizeLawNum(lawNum); err == nil {
+		return t.byNum[normalized]
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	jplaw "go.ngs.io/jplaw-api-v2"
+
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+)
+
+// Client is the jplaw client with every call recorded by a Tracker. The
+// jplaw client does not report HTTP statuses, so successful calls count as
+// 200 and failed ones as 0, or 429 when the error says so.
+type Client struct {
+	client  *jplaw.Client
+	tracker *Tracker
+}
+
+// NewClient wraps a jplaw client so that its calls are recorded by tracker.
+func NewClient(client *jplaw.Client, tracker *Tracker) *Client {
+	return &Client{client: client, tracker: tracker}
+}
+
+func (c *Client) GetLaws(params *jplaw.GetLawsParams) (*jplaw.LawsResponse, error) {
+	start := time.Now()
+	resp, err := c.client.GetLaws(params)
+	c.observe("laws", start, err)
+	return resp, err
+}
+
+func (c *Client) GetRevisions(lawIdOrNumOrRevisionId string, params *jplaw.GetRevisionsParams) (*jplaw.LawRevisionsResponse, error) {
+	start := time.Now()
+	resp, err := c.client.GetRevisions(lawIdOrNumOrRevisionId, params)
+	c.observe("law_revisions", start, err)
+	return resp, err
+}
+
+func (c *Client) GetKeyword(params *jplaw.GetKeywordParams) (*jplaw.KeywordResponse, error) {
+	start := time.Now()
+	resp, err := c.client.GetKeyword(params)
+	c.observe("keyword", start, err)
+	return resp, err
+}
+
+func (c *Client) observe(endpoint string, start time.Time, err error) {
+	status := http.StatusOK
+	if err != nil {
+		status = 0
+		if strings.Contains(err.Error(), "429") {
+			status = http.StatusTooManyRequests
+		}
+	}
+	c.tracker.Observe(endpoint, status, time.Since(start), err)
+}
+
+// NewLawDataClient returns a client of the law_data and attachment
+// endpoints at baseURL whose calls are recorded by tracker.
+func NewLawDataClient(tracker *Tracker, baseURL string) *lawdata.Client {
+	client := lawdata.NewClient()
+	client.BaseURL = strings.TrimSuffix(baseURL, "/")
+	client.HTTPClient.Transport = tracker.Transport(client.HTTPClient.Transport)
+	return client
+}
+
+// Redirect returns a transport that sends the requests for the e-Gov API
+// made through base to baseURL instead. The jplaw client has no base URL
+// setting, so it is pointed elsewhere by redirecting the default transport
+// it uses.
+func Redirect(base http.RoundTripper, baseURL string) (http.RoundTripper, error) {
+	target, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upstream base URL: %v", err)
+	}
+	origin, _ := url.Parse(lawdata.DefaultBaseURL)
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host != origin.Host || !strings.HasPrefix(req.URL.Path, origin.Path+"/") {
+			return base.RoundTrip(req)
+		}
+		req = req.Clone(req.Context())
+		req.URL.Scheme = target.Scheme
+		req.URL.Host = target.Host
+		req.URL.Path = target.Path + strings.TrimPrefix(req.URL.Path, origin.Path)
+		req.URL.RawPath = ""
+		req.Host = ""
+		return base.RoundTrip(req)
+	}), nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
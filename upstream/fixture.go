@@ -0,0 +1,193 @@
+package upstream
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// fixture is a recorded response of the e-Gov API. The body is kept as
+// JSON, text, or base64 data, whichever fits, so that fixtures can be read
+// and edited.
+type fixture struct {
+	Method string `json:"method"`
+	// URL is the path and query of the request relative to the base URL.
+	URL         string          `json:"url"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"contentType,omitempty"`
+	JSON        json.RawMessage `json:"json,omitempty"`
+	Text        string          `json:"text,omitempty"`
+	Data        []byte          `json:"data,omitempty"`
+}
+
+// Record returns a transport that makes the requests for the API at
+// baseURL through base and saves their responses in dir for Replay. Other
+// requests, and responses that are throttled or server errors, are passed
+// through without being saved.
+func Record(base http.RoundTripper, baseURL, dir string) (http.RoundTripper, error) {
+	api, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upstream base URL: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %v", err)
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rel, ok := relativeURL(api, req.URL)
+		if !ok {
+			return base.RoundTrip(req)
+		}
+		resp, err := base.RoundTrip(req)
+		if err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return resp, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upstream response: %v", err)
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		f := fixture{Method: req.Method, URL: rel, Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type")}
+		f.setBody(body)
+		if err := f.save(dir); err != nil {
+			log.Printf("Failed to record upstream response for %s: %v", rel, err)
+		}
+		return resp, nil
+	}), nil
+}
+
+// Replay returns a transport that answers the requests for the API at
+// baseURL with the responses saved in dir by Record, and makes other
+// requests through base. A request without a recorded response fails.
+func Replay(base http.RoundTripper, baseURL, dir string) (http.RoundTripper, error) {
+	api, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upstream base URL: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("fixture directory %s does not exist", dir)
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		rel, ok := relativeURL(api, req.URL)
+		if !ok {
+			return base.RoundTrip(req)
+		}
+		f, err := loadFixture(dir, req.Method, rel)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Printf("No recorded upstream response for %s %s", req.Method, rel)
+			return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, rel, dir)
+		}
+		if err != nil {
+			return nil, err
+		}
+		header := make(http.Header)
+		if f.ContentType != "" {
+			header.Set("Content-Type", f.ContentType)
+		}
+		body := f.body()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+			StatusCode:    f.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}), nil
+}
+
+// relativeURL returns the path and query of u below the API, with the query
+// parameters sorted, or false when u is not an API request.
+func relativeURL(api, u *url.URL) (string, bool) {
+	if u.Host != api.Host || !strings.HasPrefix(u.Path, api.Path+"/") {
+		return "", false
+	}
+	rel := strings.TrimPrefix(u.Path, api.Path)
+	if query := u.Query().Encode(); query != "" {
+		rel += "?" + query
+	}
+	return rel, true
+}
+
+// fixturePath names the fixture of a request after its endpoint and a hash
+// of the method and relative URL.
+func fixturePath(dir, method, rel string) string {
+	sum := sha256.Sum256([]byte(method + " " + rel))
+	endpoint, _, _ := strings.Cut(strings.TrimPrefix(rel, "/"), "/")
+	endpoint, _, _ = strings.Cut(endpoint, "?")
+	return filepath.Join(dir, endpoint+"-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+func (f *fixture) setBody(body []byte) {
+	mediaType, _, _ := mime.ParseMediaType(f.ContentType)
+	switch {
+	case (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) && json.Valid(body):
+		f.JSON = body
+	case utf8.Valid(body):
+		f.Text = string(body)
+	default:
+		f.Data = body
+	}
+}
+
+func (f *fixture) body() []byte {
+	switch {
+	case f.JSON != nil:
+		return f.JSON
+	case f.Data != nil:
+		return f.Data
+	default:
+		return []byte(f.Text)
+	}
+}
+
+// save writes the fixture through a temporary file, so that concurrent
+// requests and replays never see a partial one.
+func (f *fixture) save(dir string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %v", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".fixture-*")
+	if err != nil {
+		return fmt.Errorf("failed to create fixture: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write fixture: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write fixture: %v", err)
+	}
+	return os.Rename(tmp.Name(), fixturePath(dir, f.Method, f.URL))
+}
+
+func loadFixture(dir, method, rel string) (*fixture, error) {
+	data, err := os.ReadFile(fixturePath(dir, method, rel))
+	if err != nil {
+		return nil, err
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture for %s: %v", rel, err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,81 @@
+package upstream_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.ngs.io/jplaw2epub-web-api/config"
+	"go.ngs.io/jplaw2epub-web-api/lawdata"
+	"go.ngs.io/jplaw2epub-web-api/testsupport"
+	"go.ngs.io/jplaw2epub-web-api/upstream"
+)
+
+// fixtures holds responses recorded from the mock e-Gov API.
+const fixtures = "testdata/egov"
+
+func TestReplayLawBody(t *testing.T) {
+	// Law data is fetched through the default transport, as with
+	// -upstream=replay.
+	replayer, err := upstream.Replay(http.DefaultTransport, lawdata.DefaultBaseURL, fixtures)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	transport := http.DefaultTransport
+	http.DefaultTransport = replayer
+	t.Cleanup(func() { http.DefaultTransport = transport })
+
+	s := testsupport.NewServer(t, func(cfg *config.Config) {
+		cfg.Upstream.BaseURL = lawdata.DefaultBaseURL
+	})
+	resp := s.GraphQL(t, `query ($id: String!) {
+		lawBody(revisionId: $id) {
+			revisionId
+			lawTitle
+			mainProvision { divisions { title articles { title } } }
+		}
+	}`, map[string]interface{}{"id": "321CONSTITUTION_19470503_000000000000000"}, false)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("lawBody errors = %+v", resp.Errors)
+	}
+	var data struct {
+		LawBody struct {
+			RevisionID    string `json:"revisionId"`
+			LawTitle      string `json:"lawTitle"`
+			MainProvision struct {
+				Divisions []struct {
+					Title    string `json:"title"`
+					Articles []struct {
+						Title string `json:"title"`
+					} `json:"articles"`
+				} `json:"divisions"`
+			} `json:"mainProvision"`
+		} `json:"lawBody"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("Failed to decode lawBody: %v", err)
+	}
+	body := data.LawBody
+	if body.RevisionID != "321CONSTITUTION_19470503_000000000000000" || body.LawTitle != "日本国憲法" {
+		t.Errorf("lawBody = %s %q, want the recorded constitution", body.RevisionID, body.LawTitle)
+	}
+	if len(body.MainProvision.Divisions) == 0 || len(body.MainProvision.Divisions[0].Articles) == 0 {
+		t.Fatalf("lawBody divisions = %+v, want the recorded articles", body.MainProvision.Divisions)
+	}
+	if got := body.MainProvision.Divisions[0].Articles[0].Title; got != "第一条" {
+		t.Errorf("first article = %q, want 第一条", got)
+	}
+}
+
+func TestReplayUnrecorded(t *testing.T) {
+	replayer, err := upstream.Replay(http.DefaultTransport, lawdata.DefaultBaseURL, fixtures)
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	client := lawdata.NewClient()
+	client.HTTPClient.Transport = replayer
+	if _, err := client.FetchLawData(context.Background(), "129AC0000000089_20250101_000000000000000"); err == nil {
+		t.Error("FetchLawData() of an unrecorded law succeeded, want an error")
+	}
+}
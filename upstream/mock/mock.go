@@ -0,0 +1,257 @@
+// Package mock serves a small canned subset of the e-Gov Law API v2, for
+// local development and integration tests without network access.
+package mock
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// law is a fixture law: its entry in the law list and its XML.
+type law struct {
+	entry json.RawMessage
+	// info holds the fields of the entry that requests are matched on.
+	info struct {
+		LawInfo struct {
+			LawID   string `json:"law_id"`
+			LawNum  string `json:"law_num"`
+			LawType string `json:"law_type"`
+		} `json:"law_info"`
+		RevisionInfo struct {
+			LawRevisionID string `json:"law_revision_id"`
+			LawTitle      string `json:"law_title"`
+			LawTitleKana  string `json:"law_title_kana"`
+			Abbrev        string `json:"abbrev"`
+		} `json:"revision_info"`
+	}
+	xml []byte
+}
+
+// lawEntry is the shape of a law in the fixtures and in responses.
+type lawEntry struct {
+	LawInfo      json.RawMessage `json:"law_info"`
+	RevisionInfo json.RawMessage `json:"revision_info"`
+}
+
+// NewServer starts a server answering the laws, law_revisions, keyword,
+// law_data, and attachment endpoints under /api/2 from the fixtures. The
+// caller closes it.
+func NewServer() (*httptest.Server, error) {
+	laws, err := loadLaws()
+	if err != nil {
+		return nil, err
+	}
+	h := &handler{laws: laws}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/2/laws", h.serveLaws)
+	mux.HandleFunc("GET /api/2/law_revisions/{id}", h.serveRevisions)
+	mux.HandleFunc("GET /api/2/keyword", h.serveKeyword)
+	mux.HandleFunc("GET /api/2/law_data/{id}", h.serveLawData)
+	mux.HandleFunc("GET /api/2/attachment/{id}", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "attachments are not available from the mock API")
+	})
+	return httptest.NewServer(mux), nil
+}
+
+func loadLaws() ([]law, error) {
+	data, err := fixtures.ReadFile("fixtures/laws.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock law list: %v", err)
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse mock law list: %v", err)
+	}
+	laws := make([]law, len(entries))
+	for i, entry := range entries {
+		laws[i].entry = entry
+		if err := json.Unmarshal(entry, &laws[i].info); err != nil {
+			return nil, fmt.Errorf("failed to parse mock law: %v", err)
+		}
+		id := laws[i].info.LawInfo.LawID
+		if laws[i].xml, err = fixtures.ReadFile("fixtures/laws/" + id + ".xml"); err != nil {
+			return nil, fmt.Errorf("failed to read mock law %s: %v", id, err)
+		}
+	}
+	return laws, nil
+}
+
+type handler struct {
+	laws []law
+}
+
+// find returns the law with a law ID, law number, or revision ID.
+func (h *handler) find(id string) *law {
+	for i, l := range h.laws {
+		if id == l.info.LawInfo.LawID || id == l.info.LawInfo.LawNum || id == l.info.RevisionInfo.LawRevisionID {
+			return &h.laws[i]
+		}
+	}
+	return nil
+}
+
+func (h *handler) serveLaws(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var matched []json.RawMessage
+	for _, l := range h.laws {
+		if id := q.Get("law_id"); id != "" && id != l.info.LawInfo.LawID {
+			continue
+		}
+		if num := q.Get("law_num"); num != "" && num != l.info.LawInfo.LawNum {
+			continue
+		}
+		if title := q.Get("law_title"); title != "" && !strings.Contains(l.info.RevisionInfo.LawTitle, title) && !strings.Contains(l.info.RevisionInfo.Abbrev, title) {
+			continue
+		}
+		if kana := q.Get("law_title_kana"); kana != "" && !strings.Contains(l.info.RevisionInfo.LawTitleKana, kana) {
+			continue
+		}
+		if types := q.Get("law_type"); types != "" && !slices.Contains(strings.Split(types, ","), l.info.LawInfo.LawType) {
+			continue
+		}
+		matched = append(matched, l.entry)
+	}
+	page, next := paginate(matched, r)
+	writeJSON(w, map[string]interface{}{
+		"total_count": len(matched),
+		"count":       len(page),
+		"next_offset": next,
+		"laws":        page,
+	})
+}
+
+func (h *handler) serveRevisions(w http.ResponseWriter, r *http.Request) {
+	l := h.find(r.PathValue("id"))
+	if l == nil {
+		writeError(w, http.StatusNotFound, "law not found")
+		return
+	}
+	var entry lawEntry
+	_ = json.Unmarshal(l.entry, &entry)
+	writeJSON(w, map[string]interface{}{
+		"law_info":  entry.LawInfo,
+		"revisions": []json.RawMessage{entry.RevisionInfo},
+	})
+}
+
+func (h *handler) serveKeyword(w http.ResponseWriter, r *http.Request) {
+	keyword := r.URL.Query().Get("keyword")
+	if keyword == "" {
+		writeError(w, http.StatusBadRequest, "keyword is required")
+		return
+	}
+	var items []json.RawMessage
+	sentenceCount := 0
+	for _, l := range h.laws {
+		var matches []map[string]string
+		for _, text := range sentences(l.xml) {
+			if strings.Contains(text, keyword) {
+				matches = append(matches, map[string]string{"position": "mainprovision", "text": text})
+			}
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		sentenceCount += len(matches)
+		var entry lawEntry
+		_ = json.Unmarshal(l.entry, &entry)
+		item, _ := json.Marshal(map[string]interface{}{
+			"law_info":      entry.LawInfo,
+			"revision_info": entry.RevisionInfo,
+			"sentences":     matches,
+		})
+		items = append(items, item)
+	}
+	page, next := paginate(items, r)
+	writeJSON(w, map[string]interface{}{
+		"total_count":    len(items),
+		"sentence_count": sentenceCount,
+		"next_offset":    next,
+		"items":          page,
+	})
+}
+
+func (h *handler) serveLawData(w http.ResponseWriter, r *http.Request) {
+	l := h.find(r.PathValue("id"))
+	if l == nil {
+		writeError(w, http.StatusNotFound, "law not found")
+		return
+	}
+	var entry lawEntry
+	_ = json.Unmarshal(l.entry, &entry)
+	writeJSON(w, map[string]interface{}{
+		"attached_files_info": nil,
+		"law_info":            entry.LawInfo,
+		"revision_info":       entry.RevisionInfo,
+		"law_full_text":       base64.StdEncoding.EncodeToString(l.xml),
+	})
+}
+
+// sentences returns the text of the Sentence elements of a law.
+func sentences(data []byte) []string {
+	var texts []string
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return texts
+		}
+		if err != nil {
+			log.Printf("Mock e-Gov API: failed to read law XML: %v", err)
+			return texts
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "Sentence" {
+			var text string
+			if err := decoder.DecodeElement(&text, &start); err == nil {
+				texts = append(texts, text)
+			}
+		}
+	}
+}
+
+// paginate applies the limit and offset parameters, and returns the offset
+// of the next page or nil on the last page.
+func paginate(items []json.RawMessage, r *http.Request) ([]json.RawMessage, interface{}) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	offset = min(max(offset, 0), len(items))
+	end := min(offset+limit, len(items))
+	page := items[offset:end]
+	if page == nil {
+		page = []json.RawMessage{}
+	}
+	if end < len(items) {
+		return page, end
+	}
+	return page, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Mock e-Gov API: failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"code": strconv.Itoa(status), "message": message})
+}
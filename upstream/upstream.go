@@ -0,0 +1,271 @@
+package upstream
+
+import (
+	"expvar"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracker records calls to the e-Gov API by endpoint, and counts them
+// against its rate limit to estimate the remaining budget.
+type Tracker struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*endpointStats
+	// calls are the times of the calls within the last window, oldest
+	// first.
+	calls         []time.Time
+	throttled     int
+	lastThrottled time.Time
+}
+
+type endpointStats struct {
+	calls        int
+	errors       int
+	throttled    int
+	totalLatency time.Duration
+	maxLatency   time.Duration
+	statuses     map[int]int
+}
+
+// Usage is a snapshot of a Tracker.
+type Usage struct {
+	// Limit and Window are the rate limit; a zero Limit has no budget.
+	Limit  int
+	Window time.Duration
+	// Used is the number of calls within the last window, and Remaining
+	// what is left of the limit.
+	Used      int
+	Remaining int
+	// ResetsAt is when the oldest call in the window leaves it, or zero
+	// when the window is empty.
+	ResetsAt        time.Time
+	Throttled       int
+	LastThrottledAt time.Time
+	Endpoints       []EndpointUsage
+}
+
+// EndpointUsage describes the calls to one endpoint.
+type EndpointUsage struct {
+	Name           string
+	Calls          int
+	Errors         int
+	Throttled      int
+	AverageLatency time.Duration
+	MaxLatency     time.Duration
+	// Statuses counts responses by HTTP status. Failed calls whose status
+	// is unknown are counted under 0.
+	Statuses map[int]int
+}
+
+// NewTracker returns a tracker of calls against a limit of requests per
+// window. A zero limit tracks calls without a budget.
+func NewTracker(limit int, window time.Duration) *Tracker {
+	return &Tracker{limit: limit, window: window, endpoints: make(map[string]*endpointStats)}
+}
+
+// Publish makes the usage available under name with expvar, for the
+// metrics endpoint.
+func (t *Tracker) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return t.Usage().metrics()
+	}))
+}
+
+// Observe records a call to endpoint that took latency and answered status,
+// which is 0 when the call failed without a response.
+func (t *Tracker) Observe(endpoint string, status int, latency time.Duration, err error) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	throttled := status == http.StatusTooManyRequests
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.endpoints[endpoint]
+	if stats == nil {
+		stats = &endpointStats{statuses: make(map[int]int)}
+		t.endpoints[endpoint] = stats
+	}
+	stats.calls++
+	stats.statuses[status]++
+	stats.totalLatency += latency
+	stats.maxLatency = max(stats.maxLatency, latency)
+	if err != nil || status >= 400 || status == 0 {
+		stats.errors++
+	}
+
+	t.prune(now)
+	t.calls = append(t.calls, now)
+	if t.limit > 0 && len(t.calls) == t.limit*9/10 {
+		log.Printf("e-Gov API budget is 90%% used: %d of %d calls in the last %v", len(t.calls), t.limit, t.window)
+	}
+	if throttled {
+		stats.throttled++
+		t.throttled++
+		t.lastThrottled = now
+		log.Printf("e-Gov API throttled a %s request; %d calls in the last %v", endpoint, len(t.calls), t.window)
+	}
+}
+
+// throttleCooldown is how long calls run one at a time after e-Gov
+// answered 429.
+const throttleCooldown = time.Minute
+
+// Parallelism returns how many of n calls to run at once: at most what is
+// left of the budget, and one at a time for a while after e-Gov throttled
+// a request. It is at least 1.
+func (t *Tracker) Parallelism(n int) int {
+	if t == nil {
+		return max(n, 1)
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.lastThrottled.IsZero() && now.Sub(t.lastThrottled) < throttleCooldown {
+		return 1
+	}
+	if t.limit > 0 {
+		t.prune(now)
+		n = min(n, t.limit-len(t.calls))
+	}
+	return max(n, 1)
+}
+
+// prune drops the calls that left the window.
+func (t *Tracker) prune(now time.Time) {
+	if t.window <= 0 {
+		t.calls = t.calls[:0]
+		return
+	}
+	i := sort.Search(len(t.calls), func(i int) bool { return now.Sub(t.calls[i]) < t.window })
+	t.calls = append(t.calls[:0], t.calls[i:]...)
+}
+
+// Usage returns the current usage.
+func (t *Tracker) Usage() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prune(time.Now())
+
+	usage := Usage{
+		Limit:           t.limit,
+		Window:          t.window,
+		Used:            len(t.calls),
+		Throttled:       t.throttled,
+		LastThrottledAt: t.lastThrottled,
+	}
+	if t.limit > 0 {
+		usage.Remaining = max(t.limit-len(t.calls), 0)
+	}
+	if len(t.calls) > 0 {
+		usage.ResetsAt = t.calls[0].Add(t.window)
+	}
+	for name, stats := range t.endpoints {
+		endpoint := EndpointUsage{
+			Name:       name,
+			Calls:      stats.calls,
+			Errors:     stats.errors,
+			Throttled:  stats.throttled,
+			MaxLatency: stats.maxLatency,
+			Statuses:   make(map[int]int, len(stats.statuses)),
+		}
+		if stats.calls > 0 {
+			endpoint.AverageLatency = stats.totalLatency / time.Duration(stats.calls)
+		}
+		for status, n := range stats.statuses {
+			endpoint.Statuses[status] = n
+		}
+		usage.Endpoints = append(usage.Endpoints, endpoint)
+	}
+	sort.Slice(usage.Endpoints, func(i, j int) bool { return usage.Endpoints[i].Name < usage.Endpoints[j].Name })
+	return usage
+}
+
+// metrics returns the usage as expvar shows it, with durations in
+// milliseconds.
+func (u Usage) metrics() map[string]interface{} {
+	endpoints := make(map[string]interface{}, len(u.Endpoints))
+	for _, e := range u.Endpoints {
+		statuses := make(map[string]int, len(e.Statuses))
+		for status, n := range e.Statuses {
+			statuses[statusName(status)] = n
+		}
+		endpoints[e.Name] = map[string]interface{}{
+			"calls":              e.Calls,
+			"errors":             e.Errors,
+			"throttled":          e.Throttled,
+			"average_latency_ms": milliseconds(e.AverageLatency),
+			"max_latency_ms":     milliseconds(e.MaxLatency),
+			"statuses":           statuses,
+		}
+	}
+	m := map[string]interface{}{
+		"limit":          u.Limit,
+		"window_seconds": u.Window.Seconds(),
+		"used":           u.Used,
+		"throttled":      u.Throttled,
+		"endpoints":      endpoints,
+	}
+	if u.Limit > 0 {
+		m["remaining"] = u.Remaining
+	}
+	return m
+}
+
+func statusName(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Transport returns an HTTP transport that records the calls made through
+// base, or http.DefaultTransport when base is nil. Calls are named after
+// the first path segment after the API version, such as law_data.
+func (t *Tracker) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{tracker: t, base: base}
+}
+
+type transport struct {
+	tracker *Tracker
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	t.tracker.Observe(endpointName(req.URL.Path), status, time.Since(start), err)
+	return resp, err
+}
+
+// endpointName returns the segment of an API path after the version, such
+// as law_data for /api/2/law_data/{id}.
+func endpointName(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "2" && i+1 < len(segments) {
+			return segments[i+1]
+		}
+	}
+	return segments[len(segments)-1]
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Headers of a callback request. The signature is the hex HMAC-SHA256 of
+// the timestamp, a period, and the body, keyed by the shared secret.
+const (
+	SignatureHeader = "X-Jplaw2epub-Signature"
+	TimestampHeader = "X-Jplaw2epub-Timestamp"
+)
+
+// maxAttempts is the number of deliveries of a callback before giving up.
+const maxAttempts = 3
+
+// Sender posts signed JSON callbacks. Its client refuses to connect to
+// loopback, private, and link-local addresses, so that callback URLs
+// cannot reach services inside the deployment.
+type Sender struct {
+	secret []byte
+	client *http.Client
+}
+
+func NewSender(secret string, timeout time.Duration) *Sender {
+	dialer := &net.Dialer{Timeout: timeout, Control: refusePrivate}
+	return &Sender{
+		secret: []byte(secret),
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+			// Redirects could lead to addresses the URL check did not see.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// ValidateURL checks that a callback URL is an absolute https URL.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return fmt.Errorf("callback URL must be an absolute https URL, got %q", raw)
+	}
+	return nil
+}
+
+// Sign returns the signature header value of a callback body.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Post sends payload as JSON to callbackURL, retrying network errors and
+// 5xx responses with a short backoff.
+func (s *Sender) Post(ctx context.Context, callbackURL string, payload interface{}) error {
+	if err := ValidateURL(callbackURL); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode callback: %v", err)
+	}
+
+	backoff := time.Second
+	for attempt := 1; ; attempt++ {
+		retryable, err := s.post(ctx, callbackURL, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt == maxAttempts {
+			return fmt.Errorf("failed to post callback to %s: %v", callbackURL, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post delivers body once and reports whether a failure may be retried.
+func (s *Sender) post(ctx context.Context, callbackURL string, body []byte) (bool, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return !errors.Is(err, errPrivateAddress), err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode >= 500, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return false, nil
+}
+
+var errPrivateAddress = errors.New("callback address is not public")
+
+// refusePrivate is a net.Dialer Control function that rejects non-public
+// addresses after name resolution.
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", errPrivateAddress, ip)
+	}
+	return nil
+}